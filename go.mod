@@ -2,7 +2,10 @@ module github.com/vrajashkr/cc-kv-go
 
 go 1.24.1
 
-require github.com/stretchr/testify v1.11.0
+require (
+	github.com/stretchr/testify v1.11.0
+	github.com/yuin/gopher-lua v1.1.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"strconv"
+)
+
+// BitRange limits BITCOUNT and BITPOS to a range of bytes, or of bits when Bit is set. negative positions
+// count from the end of the value.
+type BitRange struct {
+	Start int64
+	End   int64
+	Bit   bool
+}
+
+func (r *BitRange) args() []string {
+	if r == nil {
+		return nil
+	}
+	unit := "BYTE"
+	if r.Bit {
+		unit = "BIT"
+	}
+	return []string{formatInt(r.Start), formatInt(r.End), unit}
+}
+
+// https://redis.io/docs/latest/commands/setbit/
+func (c *Client) SetBit(ctx context.Context, key string, offset int64, value int) (int64, error) {
+	return toInt(c.call(ctx, "SETBIT", key, formatInt(offset), strconv.Itoa(value)))
+}
+
+// https://redis.io/docs/latest/commands/getbit/
+func (c *Client) GetBit(ctx context.Context, key string, offset int64) (int64, error) {
+	return toInt(c.call(ctx, "GETBIT", key, formatInt(offset)))
+}
+
+// BitCount counts the bits set in the value of the key, or in a range of it when bitRange is not nil.
+// https://redis.io/docs/latest/commands/bitcount/
+func (c *Client) BitCount(ctx context.Context, key string, bitRange *BitRange) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"BITCOUNT", key}, bitRange.args()...)...))
+}
+
+// BitPos returns the position of the first bit set to the given bit in the value of the key, or in a range of
+// it when bitRange is not nil.
+// https://redis.io/docs/latest/commands/bitpos/
+func (c *Client) BitPos(ctx context.Context, key string, bit int, bitRange *BitRange) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"BITPOS", key, strconv.Itoa(bit)}, bitRange.args()...)...))
+}
+
+// BitOp stores the result of the operation (AND, OR, XOR or NOT) between the keys into the destination key.
+// https://redis.io/docs/latest/commands/bitop/
+func (c *Client) BitOp(ctx context.Context, operation string, destKey string, keys ...string) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"BITOP", operation, destKey}, keys...)...))
+}
+
+// BitField runs the operations given as arguments, like "GET", "u8", "0", and returns their results, which
+// are nil for the operations that failed with OVERFLOW FAIL.
+// https://redis.io/docs/latest/commands/bitfield/
+func (c *Client) BitField(ctx context.Context, key string, operations ...string) ([]*int64, error) {
+	elements, err := toArray(c.call(ctx, append([]string{"BITFIELD", key}, operations...)...))
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*int64, len(elements))
+	for idx, element := range elements {
+		value, err := toInt(element, nil)
+		if err == ErrNil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		results[idx] = &value
+	}
+	return results, nil
+}
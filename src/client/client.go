@@ -0,0 +1,264 @@
+// Package client is a Go client for cc-kv-go. commands are sent over a pool of connections speaking RESP2,
+// either one at a time with the typed methods of Client, or in batches with a Pipeline. the commands without
+// a typed method can be sent with Do.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+const (
+	DEFAULT_POOL_SIZE    = 10
+	DEFAULT_DIAL_TIMEOUT = 5 * time.Second
+)
+
+var (
+	// ErrNil is returned by the commands that reply with null, like GET for a key that doesn't exist.
+	ErrNil = errors.New("nil reply")
+	// ErrClosed is returned by the commands sent once the client is closed.
+	ErrClosed = errors.New("client is closed")
+)
+
+// Error is an error reply of the server, like "WRONGTYPE Operation against a key holding the wrong kind of value".
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+type Options struct {
+	// Addr is the host:port address of the server.
+	Addr string
+	// Username and Password authenticate the connections with AUTH when Password is set. the username
+	// defaults to the default user.
+	Username string
+	Password string
+	// PoolSize is the maximum number of connections open at once, DEFAULT_POOL_SIZE when 0. the commands
+	// wait for a connection to be free once all of them are busy.
+	PoolSize int
+	// DialTimeout bounds the time spent connecting, DEFAULT_DIAL_TIMEOUT when 0.
+	DialTimeout time.Duration
+	// TLSConfig enables TLS when set.
+	TLSConfig *tls.Config
+}
+
+// Client sends commands to a server over a pool of connections. It is safe for concurrent use. the context
+// of each command bounds the time spent waiting for a connection and for the reply: the connection is
+// closed when the context ends before the reply is read.
+type Client struct {
+	pool *pool
+	// dedicated is set for the clients returned by Conn, which send all their commands over one connection
+	dedicated *dedicated
+}
+
+type dedicated struct {
+	mu sync.Mutex
+	// cn is nil once the connection is returned to the pool or broken
+	cn *conn
+}
+
+// pool holds the connections of a client.
+type pool struct {
+	opts Options
+	// slots holds a token for every connection in use, so that at most PoolSize are open at once
+	slots chan struct{}
+
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+func New(opts Options) *Client {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = DEFAULT_POOL_SIZE
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = DEFAULT_DIAL_TIMEOUT
+	}
+	return &Client{pool: &pool{
+		opts:  opts,
+		slots: make(chan struct{}, opts.PoolSize),
+	}}
+}
+
+// Conn takes a connection out of the pool and returns a client sending all its commands over it, for the
+// commands whose effect is tied to the connection like CLIENT SETNAME or CLIENT TRACKING. the connection
+// goes back to the pool once the returned client is closed, and is dropped when a command fails on it.
+func (c *Client) Conn(ctx context.Context) (*Client, error) {
+	cn, err := c.pool.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{pool: c.pool, dedicated: &dedicated{cn: cn}}, nil
+}
+
+// Close closes the idle connections. the connections in use are closed once their command completes. for
+// the clients returned by Conn, Close returns their connection to the pool instead.
+func (c *Client) Close() error {
+	if c.dedicated != nil {
+		c.dedicated.mu.Lock()
+		defer c.dedicated.mu.Unlock()
+		if c.dedicated.cn == nil {
+			return ErrClosed
+		}
+		c.pool.put(c.dedicated.cn)
+		c.dedicated.cn = nil
+		return nil
+	}
+	return c.pool.close()
+}
+
+// Do sends a command and returns its reply. error replies are returned as an Error.
+func (c *Client) Do(ctx context.Context, args ...string) (data.Message, error) {
+	return c.call(ctx, args...)
+}
+
+// call sends a single command, turning its error reply into an Error.
+func (c *Client) call(ctx context.Context, args ...string) (data.Message, error) {
+	replies, err := c.process(ctx, []data.Array{newCommand(args...)})
+	if err != nil {
+		return nil, err
+	}
+	if errReply, ok := replies[0].(data.Error); ok {
+		return nil, Error(errReply.ErrMsg)
+	}
+	return replies[0], nil
+}
+
+// process sends the commands over a single connection and returns their replies.
+func (c *Client) process(ctx context.Context, commands []data.Array) ([]data.Message, error) {
+	if c.dedicated != nil {
+		c.dedicated.mu.Lock()
+		defer c.dedicated.mu.Unlock()
+		cn := c.dedicated.cn
+		if cn == nil {
+			return nil, ErrClosed
+		}
+		replies, err := cn.roundTrip(ctx, commands)
+		if cn.broken {
+			c.pool.put(cn)
+			c.dedicated.cn = nil
+		}
+		return replies, err
+	}
+
+	cn, err := c.pool.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	replies, err := cn.roundTrip(ctx, commands)
+	c.pool.put(cn)
+	return replies, err
+}
+
+func (p *pool) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	p.closed = true
+	for _, cn := range p.idle {
+		_ = cn.close()
+	}
+	p.idle = nil
+	return nil
+}
+
+func (p *pool) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+// get takes an idle connection from the pool, or opens a new one when there is none.
+func (p *pool) get(ctx context.Context) (*conn, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.slots
+		return nil, ErrClosed
+	}
+	if count := len(p.idle); count > 0 {
+		cn := p.idle[count-1]
+		p.idle = p.idle[:count-1]
+		p.mu.Unlock()
+		return cn, nil
+	}
+	p.mu.Unlock()
+
+	cn, err := p.dial(ctx)
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	return cn, nil
+}
+
+// put returns a connection to the pool, unless it can't be used anymore.
+func (p *pool) put(cn *conn) {
+	p.mu.Lock()
+	if cn.broken || p.closed {
+		_ = cn.close()
+	} else {
+		p.idle = append(p.idle, cn)
+	}
+	p.mu.Unlock()
+	<-p.slots
+}
+
+// dial opens a new connection and authenticates it.
+func (p *pool) dial(ctx context.Context) (*conn, error) {
+	dialer := &net.Dialer{Timeout: p.opts.DialTimeout}
+	var netConn net.Conn
+	var err error
+	if p.opts.TLSConfig != nil {
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: p.opts.TLSConfig}).DialContext(ctx, "tcp", p.opts.Addr)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", p.opts.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cn := newConn(netConn)
+	if p.opts.Password != "" {
+		auth := []string{"AUTH", p.opts.Password}
+		if p.opts.Username != "" {
+			auth = []string{"AUTH", p.opts.Username, p.opts.Password}
+		}
+		replies, err := cn.roundTrip(ctx, []data.Array{newCommand(auth...)})
+		if err == nil {
+			if errReply, ok := replies[0].(data.Error); ok {
+				err = Error(errReply.ErrMsg)
+			}
+		}
+		if err != nil {
+			_ = cn.close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// newCommand builds a command to send to the server.
+func newCommand(args ...string) data.Array {
+	elements := make([]data.Message, len(args))
+	for idx, arg := range args {
+		elements[idx] = data.BulkString{Data: arg}
+	}
+	return data.Array{Elements: elements}
+}
@@ -0,0 +1,210 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/client"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/server"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// startServer runs an in-process server on the port, a free one for "0", and returns its address along with its handler.
+func startServer(t *testing.T, port string, cfg *config.Config) (string, *server.Server, handler.CommandHandler) {
+	strgEng := storage.NewMapStorageEngine()
+	cmdHandler := handler.NewCommandHandlerWithConfig(&strgEng, cfg)
+	srv, err := server.NewTcpServer(port, func(conn net.Conn) server.Session {
+		return cmdHandler.NewClient(conn)
+	})
+	require.Nil(t, err)
+	t.Cleanup(srv.StopListen)
+	go srv.Serve()
+
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(srv.Addrs()[0].(*net.TCPAddr).Port))
+	return addr, srv, cmdHandler
+}
+
+// newClient starts a server and returns a client connected to it.
+func newClient(t *testing.T) *client.Client {
+	addr, _, _ := startServer(t, "0", config.NewConfig())
+	c := client.New(client.Options{Addr: addr})
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestClientDo(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	reply, err := c.Do(ctx, "PING")
+	assert.Nil(err)
+	assert.Equal(data.SimpleString{Contents: "PONG"}, reply)
+
+	reply, err = c.Do(ctx, "SET", "key", "value")
+	assert.Nil(err)
+	assert.Equal(handler.OK, reply)
+
+	_, err = c.Do(ctx, "XADD", "key", "*", "field", "value")
+	var errReply client.Error
+	assert.True(errors.As(err, &errReply))
+	assert.Equal("WRONGTYPE Operation against a key holding the wrong kind of value", errReply.Error())
+
+	_, err = c.Get(ctx, "missing")
+	assert.Equal(client.ErrNil, err)
+}
+
+func TestClientPool(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	addr, srv, _ := startServer(t, "0", config.NewConfig())
+	c := client.New(client.Options{Addr: addr, PoolSize: 2})
+	defer func() { _ = c.Close() }()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Incr(ctx, "counter")
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	value, err := c.Get(ctx, "counter")
+	require.Nil(err)
+	require.Equal("50", value)
+	require.LessOrEqual(srv.ActiveConnections(), int64(2))
+}
+
+func TestClientContext(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	addr, _, _ := startServer(t, "0", config.NewConfig())
+	c := client.New(client.Options{Addr: addr, PoolSize: 1})
+	defer func() { _ = c.Close() }()
+
+	// the blocking read never completes, so the command ends with the context
+	blocking := client.XReadArgs{Streams: []string{"stream"}, IDs: []string{"$"}, Block: -1}
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.XRead(timeoutCtx, blocking)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = c.XRead(cancelCtx, blocking)
+	assert.True(errors.Is(err, context.Canceled))
+
+	// the interrupted connections are dropped, and the pool opens a fresh one
+	require.Nil(c.Ping(context.Background()))
+
+	// the commands waiting for a connection give up with their context as well
+	conn, err := c.Conn(context.Background())
+	require.Nil(err)
+	timeoutCtx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(context.DeadlineExceeded, c.Ping(timeoutCtx))
+	require.Nil(conn.Close())
+	require.Nil(c.Ping(context.Background()))
+}
+
+func TestClientAuth(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	require.Nil(t, cfg.Set("requirepass", "secret"))
+	addr, _, _ := startServer(t, "0", cfg)
+
+	c := client.New(client.Options{Addr: addr, Password: "wrong"})
+	defer func() { _ = c.Close() }()
+	err := c.Ping(ctx)
+	var errReply client.Error
+	assert.True(errors.As(err, &errReply))
+	assert.Contains(errReply.Error(), "WRONGPASS")
+
+	c = client.New(client.Options{Addr: addr, Username: "default", Password: "secret"})
+	defer func() { _ = c.Close() }()
+	assert.Nil(c.Ping(ctx))
+	user, err := c.ACLWhoAmI(ctx)
+	assert.Nil(err)
+	assert.Equal("default", user)
+}
+
+func TestClientConn(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	conn, err := c.Conn(ctx)
+	require.Nil(err)
+	require.Nil(conn.ClientSetName(ctx, "worker"))
+	for range 3 {
+		name, err := conn.ClientGetName(ctx)
+		assert.Nil(err)
+		assert.Equal("worker", name)
+	}
+	id, err := conn.ClientID(ctx)
+	require.Nil(err)
+	require.Nil(conn.Close())
+	assert.Equal(client.ErrClosed, conn.Close())
+	_, err = conn.ClientID(ctx)
+	assert.Equal(client.ErrClosed, err)
+
+	// the connection went back to the pool
+	otherID, err := c.ClientID(ctx)
+	assert.Nil(err)
+	assert.Equal(id, otherID)
+}
+
+func TestClientClose(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	addr, _, _ := startServer(t, "0", config.NewConfig())
+	c := client.New(client.Options{Addr: addr})
+
+	assert.Nil(c.Set(ctx, "key", "value", 0))
+	assert.Nil(c.Close())
+	assert.Equal(client.ErrClosed, c.Close())
+	_, err := c.Get(ctx, "key")
+	assert.Equal(client.ErrClosed, err)
+	_, err = c.Subscribe(ctx, "channel")
+	assert.Equal(client.ErrClosed, err)
+}
+
+func TestPipeline(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	pipe := c.Pipeline()
+	pipe.Do("SET", "key", "value")
+	pipe.Do("INCR", "key")
+	pipe.Do("GET", "key")
+	assert.Equal(3, pipe.Len())
+
+	replies, err := pipe.Exec(ctx)
+	assert.Equal(client.Error("value is not an integer or out of range"), err)
+	assert.Equal([]data.Message{
+		handler.OK,
+		data.Error{ErrMsg: "value is not an integer or out of range"},
+		data.BulkString{Data: "value"},
+	}, replies)
+	assert.Equal(0, pipe.Len())
+
+	replies, err = pipe.Exec(ctx)
+	assert.Nil(err)
+	assert.Empty(replies)
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"strconv"
+)
+
+// ClusterSlots is a range of slots in the reply of CLUSTER SLOTS, along with the node serving it.
+type ClusterSlots struct {
+	Start int64
+	End   int64
+	Node  ClusterNode
+}
+
+type ClusterNode struct {
+	ID   string
+	IP   string
+	Port int64
+}
+
+// ClusterShard is a shard in the reply of CLUSTER SHARDS.
+type ClusterShard struct {
+	// Slots holds the ranges of slots of the shard, as pairs of start and end slots.
+	Slots [][2]int64
+	Nodes []ClusterShardNode
+}
+
+type ClusterShardNode struct {
+	ID                string
+	IP                string
+	Port              int64
+	Endpoint          string
+	Role              string
+	ReplicationOffset int64
+	Health            string
+}
+
+// https://redis.io/docs/latest/commands/cluster-myid/
+func (c *Client) ClusterMyID(ctx context.Context) (string, error) {
+	return toString(c.call(ctx, "CLUSTER", "MYID"))
+}
+
+// https://redis.io/docs/latest/commands/cluster-keyslot/
+func (c *Client) ClusterKeySlot(ctx context.Context, key string) (int64, error) {
+	return toInt(c.call(ctx, "CLUSTER", "KEYSLOT", key))
+}
+
+// https://redis.io/docs/latest/commands/cluster-info/
+func (c *Client) ClusterInfo(ctx context.Context) (string, error) {
+	return toString(c.call(ctx, "CLUSTER", "INFO"))
+}
+
+// ClusterNodes returns the description of the nodes of the cluster, one per line.
+// https://redis.io/docs/latest/commands/cluster-nodes/
+func (c *Client) ClusterNodes(ctx context.Context) (string, error) {
+	return toString(c.call(ctx, "CLUSTER", "NODES"))
+}
+
+// https://redis.io/docs/latest/commands/cluster-slots/
+func (c *Client) ClusterSlots(ctx context.Context) ([]ClusterSlots, error) {
+	elements, err := toArray(c.call(ctx, "CLUSTER", "SLOTS"))
+	if err != nil {
+		return nil, err
+	}
+	slots := make([]ClusterSlots, len(elements))
+	for idx, element := range elements {
+		fields, err := toArray(element, nil)
+		if err != nil || len(fields) < 3 {
+			return nil, unexpectedReply(element)
+		}
+		start, startErr := toInt(fields[0], nil)
+		end, endErr := toInt(fields[1], nil)
+		node, nodeErr := toArray(fields[2], nil)
+		if startErr != nil || endErr != nil || nodeErr != nil || len(node) != 3 {
+			return nil, unexpectedReply(element)
+		}
+		ip, ipErr := toString(node[0], nil)
+		port, portErr := toInt(node[1], nil)
+		id, idErr := toString(node[2], nil)
+		if ipErr != nil || portErr != nil || idErr != nil {
+			return nil, unexpectedReply(element)
+		}
+		slots[idx] = ClusterSlots{Start: start, End: end, Node: ClusterNode{ID: id, IP: ip, Port: port}}
+	}
+	return slots, nil
+}
+
+// https://redis.io/docs/latest/commands/cluster-shards/
+func (c *Client) ClusterShards(ctx context.Context) ([]ClusterShard, error) {
+	elements, err := toArray(c.call(ctx, "CLUSTER", "SHARDS"))
+	if err != nil {
+		return nil, err
+	}
+	shards := make([]ClusterShard, len(elements))
+	for idx, element := range elements {
+		fields, err := toFields(element, nil)
+		if err != nil {
+			return nil, err
+		}
+		slots, err := toInts(fields["slots"], nil)
+		if err != nil || len(slots)%2 != 0 {
+			return nil, unexpectedReply(element)
+		}
+		for slot := 0; slot < len(slots); slot += 2 {
+			shards[idx].Slots = append(shards[idx].Slots, [2]int64{slots[slot], slots[slot+1]})
+		}
+
+		nodes, err := toArray(fields["nodes"], nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			details, err := toFields(node, nil)
+			if err != nil {
+				return nil, err
+			}
+			shardNode := ClusterShardNode{}
+			for name, target := range map[string]*string{
+				"id":       &shardNode.ID,
+				"ip":       &shardNode.IP,
+				"endpoint": &shardNode.Endpoint,
+				"role":     &shardNode.Role,
+				"health":   &shardNode.Health,
+			} {
+				if *target, err = toString(details[name], nil); err != nil {
+					return nil, err
+				}
+			}
+			if shardNode.Port, err = toInt(details["port"], nil); err != nil {
+				return nil, err
+			}
+			if shardNode.ReplicationOffset, err = toInt(details["replication-offset"], nil); err != nil {
+				return nil, err
+			}
+			shards[idx].Nodes = append(shards[idx].Nodes, shardNode)
+		}
+	}
+	return shards, nil
+}
+
+// ClusterMeet connects the node to the node at the address, whose cluster bus listens on the usual offset
+// from its port.
+// https://redis.io/docs/latest/commands/cluster-meet/
+func (c *Client) ClusterMeet(ctx context.Context, ip string, port int) error {
+	return toOK(c.call(ctx, "CLUSTER", "MEET", ip, strconv.Itoa(port)))
+}
+
+func slotArgs(command string, slots []int) []string {
+	args := []string{"CLUSTER", command}
+	for _, slot := range slots {
+		args = append(args, strconv.Itoa(slot))
+	}
+	return args
+}
+
+// https://redis.io/docs/latest/commands/cluster-addslots/
+func (c *Client) ClusterAddSlots(ctx context.Context, slots ...int) error {
+	return toOK(c.call(ctx, slotArgs("ADDSLOTS", slots)...))
+}
+
+// https://redis.io/docs/latest/commands/cluster-delslots/
+func (c *Client) ClusterDelSlots(ctx context.Context, slots ...int) error {
+	return toOK(c.call(ctx, slotArgs("DELSLOTS", slots)...))
+}
+
+// https://redis.io/docs/latest/commands/cluster-addslotsrange/
+func (c *Client) ClusterAddSlotsRange(ctx context.Context, start int, end int) error {
+	return toOK(c.call(ctx, slotArgs("ADDSLOTSRANGE", []int{start, end})...))
+}
+
+// https://redis.io/docs/latest/commands/cluster-delslotsrange/
+func (c *Client) ClusterDelSlotsRange(ctx context.Context, start int, end int) error {
+	return toOK(c.call(ctx, slotArgs("DELSLOTSRANGE", []int{start, end})...))
+}
+
+// ClusterSetSlot changes the state of a slot with the action: MIGRATING, IMPORTING or NODE followed by a node
+// ID, or STABLE.
+// https://redis.io/docs/latest/commands/cluster-setslot/
+func (c *Client) ClusterSetSlot(ctx context.Context, slot int, action string, nodeID string) error {
+	args := []string{"CLUSTER", "SETSLOT", strconv.Itoa(slot), action}
+	if nodeID != "" {
+		args = append(args, nodeID)
+	}
+	return toOK(c.call(ctx, args...))
+}
+
+// https://redis.io/docs/latest/commands/cluster-countkeysinslot/
+func (c *Client) ClusterCountKeysInSlot(ctx context.Context, slot int) (int64, error) {
+	return toInt(c.call(ctx, "CLUSTER", "COUNTKEYSINSLOT", strconv.Itoa(slot)))
+}
+
+// https://redis.io/docs/latest/commands/cluster-getkeysinslot/
+func (c *Client) ClusterGetKeysInSlot(ctx context.Context, slot int, count int) ([]string, error) {
+	return toStrings(c.call(ctx, "CLUSTER", "GETKEYSINSLOT", strconv.Itoa(slot), strconv.Itoa(count)))
+}
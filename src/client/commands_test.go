@@ -0,0 +1,435 @@
+package client_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/client"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+func TestStringCommands(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	assert.Nil(c.Set(ctx, "key", "value", 0))
+	value, err := c.Get(ctx, "key")
+	assert.Nil(err)
+	assert.Equal("value", value)
+
+	ok, err := c.SetNX(ctx, "key", "other")
+	assert.Nil(err)
+	assert.False(ok)
+	length, err := c.Append(ctx, "key", "s")
+	assert.Nil(err)
+	assert.Equal(int64(6), length)
+	value, err = c.GetRange(ctx, "key", 0, 2)
+	assert.Nil(err)
+	assert.Equal("val", value)
+	value, err = c.GetSet(ctx, "key", "new")
+	assert.Nil(err)
+	assert.Equal("values", value)
+
+	assert.Nil(c.MSet(ctx, "a", "1", "b", "2"))
+	values, err := c.MGet(ctx, "a", "missing", "b")
+	assert.Nil(err)
+	require.Len(t, values, 3)
+	assert.Equal("1", *values[0])
+	assert.Nil(values[1])
+	assert.Equal("2", *values[2])
+
+	counter, err := c.IncrBy(ctx, "a", 10)
+	assert.Nil(err)
+	assert.Equal(int64(11), counter)
+	float, err := c.IncrByFloat(ctx, "b", 0.5)
+	assert.Nil(err)
+	assert.Equal(2.5, float)
+
+	count, err := c.Exists(ctx, "a", "b", "missing")
+	assert.Nil(err)
+	assert.Equal(int64(2), count)
+	value, err = c.GetDel(ctx, "a")
+	assert.Nil(err)
+	assert.Equal("11", value)
+	_, err = c.GetDel(ctx, "a")
+	assert.Equal(client.ErrNil, err)
+
+	// the key expires after the expiration
+	assert.Nil(c.Set(ctx, "temp", "value", 50*time.Millisecond))
+	assert.Eventually(func() bool {
+		_, err := c.Get(ctx, "temp")
+		return err == client.ErrNil
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Nil(c.MSet(ctx, "key1", "ohmytext", "key2", "mynewtext"))
+	lcs, err := c.LCS(ctx, "key1", "key2")
+	assert.Nil(err)
+	assert.Equal("mytext", lcs)
+	indexes, err := c.LCSIdx(ctx, "key1", "key2", 4)
+	assert.Nil(err)
+	assert.Equal(client.LCSIndexes{
+		Matches: []client.LCSMatch{{A: [2]int64{4, 7}, B: [2]int64{5, 8}, Len: 4}},
+		Len:     6,
+	}, indexes)
+
+	payload, err := c.Dump(ctx, "key1")
+	assert.Nil(err)
+	assert.Nil(c.Restore(ctx, "copy", 0, payload, false))
+	value, err = c.Get(ctx, "copy")
+	assert.Nil(err)
+	assert.Equal("ohmytext", value)
+}
+
+func TestBitAndHyperLogLogCommands(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	previous, err := c.SetBit(ctx, "bits", 7, 1)
+	assert.Nil(err)
+	assert.Equal(int64(0), previous)
+	bit, err := c.GetBit(ctx, "bits", 7)
+	assert.Nil(err)
+	assert.Equal(int64(1), bit)
+
+	assert.Nil(c.Set(ctx, "text", "foobar", 0))
+	count, err := c.BitCount(ctx, "text", nil)
+	assert.Nil(err)
+	assert.Equal(int64(26), count)
+	count, err = c.BitCount(ctx, "text", &client.BitRange{Start: 1, End: 1})
+	assert.Nil(err)
+	assert.Equal(int64(6), count)
+	pos, err := c.BitPos(ctx, "bits", 1, nil)
+	assert.Nil(err)
+	assert.Equal(int64(7), pos)
+
+	length, err := c.BitOp(ctx, "OR", "dest", "bits", "text")
+	assert.Nil(err)
+	assert.Equal(int64(6), length)
+
+	results, err := c.BitField(ctx, "field", "SET", "u8", "0", "200", "OVERFLOW", "FAIL", "INCRBY", "u8", "0", "100", "GET", "u8", "0")
+	assert.Nil(err)
+	require.Len(t, results, 3)
+	assert.Equal(int64(0), *results[0])
+	assert.Nil(results[1])
+	assert.Equal(int64(200), *results[2])
+
+	changed, err := c.PFAdd(ctx, "hll", "a", "b", "c")
+	assert.Nil(err)
+	assert.True(changed)
+	_, err = c.PFAdd(ctx, "other", "c", "d")
+	assert.Nil(err)
+	assert.Nil(c.PFMerge(ctx, "merged", "hll", "other"))
+	count, err = c.PFCount(ctx, "merged")
+	assert.Nil(err)
+	assert.Equal(int64(4), count)
+}
+
+func TestGeoCommands(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	added, err := c.GeoAdd(ctx, "Sicily",
+		client.GeoLocation{Name: "Palermo", Longitude: 13.361389, Latitude: 38.115556},
+		client.GeoLocation{Name: "Catania", Longitude: 15.087269, Latitude: 37.502669},
+	)
+	assert.Nil(err)
+	assert.Equal(int64(2), added)
+
+	dist, err := c.GeoDist(ctx, "Sicily", "Palermo", "Catania", "km")
+	assert.Nil(err)
+	assert.InDelta(166.2742, dist, 0.001)
+	_, err = c.GeoDist(ctx, "Sicily", "Palermo", "missing", "")
+	assert.Equal(client.ErrNil, err)
+
+	positions, err := c.GeoPos(ctx, "Sicily", "Palermo", "missing")
+	assert.Nil(err)
+	require.Len(t, positions, 2)
+	assert.InDelta(13.361389, positions[0].Longitude, 0.0001)
+	assert.InDelta(38.115556, positions[0].Latitude, 0.0001)
+	assert.Nil(positions[1])
+
+	hashes, err := c.GeoHash(ctx, "Sicily", "Palermo")
+	assert.Nil(err)
+	assert.Equal([]string{"sqc8b49rny0"}, hashes)
+
+	locations, err := c.GeoSearch(ctx, "Sicily", client.GeoSearchQuery{
+		Longitude: 15, Latitude: 37, Radius: 200, Unit: "km", Sort: "ASC",
+		WithCoord: true, WithDist: true, WithHash: true,
+	})
+	assert.Nil(err)
+	require.Len(t, locations, 2)
+	assert.Equal("Catania", locations[0].Name)
+	assert.InDelta(56.4413, locations[0].Dist, 0.001)
+	assert.Equal(int64(3479447370796909), locations[0].GeoHash)
+	assert.InDelta(15.087269, locations[0].Longitude, 0.0001)
+	assert.Equal("Palermo", locations[1].Name)
+
+	locations, err = c.GeoSearch(ctx, "Sicily", client.GeoSearchQuery{Member: "Palermo", Width: 400, Height: 400, Unit: "km", Sort: "DESC"})
+	assert.Nil(err)
+	assert.Equal([]client.GeoLocation{{Name: "Catania"}, {Name: "Palermo"}}, locations)
+
+	stored, err := c.GeoSearchStore(ctx, "near", "Sicily", client.GeoSearchQuery{Member: "Palermo", Radius: 10, Unit: "km"})
+	assert.Nil(err)
+	assert.Equal(int64(1), stored)
+	stored, err = c.GeoSearchStore(ctx, "near", "Sicily", client.GeoSearchQuery{Member: "Palermo", Radius: 200, Unit: "km", StoreDist: true})
+	assert.Nil(err)
+	assert.Equal(int64(2), stored)
+}
+
+func TestScriptingCommands(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	reply, err := c.Eval(ctx, "return {KEYS[1], ARGV[1]}", []string{"key"}, "arg")
+	assert.Nil(err)
+	assert.Equal(data.Array{Elements: []data.Message{data.BulkString{Data: "key"}, data.BulkString{Data: "arg"}}}, reply)
+
+	sha, err := c.ScriptLoad(ctx, "return redis.call('SET', KEYS[1], ARGV[1])")
+	assert.Nil(err)
+	exists, err := c.ScriptExists(ctx, sha, "0000000000000000000000000000000000000000")
+	assert.Nil(err)
+	assert.Equal([]bool{true, false}, exists)
+	_, err = c.EvalSha(ctx, sha, []string{"key"}, "value")
+	assert.Nil(err)
+	value, err := c.Get(ctx, "key")
+	assert.Nil(err)
+	assert.Equal("value", value)
+	assert.Nil(c.ScriptFlush(ctx))
+	_, err = c.EvalSha(ctx, sha, []string{"key"}, "value")
+	assert.IsType(client.Error(""), err)
+
+	code := "#!lua name=mylib\n" +
+		"redis.register_function{function_name='myget', callback=function(keys) return redis.call('GET', keys[1]) end, flags={'no-writes'}}"
+	name, err := c.FunctionLoad(ctx, code, false)
+	assert.Nil(err)
+	assert.Equal("mylib", name)
+	_, err = c.FunctionLoad(ctx, code, false)
+	assert.IsType(client.Error(""), err)
+
+	reply, err = c.FCallRO(ctx, "myget", []string{"key"})
+	assert.Nil(err)
+	assert.Equal(data.BulkString{Data: "value"}, reply)
+
+	libraries, err := c.FunctionList(ctx, "my*", true)
+	assert.Nil(err)
+	assert.Equal([]client.Library{{
+		Name:      "mylib",
+		Engine:    "LUA",
+		Functions: []client.Function{{Name: "myget", Flags: []string{"no-writes"}}},
+		Code:      code,
+	}}, libraries)
+
+	payload, err := c.FunctionDump(ctx)
+	assert.Nil(err)
+	assert.Nil(c.FunctionDelete(ctx, "mylib"))
+	assert.Nil(c.FunctionRestore(ctx, payload, ""))
+	reply, err = c.FCall(ctx, "myget", []string{"key"})
+	assert.Nil(err)
+	assert.Equal(data.BulkString{Data: "value"}, reply)
+	assert.Nil(c.FunctionFlush(ctx))
+	libraries, err = c.FunctionList(ctx, "", false)
+	assert.Nil(err)
+	assert.Empty(libraries)
+}
+
+func TestServerCommands(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	assert.Nil(c.Ping(ctx))
+	echo, err := c.Echo(ctx, "hello")
+	assert.Nil(err)
+	assert.Equal("hello", echo)
+	info, err := c.Info(ctx, "replication")
+	assert.Nil(err)
+	assert.Contains(info, "role:master")
+
+	role, err := c.Role(ctx)
+	assert.Nil(err)
+	assert.Equal(client.Role{Role: "master"}, role)
+	replicas, err := c.Wait(ctx, 0, 0)
+	assert.Nil(err)
+	assert.Equal(int64(0), replicas)
+
+	assert.Nil(c.ConfigSet(ctx, "slowlog-log-slower-than", "0", "slowlog-max-len", "10"))
+	params, err := c.ConfigGet(ctx, "slowlog-*")
+	assert.Nil(err)
+	assert.Equal(map[string]string{"slowlog-log-slower-than": "0", "slowlog-max-len": "10"}, params)
+
+	assert.Nil(c.Set(ctx, "key", "value", 0))
+	entries, err := c.SlowLogGet(ctx, 1)
+	assert.Nil(err)
+	require.Len(t, entries, 1)
+	assert.Equal([]string{"SET", "key", "value"}, entries[0].Args)
+	length, err := c.SlowLogLen(ctx)
+	assert.Nil(err)
+	assert.Positive(length)
+	assert.Nil(c.SlowLogReset(ctx))
+
+	count, err := c.CommandCount(ctx)
+	assert.Nil(err)
+	assert.Positive(count)
+	reply, err := c.CommandInfo(ctx, "get")
+	assert.Nil(err)
+	assert.IsType(data.Array{}, reply)
+}
+
+func TestConnectionCommands(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	conn, err := c.Conn(ctx)
+	require.Nil(err)
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.ClientGetName(ctx)
+	assert.Equal(client.ErrNil, err)
+	require.Nil(conn.ClientSetName(ctx, "worker"))
+	info, err := conn.ClientInfo(ctx)
+	assert.Nil(err)
+	assert.Contains(info, "name=worker")
+	list, err := c.ClientList(ctx, "TYPE", "normal")
+	assert.Nil(err)
+	assert.Contains(list, "name=worker")
+
+	id, err := conn.ClientID(ctx)
+	require.Nil(err)
+	assert.IsType(client.Error(""), conn.ClientTracking(ctx, true, client.TrackingOptions{BCAST: true}))
+	require.Nil(conn.ClientTracking(ctx, true, client.TrackingOptions{Redirect: id, BCAST: true, Prefixes: []string{"user:"}, NoLoop: true}))
+	tracking, err := conn.ClientTrackingInfo(ctx)
+	assert.Nil(err)
+	assert.Equal(client.TrackingInfo{Flags: []string{"on", "bcast", "noloop"}, Redirect: id, Prefixes: []string{"user:"}}, tracking)
+	redirect, err := conn.ClientGetRedir(ctx)
+	assert.Nil(err)
+	assert.Equal(id, redirect)
+	assert.IsType(client.Error(""), conn.ClientCaching(ctx, true))
+	require.Nil(conn.ClientTracking(ctx, false, client.TrackingOptions{}))
+
+	assert.Nil(c.ClientPause(ctx, 10*time.Millisecond, true))
+	assert.Nil(c.ClientUnpause(ctx))
+	killed, err := c.ClientKill(ctx, "ID", strconv.FormatInt(id, 10))
+	assert.Nil(err)
+	assert.Equal(int64(1), killed)
+
+	require.Nil(c.ACLSetUser(ctx, "alice", "on", ">secret", "~user:*", "+get"))
+	user, err := c.ACLGetUser(ctx, "alice")
+	assert.Nil(err)
+	assert.Equal([]string{"on"}, user.Flags)
+	assert.Len(user.Passwords, 1)
+	assert.Equal("~user:*", user.Keys)
+	_, err = c.ACLGetUser(ctx, "bob")
+	assert.Equal(client.ErrNil, err)
+	users, err := c.ACLUsers(ctx)
+	assert.Nil(err)
+	assert.Equal([]string{"alice", "default"}, users)
+	rules, err := c.ACLList(ctx)
+	assert.Nil(err)
+	assert.Len(rules, 2)
+	categories, err := c.ACLCat(ctx, "")
+	assert.Nil(err)
+	assert.Contains(categories, "read")
+	deleted, err := c.ACLDelUser(ctx, "alice", "bob")
+	assert.Nil(err)
+	assert.Equal(int64(1), deleted)
+	assert.IsType(client.Error(""), c.ACLSave(ctx))
+}
+
+func TestClusterCommands(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	// the cluster bus listens on an offset from the port, so the port can't be picked at random
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("port", "34580"))
+	require.Nil(cfg.Set("cluster-enabled", "yes"))
+	addr, _, cmdHandler := startServer(t, "34580", cfg)
+	require.Nil(cmdHandler.StartCluster())
+	t.Cleanup(cmdHandler.StopCluster)
+	c := client.New(client.Options{Addr: addr})
+	defer func() { _ = c.Close() }()
+
+	id, err := c.ClusterMyID(ctx)
+	require.Nil(err)
+	require.Nil(c.ClusterAddSlotsRange(ctx, 0, 16383))
+	slots, err := c.ClusterSlots(ctx)
+	assert.Nil(err)
+	// a node only learns its own IP once it meets another node
+	assert.Equal([]client.ClusterSlots{{Start: 0, End: 16383, Node: client.ClusterNode{ID: id, Port: 34580}}}, slots)
+	shards, err := c.ClusterShards(ctx)
+	assert.Nil(err)
+	require.Len(shards, 1)
+	assert.Equal([][2]int64{{0, 16383}}, shards[0].Slots)
+	require.Len(shards[0].Nodes, 1)
+	assert.Equal(id, shards[0].Nodes[0].ID)
+	assert.Equal("online", shards[0].Nodes[0].Health)
+
+	info, err := c.ClusterInfo(ctx)
+	assert.Nil(err)
+	assert.Contains(info, "cluster_slots_assigned:16384")
+	nodes, err := c.ClusterNodes(ctx)
+	assert.Nil(err)
+	assert.Contains(nodes, id)
+
+	require.Nil(c.Set(ctx, "key", "value", 0))
+	slot, err := c.ClusterKeySlot(ctx, "key")
+	assert.Nil(err)
+	assert.Equal(int64(12539), slot)
+	count, err := c.ClusterCountKeysInSlot(ctx, int(slot))
+	assert.Nil(err)
+	assert.Equal(int64(1), count)
+	keys, err := c.ClusterGetKeysInSlot(ctx, int(slot), 10)
+	assert.Nil(err)
+	assert.Equal([]string{"key"}, keys)
+
+	require.Nil(c.ClusterDelSlots(ctx, 0))
+	require.Nil(c.ClusterAddSlots(ctx, 0))
+	require.Nil(c.ClusterSetSlot(ctx, 0, "STABLE", ""))
+	assert.IsType(client.Error(""), c.ClusterDelSlotsRange(ctx, 5, 1))
+}
+
+func TestMigrate(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	source := newClient(t)
+	targetAddr, _, _ := startServer(t, "0", config.NewConfig())
+	target := client.New(client.Options{Addr: targetAddr})
+	defer func() { _ = target.Close() }()
+	host, portValue, _ := net.SplitHostPort(targetAddr)
+	port, _ := strconv.Atoi(portValue)
+
+	assert.Nil(source.MSet(ctx, "a", "1", "b", "2"))
+	status, err := source.Migrate(ctx, client.MigrateArgs{Host: host, Port: port, Keys: []string{"a"}})
+	assert.Nil(err)
+	assert.Equal("OK", status)
+	status, err = source.Migrate(ctx, client.MigrateArgs{Host: host, Port: port, Keys: []string{"a", "b"}, Copy: true})
+	assert.Nil(err)
+	assert.Equal("OK", status)
+	status, err = source.Migrate(ctx, client.MigrateArgs{Host: host, Port: port, Keys: []string{"a"}})
+	assert.Nil(err)
+	assert.Equal("NOKEY", status)
+
+	values, err := target.MGet(ctx, "a", "b")
+	assert.Nil(err)
+	assert.Equal([]*string{ptr("1"), ptr("2")}, values)
+	count, err := source.Exists(ctx, "a", "b")
+	assert.Nil(err)
+	assert.Equal(int64(1), count)
+}
+
+func ptr(value string) *string {
+	return &value
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// conn is a connection to the server.
+type conn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+	// broken is set once the replies can't be matched with the commands anymore, like after a timeout
+	broken bool
+}
+
+func newConn(netConn net.Conn) *conn {
+	return &conn{netConn: netConn, reader: bufio.NewReader(netConn)}
+}
+
+func (cn *conn) close() error {
+	return cn.netConn.Close()
+}
+
+// roundTrip writes the commands at once and reads their replies, which come in the same order.
+func (cn *conn) roundTrip(ctx context.Context, commands []data.Array) ([]data.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := cn.send(ctx, commands...); err != nil {
+		return nil, err
+	}
+	return cn.receive(ctx, len(commands))
+}
+
+// send writes the commands at once, without waiting for their replies.
+func (cn *conn) send(ctx context.Context, commands ...data.Array) error {
+	var sb strings.Builder
+	for _, command := range commands {
+		sb.WriteString(command.ToDataString())
+	}
+	return cn.interruptible(ctx, cn.netConn.SetWriteDeadline, func() error {
+		_, err := cn.netConn.Write([]byte(sb.String()))
+		return err
+	})
+}
+
+// receive reads the given number of replies.
+func (cn *conn) receive(ctx context.Context, count int) ([]data.Message, error) {
+	replies := make([]data.Message, count)
+	err := cn.interruptible(ctx, cn.netConn.SetReadDeadline, func() error {
+		for idx := range replies {
+			reply, err := data.ReadMessage(cn.reader)
+			if err != nil {
+				return err
+			}
+			replies[idx] = reply
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replies, nil
+}
+
+// interruptible runs a read or a write under the deadline of the context, and interrupts it when the
+// context ends. the connection is broken once the operation is interrupted or fails, since the replies
+// read afterwards could belong to other commands.
+func (cn *conn) interruptible(ctx context.Context, setDeadline func(time.Time) error, operation func() error) error {
+	if err := ctx.Err(); err != nil {
+		cn.broken = true
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := setDeadline(deadline); err != nil {
+		cn.broken = true
+		return err
+	}
+
+	// a deadline in the past wakes up the pending operation
+	interrupted := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		_ = setDeadline(time.Unix(1, 0))
+		close(interrupted)
+	})
+	err := operation()
+	if !stop() {
+		<-interrupted
+		cn.broken = true
+	}
+
+	if err == nil {
+		return nil
+	}
+	cn.broken = true
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if errors.Is(err, os.ErrDeadlineExceeded) && !deadline.IsZero() {
+		return context.DeadlineExceeded
+	}
+	return err
+}
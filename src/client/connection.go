@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// TrackingOptions are the options of CLIENT TRACKING ON.
+type TrackingOptions struct {
+	// Redirect sends the invalidation messages to the client of the ID, which has to be subscribed to
+	// __redis__:invalidate. it is required since the server only speaks RESP2.
+	Redirect int64
+	// BCAST tracks the keys starting with the prefixes, or all of them without any prefix, instead of the keys
+	// read by the connection.
+	BCAST    bool
+	Prefixes []string
+	// OptIn only tracks the keys read after CLIENT CACHING YES, while OptOut doesn't track the keys read after
+	// CLIENT CACHING NO.
+	OptIn  bool
+	OptOut bool
+	// NoLoop doesn't send the invalidations of the keys modified by the connection itself.
+	NoLoop bool
+}
+
+// TrackingInfo is the reply of CLIENT TRACKINGINFO.
+type TrackingInfo struct {
+	Flags []string
+	// Redirect is the ID of the client receiving the invalidations, or -1 when tracking is off.
+	Redirect int64
+	Prefixes []string
+}
+
+// ACLUser is the reply of ACL GETUSER.
+type ACLUser struct {
+	Flags []string
+	// Passwords holds the SHA-256 hashes of the passwords of the user.
+	Passwords []string
+	Commands  string
+	Keys      string
+	Channels  string
+}
+
+// the commands below are tied to the connection they are sent over, which is any connection of the pool
+// unless they are sent through a client returned by Conn.
+
+// https://redis.io/docs/latest/commands/client-id/
+func (c *Client) ClientID(ctx context.Context) (int64, error) {
+	return toInt(c.call(ctx, "CLIENT", "ID"))
+}
+
+// https://redis.io/docs/latest/commands/client-setname/
+func (c *Client) ClientSetName(ctx context.Context, name string) error {
+	return toOK(c.call(ctx, "CLIENT", "SETNAME", name))
+}
+
+// ClientGetName returns the name of the connection, or ErrNil when it has none.
+// https://redis.io/docs/latest/commands/client-getname/
+func (c *Client) ClientGetName(ctx context.Context) (string, error) {
+	return toString(c.call(ctx, "CLIENT", "GETNAME"))
+}
+
+// ClientInfo returns the description of the connection, in the format of CLIENT LIST.
+// https://redis.io/docs/latest/commands/client-info/
+func (c *Client) ClientInfo(ctx context.Context) (string, error) {
+	return toString(c.call(ctx, "CLIENT", "INFO"))
+}
+
+// ClientList returns the description of the connections to the server, one per line. the filters are given
+// as arguments, like "TYPE", "pubsub".
+// https://redis.io/docs/latest/commands/client-list/
+func (c *Client) ClientList(ctx context.Context, filters ...string) (string, error) {
+	return toString(c.call(ctx, append([]string{"CLIENT", "LIST"}, filters...)...))
+}
+
+// ClientKill closes the connections matching the filters, given as alternating filters and values like "ID",
+// "42", and returns their number.
+// https://redis.io/docs/latest/commands/client-kill/
+func (c *Client) ClientKill(ctx context.Context, filters ...string) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"CLIENT", "KILL"}, filters...)...))
+}
+
+// ClientPause suspends the commands of the clients for the duration, or only their writes when writeOnly is
+// set.
+// https://redis.io/docs/latest/commands/client-pause/
+func (c *Client) ClientPause(ctx context.Context, duration time.Duration, writeOnly bool) error {
+	if writeOnly {
+		return toOK(c.call(ctx, "CLIENT", "PAUSE", formatInt(duration.Milliseconds()), "WRITE"))
+	}
+	return toOK(c.call(ctx, "CLIENT", "PAUSE", formatInt(duration.Milliseconds())))
+}
+
+// https://redis.io/docs/latest/commands/client-unpause/
+func (c *Client) ClientUnpause(ctx context.Context) error {
+	return toOK(c.call(ctx, "CLIENT", "UNPAUSE"))
+}
+
+// ClientTracking turns client side caching on with the options, or off.
+// https://redis.io/docs/latest/commands/client-tracking/
+func (c *Client) ClientTracking(ctx context.Context, on bool, opts TrackingOptions) error {
+	if !on {
+		return toOK(c.call(ctx, "CLIENT", "TRACKING", "OFF"))
+	}
+
+	args := []string{"CLIENT", "TRACKING", "ON"}
+	if opts.Redirect > 0 {
+		args = append(args, "REDIRECT", formatInt(opts.Redirect))
+	}
+	if opts.BCAST {
+		args = append(args, "BCAST")
+	}
+	for _, prefix := range opts.Prefixes {
+		args = append(args, "PREFIX", prefix)
+	}
+	if opts.OptIn {
+		args = append(args, "OPTIN")
+	}
+	if opts.OptOut {
+		args = append(args, "OPTOUT")
+	}
+	if opts.NoLoop {
+		args = append(args, "NOLOOP")
+	}
+	return toOK(c.call(ctx, args...))
+}
+
+// ClientCaching decides whether the keys read by the next command are tracked, in the OPTIN and OPTOUT modes.
+// https://redis.io/docs/latest/commands/client-caching/
+func (c *Client) ClientCaching(ctx context.Context, yes bool) error {
+	if yes {
+		return toOK(c.call(ctx, "CLIENT", "CACHING", "YES"))
+	}
+	return toOK(c.call(ctx, "CLIENT", "CACHING", "NO"))
+}
+
+// https://redis.io/docs/latest/commands/client-getredir/
+func (c *Client) ClientGetRedir(ctx context.Context) (int64, error) {
+	return toInt(c.call(ctx, "CLIENT", "GETREDIR"))
+}
+
+// https://redis.io/docs/latest/commands/client-trackinginfo/
+func (c *Client) ClientTrackingInfo(ctx context.Context) (TrackingInfo, error) {
+	fields, err := toFields(c.call(ctx, "CLIENT", "TRACKINGINFO"))
+	if err != nil {
+		return TrackingInfo{}, err
+	}
+	info := TrackingInfo{}
+	if info.Flags, err = toStrings(fields["flags"], nil); err != nil {
+		return TrackingInfo{}, err
+	}
+	if info.Redirect, err = toInt(fields["redirect"], nil); err != nil {
+		return TrackingInfo{}, err
+	}
+	if info.Prefixes, err = toStrings(fields["prefixes"], nil); err != nil {
+		return TrackingInfo{}, err
+	}
+	return info, nil
+}
+
+// ACLSetUser creates or modifies the user with the rules, like "on", ">password" or "+@read".
+// https://redis.io/docs/latest/commands/acl-setuser/
+func (c *Client) ACLSetUser(ctx context.Context, username string, rules ...string) error {
+	return toOK(c.call(ctx, append([]string{"ACL", "SETUSER", username}, rules...)...))
+}
+
+// ACLGetUser returns the rules of the user, or ErrNil when it doesn't exist.
+// https://redis.io/docs/latest/commands/acl-getuser/
+func (c *Client) ACLGetUser(ctx context.Context, username string) (ACLUser, error) {
+	fields, err := toFields(c.call(ctx, "ACL", "GETUSER", username))
+	if err != nil {
+		return ACLUser{}, err
+	}
+	user := ACLUser{}
+	if user.Flags, err = toStrings(fields["flags"], nil); err != nil {
+		return ACLUser{}, err
+	}
+	if user.Passwords, err = toStrings(fields["passwords"], nil); err != nil {
+		return ACLUser{}, err
+	}
+	for name, target := range map[string]*string{
+		"commands": &user.Commands,
+		"keys":     &user.Keys,
+		"channels": &user.Channels,
+	} {
+		if *target, err = toString(fields[name], nil); err != nil {
+			return ACLUser{}, err
+		}
+	}
+	return user, nil
+}
+
+// ACLDelUser deletes the users, and returns the number of users deleted.
+// https://redis.io/docs/latest/commands/acl-deluser/
+func (c *Client) ACLDelUser(ctx context.Context, usernames ...string) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"ACL", "DELUSER"}, usernames...)...))
+}
+
+// ACLList returns the rules of every user, in the format of the ACL file.
+// https://redis.io/docs/latest/commands/acl-list/
+func (c *Client) ACLList(ctx context.Context) ([]string, error) {
+	return toStrings(c.call(ctx, "ACL", "LIST"))
+}
+
+// https://redis.io/docs/latest/commands/acl-users/
+func (c *Client) ACLUsers(ctx context.Context) ([]string, error) {
+	return toStrings(c.call(ctx, "ACL", "USERS"))
+}
+
+// https://redis.io/docs/latest/commands/acl-whoami/
+func (c *Client) ACLWhoAmI(ctx context.Context) (string, error) {
+	return toString(c.call(ctx, "ACL", "WHOAMI"))
+}
+
+// ACLCat returns the commands of the category, or the categories when it is empty.
+// https://redis.io/docs/latest/commands/acl-cat/
+func (c *Client) ACLCat(ctx context.Context, category string) ([]string, error) {
+	if category == "" {
+		return toStrings(c.call(ctx, "ACL", "CAT"))
+	}
+	return toStrings(c.call(ctx, "ACL", "CAT", category))
+}
+
+// https://redis.io/docs/latest/commands/acl-load/
+func (c *Client) ACLLoad(ctx context.Context) error {
+	return toOK(c.call(ctx, "ACL", "LOAD"))
+}
+
+// https://redis.io/docs/latest/commands/acl-save/
+func (c *Client) ACLSave(ctx context.Context) error {
+	return toOK(c.call(ctx, "ACL", "SAVE"))
+}
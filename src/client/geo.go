@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// GeoLocation is a member of a geospatial index. GEOSEARCH fills in Dist and GeoHash when asked to.
+type GeoLocation struct {
+	Name      string
+	Longitude float64
+	Latitude  float64
+	Dist      float64
+	GeoHash   int64
+}
+
+// GeoPos is the position of a member of a geospatial index.
+type GeoPos struct {
+	Longitude float64
+	Latitude  float64
+}
+
+// GeoSearchQuery selects the members of GEOSEARCH and GEOSEARCHSTORE, around Member when it is set or around
+// Longitude and Latitude otherwise, and within Width and Height when they are set or within Radius otherwise.
+type GeoSearchQuery struct {
+	Member    string
+	Longitude float64
+	Latitude  float64
+
+	Radius float64
+	Width  float64
+	Height float64
+	// Unit is the unit of the distances: m, km, ft or mi, m when empty.
+	Unit string
+
+	// Sort is ASC or DESC to sort the members by distance, unsorted when empty.
+	Sort string
+	// Count limits the number of members when positive, and Any stops the search once Count members are found.
+	Count int64
+	Any   bool
+
+	WithCoord bool
+	WithDist  bool
+	WithHash  bool
+	// StoreDist makes GeoSearchStore store the distances of the members in Unit instead of their positions.
+	StoreDist bool
+}
+
+func (q GeoSearchQuery) args(store bool) []string {
+	args := []string{}
+	if q.Member != "" {
+		args = append(args, "FROMMEMBER", q.Member)
+	} else {
+		args = append(args, "FROMLONLAT", formatFloat(q.Longitude), formatFloat(q.Latitude))
+	}
+
+	unit := q.Unit
+	if unit == "" {
+		unit = "m"
+	}
+	if q.Width > 0 || q.Height > 0 {
+		args = append(args, "BYBOX", formatFloat(q.Width), formatFloat(q.Height), unit)
+	} else {
+		args = append(args, "BYRADIUS", formatFloat(q.Radius), unit)
+	}
+
+	if q.Sort != "" {
+		args = append(args, q.Sort)
+	}
+	if q.Count > 0 {
+		args = append(args, "COUNT", formatInt(q.Count))
+		if q.Any {
+			args = append(args, "ANY")
+		}
+	}
+	if store && q.StoreDist {
+		args = append(args, "STOREDIST")
+	}
+	if !store {
+		if q.WithCoord {
+			args = append(args, "WITHCOORD")
+		}
+		if q.WithDist {
+			args = append(args, "WITHDIST")
+		}
+		if q.WithHash {
+			args = append(args, "WITHHASH")
+		}
+	}
+	return args
+}
+
+// GeoAdd adds the locations to the geospatial index of the key, and returns the number of new members.
+// https://redis.io/docs/latest/commands/geoadd/
+func (c *Client) GeoAdd(ctx context.Context, key string, locations ...GeoLocation) (int64, error) {
+	args := []string{"GEOADD", key}
+	for _, location := range locations {
+		args = append(args, formatFloat(location.Longitude), formatFloat(location.Latitude), location.Name)
+	}
+	return toInt(c.call(ctx, args...))
+}
+
+// GeoPos returns the positions of the members, which are nil for the members that don't exist.
+// https://redis.io/docs/latest/commands/geopos/
+func (c *Client) GeoPos(ctx context.Context, key string, members ...string) ([]*GeoPos, error) {
+	elements, err := toArray(c.call(ctx, append([]string{"GEOPOS", key}, members...)...))
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]*GeoPos, len(elements))
+	for idx, element := range elements {
+		coordinates, err := toArray(element, nil)
+		if err == ErrNil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if positions[idx], err = toGeoPos(coordinates); err != nil {
+			return nil, err
+		}
+	}
+	return positions, nil
+}
+
+func toGeoPos(coordinates []data.Message) (*GeoPos, error) {
+	if len(coordinates) != 2 {
+		return nil, unexpectedReply(data.Array{Elements: coordinates})
+	}
+	longitude, err := toFloat(coordinates[0], nil)
+	if err != nil {
+		return nil, err
+	}
+	latitude, err := toFloat(coordinates[1], nil)
+	if err != nil {
+		return nil, err
+	}
+	return &GeoPos{Longitude: longitude, Latitude: latitude}, nil
+}
+
+// GeoDist returns the distance between two members in the unit, m when empty.
+// https://redis.io/docs/latest/commands/geodist/
+func (c *Client) GeoDist(ctx context.Context, key string, member1 string, member2 string, unit string) (float64, error) {
+	if unit == "" {
+		unit = "m"
+	}
+	return toFloat(c.call(ctx, "GEODIST", key, member1, member2, unit))
+}
+
+// GeoHash returns the geohashes of the members, which are empty for the members that don't exist.
+// https://redis.io/docs/latest/commands/geohash/
+func (c *Client) GeoHash(ctx context.Context, key string, members ...string) ([]string, error) {
+	return toStrings(c.call(ctx, append([]string{"GEOHASH", key}, members...)...))
+}
+
+// GeoSearch returns the members of the geospatial index selected by the query, along with their coordinates,
+// distance or geohash when the query asks for them.
+// https://redis.io/docs/latest/commands/geosearch/
+func (c *Client) GeoSearch(ctx context.Context, key string, query GeoSearchQuery) ([]GeoLocation, error) {
+	elements, err := toArray(c.call(ctx, append([]string{"GEOSEARCH", key}, query.args(false)...)...))
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]GeoLocation, len(elements))
+	for idx, element := range elements {
+		if !query.WithCoord && !query.WithDist && !query.WithHash {
+			if locations[idx].Name, err = toString(element, nil); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// the name is followed by the distance, the geohash and the coordinates, in this order
+		fields, err := toArray(element, nil)
+		if err != nil || len(fields) == 0 {
+			return nil, unexpectedReply(element)
+		}
+		location := &locations[idx]
+		if location.Name, err = toString(fields[0], nil); err != nil {
+			return nil, err
+		}
+		fields = fields[1:]
+		if query.WithDist && len(fields) > 0 {
+			if location.Dist, err = toFloat(fields[0], nil); err != nil {
+				return nil, err
+			}
+			fields = fields[1:]
+		}
+		if query.WithHash && len(fields) > 0 {
+			if location.GeoHash, err = toInt(fields[0], nil); err != nil {
+				return nil, err
+			}
+			fields = fields[1:]
+		}
+		if query.WithCoord && len(fields) > 0 {
+			coordinates, err := toArray(fields[0], nil)
+			if err != nil {
+				return nil, err
+			}
+			pos, err := toGeoPos(coordinates)
+			if err != nil {
+				return nil, err
+			}
+			location.Longitude, location.Latitude = pos.Longitude, pos.Latitude
+		}
+	}
+	return locations, nil
+}
+
+// GeoSearchStore stores the members selected by the query into the destination key, and returns their number.
+// https://redis.io/docs/latest/commands/geosearchstore/
+func (c *Client) GeoSearchStore(ctx context.Context, destKey string, key string, query GeoSearchQuery) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"GEOSEARCHSTORE", destKey, key}, query.args(true)...)...))
+}
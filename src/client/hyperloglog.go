@@ -0,0 +1,19 @@
+package client
+
+import "context"
+
+// PFAdd adds the elements to the HyperLogLog of the key, and reports whether its estimate changed.
+// https://redis.io/docs/latest/commands/pfadd/
+func (c *Client) PFAdd(ctx context.Context, key string, elements ...string) (bool, error) {
+	return toBool(c.call(ctx, append([]string{"PFADD", key}, elements...)...))
+}
+
+// https://redis.io/docs/latest/commands/pfcount/
+func (c *Client) PFCount(ctx context.Context, keys ...string) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"PFCOUNT"}, keys...)...))
+}
+
+// https://redis.io/docs/latest/commands/pfmerge/
+func (c *Client) PFMerge(ctx context.Context, destKey string, sourceKeys ...string) error {
+	return toOK(c.call(ctx, append([]string{"PFMERGE", destKey}, sourceKeys...)...))
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// Pipeline queues commands to send them at once over a single connection, saving a round trip per command.
+// It is not safe for concurrent use.
+type Pipeline struct {
+	client   *Client
+	commands []data.Array
+}
+
+// Pipeline returns an empty pipeline sending its commands with the client.
+func (c *Client) Pipeline() *Pipeline {
+	return &Pipeline{client: c}
+}
+
+// Do queues a command.
+func (p *Pipeline) Do(args ...string) {
+	p.commands = append(p.commands, newCommand(args...))
+}
+
+// Len returns the number of queued commands.
+func (p *Pipeline) Len() int {
+	return len(p.commands)
+}
+
+// Exec sends the queued commands and returns their replies in the same order, emptying the pipeline. the
+// error replies are left in place as data.Error, and the first of them is returned as an Error as well.
+func (p *Pipeline) Exec(ctx context.Context) ([]data.Message, error) {
+	commands := p.commands
+	p.commands = nil
+	if len(commands) == 0 {
+		return []data.Message{}, nil
+	}
+
+	replies, err := p.client.process(ctx, commands)
+	if err != nil {
+		return nil, err
+	}
+	for _, reply := range replies {
+		if errReply, ok := reply.(data.Error); ok {
+			return replies, Error(errReply.ErrMsg)
+		}
+	}
+	return replies, nil
+}
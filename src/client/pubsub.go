@@ -0,0 +1,246 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// the number of messages received ahead of the reader of a PubSub.
+const PUBSUB_CHANNEL_SIZE = 100
+
+// Message is a message received by a PubSub.
+type Message struct {
+	// Kind is message or pmessage for the published messages, the kind of the command for the confirmations
+	// of SUBSCRIBE and the like, pong for the replies to PING, or error for the error replies.
+	Kind    string
+	Pattern string
+	Channel string
+	Payload string
+	// PayloadSlice holds the payload when it is an array, like the keys of the invalidation messages sent on
+	// __redis__:invalidate for client side caching.
+	PayloadSlice []string
+	// Count is the number of subscriptions of the connection after a confirmation.
+	Count int64
+}
+
+// PubSub is a connection subscribed to channels, on which the messages published to them are received.
+// It has a connection of its own outside of the pool of the client.
+type PubSub struct {
+	cn       *conn
+	writeMu  sync.Mutex
+	messages chan Message
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// Subscribe opens a connection subscribed to the channels, and returns once the subscriptions are confirmed.
+func (c *Client) Subscribe(ctx context.Context, channels ...string) (*PubSub, error) {
+	return c.newPubSub(ctx, "SUBSCRIBE", channels)
+}
+
+// PSubscribe opens a connection subscribed to the channels matching the patterns, and returns once the
+// subscriptions are confirmed.
+func (c *Client) PSubscribe(ctx context.Context, patterns ...string) (*PubSub, error) {
+	return c.newPubSub(ctx, "PSUBSCRIBE", patterns)
+}
+
+func (c *Client) newPubSub(ctx context.Context, command string, targets []string) (*PubSub, error) {
+	if c.pool.isClosed() {
+		return nil, ErrClosed
+	}
+
+	cn, err := c.pool.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := subscribe(ctx, cn, command, targets); err != nil {
+		_ = cn.close()
+		return nil, err
+	}
+
+	ps := &PubSub{
+		cn:       cn,
+		messages: make(chan Message, PUBSUB_CHANNEL_SIZE),
+		done:     make(chan struct{}),
+	}
+	go ps.receiveLoop()
+	return ps, nil
+}
+
+// subscribe sends the first subscription of the connection and waits for its confirmations, one per target.
+// a failed command only gets a single error reply.
+func subscribe(ctx context.Context, cn *conn, command string, targets []string) error {
+	if err := cn.send(ctx, newCommand(append([]string{command}, targets...)...)); err != nil {
+		return err
+	}
+	for range max(len(targets), 1) {
+		replies, err := cn.receive(ctx, 1)
+		if err != nil {
+			return err
+		}
+		if errReply, ok := replies[0].(data.Error); ok {
+			return Error(errReply.ErrMsg)
+		}
+	}
+	return nil
+}
+
+// Channel returns the channel on which the messages are received. It is closed along with the connection.
+// the connection stops reading when the messages are not taken from the channel.
+func (ps *PubSub) Channel() <-chan Message {
+	return ps.messages
+}
+
+// Subscribe adds subscriptions to channels. they are confirmed with messages on the channel.
+func (ps *PubSub) Subscribe(ctx context.Context, channels ...string) error {
+	return ps.send(ctx, "SUBSCRIBE", channels...)
+}
+
+// PSubscribe adds subscriptions to the channels matching the patterns. they are confirmed with messages on
+// the channel.
+func (ps *PubSub) PSubscribe(ctx context.Context, patterns ...string) error {
+	return ps.send(ctx, "PSUBSCRIBE", patterns...)
+}
+
+// Unsubscribe removes subscriptions to channels, or all of them without any channel. the removals are
+// confirmed with messages on the channel.
+func (ps *PubSub) Unsubscribe(ctx context.Context, channels ...string) error {
+	return ps.send(ctx, "UNSUBSCRIBE", channels...)
+}
+
+// PUnsubscribe removes subscriptions to patterns, or all of them without any pattern. the removals are
+// confirmed with messages on the channel.
+func (ps *PubSub) PUnsubscribe(ctx context.Context, patterns ...string) error {
+	return ps.send(ctx, "PUNSUBSCRIBE", patterns...)
+}
+
+// Ping checks the connection, which is answered with a pong message on the channel.
+func (ps *PubSub) Ping(ctx context.Context) error {
+	return ps.send(ctx, "PING")
+}
+
+func (ps *PubSub) send(ctx context.Context, command string, args ...string) error {
+	ps.writeMu.Lock()
+	defer ps.writeMu.Unlock()
+	if err := ps.cn.send(ctx, newCommand(append([]string{command}, args...)...)); err != nil {
+		// the command may have been partially written
+		_ = ps.Close()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (ps *PubSub) Close() error {
+	err := ErrClosed
+	ps.closeOnce.Do(func() {
+		close(ps.done)
+		err = ps.cn.close()
+	})
+	return err
+}
+
+// receiveLoop delivers the messages received on the connection until it is closed.
+func (ps *PubSub) receiveLoop() {
+	defer close(ps.messages)
+	for {
+		reply, err := data.ReadMessage(ps.cn.reader)
+		if err != nil {
+			_ = ps.Close()
+			return
+		}
+		msg, ok := newMessage(reply)
+		if !ok {
+			continue
+		}
+		select {
+		case ps.messages <- msg:
+		case <-ps.done:
+			return
+		}
+	}
+}
+
+// newMessage converts a message received by a subscribed connection.
+func newMessage(reply data.Message) (Message, bool) {
+	if errReply, ok := reply.(data.Error); ok {
+		return Message{Kind: "error", Payload: errReply.ErrMsg}, true
+	}
+	elements, err := toArray(reply, nil)
+	if err != nil || len(elements) < 2 {
+		return Message{}, false
+	}
+	kind, err := toString(elements[0], nil)
+	if err != nil {
+		return Message{}, false
+	}
+
+	msg := Message{Kind: strings.ToLower(kind)}
+	switch {
+	case msg.Kind == "message" && len(elements) == 3:
+		msg.Channel, _ = toString(elements[1], nil)
+		msg.setPayload(elements[2])
+	case msg.Kind == "pmessage" && len(elements) == 4:
+		msg.Pattern, _ = toString(elements[1], nil)
+		msg.Channel, _ = toString(elements[2], nil)
+		msg.setPayload(elements[3])
+	case msg.Kind == "pong":
+		msg.Payload, _ = toString(elements[1], nil)
+	case len(elements) == 3:
+		// the confirmations of the subscriptions, where the target is null when there was nothing to unsubscribe
+		msg.Channel, _ = toString(elements[1], nil)
+		msg.Count, _ = toInt(elements[2], nil)
+	default:
+		return Message{}, false
+	}
+	return msg, true
+}
+
+func (msg *Message) setPayload(payload data.Message) {
+	if _, ok := payload.(data.Array); ok {
+		msg.PayloadSlice, _ = toStrings(payload, nil)
+		return
+	}
+	msg.Payload, _ = toString(payload, nil)
+}
+
+// Publish posts a message to a channel, and returns the number of clients that received it.
+// https://redis.io/docs/latest/commands/publish/
+func (c *Client) Publish(ctx context.Context, channel string, message string) (int64, error) {
+	return toInt(c.call(ctx, "PUBLISH", channel, message))
+}
+
+// PubSubChannels returns the channels with subscribers matching the pattern, or all of them when it is empty.
+// https://redis.io/docs/latest/commands/pubsub-channels/
+func (c *Client) PubSubChannels(ctx context.Context, pattern string) ([]string, error) {
+	if pattern == "" {
+		return toStrings(c.call(ctx, "PUBSUB", "CHANNELS"))
+	}
+	return toStrings(c.call(ctx, "PUBSUB", "CHANNELS", pattern))
+}
+
+// PubSubNumSub returns the number of subscribers of the channels.
+// https://redis.io/docs/latest/commands/pubsub-numsub/
+func (c *Client) PubSubNumSub(ctx context.Context, channels ...string) (map[string]int64, error) {
+	fields, err := toFields(c.call(ctx, append([]string{"PUBSUB", "NUMSUB"}, channels...)...))
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(fields))
+	for channel, field := range fields {
+		if counts[channel], err = toInt(field, nil); err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// PubSubNumPat returns the number of subscriptions to patterns.
+// https://redis.io/docs/latest/commands/pubsub-numpat/
+func (c *Client) PubSubNumPat(ctx context.Context) (int64, error) {
+	return toInt(c.call(ctx, "PUBSUB", "NUMPAT"))
+}
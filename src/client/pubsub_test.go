@@ -0,0 +1,121 @@
+package client_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/client"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+)
+
+// receive returns the next message of the subscription, failing the test when none arrives in time.
+func receive(t *testing.T, ps *client.PubSub) client.Message {
+	select {
+	case msg, ok := <-ps.Channel():
+		require.True(t, ok, "the subscription is closed")
+		return msg
+	case <-time.After(time.Second):
+		require.Fail(t, "no message received in time")
+		return client.Message{}
+	}
+}
+
+func TestPubSub(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	ps, err := c.Subscribe(ctx, "news", "sports")
+	require.Nil(err)
+	defer func() { _ = ps.Close() }()
+
+	received, err := c.Publish(ctx, "news", "hello")
+	assert.Nil(err)
+	assert.Equal(int64(1), received)
+	assert.Equal(client.Message{Kind: "message", Channel: "news", Payload: "hello"}, receive(t, ps))
+
+	channels, err := c.PubSubChannels(ctx, "")
+	assert.Nil(err)
+	assert.ElementsMatch([]string{"news", "sports"}, channels)
+	counts, err := c.PubSubNumSub(ctx, "news", "weather")
+	assert.Nil(err)
+	assert.Equal(map[string]int64{"news": 1, "weather": 0}, counts)
+
+	require.Nil(ps.PSubscribe(ctx, "w*"))
+	assert.Equal(client.Message{Kind: "psubscribe", Channel: "w*", Count: 3}, receive(t, ps))
+	patterns, err := c.PubSubNumPat(ctx)
+	assert.Nil(err)
+	assert.Equal(int64(1), patterns)
+	_, err = c.Publish(ctx, "weather", "sunny")
+	assert.Nil(err)
+	assert.Equal(client.Message{Kind: "pmessage", Pattern: "w*", Channel: "weather", Payload: "sunny"}, receive(t, ps))
+
+	require.Nil(ps.Ping(ctx))
+	assert.Equal(client.Message{Kind: "pong"}, receive(t, ps))
+
+	require.Nil(ps.Unsubscribe(ctx, "news"))
+	assert.Equal(client.Message{Kind: "unsubscribe", Channel: "news", Count: 2}, receive(t, ps))
+
+	require.Nil(ps.Close())
+	_, ok := <-ps.Channel()
+	assert.False(ok)
+	assert.Equal(client.ErrClosed, ps.Close())
+}
+
+func TestPubSubTrackingRedirect(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	ps, err := c.Subscribe(ctx, handler.TRACKING_CHANNEL)
+	require.Nil(err)
+	defer func() { _ = ps.Close() }()
+	conn, err := c.Conn(ctx)
+	require.Nil(err)
+	defer func() { _ = conn.Close() }()
+
+	// the subscribed connection is the only connection of its kind
+	list, err := c.ClientList(ctx, "TYPE", "pubsub")
+	require.Nil(err)
+	id := ""
+	for _, field := range strings.Fields(list) {
+		if value, ok := strings.CutPrefix(field, "id="); ok {
+			id = value
+		}
+	}
+	redirect, err := strconv.ParseInt(id, 10, 64)
+	require.Nil(err)
+
+	require.Nil(conn.ClientTracking(ctx, true, client.TrackingOptions{Redirect: redirect}))
+	_, err = conn.Get(ctx, "key")
+	assert.Equal(client.ErrNil, err)
+	require.Nil(c.Set(ctx, "key", "value", 0))
+	assert.Equal(client.Message{
+		Kind:         "message",
+		Channel:      handler.TRACKING_CHANNEL,
+		PayloadSlice: []string{"key"},
+	}, receive(t, ps))
+}
+
+func TestPubSubErrors(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	_, err := c.Subscribe(ctx)
+	assert.IsType(client.Error(""), err)
+
+	// the subscription fails once its context has ended
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Nanosecond)
+	defer cancel()
+	<-timeoutCtx.Done()
+	_, err = c.Subscribe(timeoutCtx, "news")
+	assert.NotNil(err)
+}
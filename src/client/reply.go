@@ -0,0 +1,172 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// the helpers below convert the replies of the commands into Go values. they take the error of the command
+// along with its reply, so that they can wrap the calls directly.
+
+func unexpectedReply(reply data.Message) error {
+	return fmt.Errorf("unexpected reply %q", reply.ToDataString())
+}
+
+func toString(reply data.Message, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	switch reply := reply.(type) {
+	case data.BulkString:
+		return reply.Data, nil
+	case data.SimpleString:
+		return reply.Contents, nil
+	case data.Null:
+		return "", ErrNil
+	default:
+		return "", unexpectedReply(reply)
+	}
+}
+
+func toInt(reply data.Message, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	switch reply := reply.(type) {
+	case data.Integer:
+		return reply.Value, nil
+	case data.BulkString:
+		return strconv.ParseInt(reply.Data, 10, 64)
+	case data.Null:
+		return 0, ErrNil
+	default:
+		return 0, unexpectedReply(reply)
+	}
+}
+
+func toFloat(reply data.Message, err error) (float64, error) {
+	value, err := toString(reply, err)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func toBool(reply data.Message, err error) (bool, error) {
+	value, err := toInt(reply, err)
+	return value == 1, err
+}
+
+// toOK discards the reply of the commands that reply with OK.
+func toOK(_ data.Message, err error) error {
+	return err
+}
+
+func toArray(reply data.Message, err error) ([]data.Message, error) {
+	if err != nil {
+		return nil, err
+	}
+	switch reply := reply.(type) {
+	case data.Array:
+		return reply.Elements, nil
+	case data.Null:
+		return nil, ErrNil
+	default:
+		return nil, unexpectedReply(reply)
+	}
+}
+
+// toStrings converts an array of strings, where the null elements become empty strings.
+func toStrings(reply data.Message, err error) ([]string, error) {
+	elements, err := toArray(reply, err)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, len(elements))
+	for idx, element := range elements {
+		if values[idx], err = toString(element, nil); err != nil && err != ErrNil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// toOptionalStrings converts an array of strings, where the null elements become nil.
+func toOptionalStrings(reply data.Message, err error) ([]*string, error) {
+	elements, err := toArray(reply, err)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]*string, len(elements))
+	for idx, element := range elements {
+		value, err := toString(element, nil)
+		if err == ErrNil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[idx] = &value
+	}
+	return values, nil
+}
+
+func toInts(reply data.Message, err error) ([]int64, error) {
+	elements, err := toArray(reply, err)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]int64, len(elements))
+	for idx, element := range elements {
+		if values[idx], err = toInt(element, nil); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// toFields converts the arrays of alternating field names and values, like the replies of XINFO.
+func toFields(reply data.Message, err error) (map[string]data.Message, error) {
+	elements, err := toArray(reply, err)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements)%2 != 0 {
+		return nil, unexpectedReply(reply)
+	}
+	fields := make(map[string]data.Message, len(elements)/2)
+	for idx := 0; idx < len(elements); idx += 2 {
+		name, err := toString(elements[idx], nil)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = elements[idx+1]
+	}
+	return fields, nil
+}
+
+// toStringMap converts an array of alternating names and values, like the reply of CONFIG GET.
+func toStringMap(reply data.Message, err error) (map[string]string, error) {
+	fields, err := toFields(reply, err)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(fields))
+	for name, field := range fields {
+		if values[name], err = toString(field, nil); err != nil && err != ErrNil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// optionalInt converts an integer that may be null, which becomes -1.
+func optionalInt(reply data.Message) (int64, error) {
+	value, err := toInt(reply, nil)
+	if err == ErrNil {
+		return -1, nil
+	}
+	return value, err
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// Library is a library of functions in the reply of FUNCTION LIST.
+type Library struct {
+	Name      string
+	Engine    string
+	Functions []Function
+	// Code is only set when the libraries are listed with their code.
+	Code string
+}
+
+type Function struct {
+	Name        string
+	Description string
+	Flags       []string
+}
+
+func scriptArgs(command string, script string, keys []string, args []string) []string {
+	cmd := append([]string{command, script, formatInt(int64(len(keys)))}, keys...)
+	return append(cmd, args...)
+}
+
+// Eval runs a Lua script with the keys and arguments, and returns its reply as it is.
+// https://redis.io/docs/latest/commands/eval/
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...string) (data.Message, error) {
+	return c.call(ctx, scriptArgs("EVAL", script, keys, args)...)
+}
+
+// EvalSha runs a script cached by SCRIPT LOAD or EVAL with the keys and arguments.
+// https://redis.io/docs/latest/commands/evalsha/
+func (c *Client) EvalSha(ctx context.Context, sha string, keys []string, args ...string) (data.Message, error) {
+	return c.call(ctx, scriptArgs("EVALSHA", sha, keys, args)...)
+}
+
+// ScriptLoad caches a script without running it, and returns its SHA1 digest.
+// https://redis.io/docs/latest/commands/script-load/
+func (c *Client) ScriptLoad(ctx context.Context, script string) (string, error) {
+	return toString(c.call(ctx, "SCRIPT", "LOAD", script))
+}
+
+// ScriptExists reports whether the scripts of the SHA1 digests are cached.
+// https://redis.io/docs/latest/commands/script-exists/
+func (c *Client) ScriptExists(ctx context.Context, shas ...string) ([]bool, error) {
+	values, err := toInts(c.call(ctx, append([]string{"SCRIPT", "EXISTS"}, shas...)...))
+	if err != nil {
+		return nil, err
+	}
+	exists := make([]bool, len(values))
+	for idx, value := range values {
+		exists[idx] = value == 1
+	}
+	return exists, nil
+}
+
+// https://redis.io/docs/latest/commands/script-flush/
+func (c *Client) ScriptFlush(ctx context.Context) error {
+	return toOK(c.call(ctx, "SCRIPT", "FLUSH"))
+}
+
+// ScriptKill stops the script running on the server, as long as it hasn't written anything.
+// https://redis.io/docs/latest/commands/script-kill/
+func (c *Client) ScriptKill(ctx context.Context) error {
+	return toOK(c.call(ctx, "SCRIPT", "KILL"))
+}
+
+// FCall runs a function loaded by FUNCTION LOAD with the keys and arguments, and returns its reply as it is.
+// https://redis.io/docs/latest/commands/fcall/
+func (c *Client) FCall(ctx context.Context, function string, keys []string, args ...string) (data.Message, error) {
+	return c.call(ctx, scriptArgs("FCALL", function, keys, args)...)
+}
+
+// FCallRO runs a function flagged no-writes, which is allowed on read only replicas.
+// https://redis.io/docs/latest/commands/fcall_ro/
+func (c *Client) FCallRO(ctx context.Context, function string, keys []string, args ...string) (data.Message, error) {
+	return c.call(ctx, scriptArgs("FCALL_RO", function, keys, args)...)
+}
+
+// FunctionLoad loads a library, replacing the library of the same name when replace is set, and returns its
+// name.
+// https://redis.io/docs/latest/commands/function-load/
+func (c *Client) FunctionLoad(ctx context.Context, code string, replace bool) (string, error) {
+	if replace {
+		return toString(c.call(ctx, "FUNCTION", "LOAD", "REPLACE", code))
+	}
+	return toString(c.call(ctx, "FUNCTION", "LOAD", code))
+}
+
+// https://redis.io/docs/latest/commands/function-delete/
+func (c *Client) FunctionDelete(ctx context.Context, library string) error {
+	return toOK(c.call(ctx, "FUNCTION", "DELETE", library))
+}
+
+// https://redis.io/docs/latest/commands/function-flush/
+func (c *Client) FunctionFlush(ctx context.Context) error {
+	return toOK(c.call(ctx, "FUNCTION", "FLUSH"))
+}
+
+// FunctionKill stops the function running on the server, as long as it hasn't written anything.
+// https://redis.io/docs/latest/commands/function-kill/
+func (c *Client) FunctionKill(ctx context.Context) error {
+	return toOK(c.call(ctx, "FUNCTION", "KILL"))
+}
+
+// FunctionList returns the libraries whose name matches the pattern, or all of them when it is empty, along
+// with their code when withCode is set.
+// https://redis.io/docs/latest/commands/function-list/
+func (c *Client) FunctionList(ctx context.Context, pattern string, withCode bool) ([]Library, error) {
+	args := []string{"FUNCTION", "LIST"}
+	if pattern != "" {
+		args = append(args, "LIBRARYNAME", pattern)
+	}
+	if withCode {
+		args = append(args, "WITHCODE")
+	}
+
+	elements, err := toArray(c.call(ctx, args...))
+	if err != nil {
+		return nil, err
+	}
+	libraries := make([]Library, len(elements))
+	for idx, element := range elements {
+		fields, err := toFields(element, nil)
+		if err != nil {
+			return nil, err
+		}
+		library := &libraries[idx]
+		if library.Name, err = toString(fields["library_name"], nil); err != nil {
+			return nil, err
+		}
+		if library.Engine, err = toString(fields["engine"], nil); err != nil {
+			return nil, err
+		}
+		if withCode {
+			if library.Code, err = toString(fields["library_code"], nil); err != nil {
+				return nil, err
+			}
+		}
+
+		functions, err := toArray(fields["functions"], nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, function := range functions {
+			details, err := toFields(function, nil)
+			if err != nil {
+				return nil, err
+			}
+			name, err := toString(details["name"], nil)
+			if err != nil {
+				return nil, err
+			}
+			// the description is null when the function was registered without one
+			description, err := toString(details["description"], nil)
+			if err != nil && err != ErrNil {
+				return nil, err
+			}
+			flags, err := toStrings(details["flags"], nil)
+			if err != nil {
+				return nil, err
+			}
+			library.Functions = append(library.Functions, Function{Name: name, Description: description, Flags: flags})
+		}
+	}
+	return libraries, nil
+}
+
+// FunctionDump returns the serialized libraries, which FUNCTION RESTORE takes.
+// https://redis.io/docs/latest/commands/function-dump/
+func (c *Client) FunctionDump(ctx context.Context) (string, error) {
+	return toString(c.call(ctx, "FUNCTION", "DUMP"))
+}
+
+// FunctionRestore restores the libraries serialized by FUNCTION DUMP with the policy: FLUSH, APPEND or
+// REPLACE, APPEND when empty.
+// https://redis.io/docs/latest/commands/function-restore/
+func (c *Client) FunctionRestore(ctx context.Context, payload string, policy string) error {
+	if policy == "" {
+		return toOK(c.call(ctx, "FUNCTION", "RESTORE", payload))
+	}
+	return toOK(c.call(ctx, "FUNCTION", "RESTORE", payload, policy))
+}
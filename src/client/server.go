@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// Role is the reply of ROLE. a master fills in Replicas, while a replica fills in the master it replicates and
+// the state of its link.
+type Role struct {
+	// Role is master or slave.
+	Role   string
+	Offset int64
+
+	Replicas []RoleReplica
+
+	MasterHost string
+	MasterPort int64
+	State      string
+}
+
+// RoleReplica is a replica connected to a master.
+type RoleReplica struct {
+	Host      string
+	Port      int64
+	AckOffset int64
+}
+
+// SlowLog is an entry of the slow log.
+type SlowLog struct {
+	ID         int64
+	Time       time.Time
+	Duration   time.Duration
+	Args       []string
+	ClientAddr string
+	ClientName string
+}
+
+type MigrateArgs struct {
+	Host string
+	Port int
+	Keys []string
+	DB   int64
+	// Timeout bounds the time spent talking to the target, one second when 0.
+	Timeout time.Duration
+	// Copy keeps the keys, and Replace overwrites the keys existing on the target.
+	Copy    bool
+	Replace bool
+	// Username and Password authenticate with the target when Password is set.
+	Username string
+	Password string
+}
+
+// https://redis.io/docs/latest/commands/ping/
+func (c *Client) Ping(ctx context.Context) error {
+	return toOK(c.call(ctx, "PING"))
+}
+
+// https://redis.io/docs/latest/commands/echo/
+func (c *Client) Echo(ctx context.Context, message string) (string, error) {
+	return toString(c.call(ctx, "ECHO", message))
+}
+
+// Info returns the sections of INFO, or the default ones when none is given.
+// https://redis.io/docs/latest/commands/info/
+func (c *Client) Info(ctx context.Context, sections ...string) (string, error) {
+	return toString(c.call(ctx, append([]string{"INFO"}, sections...)...))
+}
+
+// https://redis.io/docs/latest/commands/role/
+func (c *Client) Role(ctx context.Context) (Role, error) {
+	elements, err := toArray(c.call(ctx, "ROLE"))
+	if err != nil {
+		return Role{}, err
+	}
+	if len(elements) == 0 {
+		return Role{}, unexpectedReply(data.Array{Elements: elements})
+	}
+
+	role := Role{}
+	if role.Role, err = toString(elements[0], nil); err != nil {
+		return Role{}, err
+	}
+	switch {
+	case role.Role == "master" && len(elements) == 3:
+		if role.Offset, err = toInt(elements[1], nil); err != nil {
+			return Role{}, err
+		}
+		replicas, err := toArray(elements[2], nil)
+		if err != nil {
+			return Role{}, err
+		}
+		for _, replica := range replicas {
+			fields, err := toArray(replica, nil)
+			if err != nil || len(fields) != 3 {
+				return Role{}, unexpectedReply(replica)
+			}
+			host, hostErr := toString(fields[0], nil)
+			port, portErr := toInt(fields[1], nil)
+			ackOffset, offsetErr := toInt(fields[2], nil)
+			if hostErr != nil || portErr != nil || offsetErr != nil {
+				return Role{}, unexpectedReply(replica)
+			}
+			role.Replicas = append(role.Replicas, RoleReplica{Host: host, Port: port, AckOffset: ackOffset})
+		}
+	case role.Role == "slave" && len(elements) == 5:
+		host, hostErr := toString(elements[1], nil)
+		port, portErr := toInt(elements[2], nil)
+		state, stateErr := toString(elements[3], nil)
+		offset, offsetErr := toInt(elements[4], nil)
+		if hostErr != nil || portErr != nil || stateErr != nil || offsetErr != nil {
+			return Role{}, unexpectedReply(data.Array{Elements: elements})
+		}
+		role.MasterHost, role.MasterPort, role.State, role.Offset = host, port, state, offset
+	default:
+		return Role{}, unexpectedReply(data.Array{Elements: elements})
+	}
+	return role, nil
+}
+
+// Wait blocks until the writes sent before it are acknowledged by numReplicas replicas or the timeout is
+// over, and returns the number of replicas that acknowledged them. a timeout of 0 waits forever.
+// https://redis.io/docs/latest/commands/wait/
+func (c *Client) Wait(ctx context.Context, numReplicas int64, timeout time.Duration) (int64, error) {
+	return toInt(c.call(ctx, "WAIT", formatInt(numReplicas), formatInt(timeout.Milliseconds())))
+}
+
+// ReplicaOf makes the server replicate the master at the address.
+// https://redis.io/docs/latest/commands/replicaof/
+func (c *Client) ReplicaOf(ctx context.Context, host string, port int) error {
+	return toOK(c.call(ctx, "REPLICAOF", host, strconv.Itoa(port)))
+}
+
+// ReplicaOfNoOne stops the replication of the server, which becomes a master.
+// https://redis.io/docs/latest/commands/replicaof/
+func (c *Client) ReplicaOfNoOne(ctx context.Context) error {
+	return toOK(c.call(ctx, "REPLICAOF", "NO", "ONE"))
+}
+
+// ConfigGet returns the parameters matching the patterns along with their values.
+// https://redis.io/docs/latest/commands/config-get/
+func (c *Client) ConfigGet(ctx context.Context, patterns ...string) (map[string]string, error) {
+	return toStringMap(c.call(ctx, append([]string{"CONFIG", "GET"}, patterns...)...))
+}
+
+// ConfigSet sets the parameters to the values, given as alternating parameters and values.
+// https://redis.io/docs/latest/commands/config-set/
+func (c *Client) ConfigSet(ctx context.Context, pairs ...string) error {
+	return toOK(c.call(ctx, append([]string{"CONFIG", "SET"}, pairs...)...))
+}
+
+// SlowLogGet returns the count most recent entries of the slow log, or all of them when count is -1.
+// https://redis.io/docs/latest/commands/slowlog-get/
+func (c *Client) SlowLogGet(ctx context.Context, count int64) ([]SlowLog, error) {
+	elements, err := toArray(c.call(ctx, "SLOWLOG", "GET", formatInt(count)))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]SlowLog, len(elements))
+	for idx, element := range elements {
+		fields, err := toArray(element, nil)
+		if err != nil || len(fields) != 6 {
+			return nil, unexpectedReply(element)
+		}
+		id, idErr := toInt(fields[0], nil)
+		timestamp, timeErr := toInt(fields[1], nil)
+		micros, durationErr := toInt(fields[2], nil)
+		args, argsErr := toStrings(fields[3], nil)
+		addr, addrErr := toString(fields[4], nil)
+		name, nameErr := toString(fields[5], nil)
+		if idErr != nil || timeErr != nil || durationErr != nil || argsErr != nil || addrErr != nil || nameErr != nil {
+			return nil, unexpectedReply(element)
+		}
+		entries[idx] = SlowLog{
+			ID:         id,
+			Time:       time.Unix(timestamp, 0),
+			Duration:   time.Duration(micros) * time.Microsecond,
+			Args:       args,
+			ClientAddr: addr,
+			ClientName: name,
+		}
+	}
+	return entries, nil
+}
+
+// https://redis.io/docs/latest/commands/slowlog-len/
+func (c *Client) SlowLogLen(ctx context.Context) (int64, error) {
+	return toInt(c.call(ctx, "SLOWLOG", "LEN"))
+}
+
+// https://redis.io/docs/latest/commands/slowlog-reset/
+func (c *Client) SlowLogReset(ctx context.Context) error {
+	return toOK(c.call(ctx, "SLOWLOG", "RESET"))
+}
+
+// https://redis.io/docs/latest/commands/command-count/
+func (c *Client) CommandCount(ctx context.Context) (int64, error) {
+	return toInt(c.call(ctx, "COMMAND", "COUNT"))
+}
+
+// CommandInfo returns the details of the commands, or of all of them when none is given, as they are.
+// https://redis.io/docs/latest/commands/command-info/
+func (c *Client) CommandInfo(ctx context.Context, commands ...string) (data.Message, error) {
+	return c.call(ctx, append([]string{"COMMAND", "INFO"}, commands...)...)
+}
+
+// CommandDocs returns the documentation of the commands, or of all of them when none is given, as it is.
+// https://redis.io/docs/latest/commands/command-docs/
+func (c *Client) CommandDocs(ctx context.Context, commands ...string) (data.Message, error) {
+	return c.call(ctx, append([]string{"COMMAND", "DOCS"}, commands...)...)
+}
+
+// Migrate moves the keys to another server. It returns NOKEY when none of the keys exists, OK otherwise.
+// https://redis.io/docs/latest/commands/migrate/
+func (c *Client) Migrate(ctx context.Context, a MigrateArgs) (string, error) {
+	args := []string{"MIGRATE", a.Host, strconv.Itoa(a.Port), "", formatInt(a.DB), formatInt(a.Timeout.Milliseconds())}
+	if len(a.Keys) == 1 {
+		args[3] = a.Keys[0]
+	}
+	if a.Copy {
+		args = append(args, "COPY")
+	}
+	if a.Replace {
+		args = append(args, "REPLACE")
+	}
+	if a.Password != "" && a.Username != "" {
+		args = append(args, "AUTH2", a.Username, a.Password)
+	} else if a.Password != "" {
+		args = append(args, "AUTH", a.Password)
+	}
+	if len(a.Keys) != 1 {
+		args = append(args, "KEYS")
+		args = append(args, a.Keys...)
+	}
+	return toString(c.call(ctx, args...))
+}
@@ -0,0 +1,622 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// XMessage is an entry of a stream. Values is nil for the entries deleted while they were pending.
+type XMessage struct {
+	ID     string
+	Values map[string]string
+}
+
+// XStream is the entries read from a stream by XREAD or XREADGROUP.
+type XStream struct {
+	Stream   string
+	Messages []XMessage
+}
+
+// XTrimArgs trims a stream down to MaxLen entries when it is positive, or down to the entries from MinID
+// otherwise. Approx lets the server trim fewer entries, up to Limit when it is positive.
+type XTrimArgs struct {
+	MaxLen int64
+	MinID  string
+	Approx bool
+	Limit  int64
+}
+
+func (a XTrimArgs) args() []string {
+	args := []string{}
+	switch {
+	case a.MaxLen > 0:
+		args = append(args, "MAXLEN")
+	case a.MinID != "":
+		args = append(args, "MINID")
+	default:
+		return args
+	}
+	if a.Approx {
+		args = append(args, "~")
+	}
+	if a.MaxLen > 0 {
+		args = append(args, formatInt(a.MaxLen))
+	} else {
+		args = append(args, a.MinID)
+	}
+	if a.Limit > 0 {
+		args = append(args, "LIMIT", formatInt(a.Limit))
+	}
+	return args
+}
+
+type XAddArgs struct {
+	Stream string
+	// NoMkStream doesn't create the stream when it doesn't exist, in which case XAdd returns ErrNil.
+	NoMkStream bool
+	// Trim trims the stream after the entry is added.
+	Trim XTrimArgs
+	// ID is the ID of the entry, generated by the server when empty.
+	ID string
+	// Values are the fields of the entry, given as alternating names and values.
+	Values []string
+}
+
+type XReadArgs struct {
+	Streams []string
+	// IDs are the IDs after which the entries are read, one per stream. $ reads the entries added while blocked.
+	IDs   []string
+	Count int64
+	// Block waits for entries for up to Block when none is available, forever when negative. the context of
+	// the command must outlast the wait.
+	Block time.Duration
+}
+
+func (a XReadArgs) args() []string {
+	args := []string{}
+	if a.Count > 0 {
+		args = append(args, "COUNT", formatInt(a.Count))
+	}
+	if a.Block != 0 {
+		args = append(args, "BLOCK", formatInt(max(a.Block.Milliseconds(), 0)))
+	}
+	args = append(args, "STREAMS")
+	args = append(args, a.Streams...)
+	return append(args, a.IDs...)
+}
+
+type XReadGroupArgs struct {
+	Group    string
+	Consumer string
+	// NoAck doesn't add the entries read to the pending entries of the group.
+	NoAck bool
+	XReadArgs
+}
+
+// XPending is the summary of the pending entries of a consumer group.
+type XPending struct {
+	Count  int64
+	Lower  string
+	Higher string
+	// Consumers maps the consumers with pending entries to their number.
+	Consumers map[string]int64
+}
+
+type XPendingExtArgs struct {
+	Stream string
+	Group  string
+	// Idle only returns the entries delivered at least Idle ago when positive.
+	Idle  time.Duration
+	Start string
+	End   string
+	Count int64
+	// Consumer only returns the entries pending for the consumer when set.
+	Consumer string
+}
+
+// XPendingExt is a pending entry of a consumer group.
+type XPendingExt struct {
+	ID         string
+	Consumer   string
+	Idle       time.Duration
+	RetryCount int64
+}
+
+type XClaimArgs struct {
+	Stream   string
+	Group    string
+	Consumer string
+	// MinIdle only claims the entries delivered at least MinIdle ago.
+	MinIdle time.Duration
+	IDs     []string
+	// Idle sets the idle time of the claimed entries when positive.
+	Idle time.Duration
+	// RetryCount sets the delivery count of the claimed entries when positive.
+	RetryCount int64
+	// Force claims the entries that are not pending as long as they exist.
+	Force bool
+}
+
+func (a XClaimArgs) args(justID bool) []string {
+	args := []string{"XCLAIM", a.Stream, a.Group, a.Consumer, formatInt(a.MinIdle.Milliseconds())}
+	args = append(args, a.IDs...)
+	if a.Idle > 0 {
+		args = append(args, "IDLE", formatInt(a.Idle.Milliseconds()))
+	}
+	if a.RetryCount > 0 {
+		args = append(args, "RETRYCOUNT", formatInt(a.RetryCount))
+	}
+	if a.Force {
+		args = append(args, "FORCE")
+	}
+	if justID {
+		args = append(args, "JUSTID")
+	}
+	return args
+}
+
+type XAutoClaimArgs struct {
+	Stream   string
+	Group    string
+	Consumer string
+	MinIdle  time.Duration
+	// Start is the ID from which the pending entries are scanned.
+	Start string
+	// Count is the number of entries scanned, 100 when 0.
+	Count int64
+}
+
+// XAutoClaim is the reply of XAUTOCLAIM.
+type XAutoClaim struct {
+	// Next is the ID from which the next call should scan, 0-0 once the scan is complete.
+	Next     string
+	Messages []XMessage
+	// Deleted holds the IDs of the pending entries that no longer exist, which are removed from the group.
+	Deleted []string
+}
+
+// XInfoStream is the reply of XINFO STREAM.
+type XInfoStream struct {
+	Length               int64
+	RadixTreeKeys        int64
+	RadixTreeNodes       int64
+	LastGeneratedID      string
+	MaxDeletedEntryID    string
+	EntriesAdded         int64
+	RecordedFirstEntryID string
+	Groups               int64
+	// FirstEntry and LastEntry are nil for empty streams.
+	FirstEntry *XMessage
+	LastEntry  *XMessage
+}
+
+// XInfoGroup is a consumer group in the reply of XINFO GROUPS.
+type XInfoGroup struct {
+	Name            string
+	Consumers       int64
+	Pending         int64
+	LastDeliveredID string
+	// EntriesRead and Lag are -1 when the server can't tell them.
+	EntriesRead int64
+	Lag         int64
+}
+
+// XInfoConsumer is a consumer in the reply of XINFO CONSUMERS.
+type XInfoConsumer struct {
+	Name    string
+	Pending int64
+	Idle    time.Duration
+	// Inactive is the time since the last successful read or claim, -1 when there was none.
+	Inactive time.Duration
+}
+
+// toXMessage converts an entry, whose fields are null when it was deleted while pending.
+func toXMessage(reply data.Message) (XMessage, error) {
+	elements, err := toArray(reply, nil)
+	if err != nil || len(elements) != 2 {
+		return XMessage{}, unexpectedReply(reply)
+	}
+	id, err := toString(elements[0], nil)
+	if err != nil {
+		return XMessage{}, err
+	}
+	fields, err := toArray(elements[1], nil)
+	if err == ErrNil {
+		return XMessage{ID: id}, nil
+	}
+	if err != nil || len(fields)%2 != 0 {
+		return XMessage{}, unexpectedReply(reply)
+	}
+
+	msg := XMessage{ID: id, Values: make(map[string]string, len(fields)/2)}
+	for idx := 0; idx < len(fields); idx += 2 {
+		name, nameErr := toString(fields[idx], nil)
+		value, valueErr := toString(fields[idx+1], nil)
+		if nameErr != nil || valueErr != nil {
+			return XMessage{}, unexpectedReply(reply)
+		}
+		msg.Values[name] = value
+	}
+	return msg, nil
+}
+
+func toXMessages(reply data.Message, err error) ([]XMessage, error) {
+	elements, err := toArray(reply, err)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]XMessage, len(elements))
+	for idx, element := range elements {
+		if messages[idx], err = toXMessage(element); err != nil {
+			return nil, err
+		}
+	}
+	return messages, nil
+}
+
+// toXStreams converts the replies of XREAD and XREADGROUP, which are null when no entry was read.
+func toXStreams(reply data.Message, err error) ([]XStream, error) {
+	elements, err := toArray(reply, err)
+	if err != nil {
+		return nil, err
+	}
+	streams := make([]XStream, len(elements))
+	for idx, element := range elements {
+		fields, err := toArray(element, nil)
+		if err != nil || len(fields) != 2 {
+			return nil, unexpectedReply(element)
+		}
+		if streams[idx].Stream, err = toString(fields[0], nil); err != nil {
+			return nil, err
+		}
+		if streams[idx].Messages, err = toXMessages(fields[1], nil); err != nil {
+			return nil, err
+		}
+	}
+	return streams, nil
+}
+
+// XAdd adds an entry to a stream and returns its ID.
+// https://redis.io/docs/latest/commands/xadd/
+func (c *Client) XAdd(ctx context.Context, a XAddArgs) (string, error) {
+	args := []string{"XADD", a.Stream}
+	if a.NoMkStream {
+		args = append(args, "NOMKSTREAM")
+	}
+	args = append(args, a.Trim.args()...)
+	if a.ID != "" {
+		args = append(args, a.ID)
+	} else {
+		args = append(args, "*")
+	}
+	return toString(c.call(ctx, append(args, a.Values...)...))
+}
+
+// https://redis.io/docs/latest/commands/xlen/
+func (c *Client) XLen(ctx context.Context, stream string) (int64, error) {
+	return toInt(c.call(ctx, "XLEN", stream))
+}
+
+// XRange returns the entries of the stream between the IDs, where - and + are the first and last entries.
+// https://redis.io/docs/latest/commands/xrange/
+func (c *Client) XRange(ctx context.Context, stream string, start string, end string) ([]XMessage, error) {
+	return toXMessages(c.call(ctx, "XRANGE", stream, start, end))
+}
+
+// XRangeN returns up to count entries of the stream between the IDs.
+// https://redis.io/docs/latest/commands/xrange/
+func (c *Client) XRangeN(ctx context.Context, stream string, start string, end string, count int64) ([]XMessage, error) {
+	return toXMessages(c.call(ctx, "XRANGE", stream, start, end, "COUNT", formatInt(count)))
+}
+
+// XRevRange returns the entries of the stream between the IDs in reverse order, from end down to start.
+// https://redis.io/docs/latest/commands/xrevrange/
+func (c *Client) XRevRange(ctx context.Context, stream string, end string, start string) ([]XMessage, error) {
+	return toXMessages(c.call(ctx, "XREVRANGE", stream, end, start))
+}
+
+// XRevRangeN returns up to count entries of the stream between the IDs in reverse order.
+// https://redis.io/docs/latest/commands/xrevrange/
+func (c *Client) XRevRangeN(ctx context.Context, stream string, end string, start string, count int64) ([]XMessage, error) {
+	return toXMessages(c.call(ctx, "XREVRANGE", stream, end, start, "COUNT", formatInt(count)))
+}
+
+// XTrim trims the stream and returns the number of entries removed.
+// https://redis.io/docs/latest/commands/xtrim/
+func (c *Client) XTrim(ctx context.Context, stream string, trim XTrimArgs) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"XTRIM", stream}, trim.args()...)...))
+}
+
+// https://redis.io/docs/latest/commands/xdel/
+func (c *Client) XDel(ctx context.Context, stream string, ids ...string) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"XDEL", stream}, ids...)...))
+}
+
+// XRead reads the entries of the streams after the IDs. It returns ErrNil when there is none, once the block
+// time is over for the blocking reads.
+// https://redis.io/docs/latest/commands/xread/
+func (c *Client) XRead(ctx context.Context, a XReadArgs) ([]XStream, error) {
+	return toXStreams(c.call(ctx, append([]string{"XREAD"}, a.args()...)...))
+}
+
+// XGroupCreate creates a consumer group that reads the stream after the ID, creating the stream when mkStream
+// is set and it doesn't exist.
+// https://redis.io/docs/latest/commands/xgroup-create/
+func (c *Client) XGroupCreate(ctx context.Context, stream string, group string, id string, mkStream bool) error {
+	args := []string{"XGROUP", "CREATE", stream, group, id}
+	if mkStream {
+		args = append(args, "MKSTREAM")
+	}
+	return toOK(c.call(ctx, args...))
+}
+
+// https://redis.io/docs/latest/commands/xgroup-setid/
+func (c *Client) XGroupSetID(ctx context.Context, stream string, group string, id string) error {
+	return toOK(c.call(ctx, "XGROUP", "SETID", stream, group, id))
+}
+
+// https://redis.io/docs/latest/commands/xgroup-destroy/
+func (c *Client) XGroupDestroy(ctx context.Context, stream string, group string) (bool, error) {
+	return toBool(c.call(ctx, "XGROUP", "DESTROY", stream, group))
+}
+
+// https://redis.io/docs/latest/commands/xgroup-createconsumer/
+func (c *Client) XGroupCreateConsumer(ctx context.Context, stream string, group string, consumer string) (bool, error) {
+	return toBool(c.call(ctx, "XGROUP", "CREATECONSUMER", stream, group, consumer))
+}
+
+// XGroupDelConsumer deletes a consumer from the group, and returns the number of its pending entries.
+// https://redis.io/docs/latest/commands/xgroup-delconsumer/
+func (c *Client) XGroupDelConsumer(ctx context.Context, stream string, group string, consumer string) (int64, error) {
+	return toInt(c.call(ctx, "XGROUP", "DELCONSUMER", stream, group, consumer))
+}
+
+// XReadGroup reads the entries of the streams for a consumer of the group. the ID > reads the entries never
+// delivered to the group, while the other IDs read the pending entries of the consumer.
+// https://redis.io/docs/latest/commands/xreadgroup/
+func (c *Client) XReadGroup(ctx context.Context, a XReadGroupArgs) ([]XStream, error) {
+	args := []string{"XREADGROUP", "GROUP", a.Group, a.Consumer}
+	if a.NoAck {
+		args = append(args, "NOACK")
+	}
+	return toXStreams(c.call(ctx, append(args, a.XReadArgs.args()...)...))
+}
+
+// https://redis.io/docs/latest/commands/xack/
+func (c *Client) XAck(ctx context.Context, stream string, group string, ids ...string) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"XACK", stream, group}, ids...)...))
+}
+
+// XPending returns the summary of the pending entries of the group.
+// https://redis.io/docs/latest/commands/xpending/
+func (c *Client) XPending(ctx context.Context, stream string, group string) (XPending, error) {
+	elements, err := toArray(c.call(ctx, "XPENDING", stream, group))
+	if err != nil {
+		return XPending{}, err
+	}
+	if len(elements) != 4 {
+		return XPending{}, unexpectedReply(data.Array{Elements: elements})
+	}
+
+	pending := XPending{Consumers: map[string]int64{}}
+	if pending.Count, err = toInt(elements[0], nil); err != nil || pending.Count == 0 {
+		return pending, err
+	}
+	if pending.Lower, err = toString(elements[1], nil); err != nil {
+		return XPending{}, err
+	}
+	if pending.Higher, err = toString(elements[2], nil); err != nil {
+		return XPending{}, err
+	}
+	consumers, err := toArray(elements[3], nil)
+	if err != nil {
+		return XPending{}, err
+	}
+	for _, consumer := range consumers {
+		fields, err := toArray(consumer, nil)
+		if err != nil || len(fields) != 2 {
+			return XPending{}, unexpectedReply(consumer)
+		}
+		name, nameErr := toString(fields[0], nil)
+		count, countErr := toInt(fields[1], nil)
+		if nameErr != nil || countErr != nil {
+			return XPending{}, unexpectedReply(consumer)
+		}
+		pending.Consumers[name] = count
+	}
+	return pending, nil
+}
+
+// XPendingExt returns the pending entries of the group between the IDs.
+// https://redis.io/docs/latest/commands/xpending/
+func (c *Client) XPendingExt(ctx context.Context, a XPendingExtArgs) ([]XPendingExt, error) {
+	args := []string{"XPENDING", a.Stream, a.Group}
+	if a.Idle > 0 {
+		args = append(args, "IDLE", formatInt(a.Idle.Milliseconds()))
+	}
+	args = append(args, a.Start, a.End, formatInt(a.Count))
+	if a.Consumer != "" {
+		args = append(args, a.Consumer)
+	}
+
+	elements, err := toArray(c.call(ctx, args...))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]XPendingExt, len(elements))
+	for idx, element := range elements {
+		fields, err := toArray(element, nil)
+		if err != nil || len(fields) != 4 {
+			return nil, unexpectedReply(element)
+		}
+		id, idErr := toString(fields[0], nil)
+		consumer, consumerErr := toString(fields[1], nil)
+		idle, idleErr := toInt(fields[2], nil)
+		retryCount, retryErr := toInt(fields[3], nil)
+		if idErr != nil || consumerErr != nil || idleErr != nil || retryErr != nil {
+			return nil, unexpectedReply(element)
+		}
+		entries[idx] = XPendingExt{
+			ID:         id,
+			Consumer:   consumer,
+			Idle:       time.Duration(idle) * time.Millisecond,
+			RetryCount: retryCount,
+		}
+	}
+	return entries, nil
+}
+
+// XClaim transfers the pending entries to the consumer, and returns the entries claimed.
+// https://redis.io/docs/latest/commands/xclaim/
+func (c *Client) XClaim(ctx context.Context, a XClaimArgs) ([]XMessage, error) {
+	return toXMessages(c.call(ctx, a.args(false)...))
+}
+
+// XClaimJustID transfers the pending entries to the consumer, and returns the IDs of the entries claimed
+// without incrementing their delivery count.
+// https://redis.io/docs/latest/commands/xclaim/
+func (c *Client) XClaimJustID(ctx context.Context, a XClaimArgs) ([]string, error) {
+	return toStrings(c.call(ctx, a.args(true)...))
+}
+
+// XAutoClaim transfers to the consumer the pending entries idle for at least MinIdle, scanning from Start.
+// https://redis.io/docs/latest/commands/xautoclaim/
+func (c *Client) XAutoClaim(ctx context.Context, a XAutoClaimArgs) (XAutoClaim, error) {
+	args := []string{"XAUTOCLAIM", a.Stream, a.Group, a.Consumer, formatInt(a.MinIdle.Milliseconds()), a.Start}
+	if a.Count > 0 {
+		args = append(args, "COUNT", formatInt(a.Count))
+	}
+
+	elements, err := toArray(c.call(ctx, args...))
+	if err != nil {
+		return XAutoClaim{}, err
+	}
+	if len(elements) != 3 {
+		return XAutoClaim{}, unexpectedReply(data.Array{Elements: elements})
+	}
+	result := XAutoClaim{}
+	if result.Next, err = toString(elements[0], nil); err != nil {
+		return XAutoClaim{}, err
+	}
+	if result.Messages, err = toXMessages(elements[1], nil); err != nil {
+		return XAutoClaim{}, err
+	}
+	if result.Deleted, err = toStrings(elements[2], nil); err != nil {
+		return XAutoClaim{}, err
+	}
+	return result, nil
+}
+
+// XInfoStream returns the details of the stream. the FULL form of the command is left to Do.
+// https://redis.io/docs/latest/commands/xinfo-stream/
+func (c *Client) XInfoStream(ctx context.Context, stream string) (XInfoStream, error) {
+	fields, err := toFields(c.call(ctx, "XINFO", "STREAM", stream))
+	if err != nil {
+		return XInfoStream{}, err
+	}
+
+	info := XInfoStream{}
+	for name, target := range map[string]*int64{
+		"length":           &info.Length,
+		"radix-tree-keys":  &info.RadixTreeKeys,
+		"radix-tree-nodes": &info.RadixTreeNodes,
+		"entries-added":    &info.EntriesAdded,
+		"groups":           &info.Groups,
+	} {
+		if *target, err = toInt(fields[name], nil); err != nil {
+			return XInfoStream{}, err
+		}
+	}
+	for name, target := range map[string]*string{
+		"last-generated-id":       &info.LastGeneratedID,
+		"max-deleted-entry-id":    &info.MaxDeletedEntryID,
+		"recorded-first-entry-id": &info.RecordedFirstEntryID,
+	} {
+		if *target, err = toString(fields[name], nil); err != nil {
+			return XInfoStream{}, err
+		}
+	}
+	for name, target := range map[string]**XMessage{
+		"first-entry": &info.FirstEntry,
+		"last-entry":  &info.LastEntry,
+	} {
+		if _, ok := fields[name].(data.Null); ok {
+			continue
+		}
+		entry, err := toXMessage(fields[name])
+		if err != nil {
+			return XInfoStream{}, err
+		}
+		*target = &entry
+	}
+	return info, nil
+}
+
+// https://redis.io/docs/latest/commands/xinfo-groups/
+func (c *Client) XInfoGroups(ctx context.Context, stream string) ([]XInfoGroup, error) {
+	elements, err := toArray(c.call(ctx, "XINFO", "GROUPS", stream))
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]XInfoGroup, len(elements))
+	for idx, element := range elements {
+		fields, err := toFields(element, nil)
+		if err != nil {
+			return nil, err
+		}
+		group := &groups[idx]
+		if group.Name, err = toString(fields["name"], nil); err != nil {
+			return nil, err
+		}
+		if group.Consumers, err = toInt(fields["consumers"], nil); err != nil {
+			return nil, err
+		}
+		if group.Pending, err = toInt(fields["pending"], nil); err != nil {
+			return nil, err
+		}
+		if group.LastDeliveredID, err = toString(fields["last-delivered-id"], nil); err != nil {
+			return nil, err
+		}
+		if group.EntriesRead, err = optionalInt(fields["entries-read"]); err != nil {
+			return nil, err
+		}
+		if group.Lag, err = optionalInt(fields["lag"]); err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+// https://redis.io/docs/latest/commands/xinfo-consumers/
+func (c *Client) XInfoConsumers(ctx context.Context, stream string, group string) ([]XInfoConsumer, error) {
+	elements, err := toArray(c.call(ctx, "XINFO", "CONSUMERS", stream, group))
+	if err != nil {
+		return nil, err
+	}
+	consumers := make([]XInfoConsumer, len(elements))
+	for idx, element := range elements {
+		fields, err := toFields(element, nil)
+		if err != nil {
+			return nil, err
+		}
+		name, nameErr := toString(fields["name"], nil)
+		pending, pendingErr := toInt(fields["pending"], nil)
+		idle, idleErr := toInt(fields["idle"], nil)
+		inactive, inactiveErr := toInt(fields["inactive"], nil)
+		if nameErr != nil || pendingErr != nil || idleErr != nil || inactiveErr != nil {
+			return nil, unexpectedReply(element)
+		}
+		consumers[idx] = XInfoConsumer{
+			Name:     name,
+			Pending:  pending,
+			Idle:     time.Duration(idle) * time.Millisecond,
+			Inactive: time.Duration(inactive) * time.Millisecond,
+		}
+		if inactive < 0 {
+			consumers[idx].Inactive = -1
+		}
+	}
+	return consumers, nil
+}
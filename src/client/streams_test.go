@@ -0,0 +1,169 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/client"
+)
+
+func TestStreamCommands(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	for _, id := range []string{"1-0", "2-0", "3-0"} {
+		added, err := c.XAdd(ctx, client.XAddArgs{Stream: "stream", ID: id, Values: []string{"n", id}})
+		assert.Nil(err)
+		assert.Equal(id, added)
+	}
+	_, err := c.XAdd(ctx, client.XAddArgs{Stream: "missing", NoMkStream: true, Values: []string{"n", "1"}})
+	assert.Equal(client.ErrNil, err)
+
+	length, err := c.XLen(ctx, "stream")
+	assert.Nil(err)
+	assert.Equal(int64(3), length)
+
+	messages, err := c.XRangeN(ctx, "stream", "-", "+", 2)
+	assert.Nil(err)
+	assert.Equal([]client.XMessage{
+		{ID: "1-0", Values: map[string]string{"n": "1-0"}},
+		{ID: "2-0", Values: map[string]string{"n": "2-0"}},
+	}, messages)
+	messages, err = c.XRevRange(ctx, "stream", "+", "2-0")
+	assert.Nil(err)
+	assert.Equal([]client.XMessage{
+		{ID: "3-0", Values: map[string]string{"n": "3-0"}},
+		{ID: "2-0", Values: map[string]string{"n": "2-0"}},
+	}, messages)
+
+	streams, err := c.XRead(ctx, client.XReadArgs{Streams: []string{"stream"}, IDs: []string{"2-0"}})
+	assert.Nil(err)
+	assert.Equal([]client.XStream{{
+		Stream:   "stream",
+		Messages: []client.XMessage{{ID: "3-0", Values: map[string]string{"n": "3-0"}}},
+	}}, streams)
+	_, err = c.XRead(ctx, client.XReadArgs{Streams: []string{"stream"}, IDs: []string{"$"}, Block: 10 * time.Millisecond})
+	assert.Equal(client.ErrNil, err)
+
+	info, err := c.XInfoStream(ctx, "stream")
+	assert.Nil(err)
+	assert.Equal(int64(3), info.Length)
+	assert.Equal("3-0", info.LastGeneratedID)
+	assert.Equal(&client.XMessage{ID: "1-0", Values: map[string]string{"n": "1-0"}}, info.FirstEntry)
+
+	deleted, err := c.XDel(ctx, "stream", "1-0")
+	assert.Nil(err)
+	assert.Equal(int64(1), deleted)
+	trimmed, err := c.XTrim(ctx, "stream", client.XTrimArgs{MaxLen: 1})
+	assert.Nil(err)
+	assert.Equal(int64(1), trimmed)
+	trimmed, err = c.XTrim(ctx, "stream", client.XTrimArgs{MinID: "4-0"})
+	assert.Nil(err)
+	assert.Equal(int64(1), trimmed)
+
+	info, err = c.XInfoStream(ctx, "stream")
+	assert.Nil(err)
+	assert.Equal(int64(0), info.Length)
+	assert.Nil(info.FirstEntry)
+	assert.Nil(info.LastEntry)
+}
+
+func TestStreamBlockingRead(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, err := c.XAdd(ctx, client.XAddArgs{Stream: "stream", ID: "1-0", Values: []string{"field", "value"}})
+		assert.Nil(err)
+	}()
+	streams, err := c.XRead(ctx, client.XReadArgs{Streams: []string{"stream"}, IDs: []string{"$"}, Block: -1})
+	assert.Nil(err)
+	assert.Equal([]client.XStream{{
+		Stream:   "stream",
+		Messages: []client.XMessage{{ID: "1-0", Values: map[string]string{"field": "value"}}},
+	}}, streams)
+}
+
+func TestStreamGroupCommands(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+	c := newClient(t)
+
+	require.Nil(c.XGroupCreate(ctx, "stream", "group", "0", true))
+	created, err := c.XGroupCreateConsumer(ctx, "stream", "group", "idle")
+	assert.Nil(err)
+	assert.True(created)
+	for _, id := range []string{"1-0", "2-0", "3-0"} {
+		_, err := c.XAdd(ctx, client.XAddArgs{Stream: "stream", ID: id, Values: []string{"n", id}})
+		require.Nil(err)
+	}
+
+	streams, err := c.XReadGroup(ctx, client.XReadGroupArgs{
+		Group:     "group",
+		Consumer:  "alice",
+		XReadArgs: client.XReadArgs{Streams: []string{"stream"}, IDs: []string{">"}, Count: 2},
+	})
+	assert.Nil(err)
+	require.Len(streams, 1)
+	assert.Len(streams[0].Messages, 2)
+
+	pending, err := c.XPending(ctx, "stream", "group")
+	assert.Nil(err)
+	assert.Equal(client.XPending{Count: 2, Lower: "1-0", Higher: "2-0", Consumers: map[string]int64{"alice": 2}}, pending)
+
+	entries, err := c.XPendingExt(ctx, client.XPendingExtArgs{Stream: "stream", Group: "group", Start: "-", End: "+", Count: 10})
+	assert.Nil(err)
+	require.Len(entries, 2)
+	assert.Equal("alice", entries[0].Consumer)
+	assert.Equal(int64(1), entries[0].RetryCount)
+
+	acked, err := c.XAck(ctx, "stream", "group", "1-0")
+	assert.Nil(err)
+	assert.Equal(int64(1), acked)
+
+	claimed, err := c.XClaim(ctx, client.XClaimArgs{Stream: "stream", Group: "group", Consumer: "bob", IDs: []string{"2-0"}})
+	assert.Nil(err)
+	assert.Equal([]client.XMessage{{ID: "2-0", Values: map[string]string{"n": "2-0"}}}, claimed)
+	ids, err := c.XClaimJustID(ctx, client.XClaimArgs{Stream: "stream", Group: "group", Consumer: "alice", IDs: []string{"2-0"}})
+	assert.Nil(err)
+	assert.Equal([]string{"2-0"}, ids)
+
+	// the entry deleted while pending is reported by XAUTOCLAIM and removed from the group
+	_, err = c.XDel(ctx, "stream", "2-0")
+	require.Nil(err)
+	autoClaim, err := c.XAutoClaim(ctx, client.XAutoClaimArgs{Stream: "stream", Group: "group", Consumer: "bob", Start: "0"})
+	assert.Nil(err)
+	assert.Equal(client.XAutoClaim{Next: "0-0", Messages: []client.XMessage{}, Deleted: []string{"2-0"}}, autoClaim)
+
+	groups, err := c.XInfoGroups(ctx, "stream")
+	assert.Nil(err)
+	require.Len(groups, 1)
+	assert.Equal("group", groups[0].Name)
+	assert.Equal(int64(3), groups[0].Consumers)
+	assert.Equal(int64(0), groups[0].Pending)
+	assert.Equal("2-0", groups[0].LastDeliveredID)
+
+	consumers, err := c.XInfoConsumers(ctx, "stream", "group")
+	assert.Nil(err)
+	require.Len(consumers, 3)
+	assert.Equal("idle", consumers[2].Name)
+	assert.Equal(time.Duration(-1), consumers[2].Inactive)
+
+	pendingCount, err := c.XGroupDelConsumer(ctx, "stream", "group", "alice")
+	assert.Nil(err)
+	assert.Equal(int64(0), pendingCount)
+	require.Nil(c.XGroupSetID(ctx, "stream", "group", "$"))
+	destroyed, err := c.XGroupDestroy(ctx, "stream", "group")
+	assert.Nil(err)
+	assert.True(destroyed)
+
+	err = c.XGroupCreate(ctx, "missing", "group", "$", false)
+	assert.IsType(client.Error(""), err)
+}
@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// LCSMatch is a match of LCS IDX, with the ranges of the match in both strings.
+type LCSMatch struct {
+	A   [2]int64
+	B   [2]int64
+	Len int64
+}
+
+// LCSIndexes is the reply of LCS IDX.
+type LCSIndexes struct {
+	Matches []LCSMatch
+	Len     int64
+}
+
+func formatInt(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// https://redis.io/docs/latest/commands/get/
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	return toString(c.call(ctx, "GET", key))
+}
+
+// Set sets the value of the key, which expires after the expiration when it is positive.
+// https://redis.io/docs/latest/commands/set/
+func (c *Client) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	if expiration > 0 {
+		return toOK(c.call(ctx, "SET", key, value, "PX", formatInt(expiration.Milliseconds())))
+	}
+	return toOK(c.call(ctx, "SET", key, value))
+}
+
+// SetAt sets the value of the key, which expires at the given time.
+// https://redis.io/docs/latest/commands/set/
+func (c *Client) SetAt(ctx context.Context, key string, value string, expiresAt time.Time) error {
+	return toOK(c.call(ctx, "SET", key, value, "PXAT", formatInt(expiresAt.UnixMilli())))
+}
+
+// https://redis.io/docs/latest/commands/setnx/
+func (c *Client) SetNX(ctx context.Context, key string, value string) (bool, error) {
+	return toBool(c.call(ctx, "SETNX", key, value))
+}
+
+// SetEx sets the value of the key, which expires after the expiration in seconds.
+// https://redis.io/docs/latest/commands/setex/
+func (c *Client) SetEx(ctx context.Context, key string, value string, expiration time.Duration) error {
+	return toOK(c.call(ctx, "SETEX", key, formatInt(int64(expiration.Seconds())), value))
+}
+
+// PSetEx sets the value of the key, which expires after the expiration in milliseconds.
+// https://redis.io/docs/latest/commands/psetex/
+func (c *Client) PSetEx(ctx context.Context, key string, value string, expiration time.Duration) error {
+	return toOK(c.call(ctx, "PSETEX", key, formatInt(expiration.Milliseconds()), value))
+}
+
+// https://redis.io/docs/latest/commands/getset/
+func (c *Client) GetSet(ctx context.Context, key string, value string) (string, error) {
+	return toString(c.call(ctx, "GETSET", key, value))
+}
+
+// https://redis.io/docs/latest/commands/getdel/
+func (c *Client) GetDel(ctx context.Context, key string) (string, error) {
+	return toString(c.call(ctx, "GETDEL", key))
+}
+
+// GetEx returns the value of the key, which then expires after the expiration when it is positive.
+// https://redis.io/docs/latest/commands/getex/
+func (c *Client) GetEx(ctx context.Context, key string, expiration time.Duration) (string, error) {
+	if expiration > 0 {
+		return toString(c.call(ctx, "GETEX", key, "PX", formatInt(expiration.Milliseconds())))
+	}
+	return toString(c.call(ctx, "GETEX", key))
+}
+
+// GetPersist returns the value of the key, which then no longer expires.
+// https://redis.io/docs/latest/commands/getex/
+func (c *Client) GetPersist(ctx context.Context, key string) (string, error) {
+	return toString(c.call(ctx, "GETEX", key, "PERSIST"))
+}
+
+// MGet returns the values of the keys, which are nil for the keys that don't exist.
+// https://redis.io/docs/latest/commands/mget/
+func (c *Client) MGet(ctx context.Context, keys ...string) ([]*string, error) {
+	return toOptionalStrings(c.call(ctx, append([]string{"MGET"}, keys...)...))
+}
+
+// MSet sets the keys to the values, given as alternating keys and values.
+// https://redis.io/docs/latest/commands/mset/
+func (c *Client) MSet(ctx context.Context, pairs ...string) error {
+	return toOK(c.call(ctx, append([]string{"MSET"}, pairs...)...))
+}
+
+// MSetNX sets the keys to the values, given as alternating keys and values, unless any of the keys exists.
+// https://redis.io/docs/latest/commands/msetnx/
+func (c *Client) MSetNX(ctx context.Context, pairs ...string) (bool, error) {
+	return toBool(c.call(ctx, append([]string{"MSETNX"}, pairs...)...))
+}
+
+// https://redis.io/docs/latest/commands/append/
+func (c *Client) Append(ctx context.Context, key string, value string) (int64, error) {
+	return toInt(c.call(ctx, "APPEND", key, value))
+}
+
+// https://redis.io/docs/latest/commands/strlen/
+func (c *Client) StrLen(ctx context.Context, key string) (int64, error) {
+	return toInt(c.call(ctx, "STRLEN", key))
+}
+
+// https://redis.io/docs/latest/commands/getrange/
+func (c *Client) GetRange(ctx context.Context, key string, start int64, end int64) (string, error) {
+	return toString(c.call(ctx, "GETRANGE", key, formatInt(start), formatInt(end)))
+}
+
+// https://redis.io/docs/latest/commands/setrange/
+func (c *Client) SetRange(ctx context.Context, key string, offset int64, value string) (int64, error) {
+	return toInt(c.call(ctx, "SETRANGE", key, formatInt(offset), value))
+}
+
+// https://redis.io/docs/latest/commands/incr/
+func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
+	return toInt(c.call(ctx, "INCR", key))
+}
+
+// https://redis.io/docs/latest/commands/decr/
+func (c *Client) Decr(ctx context.Context, key string) (int64, error) {
+	return toInt(c.call(ctx, "DECR", key))
+}
+
+// https://redis.io/docs/latest/commands/incrby/
+func (c *Client) IncrBy(ctx context.Context, key string, increment int64) (int64, error) {
+	return toInt(c.call(ctx, "INCRBY", key, formatInt(increment)))
+}
+
+// https://redis.io/docs/latest/commands/decrby/
+func (c *Client) DecrBy(ctx context.Context, key string, decrement int64) (int64, error) {
+	return toInt(c.call(ctx, "DECRBY", key, formatInt(decrement)))
+}
+
+// https://redis.io/docs/latest/commands/incrbyfloat/
+func (c *Client) IncrByFloat(ctx context.Context, key string, increment float64) (float64, error) {
+	return toFloat(c.call(ctx, "INCRBYFLOAT", key, formatFloat(increment)))
+}
+
+// https://redis.io/docs/latest/commands/exists/
+func (c *Client) Exists(ctx context.Context, keys ...string) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"EXISTS"}, keys...)...))
+}
+
+// https://redis.io/docs/latest/commands/del/
+func (c *Client) Del(ctx context.Context, keys ...string) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"DEL"}, keys...)...))
+}
+
+// https://redis.io/docs/latest/commands/lpush/
+func (c *Client) LPush(ctx context.Context, key string, elements ...string) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"LPUSH", key}, elements...)...))
+}
+
+// https://redis.io/docs/latest/commands/rpush/
+func (c *Client) RPush(ctx context.Context, key string, elements ...string) (int64, error) {
+	return toInt(c.call(ctx, append([]string{"RPUSH", key}, elements...)...))
+}
+
+// LCS returns the longest common subsequence of the values of the keys.
+// https://redis.io/docs/latest/commands/lcs/
+func (c *Client) LCS(ctx context.Context, key1 string, key2 string) (string, error) {
+	return toString(c.call(ctx, "LCS", key1, key2))
+}
+
+// LCSLen returns the length of the longest common subsequence of the values of the keys.
+// https://redis.io/docs/latest/commands/lcs/
+func (c *Client) LCSLen(ctx context.Context, key1 string, key2 string) (int64, error) {
+	return toInt(c.call(ctx, "LCS", key1, key2, "LEN"))
+}
+
+// LCSIdx returns the matches of the longest common subsequence of the values of the keys, leaving out the
+// matches shorter than minMatchLen.
+// https://redis.io/docs/latest/commands/lcs/
+func (c *Client) LCSIdx(ctx context.Context, key1 string, key2 string, minMatchLen int64) (LCSIndexes, error) {
+	fields, err := toFields(c.call(ctx, "LCS", key1, key2, "IDX", "MINMATCHLEN", formatInt(minMatchLen), "WITHMATCHLEN"))
+	if err != nil {
+		return LCSIndexes{}, err
+	}
+
+	result := LCSIndexes{}
+	if result.Len, err = toInt(fields["len"], nil); err != nil {
+		return LCSIndexes{}, err
+	}
+	matches, err := toArray(fields["matches"], nil)
+	if err != nil {
+		return LCSIndexes{}, err
+	}
+	for _, match := range matches {
+		elements, err := toArray(match, nil)
+		if err != nil || len(elements) != 3 {
+			return LCSIndexes{}, unexpectedReply(match)
+		}
+		a, aErr := toInts(elements[0], nil)
+		b, bErr := toInts(elements[1], nil)
+		length, lenErr := toInt(elements[2], nil)
+		if aErr != nil || bErr != nil || lenErr != nil || len(a) != 2 || len(b) != 2 {
+			return LCSIndexes{}, unexpectedReply(match)
+		}
+		result.Matches = append(result.Matches, LCSMatch{A: [2]int64{a[0], a[1]}, B: [2]int64{b[0], b[1]}, Len: length})
+	}
+	return result, nil
+}
+
+// Dump returns the serialized value of the key, which RESTORE takes.
+// https://redis.io/docs/latest/commands/dump/
+func (c *Client) Dump(ctx context.Context, key string) (string, error) {
+	return toString(c.call(ctx, "DUMP", key))
+}
+
+// Restore creates the key from a value serialized by DUMP, which expires after the ttl when it is positive.
+// https://redis.io/docs/latest/commands/restore/
+func (c *Client) Restore(ctx context.Context, key string, ttl time.Duration, value string, replace bool) error {
+	args := []string{"RESTORE", key, formatInt(ttl.Milliseconds()), value}
+	if replace {
+		args = append(args, "REPLACE")
+	}
+	return toOK(c.call(ctx, args...))
+}
@@ -0,0 +1,500 @@
+package cluster
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// the messages of the bus. a node sends MEET to the nodes it meets with CLUSTER MEET and PING to the
+// nodes it knows, which both reply with PONG.
+const (
+	MSG_TYPE_MEET = "MEET"
+	MSG_TYPE_PING = "PING"
+	MSG_TYPE_PONG = "PONG"
+)
+
+const (
+	CLUSTER_CRON_PERIOD  = 100 * time.Millisecond
+	CLUSTER_PING_PERIOD  = 100 * time.Millisecond
+	CLUSTER_DIAL_TIMEOUT = time.Second
+	// the time a node met with CLUSTER MEET has to reply before it is forgotten, unless the node timeout is longer
+	CLUSTER_HANDSHAKE_TIMEOUT = time.Second
+)
+
+var errInvalidMessage = errors.New("invalid cluster bus message")
+
+// gossipEntry describes a node known to the sender of a message.
+type gossipEntry struct {
+	id      string
+	ip      string
+	port    int
+	busPort int
+	flags   []string
+}
+
+// message is a message of the bus. every message describes the sender along with the slots it serves,
+// and gossips about the other nodes it knows.
+type message struct {
+	typ          string
+	sender       string
+	port         int
+	busPort      int
+	currentEpoch uint64
+	configEpoch  uint64
+	slots        [CLUSTER_SLOTS / 8]byte
+	gossip       []gossipEntry
+}
+
+func (m *message) hasSlot(slot int) bool {
+	return m.slots[slot/8]&(1<<(slot%8)) != 0
+}
+
+// encode renders the message as a RESP array of bulk strings, the slots being a bitmap.
+func (m *message) encode() []byte {
+	fields := []string{
+		m.typ,
+		m.sender,
+		strconv.Itoa(m.port),
+		strconv.Itoa(m.busPort),
+		strconv.FormatUint(m.currentEpoch, 10),
+		strconv.FormatUint(m.configEpoch, 10),
+		string(m.slots[:]),
+		strconv.Itoa(len(m.gossip)),
+	}
+	for _, entry := range m.gossip {
+		fields = append(fields, entry.id, entry.ip, strconv.Itoa(entry.port), strconv.Itoa(entry.busPort), strings.Join(entry.flags, ","))
+	}
+
+	elements := make([]data.Message, len(fields))
+	for idx, field := range fields {
+		elements[idx] = data.BulkString{Data: field}
+	}
+	return []byte(data.Array{Elements: elements}.ToDataString())
+}
+
+// decodeMessage parses a message read from the bus.
+func decodeMessage(msg data.Message) (*message, error) {
+	array, ok := msg.(data.Array)
+	if !ok || len(array.Elements) < 8 {
+		return nil, errInvalidMessage
+	}
+	fields := make([]string, len(array.Elements))
+	for idx, element := range array.Elements {
+		bulk, ok := element.(data.BulkString)
+		if !ok {
+			return nil, errInvalidMessage
+		}
+		fields[idx] = bulk.Data
+	}
+
+	m := &message{typ: fields[0], sender: fields[1]}
+	var numGossip int
+	var errs [5]error
+	m.port, errs[0] = strconv.Atoi(fields[2])
+	m.busPort, errs[1] = strconv.Atoi(fields[3])
+	m.currentEpoch, errs[2] = strconv.ParseUint(fields[4], 10, 64)
+	m.configEpoch, errs[3] = strconv.ParseUint(fields[5], 10, 64)
+	numGossip, errs[4] = strconv.Atoi(fields[7])
+	if errors.Join(errs[:]...) != nil || len(fields[6]) != len(m.slots) || numGossip < 0 || len(fields) != 8+5*numGossip {
+		return nil, errInvalidMessage
+	}
+	copy(m.slots[:], fields[6])
+
+	for idx := 8; idx < len(fields); idx += 5 {
+		entry := gossipEntry{id: fields[idx], ip: fields[idx+1]}
+		var portErr, busPortErr error
+		entry.port, portErr = strconv.Atoi(fields[idx+2])
+		entry.busPort, busPortErr = strconv.Atoi(fields[idx+3])
+		if portErr != nil || busPortErr != nil {
+			return nil, errInvalidMessage
+		}
+		if fields[idx+4] != "" {
+			entry.flags = strings.Split(fields[idx+4], ",")
+		}
+		m.gossip = append(m.gossip, entry)
+	}
+	return m, nil
+}
+
+// messageLocked builds a message of this node for the given receiver, gossiping about every other node
+// that completed its handshake.
+func (c *Cluster) messageLocked(typ string, receiver *node) *message {
+	m := &message{
+		typ:          typ,
+		sender:       c.myself.id,
+		port:         c.myself.port,
+		busPort:      c.myself.busPort,
+		currentEpoch: c.currentEpoch,
+		configEpoch:  c.myself.configEpoch,
+	}
+	for slot, owner := range c.owners {
+		if owner == c.myself {
+			m.slots[slot/8] |= 1 << (slot % 8)
+		}
+	}
+	for _, n := range c.sortedNodesLocked() {
+		if n == c.myself || n == receiver || n.handshake {
+			continue
+		}
+		m.gossip = append(m.gossip, gossipEntry{id: n.id, ip: n.ip, port: n.port, busPort: n.busPort, flags: c.flagsLocked(n)})
+	}
+	c.messagesSent++
+	return m
+}
+
+func hostOf(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// process handles a message received on a connection, link being the node at the other end of the
+// connection when this node opened it. it returns the reply to send back, if any.
+func (c *Cluster) process(m *message, conn net.Conn, link *node) *message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messagesReceived++
+
+	// a node learns its own address from the connections of the other nodes
+	if c.myself.ip == "" {
+		c.myself.ip = hostOf(conn.LocalAddr())
+	}
+
+	sender := c.nodes[m.sender]
+	if link != nil && link.handshake && m.typ == MSG_TYPE_PONG {
+		// the reply to MEET tells the actual ID of the node, which may already be known under it
+		c.removeNodeLocked(link)
+		if sender == nil {
+			link.id, link.handshake, link.removed = m.sender, false, false
+			c.nodes[link.id] = link
+			sender = link
+		}
+	}
+	if sender == nil && m.typ == MSG_TYPE_MEET {
+		sender = c.addNodeLocked(m.sender, hostOf(conn.RemoteAddr()), m.port, m.busPort)
+	}
+
+	if sender != nil && sender != c.myself && !sender.handshake {
+		if m.typ == MSG_TYPE_PONG && sender == link {
+			sender.pingSent = time.Time{}
+			sender.pongReceived = time.Now()
+			sender.pfail, sender.fail = false, false
+			clear(sender.failReports)
+		}
+		c.updateFromLocked(sender, m)
+	}
+
+	if m.typ == MSG_TYPE_PONG {
+		return nil
+	}
+	return c.messageLocked(MSG_TYPE_PONG, sender)
+}
+
+// updateFromLocked applies what a message tells about its sender and the nodes it knows.
+func (c *Cluster) updateFromLocked(sender *node, m *message) {
+	c.currentEpoch = max(c.currentEpoch, m.currentEpoch)
+	sender.port, sender.busPort = m.port, m.busPort
+	sender.configEpoch = m.configEpoch
+
+	// nodes must end up with distinct config epochs for their claims to be ordered: on a collision, the
+	// node with the smaller ID takes a new epoch
+	if sender.configEpoch == c.myself.configEpoch && sender.id > c.myself.id {
+		c.currentEpoch++
+		c.myself.configEpoch = c.currentEpoch
+	}
+
+	for slot := 0; slot < CLUSTER_SLOTS; slot++ {
+		if !m.hasSlot(slot) {
+			continue
+		}
+		owner := c.owners[slot]
+		if owner == sender || c.importing[slot] != nil {
+			continue
+		}
+		if owner == nil || owner.configEpoch < sender.configEpoch {
+			c.owners[slot] = sender
+			if owner == c.myself {
+				delete(c.migrating, slot)
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, entry := range m.gossip {
+		if entry.id == c.myself.id {
+			continue
+		}
+		n, ok := c.nodes[entry.id]
+		if !ok {
+			if !slices.Contains(entry.flags, NODE_FLAG_FAIL) {
+				c.addNodeLocked(entry.id, entry.ip, entry.port, entry.busPort)
+			}
+			continue
+		}
+		if n == c.myself || n.handshake {
+			continue
+		}
+		if slices.Contains(entry.flags, NODE_FLAG_PFAIL) || slices.Contains(entry.flags, NODE_FLAG_FAIL) {
+			n.failReports[sender.id] = now
+			c.checkFailLocked(n)
+		} else {
+			delete(n.failReports, sender.id)
+		}
+	}
+}
+
+// checkFailLocked marks a node unreachable from this node as failing once a majority of the nodes serving
+// slots agree that it is unreachable.
+func (c *Cluster) checkFailLocked(n *node) {
+	if !n.pfail || n.fail {
+		return
+	}
+
+	expiry := 2 * c.nodeTimeout()
+	failures := 1
+	for id, reportedAt := range n.failReports {
+		if time.Since(reportedAt) > expiry {
+			delete(n.failReports, id)
+			continue
+		}
+		failures++
+	}
+	if failures >= c.sizeLocked()/2+1 {
+		n.fail = true
+		slog.Warn("marking cluster node as failing", "node", n.id)
+	}
+}
+
+// cron starts the links to the known nodes, forgets the handshakes that did not complete and flags the
+// nodes that did not reply in time.
+func (c *Cluster) cron() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(CLUSTER_CRON_PERIOD)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		c.mu.Lock()
+		now := time.Now()
+		timeout := c.nodeTimeout()
+		for _, n := range c.sortedNodesLocked() {
+			if n == c.myself {
+				continue
+			}
+			if n.handshake && now.Sub(n.createdAt) > max(timeout, CLUSTER_HANDSHAKE_TIMEOUT) {
+				c.removeNodeLocked(n)
+				continue
+			}
+			if !n.linked {
+				n.linked = true
+				c.wg.Add(1)
+				go c.runLink(n)
+			}
+			if !n.handshake && now.Sub(n.pongReceived) > timeout {
+				n.pfail = true
+				c.checkFailLocked(n)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+// track records an open connection so that Stop can close it. it reports false once the bus is stopped.
+func (c *Cluster) track(conn net.Conn) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.stopCh:
+		return false
+	default:
+		c.conns[conn] = struct{}{}
+		return true
+	}
+}
+
+func (c *Cluster) untrack(conn net.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.conns, conn)
+	_ = conn.Close()
+}
+
+// wait sleeps for the given duration and reports false when the bus is stopped in the meantime.
+func (c *Cluster) wait(d time.Duration) bool {
+	select {
+	case <-c.stopCh:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// runLink pings a node over a connection opened by this node until the node is forgotten.
+func (c *Cluster) runLink(n *node) {
+	defer c.wg.Done()
+
+	var conn net.Conn
+	var reader *bufio.Reader
+	defer func() {
+		if conn != nil {
+			c.untrack(conn)
+		}
+	}()
+
+	for {
+		c.mu.Lock()
+		removed := n.removed
+		addr := net.JoinHostPort(n.ip, strconv.Itoa(n.busPort))
+		c.mu.Unlock()
+		if removed {
+			return
+		}
+
+		if conn == nil {
+			dialed, err := net.DialTimeout("tcp", addr, CLUSTER_DIAL_TIMEOUT)
+			if err != nil || !c.track(dialed) {
+				if dialed != nil {
+					_ = dialed.Close()
+				}
+				if !c.wait(CLUSTER_PING_PERIOD) {
+					return
+				}
+				continue
+			}
+			conn, reader = dialed, bufio.NewReader(dialed)
+		}
+
+		if err := c.ping(n, conn, reader); err != nil {
+			slog.Debug("lost the link with a cluster node", "addr", addr, "error", err.Error())
+			c.untrack(conn)
+			conn = nil
+			c.mu.Lock()
+			n.connected = false
+			c.mu.Unlock()
+		}
+		if !c.wait(CLUSTER_PING_PERIOD) {
+			return
+		}
+	}
+}
+
+// ping sends PING, or MEET during the handshake, to a node and processes its reply.
+func (c *Cluster) ping(n *node, conn net.Conn, reader *bufio.Reader) error {
+	c.mu.Lock()
+	typ := MSG_TYPE_PING
+	if n.handshake {
+		typ = MSG_TYPE_MEET
+	}
+	m := c.messageLocked(typ, n)
+	if n.pingSent.IsZero() {
+		n.pingSent = time.Now()
+	}
+	timeout := c.nodeTimeout()
+	c.mu.Unlock()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Write(m.encode()); err != nil {
+		return err
+	}
+	raw, err := data.ReadMessage(reader)
+	if err != nil {
+		return err
+	}
+	reply, err := decodeMessage(raw)
+	if err != nil {
+		return err
+	}
+	if reply.typ != MSG_TYPE_PONG {
+		return fmt.Errorf("unexpected %s reply", reply.typ)
+	}
+
+	c.mu.Lock()
+	n.connected = true
+	c.mu.Unlock()
+	c.process(reply, conn, n)
+	return nil
+}
+
+// serve replies to the messages received on a connection opened by another node.
+func (c *Cluster) serve(conn net.Conn) {
+	defer c.wg.Done()
+	defer c.untrack(conn)
+
+	reader := bufio.NewReader(conn)
+	for {
+		raw, err := data.ReadMessage(reader)
+		if err != nil {
+			return
+		}
+		m, err := decodeMessage(raw)
+		if err != nil {
+			slog.Debug("closing cluster bus connection", "addr", conn.RemoteAddr().String(), "error", err.Error())
+			return
+		}
+		if reply := c.process(m, conn, nil); reply != nil {
+			if _, err := conn.Write(reply.encode()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Start listens on the bus port and starts talking with the other nodes.
+func (c *Cluster) Start() error {
+	listener, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(c.myself.busPort)))
+	if err != nil {
+		return err
+	}
+	c.listener = listener
+
+	c.wg.Add(2)
+	go c.cron()
+	go func() {
+		defer c.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if !c.track(conn) {
+				_ = conn.Close()
+				return
+			}
+			c.wg.Add(1)
+			go c.serve(conn)
+		}
+	}()
+	return nil
+}
+
+// Stop closes the bus and waits for the links to be done.
+func (c *Cluster) Stop() {
+	c.mu.Lock()
+	close(c.stopCh)
+	if c.listener != nil {
+		_ = c.listener.Close()
+	}
+	for conn := range c.conns {
+		_ = conn.Close()
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+}
@@ -0,0 +1,593 @@
+package cluster
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/config"
+)
+
+// the cluster follows the design of redis cluster: the keyspace is split into hash slots, each served by one
+// node. nodes talk to each other over a separate bus where they ping each other, share the slots they serve
+// and gossip about the other nodes they know, so that a node only needs to meet one member of a cluster to
+// learn about all of it. conflicting claims on a slot are settled by the config epoch of the claiming nodes.
+
+// the flags of a node, as shown by CLUSTER NODES.
+const (
+	NODE_FLAG_MYSELF    = "myself"
+	NODE_FLAG_MASTER    = "master"
+	NODE_FLAG_PFAIL     = "fail?"
+	NODE_FLAG_FAIL      = "fail"
+	NODE_FLAG_HANDSHAKE = "handshake"
+)
+
+const (
+	CLUSTER_STATE_OK   = "ok"
+	CLUSTER_STATE_FAIL = "fail"
+
+	NODE_ID_LENGTH = 40
+	// the bus port of a node is its port plus this offset, unless cluster-port is set
+	BUS_PORT_OFFSET = 10000
+)
+
+func newNodeID() string {
+	id := make([]byte, NODE_ID_LENGTH/2)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// node is a member of the cluster as seen by this node.
+type node struct {
+	id          string
+	ip          string
+	port        int
+	busPort     int
+	configEpoch uint64
+	createdAt   time.Time
+	// handshake is set for nodes met with CLUSTER MEET until they reply, which tells their actual ID
+	handshake    bool
+	pfail        bool
+	fail         bool
+	pingSent     time.Time
+	pongReceived time.Time
+	// failReports holds the time other nodes last reported the node as failing, by their ID
+	failReports map[string]time.Time
+	// linked is set once a link to the node is running, and connected while the link has a connection
+	linked    bool
+	connected bool
+	removed   bool
+}
+
+func (n *node) addr() string {
+	return net.JoinHostPort(n.ip, strconv.Itoa(n.port))
+}
+
+// NodeInfo describes a node of the cluster.
+type NodeInfo struct {
+	ID           string
+	IP           string
+	Port         int
+	BusPort      int
+	Flags        []string
+	ConfigEpoch  uint64
+	PingSent     time.Time
+	PongReceived time.Time
+	Connected    bool
+	Slots        []SlotRange
+}
+
+// Failed reports whether the node is considered failing by the cluster.
+func (n NodeInfo) Failed() bool {
+	return slices.Contains(n.Flags, NODE_FLAG_FAIL)
+}
+
+// SlotRange is a range of consecutive slots, both ends included.
+type SlotRange struct {
+	Start int
+	End   int
+}
+
+// SlotOwner is a range of slots along with the node serving it.
+type SlotOwner struct {
+	SlotRange
+	Node NodeInfo
+}
+
+// Route tells how the commands on a slot are to be served: by this node, or by redirecting the client to
+// the address of another node.
+type Route struct {
+	Assigned bool
+	Mine     bool
+	Addr     string
+	// MigratingTo is the address of the node the slot is being migrated to by this node
+	MigratingTo string
+	// Importing is set when this node imports the slot from its owner
+	Importing bool
+}
+
+type Cluster struct {
+	cfg *config.Config
+
+	mu           sync.Mutex
+	myself       *node
+	nodes        map[string]*node
+	owners       [CLUSTER_SLOTS]*node
+	migrating    map[int]*node
+	importing    map[int]*node
+	currentEpoch uint64
+
+	messagesSent     int64
+	messagesReceived int64
+
+	listener net.Listener
+	stopCh   chan struct{}
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// New creates the cluster state of a node that knows no other node and serves no slot.
+func New(cfg *config.Config) *Cluster {
+	port := int(cfg.GetInt("port"))
+	busPort := int(cfg.GetInt("cluster-port"))
+	if busPort == 0 {
+		busPort = port + BUS_PORT_OFFSET
+	}
+
+	myself := &node{id: newNodeID(), port: port, busPort: busPort, createdAt: time.Now(), connected: true}
+	return &Cluster{
+		cfg:       cfg,
+		myself:    myself,
+		nodes:     map[string]*node{myself.id: myself},
+		migrating: make(map[int]*node),
+		importing: make(map[int]*node),
+		stopCh:    make(chan struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+func (c *Cluster) nodeTimeout() time.Duration {
+	return time.Duration(c.cfg.GetInt("cluster-node-timeout")) * time.Millisecond
+}
+
+func (c *Cluster) MyID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.myself.id
+}
+
+func checkSlot(slot int) error {
+	if slot < 0 || slot >= CLUSTER_SLOTS {
+		return fmt.Errorf("Invalid or out of range slot")
+	}
+	return nil
+}
+
+// AddSlots makes this node serve the given slots, which must all be unassigned.
+func (c *Cluster) AddSlots(slots []int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seen := make(map[int]bool, len(slots))
+	for _, slot := range slots {
+		if err := checkSlot(slot); err != nil {
+			return err
+		}
+		if seen[slot] {
+			return fmt.Errorf("Slot %d specified multiple times", slot)
+		}
+		seen[slot] = true
+		if c.owners[slot] != nil {
+			return fmt.Errorf("Slot %d is already busy", slot)
+		}
+	}
+
+	for _, slot := range slots {
+		c.owners[slot] = c.myself
+		delete(c.importing, slot)
+	}
+	return nil
+}
+
+// DelSlots forgets about the owners of the given slots, which must all be assigned.
+func (c *Cluster) DelSlots(slots []int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, slot := range slots {
+		if err := checkSlot(slot); err != nil {
+			return err
+		}
+		if c.owners[slot] == nil {
+			return fmt.Errorf("Slot %d is already unassigned", slot)
+		}
+	}
+
+	for _, slot := range slots {
+		c.owners[slot] = nil
+		delete(c.migrating, slot)
+		delete(c.importing, slot)
+	}
+	return nil
+}
+
+// knownNodeLocked returns the node with the given ID, excluding the ones still in handshake.
+func (c *Cluster) knownNodeLocked(id string) (*node, error) {
+	n, ok := c.nodes[id]
+	if !ok || n.handshake {
+		return nil, fmt.Errorf("I don't know about node %s", id)
+	}
+	return n, nil
+}
+
+// SetSlotMigrating marks a slot served by this node as being migrated to another node.
+func (c *Cluster) SetSlotMigrating(slot int, nodeID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := checkSlot(slot); err != nil {
+		return err
+	}
+	if c.owners[slot] != c.myself {
+		return fmt.Errorf("I'm not the owner of hash slot %d", slot)
+	}
+	target, err := c.knownNodeLocked(nodeID)
+	if err != nil {
+		return err
+	}
+	if target == c.myself {
+		return fmt.Errorf("I'm the owner of hash slot %d, it can't be migrated to myself", slot)
+	}
+	c.migrating[slot] = target
+	return nil
+}
+
+// SetSlotImporting marks a slot served by another node as being imported by this node.
+func (c *Cluster) SetSlotImporting(slot int, nodeID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := checkSlot(slot); err != nil {
+		return err
+	}
+	if c.owners[slot] == c.myself {
+		return fmt.Errorf("I'm already the owner of hash slot %d", slot)
+	}
+	source, err := c.knownNodeLocked(nodeID)
+	if err != nil {
+		return err
+	}
+	c.importing[slot] = source
+	return nil
+}
+
+// SetSlotStable clears the migration or the import of a slot.
+func (c *Cluster) SetSlotStable(slot int) error {
+	if err := checkSlot(slot); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.migrating, slot)
+	delete(c.importing, slot)
+	return nil
+}
+
+// SetSlotNode assigns a slot to a node, which is how a migration is completed. this node can only give away
+// a slot it serves once it holds no more keys of the slot. when this node takes over a slot it was importing,
+// it bumps its config epoch so that the rest of the cluster accepts its claim over the one of the former owner.
+func (c *Cluster) SetSlotNode(slot int, nodeID string, hasKeys bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := checkSlot(slot); err != nil {
+		return err
+	}
+	target, err := c.knownNodeLocked(nodeID)
+	if err != nil {
+		return err
+	}
+	if c.owners[slot] == c.myself && target != c.myself && hasKeys {
+		return fmt.Errorf("Can't assign hashslot %d to a different node while I still hold keys for this hash slot.", slot)
+	}
+
+	if !hasKeys {
+		delete(c.migrating, slot)
+	}
+	if target == c.myself && c.importing[slot] != nil {
+		delete(c.importing, slot)
+		c.bumpEpochLocked()
+	}
+	c.owners[slot] = target
+	return nil
+}
+
+// bumpEpochLocked gives this node a config epoch greater than the one of every other node, unless it
+// already has the greatest one.
+func (c *Cluster) bumpEpochLocked() {
+	maxEpoch := uint64(0)
+	for _, n := range c.nodes {
+		maxEpoch = max(maxEpoch, n.configEpoch)
+	}
+	if c.myself.configEpoch == 0 || c.myself.configEpoch != maxEpoch {
+		c.currentEpoch++
+		c.myself.configEpoch = c.currentEpoch
+	}
+}
+
+// Meet starts a handshake with the node at the given address, which then joins the cluster of this node.
+func (c *Cluster) Meet(ip string, port int, busPort int) error {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || port <= 0 || port > 65535 || busPort <= 0 || busPort > 65535 {
+		return fmt.Errorf("Invalid node address specified: %s:%d", ip, port)
+	}
+	ip = parsed.String()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, n := range c.nodes {
+		if n.handshake && n.ip == ip && n.port == port && n.busPort == busPort {
+			return nil
+		}
+	}
+	n := c.addNodeLocked(newNodeID(), ip, port, busPort)
+	n.handshake = true
+	return nil
+}
+
+func (c *Cluster) addNodeLocked(id string, ip string, port int, busPort int) *node {
+	now := time.Now()
+	n := &node{
+		id:           id,
+		ip:           ip,
+		port:         port,
+		busPort:      busPort,
+		createdAt:    now,
+		pongReceived: now,
+		failReports:  make(map[string]time.Time),
+	}
+	c.nodes[id] = n
+	return n
+}
+
+func (c *Cluster) removeNodeLocked(n *node) {
+	if c.nodes[n.id] == n {
+		delete(c.nodes, n.id)
+	}
+	n.removed = true
+}
+
+// Route returns how the commands on the given slot are served.
+func (c *Cluster) Route(slot int) Route {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	owner := c.owners[slot]
+	if owner == nil {
+		return Route{}
+	}
+	route := Route{Assigned: true, Mine: owner == c.myself, Addr: owner.addr(), Importing: c.importing[slot] != nil}
+	if target, ok := c.migrating[slot]; ok && route.Mine {
+		route.MigratingTo = target.addr()
+	}
+	return route
+}
+
+// sizeLocked returns the number of nodes serving slots.
+func (c *Cluster) sizeLocked() int {
+	size := 0
+	for _, n := range c.nodes {
+		if slices.Contains(c.owners[:], n) {
+			size++
+		}
+	}
+	return size
+}
+
+func (c *Cluster) stateLocked() string {
+	if !c.cfg.GetBool("cluster-require-full-coverage") {
+		return CLUSTER_STATE_OK
+	}
+	for _, owner := range c.owners {
+		if owner == nil || owner.fail {
+			return CLUSTER_STATE_FAIL
+		}
+	}
+	return CLUSTER_STATE_OK
+}
+
+// StateOK reports whether the cluster can serve commands, which by default requires every slot to be
+// served by a node that is not failing.
+func (c *Cluster) StateOK() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stateLocked() == CLUSTER_STATE_OK
+}
+
+func (c *Cluster) flagsLocked(n *node) []string {
+	flags := []string{}
+	if n == c.myself {
+		flags = append(flags, NODE_FLAG_MYSELF)
+	}
+	if n.handshake {
+		return append(flags, NODE_FLAG_HANDSHAKE)
+	}
+	flags = append(flags, NODE_FLAG_MASTER)
+	switch {
+	case n.fail:
+		flags = append(flags, NODE_FLAG_FAIL)
+	case n.pfail:
+		flags = append(flags, NODE_FLAG_PFAIL)
+	}
+	return flags
+}
+
+// slotRangesLocked returns the ranges of slots served by a node.
+func (c *Cluster) slotRangesLocked(n *node) []SlotRange {
+	ranges := []SlotRange{}
+	for slot := 0; slot < CLUSTER_SLOTS; slot++ {
+		if c.owners[slot] != n {
+			continue
+		}
+		if last := len(ranges) - 1; last >= 0 && ranges[last].End == slot-1 {
+			ranges[last].End = slot
+		} else {
+			ranges = append(ranges, SlotRange{Start: slot, End: slot})
+		}
+	}
+	return ranges
+}
+
+func (c *Cluster) nodeInfoLocked(n *node) NodeInfo {
+	return NodeInfo{
+		ID:           n.id,
+		IP:           n.ip,
+		Port:         n.port,
+		BusPort:      n.busPort,
+		Flags:        c.flagsLocked(n),
+		ConfigEpoch:  n.configEpoch,
+		PingSent:     n.pingSent,
+		PongReceived: n.pongReceived,
+		Connected:    n == c.myself || n.connected,
+		Slots:        c.slotRangesLocked(n),
+	}
+}
+
+// sortedNodesLocked returns the nodes ordered by ID.
+func (c *Cluster) sortedNodesLocked() []*node {
+	nodes := make([]*node, 0, len(c.nodes))
+	for _, n := range c.nodes {
+		nodes = append(nodes, n)
+	}
+	slices.SortFunc(nodes, func(a, b *node) int {
+		return cmp.Compare(a.id, b.id)
+	})
+	return nodes
+}
+
+// Nodes returns the nodes known to this node, ordered by ID.
+func (c *Cluster) Nodes() []NodeInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	nodes := []NodeInfo{}
+	for _, n := range c.sortedNodesLocked() {
+		nodes = append(nodes, c.nodeInfoLocked(n))
+	}
+	return nodes
+}
+
+// Slots returns the ranges of assigned slots in order, along with the nodes serving them.
+func (c *Cluster) Slots() []SlotOwner {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	owners := []SlotOwner{}
+	for slot := 0; slot < CLUSTER_SLOTS; slot++ {
+		owner := c.owners[slot]
+		if owner == nil {
+			continue
+		}
+		if last := len(owners) - 1; last >= 0 && owners[last].End == slot-1 && owners[last].Node.ID == owner.id {
+			owners[last].End = slot
+			continue
+		}
+		info := c.nodeInfoLocked(owner)
+		info.Slots = nil
+		owners = append(owners, SlotOwner{SlotRange: SlotRange{Start: slot, End: slot}, Node: info})
+	}
+	return owners
+}
+
+// Description renders the nodes in the format of CLUSTER NODES, one line per node.
+func (c *Cluster) Description() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var sb strings.Builder
+	for _, n := range c.sortedNodesLocked() {
+		info := c.nodeInfoLocked(n)
+		linkState := "disconnected"
+		if info.Connected {
+			linkState = "connected"
+		}
+		fmt.Fprintf(&sb, "%s %s@%d %s - %d %d %d %s",
+			n.id, n.addr(), n.busPort, strings.Join(info.Flags, ","),
+			unixMillis(n.pingSent), unixMillis(n.pongReceived), n.configEpoch, linkState)
+		for _, r := range info.Slots {
+			if r.Start == r.End {
+				fmt.Fprintf(&sb, " %d", r.Start)
+			} else {
+				fmt.Fprintf(&sb, " %d-%d", r.Start, r.End)
+			}
+		}
+		if n == c.myself {
+			for _, slot := range sortedSlots(c.migrating) {
+				fmt.Fprintf(&sb, " [%d->-%s]", slot, c.migrating[slot].id)
+			}
+			for _, slot := range sortedSlots(c.importing) {
+				fmt.Fprintf(&sb, " [%d-<-%s]", slot, c.importing[slot].id)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// unixMillis renders a time in milliseconds, with the zero time rendered as 0.
+func unixMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func sortedSlots(slots map[int]*node) []int {
+	keys := make([]int, 0, len(slots))
+	for slot := range slots {
+		keys = append(keys, slot)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Info renders the fields of CLUSTER INFO.
+func (c *Cluster) Info() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	assigned, pfail, fail := 0, 0, 0
+	for _, owner := range c.owners {
+		switch {
+		case owner == nil:
+			continue
+		case owner.fail:
+			fail++
+		case owner.pfail:
+			pfail++
+		}
+		assigned++
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "cluster_state:%s\r\n", c.stateLocked())
+	fmt.Fprintf(&sb, "cluster_slots_assigned:%d\r\n", assigned)
+	fmt.Fprintf(&sb, "cluster_slots_ok:%d\r\n", assigned-pfail-fail)
+	fmt.Fprintf(&sb, "cluster_slots_pfail:%d\r\n", pfail)
+	fmt.Fprintf(&sb, "cluster_slots_fail:%d\r\n", fail)
+	fmt.Fprintf(&sb, "cluster_known_nodes:%d\r\n", len(c.nodes))
+	fmt.Fprintf(&sb, "cluster_size:%d\r\n", c.sizeLocked())
+	fmt.Fprintf(&sb, "cluster_current_epoch:%d\r\n", c.currentEpoch)
+	fmt.Fprintf(&sb, "cluster_my_epoch:%d\r\n", c.myself.configEpoch)
+	fmt.Fprintf(&sb, "cluster_stats_messages_sent:%d\r\n", c.messagesSent)
+	fmt.Fprintf(&sb, "cluster_stats_messages_received:%d\r\n", c.messagesReceived)
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package cluster_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/cluster"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+)
+
+func TestKeySlot(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		key  string
+		want int
+	}{
+		{"123456789", 12739},
+		{"foo", 12182},
+		{"bar", 5061},
+		{"", 0},
+		{"{user1000}.following", cluster.KeySlot("user1000")},
+		{"{user1000}.followers", cluster.KeySlot("user1000")},
+		{"foo{}{bar}", 8363},
+		{"foo{{bar}}zap", cluster.KeySlot("{bar")},
+		{"foo{bar}{zap}", cluster.KeySlot("bar")},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.want, cluster.KeySlot(tc.key), tc.key)
+	}
+}
+
+func TestClusterSlots(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("port", "7000"))
+	c := cluster.New(cfg)
+
+	assert.Len(c.MyID(), cluster.NODE_ID_LENGTH)
+	assert.False(c.StateOK())
+	assert.Equal(cluster.Route{}, c.Route(0))
+
+	assert.EqualError(c.AddSlots([]int{cluster.CLUSTER_SLOTS}), "Invalid or out of range slot")
+	assert.EqualError(c.AddSlots([]int{1, 1}), "Slot 1 specified multiple times")
+	assert.EqualError(c.DelSlots([]int{1}), "Slot 1 is already unassigned")
+	require.Nil(c.AddSlots([]int{0, 1, 2, 5}))
+	assert.EqualError(c.AddSlots([]int{3, 2}), "Slot 2 is already busy")
+	assert.Equal(cluster.Route{Assigned: true, Mine: true, Addr: ":7000"}, c.Route(1))
+
+	assert.EqualError(c.SetSlotMigrating(3, c.MyID()), "I'm not the owner of hash slot 3")
+	assert.EqualError(c.SetSlotImporting(0, c.MyID()), "I'm already the owner of hash slot 0")
+	assert.EqualError(c.SetSlotMigrating(0, "nexist"), "I don't know about node nexist")
+	require.Nil(c.DelSlots([]int{5}))
+
+	owners := c.Slots()
+	require.Len(owners, 1)
+	assert.Equal(cluster.SlotRange{Start: 0, End: 2}, owners[0].SlotRange)
+	assert.Equal(c.MyID(), owners[0].Node.ID)
+
+	nodes := c.Nodes()
+	require.Len(nodes, 1)
+	assert.Equal([]string{cluster.NODE_FLAG_MYSELF, cluster.NODE_FLAG_MASTER}, nodes[0].Flags)
+	assert.Equal([]cluster.SlotRange{{Start: 0, End: 2}}, nodes[0].Slots)
+	assert.Equal(fmt.Sprintf("%s :7000@17000 myself,master - 0 0 0 connected 0-2\n", c.MyID()), c.Description())
+
+	ranges := []int{}
+	for slot := 3; slot < cluster.CLUSTER_SLOTS; slot++ {
+		ranges = append(ranges, slot)
+	}
+	require.Nil(c.AddSlots(ranges))
+	assert.True(c.StateOK())
+
+	info := c.Info()
+	assert.True(strings.HasPrefix(info, "cluster_state:ok\r\ncluster_slots_assigned:16384\r\ncluster_slots_ok:16384\r\n"))
+	assert.Contains(info, "cluster_known_nodes:1\r\ncluster_size:1\r\n")
+
+	// the full coverage is only required when configured
+	require.Nil(c.DelSlots([]int{0}))
+	assert.False(c.StateOK())
+	require.Nil(cfg.Set("cluster-require-full-coverage", "no"))
+	assert.True(c.StateOK())
+}
+
+func TestClusterMeet(t *testing.T) {
+	assert := assert.New(t)
+
+	c := cluster.New(config.NewConfig())
+	assert.EqualError(c.Meet("nexist", 7000, 17000), "Invalid node address specified: nexist:7000")
+	assert.Nil(c.Meet("127.0.0.1", 7000, 17000))
+
+	// the node stays in handshake until it answers
+	nodes := c.Nodes()
+	assert.Len(nodes, 2)
+	assert.Contains(c.Description(), "127.0.0.1:7000@17000 handshake")
+	assert.Contains(c.Info(), "cluster_known_nodes:2\r\ncluster_size:0\r\n")
+}
@@ -0,0 +1,43 @@
+package cluster
+
+import "strings"
+
+// the number of hash slots the keyspace is split into.
+const CLUSTER_SLOTS = 16384
+
+// the CRC16 used by redis cluster is the XMODEM variant: polynomial 0x1021, no reflection and an initial value of 0.
+var crc16Table = func() [256]uint16 {
+	var table [256]uint16
+	for idx := range table {
+		crc := uint16(idx) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		table[idx] = crc
+	}
+	return table
+}()
+
+func crc16(s string) uint16 {
+	crc := uint16(0)
+	for idx := 0; idx < len(s); idx++ {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^s[idx]]
+	}
+	return crc
+}
+
+// KeySlot returns the hash slot of a key. when the key has a hash tag, the part between the first { and
+// the first } after it, only the tag is hashed so that related keys can be kept in the same slot.
+// an empty tag like in {}key doesn't count as one.
+func KeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) & (CLUSTER_SLOTS - 1))
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vrajashkr/cc-kv-go/src/glob"
+)
+
+type param struct {
+	defaultValue string
+	validate     func(value string) error
+}
+
+func validateInt(value string) error {
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+		return fmt.Errorf("argument couldn't be parsed into an integer")
+	}
+	return nil
+}
+
+func validateOneOf(allowed ...string) func(value string) error {
+	return func(value string) error {
+		if !slices.Contains(allowed, value) {
+			return fmt.Errorf("argument must be one of %s", strings.Join(allowed, ", "))
+		}
+		return nil
+	}
+}
+
+func validateOctal(value string) error {
+	if _, err := strconv.ParseUint(value, 8, 32); err != nil {
+		return fmt.Errorf("argument couldn't be parsed into an octal number")
+	}
+	return nil
+}
+
+func validateBool(value string) error {
+	if value != "yes" && value != "no" {
+		return fmt.Errorf("argument must be 'yes' or 'no'")
+	}
+	return nil
+}
+
+func validateMemory(value string) error {
+	_, err := ParseMemory(value)
+	return err
+}
+
+func validateReplicaOf(value string) error {
+	if value == "" {
+		return nil
+	}
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return fmt.Errorf("argument must be a host and a port")
+	}
+	return validateInt(fields[1])
+}
+
+func validateOutputBufferLimits(value string) error {
+	_, err := ParseOutputBufferLimits(value)
+	return err
+}
+
+func validateKeyspaceEvents(value string) error {
+	_, err := ParseKeyspaceEvents(value)
+	return err
+}
+
+// the configuration parameters supported by the server along with their default values.
+var params = map[string]param{
+	"port":        {"6379", validateInt},
+	"bind":        {"* -::*", nil},
+	"maxmemory":   {"0", validateInt},
+	"save":        {"", nil},
+	"appendonly":  {"no", validateBool},
+	"requirepass": {"", nil},
+	"aclfile":     {"", nil},
+
+	"maxclients":                 {"10000", validateInt},
+	"timeout":                    {"0", validateInt},
+	"tcp-keepalive":              {"300", validateInt},
+	"client-output-buffer-limit": {"normal 0 0 0 replica 256mb 64mb 60 pubsub 32mb 8mb 60", validateOutputBufferLimits},
+
+	"replicaof":         {"", validateReplicaOf},
+	"replica-read-only": {"yes", validateBool},
+	"repl-backlog-size": {"1mb", validateMemory},
+	"masterauth":        {"", nil},
+	"masteruser":        {"", nil},
+
+	"cluster-enabled":               {"no", validateBool},
+	"cluster-port":                  {"0", validateInt},
+	"cluster-node-timeout":          {"15000", validateInt},
+	"cluster-require-full-coverage": {"yes", validateBool},
+
+	"slowlog-log-slower-than": {"10000", validateInt},
+	"slowlog-max-len":         {"128", validateInt},
+
+	"busy-reply-threshold": {"5000", validateInt},
+
+	"notify-keyspace-events": {"", validateKeyspaceEvents},
+
+	"unixsocket":     {"", nil},
+	"unixsocketperm": {"0", validateOctal},
+
+	"tls-port":         {"0", validateInt},
+	"tls-cert-file":    {"", nil},
+	"tls-key-file":     {"", nil},
+	"tls-ca-cert-file": {"", nil},
+	"tls-auth-clients": {"yes", validateOneOf("yes", "no", "optional")},
+}
+
+// Config holds the runtime configuration of the server.
+type Config struct {
+	mu       sync.RWMutex
+	values   map[string]string
+	watchers map[string][]func(value string)
+}
+
+func NewConfig() *Config {
+	values := make(map[string]string, len(params))
+	for name, p := range params {
+		values[name] = p.defaultValue
+	}
+
+	return &Config{
+		values:   values,
+		watchers: make(map[string][]func(string)),
+	}
+}
+
+// Get returns the current value of a parameter, or an empty string if it is unknown.
+func (c *Config) Get(name string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.values[strings.ToLower(name)]
+}
+
+// GetInt returns the current value of an integer parameter.
+func (c *Config) GetInt(name string) int64 {
+	val, _ := strconv.ParseInt(c.Get(name), 10, 64)
+	return val
+}
+
+// GetOctal returns the current value of a parameter holding an octal number, such as file permissions.
+func (c *Config) GetOctal(name string) uint64 {
+	val, _ := strconv.ParseUint(c.Get(name), 8, 32)
+	return val
+}
+
+// GetMemory returns the current value of a parameter holding a memory amount, in bytes.
+func (c *Config) GetMemory(name string) int64 {
+	val, _ := ParseMemory(c.Get(name))
+	return val
+}
+
+// GetBool returns the current value of a yes/no parameter.
+func (c *Config) GetBool(name string) bool {
+	return c.Get(name) == "yes"
+}
+
+// Set validates and updates a parameter, notifying any watchers of the change.
+func (c *Config) Set(name string, value string) error {
+	name = strings.ToLower(name)
+	p, ok := params[name]
+	if !ok {
+		return fmt.Errorf("Unknown option or number of arguments for CONFIG SET - '%s'", name)
+	}
+
+	if p.validate != nil {
+		if err := p.validate(value); err != nil {
+			return fmt.Errorf("Invalid argument '%s' for CONFIG SET '%s' - %s", value, name, err.Error())
+		}
+	}
+
+	c.mu.Lock()
+	c.values[name] = value
+	watchers := slices.Clone(c.watchers[name])
+	c.mu.Unlock()
+
+	for _, watcher := range watchers {
+		watcher(value)
+	}
+	return nil
+}
+
+// Watch registers a function that is called whenever the parameter is updated.
+func (c *Config) Watch(name string, watcher func(value string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name = strings.ToLower(name)
+	c.watchers[name] = append(c.watchers[name], watcher)
+}
+
+// Match returns the name and value of every parameter matching the glob-style pattern, ordered by name.
+func (c *Config) Match(pattern string) [][2]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pattern = strings.ToLower(pattern)
+	result := [][2]string{}
+	for name, value := range c.values {
+		if glob.Match(pattern, name) {
+			result = append(result, [2]string{name, value})
+		}
+	}
+
+	slices.SortFunc(result, func(a, b [2]string) int {
+		return strings.Compare(a[0], b[0])
+	})
+	return result
+}
+
+// LoadFile reads parameters from a file in the redis.conf format.
+// Each line holds a parameter name followed by its value, blank lines and lines starting with '#' are ignored.
+func (c *Config) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, _ := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		if err := c.Set(name, value); err != nil {
+			return fmt.Errorf("%s:%d: %s", path, lineNum, err.Error())
+		}
+	}
+
+	return scanner.Err()
+}
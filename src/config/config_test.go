@@ -0,0 +1,90 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+)
+
+func TestConfig(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	assert.Equal("6379", cfg.Get("port"))
+	assert.Equal(int64(6379), cfg.GetInt("PORT"))
+	assert.False(cfg.GetBool("appendonly"))
+
+	assert.EqualError(cfg.Set("nexist", "1"), "Unknown option or number of arguments for CONFIG SET - 'nexist'")
+	assert.EqualError(cfg.Set("port", "abc"), "Invalid argument 'abc' for CONFIG SET 'port' - argument couldn't be parsed into an integer")
+	assert.EqualError(cfg.Set("appendonly", "maybe"), "Invalid argument 'maybe' for CONFIG SET 'appendonly' - argument must be 'yes' or 'no'")
+	assert.EqualError(cfg.Set("repl-backlog-size", "big"), "Invalid argument 'big' for CONFIG SET 'repl-backlog-size' - argument must be a memory value")
+	assert.EqualError(cfg.Set("replicaof", "localhost"), "Invalid argument 'localhost' for CONFIG SET 'replicaof' - argument must be a host and a port")
+	assert.Equal(int64(1024*1024), cfg.GetMemory("repl-backlog-size"))
+	assert.EqualError(cfg.Set("cluster-enabled", "1"), "Invalid argument '1' for CONFIG SET 'cluster-enabled' - argument must be 'yes' or 'no'")
+	assert.Equal(int64(15000), cfg.GetInt("cluster-node-timeout"))
+
+	watched := ""
+	cfg.Watch("requirepass", func(value string) { watched = value })
+	require.Nil(cfg.Set("requirepass", "secret"))
+	assert.Equal("secret", watched)
+	assert.Equal("secret", cfg.Get("requirepass"))
+
+	assert.Equal([][2]string{{"aclfile", ""}, {"appendonly", "no"}}, cfg.Match("a*"))
+	assert.Empty(cfg.Match("nexist*"))
+
+	confPath := filepath.Join(t.TempDir(), "test.conf")
+	require.Nil(os.WriteFile(confPath, []byte("# comment\n\nport 7000\nrequirepass \"pass word\"\n"), 0o600))
+	require.Nil(cfg.LoadFile(confPath))
+	assert.Equal("7000", cfg.Get("port"))
+	assert.Equal("pass word", cfg.Get("requirepass"))
+	assert.Equal("pass word", watched)
+
+	require.Nil(os.WriteFile(confPath, []byte("port 7001\nunknown yes\n"), 0o600))
+	assert.EqualError(cfg.LoadFile(confPath), confPath+":2: Unknown option or number of arguments for CONFIG SET - 'unknown'")
+}
+
+func TestParseOutputBufferLimits(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mem, err := config.ParseMemory("64kb")
+	require.Nil(err)
+	assert.Equal(int64(64*1024), mem)
+	mem, err = config.ParseMemory("2m")
+	require.Nil(err)
+	assert.Equal(int64(2000000), mem)
+	_, err = config.ParseMemory("lots")
+	assert.NotNil(err)
+
+	limits, err := config.ParseOutputBufferLimits("normal 1mb 512kb 10")
+	require.Nil(err)
+	assert.Equal(config.OutputBufferLimit{Hard: 1024 * 1024, Soft: 512 * 1024, SoftDuration: 10 * time.Second}, limits[config.CLIENT_CLASS_NORMAL])
+	assert.Equal(config.OutputBufferLimit{Hard: 32 * 1024 * 1024, Soft: 8 * 1024 * 1024, SoftDuration: 60 * time.Second}, limits[config.CLIENT_CLASS_PUBSUB])
+
+	_, err = config.ParseOutputBufferLimits("normal 1mb 512kb")
+	assert.EqualError(err, "wrong number of arguments in buffer limit configuration")
+	_, err = config.ParseOutputBufferLimits("other 1mb 512kb 10")
+	assert.EqualError(err, "invalid client class specified in buffer limit configuration")
+	_, err = config.ParseOutputBufferLimits("normal 1mb 512kb -1")
+	assert.EqualError(err, "error in hard, soft or soft_seconds setting in buffer limit configuration")
+}
+
+func TestParseKeyspaceEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	flags, err := config.ParseKeyspaceEvents("KA")
+	assert.Nil(err)
+	// A leaves out the evicted events, which are never sent
+	assert.Equal("Kg$lshzxt", flags)
+
+	_, err = config.ParseKeyspaceEvents("Ke")
+	assert.EqualError(err, "The evicted events of 'e' are not supported, since keys are never evicted.")
+	_, err = config.ParseKeyspaceEvents("KQ")
+	assert.EqualError(err, "Invalid event class character. Use 'Ag$lshzxKEtmn'.")
+}
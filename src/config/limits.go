@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CLIENT_CLASS_NORMAL  = "normal"
+	CLIENT_CLASS_REPLICA = "replica"
+	CLIENT_CLASS_PUBSUB  = "pubsub"
+)
+
+// OutputBufferLimit bounds the amount of pending output of a client before it is disconnected.
+// A client is disconnected as soon as it reaches the hard limit, or when it stays above the soft limit for longer than SoftDuration.
+// A limit of zero disables the check.
+type OutputBufferLimit struct {
+	Hard         int64
+	Soft         int64
+	SoftDuration time.Duration
+}
+
+var defaultOutputBufferLimits = map[string]OutputBufferLimit{
+	CLIENT_CLASS_NORMAL:  {0, 0, 0},
+	CLIENT_CLASS_REPLICA: {256 * 1024 * 1024, 64 * 1024 * 1024, 60 * time.Second},
+	CLIENT_CLASS_PUBSUB:  {32 * 1024 * 1024, 8 * 1024 * 1024, 60 * time.Second},
+}
+
+// ParseMemory parses a memory amount with an optional unit, such as 1024, 10k, 10kb, 1mb or 2gb.
+// k, m and g are powers of 1000 while kb, mb and gb are powers of 1024.
+func ParseMemory(value string) (int64, error) {
+	lower := strings.ToLower(value)
+	multipliers := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"kb", 1024},
+		{"mb", 1024 * 1024},
+		{"gb", 1024 * 1024 * 1024},
+		{"k", 1000},
+		{"m", 1000 * 1000},
+		{"g", 1000 * 1000 * 1000},
+		{"b", 1},
+	}
+
+	multiplier := int64(1)
+	for _, m := range multipliers {
+		if strings.HasSuffix(lower, m.suffix) {
+			lower = strings.TrimSuffix(lower, m.suffix)
+			multiplier = m.multiplier
+			break
+		}
+	}
+
+	amount, err := strconv.ParseInt(lower, 10, 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("argument must be a memory value")
+	}
+	return amount * multiplier, nil
+}
+
+// ParseOutputBufferLimits parses the client-output-buffer-limit parameter, which is made of
+// groups of "<class> <hard limit> <soft limit> <soft seconds>".
+// Classes that are not mentioned keep their default limits.
+func ParseOutputBufferLimits(value string) (map[string]OutputBufferLimit, error) {
+	limits := make(map[string]OutputBufferLimit, len(defaultOutputBufferLimits))
+	for class, limit := range defaultOutputBufferLimits {
+		limits[class] = limit
+	}
+
+	fields := strings.Fields(value)
+	if len(fields)%4 != 0 {
+		return nil, fmt.Errorf("wrong number of arguments in buffer limit configuration")
+	}
+
+	for idx := 0; idx < len(fields); idx += 4 {
+		class := strings.ToLower(fields[idx])
+		if class == "slave" {
+			class = CLIENT_CLASS_REPLICA
+		}
+		if _, ok := defaultOutputBufferLimits[class]; !ok {
+			return nil, fmt.Errorf("invalid client class specified in buffer limit configuration")
+		}
+
+		hard, err := ParseMemory(fields[idx+1])
+		if err != nil {
+			return nil, fmt.Errorf("error in hard, soft or soft_seconds setting in buffer limit configuration")
+		}
+		soft, err := ParseMemory(fields[idx+2])
+		if err != nil {
+			return nil, fmt.Errorf("error in hard, soft or soft_seconds setting in buffer limit configuration")
+		}
+		softSeconds, err := strconv.ParseInt(fields[idx+3], 10, 64)
+		if err != nil || softSeconds < 0 {
+			return nil, fmt.Errorf("error in hard, soft or soft_seconds setting in buffer limit configuration")
+		}
+
+		limits[class] = OutputBufferLimit{
+			Hard:         hard,
+			Soft:         soft,
+			SoftDuration: time.Duration(softSeconds) * time.Second,
+		}
+	}
+
+	return limits, nil
+}
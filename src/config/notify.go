@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// NOTIFY_KEYSPACE publishes the events of a key on __keyspace@<db>__:<key>
+	NOTIFY_KEYSPACE = 'K'
+	// NOTIFY_KEYEVENT publishes the keys of an event on __keyevent@<db>__:<event>
+	NOTIFY_KEYEVENT = 'E'
+
+	// the classes of events selected by A, which leaves out the key miss and new key events. the key miss events
+	// of m are accepted for compatibility but never sent
+	NOTIFY_ALL_CLASSES = "g$lshzxt"
+	NOTIFY_CLASSES     = NOTIFY_ALL_CLASSES + "mn"
+	// NOTIFY_EVICTED selects the evicted events in redis, which are rejected since keys are never evicted
+	NOTIFY_EVICTED = 'e'
+)
+
+// ParseKeyspaceEvents parses the notify-keyspace-events parameter and returns its flags, with A replaced by the
+// classes it stands for. An empty value disables the notifications.
+func ParseKeyspaceEvents(value string) (string, error) {
+	var flags strings.Builder
+	for _, flag := range value {
+		switch {
+		case flag == 'A':
+			flags.WriteString(NOTIFY_ALL_CLASSES)
+		case flag == NOTIFY_KEYSPACE || flag == NOTIFY_KEYEVENT || strings.ContainsRune(NOTIFY_CLASSES, flag):
+			flags.WriteRune(flag)
+		case flag == NOTIFY_EVICTED:
+			return "", fmt.Errorf("The evicted events of 'e' are not supported, since keys are never evicted.")
+		default:
+			return "", fmt.Errorf("Invalid event class character. Use 'Ag$lshzxKEtmn'.")
+		}
+	}
+	return flags.String(), nil
+}
@@ -87,6 +87,9 @@ func NewBulkString(rawMsg string) (int, BulkString, error) {
 
 	dataStartIdx := firstCRLFIndex + 2
 	dataEndIdx := dataStartIdx + strLen
+	if strLen < 0 || dataEndIdx+2 > len(rawMsg) {
+		return 0, BulkString{}, fmt.Errorf("incomplete bulk string")
+	}
 	return dataEndIdx + 2, BulkString{
 		Data: rawMsg[dataStartIdx:dataEndIdx],
 	}, nil
@@ -115,7 +118,7 @@ func NewArray(rawMsg string) (int, Array, error) {
 	elements := []Message{}
 
 	for i := 0; i < numElements; i++ {
-		charsCount, msg, err := ProcessMessageString(rawMsg[charConsumedCount:])
+		charsCount, msg, err := processRespMessage(rawMsg[charConsumedCount:])
 		if err != nil {
 			return 0, Array{}, err
 		}
@@ -129,11 +132,12 @@ func NewArray(rawMsg string) (int, Array, error) {
 }
 
 func (a Array) ToDataString() string {
-	out := fmt.Sprintf("%c%d\r\n", MSG_TYPE_ARRAY, len(a.Elements))
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%c%d\r\n", MSG_TYPE_ARRAY, len(a.Elements))
 	for _, elem := range a.Elements {
-		out += elem.ToDataString()
+		sb.WriteString(elem.ToDataString())
 	}
-	return out
+	return sb.String()
 }
 
 type Null struct{}
@@ -146,7 +150,25 @@ func (n Null) ToDataString() string {
 	return MSG_NULL_W_BULK_STR
 }
 
+// ProcessMessageString decodes the first message in msg and returns the number of characters it used.
+// besides RESP, the inline protocol is accepted: a single line of space separated arguments, as typed in telnet.
 func ProcessMessageString(msg string) (int, Message, error) {
+	if len(msg) > 1 && !isRespDiscriminator(msg[0]) {
+		return NewInlineCommand(msg)
+	}
+	return processRespMessage(msg)
+}
+
+func isRespDiscriminator(b byte) bool {
+	switch b {
+	case MSG_TYPE_SIMPLE_STR, MSG_TYPE_ERROR, MSG_TYPE_INT, MSG_TYPE_BULK_STR, MSG_TYPE_ARRAY:
+		return true
+	default:
+		return false
+	}
+}
+
+func processRespMessage(msg string) (int, Message, error) {
 	if len(msg) <= 1 {
 		return 0, nil, fmt.Errorf("received empty invalid message")
 	}
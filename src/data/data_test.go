@@ -1,7 +1,10 @@
 package data_test
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -352,3 +355,128 @@ func TestNullToDataString(t *testing.T) {
 	msg := data.Null{}
 	assert.Equal("$-1\r\n", msg.ToDataString())
 }
+
+func TestProcessMessageStringWithInlineCommand(t *testing.T) {
+	testCases := []struct {
+		input         string
+		want          []string
+		consumedCount int
+	}{
+		{"PING\r\n", []string{"PING"}, 6},
+		{"ping\n", []string{"ping"}, 5},
+		{"SET  key   value\r\nGET key\r\n", []string{"SET", "key", "value"}, 18},
+		{"SET \"multi word\" 'single quoted'\r\n", []string{"SET", "multi word", "single quoted"}, 34},
+		{"SET k \"line\\nbreak \\\"quoted\\\" \\x41\"\r\n", []string{"SET", "k", "line\nbreak \"quoted\" A"}, 37},
+		{"SET k 'it\\'s'\r\n", []string{"SET", "k", "it's"}, 15},
+		{"SET k \"\"\r\n", []string{"SET", "k", ""}, 10},
+		{"\r\n", []string{}, 2},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("input %s", tc.input), func(t *testing.T) {
+			charsConsumedCount, result, err := data.ProcessMessageString(tc.input)
+			assert.Nil(err)
+			assert.Equal(tc.consumedCount, charsConsumedCount)
+
+			elements := []data.Message{}
+			for _, arg := range tc.want {
+				elements = append(elements, data.BulkString{Data: arg})
+			}
+			assert.Equal(data.Array{Elements: elements}, result)
+		})
+	}
+}
+
+func TestProcessMessageStringWithIncorrectInlineCommand(t *testing.T) {
+	testCases := []string{
+		"PING",
+		"SET k \"unbalanced\r\n",
+		"SET k 'unbalanced\r\n",
+		"SET k \"closing\"quote\r\n",
+		"$5\r\nabc\r\n",
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("input %s", tc), func(t *testing.T) {
+			charsConsumedCount, _, err := data.ProcessMessageString(tc)
+			assert.NotNil(err)
+			assert.Equal(0, charsConsumedCount)
+		})
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  data.Message
+	}{
+		{"+OK\r\n", data.SimpleString{Contents: "OK"}},
+		{"-ERR failed\r\n", data.Error{ErrMsg: "ERR failed"}},
+		{":-42\r\n", data.Integer{Value: -42}},
+		{"$5\r\na\r\nbc\r\n", data.BulkString{Data: "a\r\nbc"}},
+		{"$-1\r\n", data.Null{}},
+		{"*-1\r\n", data.Null{}},
+		{"*2\r\n$3\r\nGET\r\n*1\r\n:1\r\n", data.Array{Elements: []data.Message{
+			data.BulkString{Data: "GET"},
+			data.Array{Elements: []data.Message{data.Integer{Value: 1}}},
+		}}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("input %s", tc.input), func(t *testing.T) {
+			reader := bufio.NewReader(strings.NewReader(tc.input + "+next\r\n"))
+			result, err := data.ReadMessage(reader)
+			assert.Nil(err)
+			assert.Equal(tc.want, result)
+
+			// the message is consumed entirely, leaving the reader at the start of the next one
+			next, err := data.ReadMessage(reader)
+			assert.Nil(err)
+			assert.Equal(data.SimpleString{Contents: "next"}, next)
+		})
+	}
+}
+
+func TestReadMessageWithInvalidInput(t *testing.T) {
+	testCases := []string{
+		"",
+		"PING\r\n",
+		"+OK\n",
+		"$5\r\nabc\r\n",
+		"$3\r\nabcde\r\n",
+		"*2\r\n$3\r\nGET\r\n",
+		":abc\r\n",
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("input %s", tc), func(t *testing.T) {
+			_, err := data.ReadMessage(bufio.NewReader(strings.NewReader(tc)))
+			assert.NotNil(err)
+		})
+	}
+}
+
+func TestReadRequest(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("*2\r\n$3\r\nGET\r\n$6\r\na\r\nb c\r\nSET k 'v w'\r\n\r\nPING"))
+
+	assert := assert.New(t)
+	request, err := data.ReadRequest(reader)
+	assert.Nil(err)
+	assert.Equal(data.Array{Elements: []data.Message{data.BulkString{Data: "GET"}, data.BulkString{Data: "a\r\nb c"}}}, request)
+
+	request, err = data.ReadRequest(reader)
+	assert.Nil(err)
+	assert.Equal(data.Array{Elements: []data.Message{data.BulkString{Data: "SET"}, data.BulkString{Data: "k"}, data.BulkString{Data: "v w"}}}, request)
+
+	request, err = data.ReadRequest(reader)
+	assert.Nil(err)
+	assert.Equal(data.Array{Elements: []data.Message{}}, request)
+
+	// an inline command is only complete once its newline has been received
+	_, err = data.ReadRequest(reader)
+	assert.ErrorIs(err, io.EOF)
+}
@@ -0,0 +1,128 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NewInlineCommand decodes a command sent with the inline protocol into an Array of BulkString.
+// the line ends with a newline, optionally preceded by a carriage return. arguments are separated by
+// spaces and can be quoted like in redis-cli: double quoted arguments support escape sequences
+// and single quoted arguments only support escaping the single quote. a blank line yields an empty Array.
+func NewInlineCommand(rawMsg string) (int, Array, error) {
+	newlineIndex := strings.IndexByte(rawMsg, '\n')
+	if newlineIndex < 0 {
+		return 0, Array{}, fmt.Errorf("incomplete inline command")
+	}
+
+	line := strings.TrimSuffix(rawMsg[:newlineIndex], "\r")
+	args, err := splitInlineArgs(line)
+	if err != nil {
+		return 0, Array{}, err
+	}
+
+	elements := make([]Message, len(args))
+	for idx, arg := range args {
+		elements[idx] = BulkString{Data: arg}
+	}
+	return newlineIndex + 1, Array{Elements: elements}, nil
+}
+
+func isInlineSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n' || b == '\v' || b == '\f'
+}
+
+func isHexDigit(b byte) bool {
+	return strings.IndexByte("0123456789abcdefABCDEF", b) >= 0
+}
+
+// splitInlineArgs splits a line into arguments following the rules of sdssplitargs in redis.
+func splitInlineArgs(line string) ([]string, error) {
+	errUnbalancedQuotes := ProtocolError{Msg: "unbalanced quotes in request"}
+
+	args := []string{}
+	idx := 0
+	for {
+		for idx < len(line) && isInlineSpace(line[idx]) {
+			idx++
+		}
+		if idx == len(line) {
+			return args, nil
+		}
+
+		var current strings.Builder
+		inDoubleQuotes := false
+		inSingleQuotes := false
+		done := false
+		for !done {
+			if idx == len(line) {
+				if inDoubleQuotes || inSingleQuotes {
+					return nil, errUnbalancedQuotes
+				}
+				break
+			}
+
+			char := line[idx]
+			switch {
+			case inDoubleQuotes:
+				switch {
+				case char == '\\' && idx+3 < len(line) && line[idx+1] == 'x' && isHexDigit(line[idx+2]) && isHexDigit(line[idx+3]):
+					value, _ := strconv.ParseUint(line[idx+2:idx+4], 16, 8)
+					current.WriteByte(byte(value))
+					idx += 3
+				case char == '\\' && idx+1 < len(line):
+					idx++
+					switch line[idx] {
+					case 'n':
+						current.WriteByte('\n')
+					case 'r':
+						current.WriteByte('\r')
+					case 't':
+						current.WriteByte('\t')
+					case 'b':
+						current.WriteByte('\b')
+					case 'a':
+						current.WriteByte('\a')
+					default:
+						current.WriteByte(line[idx])
+					}
+				case char == '"':
+					// the closing quote must be followed by a space or by the end of the line
+					if idx+1 < len(line) && !isInlineSpace(line[idx+1]) {
+						return nil, errUnbalancedQuotes
+					}
+					done = true
+				default:
+					current.WriteByte(char)
+				}
+			case inSingleQuotes:
+				switch {
+				case char == '\\' && idx+1 < len(line) && line[idx+1] == '\'':
+					idx++
+					current.WriteByte('\'')
+				case char == '\'':
+					if idx+1 < len(line) && !isInlineSpace(line[idx+1]) {
+						return nil, errUnbalancedQuotes
+					}
+					done = true
+				default:
+					current.WriteByte(char)
+				}
+			default:
+				switch {
+				case isInlineSpace(char):
+					done = true
+				case char == '"':
+					inDoubleQuotes = true
+				case char == '\'':
+					inSingleQuotes = true
+				default:
+					current.WriteByte(char)
+				}
+			}
+			idx++
+		}
+		args = append(args, current.String())
+	}
+}
@@ -0,0 +1,136 @@
+package data
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// the bulk strings up to this length are read at once, while the longer ones grow with the data received
+// rather than with the announced length.
+const MAX_PREALLOC_BULK_LEN = 64 * 1024
+
+// ProtocolError is returned when a stream doesn't follow the protocol, after which the rest of it can't be framed.
+type ProtocolError struct {
+	Msg string
+}
+
+func (e ProtocolError) Error() string {
+	return "Protocol error: " + e.Msg
+}
+
+// ReadMessage reads a single RESP message from a stream, waiting for the rest of the message when it
+// has only been partially received. unlike ReadRequest, the inline protocol is not accepted.
+func ReadMessage(r *bufio.Reader) (Message, error) {
+	line, err := ReadLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, ProtocolError{Msg: "received empty invalid message"}
+	}
+
+	switch line[0] {
+	case MSG_TYPE_SIMPLE_STR:
+		return SimpleString{Contents: line[1:]}, nil
+	case MSG_TYPE_ERROR:
+		return Error{ErrMsg: line[1:]}, nil
+	case MSG_TYPE_INT:
+		val, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, ProtocolError{Msg: "invalid integer"}
+		}
+		return Integer{Value: val}, nil
+	case MSG_TYPE_BULK_STR:
+		strLen, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, ProtocolError{Msg: "invalid bulk length"}
+		}
+		if strLen < 0 {
+			return Null{}, nil
+		}
+		return readBulkString(r, strLen)
+	case MSG_TYPE_ARRAY:
+		numElements, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, ProtocolError{Msg: "invalid multibulk length"}
+		}
+		if numElements < 0 {
+			return Null{}, nil
+		}
+		elements := make([]Message, 0, min(numElements, 1024))
+		for range numElements {
+			element, err := ReadMessage(r)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+		}
+		return Array{Elements: elements}, nil
+	default:
+		return nil, ProtocolError{Msg: "unsupported message discriminator"}
+	}
+}
+
+// readBulkString reads the contents of a bulk string of strLen bytes, along with its terminator.
+func readBulkString(r *bufio.Reader, strLen int) (Message, error) {
+	var contents string
+	if strLen <= MAX_PREALLOC_BULK_LEN {
+		buf := make([]byte, strLen)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		contents = string(buf)
+	} else {
+		var sb strings.Builder
+		if _, err := io.CopyN(&sb, r, int64(strLen)); err != nil {
+			return nil, err
+		}
+		contents = sb.String()
+	}
+
+	terminator := make([]byte, 2)
+	if _, err := io.ReadFull(r, terminator); err != nil {
+		return nil, err
+	}
+	if string(terminator) != "\r\n" {
+		return nil, ProtocolError{Msg: "invalid message format for bulk string"}
+	}
+	return BulkString{Data: contents}, nil
+}
+
+// ReadRequest reads the next request sent by a client. like redis, a request starting with '*' is a RESP array,
+// while any other request is an inline command taking up a single line. since a request is only read once the
+// previous one has been read entirely, the inline protocol is only ever used at the start of a request.
+func ReadRequest(r *bufio.Reader) (Message, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if first[0] == MSG_TYPE_ARRAY {
+		return ReadMessage(r)
+	}
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	_, cmd, err := NewInlineCommand(line)
+	if err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// ReadLine reads a line terminated by CRLF and returns it without the terminator.
+func ReadLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(line, "\r\n") {
+		return "", ProtocolError{Msg: "invalid line terminator"}
+	}
+	return line[:len(line)-2], nil
+}
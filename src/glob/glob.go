@@ -0,0 +1,99 @@
+package glob
+
+// Match reports whether str matches the glob-style pattern.
+// The supported syntax is the one used by Redis: '*' matches any sequence of characters,
+// '?' matches a single character, '[...]' matches a set or range of characters (negated with '^')
+// and '\' escapes the following character.
+// Unlike path.Match, '/' has no special meaning.
+func Match(pattern string, str string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			// collapse consecutive stars
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for idx := 0; idx <= len(str); idx++ {
+				if Match(pattern[1:], str[idx:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(str) == 0 {
+				return false
+			}
+			str = str[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(str) == 0 {
+				return false
+			}
+			consumed, matched := matchClass(pattern, str[0])
+			if !matched {
+				return false
+			}
+			pattern = pattern[consumed:]
+			str = str[1:]
+		case '\\':
+			if len(pattern) >= 2 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(str) == 0 || pattern[0] != str[0] {
+				return false
+			}
+			str = str[1:]
+			pattern = pattern[1:]
+		}
+	}
+	return len(str) == 0
+}
+
+// matchClass matches a single character against the character class at the start of the pattern.
+// It returns the length of the class in the pattern and whether the character matched.
+func matchClass(pattern string, c byte) (int, bool) {
+	idx := 1
+	negate := false
+	if idx < len(pattern) && pattern[idx] == '^' {
+		negate = true
+		idx++
+	}
+
+	matched := false
+	for idx < len(pattern) && pattern[idx] != ']' {
+		switch {
+		case pattern[idx] == '\\' && idx+1 < len(pattern):
+			idx++
+			if pattern[idx] == c {
+				matched = true
+			}
+			idx++
+		case idx+2 < len(pattern) && pattern[idx+1] == '-' && pattern[idx+2] != ']':
+			start, end := pattern[idx], pattern[idx+2]
+			if start > end {
+				start, end = end, start
+			}
+			if c >= start && c <= end {
+				matched = true
+			}
+			idx += 3
+		default:
+			if pattern[idx] == c {
+				matched = true
+			}
+			idx++
+		}
+	}
+
+	// skip the closing bracket, an unterminated class consumes the rest of the pattern
+	if idx < len(pattern) {
+		idx++
+	}
+
+	return idx, matched != negate
+}
@@ -0,0 +1,44 @@
+package glob_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/glob"
+)
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		input   string
+		want    bool
+	}{
+		{"*", "", true},
+		{"*", "anything/with/slashes", true},
+		{"user:*", "user:1000", true},
+		{"user:*", "session:1000", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h*llo", "heeeello", true},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-b]llo", "hbllo", true},
+		{"h[a-b]llo", "hcllo", false},
+		{"h\\*llo", "h*llo", true},
+		{"h\\*llo", "hello", false},
+		{"**a", "bba", true},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+		{"exact", "exact", true},
+		{"exact", "exactly", false},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.input, func(t *testing.T) {
+			assert.Equal(tc.want, glob.Match(tc.pattern, tc.input))
+		})
+	}
+}
@@ -0,0 +1,492 @@
+package handler
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/vrajashkr/cc-kv-go/src/glob"
+)
+
+const (
+	ACL_CATEGORY_ALL = "all"
+)
+
+func aclCategoryExists(category string) bool {
+	return category == ACL_CATEGORY_ALL || slices.Contains(ACL_CATEGORIES, category)
+}
+
+func aclCommandInCategory(command string, category string) bool {
+	if category == ACL_CATEGORY_ALL {
+		return true
+	}
+	spec, ok := lookupCommand(command)
+	return ok && slices.Contains(spec.aclCategories(), category)
+}
+
+func aclCommandExists(command string) bool {
+	_, ok := lookupCommand(command)
+	return ok
+}
+
+// aclCategoryCommands returns the names of the commands that belong to the category.
+func aclCategoryCommands(category string) []string {
+	names := []string{}
+	for _, spec := range COMMAND_TABLE {
+		if slices.Contains(spec.aclCategories(), category) {
+			names = append(names, spec.name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func hashPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+// aclUser holds the credentials and permissions of a single ACL user.
+type aclUser struct {
+	name            string
+	enabled         bool
+	noPass          bool
+	passwordHashes  []string
+	cmdRules        []string
+	keyPatterns     []string
+	channelPatterns []string
+}
+
+func newAclUser(name string) *aclUser {
+	return &aclUser{
+		name:            name,
+		passwordHashes:  []string{},
+		cmdRules:        []string{},
+		keyPatterns:     []string{},
+		channelPatterns: []string{},
+	}
+}
+
+func (u *aclUser) clone() *aclUser {
+	return &aclUser{
+		name:            u.name,
+		enabled:         u.enabled,
+		noPass:          u.noPass,
+		passwordHashes:  slices.Clone(u.passwordHashes),
+		cmdRules:        slices.Clone(u.cmdRules),
+		keyPatterns:     slices.Clone(u.keyPatterns),
+		channelPatterns: slices.Clone(u.channelPatterns),
+	}
+}
+
+// applyRule updates the user with a single ACL rule, as accepted by ACL SETUSER.
+func (u *aclUser) applyRule(rule string) error {
+	lowerRule := strings.ToLower(rule)
+	switch lowerRule {
+	case "on":
+		u.enabled = true
+		return nil
+	case "off":
+		u.enabled = false
+		return nil
+	case "nopass":
+		u.noPass = true
+		u.passwordHashes = []string{}
+		return nil
+	case "resetpass":
+		u.noPass = false
+		u.passwordHashes = []string{}
+		return nil
+	case "allkeys":
+		u.keyPatterns = []string{"*"}
+		return nil
+	case "resetkeys":
+		u.keyPatterns = []string{}
+		return nil
+	case "allchannels":
+		u.channelPatterns = []string{"*"}
+		return nil
+	case "resetchannels":
+		u.channelPatterns = []string{}
+		return nil
+	case "allcommands":
+		u.cmdRules = []string{"+@all"}
+		return nil
+	case "nocommands":
+		u.cmdRules = []string{"-@all"}
+		return nil
+	case "reset":
+		for _, r := range []string{"resetpass", "resetkeys", "resetchannels", "nocommands", "off"} {
+			_ = u.applyRule(r)
+		}
+		return nil
+	}
+
+	if len(rule) < 2 {
+		return fmt.Errorf("Syntax error")
+	}
+
+	arg := rule[1:]
+	switch rule[0] {
+	case '>':
+		hash := hashPassword(arg)
+		if !slices.Contains(u.passwordHashes, hash) {
+			u.passwordHashes = append(u.passwordHashes, hash)
+		}
+		u.noPass = false
+	case '<':
+		hash := hashPassword(arg)
+		if !slices.Contains(u.passwordHashes, hash) {
+			return fmt.Errorf("no such password")
+		}
+		u.passwordHashes = slices.DeleteFunc(u.passwordHashes, func(h string) bool { return h == hash })
+	case '#':
+		hash := strings.ToLower(arg)
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha256.Size*2 {
+			return fmt.Errorf("The password hash must be exactly 64 characters and contain only lowercase hexadecimal characters")
+		}
+		if !slices.Contains(u.passwordHashes, hash) {
+			u.passwordHashes = append(u.passwordHashes, hash)
+		}
+		u.noPass = false
+	case '!':
+		hash := strings.ToLower(arg)
+		if !slices.Contains(u.passwordHashes, hash) {
+			return fmt.Errorf("no such password")
+		}
+		u.passwordHashes = slices.DeleteFunc(u.passwordHashes, func(h string) bool { return h == hash })
+	case '~':
+		if !slices.Contains(u.keyPatterns, arg) {
+			u.keyPatterns = append(u.keyPatterns, arg)
+		}
+	case '&':
+		if !slices.Contains(u.channelPatterns, arg) {
+			u.channelPatterns = append(u.channelPatterns, arg)
+		}
+	case '+', '-':
+		if strings.HasPrefix(arg, "@") {
+			category := strings.ToLower(arg[1:])
+			if !aclCategoryExists(category) {
+				return fmt.Errorf("Unknown command or category name in ACL")
+			}
+			if category == ACL_CATEGORY_ALL {
+				// +@all and -@all override every rule that came before them
+				u.cmdRules = []string{}
+			}
+			u.cmdRules = append(u.cmdRules, string(rule[0])+"@"+category)
+		} else {
+			command := strings.ToUpper(arg)
+			if !aclCommandExists(command) {
+				return fmt.Errorf("Unknown command or category name in ACL")
+			}
+			u.cmdRules = append(u.cmdRules, string(rule[0])+strings.ToLower(command))
+		}
+	default:
+		return fmt.Errorf("Syntax error")
+	}
+
+	return nil
+}
+
+// canRunCommand evaluates the command rules of the user in order.
+func (u *aclUser) canRunCommand(command string) bool {
+	allowed := false
+	for _, rule := range u.cmdRules {
+		isAllow := rule[0] == '+'
+		target := rule[1:]
+		if strings.HasPrefix(target, "@") {
+			if aclCommandInCategory(command, target[1:]) {
+				allowed = isAllow
+			}
+		} else if strings.EqualFold(target, command) {
+			allowed = isAllow
+		}
+	}
+	return allowed
+}
+
+func (u *aclUser) canAccessKey(key string) bool {
+	for _, pattern := range u.keyPatterns {
+		if glob.Match(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *aclUser) canAccessChannel(channel string) bool {
+	for _, pattern := range u.channelPatterns {
+		if glob.Match(pattern, channel) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *aclUser) checkPassword(password string) bool {
+	if u.noPass {
+		return true
+	}
+	// every hash is compared in constant time, so that the time taken doesn't tell how close the password was
+	hash := []byte(hashPassword(password))
+	matched := 0
+	for _, candidate := range u.passwordHashes {
+		matched |= subtle.ConstantTimeCompare([]byte(candidate), hash)
+	}
+	return matched == 1
+}
+
+func (u *aclUser) commandsDescription() string {
+	if len(u.cmdRules) == 0 {
+		return "-@all"
+	}
+	return strings.Join(u.cmdRules, " ")
+}
+
+func patternsDescription(prefix string, patterns []string) string {
+	parts := make([]string, len(patterns))
+	for idx, pattern := range patterns {
+		parts[idx] = prefix + pattern
+	}
+	return strings.Join(parts, " ")
+}
+
+// describe renders the user as a rule list, in the format used by ACL LIST and ACL files.
+func (u *aclUser) describe() string {
+	parts := []string{"user", u.name}
+	if u.enabled {
+		parts = append(parts, "on")
+	} else {
+		parts = append(parts, "off")
+	}
+	if u.noPass {
+		parts = append(parts, "nopass")
+	}
+	for _, hash := range u.passwordHashes {
+		parts = append(parts, "#"+hash)
+	}
+	if len(u.keyPatterns) == 0 {
+		parts = append(parts, "resetkeys")
+	} else {
+		parts = append(parts, patternsDescription("~", u.keyPatterns))
+	}
+	if len(u.channelPatterns) == 0 {
+		parts = append(parts, "resetchannels")
+	} else {
+		parts = append(parts, patternsDescription("&", u.channelPatterns))
+	}
+	parts = append(parts, u.commandsDescription())
+	return strings.Join(parts, " ")
+}
+
+// ACL holds the users that can authenticate against the server.
+type ACL struct {
+	mu    sync.RWMutex
+	users map[string]*aclUser
+}
+
+func newDefaultAclUser() *aclUser {
+	user := newAclUser(DEFAULT_USER)
+	for _, rule := range []string{"on", "nopass", "allkeys", "allchannels", "allcommands"} {
+		_ = user.applyRule(rule)
+	}
+	return user
+}
+
+func NewACL() *ACL {
+	return &ACL{
+		users: map[string]*aclUser{
+			DEFAULT_USER: newDefaultAclUser(),
+		},
+	}
+}
+
+// SetUser creates or updates a user with the given rules.
+// the rules are applied atomically: if any of them is invalid the user is left untouched.
+func (acl *ACL) SetUser(name string, rules []string) error {
+	acl.mu.Lock()
+	defer acl.mu.Unlock()
+
+	user, ok := acl.users[name]
+	if ok {
+		user = user.clone()
+	} else {
+		user = newAclUser(name)
+	}
+
+	for _, rule := range rules {
+		if err := user.applyRule(rule); err != nil {
+			return fmt.Errorf("Error in ACL SETUSER modifier '%s': %s", rule, err.Error())
+		}
+	}
+
+	acl.users[name] = user
+	return nil
+}
+
+// DeleteUser removes a user, reporting whether it existed.
+func (acl *ACL) DeleteUser(name string) bool {
+	acl.mu.Lock()
+	defer acl.mu.Unlock()
+
+	if _, ok := acl.users[name]; !ok {
+		return false
+	}
+	delete(acl.users, name)
+	return true
+}
+
+func (acl *ACL) getUser(name string) (*aclUser, bool) {
+	acl.mu.RLock()
+	defer acl.mu.RUnlock()
+	user, ok := acl.users[name]
+	return user, ok
+}
+
+// UserNames returns the names of all users in alphabetical order.
+func (acl *ACL) UserNames() []string {
+	acl.mu.RLock()
+	defer acl.mu.RUnlock()
+
+	names := make([]string, 0, len(acl.users))
+	for name := range acl.users {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Describe returns the rules of every user, as shown by ACL LIST.
+func (acl *ACL) Describe() []string {
+	names := acl.UserNames()
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if user, ok := acl.getUser(name); ok {
+			result = append(result, user.describe())
+		}
+	}
+	return result
+}
+
+// Authenticate checks the credentials of a user.
+func (acl *ACL) Authenticate(name string, password string) bool {
+	user, ok := acl.getUser(name)
+	if !ok || !user.enabled {
+		return false
+	}
+	return user.checkPassword(password)
+}
+
+// DefaultUserRequiresAuth reports whether new connections have to authenticate before running commands.
+func (acl *ACL) DefaultUserRequiresAuth() bool {
+	user, ok := acl.getUser(DEFAULT_USER)
+	return !ok || !user.enabled || !user.noPass
+}
+
+// SetRequirePass applies the requirepass configuration to the default user.
+func (acl *ACL) SetRequirePass(password string) {
+	rules := []string{"resetpass", "nopass"}
+	if password != "" {
+		rules = []string{"resetpass", ">" + password}
+	}
+	_ = acl.SetUser(DEFAULT_USER, rules)
+}
+
+// CheckPermissions verifies that a user may run a command on the given keys.
+func (acl *ACL) CheckPermissions(name string, command string, keys []string) error {
+	user, ok := acl.getUser(name)
+	if !ok {
+		return fmt.Errorf("NOPERM User %s has no permissions to run the '%s' command", name, strings.ToLower(command))
+	}
+
+	if !user.canRunCommand(command) {
+		return fmt.Errorf("NOPERM User %s has no permissions to run the '%s' command", name, strings.ToLower(command))
+	}
+
+	for _, key := range keys {
+		if !user.canAccessKey(key) {
+			return fmt.Errorf("NOPERM No permissions to access a key")
+		}
+	}
+
+	return nil
+}
+
+// CheckChannelPermissions verifies that a user may access the given channels.
+func (acl *ACL) CheckChannelPermissions(name string, channels []string) error {
+	user, ok := acl.getUser(name)
+	if !ok {
+		return fmt.Errorf("NOPERM No permissions to access a channel")
+	}
+
+	for _, channel := range channels {
+		if !user.canAccessChannel(channel) {
+			return fmt.Errorf("NOPERM No permissions to access a channel")
+		}
+	}
+	return nil
+}
+
+// LoadFile replaces all users with the ones defined in an ACL file, except for the default user when the file
+// doesn't define it. every line of the file has the form "user <name> <rules>...", blank lines and comments are ignored.
+func (acl *ACL) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	users := map[string]*aclUser{}
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if fields[0] != "user" || len(fields) < 2 {
+			return fmt.Errorf("%s:%d: should start with user keyword", path, lineNum)
+		}
+
+		user := newAclUser(fields[1])
+		for _, rule := range fields[2:] {
+			if err := user.applyRule(rule); err != nil {
+				return fmt.Errorf("%s:%d: Error in user declaration '%s': %s", path, lineNum, rule, err.Error())
+			}
+		}
+		users[user.name] = user
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	acl.mu.Lock()
+	defer acl.mu.Unlock()
+	// the default user keeps its current rules, like the password set by requirepass, when the file doesn't
+	// define it
+	if _, ok := users[DEFAULT_USER]; !ok {
+		users[DEFAULT_USER] = acl.users[DEFAULT_USER].clone()
+	}
+	acl.users = users
+	return nil
+}
+
+// SaveFile writes all users to an ACL file.
+func (acl *ACL) SaveFile(path string) error {
+	contents := strings.Join(acl.Describe(), "\n") + "\n"
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(contents), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+var (
+	WRONGPASS            = data.Error{ErrMsg: "WRONGPASS invalid username-password pair or user is disabled."}
+	NO_ACL_FILE          = data.Error{ErrMsg: "This Redis instance is not configured to use an ACL file. You may want to specify users via the ACL SETUSER command and then issue a CONFIG REWRITE (assuming you have a Redis configuration file set) in order to store users in the Redis configuration."}
+	DEFAULT_USER_NO_PASS = data.Error{ErrMsg: "AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?"}
+)
+
+// https://redis.io/docs/latest/commands/auth/
+func handleAuth(cmdArray data.Array, client *Client, acl *ACL) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+
+	var user, password string
+	switch len(cmdArray.Elements) {
+	case 2:
+		if !acl.DefaultUserRequiresAuth() {
+			return DEFAULT_USER_NO_PASS
+		}
+		user = DEFAULT_USER
+		password = cmdArray.Elements[1].(data.BulkString).Data
+	case 3:
+		user = cmdArray.Elements[1].(data.BulkString).Data
+		password = cmdArray.Elements[2].(data.BulkString).Data
+	default:
+		return data.Error{ErrMsg: "syntax error"}
+	}
+
+	if !acl.Authenticate(user, password) {
+		return WRONGPASS
+	}
+
+	client.authenticate(user)
+	return OK
+}
+
+// https://redis.io/docs/latest/commands/acl/
+func handleAcl(cmdArray data.Array, client *Client, ch CommandHandler) data.Message {
+	subCommand := strings.ToUpper(cmdArray.Elements[1].(data.BulkString).Data)
+	args := make([]string, len(cmdArray.Elements)-2)
+	for idx := range args {
+		args[idx] = cmdArray.Elements[2+idx].(data.BulkString).Data
+	}
+
+	switch subCommand {
+	case "SETUSER":
+		if len(args) < 1 {
+			return data.Error{ErrMsg: "wrong number of arguments for 'acl|setuser' command"}
+		}
+		if err := ch.acl.SetUser(args[0], args[1:]); err != nil {
+			return data.Error{ErrMsg: err.Error()}
+		}
+		return OK
+	case "GETUSER":
+		if len(args) != 1 {
+			return data.Error{ErrMsg: "wrong number of arguments for 'acl|getuser' command"}
+		}
+		return handleAclGetUser(args[0], ch.acl)
+	case "DELUSER":
+		if len(args) < 1 {
+			return data.Error{ErrMsg: "wrong number of arguments for 'acl|deluser' command"}
+		}
+		return handleAclDelUser(args, client, ch)
+	case "LIST":
+		return bulkStringArray(ch.acl.Describe())
+	case "USERS":
+		return bulkStringArray(ch.acl.UserNames())
+	case "WHOAMI":
+		if client == nil {
+			return NO_CLIENT_CONN
+		}
+		return data.BulkString{Data: client.User()}
+	case "CAT":
+		return handleAclCat(args)
+	case "LOAD":
+		aclFile := ch.cfg.Get("aclfile")
+		if aclFile == "" {
+			return NO_ACL_FILE
+		}
+		if err := ch.acl.LoadFile(aclFile); err != nil {
+			return data.Error{ErrMsg: err.Error()}
+		}
+		return OK
+	case "SAVE":
+		aclFile := ch.cfg.Get("aclfile")
+		if aclFile == "" {
+			return NO_ACL_FILE
+		}
+		if err := ch.acl.SaveFile(aclFile); err != nil {
+			return data.Error{ErrMsg: "There was an error trying to save the ACLs. Please check the server logs for more information"}
+		}
+		return OK
+	default:
+		return data.Error{
+			ErrMsg: fmt.Sprintf("unsupported subcommand %s for %s", cmdArray.Elements[1].(data.BulkString).Data, CMD_ACL),
+		}
+	}
+}
+
+// https://redis.io/docs/latest/commands/acl-getuser/
+func handleAclGetUser(name string, acl *ACL) data.Message {
+	user, ok := acl.getUser(name)
+	if !ok {
+		return data.Null{}
+	}
+
+	flags := []string{"off"}
+	if user.enabled {
+		flags[0] = "on"
+	}
+	if user.noPass {
+		flags = append(flags, "nopass")
+	}
+
+	return data.Array{
+		Elements: []data.Message{
+			data.BulkString{Data: "flags"},
+			bulkStringArray(flags),
+			data.BulkString{Data: "passwords"},
+			bulkStringArray(user.passwordHashes),
+			data.BulkString{Data: "commands"},
+			data.BulkString{Data: user.commandsDescription()},
+			data.BulkString{Data: "keys"},
+			data.BulkString{Data: patternsDescription("~", user.keyPatterns)},
+			data.BulkString{Data: "channels"},
+			data.BulkString{Data: patternsDescription("&", user.channelPatterns)},
+		},
+	}
+}
+
+// https://redis.io/docs/latest/commands/acl-deluser/
+func handleAclDelUser(names []string, client *Client, ch CommandHandler) data.Message {
+	if slices.Contains(names, DEFAULT_USER) {
+		return data.Error{ErrMsg: "The 'default' user cannot be removed"}
+	}
+
+	deleted := int64(0)
+	for _, name := range names {
+		if !ch.acl.DeleteUser(name) {
+			continue
+		}
+		deleted++
+
+		// clients authenticated as a deleted user are disconnected
+		for _, c := range ch.clients.List() {
+			if c.User() == name {
+				c.kill(c == client)
+			}
+		}
+	}
+
+	return data.Integer{Value: deleted}
+}
+
+// https://redis.io/docs/latest/commands/acl-cat/
+func handleAclCat(args []string) data.Message {
+	switch len(args) {
+	case 0:
+		return bulkStringArray(ACL_CATEGORIES)
+	case 1:
+		category := strings.ToLower(args[0])
+		if !slices.Contains(ACL_CATEGORIES, category) {
+			return data.Error{ErrMsg: fmt.Sprintf("Unknown category '%s'", args[0])}
+		}
+		return bulkStringArray(aclCategoryCommands(category))
+	default:
+		return data.Error{ErrMsg: "wrong number of arguments for 'acl|cat' command"}
+	}
+}
+
+func bulkStringArray(values []string) data.Array {
+	elements := make([]data.Message, len(values))
+	for idx, value := range values {
+		elements[idx] = data.BulkString{Data: value}
+	}
+	return data.Array{Elements: elements}
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/append/
+func handleAppend(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+	value := cmd.Elements[2].(data.BulkString).Data
+
+	length, err := strg.Append(key, value)
+	if err != nil {
+		return storageError("append", err)
+	}
+	return data.Integer{Value: length}
+}
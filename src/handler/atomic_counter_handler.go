@@ -1,27 +1,71 @@
 package handler
 
 import (
+	"errors"
 	"log/slog"
+	"math"
+	"strconv"
 
 	"github.com/vrajashkr/cc-kv-go/src/data"
 	"github.com/vrajashkr/cc-kv-go/src/storage"
 )
 
+var NOT_A_FLOAT = data.Error{ErrMsg: "value is not a valid float"}
+
 // https://redis.io/docs/latest/commands/incr/
 // https://redis.io/docs/latest/commands/decr/
-func handleAtomicUnitDelta(cmdArray data.Array, strg storage.StorageEngine, isDecrement bool) data.Message {
+// https://redis.io/docs/latest/commands/incrby/
+// https://redis.io/docs/latest/commands/decrby/
+// INCR and DECR change the counter by one, INCRBY and DECRBY take the amount as an argument.
+func handleAtomicDelta(cmdArray data.Array, strg storage.StorageEngine, isDecrement bool) data.Message {
 	key := cmdArray.Elements[1].(data.BulkString)
 
 	delta := int64(1)
+	if len(cmdArray.Elements) > 2 {
+		parsed, err := strconv.ParseInt(cmdArray.Elements[2].(data.BulkString).Data, 10, 64)
+		if err != nil {
+			return NOT_AN_INTEGER
+		}
+		delta = parsed
+	}
+
 	if isDecrement {
-		delta = int64(-1)
+		// the negation of the smallest int64 does not fit in an int64
+		if delta == math.MinInt64 {
+			return data.Error{ErrMsg: "decrement would overflow"}
+		}
+		delta = -delta
 	}
 
 	res, err := strg.AtomicDelta(key.Data, delta)
 	if err != nil {
 		slog.Error("failed to execute atomic counter change", "error", err.Error())
-		return data.Error{ErrMsg: "value is not an integer or out of range"}
+		if errors.Is(err, storage.ErrOverflow) || errors.Is(err, storage.ErrWrongType) {
+			return data.Error{ErrMsg: err.Error()}
+		}
+		return NOT_AN_INTEGER
 	}
 
 	return data.Integer{Value: res}
 }
+
+// https://redis.io/docs/latest/commands/incrbyfloat/
+func handleAtomicFloatDelta(cmdArray data.Array, strg storage.StorageEngine) data.Message {
+	key := cmdArray.Elements[1].(data.BulkString)
+
+	delta, err := strconv.ParseFloat(cmdArray.Elements[2].(data.BulkString).Data, 64)
+	if err != nil || math.IsNaN(delta) || math.IsInf(delta, 0) {
+		return NOT_A_FLOAT
+	}
+
+	res, err := strg.AtomicFloatDelta(key.Data, delta)
+	if err != nil {
+		slog.Error("failed to execute atomic counter change", "error", err.Error())
+		if errors.Is(err, storage.ErrNaNOrInfinity) || errors.Is(err, storage.ErrWrongType) {
+			return data.Error{ErrMsg: err.Error()}
+		}
+		return NOT_A_FLOAT
+	}
+
+	return data.BulkString{Data: res}
+}
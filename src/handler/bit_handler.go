@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+var INVALID_BIT_OFFSET = data.Error{ErrMsg: "bit offset is not an integer or out of range"}
+
+// parseBitOffset parses a bit offset that must fit within the largest string, including the field of the given width
+// that starts at it. when the offset may be prefixed with # it is multiplied by the width, as used by BITFIELD.
+func parseBitOffset(arg string, allowHash bool, width int) (int64, bool) {
+	multiplier := int64(1)
+	if allowHash && strings.HasPrefix(arg, "#") {
+		arg = arg[1:]
+		multiplier = int64(width)
+	}
+
+	offset, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || offset < 0 || offset > math.MaxInt64/multiplier {
+		return 0, false
+	}
+	offset *= multiplier
+
+	if (offset+int64(max(width, 1))-1)>>3 >= STRING_MAX_LEN {
+		return 0, false
+	}
+	return offset, true
+}
+
+// https://redis.io/docs/latest/commands/setbit/
+func handleSetBit(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+	offset, ok := parseBitOffset(cmd.Elements[2].(data.BulkString).Data, false, 0)
+	if !ok {
+		return INVALID_BIT_OFFSET
+	}
+
+	var value bool
+	switch cmd.Elements[3].(data.BulkString).Data {
+	case "0":
+		value = false
+	case "1":
+		value = true
+	default:
+		return data.Error{ErrMsg: "bit is not an integer or out of range"}
+	}
+
+	previous, err := strg.SetBit(key, offset, value)
+	if err != nil {
+		return storageError("set bit", err)
+	}
+	return data.Integer{Value: previous}
+}
+
+// https://redis.io/docs/latest/commands/getbit/
+func handleGetBit(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+	offset, ok := parseBitOffset(cmd.Elements[2].(data.BulkString).Data, false, 0)
+	if !ok {
+		return INVALID_BIT_OFFSET
+	}
+
+	bit, err := strg.GetBit(key, offset)
+	if err != nil {
+		return storageError("retrieve data", err)
+	}
+	return data.Integer{Value: bit}
+}
+
+// parseBitRange parses the optional start, end and BYTE|BIT unit of BITCOUNT and BITPOS.
+// BITCOUNT requires the end whenever the start is given.
+func parseBitRange(args []data.Message, requireEnd bool) (storage.BitRange, data.Message) {
+	rng := storage.BitRange{}
+	if len(args) == 0 {
+		return rng, nil
+	}
+	if len(args) > 3 || (requireEnd && len(args) == 1) {
+		return rng, SYNTAX_ERROR
+	}
+
+	start, err := strconv.ParseInt(args[0].(data.BulkString).Data, 10, 64)
+	if err != nil {
+		return rng, NOT_AN_INTEGER
+	}
+	rng.Start, rng.HasStart = start, true
+
+	if len(args) >= 2 {
+		end, err := strconv.ParseInt(args[1].(data.BulkString).Data, 10, 64)
+		if err != nil {
+			return rng, NOT_AN_INTEGER
+		}
+		rng.End, rng.HasEnd = end, true
+	}
+
+	if len(args) == 3 {
+		switch strings.ToUpper(args[2].(data.BulkString).Data) {
+		case "BYTE":
+		case "BIT":
+			rng.IsBit = true
+		default:
+			return rng, SYNTAX_ERROR
+		}
+	}
+	return rng, nil
+}
+
+// https://redis.io/docs/latest/commands/bitcount/
+func handleBitCount(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+	rng, errMsg := parseBitRange(cmd.Elements[2:], true)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	count, err := strg.BitCount(key, rng)
+	if err != nil {
+		return storageError("count bits", err)
+	}
+	return data.Integer{Value: count}
+}
+
+// https://redis.io/docs/latest/commands/bitpos/
+func handleBitPos(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	var bit bool
+	switch cmd.Elements[2].(data.BulkString).Data {
+	case "0":
+		bit = false
+	case "1":
+		bit = true
+	default:
+		if _, err := strconv.ParseInt(cmd.Elements[2].(data.BulkString).Data, 10, 64); err != nil {
+			return NOT_AN_INTEGER
+		}
+		return data.Error{ErrMsg: "The bit argument must be 1 or 0."}
+	}
+
+	rng, errMsg := parseBitRange(cmd.Elements[3:], false)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	pos, err := strg.BitPos(key, bit, rng)
+	if err != nil {
+		return storageError("find bit", err)
+	}
+	return data.Integer{Value: pos}
+}
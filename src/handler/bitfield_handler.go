@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+var INVALID_BITFIELD_TYPE = data.Error{
+	ErrMsg: "Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is.",
+}
+
+// parseBitFieldType parses an encoding like i16 or u8. signed integers can be up to 64 bits wide
+// and unsigned ones up to 63 bits so that every value fits in a reply integer.
+func parseBitFieldType(arg string) (signed bool, width int, ok bool) {
+	if len(arg) < 2 {
+		return false, 0, false
+	}
+
+	switch arg[0] {
+	case 'i', 'I':
+		signed = true
+	case 'u', 'U':
+		signed = false
+	default:
+		return false, 0, false
+	}
+
+	width, err := strconv.Atoi(arg[1:])
+	if err != nil || width < 1 || (signed && width > 64) || (!signed && width > 63) {
+		return false, 0, false
+	}
+	return signed, width, true
+}
+
+// https://redis.io/docs/latest/commands/bitfield/
+func handleBitField(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	ops := []storage.BitFieldOp{}
+	overflow := storage.BITFIELD_OVERFLOW_WRAP
+	args := cmd.Elements[2:]
+	for idx := 0; idx < len(args); {
+		subCommand := strings.ToUpper(args[idx].(data.BulkString).Data)
+
+		if subCommand == "OVERFLOW" {
+			if idx+1 >= len(args) {
+				return SYNTAX_ERROR
+			}
+			switch strings.ToUpper(args[idx+1].(data.BulkString).Data) {
+			case "WRAP":
+				overflow = storage.BITFIELD_OVERFLOW_WRAP
+			case "SAT":
+				overflow = storage.BITFIELD_OVERFLOW_SAT
+			case "FAIL":
+				overflow = storage.BITFIELD_OVERFLOW_FAIL
+			default:
+				return data.Error{ErrMsg: "Invalid OVERFLOW type specified"}
+			}
+			idx += 2
+			continue
+		}
+
+		op := storage.BitFieldOp{Overflow: overflow}
+		numArgs := 4
+		switch subCommand {
+		case "GET":
+			op.Kind = storage.BITFIELD_GET
+			numArgs = 3
+		case "SET":
+			op.Kind = storage.BITFIELD_SET
+		case "INCRBY":
+			op.Kind = storage.BITFIELD_INCRBY
+		default:
+			return SYNTAX_ERROR
+		}
+		if idx+numArgs > len(args) {
+			return SYNTAX_ERROR
+		}
+
+		signed, width, ok := parseBitFieldType(args[idx+1].(data.BulkString).Data)
+		if !ok {
+			return INVALID_BITFIELD_TYPE
+		}
+		op.Signed, op.Bits = signed, width
+
+		offset, ok := parseBitOffset(args[idx+2].(data.BulkString).Data, true, width)
+		if !ok {
+			return INVALID_BIT_OFFSET
+		}
+		op.Offset = offset
+
+		if op.Kind != storage.BITFIELD_GET {
+			value, err := strconv.ParseInt(args[idx+3].(data.BulkString).Data, 10, 64)
+			if err != nil {
+				return NOT_AN_INTEGER
+			}
+			op.Value = value
+		}
+
+		ops = append(ops, op)
+		idx += numArgs
+	}
+
+	results, err := strg.BitField(key, ops)
+	if err != nil {
+		return storageError("update bitfield", err)
+	}
+
+	elements := make([]data.Message, len(results))
+	for idx, result := range results {
+		if result.Failed {
+			elements[idx] = data.Null{}
+			continue
+		}
+		elements[idx] = data.Integer{Value: result.Value}
+	}
+	return data.Array{Elements: elements}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/bitop/
+func handleBitOp(cmd data.Array, strg storage.StorageEngine) data.Message {
+	op := strings.ToUpper(cmd.Elements[1].(data.BulkString).Data)
+	destKey := cmd.Elements[2].(data.BulkString).Data
+
+	keys := make([]string, len(cmd.Elements)-3)
+	for idx := range keys {
+		keys[idx] = cmd.Elements[3+idx].(data.BulkString).Data
+	}
+
+	switch op {
+	case storage.BITOP_AND, storage.BITOP_OR, storage.BITOP_XOR:
+	case storage.BITOP_NOT:
+		if len(keys) != 1 {
+			return data.Error{ErrMsg: "BITOP NOT must be called with a single source key."}
+		}
+	default:
+		return SYNTAX_ERROR
+	}
+
+	length, err := strg.BitOp(op, destKey, keys)
+	if err != nil {
+		return storageError("combine bits", err)
+	}
+	return data.Integer{Value: length}
+}
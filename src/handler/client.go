@@ -0,0 +1,474 @@
+package handler
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+const (
+	DEFAULT_USER = "default"
+
+	CLIENT_PAUSE_MODE_ALL   = "ALL"
+	CLIENT_PAUSE_MODE_WRITE = "WRITE"
+
+	// the types of clients, as filtered by CLIENT LIST TYPE
+	CLIENT_TYPE_NORMAL  = "normal"
+	CLIENT_TYPE_MASTER  = "master"
+	CLIENT_TYPE_REPLICA = "replica"
+	CLIENT_TYPE_PUBSUB  = "pubsub"
+)
+
+// Client holds the state of a single connection to the server.
+type Client struct {
+	id        int64
+	conn      net.Conn
+	handler   CommandHandler
+	createdAt time.Time
+
+	mu              sync.Mutex
+	name            string
+	user            string
+	db              int
+	lastCmd         string
+	lastInteraction time.Time
+	queryBufLen     int
+	outputBufLen    int
+	closing         bool
+	authenticated   bool
+	monitoring      bool
+	// clientType tells replicas and the link to the master apart from normal clients
+	clientType string
+	// replicaPort is the port a replica announced with REPLCONF listening-port
+	replicaPort string
+	// asking is set by ASKING for the next command, which is then served while its slot is imported
+	asking bool
+
+	// outMu orders the replies and the pushed messages written to the connection. the messages pushed
+	// while a request is served are held until its reply has been written.
+	outMu   sync.Mutex
+	serving bool
+	held    []byte
+}
+
+// ServeRequest executes a request received on the client's connection and writes the reply, followed by the
+// messages pushed in the meantime, like the ones published to the channels the request subscribed to.
+func (c *Client) ServeRequest(request data.Message, pending int) {
+	c.mu.Lock()
+	c.lastInteraction = time.Now()
+	c.queryBufLen = pending
+	c.mu.Unlock()
+
+	c.outMu.Lock()
+	c.serving = true
+	c.outMu.Unlock()
+
+	result := c.handler.serveRequest(c, request)
+
+	c.mu.Lock()
+	c.outputBufLen = len(result)
+	c.lastInteraction = time.Now()
+	c.mu.Unlock()
+
+	c.outMu.Lock()
+	defer c.outMu.Unlock()
+	output := append([]byte(result), c.held...)
+	c.serving, c.held = false, nil
+	if len(output) > 0 {
+		_, _ = c.conn.Write(output)
+	}
+}
+
+// push writes a message the client didn't ask for, like a published message, which never overtakes the
+// reply to the request being served.
+func (c *Client) push(msg string) error {
+	c.outMu.Lock()
+	defer c.outMu.Unlock()
+	if c.serving {
+		c.held = append(c.held, msg...)
+		return nil
+	}
+	_, err := c.conn.Write([]byte(msg))
+	return err
+}
+
+// Closing reports whether the client asked for its own connection to be closed.
+func (c *Client) Closing() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closing
+}
+
+// OutputBufferClass returns the class used to pick the output buffer limits of the client.
+func (c *Client) OutputBufferClass() string {
+	switch c.Type() {
+	case CLIENT_TYPE_REPLICA:
+		return config.CLIENT_CLASS_REPLICA
+	case CLIENT_TYPE_PUBSUB:
+		return config.CLIENT_CLASS_PUBSUB
+	}
+	return config.CLIENT_CLASS_NORMAL
+}
+
+// IdleExempt reports whether the client only receives data, like replicas, subscribers and monitors, which
+// are kept open past the idle timeout.
+func (c *Client) IdleExempt() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.monitoring || c.clientType == CLIENT_TYPE_REPLICA || c.clientType == CLIENT_TYPE_PUBSUB
+}
+
+// Close removes the client from the registry once its connection is gone.
+func (c *Client) Close() {
+	c.handler.clients.unregister(c.id)
+	c.handler.monitors.remove(c.id)
+	c.handler.replication.removeReplica(c.id)
+	c.handler.pubsub.remove(c)
+	c.handler.tracking.disable(c)
+}
+
+func (c *Client) ID() int64 {
+	return c.id
+}
+
+func (c *Client) Name() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.name
+}
+
+func (c *Client) User() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.user
+}
+
+func (c *Client) DB() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.db
+}
+
+// Type returns the type of the client: normal, or master for the link of a replica with its master,
+// or replica for the replicas of this server, or pubsub for the clients subscribed to channels.
+func (c *Client) Type() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.clientType
+}
+
+func (c *Client) setType(clientType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clientType = clientType
+}
+
+func (c *Client) setAsking(asking bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.asking = asking
+}
+
+// takeAsking returns whether ASKING was sent before the current command, clearing it.
+func (c *Client) takeAsking() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	asking := c.asking
+	c.asking = false
+	return asking
+}
+
+func (c *Client) ReplicaPort() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.replicaPort
+}
+
+func (c *Client) setReplicaPort(port string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.replicaPort = port
+}
+
+func (c *Client) Authenticated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.authenticated
+}
+
+// authenticate switches the client to the given user.
+func (c *Client) authenticate(user string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.user = user
+	c.authenticated = true
+}
+
+func (c *Client) Addr() string {
+	if c.conn == nil {
+		return ""
+	}
+	// unix socket peers have no address of their own, the socket path is shown instead
+	if c.conn.LocalAddr().Network() == "unix" {
+		return c.conn.LocalAddr().String() + ":0"
+	}
+	return c.conn.RemoteAddr().String()
+}
+
+// monitorAddr returns the address of the client as shown by MONITOR.
+func (c *Client) monitorAddr() string {
+	if c.conn != nil && c.conn.LocalAddr().Network() == "unix" {
+		return "unix:" + c.conn.LocalAddr().String()
+	}
+	return c.Addr()
+}
+
+func (c *Client) LocalAddr() string {
+	if c.conn == nil {
+		return ""
+	}
+	return c.conn.LocalAddr().String()
+}
+
+func (c *Client) setLastCmd(cmd string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastCmd = cmd
+}
+
+// setMonitoring marks the client as streaming the executed commands.
+func (c *Client) setMonitoring() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.monitoring = true
+}
+
+func (c *Client) setName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.name = name
+}
+
+// kill closes the connection of the client.
+// if the client is the one executing the current command, the connection is closed after the reply is sent.
+func (c *Client) kill(isSelf bool) {
+	if isSelf {
+		c.mu.Lock()
+		c.closing = true
+		c.mu.Unlock()
+		return
+	}
+	if c.conn != nil {
+		_ = c.conn.Close()
+	}
+}
+
+// info renders the client in the format used by CLIENT LIST and CLIENT INFO.
+func (c *Client) info() string {
+	tracking, tracked := c.handler.tracking.state(c)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	flags := ""
+	switch {
+	case c.monitoring:
+		flags = "O"
+	case c.clientType == CLIENT_TYPE_REPLICA:
+		flags = "S"
+	case c.clientType == CLIENT_TYPE_MASTER:
+		flags = "M"
+	case c.clientType == CLIENT_TYPE_PUBSUB:
+		flags = "P"
+	}
+	if tracked {
+		flags += "t"
+		if tracking.brokenRedirect {
+			flags += "R"
+		}
+	}
+	if flags == "" {
+		flags = "N"
+	}
+
+	now := time.Now()
+	return fmt.Sprintf(
+		"id=%d addr=%s laddr=%s name=%s age=%d idle=%d flags=%s db=%d qbuf=%d obl=%d cmd=%s user=%s resp=2",
+		c.id,
+		c.Addr(),
+		c.LocalAddr(),
+		c.name,
+		int64(now.Sub(c.createdAt).Seconds()),
+		int64(now.Sub(c.lastInteraction).Seconds()),
+		flags,
+		c.db,
+		c.queryBufLen,
+		c.outputBufLen,
+		c.lastCmd,
+		c.user,
+	)
+}
+
+// ClientRegistry keeps track of all the clients connected to the server.
+type ClientRegistry struct {
+	mu      sync.RWMutex
+	nextId  int64
+	clients map[int64]*Client
+
+	pauseMu       sync.Mutex
+	pauseMode     string
+	pauseDeadline time.Time
+	pauseCh       chan struct{}
+}
+
+func NewClientRegistry() *ClientRegistry {
+	return &ClientRegistry{
+		clients: make(map[int64]*Client),
+	}
+}
+
+func (cr *ClientRegistry) register(conn net.Conn, ch CommandHandler, authenticated bool) *Client {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.nextId += 1
+	now := time.Now()
+	client := &Client{
+		id:              cr.nextId,
+		conn:            conn,
+		handler:         ch,
+		createdAt:       now,
+		lastInteraction: now,
+		user:            DEFAULT_USER,
+		authenticated:   authenticated,
+		clientType:      CLIENT_TYPE_NORMAL,
+	}
+	cr.clients[client.id] = client
+	return client
+}
+
+func (cr *ClientRegistry) unregister(id int64) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	delete(cr.clients, id)
+}
+
+func (cr *ClientRegistry) Get(id int64) (*Client, bool) {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	client, ok := cr.clients[id]
+	return client, ok
+}
+
+// List returns the connected clients ordered by ID.
+func (cr *ClientRegistry) List() []*Client {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	result := make([]*Client, 0, len(cr.clients))
+	for _, client := range cr.clients {
+		result = append(result, client)
+	}
+
+	slices.SortFunc(result, func(a, b *Client) int {
+		return cmp.Compare(a.id, b.id)
+	})
+	return result
+}
+
+func (cr *ClientRegistry) Count() int {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return len(cr.clients)
+}
+
+// Pause suspends command processing for all clients (or only for write commands) until the timeout elapses.
+func (cr *ClientRegistry) Pause(timeout time.Duration, mode string) {
+	cr.pauseMu.Lock()
+	defer cr.pauseMu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	if cr.pauseCh != nil && time.Now().Before(cr.pauseDeadline) {
+		// an existing pause is extended but never shortened, and ALL takes precedence over WRITE
+		if deadline.Before(cr.pauseDeadline) {
+			deadline = cr.pauseDeadline
+		}
+		if cr.pauseMode == CLIENT_PAUSE_MODE_ALL {
+			mode = CLIENT_PAUSE_MODE_ALL
+		}
+	} else {
+		if cr.pauseCh != nil {
+			close(cr.pauseCh)
+		}
+		cr.pauseCh = make(chan struct{})
+	}
+	cr.pauseMode = mode
+	cr.pauseDeadline = deadline
+}
+
+// Unpause resumes command processing for all paused clients.
+func (cr *ClientRegistry) Unpause() {
+	cr.pauseMu.Lock()
+	defer cr.pauseMu.Unlock()
+
+	if cr.pauseCh != nil {
+		close(cr.pauseCh)
+		cr.pauseCh = nil
+	}
+}
+
+// waitIfPaused blocks the caller while clients are paused for the given kind of command.
+func (cr *ClientRegistry) waitIfPaused(isWrite bool) {
+	for {
+		cr.pauseMu.Lock()
+		pauseCh := cr.pauseCh
+		deadline := cr.pauseDeadline
+		mode := cr.pauseMode
+		cr.pauseMu.Unlock()
+
+		remaining := time.Until(deadline)
+		if pauseCh == nil || remaining <= 0 || (mode == CLIENT_PAUSE_MODE_WRITE && !isWrite) {
+			return
+		}
+
+		timer := time.NewTimer(remaining)
+		select {
+		case <-pauseCh:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// matchesKillFilter checks a single CLIENT KILL filter against the client.
+func (c *Client) matchesKillFilter(filter string, value string) (bool, error) {
+	switch filter {
+	case "ID":
+		return fmt.Sprintf("%d", c.id) == value, nil
+	case "ADDR":
+		return c.Addr() == value, nil
+	case "LADDR":
+		return c.LocalAddr() == value, nil
+	case "USER":
+		return c.User() == value, nil
+	case "TYPE":
+		return c.Type() == value, nil
+	default:
+		return false, fmt.Errorf("syntax error")
+	}
+}
+
+// cmdName renders the command name the way it is shown in CLIENT LIST.
+func cmdName(cmd string, subCmd string) string {
+	if subCmd == "" {
+		return strings.ToLower(cmd)
+	}
+	return strings.ToLower(cmd) + "|" + strings.ToLower(subCmd)
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+var NO_CLIENT_CONN = data.Error{ErrMsg: "this command requires a client connection"}
+
+// https://redis.io/docs/latest/commands/client/
+func handleClient(cmdArray data.Array, client *Client, clients *ClientRegistry, tracking *Tracking) data.Message {
+	subCommand := strings.ToUpper(cmdArray.Elements[1].(data.BulkString).Data)
+	args := make([]string, len(cmdArray.Elements)-2)
+	for idx := range args {
+		args[idx] = cmdArray.Elements[2+idx].(data.BulkString).Data
+	}
+
+	switch subCommand {
+	case "ID":
+		return handleClientId(client)
+	case "SETNAME":
+		return handleClientSetName(args, client)
+	case "GETNAME":
+		return handleClientGetName(client)
+	case "INFO":
+		if client == nil {
+			return NO_CLIENT_CONN
+		}
+		return data.BulkString{Data: client.info() + "\n"}
+	case "LIST":
+		return handleClientList(args, clients)
+	case "KILL":
+		return handleClientKill(args, client, clients)
+	case "PAUSE":
+		return handleClientPause(args, clients)
+	case "UNPAUSE":
+		clients.Unpause()
+		return OK
+	case "TRACKING":
+		return handleClientTracking(args, client, clients, tracking)
+	case "CACHING":
+		return handleClientCaching(args, client, tracking)
+	case "GETREDIR":
+		return handleClientGetRedir(client, tracking)
+	case "TRACKINGINFO":
+		return handleClientTrackingInfo(client, tracking)
+	default:
+		return data.Error{
+			ErrMsg: fmt.Sprintf("unsupported subcommand %s for %s", cmdArray.Elements[1].(data.BulkString).Data, CMD_CLIENT),
+		}
+	}
+}
+
+// https://redis.io/docs/latest/commands/client-id/
+func handleClientId(client *Client) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+	return data.Integer{Value: client.ID()}
+}
+
+// https://redis.io/docs/latest/commands/client-setname/
+func handleClientSetName(args []string, client *Client) data.Message {
+	if len(args) != 1 {
+		return data.Error{ErrMsg: "wrong number of arguments for 'client|setname' command"}
+	}
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+
+	name := args[0]
+	for _, r := range name {
+		if r <= ' ' || r > '~' {
+			return data.Error{ErrMsg: "Client names cannot contain spaces, newlines or special characters."}
+		}
+	}
+
+	client.setName(name)
+	return OK
+}
+
+// https://redis.io/docs/latest/commands/client-getname/
+func handleClientGetName(client *Client) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+
+	name := client.Name()
+	if name == "" {
+		return data.Null{}
+	}
+	return data.BulkString{Data: name}
+}
+
+// https://redis.io/docs/latest/commands/client-list/
+func handleClientList(args []string, clients *ClientRegistry) data.Message {
+	var idFilter map[int64]bool
+	typeFilter := ""
+
+	for idx := 0; idx < len(args); idx++ {
+		switch strings.ToUpper(args[idx]) {
+		case "TYPE":
+			if idx+1 >= len(args) {
+				return data.Error{ErrMsg: "syntax error"}
+			}
+			idx++
+			typeFilter = strings.ToLower(args[idx])
+			switch typeFilter {
+			case CLIENT_TYPE_NORMAL, CLIENT_TYPE_MASTER, CLIENT_TYPE_REPLICA, CLIENT_TYPE_PUBSUB:
+			default:
+				return data.Error{ErrMsg: fmt.Sprintf("Unknown client type '%s'", args[idx])}
+			}
+		case "ID":
+			if idx+1 >= len(args) {
+				return data.Error{ErrMsg: "syntax error"}
+			}
+			idFilter = make(map[int64]bool)
+			for idx+1 < len(args) {
+				idx++
+				id, err := strconv.ParseInt(args[idx], 10, 64)
+				if err != nil || id <= 0 {
+					return data.Error{ErrMsg: "Invalid client ID"}
+				}
+				idFilter[id] = true
+			}
+		default:
+			return data.Error{ErrMsg: "syntax error"}
+		}
+	}
+
+	var sb strings.Builder
+	for _, c := range clients.List() {
+		if idFilter != nil && !idFilter[c.ID()] {
+			continue
+		}
+		if typeFilter != "" && c.Type() != typeFilter {
+			continue
+		}
+		sb.WriteString(c.info())
+		sb.WriteString("\n")
+	}
+	return data.BulkString{Data: sb.String()}
+}
+
+// https://redis.io/docs/latest/commands/client-kill/
+func handleClientKill(args []string, client *Client, clients *ClientRegistry) data.Message {
+	if len(args) == 0 {
+		return data.Error{ErrMsg: "wrong number of arguments for 'client|kill' command"}
+	}
+
+	// old form: CLIENT KILL addr:port
+	if len(args) == 1 {
+		for _, c := range clients.List() {
+			if c.Addr() == args[0] {
+				c.kill(c == client)
+				return OK
+			}
+		}
+		return data.Error{ErrMsg: "No such client"}
+	}
+
+	// new form: CLIENT KILL <filter> <value> [<filter> <value> ...]
+	if len(args)%2 != 0 {
+		return data.Error{ErrMsg: "syntax error"}
+	}
+
+	skipMe := true
+	filters := [][2]string{}
+	for idx := 0; idx < len(args); idx += 2 {
+		filter := strings.ToUpper(args[idx])
+		value := args[idx+1]
+		switch filter {
+		case "SKIPME":
+			switch strings.ToLower(value) {
+			case "yes":
+				skipMe = true
+			case "no":
+				skipMe = false
+			default:
+				return data.Error{ErrMsg: "syntax error"}
+			}
+		case "ID":
+			if id, err := strconv.ParseInt(value, 10, 64); err != nil || id <= 0 {
+				return data.Error{ErrMsg: "client-id should be greater than 0"}
+			}
+			filters = append(filters, [2]string{filter, value})
+		case "TYPE":
+			switch strings.ToLower(value) {
+			case CLIENT_TYPE_NORMAL, CLIENT_TYPE_MASTER, CLIENT_TYPE_REPLICA, CLIENT_TYPE_PUBSUB:
+			default:
+				return data.Error{ErrMsg: fmt.Sprintf("Unknown client type '%s'", value)}
+			}
+			filters = append(filters, [2]string{filter, strings.ToLower(value)})
+		case "ADDR", "LADDR", "USER":
+			filters = append(filters, [2]string{filter, value})
+		default:
+			return data.Error{ErrMsg: "syntax error"}
+		}
+	}
+
+	killed := int64(0)
+	for _, c := range clients.List() {
+		isSelf := c == client
+		if isSelf && skipMe {
+			continue
+		}
+
+		matches := true
+		for _, f := range filters {
+			ok, err := c.matchesKillFilter(f[0], f[1])
+			if err != nil {
+				return data.Error{ErrMsg: err.Error()}
+			}
+			if !ok {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			c.kill(isSelf)
+			killed++
+		}
+	}
+
+	return data.Integer{Value: killed}
+}
+
+// https://redis.io/docs/latest/commands/client-pause/
+func handleClientPause(args []string, clients *ClientRegistry) data.Message {
+	if len(args) < 1 || len(args) > 2 {
+		return data.Error{ErrMsg: "wrong number of arguments for 'client|pause' command"}
+	}
+
+	timeoutMillis, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || timeoutMillis < 0 {
+		return data.Error{ErrMsg: "timeout is not an integer or out of range"}
+	}
+
+	mode := CLIENT_PAUSE_MODE_ALL
+	if len(args) == 2 {
+		mode = strings.ToUpper(args[1])
+		if mode != CLIENT_PAUSE_MODE_ALL && mode != CLIENT_PAUSE_MODE_WRITE {
+			return data.Error{ErrMsg: "syntax error"}
+		}
+	}
+
+	clients.Pause(time.Duration(timeoutMillis)*time.Millisecond, mode)
+	return OK
+}
@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/cluster"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+var (
+	CLUSTER_DISABLED = data.Error{ErrMsg: "This instance has cluster support disabled"}
+	CROSSSLOT        = data.Error{ErrMsg: "CROSSSLOT Keys in request don't hash to the same slot"}
+	CLUSTER_DOWN     = data.Error{ErrMsg: "CLUSTERDOWN The cluster is down"}
+	SLOT_NOT_SERVED  = data.Error{ErrMsg: "CLUSTERDOWN Hash slot not served"}
+	TRYAGAIN         = data.Error{ErrMsg: "TRYAGAIN Multiple keys request during rehashing of slot"}
+	INVALID_SLOT     = data.Error{ErrMsg: "Invalid or out of range slot"}
+)
+
+// clusterRedirect checks that this node serves the keys of a command. in cluster mode, the keys of a command
+// must all be in the same slot, and clients are redirected with MOVED to the node serving the slot. while a
+// slot is migrated, the keys missing from the source node are looked up on the target node with ASK, where
+// the client sends ASKING before the command to be served.
+func (ch CommandHandler) clusterRedirect(client *Client, spec *commandSpec, cmdArray data.Array, asking bool) data.Message {
+	if ch.cluster == nil || (client != nil && client.Type() == CLIENT_TYPE_MASTER) {
+		return nil
+	}
+	keys := spec.keys(bulkStrings(cmdArray.Elements))
+	if len(keys) == 0 {
+		return nil
+	}
+
+	slot := cluster.KeySlot(keys[0])
+	for _, key := range keys[1:] {
+		if cluster.KeySlot(key) != slot {
+			return CROSSSLOT
+		}
+	}
+	route := ch.cluster.Route(slot)
+	if !route.Assigned {
+		return SLOT_NOT_SERVED
+	}
+	if !ch.cluster.StateOK() {
+		return CLUSTER_DOWN
+	}
+
+	switch {
+	case route.Mine && route.MigratingTo == "":
+		return nil
+	case strings.EqualFold(spec.name, CMD_MIGRATE) && (route.Mine || route.Importing):
+		// MIGRATE moves the keys of a slot, whichever of them are still here
+		return nil
+	case route.Mine:
+		existing, _ := ch.strgEngine.Exists(keys)
+		switch {
+		case existing == len(keys):
+			return nil
+		case existing > 0:
+			return TRYAGAIN
+		default:
+			return data.Error{ErrMsg: fmt.Sprintf("ASK %d %s", slot, route.MigratingTo)}
+		}
+	case route.Importing && asking:
+		if existing, _ := ch.strgEngine.Exists(keys); len(keys) > 1 && existing < len(keys) {
+			return TRYAGAIN
+		}
+		return nil
+	default:
+		return data.Error{ErrMsg: fmt.Sprintf("MOVED %d %s", slot, route.Addr)}
+	}
+}
+
+// https://redis.io/docs/latest/commands/asking/
+func handleAsking(ch CommandHandler, client *Client) data.Message {
+	if ch.cluster == nil {
+		return CLUSTER_DISABLED
+	}
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+	client.setAsking(true)
+	return OK
+}
+
+// https://redis.io/docs/latest/commands/cluster/
+func handleCluster(ch CommandHandler, cmd data.Array) data.Message {
+	if ch.cluster == nil {
+		return CLUSTER_DISABLED
+	}
+
+	args := bulkStrings(cmd.Elements[1:])
+	switch strings.ToUpper(args[0]) {
+	case "MYID":
+		return data.BulkString{Data: ch.cluster.MyID()}
+	case "KEYSLOT":
+		return data.Integer{Value: int64(cluster.KeySlot(args[1]))}
+	case "INFO":
+		return data.BulkString{Data: ch.cluster.Info()}
+	case "NODES":
+		return data.BulkString{Data: ch.cluster.Description()}
+	case "SLOTS":
+		return handleClusterSlots(ch.cluster)
+	case "SHARDS":
+		return handleClusterShards(ch)
+	case "MEET":
+		return handleClusterMeet(args[1:], ch.cluster)
+	case "ADDSLOTS", "DELSLOTS":
+		return handleClusterSlotsUpdate(args, ch.cluster, false)
+	case "ADDSLOTSRANGE", "DELSLOTSRANGE":
+		return handleClusterSlotsUpdate(args, ch.cluster, true)
+	case "SETSLOT":
+		return handleClusterSetSlot(args[1:], ch)
+	case "COUNTKEYSINSLOT":
+		slot, err := strconv.Atoi(args[1])
+		if err != nil || slot < 0 || slot >= cluster.CLUSTER_SLOTS {
+			return data.Error{ErrMsg: "Invalid slot"}
+		}
+		return data.Integer{Value: int64(len(ch.keysInSlot(slot, -1)))}
+	case "GETKEYSINSLOT":
+		slot, slotErr := strconv.Atoi(args[1])
+		count, countErr := strconv.Atoi(args[2])
+		if slotErr != nil || countErr != nil || slot < 0 || slot >= cluster.CLUSTER_SLOTS || count < 0 {
+			return data.Error{ErrMsg: "Invalid slot or number of keys"}
+		}
+		keys := ch.keysInSlot(slot, count)
+		elements := make([]data.Message, len(keys))
+		for idx, key := range keys {
+			elements[idx] = data.BulkString{Data: key}
+		}
+		return data.Array{Elements: elements}
+	default:
+		return data.Error{ErrMsg: fmt.Sprintf("unsupported subcommand %s for CLUSTER", args[0])}
+	}
+}
+
+// keysInSlot returns up to limit keys of a slot, or all of them with a negative limit.
+func (ch CommandHandler) keysInSlot(slot int, limit int) []string {
+	keys := []string{}
+	for _, key := range ch.strgEngine.Keys() {
+		if len(keys) == limit {
+			break
+		}
+		if cluster.KeySlot(key) == slot {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// https://redis.io/docs/latest/commands/cluster-slots/
+func handleClusterSlots(c *cluster.Cluster) data.Message {
+	elements := []data.Message{}
+	for _, owner := range c.Slots() {
+		elements = append(elements, data.Array{Elements: []data.Message{
+			data.Integer{Value: int64(owner.Start)},
+			data.Integer{Value: int64(owner.End)},
+			data.Array{Elements: []data.Message{
+				data.BulkString{Data: owner.Node.IP},
+				data.Integer{Value: int64(owner.Node.Port)},
+				data.BulkString{Data: owner.Node.ID},
+			}},
+		}})
+	}
+	return data.Array{Elements: elements}
+}
+
+// https://redis.io/docs/latest/commands/cluster-shards/
+// every node is a shard of its own, since nodes have no replicas in the cluster.
+func handleClusterShards(ch CommandHandler) data.Message {
+	myID := ch.cluster.MyID()
+	shards := []data.Message{}
+	for _, node := range ch.cluster.Nodes() {
+		if slices.Contains(node.Flags, cluster.NODE_FLAG_HANDSHAKE) {
+			continue
+		}
+
+		slots := []data.Message{}
+		for _, r := range node.Slots {
+			slots = append(slots, data.Integer{Value: int64(r.Start)}, data.Integer{Value: int64(r.End)})
+		}
+		health := "online"
+		if node.Failed() {
+			health = "failed"
+		}
+		// the replication offset is only known for this node
+		offset := int64(0)
+		if node.ID == myID {
+			offset = ch.replication.Offset()
+		}
+
+		shards = append(shards, data.Array{Elements: []data.Message{
+			data.BulkString{Data: "slots"},
+			data.Array{Elements: slots},
+			data.BulkString{Data: "nodes"},
+			data.Array{Elements: []data.Message{data.Array{Elements: []data.Message{
+				data.BulkString{Data: "id"},
+				data.BulkString{Data: node.ID},
+				data.BulkString{Data: "port"},
+				data.Integer{Value: int64(node.Port)},
+				data.BulkString{Data: "ip"},
+				data.BulkString{Data: node.IP},
+				data.BulkString{Data: "endpoint"},
+				data.BulkString{Data: node.IP},
+				data.BulkString{Data: "role"},
+				data.BulkString{Data: "master"},
+				data.BulkString{Data: "replication-offset"},
+				data.Integer{Value: offset},
+				data.BulkString{Data: "health"},
+				data.BulkString{Data: health},
+			}}}},
+		}})
+	}
+	return data.Array{Elements: shards}
+}
+
+// https://redis.io/docs/latest/commands/cluster-meet/
+func handleClusterMeet(args []string, c *cluster.Cluster) data.Message {
+	if len(args) > 3 {
+		return SYNTAX_ERROR
+	}
+	port, err := strconv.Atoi(args[1])
+	if err != nil {
+		return data.Error{ErrMsg: fmt.Sprintf("Invalid base port specified: %s", args[1])}
+	}
+	busPort := port + cluster.BUS_PORT_OFFSET
+	if len(args) == 3 {
+		if busPort, err = strconv.Atoi(args[2]); err != nil {
+			return data.Error{ErrMsg: fmt.Sprintf("Invalid bus port specified: %s", args[2])}
+		}
+	}
+
+	if err := c.Meet(args[0], port, busPort); err != nil {
+		return data.Error{ErrMsg: err.Error()}
+	}
+	return OK
+}
+
+// handleClusterSlotsUpdate serves ADDSLOTS and DELSLOTS, along with their variants taking ranges of slots.
+func handleClusterSlotsUpdate(args []string, c *cluster.Cluster, isRange bool) data.Message {
+	subCommand := strings.ToUpper(args[0])
+	values := make([]int, len(args)-1)
+	for idx, arg := range args[1:] {
+		value, err := strconv.Atoi(arg)
+		if err != nil || value < 0 || value >= cluster.CLUSTER_SLOTS {
+			return INVALID_SLOT
+		}
+		values[idx] = value
+	}
+
+	slots := values
+	if isRange {
+		if len(values)%2 != 0 {
+			return data.Error{ErrMsg: fmt.Sprintf("wrong number of arguments for 'cluster|%s' command", strings.ToLower(subCommand))}
+		}
+		slots = []int{}
+		for idx := 0; idx < len(values); idx += 2 {
+			start, end := values[idx], values[idx+1]
+			if start > end {
+				return data.Error{ErrMsg: fmt.Sprintf("start slot number %d is greater than end slot number %d", start, end)}
+			}
+			for slot := start; slot <= end; slot++ {
+				slots = append(slots, slot)
+			}
+		}
+	}
+
+	var err error
+	if strings.HasPrefix(subCommand, "ADD") {
+		err = c.AddSlots(slots)
+	} else {
+		err = c.DelSlots(slots)
+	}
+	if err != nil {
+		return data.Error{ErrMsg: err.Error()}
+	}
+	return OK
+}
+
+// https://redis.io/docs/latest/commands/cluster-setslot/
+func handleClusterSetSlot(args []string, ch CommandHandler) data.Message {
+	slot, err := strconv.Atoi(args[0])
+	if err != nil || slot < 0 || slot >= cluster.CLUSTER_SLOTS {
+		return INVALID_SLOT
+	}
+
+	action := strings.ToUpper(args[1])
+	switch {
+	case action == "MIGRATING" && len(args) == 3:
+		err = ch.cluster.SetSlotMigrating(slot, args[2])
+	case action == "IMPORTING" && len(args) == 3:
+		err = ch.cluster.SetSlotImporting(slot, args[2])
+	case action == "STABLE" && len(args) == 2:
+		err = ch.cluster.SetSlotStable(slot)
+	case action == "NODE" && len(args) == 3:
+		err = ch.cluster.SetSlotNode(slot, args[2], len(ch.keysInSlot(slot, 1)) > 0)
+	default:
+		return data.Error{ErrMsg: "Invalid CLUSTER SETSLOT action or number of arguments. Try CLUSTER HELP"}
+	}
+	if err != nil {
+		return data.Error{ErrMsg: err.Error()}
+	}
+	return OK
+}
@@ -1,154 +1,402 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"strings"
+	"time"
 
+	"github.com/vrajashkr/cc-kv-go/src/cluster"
+	"github.com/vrajashkr/cc-kv-go/src/config"
 	"github.com/vrajashkr/cc-kv-go/src/data"
 	"github.com/vrajashkr/cc-kv-go/src/storage"
 )
 
 const (
-	CMD_PING         = "PING"
-	CMD_HELLO        = "HELLO"
-	CMD_ECHO         = "ECHO"
-	CMD_SET          = "SET"
-	CMD_SET_OPT_EX   = "EX"
-	CMD_SET_OPT_EXAT = "EXAT"
-	CMD_SET_OPT_PX   = "PX"
-	CMD_SET_OPT_PXAT = "PXAT"
-	CMD_GET          = "GET"
-	CMD_CONFIG       = "CONFIG"
-	CMD_EXISTS       = "EXISTS"
-	CMD_DELETE       = "DEL"
-	CMD_INCR         = "INCR"
-	CMD_DECR         = "DECR"
-	CMD_LPUSH        = "LPUSH"
-	CMD_RPUSH        = "RPUSH"
+	CMD_PING              = "PING"
+	CMD_HELLO             = "HELLO"
+	CMD_ECHO              = "ECHO"
+	CMD_SET               = "SET"
+	CMD_SET_OPT_EX        = "EX"
+	CMD_SET_OPT_EXAT      = "EXAT"
+	CMD_SET_OPT_PX        = "PX"
+	CMD_SET_OPT_PXAT      = "PXAT"
+	CMD_GET               = "GET"
+	CMD_CONFIG            = "CONFIG"
+	CMD_EXISTS            = "EXISTS"
+	CMD_DELETE            = "DEL"
+	CMD_INCR              = "INCR"
+	CMD_DECR              = "DECR"
+	CMD_LPUSH             = "LPUSH"
+	CMD_RPUSH             = "RPUSH"
+	CMD_CLIENT            = "CLIENT"
+	CMD_AUTH              = "AUTH"
+	CMD_ACL               = "ACL"
+	CMD_SLOWLOG           = "SLOWLOG"
+	CMD_MONITOR           = "MONITOR"
+	CMD_COMMAND           = "COMMAND"
+	CMD_APPEND            = "APPEND"
+	CMD_GETEX             = "GETEX"
+	CMD_SETEX             = "SETEX"
+	CMD_PSETEX            = "PSETEX"
+	CMD_GETEX_OPT_PERSIST = "PERSIST"
+	CMD_REPLCONF          = "REPLCONF"
+	CMD_PSYNC             = "PSYNC"
+	CMD_ASKING            = "ASKING"
+	CMD_MIGRATE           = "MIGRATE"
 )
 
 var (
 	INVALID_CMD_FMT  = data.Error{ErrMsg: "invalid format for command"}
 	INVALID_CMD_ARGS = data.Error{ErrMsg: "invalid args for command"}
 	OK               = data.SimpleString{Contents: "OK"}
+	NOT_AN_INTEGER   = data.Error{ErrMsg: "value is not an integer or out of range"}
+	SYNTAX_ERROR     = data.Error{ErrMsg: "syntax error"}
 )
 
-// for commands that have a minimum arg count, an entry is added to this map.
-// if there is no entry for that command, it is assumed that there is no minimum argument count for it.
-var CMD_MIN_ARGS = map[string]int{
-	CMD_INCR:   1,
-	CMD_DECR:   1,
-	CMD_CONFIG: 1,
-	CMD_DELETE: 1,
-	CMD_ECHO:   1,
-	CMD_EXISTS: 1,
-	CMD_GET:    1,
-	CMD_LPUSH:  2,
-	CMD_RPUSH:  2,
-	CMD_SET:    2,
-}
-
-func validateCommand(cmd data.Array) error {
+// the storage errors that are replied as they are, since they match the errors of redis.
+var REPLIED_STORAGE_ERRORS = []error{
+	storage.ErrWrongType,
+	storage.ErrNotHyperLogLog,
+	storage.ErrCorruptHyperLogLog,
+	storage.ErrGeoMemberNotFound,
+	storage.ErrStreamIDTooSmall,
+	storage.ErrStreamIDZero,
+	storage.ErrStreamIDExhausted,
+	storage.ErrNoSuchKey,
+	storage.ErrNoGroup,
+	storage.ErrBusyGroup,
+	storage.ErrInvalidDump,
+	storage.ErrBusyKey,
+}
+
+// storageError turns an error from the storage engine into a reply. the errors that redis replies with,
+// such as WRONGTYPE, are passed on as they are so that clients can recognise their error code.
+func storageError(action string, err error) data.Message {
+	for _, replied := range REPLIED_STORAGE_ERRORS {
+		if errors.Is(err, replied) {
+			return data.Error{ErrMsg: err.Error()}
+		}
+	}
+	return data.Error{ErrMsg: "failed to " + action + " due to error: " + err.Error()}
+}
+
+// validateCommand checks the format and the arity of a command against the command table
+// and returns the spec of the command.
+func validateCommand(cmd data.Array) (*commandSpec, error) {
+	if len(cmd.Elements) == 0 {
+		return nil, fmt.Errorf("invalid format for command")
+	}
+
 	// check that all the entries are BulkString
 	for _, element := range cmd.Elements {
 		_, ok := element.(data.BulkString)
 		if !ok {
-			return fmt.Errorf("invalid format for command")
+			return nil, fmt.Errorf("invalid format for command")
 		}
 	}
 
+	// command names are case insensitive
 	command := cmd.Elements[0].(data.BulkString).Data
+	spec, ok := lookupCommand(strings.ToUpper(command))
+	if !ok {
+		return nil, fmt.Errorf("unsupported command %s", command)
+	}
 
-	// check that the command has the correct minimum number of args
-	numArgs := CMD_MIN_ARGS[command]
+	if err := spec.checkArity(len(cmd.Elements)); err != nil {
+		return nil, err
+	}
 
-	if len(cmd.Elements) < numArgs+1 {
-		return fmt.Errorf("wrong number of arguments for '%s' command", strings.ToLower(command))
+	// subcommands have an arity of their own. unknown subcommands are reported by the command handlers.
+	if len(spec.subcommands) > 0 && len(cmd.Elements) > 1 {
+		if subSpec, ok := spec.subcommand(cmd.Elements[1].(data.BulkString).Data); ok {
+			if err := subSpec.checkArity(len(cmd.Elements)); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	return nil
+	return spec, nil
 }
 
 type CommandHandler struct {
-	strgEngine storage.StorageEngine
+	strgEngine  storage.StorageEngine
+	cfg         *config.Config
+	clients     *ClientRegistry
+	acl         *ACL
+	slowLog     *SlowLog
+	monitors    *Monitors
+	replication *Replication
+	// cluster is nil unless cluster-enabled is set
+	cluster   *cluster.Cluster
+	scripts   *Scripts
+	functions *Functions
+	pubsub    *PubSub
+	tracking  *Tracking
+	expiry    *activeExpiry
+	// script is set in the copy of the handler that runs the commands of a script
+	script    *scriptRun
+	startedAt time.Time
 }
 
 func NewCommandHandler(storageEngine storage.StorageEngine) CommandHandler {
+	return NewCommandHandlerWithConfig(storageEngine, config.NewConfig())
+}
+
+func NewCommandHandlerWithConfig(storageEngine storage.StorageEngine, cfg *config.Config) CommandHandler {
+	acl := NewACL()
+	acl.SetRequirePass(cfg.Get("requirepass"))
+	cfg.Watch("requirepass", acl.SetRequirePass)
+
+	var clusterState *cluster.Cluster
+	if cfg.GetBool("cluster-enabled") {
+		clusterState = cluster.New(cfg)
+	}
+
+	clients := NewClientRegistry()
+	pubsub := NewPubSub()
+	tracking := NewTracking(clients, pubsub)
+	notifications := NewKeyspaceNotifications(cfg, pubsub)
+	storageEngine.SetNotifier(func(event storage.KeyspaceEvent) {
+		notifications.notify(event)
+		tracking.keyspaceEvent(event)
+	})
+
 	return CommandHandler{
-		strgEngine: storageEngine,
+		strgEngine:  storageEngine,
+		cfg:         cfg,
+		clients:     clients,
+		acl:         acl,
+		slowLog:     NewSlowLog(cfg),
+		monitors:    NewMonitors(),
+		replication: NewReplication(storageEngine, cfg),
+		cluster:     clusterState,
+		scripts:     NewScripts(cfg),
+		functions:   NewFunctions(),
+		pubsub:      pubsub,
+		tracking:    tracking,
+		expiry:      &activeExpiry{},
+		startedAt:   time.Now(),
 	}
 }
 
-func (ch CommandHandler) ServeInput(rawData []byte) string {
-	rawStr := string(rawData)
-	rawStrLen := len(rawStr)
-	slog.Debug("received message", "msg", rawStr)
-	numProcessedChars := 0
-	result := ""
+// LoadACLFile loads the users from the configured aclfile, if any.
+func (ch CommandHandler) LoadACLFile() error {
+	aclFile := ch.cfg.Get("aclfile")
+	if aclFile == "" {
+		return nil
+	}
+	return ch.acl.LoadFile(aclFile)
+}
 
-	for {
-		numChars, parsedMsg, err := data.ProcessMessageString(rawStr[numProcessedChars:])
-		if err != nil {
-			result += data.Error{ErrMsg: err.Error()}.ToDataString()
-		} else {
-			result += ch.HandleCommand(parsedMsg).ToDataString()
-		}
-		numProcessedChars += numChars
-		if numProcessedChars == rawStrLen {
-			break
-		}
+// StartReplication connects to the master configured with replicaof, if any.
+func (ch CommandHandler) StartReplication() {
+	if master := strings.Fields(ch.cfg.Get("replicaof")); len(master) == 2 {
+		ch.replication.follow(ch, master[0], master[1])
 	}
+}
+
+// StartCluster opens the cluster bus when cluster mode is enabled.
+func (ch CommandHandler) StartCluster() error {
+	if ch.cluster == nil {
+		return nil
+	}
+	return ch.cluster.Start()
+}
+
+// StopCluster closes the cluster bus, if any.
+func (ch CommandHandler) StopCluster() {
+	if ch.cluster != nil {
+		ch.cluster.Stop()
+	}
+}
+
+// NewClient registers a new client for the given connection.
+func (ch CommandHandler) NewClient(conn net.Conn) *Client {
+	return ch.clients.register(conn, ch, !ch.acl.DefaultUserRequiresAuth())
+}
+
+// Clients returns the registry of the connected clients.
+func (ch CommandHandler) Clients() *ClientRegistry {
+	return ch.clients
+}
+
+func (ch CommandHandler) serveRequest(client *Client, request data.Message) string {
+	// blank inline commands are ignored
+	if cmdArray, ok := request.(data.Array); ok && len(cmdArray.Elements) == 0 {
+		return ""
+	}
+
+	result := ch.HandleClientCommand(client, request).ToDataString()
 	slog.Debug("response", "resp", result)
 	return result
 }
 
+// HandleCommand executes a command that is not associated with any client connection.
 func (ch CommandHandler) HandleCommand(msg data.Message) data.Message {
-	cmdArray, ok := msg.(data.Array)
-	if !ok {
-		return INVALID_CMD_FMT
-	}
+	return ch.HandleClientCommand(nil, msg)
+}
 
-	firstCmd, ok := cmdArray.Elements[0].(data.BulkString)
+// HandleClientCommand executes a command on behalf of the given client.
+func (ch CommandHandler) HandleClientCommand(client *Client, msg data.Message) data.Message {
+	cmdArray, ok := msg.(data.Array)
 	if !ok {
 		return INVALID_CMD_FMT
 	}
 
-	err := validateCommand(cmdArray)
+	spec, err := validateCommand(cmdArray)
 	if err != nil {
 		return data.Error{ErrMsg: err.Error()}
 	}
+	command := strings.ToUpper(spec.name)
+
+	if ch.script != nil {
+		if errMsg := ch.checkScriptCommand(spec, cmdArray); errMsg != nil {
+			return errMsg
+		}
+		// scripts can't wait for other clients, which are kept out while they run
+		if spec.hasFlag(CMD_FLAG_BLOCKING) {
+			cmdArray = withoutBlock(cmdArray, nil)
+		}
+	}
+
+	// ASKING only applies to the command that follows it, and RESTORE-ASKING implies it
+	asking := (client != nil && client.takeAsking()) || spec.hasFlag(CMD_FLAG_ASKING)
+
+	if client != nil {
+		subCmd := ""
+		if len(spec.subcommands) > 0 && len(cmdArray.Elements) > 1 {
+			subCmd = cmdArray.Elements[1].(data.BulkString).Data
+		}
+		client.setLastCmd(cmdName(command, subCmd))
+
+		if errMsg := ch.checkAccess(client, spec, cmdArray); errMsg != nil {
+			return errMsg
+		}
+		if errMsg := checkSubscribed(client, spec); errMsg != nil {
+			return errMsg
+		}
+
+		// CLIENT commands are never paused so that CLIENT UNPAUSE can always go through, and
+		// neither is the replication stream
+		if command != CMD_CLIENT && client.Type() != CLIENT_TYPE_MASTER {
+			ch.clients.waitIfPaused(spec.writes(cmdArray))
+		}
+	}
+
+	if errMsg := ch.clusterRedirect(client, spec, cmdArray, asking); errMsg != nil {
+		if ch.script != nil {
+			return data.Error{ErrMsg: "Script attempted to access a non local key in a cluster node"}
+		}
+		return errMsg
+	}
+
+	if spec.writes(cmdArray) && ch.replication.readOnly(client) {
+		return READONLY_REPLICA
+	}
+
+	// the commands of scripts already run in the turn of their script, and blocking commands can't keep
+	// scripts out while they wait, so they enter a turn for each of their attempts with enterBlocking
+	if ch.script == nil && !isScriptCommand(spec) && !spec.hasFlag(CMD_FLAG_BLOCKING) && !allowedWhileBusy(spec, cmdArray) {
+		done, errMsg := ch.scripts.enterCommand()
+		if errMsg != nil {
+			return errMsg
+		}
+		defer done()
+	}
+
+	// the keys read by the commands of a script are tracked for the client running it
+	caller := client
+	if ch.script != nil {
+		caller = ch.script.client
+	}
+	ch.tracking.beforeCommand(caller, spec, cmdArray)
+
+	start := time.Now()
+	result := ch.execute(client, spec, cmdArray)
+	if ch.script == nil {
+		ch.slowLog.record(client, cmdArray, time.Since(start))
+	}
+	ch.monitors.feed(client, ch.script, spec, cmdArray)
+	ch.tracking.afterCommand(client, caller, spec, cmdArray)
+
+	return result
+}
 
-	var result data.Message
-	switch firstCmd.Data {
-	case CMD_PING:
-		result = handlePing(cmdArray)
-	case CMD_HELLO:
-		result = handleHello(cmdArray)
-	case CMD_ECHO:
-		result = handleEcho(cmdArray)
-	case CMD_SET:
-		result = handleSet(cmdArray, ch.strgEngine)
-	case CMD_GET:
-		result = handleGet(cmdArray, ch.strgEngine)
-	case CMD_CONFIG:
-		result = handleConfig(cmdArray)
-	case CMD_EXISTS:
-		result = handleExists(cmdArray, ch.strgEngine)
-	case CMD_DELETE:
-		result = handleDelete(cmdArray, ch.strgEngine)
-	case CMD_INCR:
-		result = handleAtomicUnitDelta(cmdArray, ch.strgEngine, false)
-	case CMD_DECR:
-		result = handleAtomicUnitDelta(cmdArray, ch.strgEngine, true)
-	case CMD_LPUSH:
-		result = handleListPush(cmdArray, ch.strgEngine, true)
-	case CMD_RPUSH:
-		result = handleListPush(cmdArray, ch.strgEngine, false)
-	default:
-		result = data.Error{
-			ErrMsg: fmt.Sprintf("unsupported command %s", firstCmd.Data),
+// enterBlocking returns the function entering the turn of an attempt of a blocking command, which goes through
+// the scripts like any other command unless it runs within a script. the attempts of a blocking write command
+// are applied and propagated in one go, like the other write commands.
+func (ch CommandHandler) enterBlocking(client *Client, cmdArray data.Array) enterFunc {
+	spec, _ := lookupCommand(strings.ToUpper(cmdArray.Elements[0].(data.BulkString).Data))
+	return func() (func(data.Message), data.Message) {
+		done := func() {}
+		if ch.script == nil {
+			var errMsg data.Message
+			if done, errMsg = ch.scripts.enterCommand(); errMsg != nil {
+				return nil, errMsg
+			}
+		}
+		if !spec.writes(cmdArray) || (client != nil && client.Type() == CLIENT_TYPE_MASTER) {
+			return func(data.Message) { done() }, nil
 		}
+
+		ch.replication.writeMu.Lock()
+		return func(reply data.Message) {
+			if reply != nil {
+				ch.replication.propagate(spec, cmdArray, reply)
+			}
+			ch.replication.writeMu.Unlock()
+			done()
+		}, nil
+	}
+}
+
+// execute runs the command. write commands are propagated to the replicas in the order they were applied,
+// except for the ones received from the master, which are passed on as they were received.
+func (ch CommandHandler) execute(client *Client, spec *commandSpec, cmdArray data.Array) data.Message {
+	// blocking commands can't hold the lock while they wait, since it would keep out the writes they wait for,
+	// so each of their attempts is propagated on its own by enterBlocking
+	if !spec.writes(cmdArray) || (client != nil && client.Type() == CLIENT_TYPE_MASTER) || spec.hasFlag(CMD_FLAG_BLOCKING) {
+		return spec.handler(ch, client, cmdArray)
 	}
+
+	ch.replication.writeMu.Lock()
+	defer ch.replication.writeMu.Unlock()
+	result := spec.handler(ch, client, cmdArray)
+	ch.replication.propagate(spec, cmdArray, result)
 	return result
 }
+
+// checkAccess enforces authentication and the ACL rules of the client's user before a command is executed.
+func (ch CommandHandler) checkAccess(client *Client, spec *commandSpec, cmdArray data.Array) data.Message {
+	command := strings.ToUpper(spec.name)
+	if command == CMD_AUTH {
+		return nil
+	}
+
+	// the master is trusted with every command of the replication stream
+	if client.Type() == CLIENT_TYPE_MASTER {
+		return nil
+	}
+
+	if !client.Authenticated() {
+		return data.Error{ErrMsg: "NOAUTH Authentication required."}
+	}
+
+	args := make([]string, len(cmdArray.Elements))
+	for idx, element := range cmdArray.Elements {
+		args[idx] = element.(data.BulkString).Data
+	}
+
+	if err := ch.acl.CheckPermissions(client.User(), command, spec.keys(args)); err != nil {
+		return data.Error{ErrMsg: err.Error()}
+	}
+	if spec.getChannels != nil {
+		if err := ch.acl.CheckChannelPermissions(client.User(), spec.getChannels(args)); err != nil {
+			return data.Error{ErrMsg: err.Error()}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package handler_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleAuthWithRequirePass(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := config.NewConfig()
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandlerWithConfig(&storageEngine, cfg)
+
+	conn1, peer1 := net.Pipe()
+	defer func() { _ = peer1.Close() }()
+	client1 := ch.NewClient(conn1)
+
+	// without requirepass, every connection starts authenticated as the default user
+	assert.Equal(data.SimpleString{Contents: "PONG"}, ch.HandleClientCommand(client1, newBulkCmd("PING")))
+	assert.Equal(data.Error{ErrMsg: "AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?"}, ch.HandleClientCommand(client1, newBulkCmd("AUTH", "secret")))
+
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleClientCommand(client1, newBulkCmd("CONFIG", "SET", "requirepass", "secret")))
+
+	conn2, peer2 := net.Pipe()
+	defer func() { _ = peer2.Close() }()
+	client2 := ch.NewClient(conn2)
+
+	testCases := []struct {
+		input data.Array
+		want  data.Message
+	}{
+		{newBulkCmd("PING"), data.Error{ErrMsg: "NOAUTH Authentication required."}},
+		{newBulkCmd("GET", "key"), data.Error{ErrMsg: "NOAUTH Authentication required."}},
+		{newBulkCmd("AUTH", "wrong"), data.Error{ErrMsg: "WRONGPASS invalid username-password pair or user is disabled."}},
+		{newBulkCmd("AUTH", "default", "wrong"), data.Error{ErrMsg: "WRONGPASS invalid username-password pair or user is disabled."}},
+		{newBulkCmd("AUTH", "a", "b", "c"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("AUTH", "secret"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("PING"), data.SimpleString{Contents: "PONG"}},
+		{newBulkCmd("ACL", "WHOAMI"), data.BulkString{Data: "default"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleClientCommand(client2, tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+
+	// clients that connected before requirepass was set remain authenticated
+	assert.Equal(data.SimpleString{Contents: "PONG"}, ch.HandleClientCommand(client1, newBulkCmd("PING")))
+}
+
+func TestHandleAclCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn1, peer1 := net.Pipe()
+	defer func() { _ = peer1.Close() }()
+	admin := ch.NewClient(conn1)
+
+	conn2, peer2 := net.Pipe()
+	defer func() { _ = peer2.Close() }()
+	worker := ch.NewClient(conn2)
+
+	testCases := []struct {
+		client *handler.Client
+		input  data.Array
+		want   data.Message
+	}{
+		{admin, newBulkCmd("ACL", "USERS"), data.Array{Elements: []data.Message{data.BulkString{Data: "default"}}}},
+		{admin, newBulkCmd("ACL", "LIST"), data.Array{Elements: []data.Message{data.BulkString{Data: "user default on nopass ~* &* +@all"}}}},
+		{admin, newBulkCmd("ACL", "SETUSER", "worker", "bogus"), data.Error{ErrMsg: "Error in ACL SETUSER modifier 'bogus': Syntax error"}},
+		{admin, newBulkCmd("ACL", "SETUSER", "worker", "+@nexist"), data.Error{ErrMsg: "Error in ACL SETUSER modifier '+@nexist': Unknown command or category name in ACL"}},
+		{admin, newBulkCmd("ACL", "GETUSER", "worker"), data.Null{}},
+		{admin, newBulkCmd("ACL", "SETUSER", "worker", "on", ">workerpass", "~jobs:*", "+@read", "+set", "-exists"), data.SimpleString{Contents: "OK"}},
+		{admin, newBulkCmd("ACL", "GETUSER", "worker"), data.Array{
+			Elements: []data.Message{
+				data.BulkString{Data: "flags"},
+				data.Array{Elements: []data.Message{data.BulkString{Data: "on"}}},
+				data.BulkString{Data: "passwords"},
+				data.Array{Elements: []data.Message{data.BulkString{Data: "4ddff7855ff6e876b0c55f88023c2d23ce020906c648228eb771eb720f83c8f7"}}},
+				data.BulkString{Data: "commands"},
+				data.BulkString{Data: "+@read +set -exists"},
+				data.BulkString{Data: "keys"},
+				data.BulkString{Data: "~jobs:*"},
+				data.BulkString{Data: "channels"},
+				data.BulkString{Data: ""},
+			},
+		}},
+		{worker, newBulkCmd("AUTH", "worker", "wrong"), data.Error{ErrMsg: "WRONGPASS invalid username-password pair or user is disabled."}},
+		{worker, newBulkCmd("AUTH", "worker", "workerpass"), data.SimpleString{Contents: "OK"}},
+		{worker, newBulkCmd("ACL", "WHOAMI"), data.Error{ErrMsg: "NOPERM User worker has no permissions to run the 'acl' command"}},
+		{worker, newBulkCmd("SET", "jobs:1", "pending"), data.SimpleString{Contents: "OK"}},
+		{worker, newBulkCmd("GET", "jobs:1"), data.BulkString{Data: "pending"}},
+		{worker, newBulkCmd("GET", "secrets:1"), data.Error{ErrMsg: "NOPERM No permissions to access a key"}},
+		{worker, newBulkCmd("XREAD", "STREAMS", "jobs:stream", "0"), data.Null{}},
+		{worker, newBulkCmd("XREAD", "COUNT", "1", "STREAMS", "jobs:stream", "secrets:stream", "0", "0"), data.Error{ErrMsg: "NOPERM No permissions to access a key"}},
+		{worker, newBulkCmd("XINFO", "STREAM", "secrets:stream"), data.Error{ErrMsg: "NOPERM No permissions to access a key"}},
+		{worker, newBulkCmd("XINFO", "STREAM", "jobs:stream"), data.Error{ErrMsg: "no such key"}},
+		{worker, newBulkCmd("XREADGROUP", "GROUP", "g", "c", "STREAMS", "jobs:stream", "secrets:stream", ">", ">"), data.Error{ErrMsg: "NOPERM User worker has no permissions to run the 'xreadgroup' command"}},
+		{worker, newBulkCmd("EXISTS", "jobs:1"), data.Error{ErrMsg: "NOPERM User worker has no permissions to run the 'exists' command"}},
+		{worker, newBulkCmd("DEL", "jobs:1"), data.Error{ErrMsg: "NOPERM User worker has no permissions to run the 'del' command"}},
+		{admin, newBulkCmd("ACL", "DELUSER", "default"), data.Error{ErrMsg: "The 'default' user cannot be removed"}},
+		{admin, newBulkCmd("ACL", "DELUSER", "worker", "nexist"), data.Integer{Value: 1}},
+		{admin, newBulkCmd("ACL", "CAT", "list"), data.Array{Elements: []data.Message{data.BulkString{Data: "lpush"}, data.BulkString{Data: "rpush"}}}},
+		{admin, newBulkCmd("ACL", "CAT", "nexist"), data.Error{ErrMsg: "Unknown category 'nexist'"}},
+		{admin, newBulkCmd("ACL", "LOAD"), data.Error{ErrMsg: "This Redis instance is not configured to use an ACL file. You may want to specify users via the ACL SETUSER command and then issue a CONFIG REWRITE (assuming you have a Redis configuration file set) in order to store users in the Redis configuration."}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleClientCommand(tc.client, tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+
+	// the clients of a deleted user are disconnected
+	_, err := peer2.Read(make([]byte, 1))
+	assert.NotNil(err)
+}
+
+func TestLoadAclFile(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	aclPath := filepath.Join(t.TempDir(), "users.acl")
+	require.Nil(os.WriteFile(aclPath, []byte("# users\nuser default on >adminpass ~* &* +@all\nuser reader on >readerpass ~* +@read\n"), 0o600))
+
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("aclfile", aclPath))
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandlerWithConfig(&storageEngine, cfg)
+	require.Nil(ch.LoadACLFile())
+
+	conn1, peer1 := net.Pipe()
+	defer func() { _ = peer1.Close() }()
+	client1 := ch.NewClient(conn1)
+
+	assert.Equal(data.Error{ErrMsg: "NOAUTH Authentication required."}, ch.HandleClientCommand(client1, newBulkCmd("GET", "key")))
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleClientCommand(client1, newBulkCmd("AUTH", "reader", "readerpass")))
+	assert.Equal(data.Null{}, ch.HandleClientCommand(client1, newBulkCmd("GET", "key")))
+	assert.Equal(data.Error{ErrMsg: "NOPERM User reader has no permissions to run the 'set' command"}, ch.HandleClientCommand(client1, newBulkCmd("SET", "key", "value")))
+
+	// users survive a save and load round trip
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleCommand(newBulkCmd("ACL", "SETUSER", "writer", "on", "nopass", "~*", "+@write")))
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleCommand(newBulkCmd("ACL", "SAVE")))
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleCommand(newBulkCmd("ACL", "LOAD")))
+	assert.Equal(data.Array{Elements: []data.Message{
+		data.BulkString{Data: "default"},
+		data.BulkString{Data: "reader"},
+		data.BulkString{Data: "writer"},
+	}}, ch.HandleCommand(newBulkCmd("ACL", "USERS")))
+
+	require.Nil(os.WriteFile(aclPath, []byte("user broken on +@nexist\n"), 0o600))
+	assert.Equal(data.Error{ErrMsg: aclPath + ":1: Error in user declaration '+@nexist': Unknown command or category name in ACL"}, ch.HandleCommand(newBulkCmd("ACL", "LOAD")))
+}
+
+func TestLoadAclFileWithoutDefaultUser(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	aclPath := filepath.Join(t.TempDir(), "users.acl")
+	require.Nil(os.WriteFile(aclPath, []byte("user reader on >readerpass ~* +@read\n"), 0o600))
+
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("aclfile", aclPath))
+	require.Nil(cfg.Set("requirepass", "secret"))
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandlerWithConfig(&storageEngine, cfg)
+	require.Nil(ch.LoadACLFile())
+
+	conn, peer := net.Pipe()
+	defer func() { _ = peer.Close() }()
+	client := ch.NewClient(conn)
+
+	// the default user still requires the password of requirepass
+	assert.Equal(data.Error{ErrMsg: "NOAUTH Authentication required."}, ch.HandleClientCommand(client, newBulkCmd("GET", "key")))
+	assert.Equal(data.Error{ErrMsg: "WRONGPASS invalid username-password pair or user is disabled."}, ch.HandleClientCommand(client, newBulkCmd("AUTH", "wrong")))
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleClientCommand(client, newBulkCmd("AUTH", "secret")))
+	assert.Equal(data.Null{}, ch.HandleClientCommand(client, newBulkCmd("GET", "key")))
+}
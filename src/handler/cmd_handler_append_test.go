@@ -0,0 +1,35 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleAppendCommand(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("APPEND", "key", "Hello"), data.Integer{Value: 5}},
+		{newBulkCmd("APPEND", "key", " World"), data.Integer{Value: 11}},
+		{newBulkCmd("GET", "key"), data.BulkString{Data: "Hello World"}},
+		{newBulkCmd("APPEND", "bin", "\x00\r\n"), data.Integer{Value: 3}},
+		{newBulkCmd("GET", "bin"), data.BulkString{Data: "\x00\r\n"}},
+		{newBulkCmd("APPEND", "key"), data.Error{ErrMsg: "wrong number of arguments for 'append' command"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
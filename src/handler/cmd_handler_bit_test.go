@@ -0,0 +1,86 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleBitCommands(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("SET", "foobar", "foobar"))
+	ch.HandleCommand(newBulkCmd("SET", "ones", "\xff\xf0\x00"))
+	ch.HandleCommand(newBulkCmd("SET", "zeros", "\x00\xff\xf0"))
+	ch.HandleCommand(newBulkCmd("SET", "full", "\xff\xff\xff"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("SETBIT", "bits", "7", "1"), data.Integer{Value: 0}},
+		{newBulkCmd("SETBIT", "bits", "7", "0"), data.Integer{Value: 1}},
+		{newBulkCmd("SETBIT", "bits", "17", "1"), data.Integer{Value: 0}},
+		{newBulkCmd("GET", "bits"), data.BulkString{Data: "\x00\x00\x40"}},
+		{newBulkCmd("GETBIT", "bits", "17"), data.Integer{Value: 1}},
+		{newBulkCmd("GETBIT", "bits", "7"), data.Integer{Value: 0}},
+		{newBulkCmd("GETBIT", "bits", "1000"), data.Integer{Value: 0}},
+		{newBulkCmd("GETBIT", "nexist", "0"), data.Integer{Value: 0}},
+		{newBulkCmd("SETBIT", "bits", "-1", "1"), data.Error{ErrMsg: "bit offset is not an integer or out of range"}},
+		{newBulkCmd("SETBIT", "bits", "4294967296", "1"), data.Error{ErrMsg: "bit offset is not an integer or out of range"}},
+		{newBulkCmd("SETBIT", "bits", "0", "2"), data.Error{ErrMsg: "bit is not an integer or out of range"}},
+		{newBulkCmd("GETBIT", "bits", "one"), data.Error{ErrMsg: "bit offset is not an integer or out of range"}},
+
+		{newBulkCmd("BITCOUNT", "foobar"), data.Integer{Value: 26}},
+		{newBulkCmd("BITCOUNT", "foobar", "0", "0"), data.Integer{Value: 4}},
+		{newBulkCmd("BITCOUNT", "foobar", "1", "1"), data.Integer{Value: 6}},
+		{newBulkCmd("BITCOUNT", "foobar", "1", "1", "byte"), data.Integer{Value: 6}},
+		{newBulkCmd("BITCOUNT", "foobar", "5", "30", "BIT"), data.Integer{Value: 17}},
+		{newBulkCmd("BITCOUNT", "foobar", "-2", "-1"), data.Integer{Value: 7}},
+		{newBulkCmd("BITCOUNT", "foobar", "3", "1"), data.Integer{Value: 0}},
+		{newBulkCmd("BITCOUNT", "nexist"), data.Integer{Value: 0}},
+		{newBulkCmd("BITCOUNT", "foobar", "0"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("BITCOUNT", "foobar", "0", "1", "WORD"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("BITCOUNT", "foobar", "a", "1"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+
+		{newBulkCmd("BITPOS", "ones", "0"), data.Integer{Value: 12}},
+		{newBulkCmd("BITPOS", "zeros", "1", "0"), data.Integer{Value: 8}},
+		{newBulkCmd("BITPOS", "zeros", "1", "2"), data.Integer{Value: 16}},
+		{newBulkCmd("BITPOS", "zeros", "1", "2", "-1", "BYTE"), data.Integer{Value: 16}},
+		{newBulkCmd("BITPOS", "zeros", "1", "7", "15", "BIT"), data.Integer{Value: 8}},
+		{newBulkCmd("BITPOS", "zeros", "1", "7", "-3", "BIT"), data.Integer{Value: 8}},
+		{newBulkCmd("BITPOS", "zeros", "1", "0", "7", "BIT"), data.Integer{Value: -1}},
+		{newBulkCmd("BITPOS", "full", "0"), data.Integer{Value: 24}},
+		{newBulkCmd("BITPOS", "full", "0", "0", "-1"), data.Integer{Value: -1}},
+		{newBulkCmd("BITPOS", "nexist", "1"), data.Integer{Value: -1}},
+		{newBulkCmd("BITPOS", "nexist", "0"), data.Integer{Value: 0}},
+		{newBulkCmd("BITPOS", "ones", "2"), data.Error{ErrMsg: "The bit argument must be 1 or 0."}},
+		{newBulkCmd("BITPOS", "ones", "one"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+
+		{newBulkCmd("SET", "key1", "foobar"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("SET", "key2", "abcdef"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("BITOP", "AND", "dest", "key1", "key2"), data.Integer{Value: 6}},
+		{newBulkCmd("GET", "dest"), data.BulkString{Data: "`bc`ab"}},
+		{newBulkCmd("BITOP", "or", "dest", "key1", "nexist"), data.Integer{Value: 6}},
+		{newBulkCmd("GET", "dest"), data.BulkString{Data: "foobar"}},
+		{newBulkCmd("BITOP", "XOR", "dest", "key1", "key1"), data.Integer{Value: 6}},
+		{newBulkCmd("GET", "dest"), data.BulkString{Data: "\x00\x00\x00\x00\x00\x00"}},
+		{newBulkCmd("BITOP", "NOT", "dest", "zeros"), data.Integer{Value: 3}},
+		{newBulkCmd("GET", "dest"), data.BulkString{Data: "\xff\x00\x0f"}},
+		{newBulkCmd("BITOP", "AND", "dest", "nexist"), data.Integer{Value: 0}},
+		{newBulkCmd("EXISTS", "dest"), data.Integer{Value: 0}},
+		{newBulkCmd("BITOP", "NOT", "dest", "key1", "key2"), data.Error{ErrMsg: "BITOP NOT must be called with a single source key."}},
+		{newBulkCmd("BITOP", "NAND", "dest", "key1"), data.Error{ErrMsg: "syntax error"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func integers(values ...int64) data.Array {
+	elements := make([]data.Message, len(values))
+	for idx, value := range values {
+		elements[idx] = data.Integer{Value: value}
+	}
+	return data.Array{Elements: elements}
+}
+
+func TestHandleBitFieldCommand(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("BITFIELD", "bf", "GET", "u8", "0"), integers(0)},
+		{newBulkCmd("EXISTS", "bf"), data.Integer{Value: 0}},
+		{newBulkCmd("BITFIELD", "bf", "INCRBY", "i5", "100", "1", "GET", "u4", "0"), integers(1, 0)},
+		{newBulkCmd("STRLEN", "bf"), data.Integer{Value: 14}},
+		{newBulkCmd("BITFIELD", "bf", "SET", "i8", "#1", "-100", "GET", "i8", "8", "GET", "u8", "8"), integers(0, -100, 156)},
+		{newBulkCmd("BITFIELD", "bf", "SET", "u8", "0", "255", "GET", "u8", "0"), integers(0, 255)},
+		{newBulkCmd("BITFIELD", "bf", "INCRBY", "u8", "0", "1"), integers(0)},
+		{newBulkCmd("BITFIELD", "bf", "SET", "i64", "0", "-1", "GET", "i64", "0"), integers(0x9c<<48, -1)},
+
+		{newBulkCmd("BITFIELD", "sat", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"), integers(1, 1)},
+		{newBulkCmd("BITFIELD", "sat", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"), integers(2, 2)},
+		{newBulkCmd("BITFIELD", "sat", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"), integers(3, 3)},
+		{newBulkCmd("BITFIELD", "sat", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"), integers(0, 3)},
+		{
+			newBulkCmd("BITFIELD", "sat", "OVERFLOW", "FAIL", "INCRBY", "u2", "102", "1", "SET", "u2", "102", "4", "INCRBY", "u2", "102", "-1"),
+			data.Array{Elements: []data.Message{data.Null{}, data.Null{}, data.Integer{Value: 2}}},
+		},
+		{newBulkCmd("BITFIELD", "sat", "OVERFLOW", "SAT", "INCRBY", "i8", "0", "-200", "SET", "i8", "0", "1000"), integers(-128, -128)},
+		{newBulkCmd("BITFIELD", "sat", "GET", "i8", "0", "OVERFLOW", "WRAP", "INCRBY", "i8", "0", "1000"), integers(127, 103)},
+		{newBulkCmd("BITFIELD", "big", "SET", "i64", "0", "9223372036854775807", "INCRBY", "i64", "0", "1"), integers(0, -9223372036854775808)},
+
+		{newBulkCmd("BITFIELD", "bf", "GET", "u64", "0"), data.Error{ErrMsg: "Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."}},
+		{newBulkCmd("BITFIELD", "bf", "GET", "x8", "0"), data.Error{ErrMsg: "Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."}},
+		{newBulkCmd("BITFIELD", "bf", "GET", "i0", "0"), data.Error{ErrMsg: "Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."}},
+		{newBulkCmd("BITFIELD", "bf", "GET", "u8", "-1"), data.Error{ErrMsg: "bit offset is not an integer or out of range"}},
+		{newBulkCmd("BITFIELD", "bf", "GET", "u8", "#a"), data.Error{ErrMsg: "bit offset is not an integer or out of range"}},
+		{newBulkCmd("BITFIELD", "bf", "SET", "u8", "0", "ten"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("BITFIELD", "bf", "OVERFLOW", "NONE"), data.Error{ErrMsg: "Invalid OVERFLOW type specified"}},
+		{newBulkCmd("BITFIELD", "bf", "SET", "u8", "0"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("BITFIELD", "bf", "DEL", "u8", "0"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("BITFIELD", "bf"), data.Array{Elements: []data.Message{}}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package handler_test
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func newBulkCmd(args ...string) data.Array {
+	elements := make([]data.Message, len(args))
+	for idx, arg := range args {
+		elements[idx] = data.BulkString{Data: arg}
+	}
+	return data.Array{Elements: elements}
+}
+
+func TestHandleClientCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn1, peer1 := net.Pipe()
+	defer func() { _ = peer1.Close() }()
+	conn2, peer2 := net.Pipe()
+	defer func() { _ = peer2.Close() }()
+
+	client1 := ch.NewClient(conn1)
+	client2 := ch.NewClient(conn2)
+	assert.Equal(2, ch.Clients().Count())
+
+	testCases := []struct {
+		client *handler.Client
+		input  data.Array
+		want   data.Message
+	}{
+		{client1, newBulkCmd("CLIENT"), data.Error{ErrMsg: "wrong number of arguments for 'client' command"}},
+		{client1, newBulkCmd("CLIENT", "ID"), data.Integer{Value: client1.ID()}},
+		{client2, newBulkCmd("CLIENT", "ID"), data.Integer{Value: client2.ID()}},
+		{client1, newBulkCmd("CLIENT", "GETNAME"), data.Null{}},
+		{client1, newBulkCmd("CLIENT", "SETNAME", "worker 1"), data.Error{ErrMsg: "Client names cannot contain spaces, newlines or special characters."}},
+		{client1, newBulkCmd("CLIENT", "SETNAME", "worker-1"), data.SimpleString{Contents: "OK"}},
+		{client1, newBulkCmd("CLIENT", "GETNAME"), data.BulkString{Data: "worker-1"}},
+		{client2, newBulkCmd("CLIENT", "GETNAME"), data.Null{}},
+		{nil, newBulkCmd("CLIENT", "ID"), data.Error{ErrMsg: "this command requires a client connection"}},
+		{client1, newBulkCmd("CLIENT", "LIST", "TYPE", "pubsub"), data.BulkString{Data: ""}},
+		{client1, newBulkCmd("CLIENT", "LIST", "TYPE", "unknown"), data.Error{ErrMsg: "Unknown client type 'unknown'"}},
+		{client1, newBulkCmd("CLIENT", "LIST", "ID", "abc"), data.Error{ErrMsg: "Invalid client ID"}},
+		{client1, newBulkCmd("CLIENT", "KILL", "1.2.3.4:5"), data.Error{ErrMsg: "No such client"}},
+		{client1, newBulkCmd("CLIENT", "KILL", "ID", "0"), data.Error{ErrMsg: "client-id should be greater than 0"}},
+		{client1, newBulkCmd("CLIENT", "KILL", "USER", "nobody"), data.Integer{Value: 0}},
+		{client1, newBulkCmd("CLIENT", "KILL", "TYPE", "master"), data.Integer{Value: 0}},
+		{client1, newBulkCmd("CLIENT", "KILL", "TYPE", "unknown"), data.Error{ErrMsg: "Unknown client type 'unknown'"}},
+		{client1, newBulkCmd("CLIENT", "PAUSE", "abc"), data.Error{ErrMsg: "timeout is not an integer or out of range"}},
+		{client1, newBulkCmd("CLIENT", "PAUSE", "10", "SOME"), data.Error{ErrMsg: "syntax error"}},
+		{client1, newBulkCmd("CLIENT", "NEXIST"), data.Error{ErrMsg: "unsupported subcommand NEXIST for CLIENT"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleClientCommand(tc.client, tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+
+	// CLIENT INFO and CLIENT LIST
+	info := ch.HandleClientCommand(client1, newBulkCmd("CLIENT", "INFO")).(data.BulkString).Data
+	assert.True(strings.HasPrefix(info, fmt.Sprintf("id=%d ", client1.ID())))
+	assert.Contains(info, " name=worker-1 ")
+	assert.Contains(info, " cmd=client|info ")
+	assert.Contains(info, " user=default ")
+
+	list := ch.HandleClientCommand(client1, newBulkCmd("CLIENT", "LIST")).(data.BulkString).Data
+	lines := strings.Split(strings.TrimSuffix(list, "\n"), "\n")
+	assert.Len(lines, 2)
+	assert.True(strings.HasPrefix(lines[0], fmt.Sprintf("id=%d ", client1.ID())))
+	assert.True(strings.HasPrefix(lines[1], fmt.Sprintf("id=%d ", client2.ID())))
+
+	list = ch.HandleClientCommand(client1, newBulkCmd("CLIENT", "LIST", "ID", fmt.Sprint(client2.ID()))).(data.BulkString).Data
+	assert.True(strings.HasPrefix(list, fmt.Sprintf("id=%d ", client2.ID())))
+	assert.Equal(1, strings.Count(list, "\n"))
+}
+
+func TestHandleClientKill(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn1, peer1 := net.Pipe()
+	defer func() { _ = peer1.Close() }()
+	conn2, peer2 := net.Pipe()
+
+	client1 := ch.NewClient(conn1)
+	client2 := ch.NewClient(conn2)
+
+	// SKIPME defaults to yes, so only the other client is killed
+	result := ch.HandleClientCommand(client1, newBulkCmd("CLIENT", "KILL", "USER", "default"))
+	assert.Equal(data.Integer{Value: 1}, result)
+	assert.False(client1.Closing())
+
+	// the peer of the killed connection observes the close
+	_, err := peer2.Read(make([]byte, 1))
+	require.NotNil(err)
+
+	client2.Close()
+	assert.Equal(1, ch.Clients().Count())
+
+	// killing yourself closes the connection once the reply has been sent
+	result = ch.HandleClientCommand(client1, newBulkCmd("CLIENT", "KILL", "ID", fmt.Sprint(client1.ID()), "SKIPME", "no"))
+	assert.Equal(data.Integer{Value: 1}, result)
+	assert.True(client1.Closing())
+}
+
+func TestHandleClientPause(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn1, peer1 := net.Pipe()
+	defer func() { _ = peer1.Close() }()
+	client1 := ch.NewClient(conn1)
+
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleClientCommand(client1, newBulkCmd("CLIENT", "PAUSE", "10000", "WRITE")))
+
+	// reads are not affected by a write pause
+	assert.Equal(data.Null{}, ch.HandleClientCommand(client1, newBulkCmd("GET", "paused")))
+
+	done := make(chan data.Message)
+	go func() {
+		done <- ch.HandleClientCommand(client1, newBulkCmd("SET", "paused", "value"))
+	}()
+
+	select {
+	case <-done:
+		assert.Fail("write command was not paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleClientCommand(client1, newBulkCmd("CLIENT", "UNPAUSE")))
+	assert.Equal(data.SimpleString{Contents: "OK"}, <-done)
+
+	// pauses expire on their own
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleClientCommand(client1, newBulkCmd("CLIENT", "PAUSE", "20")))
+	start := time.Now()
+	assert.Equal(data.BulkString{Data: "value"}, ch.HandleClientCommand(client1, newBulkCmd("GET", "paused")))
+	assert.GreaterOrEqual(time.Since(start), 15*time.Millisecond)
+}
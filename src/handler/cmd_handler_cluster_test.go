@@ -0,0 +1,122 @@
+package handler_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleClusterDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	assert.Equal(data.Error{ErrMsg: "This instance has cluster support disabled"}, ch.HandleCommand(newBulkCmd("CLUSTER", "INFO")))
+	assert.Equal(data.Error{ErrMsg: "This instance has cluster support disabled"}, ch.HandleCommand(newBulkCmd("ASKING")))
+	assert.Equal(handler.OK, ch.HandleCommand(newBulkCmd("MSET", "foo", "1", "bar", "2")))
+
+	info := ch.HandleCommand(newBulkCmd("INFO", "cluster")).(data.BulkString).Data
+	assert.Contains(info, "cluster_enabled:0\r\n")
+}
+
+func TestHandleClusterCommands(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("cluster-enabled", "yes"))
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandlerWithConfig(&storageEngine, cfg)
+
+	conn1, peer1 := net.Pipe()
+	defer func() { _ = peer1.Close() }()
+	client1 := ch.NewClient(conn1)
+
+	myID := ch.HandleCommand(newBulkCmd("CLUSTER", "MYID")).(data.BulkString).Data
+	require.Len(myID, 40)
+
+	testCases := []struct {
+		input data.Array
+		want  data.Message
+	}{
+		{newBulkCmd("CLUSTER", "KEYSLOT", "foo"), data.Integer{Value: 12182}},
+		{newBulkCmd("CLUSTER", "KEYSLOT", "{foo}.bar"), data.Integer{Value: 12182}},
+		{newBulkCmd("CLUSTER", "KEYSLOT"), data.Error{ErrMsg: "wrong number of arguments for 'cluster|keyslot' command"}},
+		{newBulkCmd("CLUSTER", "NEXIST"), data.Error{ErrMsg: "unsupported subcommand NEXIST for CLUSTER"}},
+		{newBulkCmd("GET", "foo"), data.Error{ErrMsg: "CLUSTERDOWN Hash slot not served"}},
+		{newBulkCmd("PING"), data.SimpleString{Contents: "PONG"}},
+		{newBulkCmd("CLUSTER", "ADDSLOTS", "16384"), data.Error{ErrMsg: "Invalid or out of range slot"}},
+		{newBulkCmd("CLUSTER", "ADDSLOTS", "1", "1"), data.Error{ErrMsg: "Slot 1 specified multiple times"}},
+		{newBulkCmd("CLUSTER", "ADDSLOTSRANGE", "10", "5"), data.Error{ErrMsg: "start slot number 10 is greater than end slot number 5"}},
+		{newBulkCmd("CLUSTER", "ADDSLOTSRANGE", "0", "5", "6"), data.Error{ErrMsg: "wrong number of arguments for 'cluster|addslotsrange' command"}},
+		{newBulkCmd("CLUSTER", "DELSLOTS", "0"), data.Error{ErrMsg: "Slot 0 is already unassigned"}},
+		{newBulkCmd("CLUSTER", "ADDSLOTSRANGE", "0", "12181"), handler.OK},
+		{newBulkCmd("GET", "bar"), data.Error{ErrMsg: "CLUSTERDOWN The cluster is down"}},
+		{newBulkCmd("CLUSTER", "ADDSLOTSRANGE", "12182", "16382"), handler.OK},
+		{newBulkCmd("CLUSTER", "ADDSLOTS", "16383"), handler.OK},
+		{newBulkCmd("CLUSTER", "ADDSLOTS", "16383"), data.Error{ErrMsg: "Slot 16383 is already busy"}},
+		{newBulkCmd("SET", "foo", "1"), handler.OK},
+		{newBulkCmd("MSET", "{foo}.a", "1", "{foo}.b", "2"), handler.OK},
+		{newBulkCmd("MSET", "foo", "1", "bar", "2"), data.Error{ErrMsg: "CROSSSLOT Keys in request don't hash to the same slot"}},
+		{newBulkCmd("CLUSTER", "COUNTKEYSINSLOT", "12182"), data.Integer{Value: 3}},
+		{newBulkCmd("CLUSTER", "COUNTKEYSINSLOT", "16384"), data.Error{ErrMsg: "Invalid slot"}},
+		{newBulkCmd("CLUSTER", "GETKEYSINSLOT", "12182", "0"), data.Array{Elements: []data.Message{}}},
+		{newBulkCmd("CLUSTER", "GETKEYSINSLOT", "5061", "10"), data.Array{Elements: []data.Message{}}},
+		{newBulkCmd("CLUSTER", "GETKEYSINSLOT", "5061", "-1"), data.Error{ErrMsg: "Invalid slot or number of keys"}},
+		{newBulkCmd("CLUSTER", "SETSLOT", "16384", "STABLE"), data.Error{ErrMsg: "Invalid or out of range slot"}},
+		{newBulkCmd("CLUSTER", "SETSLOT", "0", "STABLE", "extra"), data.Error{ErrMsg: "Invalid CLUSTER SETSLOT action or number of arguments. Try CLUSTER HELP"}},
+		{newBulkCmd("CLUSTER", "SETSLOT", "0", "MIGRATING", "nexist"), data.Error{ErrMsg: "I don't know about node nexist"}},
+		{newBulkCmd("CLUSTER", "SETSLOT", "0", "MIGRATING", myID), data.Error{ErrMsg: "I'm the owner of hash slot 0, it can't be migrated to myself"}},
+		{newBulkCmd("CLUSTER", "SETSLOT", "0", "IMPORTING", myID), data.Error{ErrMsg: "I'm already the owner of hash slot 0"}},
+		{newBulkCmd("CLUSTER", "SETSLOT", "0", "STABLE"), handler.OK},
+		{newBulkCmd("CLUSTER", "SETSLOT", "0", "NODE", myID), handler.OK},
+		{newBulkCmd("CLUSTER", "MEET", "nexist", "7000"), data.Error{ErrMsg: "Invalid node address specified: nexist:7000"}},
+		{newBulkCmd("CLUSTER", "MEET", "127.0.0.1", "abc"), data.Error{ErrMsg: "Invalid base port specified: abc"}},
+		{newBulkCmd("REPLICAOF", "127.0.0.1", "7000"), data.Error{ErrMsg: "REPLICAOF not allowed in cluster mode."}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.want, ch.HandleClientCommand(client1, tc.input), strings.Join(bulkArgs(tc.input), " "))
+	}
+
+	keys := ch.HandleCommand(newBulkCmd("CLUSTER", "GETKEYSINSLOT", "12182", "10")).(data.Array).Elements
+	assert.ElementsMatch([]data.Message{
+		data.BulkString{Data: "foo"}, data.BulkString{Data: "{foo}.a"}, data.BulkString{Data: "{foo}.b"},
+	}, keys)
+	assert.Len(ch.HandleCommand(newBulkCmd("CLUSTER", "GETKEYSINSLOT", "12182", "2")).(data.Array).Elements, 2)
+
+	// this node serves every slot on its own
+	assert.Equal(data.Array{Elements: []data.Message{data.Array{Elements: []data.Message{
+		data.Integer{Value: 0},
+		data.Integer{Value: 16383},
+		data.Array{Elements: []data.Message{data.BulkString{Data: ""}, data.Integer{Value: 6379}, data.BulkString{Data: myID}}},
+	}}}}, ch.HandleCommand(newBulkCmd("CLUSTER", "SLOTS")))
+	assert.Equal(data.BulkString{Data: myID + " :6379@16379 myself,master - 0 0 0 connected 0-16383\n"}, ch.HandleCommand(newBulkCmd("CLUSTER", "NODES")))
+
+	clusterInfo := ch.HandleCommand(newBulkCmd("CLUSTER", "INFO")).(data.BulkString).Data
+	assert.True(strings.HasPrefix(clusterInfo, "cluster_state:ok\r\ncluster_slots_assigned:16384\r\n"))
+
+	info := ch.HandleCommand(newBulkCmd("INFO")).(data.BulkString).Data
+	assert.Contains(info, "redis_mode:cluster\r\n")
+	assert.Contains(info, "# Cluster\r\ncluster_enabled:1\r\n")
+
+	// ASKING only lasts for the next command
+	assert.Equal(handler.OK, ch.HandleClientCommand(client1, newBulkCmd("ASKING")))
+	assert.Equal(data.BulkString{Data: "1"}, ch.HandleClientCommand(client1, newBulkCmd("GET", "foo")))
+}
+
+func bulkArgs(cmd data.Array) []string {
+	args := make([]string, len(cmd.Elements))
+	for idx, element := range cmd.Elements {
+		args[idx] = element.(data.BulkString).Data
+	}
+	return args
+}
@@ -0,0 +1,123 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleCommandCommand(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	getInfo := data.Array{Elements: []data.Message{
+		data.BulkString{Data: "get"},
+		data.Integer{Value: 2},
+		data.Array{Elements: []data.Message{data.SimpleString{Contents: "readonly"}, data.SimpleString{Contents: "fast"}}},
+		data.Integer{Value: 1},
+		data.Integer{Value: 1},
+		data.Integer{Value: 1},
+		data.Array{Elements: []data.Message{
+			data.SimpleString{Contents: "@fast"},
+			data.SimpleString{Contents: "@read"},
+			data.SimpleString{Contents: "@string"},
+		}},
+		data.Array{Elements: []data.Message{}},
+		data.Array{Elements: []data.Message{
+			data.Array{Elements: []data.Message{
+				data.BulkString{Data: "flags"},
+				data.Array{Elements: []data.Message{data.SimpleString{Contents: "RO"}, data.SimpleString{Contents: "access"}}},
+				data.BulkString{Data: "begin_search"},
+				data.Array{Elements: []data.Message{
+					data.BulkString{Data: "type"},
+					data.BulkString{Data: "index"},
+					data.BulkString{Data: "spec"},
+					data.Array{Elements: []data.Message{data.BulkString{Data: "index"}, data.Integer{Value: 1}}},
+				}},
+				data.BulkString{Data: "find_keys"},
+				data.Array{Elements: []data.Message{
+					data.BulkString{Data: "type"},
+					data.BulkString{Data: "range"},
+					data.BulkString{Data: "spec"},
+					data.Array{Elements: []data.Message{
+						data.BulkString{Data: "lastkey"},
+						data.Integer{Value: 0},
+						data.BulkString{Data: "keystep"},
+						data.Integer{Value: 1},
+						data.BulkString{Data: "limit"},
+						data.Integer{Value: 0},
+					}},
+				}},
+			}},
+		}},
+		data.Array{Elements: []data.Message{}},
+	}}
+
+	getDocs := data.Array{Elements: []data.Message{
+		data.BulkString{Data: "get"},
+		data.Array{Elements: []data.Message{
+			data.BulkString{Data: "summary"},
+			data.BulkString{Data: "Returns the string value of a key."},
+			data.BulkString{Data: "since"},
+			data.BulkString{Data: "1.0.0"},
+			data.BulkString{Data: "group"},
+			data.BulkString{Data: "string"},
+			data.BulkString{Data: "complexity"},
+			data.BulkString{Data: "O(1)"},
+			data.BulkString{Data: "arguments"},
+			data.Array{Elements: []data.Message{
+				data.Array{Elements: []data.Message{
+					data.BulkString{Data: "name"},
+					data.BulkString{Data: "key"},
+					data.BulkString{Data: "type"},
+					data.BulkString{Data: "key"},
+				}},
+			}},
+		}},
+	}}
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("COMMAND", "INFO", "get", "nexist"), data.Array{Elements: []data.Message{getInfo, data.Null{}}}},
+		{newBulkCmd("COMMAND", "DOCS", "get", "nexist"), getDocs},
+		{newBulkCmd("COMMAND", "COUNT", "extra"), data.Error{ErrMsg: "wrong number of arguments for 'command|count' command"}},
+		{newBulkCmd("COMMAND", "NEXIST"), data.Error{ErrMsg: "unsupported subcommand NEXIST for COMMAND"}},
+		// validation is driven by the command table
+		{newBulkCmd("GET", "key", "extra"), data.Error{ErrMsg: "wrong number of arguments for 'get' command"}},
+		{newBulkCmd("CONFIG", "SET", "maxmemory"), data.Error{ErrMsg: "wrong number of arguments for 'config|set' command"}},
+		{newBulkCmd("MONITOR", "extra"), data.Error{ErrMsg: "wrong number of arguments for 'monitor' command"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+
+	count, ok := ch.HandleCommand(newBulkCmd("COMMAND", "COUNT")).(data.Integer)
+	require.True(t, ok)
+	all := ch.HandleCommand(newBulkCmd("COMMAND")).(data.Array).Elements
+	assert.Len(all, int(count.Value))
+	assert.Len(ch.HandleCommand(newBulkCmd("COMMAND", "INFO")).(data.Array).Elements, int(count.Value))
+	assert.Len(ch.HandleCommand(newBulkCmd("COMMAND", "DOCS")).(data.Array).Elements, 2*int(count.Value))
+
+	// ACL categories follow from the flags of the commands
+	readCommands := ch.HandleCommand(newBulkCmd("ACL", "CAT", "read")).(data.Array).Elements
+	assert.Contains(readCommands, data.BulkString{Data: "get"})
+	assert.NotContains(readCommands, data.BulkString{Data: "set"})
+
+	// container commands list their subcommands
+	configInfo := ch.HandleCommand(newBulkCmd("COMMAND", "INFO", "config")).(data.Array).Elements[0].(data.Array).Elements
+	subcommands := configInfo[9].(data.Array).Elements
+	require.Len(t, subcommands, 2)
+	assert.Equal(data.BulkString{Data: "config|get"}, subcommands[0].(data.Array).Elements[0])
+	assert.Equal(data.Integer{Value: -4}, subcommands[1].(data.Array).Elements[1])
+}
@@ -61,6 +61,56 @@ func TestHandleConfigCommand(t *testing.T) {
 			},
 			data.Error{ErrMsg: "unsupported subcommand NEXIST for CONFIG"},
 		},
+		{
+			data.Array{
+				Elements: []data.Message{
+					data.BulkString{Data: "CONFIG"},
+					data.BulkString{Data: "SET"},
+					data.BulkString{Data: "maxmemory"},
+				},
+			},
+			data.Error{ErrMsg: "wrong number of arguments for 'config|set' command"},
+		},
+		{
+			data.Array{
+				Elements: []data.Message{
+					data.BulkString{Data: "CONFIG"},
+					data.BulkString{Data: "SET"},
+					data.BulkString{Data: "nexist"},
+					data.BulkString{Data: "1"},
+				},
+			},
+			data.Error{ErrMsg: "Unknown option or number of arguments for CONFIG SET - 'nexist'"},
+		},
+		{
+			data.Array{
+				Elements: []data.Message{
+					data.BulkString{Data: "CONFIG"},
+					data.BulkString{Data: "SET"},
+					data.BulkString{Data: "maxmemory"},
+					data.BulkString{Data: "1024"},
+				},
+			},
+			data.SimpleString{Contents: "OK"},
+		},
+		{
+			data.Array{
+				Elements: []data.Message{
+					data.BulkString{Data: "CONFIG"},
+					data.BulkString{Data: "GET"},
+					data.BulkString{Data: "maxmem*"},
+					data.BulkString{Data: "appendonly"},
+				},
+			},
+			data.Array{
+				Elements: []data.Message{
+					data.BulkString{Data: "maxmemory"},
+					data.BulkString{Data: "1024"},
+					data.BulkString{Data: "appendonly"},
+					data.BulkString{Data: "no"},
+				},
+			},
+		},
 	}
 
 	assert := assert.New(t)
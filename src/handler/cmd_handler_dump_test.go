@@ -0,0 +1,59 @@
+package handler_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleDumpRestore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	require.Equal(handler.OK, ch.HandleCommand(newBulkCmd("SET", "key", "value")))
+	payload := ch.HandleCommand(newBulkCmd("DUMP", "key")).(data.BulkString).Data
+	past := strconv.FormatInt(time.Now().Add(-time.Second).UnixMilli(), 10)
+
+	testCases := []struct {
+		input data.Array
+		want  data.Message
+	}{
+		{newBulkCmd("DUMP", "nexist"), data.Null{}},
+		{newBulkCmd("DUMP"), data.Error{ErrMsg: "wrong number of arguments for 'dump' command"}},
+		{newBulkCmd("RESTORE", "key", "0", payload), data.Error{ErrMsg: "BUSYKEY Target key name already exists."}},
+		{newBulkCmd("RESTORE", "copy", "0", "payload"), data.Error{ErrMsg: "DUMP payload version or checksum are wrong"}},
+		{newBulkCmd("RESTORE", "copy", "abc", payload), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("RESTORE", "copy", "-1", payload), data.Error{ErrMsg: "Invalid TTL value, must be >= 0"}},
+		{newBulkCmd("RESTORE", "copy", "0", payload, "IDLETIME", "-1"), data.Error{ErrMsg: "Invalid IDLETIME value, must be >= 0"}},
+		{newBulkCmd("RESTORE", "copy", "0", payload, "FREQ", "256"), data.Error{ErrMsg: "Invalid FREQ value, must be >= 0 and <= 255"}},
+		{newBulkCmd("RESTORE", "copy", "0", payload, "FREQ", "1", "IDLETIME", "1"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("RESTORE", "copy", "0", payload, "NEXIST"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("RESTORE", "copy", "0", payload, "IDLETIME", "10"), handler.OK},
+		{newBulkCmd("GET", "copy"), data.BulkString{Data: "value"}},
+		{newBulkCmd("RESTORE", "key", "100000", payload, "REPLACE", "FREQ", "5"), handler.OK},
+		{newBulkCmd("RESTORE", "expired", past, payload, "ABSTTL"), handler.OK},
+		{newBulkCmd("EXISTS", "expired"), data.Integer{Value: 0}},
+		{newBulkCmd("RESTORE-ASKING", "other", "0", payload), handler.OK},
+		{newBulkCmd("GET", "other"), data.BulkString{Data: "value"}},
+		{newBulkCmd("MIGRATE", "127.0.0.1", "6379", "nexist", "0", "100"), data.SimpleString{Contents: "NOKEY"}},
+		{newBulkCmd("MIGRATE", "127.0.0.1", "6379", "", "0", "100", "KEYS", "nexist"), data.SimpleString{Contents: "NOKEY"}},
+		{newBulkCmd("MIGRATE", "127.0.0.1", "6379", "key", "0", "100", "KEYS", "key"), data.Error{ErrMsg: "When using MIGRATE KEYS option, the key argument must be set to the empty string"}},
+		{newBulkCmd("MIGRATE", "127.0.0.1", "abc", "key", "0", "100"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("MIGRATE", "127.0.0.1", "6379", "key", "0", "100", "NEXIST"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("MIGRATE", "127.0.0.1", "1", "key", "0", "100"), data.Error{ErrMsg: "IOERR error or timeout connecting to the client"}},
+		{newBulkCmd("MIGRATE", "127.0.0.1", "1", "key", "1", "100"), data.Error{ErrMsg: "DB index is out of range"}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.want, ch.HandleCommand(tc.input), bulkArgs(tc.input))
+	}
+}
@@ -0,0 +1,160 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+const (
+	counterLibrary = "#!lua name=counter\n" +
+		"local function incr(keys, args) return redis.call('INCRBY', keys[1], args[1]) end\n" +
+		"redis.register_function('incr', incr)\n" +
+		"redis.register_function{function_name='peek', callback=function(keys) return redis.call('GET', keys[1]) end, " +
+		"flags={'no-writes'}, description='reads the counter'}\n"
+	sneakyLibrary = "#!lua name=sneaky\n" +
+		"redis.register_function{function_name='sneak', callback=function(keys) return redis.call('SET', keys[1], 'x') end, flags={'no-writes'}}\n"
+)
+
+func functionInfo(name string, description data.Message, flags ...string) data.Array {
+	flagElements := make([]data.Message, len(flags))
+	for idx, flag := range flags {
+		flagElements[idx] = data.SimpleString{Contents: flag}
+	}
+	return data.Array{Elements: []data.Message{
+		data.BulkString{Data: "name"},
+		data.BulkString{Data: name},
+		data.BulkString{Data: "description"},
+		description,
+		data.BulkString{Data: "flags"},
+		data.Array{Elements: flagElements},
+	}}
+}
+
+func TestHandleFunctionCommands(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	counterInfo := []data.Message{
+		data.BulkString{Data: "library_name"},
+		data.BulkString{Data: "counter"},
+		data.BulkString{Data: "engine"},
+		data.BulkString{Data: "LUA"},
+		data.BulkString{Data: "functions"},
+		data.Array{Elements: []data.Message{
+			functionInfo("incr", data.Null{}),
+			functionInfo("peek", data.BulkString{Data: "reads the counter"}, "no-writes"),
+		}},
+	}
+
+	testCases := []struct {
+		input data.Array
+		want  data.Message
+	}{
+		{newBulkCmd("FUNCTION", "LOAD", counterLibrary), data.BulkString{Data: "counter"}},
+		{newBulkCmd("FUNCTION", "LOAD", counterLibrary), data.Error{ErrMsg: "Library 'counter' already exists"}},
+		{newBulkCmd("FUNCTION", "LOAD", "REPLACE", counterLibrary), data.BulkString{Data: "counter"}},
+		{newBulkCmd("FUNCTION", "LOAD", "NOW", counterLibrary), data.Error{ErrMsg: "Unknown option given: NOW"}},
+		{newBulkCmd("FCALL", "incr", "1", "hits", "5"), data.Integer{Value: 5}},
+		{newBulkCmd("FCALL", "peek", "1", "hits"), data.BulkString{Data: "5"}},
+		{newBulkCmd("FCALL_RO", "peek", "1", "hits"), data.BulkString{Data: "5"}},
+		{newBulkCmd("FCALL_RO", "incr", "1", "hits", "5"), data.Error{ErrMsg: "Can not execute a script with write flag using *_ro command."}},
+		{newBulkCmd("FCALL", "nexist", "0"), data.Error{ErrMsg: "Function not found"}},
+		{newBulkCmd("FCALL", "incr", "2", "hits"), data.Error{ErrMsg: "Number of keys can't be greater than number of args"}},
+		{newBulkCmd("FCALL", "incr", "1", "hits", "x"), data.Error{ErrMsg: "value is not an integer or out of range script: incr"}},
+		{newBulkCmd("FUNCTION", "LOAD", sneakyLibrary), data.BulkString{Data: "sneaky"}},
+		{newBulkCmd("FCALL", "sneak", "1", "hits"), data.Error{ErrMsg: "Write commands are not allowed from read-only scripts. script: sneak"}},
+		{newBulkCmd("FUNCTION", "LIST", "LIBRARYNAME", "c*"), data.Array{Elements: []data.Message{data.Array{Elements: counterInfo}}}},
+		{newBulkCmd("FUNCTION", "LIST", "LIBRARYNAME", "c*", "WITHCODE"), data.Array{Elements: []data.Message{
+			data.Array{Elements: append(counterInfo, data.BulkString{Data: "library_code"}, data.BulkString{Data: counterLibrary})},
+		}}},
+		{newBulkCmd("FUNCTION", "LIST", "VERBOSE"), data.Error{ErrMsg: "Unknown argument VERBOSE"}},
+		{newBulkCmd("FUNCTION", "DELETE", "sneaky"), handler.OK},
+		{newBulkCmd("FUNCTION", "DELETE", "sneaky"), data.Error{ErrMsg: "Library not found"}},
+		{newBulkCmd("FCALL", "sneak", "1", "hits"), data.Error{ErrMsg: "Function not found"}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua name=copy\nredis.register_function('incr', function() end)"), data.Error{ErrMsg: "Function incr already exists"}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua name=dup\nredis.register_function('f', function() end)\nredis.register_function('f', function() end)"), data.Error{
+			ErrMsg: "Error registering functions: user_function:3: Function already exists in the library",
+		}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua name=calls\nredis.call('GET', 'key')"), data.Error{
+			ErrMsg: "Error registering functions: user_function:2: attempt to call a non-function object",
+		}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua name=global\nx = 1"), data.Error{
+			ErrMsg: "Error registering functions: user_function:2: Script attempted to create global variable 'x'",
+		}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua name=flags\nredis.register_function{function_name='f', callback=function() end, flags={'fast'}}"), data.Error{
+			ErrMsg: "Error registering functions: user_function:2: unknown flag given",
+		}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua name=names\nredis.register_function('a-b', function() end)"), data.Error{
+			ErrMsg: "Error registering functions: user_function:2: Function names can only contain letters, numbers, or underscores(_) and must be at least one character long",
+		}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua name=empty\nlocal unused = 1"), data.Error{ErrMsg: "No functions registered"}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua name=broken\nreturn ("), data.Error{ErrMsg: "Error compiling function: user_function at EOF: syntax error"}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua name=slow\nwhile true do end"), data.Error{ErrMsg: "FUNCTION LOAD timeout"}},
+		{newBulkCmd("FUNCTION", "LOAD", "return 1"), data.Error{ErrMsg: "Missing library metadata"}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!js name=lib\n"), data.Error{ErrMsg: "Engine 'js' not found"}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua\n"), data.Error{ErrMsg: "Library name was not given"}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua name=lib version=1\n"), data.Error{ErrMsg: "Invalid metadata value given: version=1"}},
+		{newBulkCmd("FUNCTION", "LOAD", "#!lua name=my-lib\n"), data.Error{
+			ErrMsg: "Library names can only contain letters, numbers, or underscores(_) and must be at least one character long",
+		}},
+		{newBulkCmd("FUNCTION", "FLUSH", "LAZY"), data.Error{ErrMsg: "FUNCTION FLUSH only supports SYNC|ASYNC option"}},
+		{newBulkCmd("FUNCTION", "KILL"), data.Error{ErrMsg: "NOTBUSY No scripts in execution right now."}},
+		{newBulkCmd("FUNCTION", "STATS"), data.Error{ErrMsg: "unsupported subcommand STATS for FUNCTION"}},
+		{newBulkCmd("EVAL", "return redis.call('FCALL', 'incr', '1', 'hits', '1')", "0"), data.Error{
+			ErrMsg: "This Redis command is not allowed from script script: ee0bd20244ae049badc75673af345eb3b6d82023",
+		}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.want, ch.HandleCommand(tc.input), bulkArgs(tc.input))
+	}
+}
+
+func TestHandleFunctionDumpRestore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	require.Equal(data.BulkString{Data: "counter"}, ch.HandleCommand(newBulkCmd("FUNCTION", "LOAD", counterLibrary)))
+	payload := ch.HandleCommand(newBulkCmd("FUNCTION", "DUMP")).(data.BulkString).Data
+	list := ch.HandleCommand(newBulkCmd("FUNCTION", "LIST", "WITHCODE"))
+	otherLibrary := "#!lua name=other\nredis.register_function('other', function() return 1 end)"
+
+	testCases := []struct {
+		input data.Array
+		want  data.Message
+	}{
+		{newBulkCmd("FUNCTION", "RESTORE", payload), data.Error{ErrMsg: "Library counter already exists"}},
+		{newBulkCmd("FUNCTION", "RESTORE", payload, "MERGE"), data.Error{ErrMsg: "Wrong restore policy given, value should be either FLUSH, APPEND or REPLACE."}},
+		{newBulkCmd("FUNCTION", "RESTORE", "payload"), data.Error{ErrMsg: "payload version or checksum are wrong"}},
+		{newBulkCmd("FUNCTION", "FLUSH"), handler.OK},
+		{newBulkCmd("FUNCTION", "LIST"), data.Array{Elements: []data.Message{}}},
+		{newBulkCmd("FCALL", "incr", "1", "hits", "1"), data.Error{ErrMsg: "Function not found"}},
+		{newBulkCmd("FUNCTION", "RESTORE", payload), handler.OK},
+		{newBulkCmd("FUNCTION", "LIST", "WITHCODE"), list},
+		{newBulkCmd("FUNCTION", "RESTORE", payload, "REPLACE"), handler.OK},
+		{newBulkCmd("FUNCTION", "LOAD", otherLibrary), data.BulkString{Data: "other"}},
+		{newBulkCmd("FUNCTION", "RESTORE", payload, "FLUSH"), handler.OK},
+		{newBulkCmd("FUNCTION", "LIST", "WITHCODE"), list},
+		{newBulkCmd("FCALL", "incr", "1", "hits", "1"), data.Integer{Value: 1}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.want, ch.HandleCommand(tc.input), bulkArgs(tc.input))
+	}
+
+	// the libraries are kept with the dataset, and are compiled again once it is replaced
+	snapshot := storageEngine.Snapshot()
+	require.Equal(handler.OK, ch.HandleCommand(newBulkCmd("FUNCTION", "FLUSH", "SYNC")))
+	require.Nil(storageEngine.LoadSnapshot(snapshot))
+	assert.Equal(data.Integer{Value: 2}, ch.HandleCommand(newBulkCmd("FCALL", "incr", "1", "hits", "1")))
+}
@@ -0,0 +1,127 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func bulkStrings(values ...string) data.Array {
+	elements := make([]data.Message, len(values))
+	for idx, value := range values {
+		elements[idx] = data.BulkString{Data: value}
+	}
+	return data.Array{Elements: elements}
+}
+
+func TestHandleGeoCommands(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("SET", "str", "value"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("GEOADD", "Sicily", "13.361389", "38.115556", "Palermo", "15.087269", "37.502669", "Catania"), data.Integer{Value: 2}},
+		{newBulkCmd("GEOADD", "Sicily", "13.361389", "38.115556", "Palermo"), data.Integer{Value: 0}},
+		{newBulkCmd("GEOADD", "Sicily", "NX", "CH", "13", "38", "Palermo"), data.Integer{Value: 0}},
+		{newBulkCmd("GEOADD", "Sicily", "XX", "13", "38", "Agrigento"), data.Integer{Value: 0}},
+		{newBulkCmd("GEOADD", "moved", "1", "1", "a"), data.Integer{Value: 1}},
+		{newBulkCmd("GEOADD", "moved", "ch", "2", "2", "a", "3", "3", "b"), data.Integer{Value: 2}},
+		{newBulkCmd("GEOADD", "Sicily", "NX", "XX", "13", "38", "Palermo"), data.Error{ErrMsg: "XX and NX options at the same time are not compatible"}},
+		{newBulkCmd("GEOADD", "Sicily", "13", "38"), data.Error{ErrMsg: "wrong number of arguments for 'geoadd' command"}},
+		{newBulkCmd("GEOADD", "Sicily", "CH", "13", "38"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("GEOADD", "Sicily", "13", "north", "Palermo"), data.Error{ErrMsg: "value is not a valid float"}},
+		{newBulkCmd("GEOADD", "Sicily", "10", "90", "pole"), data.Error{ErrMsg: "invalid longitude,latitude pair 10.000000,90.000000"}},
+		{newBulkCmd("GEOADD", "str", "13", "38", "Palermo"), data.Error{ErrMsg: "WRONGTYPE Operation against a key holding the wrong kind of value"}},
+		{newBulkCmd("GET", "Sicily"), data.Error{ErrMsg: "WRONGTYPE Operation against a key holding the wrong kind of value"}},
+
+		{newBulkCmd("GEODIST", "Sicily", "Palermo", "Catania"), data.BulkString{Data: "166274.1516"}},
+		{newBulkCmd("GEODIST", "Sicily", "Palermo", "Catania", "km"), data.BulkString{Data: "166.2742"}},
+		{newBulkCmd("GEODIST", "Sicily", "Palermo", "Catania", "MI"), data.BulkString{Data: "103.3182"}},
+		{newBulkCmd("GEODIST", "Sicily", "Palermo", "Agrigento"), data.Null{}},
+		{newBulkCmd("GEODIST", "Sicily", "Palermo", "Catania", "yards"), data.Error{ErrMsg: "unsupported unit provided. please use M, KM, FT, MI"}},
+		{newBulkCmd("GEODIST", "Sicily", "Palermo", "Catania", "km", "m"), data.Error{ErrMsg: "syntax error"}},
+
+		{newBulkCmd("GEOHASH", "Sicily", "Palermo", "Catania", "Agrigento"), data.Array{Elements: []data.Message{
+			data.BulkString{Data: "sqc8b49rny0"}, data.BulkString{Data: "sqdtr74hyu0"}, data.Null{},
+		}}},
+		{newBulkCmd("GEOPOS", "Sicily", "Palermo", "Agrigento"), data.Array{Elements: []data.Message{
+			bulkStrings("13.361389338970184", "38.1155563954963"), data.Null{},
+		}}},
+		{newBulkCmd("GEOPOS", "nexist", "Palermo"), data.Array{Elements: []data.Message{data.Null{}}}},
+		{newBulkCmd("GEOPOS", "str", "Palermo"), data.Error{ErrMsg: "WRONGTYPE Operation against a key holding the wrong kind of value"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
+
+func TestHandleGeoSearchCommands(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("GEOADD", "Sicily", "13.361389", "38.115556", "Palermo", "15.087269", "37.502669", "Catania"))
+	ch.HandleCommand(newBulkCmd("GEOADD", "Sicily", "12.758489", "38.788135", "edge1", "17.241510", "38.788135", "edge2"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "ASC"), bulkStrings("Catania", "Palermo")},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "DESC"), bulkStrings("Palermo", "Catania")},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYBOX", "400", "400", "km", "ASC", "WITHDIST"), data.Array{Elements: []data.Message{
+			bulkStrings("Catania", "56.4413"), bulkStrings("Palermo", "190.4424"), bulkStrings("edge2", "279.7403"), bulkStrings("edge1", "279.7405"),
+		}}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "200", "km", "ASC", "WITHDIST", "WITHHASH"), data.Array{Elements: []data.Message{
+			data.Array{Elements: []data.Message{data.BulkString{Data: "Palermo"}, data.BulkString{Data: "0.0000"}, data.Integer{Value: 3479099956230698}}},
+			data.Array{Elements: []data.Message{data.BulkString{Data: "edge1"}, data.BulkString{Data: "91.4007"}, data.Integer{Value: 3479273021651468}}},
+			data.Array{Elements: []data.Message{data.BulkString{Data: "Catania"}, data.BulkString{Data: "166.2742"}, data.Integer{Value: 3479447370796909}}},
+		}}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "COUNT", "1", "WITHCOORD"), data.Array{Elements: []data.Message{
+			data.Array{Elements: []data.Message{data.BulkString{Data: "Catania"}, bulkStrings("15.087267458438873", "37.50266842333162")}},
+		}}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYBOX", "1000", "1000", "km", "COUNT", "2", "ANY"), data.Integer{Value: 2}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMLONLAT", "0", "0", "BYRADIUS", "10", "km"), data.Array{Elements: []data.Message{}}},
+		{newBulkCmd("GEOSEARCH", "nexist", "FROMMEMBER", "Palermo", "BYRADIUS", "10", "km"), data.Array{Elements: []data.Message{}}},
+
+		{newBulkCmd("GEOSEARCHSTORE", "near", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km"), data.Integer{Value: 2}},
+		{newBulkCmd("GEOPOS", "near", "Catania"), data.Array{Elements: []data.Message{bulkStrings("15.087267458438873", "37.50266842333162")}}},
+		{newBulkCmd("GEOSEARCHSTORE", "near", "Sicily", "FROMLONLAT", "0", "0", "BYRADIUS", "10", "km"), data.Integer{Value: 0}},
+		{newBulkCmd("EXISTS", "near"), data.Integer{Value: 0}},
+
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMMEMBER", "Agrigento", "BYRADIUS", "10", "km"), data.Error{ErrMsg: "could not decode requested zset member"}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "FROMLONLAT", "15", "37", "BYRADIUS", "10", "km"), data.Error{ErrMsg: "exactly one of FROMMEMBER or FROMLONLAT can be specified for geosearch"}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "BYRADIUS", "10", "km", "ASC", "WITHDIST"), data.Error{ErrMsg: "exactly one of FROMMEMBER or FROMLONLAT can be specified for geosearch"}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "10", "km", "BYBOX", "1", "1", "km"), data.Error{ErrMsg: "exactly one of BYRADIUS and BYBOX can be specified for geosearch"}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "ASC", "WITHDIST", "WITHHASH"), data.Error{ErrMsg: "exactly one of BYRADIUS and BYBOX can be specified for geosearch"}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "10", "km", "ANY"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "10", "km", "COUNT", "0"), data.Error{ErrMsg: "COUNT must be > 0"}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "-1", "km"), data.Error{ErrMsg: "radius cannot be negative"}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "10", "yd"), data.Error{ErrMsg: "unsupported unit provided. please use M, KM, FT, MI"}},
+		{newBulkCmd("GEOSEARCHSTORE", "near", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "10", "km", "WITHDIST"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "10", "km", "STOREDIST"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("GEOSEARCHSTORE", "near", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "200", "km", "STOREDIST"), data.Integer{Value: 3}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			if count, ok := tc.want.(data.Integer); ok && tc.input.(data.Array).Elements[0].(data.BulkString).Data == "GEOSEARCH" {
+				// with ANY, any matching members may be returned
+				assert.Len(result.(data.Array).Elements, int(count.Value))
+				return
+			}
+			assert.Equal(tc.want, result)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handler_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleGetExCommand(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("SET", "key", "value"))
+	ch.HandleCommand(newBulkCmd("SET", "persisted", "value", "PX", "50"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("GETEX", "key"), data.BulkString{Data: "value"}},
+		{newBulkCmd("GETEX", "nexist", "EX", "10"), data.Null{}},
+		{newBulkCmd("GETEX", "persisted", "persist"), data.BulkString{Data: "value"}},
+		{newBulkCmd("GETEX", "key", "PX", "50"), data.BulkString{Data: "value"}},
+		{newBulkCmd("GETEX", "key", "EX", "0"), data.Error{ErrMsg: "invalid expire time in 'getex' command"}},
+		{newBulkCmd("GETEX", "key", "EX", "ten"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("GETEX", "key", "KEEPTTL"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("GETEX", "key", "NX", "10"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("GETEX", "key", "EX", "10", "PERSIST"), data.Error{ErrMsg: "syntax error"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(data.Null{}, ch.HandleCommand(newBulkCmd("GET", "key")))
+	assert.Equal(data.BulkString{Data: "value"}, ch.HandleCommand(newBulkCmd("GET", "persisted")))
+
+	// absolute expiry times in the past remove the key
+	ch.HandleCommand(newBulkCmd("SET", "key", "value"))
+	past := fmt.Sprint(time.Now().Add(-time.Second).UnixMilli())
+	assert.Equal(data.BulkString{Data: "value"}, ch.HandleCommand(newBulkCmd("GETEX", "key", "PXAT", past)))
+	assert.Equal(data.Null{}, ch.HandleCommand(newBulkCmd("GET", "key")))
+}
@@ -0,0 +1,39 @@
+package handler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleGetSetCommand(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("GETSET", "key", "first"), data.Null{}},
+		{newBulkCmd("GETSET", "key", "second"), data.BulkString{Data: "first"}},
+		{newBulkCmd("GET", "key"), data.BulkString{Data: "second"}},
+		{newBulkCmd("SET", "ttl", "old", "PX", "20"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("GETSET", "ttl", "new"), data.BulkString{Data: "old"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+
+	// GETSET discards the previous expiry
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(data.BulkString{Data: "new"}, ch.HandleCommand(newBulkCmd("GET", "ttl")))
+}
@@ -1,6 +1,7 @@
 package handler_test
 
 import (
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,43 +14,44 @@ func TestHandleHelloCommand(t *testing.T) {
 	storageEngine := storage.NewMapStorageEngine()
 	ch := handler.NewCommandHandler(&storageEngine)
 
+	conn, peer := net.Pipe()
+	defer func() { _ = peer.Close() }()
+	client := ch.NewClient(conn)
+
+	hello := data.Array{Elements: []data.Message{
+		data.BulkString{Data: "server"},
+		data.BulkString{Data: handler.SERVER_NAME},
+		data.BulkString{Data: "version"},
+		data.BulkString{Data: handler.SERVER_VERSION},
+		data.BulkString{Data: "proto"},
+		data.Integer{Value: 2},
+		data.BulkString{Data: "id"},
+		data.Integer{Value: client.ID()},
+		data.BulkString{Data: "mode"},
+		data.BulkString{Data: "standalone"},
+		data.BulkString{Data: "role"},
+		data.BulkString{Data: "master"},
+		data.BulkString{Data: "modules"},
+		data.Array{Elements: []data.Message{}},
+	}}
+
 	testCases := []struct {
-		input data.Message
-		want  data.Message
+		client *handler.Client
+		input  data.Message
+		want   data.Message
 	}{
-		{
-			data.Array{
-				Elements: []data.Message{
-					data.BulkString{Data: "HELLO"},
-					data.BulkString{Data: "3"},
-				},
-			},
-			data.Error{ErrMsg: "NOPROTO sorry, this protocol version is not supported"},
-		},
-		{
-			data.Array{
-				Elements: []data.Message{
-					data.BulkString{Data: "HELLO"},
-					data.BulkString{Data: "2"},
-				},
-			},
-			data.SimpleString{Contents: "OK"},
-		},
-		{
-			data.Array{
-				Elements: []data.Message{
-					data.BulkString{Data: "HELLO"},
-					data.BulkString{Data: "nan"},
-				},
-			},
-			data.Error{ErrMsg: "invalid args for command"},
-		},
+		{client, newBulkCmd("HELLO", "3"), data.Error{ErrMsg: "NOPROTO sorry, this protocol version is not supported"}},
+		{client, newBulkCmd("HELLO", "2"), hello},
+		// without a protocol version, the connection keeps its protocol and still gets its properties
+		{client, newBulkCmd("HELLO"), hello},
+		{client, newBulkCmd("HELLO", "nan"), data.Error{ErrMsg: "invalid args for command"}},
+		{nil, newBulkCmd("HELLO"), data.Error{ErrMsg: "this command requires a client connection"}},
 	}
 
 	assert := assert.New(t)
 	for _, tc := range testCases {
 		t.Run(tc.input.ToDataString(), func(t *testing.T) {
-			result := ch.HandleCommand(tc.input)
+			result := ch.HandleClientCommand(tc.client, tc.input)
 			assert.Equal(tc.want, result)
 		})
 	}
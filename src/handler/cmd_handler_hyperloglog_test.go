@@ -0,0 +1,62 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleHyperLogLogCommands(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("SET", "str", "value"))
+	ch.HandleCommand(newBulkCmd("SET", "corrupt", "HYLL\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x7f\xfe"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("PFADD", "hll"), data.Integer{Value: 1}},
+		{newBulkCmd("PFADD", "hll"), data.Integer{Value: 0}},
+		{newBulkCmd("PFCOUNT", "hll"), data.Integer{Value: 0}},
+		{newBulkCmd("PFADD", "hll", "a", "b", "c", "d", "e", "f", "g"), data.Integer{Value: 1}},
+		{newBulkCmd("PFADD", "hll", "a", "b"), data.Integer{Value: 0}},
+		{newBulkCmd("PFCOUNT", "hll"), data.Integer{Value: 7}},
+		{newBulkCmd("PFADD", "other", "f", "g", "h", "i"), data.Integer{Value: 1}},
+		{newBulkCmd("PFCOUNT", "hll", "other", "nexist"), data.Integer{Value: 9}},
+		{newBulkCmd("PFMERGE", "merged", "hll", "other"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("PFCOUNT", "merged"), data.Integer{Value: 9}},
+		{newBulkCmd("PFMERGE", "empty"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("PFCOUNT", "empty"), data.Integer{Value: 0}},
+		{newBulkCmd("PFCOUNT", "nexist"), data.Integer{Value: 0}},
+		{newBulkCmd("PFADD", "str", "a"), data.Error{ErrMsg: "WRONGTYPE Key is not a valid HyperLogLog string value."}},
+		{newBulkCmd("PFCOUNT", "hll", "str"), data.Error{ErrMsg: "WRONGTYPE Key is not a valid HyperLogLog string value."}},
+		{newBulkCmd("PFMERGE", "str", "hll"), data.Error{ErrMsg: "WRONGTYPE Key is not a valid HyperLogLog string value."}},
+		{newBulkCmd("PFCOUNT", "corrupt"), data.Error{ErrMsg: "INVALIDOBJ Corrupted HLL object detected"}},
+		{newBulkCmd("PFCOUNT"), data.Error{ErrMsg: "wrong number of arguments for 'pfcount' command"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
+
+func TestHandleHyperLogLogRoundTrip(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	assert := assert.New(t)
+
+	ch.HandleCommand(newBulkCmd("PFADD", "hll", "a", "b", "c"))
+	value := ch.HandleCommand(newBulkCmd("GET", "hll")).(data.BulkString)
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleCommand(newBulkCmd("SET", "copy", value.Data)))
+	assert.Equal(data.Integer{Value: 3}, ch.HandleCommand(newBulkCmd("PFCOUNT", "copy")))
+	assert.Equal(data.Integer{Value: 1}, ch.HandleCommand(newBulkCmd("PFADD", "copy", "d")))
+	assert.Equal(data.Integer{Value: 4}, ch.HandleCommand(newBulkCmd("PFCOUNT", "copy")))
+}
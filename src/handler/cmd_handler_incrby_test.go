@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleIncrByCommands(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("SET", "max", "9223372036854775807"))
+	ch.HandleCommand(newBulkCmd("SET", "min", "-9223372036854775808"))
+	ch.HandleCommand(newBulkCmd("SET", "str", "abc"))
+	ch.HandleCommand(newBulkCmd("SET", "flt", "10.50"))
+	ch.HandleCommand(newBulkCmd("SET", "exp", "5.0e3"))
+	ch.HandleCommand(newBulkCmd("SET", "big", "1.7e308"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("INCRBY", "ctr", "10"), data.Integer{Value: 10}},
+		{newBulkCmd("DECRBY", "ctr", "15"), data.Integer{Value: -5}},
+		{newBulkCmd("incrby", "ctr", "-5"), data.Integer{Value: -10}},
+		{newBulkCmd("INCRBY", "ctr", "ten"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("INCRBY", "ctr", "99999999999999999999"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("INCRBY", "str", "1"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("INCRBY", "max", "1"), data.Error{ErrMsg: "increment or decrement would overflow"}},
+		{newBulkCmd("INCR", "max"), data.Error{ErrMsg: "increment or decrement would overflow"}},
+		{newBulkCmd("DECRBY", "min", "1"), data.Error{ErrMsg: "increment or decrement would overflow"}},
+		{newBulkCmd("DECRBY", "ctr", "-9223372036854775808"), data.Error{ErrMsg: "decrement would overflow"}},
+		{newBulkCmd("INCRBY", "ctr"), data.Error{ErrMsg: "wrong number of arguments for 'incrby' command"}},
+		{newBulkCmd("INCRBYFLOAT", "flt", "0.1"), data.BulkString{Data: "10.6"}},
+		{newBulkCmd("INCRBYFLOAT", "exp", "2.0e2"), data.BulkString{Data: "5200"}},
+		{newBulkCmd("INCRBYFLOAT", "newflt", "-1.5"), data.BulkString{Data: "-1.5"}},
+		{newBulkCmd("INCRBYFLOAT", "ctr", "3"), data.BulkString{Data: "-7"}},
+		{newBulkCmd("INCRBYFLOAT", "str", "1"), data.Error{ErrMsg: "value is not a valid float"}},
+		{newBulkCmd("INCRBYFLOAT", "flt", "abc"), data.Error{ErrMsg: "value is not a valid float"}},
+		{newBulkCmd("INCRBYFLOAT", "flt", "inf"), data.Error{ErrMsg: "value is not a valid float"}},
+		{newBulkCmd("INCRBYFLOAT", "big", "1.7e308"), data.Error{ErrMsg: "increment would produce NaN or Infinity"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
+
+func TestHandleIncrByKeepsExpiry(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	assert := assert.New(t)
+
+	ch.HandleCommand(newBulkCmd("SET", "ctr", "1", "PX", "50"))
+	ch.HandleCommand(newBulkCmd("SET", "flt", "1.5", "PX", "50"))
+	assert.Equal(data.Integer{Value: 2}, ch.HandleCommand(newBulkCmd("INCR", "ctr")))
+	assert.Equal(data.Integer{Value: 7}, ch.HandleCommand(newBulkCmd("INCRBY", "ctr", "5")))
+	assert.Equal(data.Integer{Value: 4}, ch.HandleCommand(newBulkCmd("DECRBY", "ctr", "3")))
+	assert.Equal(data.BulkString{Data: "2"}, ch.HandleCommand(newBulkCmd("INCRBYFLOAT", "flt", "0.5")))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(data.Null{}, ch.HandleCommand(newBulkCmd("GET", "ctr")))
+	assert.Equal(data.Null{}, ch.HandleCommand(newBulkCmd("GET", "flt")))
+}
@@ -0,0 +1,65 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func lcsRange(start int64, end int64) data.Array {
+	return data.Array{Elements: []data.Message{data.Integer{Value: start}, data.Integer{Value: end}}}
+}
+
+func TestHandleLcsCommand(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("SET", "key1", "ohmytext"))
+	ch.HandleCommand(newBulkCmd("SET", "key2", "mynewtext"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("LCS", "key1", "key2"), data.BulkString{Data: "mytext"}},
+		{newBulkCmd("LCS", "key1", "key2", "LEN"), data.Integer{Value: 6}},
+		{newBulkCmd("LCS", "key1", "nexist"), data.BulkString{Data: ""}},
+		{
+			newBulkCmd("LCS", "key1", "key2", "IDX"),
+			data.Array{Elements: []data.Message{
+				data.BulkString{Data: "matches"},
+				data.Array{Elements: []data.Message{
+					data.Array{Elements: []data.Message{lcsRange(4, 7), lcsRange(5, 8)}},
+					data.Array{Elements: []data.Message{lcsRange(2, 3), lcsRange(0, 1)}},
+				}},
+				data.BulkString{Data: "len"},
+				data.Integer{Value: 6},
+			}},
+		},
+		{
+			newBulkCmd("LCS", "key1", "key2", "IDX", "MINMATCHLEN", "4", "WITHMATCHLEN"),
+			data.Array{Elements: []data.Message{
+				data.BulkString{Data: "matches"},
+				data.Array{Elements: []data.Message{
+					data.Array{Elements: []data.Message{lcsRange(4, 7), lcsRange(5, 8), data.Integer{Value: 4}}},
+				}},
+				data.BulkString{Data: "len"},
+				data.Integer{Value: 6},
+			}},
+		},
+		{newBulkCmd("LCS", "key1", "key2", "LEN", "IDX"), data.Error{ErrMsg: "If you want both the length and indexes, please just use IDX."}},
+		{newBulkCmd("LCS", "key1", "key2", "MINMATCHLEN"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("LCS", "key1", "key2", "MINMATCHLEN", "x"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("LCS", "key1", "key2", "NEXIST"), data.Error{ErrMsg: "syntax error"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleMGetCommand(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("SET", "key1", "Hello"))
+	ch.HandleCommand(newBulkCmd("SET", "key2", "World"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{
+			newBulkCmd("MGET", "key1", "nexist", "key2"),
+			data.Array{Elements: []data.Message{data.BulkString{Data: "Hello"}, data.Null{}, data.BulkString{Data: "World"}}},
+		},
+		{newBulkCmd("MGET", "nexist"), data.Array{Elements: []data.Message{data.Null{}}}},
+		{newBulkCmd("MGET"), data.Error{ErrMsg: "wrong number of arguments for 'mget' command"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
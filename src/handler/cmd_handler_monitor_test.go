@@ -0,0 +1,68 @@
+package handler_test
+
+import (
+	"bufio"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleMonitorCommand(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	assert.Equal(handler.NO_CLIENT_CONN, ch.HandleCommand(newBulkCmd("MONITOR")))
+
+	monitorConn, monitorPeer := net.Pipe()
+	defer func() { _ = monitorPeer.Close() }()
+	monitor := ch.NewClient(monitorConn)
+	assert.Equal(handler.OK, ch.HandleClientCommand(monitor, newBulkCmd("MONITOR")))
+	assert.Contains(ch.HandleClientCommand(monitor, newBulkCmd("CLIENT", "INFO")).(data.BulkString).Data, "flags=O")
+
+	conn2, peer2 := net.Pipe()
+	defer func() { _ = peer2.Close() }()
+	client2 := ch.NewClient(conn2)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// administrative commands are not shown
+		ch.HandleClientCommand(client2, newBulkCmd("CONFIG", "GET", "port"))
+		ch.HandleClientCommand(client2, newBulkCmd("SET", "key", "multi\nline \"value\""))
+		ch.HandleClientCommand(client2, newBulkCmd("AUTH", "secret"))
+		ch.HandleClientCommand(client2, newBulkCmd("GET", "key"))
+		// the commands of scripts are shown as coming from lua, before the script itself
+		ch.HandleClientCommand(client2, newBulkCmd("EVAL", "return redis.call('GET', KEYS[1])", "1", "key"))
+	}()
+
+	require.Nil(monitorPeer.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	reader := bufio.NewReader(monitorPeer)
+	lines := []string{}
+	for range 5 {
+		line, err := reader.ReadString('\n')
+		require.Nil(err)
+		lines = append(lines, line)
+	}
+	<-done
+
+	prefix := `^\+\d+\.\d{6} \[0 ` + regexp.QuoteMeta(client2.Addr()) + `\] `
+	assert.Regexp(prefix+regexp.QuoteMeta(`"SET" "key" "multi\nline \"value\""`)+"\r\n$", lines[0])
+	assert.Regexp(prefix+regexp.QuoteMeta(`"AUTH" "(redacted)"`)+"\r\n$", lines[1])
+	assert.Regexp(prefix+regexp.QuoteMeta(`"GET" "key"`)+"\r\n$", lines[2])
+	assert.Regexp(`^\+\d+\.\d{6} \[0 lua\] `+regexp.QuoteMeta(`"GET" "key"`)+"\r\n$", lines[3])
+	assert.Regexp(prefix+regexp.QuoteMeta(`"EVAL" "return redis.call('GET', KEYS[1])" "1" "key"`)+"\r\n$", lines[4])
+
+	// once the monitor is gone, commands are no longer streamed to it
+	monitor.Close()
+	assert.Equal(handler.OK, ch.HandleClientCommand(client2, newBulkCmd("SET", "key", "value")))
+}
@@ -0,0 +1,81 @@
+package handler_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleMSetCommand(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("MSET", "key1", "Hello", "key2", "World"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("MGET", "key1", "key2"), data.Array{Elements: []data.Message{data.BulkString{Data: "Hello"}, data.BulkString{Data: "World"}}}},
+		// the last value wins for repeated keys
+		{newBulkCmd("MSET", "key1", "a", "key1", "b"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("GET", "key1"), data.BulkString{Data: "b"}},
+		{newBulkCmd("MSET", "key1", "a", "key2"), data.Error{ErrMsg: "wrong number of arguments for 'mset' command"}},
+		{newBulkCmd("MSETNX", "key3", "x", "key4", "y"), data.Integer{Value: 1}},
+		// nothing is set if any key exists
+		{newBulkCmd("MSETNX", "key5", "x", "key3", "y"), data.Integer{Value: 0}},
+		{newBulkCmd("MGET", "key3", "key5"), data.Array{Elements: []data.Message{data.BulkString{Data: "x"}, data.Null{}}}},
+		{newBulkCmd("MSETNX", "key5"), data.Error{ErrMsg: "wrong number of arguments for 'msetnx' command"}},
+		{newBulkCmd("MSETNX", "key5", "x", "key6"), data.Error{ErrMsg: "wrong number of arguments for 'msetnx' command"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
+
+func TestHandleMSetIsAtomic(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	numKeys := 1000
+	mgetArgs := []string{"MGET"}
+	pairs := func(value string) data.Array {
+		args := []string{"MSET"}
+		for idx := range numKeys {
+			args = append(args, fmt.Sprintf("key%d", idx), value)
+		}
+		return newBulkCmd(args...)
+	}
+	for idx := range numKeys {
+		mgetArgs = append(mgetArgs, fmt.Sprintf("key%d", idx))
+	}
+	ch.HandleCommand(pairs("old"))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 20 {
+			ch.HandleCommand(pairs("old"))
+			ch.HandleCommand(pairs("new"))
+		}
+	}()
+
+	// readers never observe a partially applied MSET
+	for range 20 {
+		values := ch.HandleCommand(newBulkCmd(mgetArgs...)).(data.Array).Elements
+		for _, value := range values {
+			assert.Equal(t, values[0], value)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,193 @@
+package handler_test
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func subscription(kind string, target data.Message, count int64) string {
+	return data.Array{Elements: []data.Message{data.BulkString{Data: kind}, target, data.Integer{Value: count}}}.ToDataString()
+}
+
+// readMessages reads the messages delivered to a subscriber, which must be exactly the expected ones.
+func readMessages(t *testing.T, peer net.Conn, messages ...data.Array) {
+	expected := ""
+	for _, msg := range messages {
+		expected += msg.ToDataString()
+	}
+
+	require.Nil(t, peer.SetReadDeadline(time.Now().Add(5*time.Second)))
+	received := make([]byte, len(expected))
+	_, err := io.ReadFull(peer, received)
+	require.Nil(t, err)
+	assert.Equal(t, expected, string(received))
+}
+
+func TestHandlePubSubCommands(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	assert.Equal(handler.NO_CLIENT_CONN, ch.HandleCommand(newBulkCmd("SUBSCRIBE", "news")))
+
+	conn, peer := net.Pipe()
+	defer func() { _ = peer.Close() }()
+	subscriber := ch.NewClient(conn)
+
+	assert.Equal(
+		subscription("subscribe", data.BulkString{Data: "news"}, 1)+subscription("subscribe", data.BulkString{Data: "sport"}, 2),
+		ch.HandleClientCommand(subscriber, newBulkCmd("SUBSCRIBE", "news", "sport")).ToDataString(),
+	)
+	assert.Equal(subscription("psubscribe", data.BulkString{Data: "n*"}, 3), ch.HandleClientCommand(subscriber, newBulkCmd("PSUBSCRIBE", "n*")).ToDataString())
+
+	// subscribed clients can only manage their subscriptions
+	assert.Equal(data.Error{
+		ErrMsg: "Can't execute 'get': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING are allowed in this context",
+	}, ch.HandleClientCommand(subscriber, newBulkCmd("GET", "key")))
+	assert.Equal(bulkStrings("pong", ""), ch.HandleClientCommand(subscriber, newBulkCmd("PING")))
+	assert.Equal(bulkStrings("pong", "hello"), ch.HandleClientCommand(subscriber, newBulkCmd("PING", "hello")))
+	assert.Contains(ch.HandleCommand(newBulkCmd("CLIENT", "LIST", "TYPE", "pubsub")).(data.BulkString).Data, "flags=P")
+	assert.Equal("pubsub", subscriber.OutputBufferClass())
+
+	testCases := []struct {
+		input data.Array
+		want  data.Message
+	}{
+		{newBulkCmd("PUBSUB", "CHANNELS"), bulkStrings("news", "sport")},
+		{newBulkCmd("PUBSUB", "CHANNELS", "s*"), bulkStrings("sport")},
+		{newBulkCmd("PUBSUB", "CHANNELS", "s*", "n*"), data.Error{ErrMsg: "wrong number of arguments for 'pubsub|channels' command"}},
+		{newBulkCmd("PUBSUB", "NUMSUB", "news", "nexist"), data.Array{Elements: []data.Message{
+			data.BulkString{Data: "news"}, data.Integer{Value: 1}, data.BulkString{Data: "nexist"}, data.Integer{Value: 0},
+		}}},
+		{newBulkCmd("PUBSUB", "NUMPAT"), data.Integer{Value: 1}},
+		{newBulkCmd("PUBSUB", "SHARDCHANNELS"), data.Error{ErrMsg: "unsupported subcommand SHARDCHANNELS for PUBSUB"}},
+	}
+	for _, tc := range testCases {
+		assert.Equal(tc.want, ch.HandleCommand(tc.input), bulkArgs(tc.input))
+	}
+
+	// the messages are delivered while PUBLISH runs, so they are read on the side
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.Equal(data.Integer{Value: 2}, ch.HandleCommand(newBulkCmd("PUBLISH", "news", "hello")))
+		assert.Equal(data.Integer{Value: 1}, ch.HandleCommand(newBulkCmd("PUBLISH", "sport", "score")))
+		assert.Equal(data.Integer{Value: 0}, ch.HandleCommand(newBulkCmd("PUBLISH", "weather", "rain")))
+	}()
+	readMessages(t, peer,
+		bulkStrings("message", "news", "hello"),
+		bulkStrings("pmessage", "n*", "news", "hello"),
+		bulkStrings("message", "sport", "score"),
+	)
+	<-done
+
+	assert.Equal(
+		subscription("unsubscribe", data.BulkString{Data: "news"}, 2)+subscription("unsubscribe", data.BulkString{Data: "sport"}, 1),
+		ch.HandleClientCommand(subscriber, newBulkCmd("UNSUBSCRIBE")).ToDataString(),
+	)
+	assert.Equal(subscription("punsubscribe", data.BulkString{Data: "n*"}, 0), ch.HandleClientCommand(subscriber, newBulkCmd("PUNSUBSCRIBE", "n*")).ToDataString())
+	assert.Equal(subscription("punsubscribe", data.Null{}, 0), ch.HandleClientCommand(subscriber, newBulkCmd("PUNSUBSCRIBE")).ToDataString())
+	assert.Equal(data.Null{}, ch.HandleClientCommand(subscriber, newBulkCmd("GET", "key")))
+	assert.Equal(data.SimpleString{Contents: "PONG"}, ch.HandleClientCommand(subscriber, newBulkCmd("PING")))
+	assert.Equal(data.Integer{Value: 0}, ch.HandleCommand(newBulkCmd("PUBLISH", "news", "hello")))
+	assert.Equal(data.Array{Elements: []data.Message{}}, ch.HandleCommand(newBulkCmd("PUBSUB", "CHANNELS")))
+
+	// the subscriptions are dropped along with the client
+	assert.Equal(subscription("subscribe", data.BulkString{Data: "news"}, 1), ch.HandleClientCommand(subscriber, newBulkCmd("SUBSCRIBE", "news")).ToDataString())
+	subscriber.Close()
+	assert.Equal(data.Integer{Value: 0}, ch.HandleCommand(newBulkCmd("PUBLISH", "news", "hello")))
+}
+
+func TestHandlePubSubPermissions(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn, peer := net.Pipe()
+	defer func() { _ = peer.Close() }()
+	client := ch.NewClient(conn)
+
+	noPerm := data.Error{ErrMsg: "NOPERM No permissions to access a channel"}
+	assert.Equal(handler.OK, ch.HandleClientCommand(client, newBulkCmd("ACL", "SETUSER", "reader", "on", "nopass", "~*", "&news.*", "+@all")))
+	assert.Equal(handler.OK, ch.HandleClientCommand(client, newBulkCmd("AUTH", "reader", "any")))
+	assert.Equal(noPerm, ch.HandleClientCommand(client, newBulkCmd("PUBLISH", "sport", "score")))
+	assert.Equal(data.Integer{Value: 0}, ch.HandleClientCommand(client, newBulkCmd("PUBLISH", "news.local", "hello")))
+	assert.Equal(noPerm, ch.HandleClientCommand(client, newBulkCmd("SUBSCRIBE", "news.local", "sport")))
+	assert.Equal(noPerm, ch.HandleClientCommand(client, newBulkCmd("PSUBSCRIBE", "*")))
+	assert.Equal(subscription("psubscribe", data.BulkString{Data: "news.*"}, 1), ch.HandleClientCommand(client, newBulkCmd("PSUBSCRIBE", "news.*")).ToDataString())
+}
+
+func TestHandleKeyspaceNotifications(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	require.NoError(cfg.Set("notify-keyspace-events", "E$lx"))
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandlerWithConfig(&storageEngine, cfg)
+
+	conn, peer := net.Pipe()
+	defer func() { _ = peer.Close() }()
+	subscriber := ch.NewClient(conn)
+	ch.HandleClientCommand(subscriber, newBulkCmd("PSUBSCRIBE", "__keyevent@0__:*", "__keyspace@0__:*"))
+
+	keyevent := func(event string, key string) data.Array {
+		return bulkStrings("pmessage", "__keyevent@0__:*", "__keyevent@0__:"+event, key)
+	}
+	keyspace := func(key string, event string) data.Array {
+		return bulkStrings("pmessage", "__keyspace@0__:*", "__keyspace@0__:"+key, event)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// the generic events, like del and expire, are not enabled
+		ch.HandleCommand(newBulkCmd("SET", "key", "value"))
+		ch.HandleCommand(newBulkCmd("DEL", "key"))
+		ch.HandleCommand(newBulkCmd("INCRBY", "counter", "5"))
+		ch.HandleCommand(newBulkCmd("LPUSH", "list", "a"))
+		ch.HandleCommand(newBulkCmd("RPUSH", "list", "b"))
+		ch.HandleCommand(newBulkCmd("SET", "temp", "value", "PX", "1"))
+		time.Sleep(5 * time.Millisecond)
+		ch.HandleCommand(newBulkCmd("GET", "temp"))
+
+		assert.Equal(handler.OK, ch.HandleCommand(newBulkCmd("CONFIG", "SET", "notify-keyspace-events", "Kg")))
+		ch.HandleCommand(newBulkCmd("SET", "key", "value"))
+		ch.HandleCommand(newBulkCmd("DEL", "counter"))
+		assert.Equal(handler.OK, ch.HandleCommand(newBulkCmd("CONFIG", "SET", "notify-keyspace-events", "")))
+		ch.HandleCommand(newBulkCmd("DEL", "key"))
+		assert.Equal(handler.OK, ch.HandleCommand(newBulkCmd("CONFIG", "SET", "notify-keyspace-events", "KEA")))
+		ch.HandleCommand(newBulkCmd("DEL", "list"))
+	}()
+	readMessages(t, peer,
+		keyevent("set", "key"),
+		keyevent("incrby", "counter"),
+		keyevent("lpush", "list"),
+		keyevent("rpush", "list"),
+		keyevent("set", "temp"),
+		keyevent("expired", "temp"),
+		keyspace("counter", "del"),
+		keyspace("list", "del"),
+		keyevent("del", "list"),
+	)
+	<-done
+
+	assert.Equal(data.Error{
+		ErrMsg: "Invalid argument 'KEQ' for CONFIG SET 'notify-keyspace-events' - Invalid event class character. Use 'Ag$lshzxKEtmn'.",
+	}, ch.HandleCommand(newBulkCmd("CONFIG", "SET", "notify-keyspace-events", "KEQ")))
+	// keys are never evicted, so the evicted events can't be selected
+	assert.Equal(data.Error{
+		ErrMsg: "Invalid argument 'KEe' for CONFIG SET 'notify-keyspace-events' - The evicted events of 'e' are not supported, since keys are never evicted.",
+	}, ch.HandleCommand(newBulkCmd("CONFIG", "SET", "notify-keyspace-events", "KEe")))
+}
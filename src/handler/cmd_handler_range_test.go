@@ -0,0 +1,73 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleGetRangeCommand(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("SET", "mykey", "This is a string"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("GETRANGE", "mykey", "0", "3"), data.BulkString{Data: "This"}},
+		{newBulkCmd("GETRANGE", "mykey", "-3", "-1"), data.BulkString{Data: "ing"}},
+		{newBulkCmd("GETRANGE", "mykey", "0", "-1"), data.BulkString{Data: "This is a string"}},
+		{newBulkCmd("GETRANGE", "mykey", "10", "100"), data.BulkString{Data: "string"}},
+		{newBulkCmd("GETRANGE", "mykey", "-1", "-5"), data.BulkString{Data: ""}},
+		{newBulkCmd("GETRANGE", "mykey", "5", "3"), data.BulkString{Data: ""}},
+		{newBulkCmd("GETRANGE", "nexist", "0", "-1"), data.BulkString{Data: ""}},
+		{newBulkCmd("GETRANGE", "mykey", "a", "3"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
+
+func TestHandleSetRangeCommand(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("SET", "key1", "Hello World"))
+	ch.HandleCommand(newBulkCmd("SET", "ttl", "value", "EX", "100"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("SETRANGE", "key1", "6", "Redis"), data.Integer{Value: 11}},
+		{newBulkCmd("GET", "key1"), data.BulkString{Data: "Hello Redis"}},
+		// missing bytes are padded with zeros
+		{newBulkCmd("SETRANGE", "key2", "6", "Redis"), data.Integer{Value: 11}},
+		{newBulkCmd("GET", "key2"), data.BulkString{Data: "\x00\x00\x00\x00\x00\x00Redis"}},
+		{newBulkCmd("SETRANGE", "key1", "13", "!"), data.Integer{Value: 14}},
+		{newBulkCmd("GET", "key1"), data.BulkString{Data: "Hello Redis\x00\x00!"}},
+		// an empty value does not create the key
+		{newBulkCmd("SETRANGE", "key3", "5", ""), data.Integer{Value: 0}},
+		{newBulkCmd("EXISTS", "key3"), data.Integer{Value: 0}},
+		{newBulkCmd("SETRANGE", "ttl", "0", "V"), data.Integer{Value: 5}},
+		{newBulkCmd("SETRANGE", "key1", "-1", "x"), data.Error{ErrMsg: "offset is out of range"}},
+		{newBulkCmd("SETRANGE", "key1", "536870912", "x"), data.Error{ErrMsg: "string exceeds maximum allowed size (proto-max-bulk-len)"}},
+		{newBulkCmd("SETRANGE", "key1", "one", "x"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
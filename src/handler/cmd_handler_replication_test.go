@@ -0,0 +1,116 @@
+package handler_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleReplicationCommands(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn1, peer1 := net.Pipe()
+	defer func() { _ = peer1.Close() }()
+	client1 := ch.NewClient(conn1)
+
+	testCases := []struct {
+		client *handler.Client
+		input  data.Array
+		want   data.Message
+	}{
+		{client1, newBulkCmd("ROLE"), data.Array{Elements: []data.Message{
+			data.BulkString{Data: "master"}, data.Integer{Value: 0}, data.Array{Elements: []data.Message{}},
+		}}},
+		{client1, newBulkCmd("ROLE", "extra"), data.Error{ErrMsg: "wrong number of arguments for 'role' command"}},
+		{client1, newBulkCmd("WAIT", "0", "0"), data.Integer{Value: 0}},
+		{client1, newBulkCmd("WAIT", "1", "10"), data.Integer{Value: 0}},
+		{client1, newBulkCmd("WAIT", "abc", "0"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{client1, newBulkCmd("WAIT", "1", "abc"), data.Error{ErrMsg: "timeout is not an integer or out of range"}},
+		{client1, newBulkCmd("WAIT", "1", "-1"), data.Error{ErrMsg: "timeout is negative"}},
+		{client1, newBulkCmd("REPLCONF", "listening-port", "6380"), data.SimpleString{Contents: "OK"}},
+		{client1, newBulkCmd("REPLCONF", "listening-port", "abc"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{client1, newBulkCmd("REPLCONF", "capa", "psync2", "ip-address", "10.0.0.1"), data.SimpleString{Contents: "OK"}},
+		{client1, newBulkCmd("REPLCONF", "listening-port"), data.Error{ErrMsg: "syntax error"}},
+		{client1, newBulkCmd("REPLCONF", "nexist", "1"), data.Error{ErrMsg: "Unrecognized REPLCONF option: nexist"}},
+		{nil, newBulkCmd("REPLCONF", "listening-port", "6380"), data.Error{ErrMsg: "this command requires a client connection"}},
+		{client1, newBulkCmd("PSYNC", "?", "abc"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{nil, newBulkCmd("PSYNC", "?", "-1"), data.Error{ErrMsg: "this command requires a client connection"}},
+		{client1, newBulkCmd("REPLICAOF", "localhost", "abc"), data.Error{ErrMsg: "Invalid master port"}},
+		{client1, newBulkCmd("REPLICAOF", "localhost", "70000"), data.Error{ErrMsg: "Invalid master port"}},
+		{client1, newBulkCmd("REPLICAOF", "no", "one"), data.SimpleString{Contents: "OK"}},
+		{client1, newBulkCmd("INFO", "nexist"), data.BulkString{Data: ""}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleClientCommand(tc.client, tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
+
+func TestHandleInfoCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	info := ch.HandleCommand(newBulkCmd("INFO")).(data.BulkString).Data
+	assert.True(strings.HasPrefix(info, "# Server\r\nredis_mode:standalone\r\n"))
+	assert.Contains(info, "\r\n# Clients\r\nconnected_clients:0\r\n")
+	assert.Contains(info, "\r\n# Replication\r\nrole:master\r\nconnected_slaves:0\r\n")
+	assert.Contains(info, "tcp_port:6379\r\n")
+	assert.Equal(info, ch.HandleCommand(newBulkCmd("INFO", "everything")).(data.BulkString).Data)
+
+	replication := ch.HandleCommand(newBulkCmd("INFO", "REPLICATION", "nexist")).(data.BulkString).Data
+	assert.True(strings.HasPrefix(replication, "# Replication\r\nrole:master\r\n"))
+	assert.Contains(replication, "master_repl_offset:0\r\n")
+	assert.Contains(replication, "repl_backlog_active:0\r\n")
+	assert.NotContains(replication, "# Server")
+
+	both := ch.HandleCommand(newBulkCmd("INFO", "clients", "server")).(data.BulkString).Data
+	assert.True(strings.HasPrefix(both, "# Server\r\n"))
+	assert.Contains(both, "# Clients\r\n")
+}
+
+func TestHandleReadOnlyReplica(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	// nothing listens on the port, so the replica keeps trying to connect to its master
+	require.Equal(data.SimpleString{Contents: "OK"}, ch.HandleCommand(newBulkCmd("REPLICAOF", "127.0.0.1", "1")))
+	defer ch.HandleCommand(newBulkCmd("REPLICAOF", "NO", "ONE"))
+	assert.Equal(data.SimpleString{Contents: "OK Already connected to specified master"}, ch.HandleCommand(newBulkCmd("REPLICAOF", "127.0.0.1", "1")))
+
+	assert.Equal(data.Error{ErrMsg: "READONLY You can't write against a read only replica."}, ch.HandleCommand(newBulkCmd("SET", "key", "value")))
+	assert.Equal(data.Null{}, ch.HandleCommand(newBulkCmd("GET", "key")))
+	assert.Equal(data.Error{ErrMsg: "WAIT cannot be used with replica instances. Please also note that writes to replicas are just local and are not propagated."}, ch.HandleCommand(newBulkCmd("WAIT", "1", "0")))
+
+	role := ch.HandleCommand(newBulkCmd("ROLE")).(data.Array).Elements
+	assert.Equal([]data.Message{data.BulkString{Data: "slave"}, data.BulkString{Data: "127.0.0.1"}, data.Integer{Value: 1}}, role[:3])
+
+	info := ch.HandleCommand(newBulkCmd("INFO", "replication")).(data.BulkString).Data
+	assert.Contains(info, "role:slave\r\nmaster_host:127.0.0.1\r\nmaster_port:1\r\nmaster_link_status:down\r\n")
+	assert.Contains(info, "slave_read_only:1\r\n")
+
+	// writes are allowed once the replica is no longer read only
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleCommand(newBulkCmd("CONFIG", "SET", "replica-read-only", "no")))
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleCommand(newBulkCmd("SET", "key", "value")))
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleCommand(newBulkCmd("CONFIG", "SET", "replica-read-only", "yes")))
+
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleCommand(newBulkCmd("REPLICAOF", "NO", "ONE")))
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleCommand(newBulkCmd("SET", "key", "value")))
+	assert.Equal(data.BulkString{Data: "master"}, ch.HandleCommand(newBulkCmd("ROLE")).(data.Array).Elements[0])
+}
@@ -0,0 +1,149 @@
+package handler_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleScriptCommands(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	const setScript = "return redis.call('SET', KEYS[1], ARGV[1])"
+	const setSHA = "b0ffe2e0e2b6a2be6aa1be6fd7dbbdb1d7e8a0bb"
+	sha := ch.HandleCommand(newBulkCmd("SCRIPT", "LOAD", setScript)).(data.BulkString).Data
+
+	testCases := []struct {
+		input data.Array
+		want  data.Message
+	}{
+		{newBulkCmd("EVAL", "return 1", "0"), data.Integer{Value: 1}},
+		{newBulkCmd("EVAL", "return {1, 2.7, 'x', false, {ok='fine'}, nil, 3}", "0"), data.Array{Elements: []data.Message{
+			data.Integer{Value: 1}, data.Integer{Value: 2}, data.BulkString{Data: "x"}, data.Null{}, data.SimpleString{Contents: "fine"},
+		}}},
+		{newBulkCmd("EVAL", "return {KEYS[1], KEYS[2], ARGV[1]}", "2", "a", "b", "c"), bulkStrings("a", "b", "c")},
+		{newBulkCmd("EVAL", "return redis.status_reply('DONE')", "0"), data.SimpleString{Contents: "DONE"}},
+		{newBulkCmd("EVAL", "return redis.error_reply('MYERR failed')", "0"), data.Error{ErrMsg: "MYERR failed"}},
+		{newBulkCmd("EVAL", "return redis.sha1hex('')", "0"), data.BulkString{Data: "da39a3ee5e6b4b0d3255bfef95601890afd80709"}},
+		{newBulkCmd("EVALSHA", sha, "1", "key", "value"), handler.OK},
+		{newBulkCmd("EVAL", "return redis.call('GET', KEYS[1])", "1", "key"), data.BulkString{Data: "value"}},
+		{newBulkCmd("EVAL", "return redis.call('GET', 'nexist')", "0"), data.Null{}},
+		{newBulkCmd("EVAL", "return redis.call('GET', 'nexist') == false", "0"), data.Integer{Value: 1}},
+		{newBulkCmd("EVAL", "return redis.call('INCR', KEYS[1])", "1", "key"), data.Error{
+			ErrMsg: "value is not an integer or out of range script: 61636018f4e6b5817b89791bbed242f93fa089e3",
+		}},
+		{newBulkCmd("EVAL", "return redis.pcall('INCR', KEYS[1])", "1", "key"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("EVAL", "return redis.call('EVAL', 'return 1', '0')", "0"), data.Error{
+			ErrMsg: "This Redis command is not allowed from script script: 1886d93748255a1d10c07a09a9ca909ffca6040e",
+		}},
+		{newBulkCmd("EVAL", "x = 1", "0"), data.Error{
+			ErrMsg: "user_script:1: Script attempted to create global variable 'x' script: 34bce5f775de97f557a34088509c8bfe1ea17e52",
+		}},
+		{newBulkCmd("EVAL", "return x", "0"), data.Error{
+			ErrMsg: "user_script:1: Script attempted to access nonexistent global variable 'x' script: 03c387736bb5cc009ff35151572cee04677aa374",
+		}},
+		{newBulkCmd("EVAL", "return loadstring", "0"), data.Error{
+			ErrMsg: "user_script:1: Script attempted to access nonexistent global variable 'loadstring' script: 1f6fb90c861e065f432346548944bdb9bc450e44",
+		}},
+		{newBulkCmd("EVAL", "return (", "0"), data.Error{ErrMsg: "Error compiling script (new function): user_script at EOF: syntax error"}},
+		{newBulkCmd("EVAL", "return 1", "-1"), data.Error{ErrMsg: "Number of keys can't be negative"}},
+		{newBulkCmd("EVAL", "return 1", "2", "a"), data.Error{ErrMsg: "Number of keys can't be greater than number of args"}},
+		{newBulkCmd("EVAL", "return 1", "x"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("EVALSHA", setSHA, "0"), data.Error{ErrMsg: "NOSCRIPT No matching script. Please use EVAL."}},
+		{newBulkCmd("SCRIPT", "EXISTS", sha, setSHA, "e0e1f9fabfc9d4800c877a703b823ac0578ff8db"), data.Array{Elements: []data.Message{
+			data.Integer{Value: 1}, data.Integer{Value: 0}, data.Integer{Value: 1},
+		}}},
+		{newBulkCmd("SCRIPT", "KILL"), data.Error{ErrMsg: "NOTBUSY No scripts in execution right now."}},
+		{newBulkCmd("SCRIPT", "FLUSH", "LAZY"), data.Error{ErrMsg: "SCRIPT FLUSH only support SYNC|ASYNC option"}},
+		{newBulkCmd("SCRIPT", "FLUSH", "ASYNC"), handler.OK},
+		{newBulkCmd("SCRIPT", "EXISTS", sha), data.Array{Elements: []data.Message{data.Integer{Value: 0}}}},
+		{newBulkCmd("EVALSHA", sha, "1", "key", "value"), data.Error{ErrMsg: "NOSCRIPT No matching script. Please use EVAL."}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.want, ch.HandleCommand(tc.input), bulkArgs(tc.input))
+	}
+}
+
+func TestHandleScriptKill(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	require.NoError(cfg.Set("busy-reply-threshold", "50"))
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandlerWithConfig(&storageEngine, cfg)
+
+	done := make(chan data.Message)
+	go func() {
+		done <- ch.HandleCommand(newBulkCmd("EVAL", "while true do end", "0"))
+	}()
+
+	// the other commands wait for the script, then are replied with BUSY once it ran for long enough
+	deadline := time.Now().Add(time.Second)
+	reply := ch.HandleCommand(newBulkCmd("GET", "key"))
+	for reply != handler.SCRIPT_BUSY && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		reply = ch.HandleCommand(newBulkCmd("GET", "key"))
+	}
+	require.Equal(handler.SCRIPT_BUSY, reply)
+	assert.Equal(handler.SCRIPT_BUSY, ch.HandleCommand(newBulkCmd("EVAL", "return 1", "0")))
+
+	assert.Equal(handler.OK, ch.HandleCommand(newBulkCmd("SCRIPT", "KILL")))
+	assert.Equal(data.Error{ErrMsg: "Script killed by user with SCRIPT KILL..."}, <-done)
+	assert.Equal(data.Null{}, ch.HandleCommand(newBulkCmd("GET", "key")))
+	assert.Equal(handler.SCRIPT_NOT_BUSY, ch.HandleCommand(newBulkCmd("SCRIPT", "KILL")))
+}
+
+func TestHandleScriptPermissions(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn, peer := net.Pipe()
+	defer func() { _ = peer.Close() }()
+	client := ch.NewClient(conn)
+
+	// the commands of a script are checked against the permissions of the client that called it
+	assert.Equal(handler.OK, ch.HandleClientCommand(client, newBulkCmd("ACL", "SETUSER", "reader", "on", "nopass", "~*", "+@read", "+eval", "+auth")))
+	assert.Equal(handler.OK, ch.HandleClientCommand(client, newBulkCmd("AUTH", "reader", "any")))
+	assert.Equal(data.Null{}, ch.HandleClientCommand(client, newBulkCmd("EVAL", "return redis.call('GET', 'key')", "0")))
+	assert.Equal(data.Error{
+		ErrMsg: "NOPERM User reader has no permissions to run the 'set' command script: 37c15d700c7198b39a3f0fbe8094ffaa6d477ecb",
+	}, ch.HandleClientCommand(client, newBulkCmd("EVAL", "return redis.call('SET', 'key', 'value')", "0")))
+}
+
+func TestHandleScriptBlockedCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	reply := make(chan data.Message)
+	go func() {
+		reply <- ch.HandleCommand(newBulkCmd("XREAD", "BLOCK", "0", "STREAMS", "stream", "$"))
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// the blocked read is woken up by the first entry, but only reads once the script is done
+	script := "redis.call('XADD', KEYS[1], '1-0', 'n', '1') for i = 1, 1000000 do end redis.call('XADD', KEYS[1], '2-0', 'n', '2')"
+	assert.Equal(data.Null{}, ch.HandleCommand(newBulkCmd("EVAL", script, "1", "stream")))
+	assert.Equal(data.Array{Elements: []data.Message{data.Array{Elements: []data.Message{
+		data.BulkString{Data: "stream"},
+		data.Array{Elements: []data.Message{
+			data.Array{Elements: []data.Message{data.BulkString{Data: "1-0"}, bulkStrings("n", "1")}},
+			data.Array{Elements: []data.Message{data.BulkString{Data: "2-0"}, bulkStrings("n", "2")}},
+		}},
+	}}}}, <-reply)
+}
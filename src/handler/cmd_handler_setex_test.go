@@ -0,0 +1,40 @@
+package handler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleSetExCommand(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("SETEX", "key", "10", "Hello"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("GET", "key"), data.BulkString{Data: "Hello"}},
+		{newBulkCmd("PSETEX", "short", "20", "Hello"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("SETEX", "key", "0", "Hello"), data.Error{ErrMsg: "invalid expire time in 'setex' command"}},
+		{newBulkCmd("PSETEX", "key", "-5", "Hello"), data.Error{ErrMsg: "invalid expire time in 'psetex' command"}},
+		{newBulkCmd("SETEX", "key", "ten", "Hello"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(data.Null{}, ch.HandleCommand(newBulkCmd("GET", "short")))
+	assert.Equal(data.BulkString{Data: "Hello"}, ch.HandleCommand(newBulkCmd("GET", "key")))
+}
@@ -0,0 +1,91 @@
+package handler_test
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func TestHandleSlowLogCommand(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn1, peer1 := net.Pipe()
+	defer func() { _ = peer1.Close() }()
+	client1 := ch.NewClient(conn1)
+	ch.HandleClientCommand(client1, newBulkCmd("CLIENT", "SETNAME", "slowpoke"))
+
+	// with the default threshold, fast commands are not logged
+	ch.HandleClientCommand(client1, newBulkCmd("PING"))
+	assert.Equal(data.Integer{Value: 0}, ch.HandleClientCommand(client1, newBulkCmd("SLOWLOG", "LEN")))
+
+	// log every command from now on
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleClientCommand(client1, newBulkCmd("CONFIG", "SET", "slowlog-log-slower-than", "0")))
+
+	ch.HandleClientCommand(client1, newBulkCmd("SET", "key", strings.Repeat("v", 200)))
+	ch.HandleClientCommand(client1, newBulkCmd("AUTH", "secret"))
+
+	manyArgs := []string{"DEL"}
+	for idx := range 40 {
+		manyArgs = append(manyArgs, fmt.Sprintf("k%d", idx))
+	}
+	ch.HandleClientCommand(client1, newBulkCmd(manyArgs...))
+
+	assert.Equal(data.Integer{Value: 4}, ch.HandleClientCommand(client1, newBulkCmd("SLOWLOG", "LEN")))
+
+	entries := ch.HandleClientCommand(client1, newBulkCmd("SLOWLOG", "GET", "2")).(data.Array).Elements
+	require.Len(entries, 2)
+
+	// newest entry first
+	delEntry := entries[0].(data.Array).Elements
+	assert.Equal(data.Integer{Value: 3}, delEntry[0])
+	assert.GreaterOrEqual(delEntry[2].(data.Integer).Value, int64(0))
+	delArgs := delEntry[3].(data.Array).Elements
+	assert.Len(delArgs, 32)
+	assert.Equal(data.BulkString{Data: "DEL"}, delArgs[0])
+	assert.Equal(data.BulkString{Data: "... (10 more arguments)"}, delArgs[31])
+	assert.Equal(data.BulkString{Data: client1.Addr()}, delEntry[4])
+	assert.Equal(data.BulkString{Data: "slowpoke"}, delEntry[5])
+
+	authEntry := entries[1].(data.Array).Elements
+	assert.Equal(data.Array{Elements: []data.Message{data.BulkString{Data: "AUTH"}, data.BulkString{Data: "(redacted)"}}}, authEntry[3])
+
+	entries = ch.HandleClientCommand(client1, newBulkCmd("SLOWLOG", "GET", "-1")).(data.Array).Elements
+	require.Len(entries, 4)
+	setArgs := entries[2].(data.Array).Elements[3].(data.Array).Elements
+	assert.Equal(data.BulkString{Data: strings.Repeat("v", 128) + "... (72 more bytes)"}, setArgs[2])
+
+	// the ring buffer keeps only the newest entries
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleClientCommand(client1, newBulkCmd("CONFIG", "SET", "slowlog-max-len", "3")))
+	for range 5 {
+		ch.HandleClientCommand(client1, newBulkCmd("PING"))
+	}
+	entries = ch.HandleClientCommand(client1, newBulkCmd("SLOWLOG", "GET")).(data.Array).Elements
+	require.Len(entries, 3)
+	ids := []int64{}
+	for _, entry := range entries {
+		ids = append(ids, entry.(data.Array).Elements[0].(data.Integer).Value)
+	}
+	assert.Equal([]int64{9, 8, 7}, ids)
+
+	assert.Equal(data.Error{ErrMsg: "count should be greater than or equal to -1"}, ch.HandleClientCommand(client1, newBulkCmd("SLOWLOG", "GET", "-2")))
+	assert.Equal(data.Error{ErrMsg: "unsupported subcommand NEXIST for SLOWLOG"}, ch.HandleClientCommand(client1, newBulkCmd("SLOWLOG", "NEXIST")))
+
+	assert.Equal(data.SimpleString{Contents: "OK"}, ch.HandleClientCommand(client1, newBulkCmd("SLOWLOG", "RESET")))
+	assert.Equal(data.Integer{Value: 0}, ch.HandleClientCommand(client1, newBulkCmd("SLOWLOG", "LEN")))
+
+	// a negative threshold disables the log
+	ch.HandleClientCommand(client1, newBulkCmd("CONFIG", "SET", "slowlog-log-slower-than", "-1"))
+	ch.HandleClientCommand(client1, newBulkCmd("PING"))
+	assert.Equal(data.Integer{Value: 0}, ch.HandleClientCommand(client1, newBulkCmd("SLOWLOG", "LEN")))
+}
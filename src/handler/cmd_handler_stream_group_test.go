@@ -0,0 +1,253 @@
+package handler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// streamRead builds the reply for the entries read from a single stream.
+func streamRead(key string, entries data.Array) data.Array {
+	return data.Array{Elements: []data.Message{
+		data.Array{Elements: []data.Message{data.BulkString{Data: key}, entries}},
+	}}
+}
+
+func TestHandleStreamGroupCommands(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("XADD", "s", "1-0", "a", "1"))
+	ch.HandleCommand(newBulkCmd("XADD", "s", "2-0", "b", "2"))
+	ch.HandleCommand(newBulkCmd("XADD", "s", "3-0", "c", "3"))
+	ch.HandleCommand(newBulkCmd("SET", "str", "value"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("XGROUP", "CREATE", "s", "g", "0"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("XGROUP", "CREATE", "s", "g", "$"), data.Error{ErrMsg: "BUSYGROUP Consumer Group name already exists"}},
+		{newBulkCmd("XGROUP", "CREATE", "nexist", "g", "$"), data.Error{ErrMsg: "The XGROUP subcommand requires the key to exist. Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically."}},
+		{newBulkCmd("XGROUP", "CREATE", "empty", "g", "$", "MKSTREAM"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("XLEN", "empty"), data.Integer{Value: 0}},
+		{newBulkCmd("XGROUP", "CREATE", "str", "g", "$"), data.Error{ErrMsg: "WRONGTYPE Operation against a key holding the wrong kind of value"}},
+		{newBulkCmd("XGROUP", "CREATE", "s", "other", "x"), data.Error{ErrMsg: "Invalid stream ID specified as stream command argument"}},
+		{newBulkCmd("XGROUP", "CREATE", "s", "other", "$", "ENTRIESREAD", "-2"), data.Error{ErrMsg: "value for ENTRIESREAD must be positive or -1"}},
+		{newBulkCmd("XGROUP", "SETID", "s", "other", "$", "MKSTREAM"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("XGROUP", "SETID", "s", "nexist", "0"), data.Error{ErrMsg: "NOGROUP No such consumer group 'nexist' for key name 's'"}},
+		{newBulkCmd("XGROUP", "BOGUS", "s"), data.Error{ErrMsg: "unsupported subcommand BOGUS for XGROUP"}},
+		{newBulkCmd("XGROUP", "DESTROY", "s"), data.Error{ErrMsg: "wrong number of arguments for 'xgroup|destroy' command"}},
+
+		{newBulkCmd("XREADGROUP", "GROUP", "g", "alice", "COUNT", "2", "STREAMS", "s", ">"), streamRead("s", streamEntries(
+			streamEntry("1-0", "a", "1"), streamEntry("2-0", "b", "2"),
+		))},
+		{newBulkCmd("XREADGROUP", "GROUP", "g", "bob", "STREAMS", "s", ">"), streamRead("s", streamEntries(streamEntry("3-0", "c", "3")))},
+		{newBulkCmd("XREADGROUP", "GROUP", "g", "bob", "STREAMS", "s", ">"), data.Null{}},
+		{newBulkCmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", "0"), streamRead("s", streamEntries(
+			streamEntry("1-0", "a", "1"), streamEntry("2-0", "b", "2"),
+		))},
+		{newBulkCmd("XREADGROUP", "GROUP", "g", "carol", "STREAMS", "s", "0"), streamRead("s", streamEntries())},
+		{newBulkCmd("XPENDING", "s", "g"), data.Array{Elements: []data.Message{
+			data.Integer{Value: 3},
+			data.BulkString{Data: "1-0"},
+			data.BulkString{Data: "3-0"},
+			data.Array{Elements: []data.Message{
+				bulkStrings("alice", "2"),
+				bulkStrings("bob", "1"),
+			}},
+		}}},
+		{newBulkCmd("XPENDING", "s", "g", "IDLE", "3600000", "-", "+", "10"), data.Array{Elements: []data.Message{}}},
+		{newBulkCmd("XPENDING", "s", "g", "-", "+", "10", "nexist"), data.Array{Elements: []data.Message{}}},
+		{newBulkCmd("XPENDING", "s", "g", "-", "+"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("XPENDING", "s", "nexist"), data.Error{ErrMsg: "NOGROUP No such key 's' or consumer group 'nexist'"}},
+		{newBulkCmd("XACK", "s", "g", "1-0", "9-0"), data.Integer{Value: 1}},
+		{newBulkCmd("XACK", "s", "nexist", "1-0"), data.Integer{Value: 0}},
+		{newBulkCmd("XACK", "s", "g", "x"), data.Error{ErrMsg: "Invalid stream ID specified as stream command argument"}},
+
+		// deleted entries stay pending until they are claimed or acknowledged
+		{newBulkCmd("XDEL", "s", "2-0"), data.Integer{Value: 1}},
+		{newBulkCmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", "0"), streamRead("s", data.Array{Elements: []data.Message{
+			data.Array{Elements: []data.Message{data.BulkString{Data: "2-0"}, data.Null{}}},
+		}})},
+		{newBulkCmd("XCLAIM", "s", "g", "bob", "0", "2-0"), streamEntries()},
+		{newBulkCmd("XPENDING", "s", "g"), data.Array{Elements: []data.Message{
+			data.Integer{Value: 1},
+			data.BulkString{Data: "3-0"},
+			data.BulkString{Data: "3-0"},
+			data.Array{Elements: []data.Message{bulkStrings("bob", "1")}},
+		}}},
+		{newBulkCmd("XCLAIM", "s", "g", "alice", "3600000", "3-0"), streamEntries()},
+		{newBulkCmd("XCLAIM", "s", "g", "alice", "0", "3-0", "JUSTID"), bulkStrings("3-0")},
+		{newBulkCmd("XCLAIM", "s", "g", "alice", "0", "3-0", "BOGUS"), data.Error{ErrMsg: "Unrecognized XCLAIM option 'BOGUS'"}},
+		{newBulkCmd("XCLAIM", "s", "g", "alice", "x", "3-0"), data.Error{ErrMsg: "Invalid min-idle-time argument for XCLAIM"}},
+		{newBulkCmd("XCLAIM", "s", "nexist", "alice", "0", "3-0"), data.Error{ErrMsg: "NOGROUP No such key 's' or consumer group 'nexist'"}},
+		{newBulkCmd("XAUTOCLAIM", "s", "g", "bob", "0", "0"), data.Array{Elements: []data.Message{
+			data.BulkString{Data: "0-0"},
+			streamEntries(streamEntry("3-0", "c", "3")),
+			data.Array{Elements: []data.Message{}},
+		}}},
+		{newBulkCmd("XAUTOCLAIM", "s", "g", "bob", "0", "0", "COUNT", "0"), data.Error{ErrMsg: "COUNT must be > 0"}},
+		{newBulkCmd("XCLAIM", "s", "g", "alice", "0", "1-0", "FORCE", "RETRYCOUNT", "5", "JUSTID"), bulkStrings("1-0")},
+		{newBulkCmd("XGROUP", "CREATECONSUMER", "s", "g", "dave"), data.Integer{Value: 1}},
+		{newBulkCmd("XGROUP", "CREATECONSUMER", "s", "g", "dave"), data.Integer{Value: 0}},
+		{newBulkCmd("XGROUP", "DELCONSUMER", "s", "g", "bob"), data.Integer{Value: 1}},
+		{newBulkCmd("XGROUP", "DELCONSUMER", "s", "g", "bob"), data.Integer{Value: 0}},
+		{newBulkCmd("XPENDING", "s", "g"), data.Array{Elements: []data.Message{
+			data.Integer{Value: 1},
+			data.BulkString{Data: "1-0"},
+			data.BulkString{Data: "1-0"},
+			data.Array{Elements: []data.Message{bulkStrings("alice", "1")}},
+		}}},
+
+		// the entries read by the group are unknown once it is moved back before a deleted entry
+		{newBulkCmd("XGROUP", "SETID", "s", "g", "0"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("XINFO", "GROUPS", "s"), data.Array{Elements: []data.Message{data.Array{Elements: []data.Message{
+			data.BulkString{Data: "name"},
+			data.BulkString{Data: "g"},
+			data.BulkString{Data: "consumers"},
+			data.Integer{Value: 3},
+			data.BulkString{Data: "pending"},
+			data.Integer{Value: 1},
+			data.BulkString{Data: "last-delivered-id"},
+			data.BulkString{Data: "0-0"},
+			data.BulkString{Data: "entries-read"},
+			data.Null{},
+			data.BulkString{Data: "lag"},
+			data.Null{},
+		}}}}},
+		{newBulkCmd("XGROUP", "SETID", "s", "g", "$", "ENTRIESREAD", "3"), data.SimpleString{Contents: "OK"}},
+		{newBulkCmd("XADD", "s", "4-0", "d", "4"), data.BulkString{Data: "4-0"}},
+		{newBulkCmd("XINFO", "GROUPS", "s"), data.Array{Elements: []data.Message{data.Array{Elements: []data.Message{
+			data.BulkString{Data: "name"},
+			data.BulkString{Data: "g"},
+			data.BulkString{Data: "consumers"},
+			data.Integer{Value: 3},
+			data.BulkString{Data: "pending"},
+			data.Integer{Value: 1},
+			data.BulkString{Data: "last-delivered-id"},
+			data.BulkString{Data: "3-0"},
+			data.BulkString{Data: "entries-read"},
+			data.Integer{Value: 3},
+			data.BulkString{Data: "lag"},
+			data.Integer{Value: 1},
+		}}}}},
+		{newBulkCmd("XINFO", "STREAM", "s"), data.Array{Elements: []data.Message{
+			data.BulkString{Data: "length"},
+			data.Integer{Value: 3},
+			data.BulkString{Data: "radix-tree-keys"},
+			data.Integer{Value: 1},
+			data.BulkString{Data: "radix-tree-nodes"},
+			data.Integer{Value: 1},
+			data.BulkString{Data: "last-generated-id"},
+			data.BulkString{Data: "4-0"},
+			data.BulkString{Data: "max-deleted-entry-id"},
+			data.BulkString{Data: "2-0"},
+			data.BulkString{Data: "entries-added"},
+			data.Integer{Value: 4},
+			data.BulkString{Data: "recorded-first-entry-id"},
+			data.BulkString{Data: "1-0"},
+			data.BulkString{Data: "groups"},
+			data.Integer{Value: 1},
+			data.BulkString{Data: "first-entry"},
+			streamEntry("1-0", "a", "1"),
+			data.BulkString{Data: "last-entry"},
+			streamEntry("4-0", "d", "4"),
+		}}},
+		{newBulkCmd("XINFO", "STREAM", "s", "FULL", "COUNT", "x"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("XINFO", "STREAM", "nexist"), data.Error{ErrMsg: "no such key"}},
+		{newBulkCmd("XINFO", "CONSUMERS", "s", "nexist"), data.Error{ErrMsg: "NOGROUP No such consumer group 'nexist' for key name 's'"}},
+
+		{newBulkCmd("XGROUP", "DESTROY", "s", "g"), data.Integer{Value: 1}},
+		{newBulkCmd("XGROUP", "DESTROY", "s", "g"), data.Integer{Value: 0}},
+		{newBulkCmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", ">"), data.Error{ErrMsg: "NOGROUP No such key 's' or consumer group 'g' in XREADGROUP with GROUP option"}},
+		{newBulkCmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "nexist", ">"), data.Error{ErrMsg: "NOGROUP No such key 'nexist' or consumer group 'g' in XREADGROUP with GROUP option"}},
+		{newBulkCmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", "$"), data.Error{ErrMsg: "The $ ID is meaningless in the context of XREADGROUP: you want to read the history of this consumer by specifying a proper ID, or use the > ID to get new messages. The $ ID would just return an empty result set."}},
+		{newBulkCmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", "t", ">"), data.Error{ErrMsg: "Unbalanced 'xreadgroup' list of streams: for each stream key an ID or '>' must be specified."}},
+		{newBulkCmd("XREADGROUP", "COUNT", "1", "BLOCK", "1", "STREAMS", "s", ">"), data.Error{ErrMsg: "Missing GROUP option for XREADGROUP"}},
+		{newBulkCmd("XREAD", "GROUP", "g", "alice", "STREAMS", "s", "0"), data.Error{ErrMsg: "The GROUP option is only supported by XREADGROUP. You called XREAD instead."}},
+		{newBulkCmd("XREAD", "STREAMS", "s", ">"), data.Error{ErrMsg: "The > ID can be specified only when calling XREADGROUP using the GROUP <group> <consumer> option."}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
+
+func TestHandleStreamGroupDeliveries(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ch.HandleCommand(newBulkCmd("XGROUP", "CREATE", "s", "g", "$", "MKSTREAM"))
+	ch.HandleCommand(newBulkCmd("XADD", "s", "1-0", "a", "1"))
+	ch.HandleCommand(newBulkCmd("XADD", "s", "2-0", "b", "2"))
+	ch.HandleCommand(newBulkCmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", ">"))
+	ch.HandleCommand(newBulkCmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", "0"))
+	ch.HandleCommand(newBulkCmd("XCLAIM", "s", "g", "bob", "0", "2-0", "IDLE", "5000"))
+
+	// reading the history and claiming count as deliveries
+	pending := ch.HandleCommand(newBulkCmd("XPENDING", "s", "g", "-", "+", "10")).(data.Array)
+	require.Len(pending.Elements, 2)
+	first := pending.Elements[0].(data.Array).Elements
+	assert.Equal(data.BulkString{Data: "1-0"}, first[0])
+	assert.Equal(data.BulkString{Data: "alice"}, first[1])
+	assert.Equal(data.Integer{Value: 2}, first[3])
+	second := pending.Elements[1].(data.Array).Elements
+	assert.Equal(data.BulkString{Data: "bob"}, second[1])
+	assert.GreaterOrEqual(second[2].(data.Integer).Value, int64(5000))
+	assert.Equal(data.Integer{Value: 3}, second[3])
+
+	idle := ch.HandleCommand(newBulkCmd("XPENDING", "s", "g", "IDLE", "5000", "-", "+", "10")).(data.Array)
+	require.Len(idle.Elements, 1)
+	assert.Equal(data.BulkString{Data: "2-0"}, idle.Elements[0].(data.Array).Elements[0])
+
+	consumers := ch.HandleCommand(newBulkCmd("XINFO", "CONSUMERS", "s", "g")).(data.Array)
+	require.Len(consumers.Elements, 2)
+	alice := consumers.Elements[0].(data.Array).Elements
+	assert.Equal([]data.Message{data.BulkString{Data: "name"}, data.BulkString{Data: "alice"}, data.BulkString{Data: "pending"}, data.Integer{Value: 1}}, alice[:4])
+
+	// NOACK delivers entries without making them pending
+	ch.HandleCommand(newBulkCmd("XADD", "s", "3-0", "c", "3"))
+	assert.Equal(streamRead("s", streamEntries(streamEntry("3-0", "c", "3"))),
+		ch.HandleCommand(newBulkCmd("XREADGROUP", "GROUP", "g", "carol", "NOACK", "STREAMS", "s", ">")))
+	assert.Equal(data.Integer{Value: 2}, ch.HandleCommand(newBulkCmd("XPENDING", "s", "g")).(data.Array).Elements[0])
+
+	full := ch.HandleCommand(newBulkCmd("XINFO", "STREAM", "s", "FULL", "COUNT", "1")).(data.Array).Elements
+	assert.Equal(data.BulkString{Data: "entries"}, full[14])
+	assert.Equal(streamEntries(streamEntry("1-0", "a", "1")), full[15])
+	group := full[17].(data.Array).Elements[0].(data.Array).Elements
+	assert.Equal(data.BulkString{Data: "pel-count"}, group[8])
+	assert.Equal(data.Integer{Value: 2}, group[9])
+}
+
+func TestHandleXReadGroupBlocking(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	assert := assert.New(t)
+	ch.HandleCommand(newBulkCmd("XGROUP", "CREATE", "s", "g", "$", "MKSTREAM"))
+
+	replies := make(chan data.Message)
+	go func() {
+		replies <- ch.HandleCommand(newBulkCmd("XREADGROUP", "GROUP", "g", "alice", "BLOCK", "0", "STREAMS", "s", ">"))
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	ch.HandleCommand(newBulkCmd("XADD", "s", "1-0", "a", "1"))
+	select {
+	case reply := <-replies:
+		assert.Equal(streamRead("s", streamEntries(streamEntry("1-0", "a", "1"))), reply)
+	case <-time.After(time.Second):
+		assert.Fail("XREADGROUP did not return after an entry was added")
+	}
+	assert.Equal(data.Integer{Value: 1}, ch.HandleCommand(newBulkCmd("XPENDING", "s", "g")).(data.Array).Elements[0])
+}
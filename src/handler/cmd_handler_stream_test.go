@@ -0,0 +1,179 @@
+package handler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// streamEntry builds the reply for a single stream entry.
+func streamEntry(id string, fields ...string) data.Array {
+	return data.Array{Elements: []data.Message{data.BulkString{Data: id}, bulkStrings(fields...)}}
+}
+
+func streamEntries(entries ...data.Message) data.Array {
+	return data.Array{Elements: append([]data.Message{}, entries...)}
+}
+
+func TestHandleStreamCommands(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("SET", "str", "value"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("XADD", "s", "1-1", "a", "1"), data.BulkString{Data: "1-1"}},
+		{newBulkCmd("XADD", "s", "1-*", "b", "2"), data.BulkString{Data: "1-2"}},
+		{newBulkCmd("XADD", "s", "2", "c", "3", "d", "4"), data.BulkString{Data: "2-0"}},
+		{newBulkCmd("XADD", "s", "2-0", "e", "5"), data.Error{ErrMsg: "The ID specified in XADD is equal or smaller than the target stream top item"}},
+		{newBulkCmd("XADD", "s", "1-*", "e", "5"), data.Error{ErrMsg: "The ID specified in XADD is equal or smaller than the target stream top item"}},
+		{newBulkCmd("XADD", "new", "0-0", "a", "1"), data.Error{ErrMsg: "The ID specified in XADD must be greater than 0-0"}},
+		{newBulkCmd("XADD", "new", "0-*", "a", "1"), data.BulkString{Data: "0-1"}},
+		{newBulkCmd("XADD", "s", "bogus", "e", "5"), data.Error{ErrMsg: "Invalid stream ID specified as stream command argument"}},
+		{newBulkCmd("XADD", "s", "3-0", "e"), data.Error{ErrMsg: "wrong number of arguments for 'xadd' command"}},
+		{newBulkCmd("XADD", "nomk", "NOMKSTREAM", "*", "a", "1"), data.Null{}},
+		{newBulkCmd("EXISTS", "nomk"), data.Integer{Value: 0}},
+		{newBulkCmd("XADD", "str", "*", "a", "1"), data.Error{ErrMsg: "WRONGTYPE Operation against a key holding the wrong kind of value"}},
+		{newBulkCmd("GET", "s"), data.Error{ErrMsg: "WRONGTYPE Operation against a key holding the wrong kind of value"}},
+		{newBulkCmd("XLEN", "s"), data.Integer{Value: 3}},
+		{newBulkCmd("XLEN", "nexist"), data.Integer{Value: 0}},
+		{newBulkCmd("XLEN", "str"), data.Error{ErrMsg: "WRONGTYPE Operation against a key holding the wrong kind of value"}},
+		{newBulkCmd("XRANGE", "s", "-", "+"), streamEntries(
+			streamEntry("1-1", "a", "1"), streamEntry("1-2", "b", "2"), streamEntry("2-0", "c", "3", "d", "4"),
+		)},
+		{newBulkCmd("XRANGE", "s", "1", "1"), streamEntries(streamEntry("1-1", "a", "1"), streamEntry("1-2", "b", "2"))},
+		{newBulkCmd("XRANGE", "s", "(1-1", "+", "COUNT", "1"), streamEntries(streamEntry("1-2", "b", "2"))},
+		{newBulkCmd("XRANGE", "s", "-", "(2-0"), streamEntries(streamEntry("1-1", "a", "1"), streamEntry("1-2", "b", "2"))},
+		{newBulkCmd("XRANGE", "s", "-", "+", "COUNT", "0"), streamEntries()},
+		{newBulkCmd("XRANGE", "s", "3", "2"), streamEntries()},
+		{newBulkCmd("XRANGE", "nexist", "-", "+"), streamEntries()},
+		{newBulkCmd("XRANGE", "s", "(18446744073709551615-18446744073709551615", "+"), data.Error{ErrMsg: "invalid start ID for the interval"}},
+		{newBulkCmd("XRANGE", "s", "-", "(0-0"), data.Error{ErrMsg: "invalid end ID for the interval"}},
+		{newBulkCmd("XRANGE", "s", "x", "+"), data.Error{ErrMsg: "Invalid stream ID specified as stream command argument"}},
+		{newBulkCmd("XRANGE", "s", "-", "+", "LIMIT", "1"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("XREVRANGE", "s", "+", "-", "COUNT", "2"), streamEntries(
+			streamEntry("2-0", "c", "3", "d", "4"), streamEntry("1-2", "b", "2"),
+		)},
+		{newBulkCmd("XDEL", "s", "1-2", "5-0"), data.Integer{Value: 1}},
+		{newBulkCmd("XDEL", "s", "x"), data.Error{ErrMsg: "Invalid stream ID specified as stream command argument"}},
+		{newBulkCmd("XLEN", "s"), data.Integer{Value: 2}},
+		// the IDs of deleted entries are never reused
+		{newBulkCmd("XADD", "s", "2-0", "e", "5"), data.Error{ErrMsg: "The ID specified in XADD is equal or smaller than the target stream top item"}},
+		{newBulkCmd("XADD", "s", "MAXLEN", "2", "3-0", "e", "5"), data.BulkString{Data: "3-0"}},
+		{newBulkCmd("XRANGE", "s", "-", "+"), streamEntries(streamEntry("2-0", "c", "3", "d", "4"), streamEntry("3-0", "e", "5"))},
+		{newBulkCmd("XTRIM", "s", "MINID", "=", "3"), data.Integer{Value: 1}},
+		{newBulkCmd("XTRIM", "s", "MAXLEN", "0"), data.Integer{Value: 1}},
+		{newBulkCmd("EXISTS", "s"), data.Integer{Value: 1}},
+		{newBulkCmd("XTRIM", "s", "MAXLEN", "-1"), data.Error{ErrMsg: "The MAXLEN argument must be >= 0."}},
+		{newBulkCmd("XTRIM", "s", "MAXLEN", "1", "LIMIT", "10"), data.Error{ErrMsg: "syntax error, LIMIT cannot be used without the special ~ option"}},
+		{newBulkCmd("XTRIM", "s", "MAXLEN", "~", "1", "LIMIT", "-1"), data.Error{ErrMsg: "The LIMIT argument must be >= 0."}},
+		{newBulkCmd("XTRIM", "s", "MAXLEN", "1", "MINID", "1"), data.Error{ErrMsg: "syntax error, MAXLEN and MINID options at the same time are not compatible"}},
+		{newBulkCmd("XTRIM", "s", "LENGTH", "1"), data.Error{ErrMsg: "syntax error"}},
+		{newBulkCmd("XTRIM", "nexist", "MAXLEN", "0"), data.Integer{Value: 0}},
+		{newBulkCmd("XDEL", "s"), data.Error{ErrMsg: "wrong number of arguments for 'xdel' command"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
+
+func TestHandleXAddApproximateTrim(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	assert := assert.New(t)
+
+	for range 250 {
+		ch.HandleCommand(newBulkCmd("XADD", "s", "*", "a", "1"))
+	}
+
+	// only whole nodes of 100 entries are removed
+	assert.Equal(data.Integer{Value: 100}, ch.HandleCommand(newBulkCmd("XTRIM", "s", "MAXLEN", "~", "120")))
+	assert.Equal(data.Integer{Value: 150}, ch.HandleCommand(newBulkCmd("XLEN", "s")))
+	assert.Equal(data.Integer{Value: 0}, ch.HandleCommand(newBulkCmd("XTRIM", "s", "MAXLEN", "~", "50", "LIMIT", "99")))
+	assert.Equal(data.Integer{Value: 100}, ch.HandleCommand(newBulkCmd("XTRIM", "s", "MAXLEN", "~", "0", "LIMIT", "0")))
+	assert.Equal(data.Integer{Value: 50}, ch.HandleCommand(newBulkCmd("XLEN", "s")))
+}
+
+func TestHandleXRead(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	ch.HandleCommand(newBulkCmd("SET", "str", "value"))
+	ch.HandleCommand(newBulkCmd("XADD", "s1", "1-0", "a", "1"))
+	ch.HandleCommand(newBulkCmd("XADD", "s1", "2-0", "b", "2"))
+	ch.HandleCommand(newBulkCmd("XADD", "s2", "3-0", "c", "3"))
+
+	testCases := []struct {
+		input data.Message
+		want  data.Message
+	}{
+		{newBulkCmd("XREAD", "STREAMS", "s1", "s2", "0", "0"), data.Array{Elements: []data.Message{
+			data.Array{Elements: []data.Message{
+				data.BulkString{Data: "s1"},
+				streamEntries(streamEntry("1-0", "a", "1"), streamEntry("2-0", "b", "2")),
+			}},
+			data.Array{Elements: []data.Message{data.BulkString{Data: "s2"}, streamEntries(streamEntry("3-0", "c", "3"))}},
+		}}},
+		{newBulkCmd("XREAD", "COUNT", "1", "STREAMS", "s1", "s2", "1", "3"), data.Array{Elements: []data.Message{
+			data.Array{Elements: []data.Message{data.BulkString{Data: "s1"}, streamEntries(streamEntry("2-0", "b", "2"))}},
+		}}},
+		{newBulkCmd("XREAD", "STREAMS", "s1", "nexist", "$", "$"), data.Null{}},
+		{newBulkCmd("XREAD", "BLOCK", "10", "STREAMS", "s1", "$"), data.Null{}},
+		{newBulkCmd("XREAD", "STREAMS", "str", "0"), data.Error{ErrMsg: "WRONGTYPE Operation against a key holding the wrong kind of value"}},
+		{newBulkCmd("XREAD", "STREAMS", "s1", "s2", "0"), data.Error{ErrMsg: "Unbalanced 'xread' list of streams: for each stream key an ID or '$' must be specified."}},
+		{newBulkCmd("XREAD", "STREAMS", "s1", "x"), data.Error{ErrMsg: "Invalid stream ID specified as stream command argument"}},
+		{newBulkCmd("XREAD", "BLOCK", "-1", "STREAMS", "s1", "0"), data.Error{ErrMsg: "timeout is negative"}},
+		{newBulkCmd("XREAD", "BLOCK", "x", "STREAMS", "s1", "0"), data.Error{ErrMsg: "timeout is not an integer or out of range"}},
+		{newBulkCmd("XREAD", "COUNT", "x", "STREAMS", "s1", "0"), data.Error{ErrMsg: "value is not an integer or out of range"}},
+		{newBulkCmd("XREAD", "WAIT", "1", "s1", "0"), data.Error{ErrMsg: "syntax error"}},
+	}
+
+	assert := assert.New(t)
+	for _, tc := range testCases {
+		t.Run(tc.input.ToDataString(), func(t *testing.T) {
+			result := ch.HandleCommand(tc.input)
+			assert.Equal(tc.want, result)
+		})
+	}
+}
+
+func TestHandleXReadBlocking(t *testing.T) {
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+	assert := assert.New(t)
+	ch.HandleCommand(newBulkCmd("XADD", "s1", "1-0", "a", "1"))
+
+	replies := make(chan data.Message)
+	go func() {
+		replies <- ch.HandleCommand(newBulkCmd("XREAD", "BLOCK", "0", "STREAMS", "s1", "s2", "$", "$"))
+	}()
+
+	// entries added to other streams do not wake up the reader
+	time.Sleep(20 * time.Millisecond)
+	ch.HandleCommand(newBulkCmd("XADD", "other", "*", "x", "0"))
+	select {
+	case reply := <-replies:
+		assert.Fail("XREAD returned before an entry was added", reply)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	ch.HandleCommand(newBulkCmd("XADD", "s2", "5-0", "b", "2"))
+	select {
+	case reply := <-replies:
+		assert.Equal(data.Array{Elements: []data.Message{
+			data.Array{Elements: []data.Message{data.BulkString{Data: "s2"}, streamEntries(streamEntry("5-0", "b", "2"))}},
+		}}, reply)
+	case <-time.After(time.Second):
+		assert.Fail("XREAD did not return after an entry was added")
+	}
+}
@@ -37,6 +37,35 @@ func TestHandleCommand(t *testing.T) {
 			},
 			data.Error{ErrMsg: "unsupported command UNSUPPORTED"},
 		},
+		{
+			data.Array{
+				Elements: []data.Message{
+					data.BulkString{Data: "ping"},
+				},
+			},
+			data.SimpleString{Contents: "PONG"},
+		},
+		{
+			data.Array{
+				Elements: []data.Message{
+					data.BulkString{Data: "sEt"},
+					data.BulkString{Data: "key"},
+					data.BulkString{Data: "value"},
+					data.BulkString{Data: "px"},
+					data.BulkString{Data: "1000"},
+				},
+			},
+			data.SimpleString{Contents: "OK"},
+		},
+		{
+			data.Array{
+				Elements: []data.Message{
+					data.BulkString{Data: "Get"},
+					data.BulkString{Data: "key"},
+				},
+			},
+			data.BulkString{Data: "value"},
+		},
 	}
 
 	assert := assert.New(t)
@@ -0,0 +1,236 @@
+package handler_test
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func invalidation(keys ...string) data.Array {
+	return data.Array{Elements: []data.Message{
+		data.BulkString{Data: "message"},
+		data.BulkString{Data: handler.TRACKING_CHANNEL},
+		bulkStrings(keys...),
+	}}
+}
+
+func trackingInfo(flags data.Array, redirect int64, prefixes data.Array) data.Array {
+	return data.Array{Elements: []data.Message{
+		data.BulkString{Data: "flags"},
+		flags,
+		data.BulkString{Data: "redirect"},
+		data.Integer{Value: redirect},
+		data.BulkString{Data: "prefixes"},
+		prefixes,
+	}}
+}
+
+func TestHandleClientTrackingOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn, peer := net.Pipe()
+	defer func() { _ = peer.Close() }()
+	client := ch.NewClient(conn)
+	id := strconv.FormatInt(client.ID(), 10)
+
+	testCases := []struct {
+		input data.Array
+		want  data.Message
+	}{
+		{newBulkCmd("CLIENT", "GETREDIR"), data.Integer{Value: -1}},
+		{newBulkCmd("CLIENT", "TRACKINGINFO"), trackingInfo(bulkStrings("off"), -1, bulkStrings())},
+		{newBulkCmd("CLIENT", "CACHING", "YES"), data.Error{
+			ErrMsg: "CLIENT CACHING can be called only when the client is in tracking mode with OPTIN or OPTOUT mode enabled",
+		}},
+		{newBulkCmd("CLIENT", "TRACKING", "MAYBE"), handler.SYNTAX_ERROR},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "FOO"), handler.SYNTAX_ERROR},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "REDIRECT", "abc"), handler.NOT_AN_INTEGER},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "REDIRECT", "999"), data.Error{ErrMsg: "The client ID you want redirect to does not exist"}},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "PREFIX", "user:"), data.Error{ErrMsg: "PREFIX option requires BCAST mode to be enabled"}},
+		{newBulkCmd("CLIENT", "TRACKING", "ON"), handler.TRACKING_REDIRECT_REQUIRED},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "BCAST"), handler.TRACKING_REDIRECT_REQUIRED},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "OPTIN", "OPTOUT"), data.Error{ErrMsg: "You can't use both OPTIN and OPTOUT"}},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "BCAST", "OPTIN"), data.Error{ErrMsg: "OPTIN and OPTOUT are not compatible with BCAST"}},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "user", "PREFIX", "user:1"), data.Error{
+			ErrMsg: "Prefix 'user' overlaps with another provided prefix 'user:1'. Prefixes for a single client must not overlap.",
+		}},
+
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "OPTIN", "REDIRECT", id), handler.OK},
+		{newBulkCmd("CLIENT", "GETREDIR"), data.Integer{Value: client.ID()}},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "OPTOUT", "REDIRECT", id), data.Error{
+			ErrMsg: "You can't switch OPTIN/OPTOUT mode before disabling tracking for this client, and then re-enabling it with a different mode.",
+		}},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "BCAST", "REDIRECT", id), data.Error{
+			ErrMsg: "You can't switch BCAST mode on/off before disabling tracking for this client, and then re-enabling it with a different mode.",
+		}},
+		{newBulkCmd("CLIENT", "CACHING", "NO"), data.Error{ErrMsg: "CLIENT CACHING NO is only valid when tracking is enabled in OPTOUT mode."}},
+		{newBulkCmd("CLIENT", "CACHING", "YES"), handler.OK},
+		{newBulkCmd("CLIENT", "TRACKINGINFO"), trackingInfo(bulkStrings("on", "optin", "caching-yes"), client.ID(), bulkStrings())},
+		// CLIENT CACHING only applies to the next command
+		{newBulkCmd("GET", "key"), data.Null{}},
+		{newBulkCmd("CLIENT", "TRACKINGINFO"), trackingInfo(bulkStrings("on", "optin"), client.ID(), bulkStrings())},
+
+		{newBulkCmd("CLIENT", "TRACKING", "OFF"), handler.OK},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "BCAST", "NOLOOP", "PREFIX", "user:", "REDIRECT", id), handler.OK},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "user:1", "REDIRECT", id), data.Error{
+			ErrMsg: "Prefix 'user:1' overlaps with an existing prefix 'user:'. Prefixes for a single client must not overlap.",
+		}},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "post:", "PREFIX", "user:", "REDIRECT", id), handler.OK},
+		{newBulkCmd("CLIENT", "TRACKINGINFO"), trackingInfo(bulkStrings("on", "bcast"), client.ID(), bulkStrings("user:", "post:"))},
+		{newBulkCmd("CLIENT", "TRACKING", "OFF"), handler.OK},
+		{newBulkCmd("CLIENT", "TRACKING", "ON", "BCAST", "REDIRECT", id), handler.OK},
+		{newBulkCmd("CLIENT", "TRACKINGINFO"), trackingInfo(bulkStrings("on", "bcast"), client.ID(), bulkStrings(""))},
+	}
+	for _, tc := range testCases {
+		assert.Equal(tc.want, ch.HandleClientCommand(client, tc.input), bulkArgs(tc.input))
+	}
+
+	assert.Equal(handler.NO_CLIENT_CONN, ch.HandleCommand(newBulkCmd("CLIENT", "TRACKING", "ON")))
+}
+
+func TestHandleClientTrackingInvalidation(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn, peer := net.Pipe()
+	defer func() { _ = peer.Close() }()
+	subscriber := ch.NewClient(conn)
+	ch.HandleClientCommand(subscriber, newBulkCmd("SUBSCRIBE", handler.TRACKING_CHANNEL))
+	redirect := strconv.FormatInt(subscriber.ID(), 10)
+
+	newTracker := func(options ...string) *handler.Client {
+		conn, peer := net.Pipe()
+		t.Cleanup(func() { _ = peer.Close() })
+		tracker := ch.NewClient(conn)
+		args := append([]string{"CLIENT", "TRACKING", "ON", "REDIRECT", redirect}, options...)
+		assert.Equal(handler.OK, ch.HandleClientCommand(tracker, newBulkCmd(args...)))
+		return tracker
+	}
+	tracker := newTracker()
+	broadcast := newTracker("BCAST", "PREFIX", "user:")
+	optIn := newTracker("OPTIN", "NOLOOP")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ch.HandleCommand(newBulkCmd("SET", "temp", "value", "PX", "50"))
+		ch.HandleClientCommand(tracker, newBulkCmd("MGET", "key", "other", "temp"))
+		ch.HandleCommand(newBulkCmd("SET", "key", "value"))
+		// the keys are only invalidated once until they are read again
+		ch.HandleCommand(newBulkCmd("SET", "key", "value"))
+		ch.HandleCommand(newBulkCmd("DEL", "other"))
+		ch.HandleCommand(newBulkCmd("DEL", "user:1"))
+		time.Sleep(60 * time.Millisecond)
+		ch.HandleCommand(newBulkCmd("GET", "temp"))
+
+		ch.HandleClientCommand(optIn, newBulkCmd("GET", "skipped"))
+		ch.HandleClientCommand(optIn, newBulkCmd("CLIENT", "CACHING", "YES"))
+		ch.HandleClientCommand(optIn, newBulkCmd("GET", "cached"))
+		ch.HandleCommand(newBulkCmd("SET", "skipped", "value"))
+		// the changes of the client itself are not sent with NOLOOP
+		ch.HandleClientCommand(optIn, newBulkCmd("SET", "cached", "value"))
+		ch.HandleClientCommand(broadcast, newBulkCmd("SET", "user:2", "value"))
+		ch.HandleClientCommand(optIn, newBulkCmd("GET", "post:1"))
+		ch.HandleCommand(newBulkCmd("EVAL", "redis.call('SET', KEYS[1], 'value')", "1", "post:1"))
+	}()
+	readMessages(t, peer,
+		invalidation("key"),
+		invalidation("other"),
+		invalidation("user:1"),
+		invalidation("temp"),
+		invalidation("user:2"),
+	)
+	<-done
+
+	// the invalidations are lost once the client they are redirected to is gone
+	subscriber.Close()
+	ch.HandleClientCommand(tracker, newBulkCmd("GET", "key"))
+	ch.HandleCommand(newBulkCmd("DEL", "key"))
+	assert.Equal(
+		trackingInfo(bulkStrings("on", "broken_redirect"), subscriber.ID(), bulkStrings()),
+		ch.HandleClientCommand(tracker, newBulkCmd("CLIENT", "TRACKINGINFO")),
+	)
+}
+
+func TestHandleClientTrackingRedirectNotSubscribed(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn, peer := net.Pipe()
+	defer func() { _ = peer.Close() }()
+	subscriber := ch.NewClient(conn)
+	ch.HandleClientCommand(subscriber, newBulkCmd("SUBSCRIBE", "news"))
+	trackerConn, trackerPeer := net.Pipe()
+	defer func() { _ = trackerPeer.Close() }()
+	tracker := ch.NewClient(trackerConn)
+	redirect := strconv.FormatInt(subscriber.ID(), 10)
+	assert.Equal(handler.OK, ch.HandleClientCommand(tracker, newBulkCmd("CLIENT", "TRACKING", "ON", "REDIRECT", redirect)))
+
+	// the client is in Pub/Sub mode, but only gets the messages of its own channels
+	go func() {
+		ch.HandleClientCommand(tracker, newBulkCmd("GET", "key"))
+		ch.HandleCommand(newBulkCmd("SET", "key", "value"))
+		ch.HandleCommand(newBulkCmd("PUBLISH", "news", "hello"))
+	}()
+	readMessages(t, peer, bulkStrings("message", "news", "hello"))
+}
+
+func TestHandleClientTrackingBrokenRedirect(t *testing.T) {
+	assert := assert.New(t)
+
+	storageEngine := storage.NewMapStorageEngine()
+	ch := handler.NewCommandHandler(&storageEngine)
+
+	conn, peer := net.Pipe()
+	defer func() { _ = peer.Close() }()
+	subscriber := ch.NewClient(conn)
+	ch.HandleClientCommand(subscriber, newBulkCmd("SUBSCRIBE", handler.TRACKING_CHANNEL))
+	trackerConn, trackerPeer := net.Pipe()
+	defer func() { _ = trackerPeer.Close() }()
+	tracker := ch.NewClient(trackerConn)
+	redirect := strconv.FormatInt(subscriber.ID(), 10)
+	assert.Equal(handler.OK, ch.HandleClientCommand(tracker, newBulkCmd("CLIENT", "TRACKING", "ON", "REDIRECT", redirect)))
+	assert.Contains(ch.HandleClientCommand(tracker, newBulkCmd("CLIENT", "INFO")).(data.BulkString).Data, "flags=t ")
+
+	// once the client the messages are redirected to is gone, the invalidations are dropped without telling the
+	// tracking client, which has no way to receive a message over RESP2
+	subscriber.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ch.HandleClientCommand(tracker, newBulkCmd("GET", "key"))
+		ch.HandleCommand(newBulkCmd("SET", "key", "value"))
+	}()
+	assert.Nil(trackerPeer.SetReadDeadline(time.Now().Add(100 * time.Millisecond)))
+	_, err := trackerPeer.Read(make([]byte, 1))
+	assert.ErrorIs(err, os.ErrDeadlineExceeded)
+	<-done
+
+	assert.Equal(
+		trackingInfo(bulkStrings("on", "broken_redirect"), subscriber.ID(), bulkStrings()),
+		ch.HandleClientCommand(tracker, newBulkCmd("CLIENT", "TRACKINGINFO")),
+	)
+	assert.Contains(ch.HandleClientCommand(tracker, newBulkCmd("CLIENT", "INFO")).(data.BulkString).Data, "flags=tR ")
+
+	// tracking again with another redirection repairs it
+	other := ch.NewClient(conn)
+	assert.Equal(handler.OK, ch.HandleClientCommand(tracker, newBulkCmd("CLIENT", "TRACKING", "ON", "REDIRECT", strconv.FormatInt(other.ID(), 10))))
+	assert.Equal(
+		trackingInfo(bulkStrings("on"), other.ID(), bulkStrings()),
+		ch.HandleClientCommand(tracker, newBulkCmd("CLIENT", "TRACKINGINFO")),
+	)
+}
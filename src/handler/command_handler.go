@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// https://redis.io/docs/latest/commands/command/
+func handleCommand(cmdArray data.Array) data.Message {
+	if len(cmdArray.Elements) == 1 {
+		return commandInfos(nil)
+	}
+
+	subCommandHolder := cmdArray.Elements[1].(data.BulkString)
+	names := make([]string, len(cmdArray.Elements)-2)
+	for idx := range names {
+		names[idx] = cmdArray.Elements[2+idx].(data.BulkString).Data
+	}
+
+	switch strings.ToUpper(subCommandHolder.Data) {
+	case "COUNT":
+		return data.Integer{Value: int64(len(COMMAND_TABLE))}
+	case "INFO":
+		return commandInfos(names)
+	case "DOCS":
+		return commandDocs(names)
+	default:
+		return data.Error{
+			ErrMsg: fmt.Sprintf("unsupported subcommand %s for %s", subCommandHolder.Data, CMD_COMMAND),
+		}
+	}
+}
+
+// sortedCommandSpecs returns the specs of all the commands ordered by name.
+func sortedCommandSpecs() []*commandSpec {
+	specs := make([]*commandSpec, 0, len(COMMAND_TABLE))
+	for _, spec := range COMMAND_TABLE {
+		specs = append(specs, spec)
+	}
+	slices.SortFunc(specs, func(a, b *commandSpec) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return specs
+}
+
+// https://redis.io/docs/latest/commands/command-info/
+// with no names, every command is returned. unknown commands are returned as nil.
+func commandInfos(names []string) data.Message {
+	elements := []data.Message{}
+	if len(names) == 0 {
+		for _, spec := range sortedCommandSpecs() {
+			elements = append(elements, spec.info())
+		}
+		return data.Array{Elements: elements}
+	}
+
+	for _, name := range names {
+		spec, ok := lookupCommand(strings.ToUpper(name))
+		if !ok {
+			elements = append(elements, data.Null{})
+			continue
+		}
+		elements = append(elements, spec.info())
+	}
+	return data.Array{Elements: elements}
+}
+
+// https://redis.io/docs/latest/commands/command-docs/
+// with no names, every command is documented. unknown commands are skipped.
+func commandDocs(names []string) data.Message {
+	specs := []*commandSpec{}
+	if len(names) == 0 {
+		specs = sortedCommandSpecs()
+	}
+	for _, name := range names {
+		if spec, ok := lookupCommand(strings.ToUpper(name)); ok {
+			specs = append(specs, spec)
+		}
+	}
+
+	elements := []data.Message{}
+	for _, spec := range specs {
+		elements = append(elements, data.BulkString{Data: spec.name}, spec.docs())
+	}
+	return data.Array{Elements: elements}
+}
@@ -0,0 +1,2953 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// command flags, as reported by COMMAND INFO.
+const (
+	CMD_FLAG_WRITE     = "write"
+	CMD_FLAG_READONLY  = "readonly"
+	CMD_FLAG_DENYOOM   = "denyoom"
+	CMD_FLAG_FAST      = "fast"
+	CMD_FLAG_ADMIN     = "admin"
+	CMD_FLAG_PUBSUB    = "pubsub"
+	CMD_FLAG_NOSCRIPT  = "noscript"
+	CMD_FLAG_BLOCKING  = "blocking"
+	CMD_FLAG_LOADING   = "loading"
+	CMD_FLAG_STALE     = "stale"
+	CMD_FLAG_NO_AUTH   = "no_auth"
+	CMD_FLAG_ALLOWBUSY = "allow_busy"
+	CMD_FLAG_MOVABLE   = "movablekeys"
+	CMD_FLAG_ASKING    = "asking"
+)
+
+// argument types, as reported by COMMAND DOCS.
+const (
+	ARG_TYPE_KEY       = "key"
+	ARG_TYPE_STRING    = "string"
+	ARG_TYPE_INTEGER   = "integer"
+	ARG_TYPE_DOUBLE    = "double"
+	ARG_TYPE_PATTERN   = "pattern"
+	ARG_TYPE_UNIX_TIME = "unix-time"
+	ARG_TYPE_TOKEN     = "pure-token"
+	ARG_TYPE_ONEOF     = "oneof"
+	ARG_TYPE_BLOCK     = "block"
+)
+
+// the ACL categories known to the server.
+var ACL_CATEGORIES = []string{
+	"keyspace", "read", "write", "set", "sortedset", "list", "hash", "string", "bitmap", "hyperloglog", "geo",
+	"stream", "pubsub", "admin", "fast", "slow", "blocking", "dangerous", "connection", "transaction", "scripting",
+}
+
+// commandArg documents a single argument of a command for COMMAND DOCS.
+type commandArg struct {
+	name     string
+	typ      string
+	token    string
+	optional bool
+	multiple bool
+	args     []commandArg
+}
+
+// commandFunc executes a validated command on behalf of the client, which is nil for internal calls.
+type commandFunc func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message
+
+// commandSpec describes a command: how it is validated, dispatched, checked by the ACL and introspected.
+// the arity follows the redis convention: a positive arity is the exact number of arguments
+// (including the command name) and a negative arity is the minimum number of arguments.
+type commandSpec struct {
+	name       string
+	arity      int
+	flags      []string
+	firstKey   int
+	lastKey    int
+	keyStep    int
+	categories []string
+	// getKeys finds the keys of commands whose keys are not at fixed positions, like the streams of XREAD
+	getKeys func(args []string) []string
+	// getChannels finds the channels of the pub/sub commands, which are checked against the channel permissions
+	getChannels func(args []string) []string
+	// propagate rewrites a write command into the commands sent to the replicas, so that they apply it with the
+	// same effect, like XADD with the ID it generated. commands rewritten as no command are not propagated
+	propagate func(cmdArray data.Array, reply data.Message, strg storage.StorageEngine) []data.Array
+
+	summary    string
+	since      string
+	group      string
+	complexity string
+	args       []commandArg
+
+	subcommands []*commandSpec
+	handler     commandFunc
+}
+
+// COMMAND_TABLE holds every command supported by the server, keyed by its upper case name.
+var COMMAND_TABLE map[string]*commandSpec
+
+func init() {
+	COMMAND_TABLE = map[string]*commandSpec{}
+	for _, spec := range commandSpecs() {
+		COMMAND_TABLE[strings.ToUpper(spec.name)] = spec
+	}
+}
+
+func lookupCommand(command string) (*commandSpec, bool) {
+	spec, ok := COMMAND_TABLE[command]
+	return spec, ok
+}
+
+// subcommand returns the spec of the given subcommand of a container command like CONFIG.
+func (spec *commandSpec) subcommand(subCmd string) (*commandSpec, bool) {
+	fullName := spec.name + "|" + strings.ToLower(subCmd)
+	for _, sub := range spec.subcommands {
+		if sub.name == fullName {
+			return sub, true
+		}
+	}
+	return nil, false
+}
+
+func (spec *commandSpec) hasFlag(flag string) bool {
+	return slices.Contains(spec.flags, flag)
+}
+
+// writes reports whether a command writes to the dataset. containers like FUNCTION whose subcommands
+// don't all write leave the write flag to their subcommands.
+func (spec *commandSpec) writes(cmdArray data.Array) bool {
+	if spec.hasFlag(CMD_FLAG_WRITE) {
+		return true
+	}
+	if len(spec.subcommands) > 0 && len(cmdArray.Elements) > 1 {
+		if sub, ok := spec.subcommand(cmdArray.Elements[1].(data.BulkString).Data); ok {
+			return sub.hasFlag(CMD_FLAG_WRITE)
+		}
+	}
+	return false
+}
+
+func (spec *commandSpec) checkArity(argc int) error {
+	if (spec.arity >= 0 && argc != spec.arity) || argc < -spec.arity {
+		return fmt.Errorf("wrong number of arguments for '%s' command", spec.name)
+	}
+	return nil
+}
+
+// aclCategories returns the ACL categories of the command. like redis, the categories that
+// follow from the flags of the command are added to the ones listed explicitly.
+func (spec *commandSpec) aclCategories() []string {
+	categories := slices.Clone(spec.categories)
+	if spec.hasFlag(CMD_FLAG_WRITE) {
+		categories = append(categories, "write")
+	}
+	if spec.hasFlag(CMD_FLAG_READONLY) {
+		categories = append(categories, "read")
+	}
+	if spec.hasFlag(CMD_FLAG_ADMIN) {
+		categories = append(categories, "admin", "dangerous")
+	}
+	if spec.hasFlag(CMD_FLAG_PUBSUB) {
+		categories = append(categories, "pubsub")
+	}
+	if spec.hasFlag(CMD_FLAG_BLOCKING) {
+		categories = append(categories, "blocking")
+	}
+	if spec.hasFlag(CMD_FLAG_FAST) {
+		categories = append(categories, "fast")
+	} else {
+		categories = append(categories, "slow")
+	}
+
+	slices.Sort(categories)
+	return slices.Compact(categories)
+}
+
+// keys returns the keys accessed by the command given its arguments (including the command name).
+// a negative last key position is relative to the end of the arguments. the keys of container
+// commands like XGROUP are found by their subcommands.
+func (spec *commandSpec) keys(args []string) []string {
+	if spec.getKeys != nil {
+		return spec.getKeys(args)
+	}
+	if len(args) > 1 {
+		if sub, ok := spec.subcommand(args[1]); ok {
+			return sub.keys(args)
+		}
+	}
+	if spec.firstKey == 0 {
+		return nil
+	}
+
+	last := spec.lastKey
+	if last < 0 {
+		last = len(args) + last
+	}
+
+	keys := []string{}
+	for idx := spec.firstKey; idx <= last && idx < len(args); idx += spec.keyStep {
+		keys = append(keys, args[idx])
+	}
+	return keys
+}
+
+// info renders the command in the format of COMMAND INFO.
+func (spec *commandSpec) info() data.Message {
+	flags := make([]data.Message, len(spec.flags))
+	for idx, flag := range spec.flags {
+		flags[idx] = data.SimpleString{Contents: flag}
+	}
+
+	aclCategories := spec.aclCategories()
+	categories := make([]data.Message, len(aclCategories))
+	for idx, category := range aclCategories {
+		categories[idx] = data.SimpleString{Contents: "@" + category}
+	}
+
+	subcommands := make([]data.Message, len(spec.subcommands))
+	for idx, sub := range spec.subcommands {
+		subcommands[idx] = sub.info()
+	}
+
+	return data.Array{Elements: []data.Message{
+		data.BulkString{Data: spec.name},
+		data.Integer{Value: int64(spec.arity)},
+		data.Array{Elements: flags},
+		data.Integer{Value: int64(spec.firstKey)},
+		data.Integer{Value: int64(spec.lastKey)},
+		data.Integer{Value: int64(spec.keyStep)},
+		data.Array{Elements: categories},
+		data.Array{Elements: []data.Message{}},
+		spec.keySpecs(),
+		data.Array{Elements: subcommands},
+	}}
+}
+
+// keySpecs renders the key positions of the command as a redis key specification.
+func (spec *commandSpec) keySpecs() data.Array {
+	if spec.firstKey == 0 {
+		return data.Array{Elements: []data.Message{}}
+	}
+
+	keyFlags := []data.Message{data.SimpleString{Contents: "RW"}, data.SimpleString{Contents: "update"}}
+	if spec.hasFlag(CMD_FLAG_READONLY) {
+		keyFlags = []data.Message{data.SimpleString{Contents: "RO"}, data.SimpleString{Contents: "access"}}
+	}
+
+	// the last key of a range is relative to the first key, unless it is counted from the end
+	lastKey := spec.lastKey
+	if lastKey >= 0 {
+		lastKey -= spec.firstKey
+	}
+
+	return data.Array{Elements: []data.Message{
+		data.Array{Elements: []data.Message{
+			data.BulkString{Data: "flags"},
+			data.Array{Elements: keyFlags},
+			data.BulkString{Data: "begin_search"},
+			data.Array{Elements: []data.Message{
+				data.BulkString{Data: "type"},
+				data.BulkString{Data: "index"},
+				data.BulkString{Data: "spec"},
+				data.Array{Elements: []data.Message{
+					data.BulkString{Data: "index"},
+					data.Integer{Value: int64(spec.firstKey)},
+				}},
+			}},
+			data.BulkString{Data: "find_keys"},
+			data.Array{Elements: []data.Message{
+				data.BulkString{Data: "type"},
+				data.BulkString{Data: "range"},
+				data.BulkString{Data: "spec"},
+				data.Array{Elements: []data.Message{
+					data.BulkString{Data: "lastkey"},
+					data.Integer{Value: int64(lastKey)},
+					data.BulkString{Data: "keystep"},
+					data.Integer{Value: int64(spec.keyStep)},
+					data.BulkString{Data: "limit"},
+					data.Integer{Value: 0},
+				}},
+			}},
+		}},
+	}}
+}
+
+// docs renders the documentation of the command in the format of COMMAND DOCS.
+func (spec *commandSpec) docs() data.Message {
+	elements := []data.Message{
+		data.BulkString{Data: "summary"},
+		data.BulkString{Data: spec.summary},
+		data.BulkString{Data: "since"},
+		data.BulkString{Data: spec.since},
+		data.BulkString{Data: "group"},
+		data.BulkString{Data: spec.group},
+	}
+	if spec.complexity != "" {
+		elements = append(elements, data.BulkString{Data: "complexity"}, data.BulkString{Data: spec.complexity})
+	}
+	if len(spec.args) > 0 {
+		elements = append(elements, data.BulkString{Data: "arguments"}, argsDocs(spec.args))
+	}
+	if len(spec.subcommands) > 0 {
+		subcommands := []data.Message{}
+		for _, sub := range spec.subcommands {
+			subcommands = append(subcommands, data.BulkString{Data: sub.name}, sub.docs())
+		}
+		elements = append(elements, data.BulkString{Data: "subcommands"}, data.Array{Elements: subcommands})
+	}
+	return data.Array{Elements: elements}
+}
+
+func argsDocs(args []commandArg) data.Array {
+	elements := make([]data.Message, len(args))
+	for idx, arg := range args {
+		argElements := []data.Message{
+			data.BulkString{Data: "name"},
+			data.BulkString{Data: arg.name},
+			data.BulkString{Data: "type"},
+			data.BulkString{Data: arg.typ},
+		}
+		if arg.token != "" {
+			argElements = append(argElements, data.BulkString{Data: "token"}, data.BulkString{Data: arg.token})
+		}
+
+		flags := []data.Message{}
+		if arg.optional {
+			flags = append(flags, data.SimpleString{Contents: "optional"})
+		}
+		if arg.multiple {
+			flags = append(flags, data.SimpleString{Contents: "multiple"})
+		}
+		if len(flags) > 0 {
+			argElements = append(argElements, data.BulkString{Data: "flags"}, data.Array{Elements: flags})
+		}
+
+		if len(arg.args) > 0 {
+			argElements = append(argElements, data.BulkString{Data: "arguments"}, argsDocs(arg.args))
+		}
+		elements[idx] = data.Array{Elements: argElements}
+	}
+	return data.Array{Elements: elements}
+}
+
+// commandSpecs lists the commands supported by the server.
+func commandSpecs() []*commandSpec {
+	return []*commandSpec{
+		{
+			name:       "ping",
+			arity:      -1,
+			flags:      []string{CMD_FLAG_FAST},
+			categories: []string{"connection"},
+			summary:    "Returns the server's liveliness response.",
+			since:      "1.0.0",
+			group:      "connection",
+			complexity: "O(1)",
+			args:       []commandArg{{name: "message", typ: ARG_TYPE_STRING, optional: true}},
+			handler: func(_ CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handlePing(client, cmdArray)
+			},
+		},
+		{
+			name:       "echo",
+			arity:      2,
+			flags:      []string{CMD_FLAG_FAST},
+			categories: []string{"connection"},
+			summary:    "Returns the given string.",
+			since:      "1.0.0",
+			group:      "connection",
+			complexity: "O(1)",
+			args:       []commandArg{{name: "message", typ: ARG_TYPE_STRING}},
+			handler: func(_ CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleEcho(cmdArray)
+			},
+		},
+		{
+			name:       "hello",
+			arity:      -1,
+			flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE, CMD_FLAG_FAST, CMD_FLAG_NO_AUTH, CMD_FLAG_ALLOWBUSY},
+			categories: []string{"connection"},
+			summary:    "Handshakes with the Redis server.",
+			since:      "6.0.0",
+			group:      "connection",
+			complexity: "O(1)",
+			args:       []commandArg{{name: "protover", typ: ARG_TYPE_INTEGER, optional: true}},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleHello(ch, client, cmdArray)
+			},
+		},
+		{
+			name:       "auth",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE, CMD_FLAG_FAST, CMD_FLAG_NO_AUTH, CMD_FLAG_ALLOWBUSY},
+			categories: []string{"connection"},
+			summary:    "Authenticates the connection.",
+			since:      "1.0.0",
+			group:      "connection",
+			complexity: "O(N) where N is the number of passwords defined for the user",
+			args: []commandArg{
+				{name: "username", typ: ARG_TYPE_STRING, optional: true},
+				{name: "password", typ: ARG_TYPE_STRING},
+			},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleAuth(cmdArray, client, ch.acl)
+			},
+		},
+		{
+			name:       "set",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			propagate:  propagateSet,
+			summary:    "Sets the string value of a key, ignoring its type. The key is created if it doesn't exist.",
+			since:      "1.0.0",
+			group:      "string",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "value", typ: ARG_TYPE_STRING},
+				{name: "expiration", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+					{name: "seconds", typ: ARG_TYPE_INTEGER, token: CMD_SET_OPT_EX},
+					{name: "milliseconds", typ: ARG_TYPE_INTEGER, token: CMD_SET_OPT_PX},
+					{name: "unix-time-seconds", typ: ARG_TYPE_UNIX_TIME, token: CMD_SET_OPT_EXAT},
+					{name: "unix-time-milliseconds", typ: ARG_TYPE_UNIX_TIME, token: CMD_SET_OPT_PXAT},
+				}},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleSet(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "get",
+			arity:      2,
+			flags:      []string{CMD_FLAG_READONLY, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Returns the string value of a key.",
+			since:      "1.0.0",
+			group:      "string",
+			complexity: "O(1)",
+			args:       []commandArg{{name: "key", typ: ARG_TYPE_KEY}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGet(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "exists",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_READONLY, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    -1,
+			keyStep:    1,
+			categories: []string{"keyspace"},
+			summary:    "Determines whether one or more keys exist.",
+			since:      "1.0.0",
+			group:      "generic",
+			complexity: "O(N) where N is the number of keys to check.",
+			args:       []commandArg{{name: "key", typ: ARG_TYPE_KEY, multiple: true}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleExists(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "del",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_WRITE},
+			firstKey:   1,
+			lastKey:    -1,
+			keyStep:    1,
+			categories: []string{"keyspace"},
+			summary:    "Deletes one or more keys.",
+			since:      "1.0.0",
+			group:      "generic",
+			complexity: "O(N) where N is the number of keys that will be removed.",
+			args:       []commandArg{{name: "key", typ: ARG_TYPE_KEY, multiple: true}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleDelete(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "incr",
+			arity:      2,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Increments the integer value of a key by one. Uses 0 as initial value if the key doesn't exist.",
+			since:      "1.0.0",
+			group:      "string",
+			complexity: "O(1)",
+			args:       []commandArg{{name: "key", typ: ARG_TYPE_KEY}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleAtomicDelta(cmdArray, ch.strgEngine, false)
+			},
+		},
+		{
+			name:       "decr",
+			arity:      2,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Decrements the integer value of a key by one. Uses 0 as initial value if the key doesn't exist.",
+			since:      "1.0.0",
+			group:      "string",
+			complexity: "O(1)",
+			args:       []commandArg{{name: "key", typ: ARG_TYPE_KEY}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleAtomicDelta(cmdArray, ch.strgEngine, true)
+			},
+		},
+		{
+			name:       "incrby",
+			arity:      3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Increments the integer value of a key by a number. Uses 0 as initial value if the key doesn't exist.",
+			since:      "1.0.0",
+			group:      "string",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "increment", typ: ARG_TYPE_INTEGER},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleAtomicDelta(cmdArray, ch.strgEngine, false)
+			},
+		},
+		{
+			name:       "decrby",
+			arity:      3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Decrements a number from the integer value of a key. Uses 0 as initial value if the key doesn't exist.",
+			since:      "1.0.0",
+			group:      "string",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "decrement", typ: ARG_TYPE_INTEGER},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleAtomicDelta(cmdArray, ch.strgEngine, true)
+			},
+		},
+		{
+			name:       "incrbyfloat",
+			arity:      3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Increment the floating point value of a key by a number. Uses 0 as initial value if the key doesn't exist.",
+			since:      "2.6.0",
+			group:      "string",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "increment", typ: ARG_TYPE_DOUBLE},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleAtomicFloatDelta(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "lpush",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"list"},
+			summary:    "Prepends one or more elements to a list. Creates the key if it doesn't exist.",
+			since:      "1.0.0",
+			group:      "list",
+			complexity: "O(1) for each element added, so O(N) to add N elements when the command is called with multiple arguments.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "element", typ: ARG_TYPE_STRING, multiple: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleListPush(cmdArray, ch.strgEngine, true)
+			},
+		},
+		{
+			name:       "rpush",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"list"},
+			summary:    "Appends one or more elements to a list. Creates the key if it doesn't exist.",
+			since:      "1.0.0",
+			group:      "list",
+			complexity: "O(1) for each element added, so O(N) to add N elements when the command is called with multiple arguments.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "element", typ: ARG_TYPE_STRING, multiple: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleListPush(cmdArray, ch.strgEngine, false)
+			},
+		},
+		{
+			name:       "append",
+			arity:      3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Appends a string to the value of a key. Creates the key if it doesn't exist.",
+			since:      "2.0.0",
+			group:      "string",
+			complexity: "O(1). The amortized time complexity is O(1) assuming the appended value is small and the already present value is of any size, since the dynamic string library used by Redis will double the free space available on every reallocation.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "value", typ: ARG_TYPE_STRING},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleAppend(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "strlen",
+			arity:      2,
+			flags:      []string{CMD_FLAG_READONLY, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Returns the length of a string value.",
+			since:      "2.2.0",
+			group:      "string",
+			complexity: "O(1)",
+			args:       []commandArg{{name: "key", typ: ARG_TYPE_KEY}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleStrLen(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "getrange",
+			arity:      4,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Returns a substring of the string stored at a key.",
+			since:      "2.4.0",
+			group:      "string",
+			complexity: "O(N) where N is the length of the returned string. The complexity is ultimately determined by the returned length, but because creating a substring from an existing string is very cheap, it can be considered O(1) for small strings.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "start", typ: ARG_TYPE_INTEGER},
+				{name: "end", typ: ARG_TYPE_INTEGER},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGetRange(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "setrange",
+			arity:      4,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Overwrites a part of a string value with another by an offset. Creates the key if it doesn't exist.",
+			since:      "2.2.0",
+			group:      "string",
+			complexity: "O(1), not counting the time taken to copy the new string in place. Usually, this string is very small so the amortized complexity is O(1). Otherwise, complexity is O(M) with M being the length of the value argument.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "offset", typ: ARG_TYPE_INTEGER},
+				{name: "value", typ: ARG_TYPE_STRING},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleSetRange(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "getdel",
+			arity:      2,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Returns the string value of a key after deleting the key.",
+			since:      "6.2.0",
+			group:      "string",
+			complexity: "O(1)",
+			args:       []commandArg{{name: "key", typ: ARG_TYPE_KEY}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGetDel(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "getex",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			propagate:  propagateGetEx,
+			summary:    "Returns the string value of a key after setting its expiration time.",
+			since:      "6.2.0",
+			group:      "string",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "expiration", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+					{name: "seconds", typ: ARG_TYPE_INTEGER, token: CMD_SET_OPT_EX},
+					{name: "milliseconds", typ: ARG_TYPE_INTEGER, token: CMD_SET_OPT_PX},
+					{name: "unix-time-seconds", typ: ARG_TYPE_UNIX_TIME, token: CMD_SET_OPT_EXAT},
+					{name: "unix-time-milliseconds", typ: ARG_TYPE_UNIX_TIME, token: CMD_SET_OPT_PXAT},
+					{name: "persist", typ: ARG_TYPE_TOKEN, token: CMD_GETEX_OPT_PERSIST},
+				}},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGetEx(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "getset",
+			arity:      3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Returns the previous string value of a key after setting it to a new value.",
+			since:      "1.0.0",
+			group:      "string",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "value", typ: ARG_TYPE_STRING},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGetSet(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "setnx",
+			arity:      3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Set the string value of a key only when the key doesn't exist.",
+			since:      "1.0.0",
+			group:      "string",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "value", typ: ARG_TYPE_STRING},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleSetNX(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "setex",
+			arity:      4,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			propagate:  propagateSetEx,
+			summary:    "Sets the string value and expiration time of a key. Creates the key if it doesn't exist.",
+			since:      "2.0.0",
+			group:      "string",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "seconds", typ: ARG_TYPE_INTEGER},
+				{name: "value", typ: ARG_TYPE_STRING},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleSetEx(cmdArray, ch.strgEngine, false)
+			},
+		},
+		{
+			name:       "psetex",
+			arity:      4,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"string"},
+			propagate:  propagateSetEx,
+			summary:    "Sets both string value and expiration time in milliseconds of a key. The key is created if it doesn't exist.",
+			since:      "2.6.0",
+			group:      "string",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "milliseconds", typ: ARG_TYPE_INTEGER},
+				{name: "value", typ: ARG_TYPE_STRING},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleSetEx(cmdArray, ch.strgEngine, true)
+			},
+		},
+		{
+			name:       "lcs",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    2,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Finds the longest common substring.",
+			since:      "7.0.0",
+			group:      "string",
+			complexity: "O(N*M) where N and M are the lengths of s1 and s2, respectively",
+			args: []commandArg{
+				{name: "key1", typ: ARG_TYPE_KEY},
+				{name: "key2", typ: ARG_TYPE_KEY},
+				{name: "len", typ: ARG_TYPE_TOKEN, token: "LEN", optional: true},
+				{name: "idx", typ: ARG_TYPE_TOKEN, token: "IDX", optional: true},
+				{name: "min-match-len", typ: ARG_TYPE_INTEGER, token: "MINMATCHLEN", optional: true},
+				{name: "withmatchlen", typ: ARG_TYPE_TOKEN, token: "WITHMATCHLEN", optional: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleLcs(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "mget",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_READONLY, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    -1,
+			keyStep:    1,
+			categories: []string{"string"},
+			summary:    "Atomically returns the string values of one or more keys.",
+			since:      "1.0.0",
+			group:      "string",
+			complexity: "O(N) where N is the number of keys to retrieve.",
+			args:       []commandArg{{name: "key", typ: ARG_TYPE_KEY, multiple: true}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleMGet(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "mset",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    -1,
+			keyStep:    2,
+			categories: []string{"string"},
+			summary:    "Atomically creates or modifies the string values of one or more keys.",
+			since:      "1.0.1",
+			group:      "string",
+			complexity: "O(N) where N is the number of keys to set.",
+			args: []commandArg{{name: "data", typ: ARG_TYPE_BLOCK, multiple: true, args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "value", typ: ARG_TYPE_STRING},
+			}}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleMSet(cmdArray, ch.strgEngine, false)
+			},
+		},
+		{
+			name:       "msetnx",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    -1,
+			keyStep:    2,
+			categories: []string{"string"},
+			summary:    "Atomically modifies the string values of one or more keys only when all keys don't exist.",
+			since:      "1.0.1",
+			group:      "string",
+			complexity: "O(N) where N is the number of keys to set.",
+			args: []commandArg{{name: "data", typ: ARG_TYPE_BLOCK, multiple: true, args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "value", typ: ARG_TYPE_STRING},
+			}}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleMSet(cmdArray, ch.strgEngine, true)
+			},
+		},
+		{
+			name:       "setbit",
+			arity:      4,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"bitmap"},
+			summary:    "Sets or clears the bit at offset of the string value. Creates the key if it doesn't exist.",
+			since:      "2.2.0",
+			group:      "bitmap",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "offset", typ: ARG_TYPE_INTEGER},
+				{name: "value", typ: ARG_TYPE_INTEGER},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleSetBit(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "getbit",
+			arity:      3,
+			flags:      []string{CMD_FLAG_READONLY, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"bitmap"},
+			summary:    "Returns a bit value by offset.",
+			since:      "2.2.0",
+			group:      "bitmap",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "offset", typ: ARG_TYPE_INTEGER},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGetBit(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "bitcount",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"bitmap"},
+			summary:    "Counts the number of set bits (population counting) in a string.",
+			since:      "2.6.0",
+			group:      "bitmap",
+			complexity: "O(N)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "range", typ: ARG_TYPE_BLOCK, optional: true, args: []commandArg{
+					{name: "start", typ: ARG_TYPE_INTEGER},
+					{name: "end", typ: ARG_TYPE_INTEGER},
+					{name: "unit", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+						{name: "byte", typ: ARG_TYPE_TOKEN, token: "BYTE"},
+						{name: "bit", typ: ARG_TYPE_TOKEN, token: "BIT"},
+					}},
+				}},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleBitCount(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "bitpos",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"bitmap"},
+			summary:    "Finds the first set (1) or clear (0) bit in a string.",
+			since:      "2.8.7",
+			group:      "bitmap",
+			complexity: "O(N)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "bit", typ: ARG_TYPE_INTEGER},
+				{name: "range", typ: ARG_TYPE_BLOCK, optional: true, args: []commandArg{
+					{name: "start", typ: ARG_TYPE_INTEGER},
+					{name: "end-unit-block", typ: ARG_TYPE_BLOCK, optional: true, args: []commandArg{
+						{name: "end", typ: ARG_TYPE_INTEGER},
+						{name: "unit", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+							{name: "byte", typ: ARG_TYPE_TOKEN, token: "BYTE"},
+							{name: "bit", typ: ARG_TYPE_TOKEN, token: "BIT"},
+						}},
+					}},
+				}},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleBitPos(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "bitop",
+			arity:      -4,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   2,
+			lastKey:    -1,
+			keyStep:    1,
+			categories: []string{"bitmap"},
+			summary:    "Performs bitwise operations on multiple strings, and stores the result.",
+			since:      "2.6.0",
+			group:      "bitmap",
+			complexity: "O(N)",
+			args: []commandArg{
+				{name: "operation", typ: ARG_TYPE_ONEOF, args: []commandArg{
+					{name: "and", typ: ARG_TYPE_TOKEN, token: storage.BITOP_AND},
+					{name: "or", typ: ARG_TYPE_TOKEN, token: storage.BITOP_OR},
+					{name: "xor", typ: ARG_TYPE_TOKEN, token: storage.BITOP_XOR},
+					{name: "not", typ: ARG_TYPE_TOKEN, token: storage.BITOP_NOT},
+				}},
+				{name: "destkey", typ: ARG_TYPE_KEY},
+				{name: "key", typ: ARG_TYPE_KEY, multiple: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleBitOp(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "bitfield",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"bitmap"},
+			summary:    "Performs arbitrary bitfield integer operations on strings.",
+			since:      "3.2.0",
+			group:      "bitmap",
+			complexity: "O(1) for each subcommand specified",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "operation", typ: ARG_TYPE_ONEOF, optional: true, multiple: true, args: []commandArg{
+					{name: "get-block", typ: ARG_TYPE_BLOCK, token: "GET", args: []commandArg{
+						{name: "encoding", typ: ARG_TYPE_STRING},
+						{name: "offset", typ: ARG_TYPE_INTEGER},
+					}},
+					{name: "write", typ: ARG_TYPE_BLOCK, args: []commandArg{
+						{name: "overflow-block", typ: ARG_TYPE_ONEOF, token: "OVERFLOW", optional: true, args: []commandArg{
+							{name: "wrap", typ: ARG_TYPE_TOKEN, token: "WRAP"},
+							{name: "sat", typ: ARG_TYPE_TOKEN, token: "SAT"},
+							{name: "fail", typ: ARG_TYPE_TOKEN, token: "FAIL"},
+						}},
+						{name: "write-operation", typ: ARG_TYPE_ONEOF, args: []commandArg{
+							{name: "set-block", typ: ARG_TYPE_BLOCK, token: "SET", args: []commandArg{
+								{name: "encoding", typ: ARG_TYPE_STRING},
+								{name: "offset", typ: ARG_TYPE_INTEGER},
+								{name: "value", typ: ARG_TYPE_INTEGER},
+							}},
+							{name: "incrby-block", typ: ARG_TYPE_BLOCK, token: "INCRBY", args: []commandArg{
+								{name: "encoding", typ: ARG_TYPE_STRING},
+								{name: "offset", typ: ARG_TYPE_INTEGER},
+								{name: "increment", typ: ARG_TYPE_INTEGER},
+							}},
+						}},
+					}},
+				}},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleBitField(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "pfadd",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"hyperloglog"},
+			summary:    "Adds elements to a HyperLogLog key. Creates the key if it doesn't exist.",
+			since:      "2.8.9",
+			group:      "hyperloglog",
+			complexity: "O(1) to add every element.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "element", typ: ARG_TYPE_STRING, optional: true, multiple: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handlePFAdd(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "pfcount",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    -1,
+			keyStep:    1,
+			categories: []string{"hyperloglog"},
+			summary:    "Returns the approximated cardinality of the set(s) observed by the HyperLogLog key(s).",
+			since:      "2.8.9",
+			group:      "hyperloglog",
+			complexity: "O(1) with a very small average constant time when called with a single key. O(N) with N being the number of keys, and much bigger constant times, when called with multiple keys.",
+			args:       []commandArg{{name: "key", typ: ARG_TYPE_KEY, multiple: true}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handlePFCount(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "pfmerge",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    -1,
+			keyStep:    1,
+			categories: []string{"hyperloglog"},
+			summary:    "Merges one or more HyperLogLog values into a single key.",
+			since:      "2.8.9",
+			group:      "hyperloglog",
+			complexity: "O(N) to merge N HyperLogLogs, but with high constant times.",
+			args: []commandArg{
+				{name: "destkey", typ: ARG_TYPE_KEY},
+				{name: "sourcekey", typ: ARG_TYPE_KEY, optional: true, multiple: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handlePFMerge(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "geoadd",
+			arity:      -5,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"geo"},
+			summary:    "Adds one or more members to a geospatial index. The key is created if it doesn't exist.",
+			since:      "3.2.0",
+			group:      "geo",
+			complexity: "O(log(N)) for each item added, where N is the number of elements in the sorted set.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "condition", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+					{name: "nx", typ: ARG_TYPE_TOKEN, token: "NX"},
+					{name: "xx", typ: ARG_TYPE_TOKEN, token: "XX"},
+				}},
+				{name: "change", typ: ARG_TYPE_TOKEN, token: "CH", optional: true},
+				{name: "data", typ: ARG_TYPE_BLOCK, multiple: true, args: []commandArg{
+					{name: "longitude", typ: ARG_TYPE_DOUBLE},
+					{name: "latitude", typ: ARG_TYPE_DOUBLE},
+					{name: "member", typ: ARG_TYPE_STRING},
+				}},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGeoAdd(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "geopos",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"geo"},
+			summary:    "Returns the longitude and latitude of members from a geospatial index.",
+			since:      "3.2.0",
+			group:      "geo",
+			complexity: "O(1) for each member requested.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "member", typ: ARG_TYPE_STRING, optional: true, multiple: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGeoPos(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "geodist",
+			arity:      -4,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"geo"},
+			summary:    "Returns the distance between two members of a geospatial index.",
+			since:      "3.2.0",
+			group:      "geo",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "member1", typ: ARG_TYPE_STRING},
+				{name: "member2", typ: ARG_TYPE_STRING},
+				{name: "unit", typ: ARG_TYPE_ONEOF, optional: true, args: geoUnitArgs()},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGeoDist(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "geohash",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"geo"},
+			summary:    "Returns members from a geospatial index as geohash strings.",
+			since:      "3.2.0",
+			group:      "geo",
+			complexity: "O(1) for each member requested.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "member", typ: ARG_TYPE_STRING, optional: true, multiple: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGeoHash(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "geosearch",
+			arity:      -7,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"geo"},
+			summary:    "Queries a geospatial index for members inside an area of a box or a circle.",
+			since:      "6.2.0",
+			group:      "geo",
+			complexity: "O(N+log(M)) where N is the number of elements in the grid-aligned bounding box area around the shape provided as the filter and M is the number of items inside the shape",
+			args: append(append([]commandArg{{name: "key", typ: ARG_TYPE_KEY}}, geoSearchArgs()...),
+				commandArg{name: "withcoord", typ: ARG_TYPE_TOKEN, token: "WITHCOORD", optional: true},
+				commandArg{name: "withdist", typ: ARG_TYPE_TOKEN, token: "WITHDIST", optional: true},
+				commandArg{name: "withhash", typ: ARG_TYPE_TOKEN, token: "WITHHASH", optional: true},
+			),
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGeoSearch(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "geosearchstore",
+			arity:      -8,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    2,
+			keyStep:    1,
+			categories: []string{"geo"},
+			summary:    "Queries a geospatial index for members inside an area of a box or a circle, optionally stores the result.",
+			since:      "6.2.0",
+			group:      "geo",
+			complexity: "O(N+log(M)) where N is the number of elements in the grid-aligned bounding box area around the shape provided as the filter and M is the number of items inside the shape",
+			args: append([]commandArg{
+				{name: "destination", typ: ARG_TYPE_KEY},
+				{name: "source", typ: ARG_TYPE_KEY},
+			}, geoSearchArgs()...),
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleGeoSearchStore(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "xadd",
+			arity:      -5,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"stream"},
+			propagate:  propagateXAdd,
+			summary:    "Appends a new message to a stream. Creates the key if it doesn't exist.",
+			since:      "5.0.0",
+			group:      "stream",
+			complexity: "O(1) when adding a new entry, O(N) when trimming where N being the number of entries evicted.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "nomkstream", typ: ARG_TYPE_TOKEN, token: "NOMKSTREAM", optional: true},
+				{name: "trim", typ: ARG_TYPE_BLOCK, optional: true, args: []commandArg{
+					{name: "strategy", typ: ARG_TYPE_ONEOF, args: []commandArg{
+						{name: "maxlen", typ: ARG_TYPE_TOKEN, token: "MAXLEN"},
+						{name: "minid", typ: ARG_TYPE_TOKEN, token: "MINID"},
+					}},
+					{name: "operator", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+						{name: "equal", typ: ARG_TYPE_TOKEN, token: "="},
+						{name: "approximately", typ: ARG_TYPE_TOKEN, token: "~"},
+					}},
+					{name: "threshold", typ: ARG_TYPE_STRING},
+					{name: "count", typ: ARG_TYPE_INTEGER, token: "LIMIT", optional: true},
+				}},
+				{name: "id-selector", typ: ARG_TYPE_ONEOF, args: []commandArg{
+					{name: "auto-id", typ: ARG_TYPE_TOKEN, token: "*"},
+					{name: "id", typ: ARG_TYPE_STRING},
+				}},
+				{name: "data", typ: ARG_TYPE_BLOCK, multiple: true, args: []commandArg{
+					{name: "field", typ: ARG_TYPE_STRING},
+					{name: "value", typ: ARG_TYPE_STRING},
+				}},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXAdd(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "xlen",
+			arity:      2,
+			flags:      []string{CMD_FLAG_READONLY, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"stream"},
+			summary:    "Return the number of messages in a stream.",
+			since:      "5.0.0",
+			group:      "stream",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXLen(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "xrange",
+			arity:      -4,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"stream"},
+			summary:    "Returns the messages from a stream within a range of IDs.",
+			since:      "5.0.0",
+			group:      "stream",
+			complexity: "O(N) with N being the number of elements being returned. If N is constant (e.g. always asking for the first 10 elements with COUNT), you can consider it O(1).",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "start", typ: ARG_TYPE_STRING},
+				{name: "end", typ: ARG_TYPE_STRING},
+				{name: "count", typ: ARG_TYPE_INTEGER, token: "COUNT", optional: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXRange(cmdArray, ch.strgEngine, false)
+			},
+		},
+		{
+			name:       "xrevrange",
+			arity:      -4,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"stream"},
+			summary:    "Returns the messages from a stream within a range of IDs in reverse order.",
+			since:      "5.0.0",
+			group:      "stream",
+			complexity: "O(N) with N being the number of elements returned. If N is constant (e.g. always asking for the first 10 elements with COUNT), you can consider it O(1).",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "end", typ: ARG_TYPE_STRING},
+				{name: "start", typ: ARG_TYPE_STRING},
+				{name: "count", typ: ARG_TYPE_INTEGER, token: "COUNT", optional: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXRange(cmdArray, ch.strgEngine, true)
+			},
+		},
+		{
+			name:       "xtrim",
+			arity:      -4,
+			flags:      []string{CMD_FLAG_WRITE},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"stream"},
+			summary:    "Deletes messages from the beginning of a stream.",
+			since:      "5.0.0",
+			group:      "stream",
+			complexity: "O(N), with N being the number of evicted entries. Constant times are very small however, since entries are organized in macro nodes containing multiple entries that can be released with a single deallocation.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "trim", typ: ARG_TYPE_BLOCK, args: []commandArg{
+					{name: "strategy", typ: ARG_TYPE_ONEOF, args: []commandArg{
+						{name: "maxlen", typ: ARG_TYPE_TOKEN, token: "MAXLEN"},
+						{name: "minid", typ: ARG_TYPE_TOKEN, token: "MINID"},
+					}},
+					{name: "operator", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+						{name: "equal", typ: ARG_TYPE_TOKEN, token: "="},
+						{name: "approximately", typ: ARG_TYPE_TOKEN, token: "~"},
+					}},
+					{name: "threshold", typ: ARG_TYPE_STRING},
+					{name: "count", typ: ARG_TYPE_INTEGER, token: "LIMIT", optional: true},
+				}},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXTrim(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "xdel",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"stream"},
+			summary:    "Returns the number of messages after removing them from a stream.",
+			since:      "5.0.0",
+			group:      "stream",
+			complexity: "O(1) for each single item to delete in the stream, regardless of the stream size.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "id", typ: ARG_TYPE_STRING, multiple: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXDel(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "xread",
+			arity:      -4,
+			flags:      []string{CMD_FLAG_READONLY, CMD_FLAG_BLOCKING, CMD_FLAG_MOVABLE},
+			categories: []string{"stream"},
+			getKeys: func(args []string) []string {
+				return xreadKeys(args[1:])
+			},
+			summary:    "Returns messages from multiple streams with IDs greater than the ones requested. Blocks until a message is available otherwise.",
+			since:      "5.0.0",
+			group:      "stream",
+			complexity: "For each stream mentioned: O(N) with N being the number of elements being returned, it means that XREAD-ing with a fixed COUNT is O(1). Note that when the BLOCK option is used, XADD will pay O(M) time in order to serve the M clients blocked on the stream getting new data.",
+			args: []commandArg{
+				{name: "count", typ: ARG_TYPE_INTEGER, token: "COUNT", optional: true},
+				{name: "milliseconds", typ: ARG_TYPE_INTEGER, token: "BLOCK", optional: true},
+				{name: "streams", typ: ARG_TYPE_BLOCK, token: "STREAMS", args: []commandArg{
+					{name: "key", typ: ARG_TYPE_KEY, multiple: true},
+					{name: "id", typ: ARG_TYPE_STRING, multiple: true},
+				}},
+			},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleXRead(cmdArray, ch.strgEngine, ch.enterBlocking(client, cmdArray))
+			},
+		},
+		{
+			name:       "xgroup",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_WRITE},
+			categories: []string{"stream"},
+			summary:    "A container for consumer groups commands.",
+			since:      "5.0.0",
+			group:      "stream",
+			subcommands: []*commandSpec{
+				{
+					name:       "xgroup|create",
+					arity:      -5,
+					flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+					firstKey:   2,
+					lastKey:    2,
+					keyStep:    1,
+					categories: []string{"stream"},
+					summary:    "Creates a consumer group.",
+					since:      "5.0.0",
+					group:      "stream",
+					complexity: "O(1)",
+					args: []commandArg{
+						{name: "key", typ: ARG_TYPE_KEY},
+						{name: "group", typ: ARG_TYPE_STRING},
+						{name: "id-selector", typ: ARG_TYPE_ONEOF, args: []commandArg{
+							{name: "id", typ: ARG_TYPE_STRING},
+							{name: "new-id", typ: ARG_TYPE_TOKEN, token: "$"},
+						}},
+						{name: "mkstream", typ: ARG_TYPE_TOKEN, token: "MKSTREAM", optional: true},
+						{name: "entriesread", typ: ARG_TYPE_INTEGER, token: "ENTRIESREAD", optional: true},
+					},
+				},
+				{
+					name:       "xgroup|setid",
+					arity:      -5,
+					flags:      []string{CMD_FLAG_WRITE},
+					firstKey:   2,
+					lastKey:    2,
+					keyStep:    1,
+					categories: []string{"stream"},
+					summary:    "Sets the last-delivered ID of a consumer group.",
+					since:      "5.0.0",
+					group:      "stream",
+					complexity: "O(1)",
+					args: []commandArg{
+						{name: "key", typ: ARG_TYPE_KEY},
+						{name: "group", typ: ARG_TYPE_STRING},
+						{name: "id-selector", typ: ARG_TYPE_ONEOF, args: []commandArg{
+							{name: "id", typ: ARG_TYPE_STRING},
+							{name: "new-id", typ: ARG_TYPE_TOKEN, token: "$"},
+						}},
+						{name: "entriesread", typ: ARG_TYPE_INTEGER, token: "ENTRIESREAD", optional: true},
+					},
+				},
+				{
+					name:       "xgroup|destroy",
+					arity:      4,
+					flags:      []string{CMD_FLAG_WRITE},
+					firstKey:   2,
+					lastKey:    2,
+					keyStep:    1,
+					categories: []string{"stream"},
+					summary:    "Destroys a consumer group.",
+					since:      "5.0.0",
+					group:      "stream",
+					complexity: "O(N) where N is the number of entries in the group's pending entries list (PEL).",
+					args: []commandArg{
+						{name: "key", typ: ARG_TYPE_KEY},
+						{name: "group", typ: ARG_TYPE_STRING},
+					},
+				},
+				{
+					name:       "xgroup|createconsumer",
+					arity:      5,
+					flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+					firstKey:   2,
+					lastKey:    2,
+					keyStep:    1,
+					categories: []string{"stream"},
+					summary:    "Creates a consumer in a consumer group.",
+					since:      "6.2.0",
+					group:      "stream",
+					complexity: "O(1)",
+					args: []commandArg{
+						{name: "key", typ: ARG_TYPE_KEY},
+						{name: "group", typ: ARG_TYPE_STRING},
+						{name: "consumer", typ: ARG_TYPE_STRING},
+					},
+				},
+				{
+					name:       "xgroup|delconsumer",
+					arity:      5,
+					flags:      []string{CMD_FLAG_WRITE},
+					firstKey:   2,
+					lastKey:    2,
+					keyStep:    1,
+					categories: []string{"stream"},
+					summary:    "Deletes a consumer from a consumer group.",
+					since:      "5.0.0",
+					group:      "stream",
+					complexity: "O(1)",
+					args: []commandArg{
+						{name: "key", typ: ARG_TYPE_KEY},
+						{name: "group", typ: ARG_TYPE_STRING},
+						{name: "consumer", typ: ARG_TYPE_STRING},
+					},
+				},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXGroup(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "xreadgroup",
+			arity:      -7,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_BLOCKING, CMD_FLAG_MOVABLE},
+			categories: []string{"stream"},
+			getKeys: func(args []string) []string {
+				return xreadKeys(args[1:])
+			},
+			propagate:  propagateXReadGroup,
+			summary:    "Returns new or historical messages from a stream for a consumer in a group. Blocks until a message is available otherwise.",
+			since:      "5.0.0",
+			group:      "stream",
+			complexity: "For each stream mentioned: O(M) with M being the number of elements returned. If M is constant (e.g. always asking for the first 10 elements with COUNT), you can consider it O(1). On the other side when XREADGROUP blocks, XADD will pay the O(N) time in order to serve the N clients blocked on the stream getting new data.",
+			args: []commandArg{
+				{name: "group-block", typ: ARG_TYPE_BLOCK, token: "GROUP", args: []commandArg{
+					{name: "group", typ: ARG_TYPE_STRING},
+					{name: "consumer", typ: ARG_TYPE_STRING},
+				}},
+				{name: "count", typ: ARG_TYPE_INTEGER, token: "COUNT", optional: true},
+				{name: "milliseconds", typ: ARG_TYPE_INTEGER, token: "BLOCK", optional: true},
+				{name: "noack", typ: ARG_TYPE_TOKEN, token: "NOACK", optional: true},
+				{name: "streams", typ: ARG_TYPE_BLOCK, token: "STREAMS", args: []commandArg{
+					{name: "key", typ: ARG_TYPE_KEY, multiple: true},
+					{name: "id", typ: ARG_TYPE_STRING, multiple: true},
+				}},
+			},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleXReadGroup(cmdArray, ch.strgEngine, ch.enterBlocking(client, cmdArray))
+			},
+		},
+		{
+			name:       "xack",
+			arity:      -4,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"stream"},
+			summary:    "Returns the number of messages that were successfully acknowledged by the consumer group member of a stream.",
+			since:      "5.0.0",
+			group:      "stream",
+			complexity: "O(1) for each message ID processed.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "group", typ: ARG_TYPE_STRING},
+				{name: "id", typ: ARG_TYPE_STRING, multiple: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXAck(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "xpending",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"stream"},
+			summary:    "Returns the information and entries from a stream consumer group's pending entries list.",
+			since:      "5.0.0",
+			group:      "stream",
+			complexity: "O(N) with N being the number of elements returned, so asking for a small fixed number of entries per call is O(1). O(M), where M is the total number of entries scanned when used with the IDLE filter. When the command returns just the summary and the list of consumers is small, it runs in O(1) time; otherwise, an additional O(N) time for iterating every consumer.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "group", typ: ARG_TYPE_STRING},
+				{name: "filters", typ: ARG_TYPE_BLOCK, optional: true, args: []commandArg{
+					{name: "min-idle-time", typ: ARG_TYPE_INTEGER, token: "IDLE", optional: true},
+					{name: "start", typ: ARG_TYPE_STRING},
+					{name: "end", typ: ARG_TYPE_STRING},
+					{name: "count", typ: ARG_TYPE_INTEGER},
+					{name: "consumer", typ: ARG_TYPE_STRING, optional: true},
+				}},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXPending(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "xclaim",
+			arity:      -6,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"stream"},
+			summary:    "Changes, or acquires, ownership of a message in a consumer group, as if the message was delivered a consumer group member.",
+			since:      "5.0.0",
+			group:      "stream",
+			complexity: "O(log N) with N being the number of messages in the PEL of the consumer group.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "group", typ: ARG_TYPE_STRING},
+				{name: "consumer", typ: ARG_TYPE_STRING},
+				{name: "min-idle-time", typ: ARG_TYPE_STRING},
+				{name: "id", typ: ARG_TYPE_STRING, multiple: true},
+				{name: "ms", typ: ARG_TYPE_INTEGER, token: "IDLE", optional: true},
+				{name: "unix-time-milliseconds", typ: ARG_TYPE_UNIX_TIME, token: "TIME", optional: true},
+				{name: "count", typ: ARG_TYPE_INTEGER, token: "RETRYCOUNT", optional: true},
+				{name: "force", typ: ARG_TYPE_TOKEN, token: "FORCE", optional: true},
+				{name: "justid", typ: ARG_TYPE_TOKEN, token: "JUSTID", optional: true},
+				{name: "lastid", typ: ARG_TYPE_STRING, token: "LASTID", optional: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXClaim(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "xautoclaim",
+			arity:      -6,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_FAST},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"stream"},
+			summary:    "Changes, or acquires, ownership of messages in a consumer group, as if the messages were delivered to as consumer group member.",
+			since:      "6.2.0",
+			group:      "stream",
+			complexity: "O(1) if COUNT is small.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "group", typ: ARG_TYPE_STRING},
+				{name: "consumer", typ: ARG_TYPE_STRING},
+				{name: "min-idle-time", typ: ARG_TYPE_STRING},
+				{name: "start", typ: ARG_TYPE_STRING},
+				{name: "count", typ: ARG_TYPE_INTEGER, token: "COUNT", optional: true},
+				{name: "justid", typ: ARG_TYPE_TOKEN, token: "JUSTID", optional: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXAutoClaim(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "xinfo",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_READONLY},
+			categories: []string{"stream"},
+			summary:    "A container for stream introspection commands.",
+			since:      "5.0.0",
+			group:      "stream",
+			subcommands: []*commandSpec{
+				{
+					name:       "xinfo|stream",
+					arity:      -3,
+					flags:      []string{CMD_FLAG_READONLY},
+					firstKey:   2,
+					lastKey:    2,
+					keyStep:    1,
+					categories: []string{"stream"},
+					summary:    "Returns information about a stream.",
+					since:      "5.0.0",
+					group:      "stream",
+					complexity: "O(log N) with N being the number of items in the stream.",
+					args: []commandArg{
+						{name: "key", typ: ARG_TYPE_KEY},
+						{name: "full-block", typ: ARG_TYPE_BLOCK, token: "FULL", optional: true, args: []commandArg{
+							{name: "count", typ: ARG_TYPE_INTEGER, token: "COUNT", optional: true},
+						}},
+					},
+				},
+				{
+					name:       "xinfo|groups",
+					arity:      3,
+					flags:      []string{CMD_FLAG_READONLY},
+					firstKey:   2,
+					lastKey:    2,
+					keyStep:    1,
+					categories: []string{"stream"},
+					summary:    "Returns a list of the consumer groups of a stream.",
+					since:      "5.0.0",
+					group:      "stream",
+					complexity: "O(1)",
+					args: []commandArg{
+						{name: "key", typ: ARG_TYPE_KEY},
+					},
+				},
+				{
+					name:       "xinfo|consumers",
+					arity:      4,
+					flags:      []string{CMD_FLAG_READONLY},
+					firstKey:   2,
+					lastKey:    2,
+					keyStep:    1,
+					categories: []string{"stream"},
+					summary:    "Returns a list of the consumers in a consumer group.",
+					since:      "5.0.0",
+					group:      "stream",
+					complexity: "O(1)",
+					args: []commandArg{
+						{name: "key", typ: ARG_TYPE_KEY},
+						{name: "group", typ: ARG_TYPE_STRING},
+					},
+				},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleXInfo(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "replicaof",
+			arity:      3,
+			flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_STALE},
+			summary:    "Configures a server as replica of another, or promotes it to a master.",
+			since:      "5.0.0",
+			group:      "server",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "host", typ: ARG_TYPE_STRING},
+				{name: "port", typ: ARG_TYPE_INTEGER},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleReplicaOf(ch, cmdArray)
+			},
+		},
+		{
+			name:       "replconf",
+			arity:      -1,
+			flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE, CMD_FLAG_ALLOWBUSY},
+			summary:    "An internal command for configuring the replication stream.",
+			since:      "3.0.0",
+			group:      "server",
+			complexity: "O(1)",
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleReplconf(ch, client, cmdArray)
+			},
+		},
+		{
+			name:       "psync",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT},
+			summary:    "An internal command used in replication.",
+			since:      "2.8.0",
+			group:      "server",
+			complexity: "O(N) for a full resynchronisation where N is the number of keys, O(M) otherwise where M is the size of the backlog sent",
+			args: []commandArg{
+				{name: "replicationid", typ: ARG_TYPE_STRING},
+				{name: "offset", typ: ARG_TYPE_INTEGER},
+			},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handlePsync(ch, client, cmdArray)
+			},
+		},
+		{
+			name:       "role",
+			arity:      1,
+			flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE, CMD_FLAG_FAST},
+			categories: []string{"admin", "dangerous"},
+			summary:    "Returns the replication role.",
+			since:      "2.8.12",
+			group:      "server",
+			complexity: "O(1)",
+			handler: func(ch CommandHandler, _ *Client, _ data.Array) data.Message {
+				return handleRole(ch.replication)
+			},
+		},
+		{
+			name:       "wait",
+			arity:      3,
+			flags:      []string{CMD_FLAG_NOSCRIPT},
+			categories: []string{"connection"},
+			summary:    "Blocks until the asynchronous replication of all preceding write commands sent by the connection is completed.",
+			since:      "3.0.0",
+			group:      "generic",
+			complexity: "O(1)",
+			args: []commandArg{
+				{name: "numreplicas", typ: ARG_TYPE_INTEGER},
+				{name: "timeout", typ: ARG_TYPE_INTEGER},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleWait(cmdArray, ch.replication)
+			},
+		},
+		{
+			name:       "info",
+			arity:      -1,
+			flags:      []string{CMD_FLAG_LOADING, CMD_FLAG_STALE},
+			categories: []string{"dangerous"},
+			summary:    "Returns information and statistics about the server.",
+			since:      "1.0.0",
+			group:      "server",
+			complexity: "O(1)",
+			args:       []commandArg{{name: "section", typ: ARG_TYPE_STRING, optional: true, multiple: true}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleInfo(ch, cmdArray)
+			},
+		},
+		{
+			name:       "dump",
+			arity:      2,
+			flags:      []string{CMD_FLAG_READONLY},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"keyspace"},
+			summary:    "Returns a serialized representation of the value stored at a key.",
+			since:      "2.6.0",
+			group:      "generic",
+			complexity: "O(1) to access the key and additional O(N*M) to serialize it, where N is the number of Redis objects composing the value and M their average size.",
+			args:       []commandArg{{name: "key", typ: ARG_TYPE_KEY}},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleDump(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "restore",
+			arity:      -4,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"keyspace", "dangerous"},
+			summary:    "Creates a key from the serialized representation of a value.",
+			since:      "2.6.0",
+			group:      "generic",
+			complexity: "O(1) to create the new key and additional O(N*M) to reconstruct the serialized value, where N is the number of Redis objects composing the value and M their average size.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "ttl", typ: ARG_TYPE_INTEGER},
+				{name: "serialized-value", typ: ARG_TYPE_STRING},
+				{name: "replace", typ: ARG_TYPE_TOKEN, token: "REPLACE", optional: true},
+				{name: "absttl", typ: ARG_TYPE_TOKEN, token: "ABSTTL", optional: true},
+				{name: "seconds", typ: ARG_TYPE_INTEGER, token: "IDLETIME", optional: true},
+				{name: "frequency", typ: ARG_TYPE_INTEGER, token: "FREQ", optional: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleRestore(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "restore-asking",
+			arity:      -4,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_DENYOOM, CMD_FLAG_ASKING},
+			firstKey:   1,
+			lastKey:    1,
+			keyStep:    1,
+			categories: []string{"keyspace", "dangerous"},
+			summary:    "An internal command for migrating keys in a cluster.",
+			since:      "3.0.0",
+			group:      "server",
+			complexity: "O(1) to create the new key and additional O(N*M) to reconstruct the serialized value, where N is the number of Redis objects composing the value and M their average size.",
+			args: []commandArg{
+				{name: "key", typ: ARG_TYPE_KEY},
+				{name: "ttl", typ: ARG_TYPE_INTEGER},
+				{name: "serialized-value", typ: ARG_TYPE_STRING},
+				{name: "replace", typ: ARG_TYPE_TOKEN, token: "REPLACE", optional: true},
+				{name: "absttl", typ: ARG_TYPE_TOKEN, token: "ABSTTL", optional: true},
+				{name: "seconds", typ: ARG_TYPE_INTEGER, token: "IDLETIME", optional: true},
+				{name: "frequency", typ: ARG_TYPE_INTEGER, token: "FREQ", optional: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleRestore(cmdArray, ch.strgEngine)
+			},
+		},
+		{
+			name:       "migrate",
+			arity:      -6,
+			flags:      []string{CMD_FLAG_WRITE, CMD_FLAG_MOVABLE},
+			categories: []string{"keyspace", "dangerous"},
+			getKeys:    migrateKeys,
+			propagate:  propagateMigrate,
+			summary:    "Atomically transfers a key from one Redis instance to another.",
+			since:      "2.6.0",
+			group:      "generic",
+			complexity: "This command actually executes a DUMP+DEL in the source instance, and a RESTORE in the target instance. See the pages of these commands for time complexity. Also an O(N) data transfer between the two instances is performed.",
+			args: []commandArg{
+				{name: "host", typ: ARG_TYPE_STRING},
+				{name: "port", typ: ARG_TYPE_INTEGER},
+				{name: "key-selector", typ: ARG_TYPE_ONEOF, args: []commandArg{
+					{name: "key", typ: ARG_TYPE_KEY},
+					{name: "empty-string", typ: ARG_TYPE_TOKEN, token: "\"\""},
+				}},
+				{name: "destination-db", typ: ARG_TYPE_INTEGER},
+				{name: "timeout", typ: ARG_TYPE_INTEGER},
+				{name: "copy", typ: ARG_TYPE_TOKEN, token: "COPY", optional: true},
+				{name: "replace", typ: ARG_TYPE_TOKEN, token: "REPLACE", optional: true},
+				{name: "authentication", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+					{name: "auth", typ: ARG_TYPE_STRING, token: "AUTH"},
+					{name: "auth2", typ: ARG_TYPE_BLOCK, token: "AUTH2", args: []commandArg{
+						{name: "username", typ: ARG_TYPE_STRING},
+						{name: "password", typ: ARG_TYPE_STRING},
+					}},
+				}},
+				{name: "keys", typ: ARG_TYPE_KEY, token: "KEYS", optional: true, multiple: true},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleMigrate(ch, cmdArray)
+			},
+		},
+		{
+			name:       "eval",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_STALE, CMD_FLAG_MOVABLE},
+			categories: []string{"scripting"},
+			getKeys:    scriptKeys,
+			summary:    "Executes a server-side Lua script.",
+			since:      "2.6.0",
+			group:      "scripting",
+			complexity: "Depends on the script that is executed.",
+			args: []commandArg{
+				{name: "script", typ: ARG_TYPE_STRING},
+				{name: "numkeys", typ: ARG_TYPE_INTEGER},
+				{name: "key", typ: ARG_TYPE_KEY, optional: true, multiple: true},
+				{name: "arg", typ: ARG_TYPE_STRING, optional: true, multiple: true},
+			},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleEval(ch, client, cmdArray, false)
+			},
+		},
+		{
+			name:       "evalsha",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_STALE, CMD_FLAG_MOVABLE},
+			categories: []string{"scripting"},
+			getKeys:    scriptKeys,
+			summary:    "Executes a server-side Lua script by SHA1 digest.",
+			since:      "2.6.0",
+			group:      "scripting",
+			complexity: "Depends on the script that is executed.",
+			args: []commandArg{
+				{name: "sha1", typ: ARG_TYPE_STRING},
+				{name: "numkeys", typ: ARG_TYPE_INTEGER},
+				{name: "key", typ: ARG_TYPE_KEY, optional: true, multiple: true},
+				{name: "arg", typ: ARG_TYPE_STRING, optional: true, multiple: true},
+			},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleEval(ch, client, cmdArray, true)
+			},
+		},
+		{
+			name:       "script",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_NOSCRIPT},
+			categories: []string{"scripting"},
+			summary:    "A container for Lua scripts management commands.",
+			since:      "2.6.0",
+			group:      "scripting",
+			subcommands: []*commandSpec{
+				{
+					name:       "script|exists",
+					arity:      -3,
+					flags:      []string{CMD_FLAG_NOSCRIPT},
+					categories: []string{"scripting"},
+					summary:    "Determines whether server-side Lua scripts exist in the script cache.",
+					since:      "2.6.0",
+					group:      "scripting",
+					complexity: "O(N) with N being the number of scripts to check (so checking a single script is an O(1) operation).",
+					args:       []commandArg{{name: "sha1", typ: ARG_TYPE_STRING, multiple: true}},
+				},
+				{
+					name:       "script|flush",
+					arity:      -2,
+					flags:      []string{CMD_FLAG_NOSCRIPT},
+					categories: []string{"scripting"},
+					summary:    "Removes all server-side Lua scripts from the script cache.",
+					since:      "2.6.0",
+					group:      "scripting",
+					complexity: "O(N) with N being the number of scripts in cache",
+					args: []commandArg{{name: "flush-type", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+						{name: "async", typ: ARG_TYPE_TOKEN, token: "ASYNC"},
+						{name: "sync", typ: ARG_TYPE_TOKEN, token: "SYNC"},
+					}}},
+				},
+				{
+					name:       "script|kill",
+					arity:      2,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_ALLOWBUSY},
+					categories: []string{"scripting"},
+					summary:    "Terminates a server-side Lua script during execution.",
+					since:      "2.6.0",
+					group:      "scripting",
+					complexity: "O(1)",
+				},
+				{
+					name:       "script|load",
+					arity:      3,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_STALE},
+					categories: []string{"scripting"},
+					summary:    "Loads a server-side Lua script to the script cache.",
+					since:      "2.6.0",
+					group:      "scripting",
+					complexity: "O(N) with N being the length in bytes of the script body.",
+					args:       []commandArg{{name: "script", typ: ARG_TYPE_STRING}},
+				},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleScript(ch, cmdArray)
+			},
+		},
+		{
+			name:       "fcall",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_STALE, CMD_FLAG_MOVABLE},
+			categories: []string{"scripting"},
+			getKeys:    scriptKeys,
+			summary:    "Invokes a function.",
+			since:      "7.0.0",
+			group:      "scripting",
+			complexity: "Depends on the function that is executed.",
+			args: []commandArg{
+				{name: "function", typ: ARG_TYPE_STRING},
+				{name: "numkeys", typ: ARG_TYPE_INTEGER},
+				{name: "key", typ: ARG_TYPE_KEY, optional: true, multiple: true},
+				{name: "arg", typ: ARG_TYPE_STRING, optional: true, multiple: true},
+			},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleFcall(ch, client, cmdArray, false)
+			},
+		},
+		{
+			name:       "fcall_ro",
+			arity:      -3,
+			flags:      []string{CMD_FLAG_READONLY, CMD_FLAG_NOSCRIPT, CMD_FLAG_STALE, CMD_FLAG_MOVABLE},
+			categories: []string{"scripting"},
+			getKeys:    scriptKeys,
+			summary:    "Invokes a read-only function.",
+			since:      "7.0.0",
+			group:      "scripting",
+			complexity: "Depends on the function that is executed.",
+			args: []commandArg{
+				{name: "function", typ: ARG_TYPE_STRING},
+				{name: "numkeys", typ: ARG_TYPE_INTEGER},
+				{name: "key", typ: ARG_TYPE_KEY, optional: true, multiple: true},
+				{name: "arg", typ: ARG_TYPE_STRING, optional: true, multiple: true},
+			},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleFcall(ch, client, cmdArray, true)
+			},
+		},
+		{
+			name:       "function",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_NOSCRIPT},
+			categories: []string{"scripting"},
+			summary:    "A container for function commands.",
+			since:      "7.0.0",
+			group:      "scripting",
+			subcommands: []*commandSpec{
+				{
+					name:       "function|delete",
+					arity:      3,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_WRITE},
+					categories: []string{"scripting"},
+					summary:    "Deletes a library and its functions.",
+					since:      "7.0.0",
+					group:      "scripting",
+					complexity: "O(1)",
+					args:       []commandArg{{name: "library-name", typ: ARG_TYPE_STRING}},
+				},
+				{
+					name:       "function|dump",
+					arity:      2,
+					flags:      []string{CMD_FLAG_NOSCRIPT},
+					categories: []string{"scripting"},
+					summary:    "Dumps all libraries into a serialized binary payload.",
+					since:      "7.0.0",
+					group:      "scripting",
+					complexity: "O(N) where N is the number of functions",
+				},
+				{
+					name:       "function|flush",
+					arity:      -2,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_WRITE},
+					categories: []string{"scripting"},
+					summary:    "Deletes all libraries and functions.",
+					since:      "7.0.0",
+					group:      "scripting",
+					complexity: "O(N) where N is the number of functions deleted",
+					args: []commandArg{{name: "flush-type", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+						{name: "async", typ: ARG_TYPE_TOKEN, token: "ASYNC"},
+						{name: "sync", typ: ARG_TYPE_TOKEN, token: "SYNC"},
+					}}},
+				},
+				{
+					name:       "function|kill",
+					arity:      2,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_ALLOWBUSY},
+					categories: []string{"scripting"},
+					summary:    "Terminates a function during execution.",
+					since:      "7.0.0",
+					group:      "scripting",
+					complexity: "O(1)",
+				},
+				{
+					name:       "function|list",
+					arity:      -2,
+					flags:      []string{CMD_FLAG_NOSCRIPT},
+					categories: []string{"scripting"},
+					summary:    "Returns information about all libraries.",
+					since:      "7.0.0",
+					group:      "scripting",
+					complexity: "O(N) where N is the number of functions",
+					args: []commandArg{
+						{name: "library-name-pattern", typ: ARG_TYPE_STRING, token: "LIBRARYNAME", optional: true},
+						{name: "withcode", typ: ARG_TYPE_TOKEN, token: "WITHCODE", optional: true},
+					},
+				},
+				{
+					name:       "function|load",
+					arity:      -3,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_WRITE},
+					categories: []string{"scripting"},
+					summary:    "Creates a library.",
+					since:      "7.0.0",
+					group:      "scripting",
+					complexity: "O(1) (considering compilation time is redundant)",
+					args: []commandArg{
+						{name: "replace", typ: ARG_TYPE_TOKEN, token: "REPLACE", optional: true},
+						{name: "function-code", typ: ARG_TYPE_STRING},
+					},
+				},
+				{
+					name:       "function|restore",
+					arity:      -3,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_WRITE},
+					categories: []string{"scripting"},
+					summary:    "Restores all libraries from a payload.",
+					since:      "7.0.0",
+					group:      "scripting",
+					complexity: "O(N) where N is the number of functions on the payload",
+					args: []commandArg{
+						{name: "serialized-value", typ: ARG_TYPE_STRING},
+						{name: "policy", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+							{name: "flush", typ: ARG_TYPE_TOKEN, token: "FLUSH"},
+							{name: "append", typ: ARG_TYPE_TOKEN, token: "APPEND"},
+							{name: "replace", typ: ARG_TYPE_TOKEN, token: "REPLACE"},
+						}},
+					},
+				},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleFunction(ch, cmdArray)
+			},
+		},
+		{
+			name:        "subscribe",
+			arity:       -2,
+			flags:       []string{CMD_FLAG_PUBSUB, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+			getChannels: subscribeChannels,
+			summary:     "Listens for messages published to channels.",
+			since:       "2.0.0",
+			group:       "pubsub",
+			complexity:  "O(N) where N is the number of channels to subscribe to.",
+			args:        []commandArg{{name: "channel", typ: ARG_TYPE_STRING, multiple: true}},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleSubscribe(ch, client, cmdArray, false)
+			},
+		},
+		{
+			name:        "psubscribe",
+			arity:       -2,
+			flags:       []string{CMD_FLAG_PUBSUB, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+			getChannels: subscribeChannels,
+			summary:     "Listens for messages published to channels that match one or more patterns.",
+			since:       "2.0.0",
+			group:       "pubsub",
+			complexity:  "O(N) where N is the number of patterns to subscribe to.",
+			args:        []commandArg{{name: "pattern", typ: ARG_TYPE_PATTERN, multiple: true}},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleSubscribe(ch, client, cmdArray, true)
+			},
+		},
+		{
+			name:       "unsubscribe",
+			arity:      -1,
+			flags:      []string{CMD_FLAG_PUBSUB, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+			summary:    "Stops listening to messages posted to channels.",
+			since:      "2.0.0",
+			group:      "pubsub",
+			complexity: "O(N) where N is the number of channels to unsubscribe.",
+			args:       []commandArg{{name: "channel", typ: ARG_TYPE_STRING, optional: true, multiple: true}},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleUnsubscribe(ch, client, cmdArray, false)
+			},
+		},
+		{
+			name:       "punsubscribe",
+			arity:      -1,
+			flags:      []string{CMD_FLAG_PUBSUB, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+			summary:    "Stops listening to messages published to channels that match one or more patterns.",
+			since:      "2.0.0",
+			group:      "pubsub",
+			complexity: "O(N) where N is the number of patterns to unsubscribe.",
+			args:       []commandArg{{name: "pattern", typ: ARG_TYPE_PATTERN, optional: true, multiple: true}},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleUnsubscribe(ch, client, cmdArray, true)
+			},
+		},
+		{
+			name:        "publish",
+			arity:       3,
+			flags:       []string{CMD_FLAG_PUBSUB, CMD_FLAG_LOADING, CMD_FLAG_STALE, CMD_FLAG_FAST},
+			getChannels: publishChannels,
+			summary:     "Posts a message to a channel.",
+			since:       "2.0.0",
+			group:       "pubsub",
+			complexity: "O(N+M) where N is the number of clients subscribed to the receiving channel and M is " +
+				"the total number of subscribed patterns (by any client).",
+			args: []commandArg{
+				{name: "channel", typ: ARG_TYPE_STRING},
+				{name: "message", typ: ARG_TYPE_STRING},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handlePublish(ch, cmdArray)
+			},
+		},
+		{
+			name:    "pubsub",
+			arity:   -2,
+			summary: "A container for Pub/Sub commands.",
+			since:   "2.8.0",
+			group:   "pubsub",
+			subcommands: []*commandSpec{
+				{
+					name:    "pubsub|channels",
+					arity:   -2,
+					flags:   []string{CMD_FLAG_PUBSUB, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary: "Returns the active channels.",
+					since:   "2.8.0",
+					group:   "pubsub",
+					complexity: "O(N) where N is the number of active channels, and assuming constant time pattern " +
+						"matching (relatively short channels and patterns)",
+					args: []commandArg{{name: "pattern", typ: ARG_TYPE_PATTERN, optional: true}},
+				},
+				{
+					name:       "pubsub|numpat",
+					arity:      2,
+					flags:      []string{CMD_FLAG_PUBSUB, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Returns a count of unique pattern subscriptions.",
+					since:      "2.8.0",
+					group:      "pubsub",
+					complexity: "O(1)",
+				},
+				{
+					name:       "pubsub|numsub",
+					arity:      -2,
+					flags:      []string{CMD_FLAG_PUBSUB, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Returns a count of subscribers to channels.",
+					since:      "2.8.0",
+					group:      "pubsub",
+					complexity: "O(N) for the NUMSUB subcommand, where N is the number of requested channels",
+					args:       []commandArg{{name: "channel", typ: ARG_TYPE_STRING, optional: true, multiple: true}},
+				},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handlePubSub(ch, cmdArray)
+			},
+		},
+		{
+			name:       "asking",
+			arity:      1,
+			flags:      []string{CMD_FLAG_FAST},
+			categories: []string{"connection"},
+			summary:    "Signals that a cluster client is following an -ASK redirect.",
+			since:      "3.0.0",
+			group:      "cluster",
+			complexity: "O(1)",
+			handler: func(ch CommandHandler, client *Client, _ data.Array) data.Message {
+				return handleAsking(ch, client)
+			},
+		},
+		{
+			name:    "cluster",
+			arity:   -2,
+			summary: "A container for Redis Cluster commands.",
+			since:   "3.0.0",
+			group:   "cluster",
+			subcommands: []*commandSpec{
+				{
+					name:       "cluster|addslots",
+					arity:      -3,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_STALE},
+					summary:    "Assigns new hash slots to a node.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(N) where N is the total number of hash slot arguments",
+					args: []commandArg{
+						{name: "slot", typ: ARG_TYPE_INTEGER, multiple: true},
+					},
+				},
+				{
+					name:       "cluster|addslotsrange",
+					arity:      -4,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_STALE},
+					summary:    "Assigns new hash slot ranges to a node.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(N) where N is the total number of the slots between the start slot and end slot arguments.",
+					args: []commandArg{
+						{name: "range", typ: ARG_TYPE_BLOCK, multiple: true, args: []commandArg{
+							{name: "start-slot", typ: ARG_TYPE_INTEGER},
+							{name: "end-slot", typ: ARG_TYPE_INTEGER},
+						}},
+					},
+				},
+				{
+					name:       "cluster|countkeysinslot",
+					arity:      3,
+					flags:      []string{CMD_FLAG_STALE},
+					summary:    "Returns the number of keys in a hash slot.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(N) where N is the number of keys in the database",
+					args: []commandArg{
+						{name: "slot", typ: ARG_TYPE_INTEGER},
+					},
+				},
+				{
+					name:       "cluster|delslots",
+					arity:      -3,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_STALE},
+					summary:    "Sets hash slots as unbound for a node.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(N) where N is the total number of hash slot arguments",
+					args: []commandArg{
+						{name: "slot", typ: ARG_TYPE_INTEGER, multiple: true},
+					},
+				},
+				{
+					name:       "cluster|delslotsrange",
+					arity:      -4,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_STALE},
+					summary:    "Sets hash slot ranges as unbound for a node.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(N) where N is the total number of the slots between the start slot and end slot arguments.",
+					args: []commandArg{
+						{name: "range", typ: ARG_TYPE_BLOCK, multiple: true, args: []commandArg{
+							{name: "start-slot", typ: ARG_TYPE_INTEGER},
+							{name: "end-slot", typ: ARG_TYPE_INTEGER},
+						}},
+					},
+				},
+				{
+					name:       "cluster|getkeysinslot",
+					arity:      4,
+					flags:      []string{CMD_FLAG_STALE},
+					summary:    "Returns the key names in a hash slot.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(N) where N is the number of keys in the database",
+					args: []commandArg{
+						{name: "slot", typ: ARG_TYPE_INTEGER},
+						{name: "count", typ: ARG_TYPE_INTEGER},
+					},
+				},
+				{
+					name:       "cluster|info",
+					arity:      2,
+					flags:      []string{CMD_FLAG_STALE},
+					summary:    "Returns information about the state of a node.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(1)",
+				},
+				{
+					name:       "cluster|keyslot",
+					arity:      3,
+					flags:      []string{CMD_FLAG_STALE},
+					summary:    "Returns the hash slot for a key.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(N) where N is the number of bytes in the key",
+					args: []commandArg{
+						{name: "key", typ: ARG_TYPE_STRING},
+					},
+				},
+				{
+					name:       "cluster|meet",
+					arity:      -4,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_STALE},
+					summary:    "Forces a node to handshake with another node.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(1)",
+					args: []commandArg{
+						{name: "ip", typ: ARG_TYPE_STRING},
+						{name: "port", typ: ARG_TYPE_INTEGER},
+						{name: "cluster-bus-port", typ: ARG_TYPE_INTEGER, optional: true},
+					},
+				},
+				{
+					name:       "cluster|myid",
+					arity:      2,
+					flags:      []string{CMD_FLAG_STALE},
+					summary:    "Returns the ID of a node.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(1)",
+				},
+				{
+					name:       "cluster|nodes",
+					arity:      2,
+					flags:      []string{CMD_FLAG_STALE},
+					summary:    "Returns the cluster configuration for a node.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(N) where N is the total number of Cluster nodes",
+				},
+				{
+					name:       "cluster|setslot",
+					arity:      -4,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_STALE},
+					summary:    "Binds a hash slot to a node.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(1)",
+					args: []commandArg{
+						{name: "slot", typ: ARG_TYPE_INTEGER},
+						{name: "subcommand", typ: ARG_TYPE_ONEOF, args: []commandArg{
+							{name: "importing", typ: ARG_TYPE_STRING, token: "IMPORTING"},
+							{name: "migrating", typ: ARG_TYPE_STRING, token: "MIGRATING"},
+							{name: "node", typ: ARG_TYPE_STRING, token: "NODE"},
+							{name: "stable", typ: ARG_TYPE_TOKEN, token: "STABLE"},
+						}},
+					},
+				},
+				{
+					name:       "cluster|shards",
+					arity:      2,
+					flags:      []string{CMD_FLAG_STALE},
+					summary:    "Returns the mapping of cluster slots to shards.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(N) where N is the total number of cluster nodes",
+				},
+				{
+					name:       "cluster|slots",
+					arity:      2,
+					flags:      []string{CMD_FLAG_STALE},
+					summary:    "Returns the mapping of cluster slots to nodes.",
+					since:      "3.0.0",
+					group:      "cluster",
+					complexity: "O(N) where N is the total number of Cluster nodes",
+				},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleCluster(ch, cmdArray)
+			},
+		},
+		{
+			name:    "config",
+			arity:   -2,
+			flags:   []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+			summary: "A container for server configuration commands.",
+			since:   "2.0.0",
+			group:   "server",
+			subcommands: []*commandSpec{
+				{
+					name:       "config|get",
+					arity:      -2,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Returns the effective values of configuration parameters.",
+					since:      "2.0.0",
+					group:      "server",
+					complexity: "O(N) when N is the number of configuration parameters provided",
+					args:       []commandArg{{name: "parameter", typ: ARG_TYPE_STRING, optional: true, multiple: true}},
+				},
+				{
+					name:       "config|set",
+					arity:      -4,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Sets configuration parameters in-flight.",
+					since:      "2.0.0",
+					group:      "server",
+					complexity: "O(N) when N is the number of configuration parameters provided",
+					args: []commandArg{{name: "data", typ: ARG_TYPE_BLOCK, multiple: true, args: []commandArg{
+						{name: "parameter", typ: ARG_TYPE_STRING},
+						{name: "value", typ: ARG_TYPE_STRING},
+					}}},
+				},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleConfig(cmdArray, ch.cfg)
+			},
+		},
+		{
+			name:       "client",
+			arity:      -2,
+			flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+			categories: []string{"connection"},
+			summary:    "A container for client connection commands.",
+			since:      "2.4.0",
+			group:      "connection",
+			subcommands: []*commandSpec{
+				{
+					name:       "client|id",
+					arity:      2,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Returns the unique client ID of the connection.",
+					since:      "5.0.0",
+					group:      "connection",
+					complexity: "O(1)",
+				},
+				{
+					name:       "client|setname",
+					arity:      3,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Sets the connection name.",
+					since:      "2.6.9",
+					group:      "connection",
+					complexity: "O(1)",
+					args:       []commandArg{{name: "connection-name", typ: ARG_TYPE_STRING}},
+				},
+				{
+					name:       "client|getname",
+					arity:      2,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Returns the name of the connection.",
+					since:      "2.6.9",
+					group:      "connection",
+					complexity: "O(1)",
+				},
+				{
+					name:       "client|info",
+					arity:      2,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Returns information about the connection.",
+					since:      "6.2.0",
+					group:      "connection",
+					complexity: "O(1)",
+				},
+				{
+					name:       "client|list",
+					arity:      -2,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Lists open connections.",
+					since:      "2.4.0",
+					group:      "connection",
+					complexity: "O(N) where N is the number of client connections",
+					args: []commandArg{
+						{name: "client-type", typ: ARG_TYPE_ONEOF, token: "TYPE", optional: true, args: []commandArg{
+							{name: "normal", typ: ARG_TYPE_TOKEN, token: "NORMAL"},
+							{name: "master", typ: ARG_TYPE_TOKEN, token: "MASTER"},
+							{name: "replica", typ: ARG_TYPE_TOKEN, token: "REPLICA"},
+							{name: "pubsub", typ: ARG_TYPE_TOKEN, token: "PUBSUB"},
+						}},
+						{name: "client-id", typ: ARG_TYPE_INTEGER, token: "ID", optional: true, multiple: true},
+					},
+				},
+				{
+					name:       "client|kill",
+					arity:      -3,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Terminates open connections.",
+					since:      "2.4.0",
+					group:      "connection",
+					complexity: "O(N) where N is the number of client connections",
+					args: []commandArg{{name: "filter", typ: ARG_TYPE_ONEOF, args: []commandArg{
+						{name: "ip:port", typ: ARG_TYPE_STRING},
+						{name: "new-format", typ: ARG_TYPE_ONEOF, multiple: true, args: []commandArg{
+							{name: "client-id", typ: ARG_TYPE_INTEGER, token: "ID", optional: true},
+							{name: "client-type", typ: ARG_TYPE_ONEOF, token: "TYPE", optional: true, args: []commandArg{
+								{name: "normal", typ: ARG_TYPE_TOKEN, token: "NORMAL"},
+								{name: "master", typ: ARG_TYPE_TOKEN, token: "MASTER"},
+								{name: "replica", typ: ARG_TYPE_TOKEN, token: "REPLICA"},
+								{name: "pubsub", typ: ARG_TYPE_TOKEN, token: "PUBSUB"},
+							}},
+							{name: "username", typ: ARG_TYPE_STRING, token: "USER", optional: true},
+							{name: "addr", typ: ARG_TYPE_STRING, token: "ADDR", optional: true},
+							{name: "laddr", typ: ARG_TYPE_STRING, token: "LADDR", optional: true},
+							{name: "skipme", typ: ARG_TYPE_ONEOF, token: "SKIPME", optional: true, args: []commandArg{
+								{name: "yes", typ: ARG_TYPE_TOKEN, token: "YES"},
+								{name: "no", typ: ARG_TYPE_TOKEN, token: "NO"},
+							}},
+						}},
+					}}},
+				},
+				{
+					name:       "client|pause",
+					arity:      -3,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Suspends commands processing.",
+					since:      "3.0.0",
+					group:      "connection",
+					complexity: "O(1)",
+					args: []commandArg{
+						{name: "timeout", typ: ARG_TYPE_INTEGER},
+						{name: "mode", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+							{name: "write", typ: ARG_TYPE_TOKEN, token: CLIENT_PAUSE_MODE_WRITE},
+							{name: "all", typ: ARG_TYPE_TOKEN, token: CLIENT_PAUSE_MODE_ALL},
+						}},
+					},
+				},
+				{
+					name:       "client|unpause",
+					arity:      2,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Resumes processing commands from paused clients.",
+					since:      "6.2.0",
+					group:      "connection",
+					complexity: "O(N) Where N is the number of paused clients",
+				},
+				{
+					name:       "client|tracking",
+					arity:      -3,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Controls server-assisted client-side caching for the connection.",
+					since:      "6.0.0",
+					group:      "connection",
+					complexity: "O(1). Some options may introduce additional complexity.",
+					args: []commandArg{
+						{name: "status", typ: ARG_TYPE_ONEOF, args: []commandArg{
+							{name: "on", typ: ARG_TYPE_TOKEN, token: "ON"},
+							{name: "off", typ: ARG_TYPE_TOKEN, token: "OFF"},
+						}},
+						{name: "client-id", typ: ARG_TYPE_INTEGER, token: "REDIRECT", optional: true},
+						{name: "prefix", typ: ARG_TYPE_STRING, token: "PREFIX", optional: true, multiple: true},
+						{name: "bcast", typ: ARG_TYPE_TOKEN, token: "BCAST", optional: true},
+						{name: "optin", typ: ARG_TYPE_TOKEN, token: "OPTIN", optional: true},
+						{name: "optout", typ: ARG_TYPE_TOKEN, token: "OPTOUT", optional: true},
+						{name: "noloop", typ: ARG_TYPE_TOKEN, token: "NOLOOP", optional: true},
+					},
+				},
+				{
+					name:       "client|caching",
+					arity:      3,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Instructs the server whether to track the keys in the next request.",
+					since:      "6.0.0",
+					group:      "connection",
+					complexity: "O(1)",
+					args: []commandArg{{name: "mode", typ: ARG_TYPE_ONEOF, args: []commandArg{
+						{name: "yes", typ: ARG_TYPE_TOKEN, token: "YES"},
+						{name: "no", typ: ARG_TYPE_TOKEN, token: "NO"},
+					}}},
+				},
+				{
+					name:       "client|getredir",
+					arity:      2,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Returns the client ID to which the connection's tracking notifications are redirected.",
+					since:      "6.0.0",
+					group:      "connection",
+					complexity: "O(1)",
+				},
+				{
+					name:       "client|trackinginfo",
+					arity:      2,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Returns information about server-assisted client-side caching for the connection.",
+					since:      "6.2.0",
+					group:      "connection",
+					complexity: "O(1)",
+				},
+			},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleClient(cmdArray, client, ch.clients, ch.tracking)
+			},
+		},
+		{
+			name:    "acl",
+			arity:   -2,
+			flags:   []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+			summary: "A container for Access List Control commands.",
+			since:   "6.0.0",
+			group:   "server",
+			subcommands: []*commandSpec{
+				{
+					name:       "acl|setuser",
+					arity:      -3,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Creates and modifies an ACL user and its rules.",
+					since:      "6.0.0",
+					group:      "server",
+					complexity: "O(N). Where N is the number of rules provided.",
+					args: []commandArg{
+						{name: "username", typ: ARG_TYPE_STRING},
+						{name: "rule", typ: ARG_TYPE_STRING, optional: true, multiple: true},
+					},
+				},
+				{
+					name:       "acl|getuser",
+					arity:      3,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Lists the ACL rules of a user.",
+					since:      "6.0.0",
+					group:      "server",
+					complexity: "O(N). Where N is the number of password, command and pattern rules that the user has.",
+					args:       []commandArg{{name: "username", typ: ARG_TYPE_STRING}},
+				},
+				{
+					name:       "acl|deluser",
+					arity:      -3,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Deletes ACL users, and terminates their connections.",
+					since:      "6.0.0",
+					group:      "server",
+					complexity: "O(1) amortized time considering the typical user.",
+					args:       []commandArg{{name: "username", typ: ARG_TYPE_STRING, multiple: true}},
+				},
+				{
+					name:       "acl|list",
+					arity:      2,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Dumps the effective rules in ACL file format.",
+					since:      "6.0.0",
+					group:      "server",
+					complexity: "O(N). Where N is the number of configured users.",
+				},
+				{
+					name:       "acl|users",
+					arity:      2,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Lists all ACL users.",
+					since:      "6.0.0",
+					group:      "server",
+					complexity: "O(N). Where N is the number of configured users.",
+				},
+				{
+					name:       "acl|whoami",
+					arity:      2,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Returns the authenticated username of the current connection.",
+					since:      "6.0.0",
+					group:      "server",
+					complexity: "O(1)",
+				},
+				{
+					name:       "acl|cat",
+					arity:      -2,
+					flags:      []string{CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Lists the ACL categories, or the commands inside a category.",
+					since:      "6.0.0",
+					group:      "server",
+					complexity: "O(1) since the categories and commands are a fixed set.",
+					args:       []commandArg{{name: "category", typ: ARG_TYPE_STRING, optional: true}},
+				},
+				{
+					name:       "acl|load",
+					arity:      2,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Reloads the rules from the configured ACL file.",
+					since:      "6.0.0",
+					group:      "server",
+					complexity: "O(N). Where N is the number of configured users.",
+				},
+				{
+					name:       "acl|save",
+					arity:      2,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Saves the effective ACL rules in the configured ACL file.",
+					since:      "6.0.0",
+					group:      "server",
+					complexity: "O(N). Where N is the number of configured users.",
+				},
+			},
+			handler: func(ch CommandHandler, client *Client, cmdArray data.Array) data.Message {
+				return handleAcl(cmdArray, client, ch)
+			},
+		},
+		{
+			name:    "slowlog",
+			arity:   -2,
+			flags:   []string{CMD_FLAG_ADMIN, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+			summary: "A container for slow log commands.",
+			since:   "2.2.12",
+			group:   "server",
+			subcommands: []*commandSpec{
+				{
+					name:       "slowlog|get",
+					arity:      -2,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Returns the slow log's entries.",
+					since:      "2.2.12",
+					group:      "server",
+					complexity: "O(N) where N is the number of entries returned",
+					args:       []commandArg{{name: "count", typ: ARG_TYPE_INTEGER, optional: true}},
+				},
+				{
+					name:       "slowlog|len",
+					arity:      2,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Returns the number of entries in the slow log.",
+					since:      "2.2.12",
+					group:      "server",
+					complexity: "O(1)",
+				},
+				{
+					name:       "slowlog|reset",
+					arity:      2,
+					flags:      []string{CMD_FLAG_ADMIN, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					summary:    "Clears all entries from the slow log.",
+					since:      "2.2.12",
+					group:      "server",
+					complexity: "O(N) where N is the number of entries in the slowlog",
+				},
+			},
+			handler: func(ch CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleSlowLog(cmdArray, ch.slowLog)
+			},
+		},
+		{
+			name:    "monitor",
+			arity:   1,
+			flags:   []string{CMD_FLAG_ADMIN, CMD_FLAG_NOSCRIPT, CMD_FLAG_LOADING, CMD_FLAG_STALE},
+			summary: "Listens for all requests received by the server in real-time.",
+			since:   "1.0.0",
+			group:   "server",
+			handler: func(ch CommandHandler, client *Client, _ data.Array) data.Message {
+				return handleMonitor(client, ch.monitors)
+			},
+		},
+		{
+			name:       "command",
+			arity:      -1,
+			flags:      []string{CMD_FLAG_LOADING, CMD_FLAG_STALE},
+			categories: []string{"connection"},
+			summary:    "Returns detailed information about all commands.",
+			since:      "2.8.13",
+			group:      "server",
+			complexity: "O(N) where N is the total number of Redis commands",
+			subcommands: []*commandSpec{
+				{
+					name:       "command|count",
+					arity:      2,
+					flags:      []string{CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Returns a count of commands.",
+					since:      "2.8.13",
+					group:      "server",
+					complexity: "O(1)",
+				},
+				{
+					name:       "command|info",
+					arity:      -2,
+					flags:      []string{CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Returns information about one, multiple or all commands.",
+					since:      "2.8.13",
+					group:      "server",
+					complexity: "O(N) where N is the number of commands to look up",
+					args:       []commandArg{{name: "command-name", typ: ARG_TYPE_STRING, optional: true, multiple: true}},
+				},
+				{
+					name:       "command|docs",
+					arity:      -2,
+					flags:      []string{CMD_FLAG_LOADING, CMD_FLAG_STALE},
+					categories: []string{"connection"},
+					summary:    "Returns documentary information about one, multiple or all commands.",
+					since:      "7.0.0",
+					group:      "server",
+					complexity: "O(N) where N is the number of commands to look up",
+					args:       []commandArg{{name: "command-name", typ: ARG_TYPE_STRING, optional: true, multiple: true}},
+				},
+			},
+			handler: func(_ CommandHandler, _ *Client, cmdArray data.Array) data.Message {
+				return handleCommand(cmdArray)
+			},
+		},
+	}
+}
+
+// geoUnitArgs documents the distance units accepted by the geo commands.
+func geoUnitArgs() []commandArg {
+	return []commandArg{
+		{name: "m", typ: ARG_TYPE_TOKEN, token: "M"},
+		{name: "km", typ: ARG_TYPE_TOKEN, token: "KM"},
+		{name: "ft", typ: ARG_TYPE_TOKEN, token: "FT"},
+		{name: "mi", typ: ARG_TYPE_TOKEN, token: "MI"},
+	}
+}
+
+// geoSearchArgs documents the search options shared by GEOSEARCH and GEOSEARCHSTORE.
+func geoSearchArgs() []commandArg {
+	return []commandArg{
+		{name: "from", typ: ARG_TYPE_ONEOF, args: []commandArg{
+			{name: "member", typ: ARG_TYPE_STRING, token: "FROMMEMBER"},
+			{name: "fromlonlat", typ: ARG_TYPE_BLOCK, token: "FROMLONLAT", args: []commandArg{
+				{name: "longitude", typ: ARG_TYPE_DOUBLE},
+				{name: "latitude", typ: ARG_TYPE_DOUBLE},
+			}},
+		}},
+		{name: "by", typ: ARG_TYPE_ONEOF, args: []commandArg{
+			{name: "circle", typ: ARG_TYPE_BLOCK, args: []commandArg{
+				{name: "radius", typ: ARG_TYPE_DOUBLE, token: "BYRADIUS"},
+				{name: "unit", typ: ARG_TYPE_ONEOF, args: geoUnitArgs()},
+			}},
+			{name: "box", typ: ARG_TYPE_BLOCK, args: []commandArg{
+				{name: "width", typ: ARG_TYPE_DOUBLE, token: "BYBOX"},
+				{name: "height", typ: ARG_TYPE_DOUBLE},
+				{name: "unit", typ: ARG_TYPE_ONEOF, args: geoUnitArgs()},
+			}},
+		}},
+		{name: "order", typ: ARG_TYPE_ONEOF, optional: true, args: []commandArg{
+			{name: "asc", typ: ARG_TYPE_TOKEN, token: "ASC"},
+			{name: "desc", typ: ARG_TYPE_TOKEN, token: "DESC"},
+		}},
+		{name: "count-block", typ: ARG_TYPE_BLOCK, optional: true, args: []commandArg{
+			{name: "count", typ: ARG_TYPE_INTEGER, token: "COUNT"},
+			{name: "any", typ: ARG_TYPE_TOKEN, token: "ANY", optional: true},
+		}},
+	}
+}
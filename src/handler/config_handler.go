@@ -2,29 +2,68 @@ package handler
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/vrajashkr/cc-kv-go/src/config"
 	"github.com/vrajashkr/cc-kv-go/src/data"
 )
 
+// the parameters returned by CONFIG GET when no pattern is given.
+var DEFAULT_CONFIG_GET_PARAMS = []string{"maxmemory", "save", "appendonly"}
+
 // https://redis.io/docs/latest/commands/config-get/
-func handleConfig(cmdArray data.Array) data.Message {
+// https://redis.io/docs/latest/commands/config-set/
+func handleConfig(cmdArray data.Array, cfg *config.Config) data.Message {
 	subCommandHolder := cmdArray.Elements[1].(data.BulkString)
+	args := make([]string, len(cmdArray.Elements)-2)
+	for idx := range args {
+		args[idx] = cmdArray.Elements[2+idx].(data.BulkString).Data
+	}
 
-	switch subCommandHolder.Data {
+	switch strings.ToUpper(subCommandHolder.Data) {
 	case "GET":
-		return data.Array{
-			Elements: []data.Message{
-				data.BulkString{Data: "maxmemory"},
-				data.BulkString{Data: "0"},
-				data.BulkString{Data: "save"},
-				data.BulkString{Data: ""},
-				data.BulkString{Data: "appendonly"},
-				data.BulkString{Data: "no"},
-			},
-		}
+		return handleConfigGet(args, cfg)
+	case "SET":
+		return handleConfigSet(args, cfg)
 	default:
 		return data.Error{
 			ErrMsg: fmt.Sprintf("unsupported subcommand %s for %s", subCommandHolder.Data, CMD_CONFIG),
 		}
 	}
 }
+
+func handleConfigGet(patterns []string, cfg *config.Config) data.Message {
+	elements := []data.Message{}
+
+	if len(patterns) == 0 {
+		for _, name := range DEFAULT_CONFIG_GET_PARAMS {
+			elements = append(elements, data.BulkString{Data: name}, data.BulkString{Data: cfg.Get(name)})
+		}
+		return data.Array{Elements: elements}
+	}
+
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		for _, entry := range cfg.Match(pattern) {
+			if seen[entry[0]] {
+				continue
+			}
+			seen[entry[0]] = true
+			elements = append(elements, data.BulkString{Data: entry[0]}, data.BulkString{Data: entry[1]})
+		}
+	}
+	return data.Array{Elements: elements}
+}
+
+func handleConfigSet(args []string, cfg *config.Config) data.Message {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return data.Error{ErrMsg: "wrong number of arguments for 'config|set' command"}
+	}
+
+	for idx := 0; idx < len(args); idx += 2 {
+		if err := cfg.Set(args[idx], args[idx+1]); err != nil {
+			return data.Error{ErrMsg: err.Error()}
+		}
+	}
+	return OK
+}
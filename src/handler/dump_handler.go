@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/dump/
+func handleDump(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	ok, payload, _, err := strg.Dump(key)
+	if err != nil {
+		return storageError("dump value", err)
+	}
+	if !ok {
+		return data.Null{}
+	}
+	return data.BulkString{Data: string(payload)}
+}
+
+// https://redis.io/docs/latest/commands/restore/
+// a TTL of 0 restores the key without an expiry. IDLETIME and FREQ are accepted for compatibility,
+// but they are not kept since keys are never evicted.
+func handleRestore(cmd data.Array, strg storage.StorageEngine) data.Message {
+	args := bulkStrings(cmd.Elements[1:])
+	key, payload := args[0], args[2]
+	ttl, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return NOT_AN_INTEGER
+	}
+	if ttl < 0 {
+		return data.Error{ErrMsg: "Invalid TTL value, must be >= 0"}
+	}
+
+	replace, absTTL := false, false
+	idleTime, freq := int64(-1), int64(-1)
+	for idx := 3; idx < len(args); idx++ {
+		switch option := strings.ToUpper(args[idx]); {
+		case option == "REPLACE":
+			replace = true
+		case option == "ABSTTL":
+			absTTL = true
+		case option == "IDLETIME" && idx+1 < len(args) && freq == -1:
+			idx++
+			if idleTime, err = strconv.ParseInt(args[idx], 10, 64); err != nil {
+				return NOT_AN_INTEGER
+			}
+			if idleTime < 0 {
+				return data.Error{ErrMsg: "Invalid IDLETIME value, must be >= 0"}
+			}
+		case option == "FREQ" && idx+1 < len(args) && idleTime == -1:
+			idx++
+			if freq, err = strconv.ParseInt(args[idx], 10, 64); err != nil {
+				return NOT_AN_INTEGER
+			}
+			if freq < 0 || freq > 255 {
+				return data.Error{ErrMsg: "Invalid FREQ value, must be >= 0 and <= 255"}
+			}
+		default:
+			return SYNTAX_ERROR
+		}
+	}
+
+	var expiresAt time.Time
+	switch {
+	case ttl == 0:
+	case absTTL:
+		expiresAt = time.UnixMilli(ttl)
+	default:
+		expiresAt = time.Now().Add(time.Duration(ttl) * time.Millisecond)
+	}
+
+	if err := strg.Restore(key, []byte(payload), replace, expiresAt); err != nil {
+		return storageError("restore value", err)
+	}
+	return OK
+}
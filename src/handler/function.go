@@ -0,0 +1,403 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// functions are registered by libraries of Lua code starting with a "#!lua name=<library>" line. the code
+// of the libraries is kept by the storage engine, so that it is part of the snapshots, and the libraries
+// are compiled by the handler when their code changes. a function runs in a fresh state like the scripts,
+// in which its library is loaded again to register its callback.
+
+var (
+	FUNCTION_NOT_FOUND = data.Error{ErrMsg: "Function not found"}
+	LIBRARY_NOT_FOUND  = data.Error{ErrMsg: "Library not found"}
+)
+
+// FUNCTION_LOAD_TIMEOUT bounds the time a library can take to register its functions.
+const FUNCTION_LOAD_TIMEOUT = 500 * time.Millisecond
+
+// the flags functions can be registered with.
+const (
+	FUNCTION_FLAG_NO_WRITES  = "no-writes"
+	FUNCTION_FLAG_NO_CLUSTER = "no-cluster"
+)
+
+var FUNCTION_FLAGS = []string{FUNCTION_FLAG_NO_WRITES, "allow-oom", "allow-stale", FUNCTION_FLAG_NO_CLUSTER, "allow-cross-slot-keys"}
+
+// library is a compiled function library.
+type library struct {
+	name      string
+	code      string
+	proto     *lua.FunctionProto
+	functions map[string]*libraryFunction
+}
+
+type libraryFunction struct {
+	name        string
+	description string
+	flags       []string
+	// callback is only set for the functions registered in the state of a running function
+	callback *lua.LFunction
+}
+
+// Functions holds the libraries compiled from the code kept by the storage engine.
+type Functions struct {
+	mu sync.Mutex
+	// compiled holds the libraries by their code
+	compiled map[string]*library
+}
+
+func NewFunctions() *Functions {
+	return &Functions{compiled: make(map[string]*library)}
+}
+
+func (fn *libraryFunction) hasFlag(flag string) bool {
+	return slices.Contains(fn.flags, flag)
+}
+
+// librariesLocked returns the libraries of the storage engine by their name, compiling the ones whose code
+// changed. It must be called with the lock held.
+func (f *Functions) librariesLocked(strg storage.StorageEngine) map[string]*library {
+	codes := strg.Libraries()
+	compiled := make(map[string]*library, len(codes))
+	libraries := make(map[string]*library, len(codes))
+	for name, code := range codes {
+		lib, ok := f.compiled[code]
+		if !ok {
+			var errMsg data.Message
+			if lib, errMsg = parseLibrary(code); errMsg != nil {
+				slog.Warn("skipping invalid function library", "library", name, "error", errMsg)
+				continue
+			}
+		}
+		compiled[code] = lib
+		libraries[lib.name] = lib
+	}
+	f.compiled = compiled
+	return libraries
+}
+
+// storeLocked replaces the libraries of the storage engine, unless two of them register the same function.
+// It must be called with the lock held.
+func (f *Functions) storeLocked(strg storage.StorageEngine, libraries map[string]*library) data.Message {
+	registered := make(map[string]bool)
+	for _, name := range slices.Sorted(maps.Keys(libraries)) {
+		for _, fnName := range slices.Sorted(maps.Keys(libraries[name].functions)) {
+			if registered[fnName] {
+				return data.Error{ErrMsg: fmt.Sprintf("Function %s already exists", fnName)}
+			}
+			registered[fnName] = true
+		}
+	}
+
+	codes := make(map[string]string, len(libraries))
+	for name, lib := range libraries {
+		codes[name] = lib.code
+	}
+	strg.SetLibraries(codes)
+	return nil
+}
+
+// find returns the function with the given name along with its library.
+func (f *Functions) find(strg storage.StorageEngine, name string) (*library, *libraryFunction, data.Message) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, lib := range f.librariesLocked(strg) {
+		if fn, ok := lib.functions[name]; ok {
+			return lib, fn, nil
+		}
+	}
+	return nil, nil, FUNCTION_NOT_FOUND
+}
+
+// list returns the libraries sorted by their name.
+func (f *Functions) list(strg storage.StorageEngine) []*library {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	libraries := f.librariesLocked(strg)
+	return slices.SortedFunc(maps.Values(libraries), func(a, b *library) int {
+		return strings.Compare(a.name, b.name)
+	})
+}
+
+// load adds a library, or replaces the library with the same name when replace is set.
+func (f *Functions) load(strg storage.StorageEngine, code string, replace bool) (string, data.Message) {
+	lib, errMsg := parseLibrary(code)
+	if errMsg != nil {
+		return "", errMsg
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	libraries := f.librariesLocked(strg)
+	if _, exists := libraries[lib.name]; exists && !replace {
+		return "", data.Error{ErrMsg: fmt.Sprintf("Library '%s' already exists", lib.name)}
+	}
+	libraries[lib.name] = lib
+	if errMsg := f.storeLocked(strg, libraries); errMsg != nil {
+		return "", errMsg
+	}
+	return lib.name, nil
+}
+
+func (f *Functions) delete(strg storage.StorageEngine, name string) data.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	libraries := f.librariesLocked(strg)
+	if _, exists := libraries[name]; !exists {
+		return LIBRARY_NOT_FOUND
+	}
+	delete(libraries, name)
+	return f.storeLocked(strg, libraries)
+}
+
+func (f *Functions) flush(strg storage.StorageEngine) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	strg.SetLibraries(map[string]string{})
+}
+
+// dump serialises the code of every library.
+func (f *Functions) dump(strg storage.StorageEngine) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return storage.DumpLibraries(strg.Libraries())
+}
+
+// restore adds the libraries of a payload created by dump. with the FLUSH policy they replace every library,
+// with APPEND they can't have the name of an existing library, and with REPLACE they replace the libraries
+// with the same name.
+func (f *Functions) restore(strg storage.StorageEngine, payload []byte, policy string) data.Message {
+	codes, err := storage.RestoreLibraries(payload)
+	if err != nil {
+		return data.Error{ErrMsg: "payload version or checksum are wrong"}
+	}
+	restored := make(map[string]*library, len(codes))
+	for _, name := range slices.Sorted(maps.Keys(codes)) {
+		lib, errMsg := parseLibrary(codes[name])
+		if errMsg != nil {
+			return errMsg
+		}
+		restored[lib.name] = lib
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	libraries := f.librariesLocked(strg)
+	switch policy {
+	case "FLUSH":
+		libraries = restored
+	case "APPEND":
+		for _, name := range slices.Sorted(maps.Keys(restored)) {
+			if _, exists := libraries[name]; exists {
+				return data.Error{ErrMsg: fmt.Sprintf("Library %s already exists", name)}
+			}
+			libraries[name] = restored[name]
+		}
+	case "REPLACE":
+		maps.Copy(libraries, restored)
+	}
+	return f.storeLocked(strg, libraries)
+}
+
+// validFunctionName reports whether a library or a function name is made of letters, numbers and underscores.
+func validFunctionName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLibraryMetadata reads the name of a library from its first line, returning the name with the
+// code that follows the line.
+func parseLibraryMetadata(code string) (string, string, data.Message) {
+	if !strings.HasPrefix(code, "#!") {
+		return "", "", data.Error{ErrMsg: "Missing library metadata"}
+	}
+	line, body, _ := strings.Cut(code[2:], "\n")
+	fields := strings.Fields(line)
+	engine := ""
+	if len(fields) > 0 {
+		engine = fields[0]
+	}
+	if !strings.EqualFold(engine, "lua") {
+		return "", "", data.Error{ErrMsg: fmt.Sprintf("Engine '%s' not found", engine)}
+	}
+
+	name := ""
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key != "name" {
+			return "", "", data.Error{ErrMsg: fmt.Sprintf("Invalid metadata value given: %s", field)}
+		}
+		name = value
+	}
+	if name == "" {
+		return "", "", data.Error{ErrMsg: "Library name was not given"}
+	}
+	if !validFunctionName(name) {
+		return "", "", data.Error{ErrMsg: "Library names can only contain letters, numbers, or underscores(_) and must be at least one character long"}
+	}
+	// the metadata line is left empty so that errors point to the right lines
+	return name, "\n" + body, nil
+}
+
+// parseLibrary compiles a library and runs it to find out the functions it registers.
+func parseLibrary(code string) (*library, data.Message) {
+	name, body, errMsg := parseLibraryMetadata(code)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	proto, err := compileScript(body, "user_function")
+	if err != nil {
+		return nil, data.Error{ErrMsg: fmt.Sprintf("Error compiling function: %s", singleLine(err.Error()))}
+	}
+
+	// libraries can't call commands while they are loaded
+	L := newScriptState(CommandHandler{script: &scriptRun{name: name}})
+	defer L.Close()
+	redis := L.GetGlobal("redis").(*lua.LTable)
+	redis.RawSetString("call", lua.LNil)
+	redis.RawSetString("pcall", lua.LNil)
+	ctx, cancel := context.WithTimeout(context.Background(), FUNCTION_LOAD_TIMEOUT)
+	defer cancel()
+	L.SetContext(ctx)
+
+	functions, err := loadLibrary(L, proto)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, data.Error{ErrMsg: "FUNCTION LOAD timeout"}
+		}
+		return nil, data.Error{ErrMsg: fmt.Sprintf("Error registering functions: %s", luaErrorMessage(err))}
+	}
+	if len(functions) == 0 {
+		return nil, data.Error{ErrMsg: "No functions registered"}
+	}
+	// the callbacks belong to the state the library was loaded in
+	for _, fn := range functions {
+		fn.callback = nil
+	}
+	return &library{name: name, code: code, proto: proto, functions: functions}, nil
+}
+
+// loadLibrary runs the code of a library with redis.register_function, returning the functions it registers.
+func loadLibrary(L *lua.LState, proto *lua.FunctionProto) (map[string]*libraryFunction, error) {
+	functions := make(map[string]*libraryFunction)
+	redis := L.GetGlobal("redis").(*lua.LTable)
+	redis.RawSetString("register_function", L.NewFunction(func(L *lua.LState) int {
+		registerFunction(L, functions)
+		return 0
+	}))
+	// functions can only be registered while their library is loaded
+	defer redis.RawSetString("register_function", lua.LNil)
+
+	protectGlobals(L)
+	L.Push(L.NewFunctionFromProto(proto))
+	if err := L.PCall(0, 0, nil); err != nil {
+		return nil, err
+	}
+	return functions, nil
+}
+
+// registerFunction implements redis.register_function, which takes either the name and the callback of the
+// function, or a table with function_name, callback, flags and description.
+func registerFunction(L *lua.LState, functions map[string]*libraryFunction) {
+	var nameArg, callbackArg, flagsArg, descriptionArg lua.LValue = lua.LNil, lua.LNil, lua.LNil, lua.LNil
+	switch L.GetTop() {
+	case 1:
+		table, ok := L.Get(1).(*lua.LTable)
+		if !ok {
+			L.RaiseError("calling redis.register_function with a single argument is only applicable to Lua table (representing named arguments).")
+		}
+		table.ForEach(func(key lua.LValue, value lua.LValue) {
+			switch key.String() {
+			case "function_name":
+				nameArg = value
+			case "callback":
+				callbackArg = value
+			case "flags":
+				flagsArg = value
+			case "description":
+				descriptionArg = value
+			default:
+				L.RaiseError("unknown argument given to redis.register_function")
+			}
+		})
+	case 2:
+		nameArg, callbackArg = L.Get(1), L.Get(2)
+	default:
+		L.RaiseError("wrong number of arguments to redis.register_function")
+	}
+
+	name, ok := nameArg.(lua.LString)
+	if !ok {
+		L.RaiseError("function_name argument given to redis.register_function must be a string")
+	}
+	callback, ok := callbackArg.(*lua.LFunction)
+	if !ok {
+		L.RaiseError("callback argument given to redis.register_function must be a function")
+	}
+	fn := &libraryFunction{name: string(name), callback: callback}
+	if descriptionArg != lua.LNil {
+		description, ok := descriptionArg.(lua.LString)
+		if !ok {
+			L.RaiseError("description argument given to redis.register_function must be a string")
+		}
+		fn.description = string(description)
+	}
+	if flagsArg != lua.LNil {
+		flags, ok := flagsArg.(*lua.LTable)
+		if !ok {
+			L.RaiseError("flags argument to redis.register_function must be a table representing function flags")
+		}
+		for idx := 1; idx <= flags.Len(); idx++ {
+			flag := flags.RawGetInt(idx).String()
+			if !slices.Contains(FUNCTION_FLAGS, flag) {
+				L.RaiseError("unknown flag given")
+			}
+			fn.flags = append(fn.flags, flag)
+		}
+	}
+
+	if !validFunctionName(fn.name) {
+		L.RaiseError("Function names can only contain letters, numbers, or underscores(_) and must be at least one character long")
+	}
+	if _, exists := functions[fn.name]; exists {
+		L.RaiseError("Function already exists in the library")
+	}
+	functions[fn.name] = fn
+}
+
+// fcallLoader loads the library of a function run by FCALL, whose callback is called with the keys and the
+// arguments.
+func fcallLoader(lib *library, name string, keys []string, args []string) scriptLoader {
+	return func(L *lua.LState) (*lua.LFunction, []lua.LValue, error) {
+		functions, err := loadLibrary(L, lib.proto)
+		if err != nil {
+			return nil, nil, err
+		}
+		fn, ok := functions[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("function %s is no longer registered by library %s", name, lib.name)
+		}
+		return fn.callback, []lua.LValue{stringsToLua(L, keys), stringsToLua(L, args)}, nil
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/glob"
+)
+
+// https://redis.io/docs/latest/commands/fcall/
+// https://redis.io/docs/latest/commands/fcall_ro/
+// functions registered with the no-writes flag always run as read-only, and they are the only ones FCALL_RO
+// can call.
+func handleFcall(ch CommandHandler, client *Client, cmd data.Array, readOnly bool) data.Message {
+	args := bulkStrings(cmd.Elements[1:])
+	keys, argv, errMsg := parseScriptArgs(args[1:])
+	if errMsg != nil {
+		return errMsg
+	}
+
+	lib, fn, errMsg := ch.functions.find(ch.strgEngine, args[0])
+	if errMsg != nil {
+		return errMsg
+	}
+	noWrites := fn.hasFlag(FUNCTION_FLAG_NO_WRITES)
+	if readOnly && !noWrites {
+		return data.Error{ErrMsg: "Can not execute a script with write flag using *_ro command."}
+	}
+	if ch.cluster != nil && fn.hasFlag(FUNCTION_FLAG_NO_CLUSTER) {
+		return data.Error{ErrMsg: "Can not run script on cluster, 'no-cluster' flag is set."}
+	}
+	return ch.runScript(client, fn.name, readOnly || noWrites, fcallLoader(lib, fn.name, keys, argv))
+}
+
+// https://redis.io/docs/latest/commands/function/
+func handleFunction(ch CommandHandler, cmd data.Array) data.Message {
+	args := bulkStrings(cmd.Elements[1:])
+	switch strings.ToUpper(args[0]) {
+	case "LOAD":
+		replace := len(args) == 3 && strings.EqualFold(args[1], "REPLACE")
+		if len(args) == 3 && !replace {
+			return data.Error{ErrMsg: fmt.Sprintf("Unknown option given: %s", args[1])}
+		}
+		if len(args) > 3 {
+			return SYNTAX_ERROR
+		}
+		name, errMsg := ch.functions.load(ch.strgEngine, args[len(args)-1], replace)
+		if errMsg != nil {
+			return errMsg
+		}
+		return data.BulkString{Data: name}
+	case "DELETE":
+		if errMsg := ch.functions.delete(ch.strgEngine, args[1]); errMsg != nil {
+			return errMsg
+		}
+		return OK
+	case "FLUSH":
+		if len(args) > 2 || (len(args) == 2 && !strings.EqualFold(args[1], "ASYNC") && !strings.EqualFold(args[1], "SYNC")) {
+			return data.Error{ErrMsg: "FUNCTION FLUSH only supports SYNC|ASYNC option"}
+		}
+		ch.functions.flush(ch.strgEngine)
+		return OK
+	case "LIST":
+		return handleFunctionList(ch, args[1:])
+	case "DUMP":
+		return data.BulkString{Data: string(ch.functions.dump(ch.strgEngine))}
+	case "RESTORE":
+		policy := "APPEND"
+		if len(args) == 3 {
+			policy = strings.ToUpper(args[2])
+		}
+		if len(args) > 3 || !slices.Contains([]string{"FLUSH", "APPEND", "REPLACE"}, policy) {
+			return data.Error{ErrMsg: "Wrong restore policy given, value should be either FLUSH, APPEND or REPLACE."}
+		}
+		if errMsg := ch.functions.restore(ch.strgEngine, []byte(args[1]), policy); errMsg != nil {
+			return errMsg
+		}
+		return OK
+	case "KILL":
+		return ch.scripts.kill()
+	default:
+		return data.Error{ErrMsg: fmt.Sprintf("unsupported subcommand %s for FUNCTION", args[0])}
+	}
+}
+
+// handleFunctionList lists the libraries along with their functions, optionally filtered by a pattern on
+// their name and with their code.
+func handleFunctionList(ch CommandHandler, args []string) data.Message {
+	pattern, withCode := "", false
+	for idx := 0; idx < len(args); idx++ {
+		switch option := strings.ToUpper(args[idx]); {
+		case option == "WITHCODE" && !withCode:
+			withCode = true
+		case option == "LIBRARYNAME" && idx+1 < len(args) && pattern == "":
+			pattern = args[idx+1]
+			idx++
+		default:
+			return data.Error{ErrMsg: fmt.Sprintf("Unknown argument %s", args[idx])}
+		}
+	}
+
+	libraries := []data.Message{}
+	for _, lib := range ch.functions.list(ch.strgEngine) {
+		if pattern != "" && !glob.Match(pattern, lib.name) {
+			continue
+		}
+		functions := []data.Message{}
+		for _, name := range slices.Sorted(maps.Keys(lib.functions)) {
+			fn := lib.functions[name]
+			var description data.Message = data.Null{}
+			if fn.description != "" {
+				description = data.BulkString{Data: fn.description}
+			}
+			flags := make([]data.Message, len(fn.flags))
+			for idx, flag := range fn.flags {
+				flags[idx] = data.SimpleString{Contents: flag}
+			}
+			functions = append(functions, data.Array{Elements: []data.Message{
+				data.BulkString{Data: "name"},
+				data.BulkString{Data: fn.name},
+				data.BulkString{Data: "description"},
+				description,
+				data.BulkString{Data: "flags"},
+				data.Array{Elements: flags},
+			}})
+		}
+
+		entry := []data.Message{
+			data.BulkString{Data: "library_name"},
+			data.BulkString{Data: lib.name},
+			data.BulkString{Data: "engine"},
+			data.BulkString{Data: "LUA"},
+			data.BulkString{Data: "functions"},
+			data.Array{Elements: functions},
+		}
+		if withCode {
+			entry = append(entry, data.BulkString{Data: "library_code"}, data.BulkString{Data: lib.code})
+		}
+		libraries = append(libraries, data.Array{Elements: entry})
+	}
+	return data.Array{Elements: libraries}
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+const INVALID_LONG_LAT_FMT = "invalid longitude,latitude pair %f,%f"
+
+var UNSUPPORTED_UNIT = data.Error{ErrMsg: "unsupported unit provided. please use M, KM, FT, MI"}
+
+// geoUnit returns the number of meters in the distance unit.
+func geoUnit(unit string) (float64, bool) {
+	switch strings.ToLower(unit) {
+	case "m":
+		return 1, true
+	case "km":
+		return 1000, true
+	case "ft":
+		return 0.3048, true
+	case "mi":
+		return 1609.34, true
+	default:
+		return 0, false
+	}
+}
+
+// parseGeoCoordinates parses a longitude and a latitude that can be indexed.
+func parseGeoCoordinates(longArg string, latArg string) (float64, float64, data.Message) {
+	long, longErr := strconv.ParseFloat(longArg, 64)
+	lat, latErr := strconv.ParseFloat(latArg, 64)
+	if longErr != nil || latErr != nil {
+		return 0, 0, NOT_A_FLOAT
+	}
+	if !storage.ValidGeoCoordinates(long, lat) {
+		return 0, 0, data.Error{ErrMsg: fmt.Sprintf(INVALID_LONG_LAT_FMT, long, lat)}
+	}
+	return long, lat, nil
+}
+
+func formatCoordinate(value float64) data.BulkString {
+	return data.BulkString{Data: strconv.FormatFloat(value, 'f', -1, 64)}
+}
+
+// geoPosition replies with the longitude and latitude of a geohash score.
+func geoPosition(score uint64) data.Array {
+	long, lat := storage.GeoDecode(score)
+	return data.Array{Elements: []data.Message{formatCoordinate(long), formatCoordinate(lat)}}
+}
+
+// https://redis.io/docs/latest/commands/geoadd/
+func handleGeoAdd(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	onlyIfNotExists, onlyIfExists, countChanged := false, false, false
+	idx := 2
+	for ; idx < len(cmd.Elements); idx++ {
+		switch strings.ToUpper(cmd.Elements[idx].(data.BulkString).Data) {
+		case "NX":
+			onlyIfNotExists = true
+			continue
+		case "XX":
+			onlyIfExists = true
+			continue
+		case "CH":
+			countChanged = true
+			continue
+		}
+		break
+	}
+
+	if onlyIfNotExists && onlyIfExists {
+		return data.Error{ErrMsg: "XX and NX options at the same time are not compatible"}
+	}
+	args := cmd.Elements[idx:]
+	if len(args) == 0 || len(args)%3 != 0 {
+		return SYNTAX_ERROR
+	}
+
+	members := make([]string, len(args)/3)
+	scores := make([]uint64, len(args)/3)
+	for memberIdx := range members {
+		long, lat, errMsg := parseGeoCoordinates(args[3*memberIdx].(data.BulkString).Data, args[3*memberIdx+1].(data.BulkString).Data)
+		if errMsg != nil {
+			return errMsg
+		}
+		members[memberIdx] = args[3*memberIdx+2].(data.BulkString).Data
+		scores[memberIdx] = storage.GeoEncode(long, lat)
+	}
+
+	count, err := strg.GeoAdd(key, members, scores, onlyIfNotExists, onlyIfExists, countChanged)
+	if err != nil {
+		return storageError("add members", err)
+	}
+	return data.Integer{Value: count}
+}
+
+// https://redis.io/docs/latest/commands/geopos/
+func handleGeoPos(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	found, scores, err := strg.GeoScores(key, bulkStrings(cmd.Elements[2:]))
+	if err != nil {
+		return storageError("retrieve data", err)
+	}
+
+	elements := make([]data.Message, len(found))
+	for idx := range found {
+		if !found[idx] {
+			elements[idx] = data.Null{}
+			continue
+		}
+		elements[idx] = geoPosition(scores[idx])
+	}
+	return data.Array{Elements: elements}
+}
+
+// https://redis.io/docs/latest/commands/geodist/
+func handleGeoDist(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	unit := 1.0
+	switch len(cmd.Elements) {
+	case 4:
+	case 5:
+		var ok bool
+		unit, ok = geoUnit(cmd.Elements[4].(data.BulkString).Data)
+		if !ok {
+			return UNSUPPORTED_UNIT
+		}
+	default:
+		return SYNTAX_ERROR
+	}
+
+	found, scores, err := strg.GeoScores(key, bulkStrings(cmd.Elements[2:4]))
+	if err != nil {
+		return storageError("retrieve data", err)
+	}
+	if !found[0] || !found[1] {
+		return data.Null{}
+	}
+
+	long1, lat1 := storage.GeoDecode(scores[0])
+	long2, lat2 := storage.GeoDecode(scores[1])
+	return formatDistance(storage.GeoDistance(long1, lat1, long2, lat2) / unit)
+}
+
+func formatDistance(distance float64) data.BulkString {
+	return data.BulkString{Data: strconv.FormatFloat(distance, 'f', 4, 64)}
+}
+
+// https://redis.io/docs/latest/commands/geohash/
+func handleGeoHash(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	found, scores, err := strg.GeoScores(key, bulkStrings(cmd.Elements[2:]))
+	if err != nil {
+		return storageError("retrieve data", err)
+	}
+
+	elements := make([]data.Message, len(found))
+	for idx := range found {
+		if !found[idx] {
+			elements[idx] = data.Null{}
+			continue
+		}
+		elements[idx] = data.BulkString{Data: storage.GeoHashString(scores[idx])}
+	}
+	return data.Array{Elements: elements}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// geoSearchOptions holds what GEOSEARCH should reply with for each member found.
+type geoSearchOptions struct {
+	unit      float64
+	withCoord bool
+	withDist  bool
+	withHash  bool
+	storeDist bool
+}
+
+// parseGeoSearch parses the options shared by GEOSEARCH and GEOSEARCHSTORE.
+// the WITH options only apply to GEOSEARCH, since GEOSEARCHSTORE does not reply with the members, while
+// STOREDIST only applies to GEOSEARCHSTORE.
+func parseGeoSearch(command string, args []data.Message, isStore bool) (storage.GeoQuery, geoSearchOptions, data.Message) {
+	query := storage.GeoQuery{}
+	opts := geoSearchOptions{unit: 1}
+	fromMember, fromLonLat, byRadius, byBox, withCount := false, false, false, false, false
+
+	// hasArgs checks that the option at idx is followed by enough arguments
+	hasArgs := func(idx int, count int) bool {
+		return idx+count < len(args)
+	}
+	arg := func(idx int) string {
+		return args[idx].(data.BulkString).Data
+	}
+
+	for idx := 0; idx < len(args); idx++ {
+		switch option := strings.ToUpper(arg(idx)); {
+		case option == "FROMMEMBER" && hasArgs(idx, 1):
+			query.FromMember, query.Member = true, arg(idx+1)
+			fromMember = true
+			idx++
+		case option == "FROMLONLAT" && hasArgs(idx, 2):
+			long, lat, errMsg := parseGeoCoordinates(arg(idx+1), arg(idx+2))
+			if errMsg != nil {
+				return query, opts, errMsg
+			}
+			query.Long, query.Lat = long, lat
+			fromLonLat = true
+			idx += 2
+		case option == "BYRADIUS" && hasArgs(idx, 2):
+			radius, err := strconv.ParseFloat(arg(idx+1), 64)
+			if err != nil {
+				return query, opts, data.Error{ErrMsg: "need numeric radius"}
+			}
+			if radius < 0 {
+				return query, opts, data.Error{ErrMsg: "radius cannot be negative"}
+			}
+			unit, ok := geoUnit(arg(idx + 2))
+			if !ok {
+				return query, opts, UNSUPPORTED_UNIT
+			}
+			query.Radius, opts.unit = radius*unit, unit
+			byRadius = true
+			idx += 2
+		case option == "BYBOX" && hasArgs(idx, 3):
+			width, widthErr := strconv.ParseFloat(arg(idx+1), 64)
+			height, heightErr := strconv.ParseFloat(arg(idx+2), 64)
+			if widthErr != nil || heightErr != nil {
+				return query, opts, NOT_A_FLOAT
+			}
+			if width < 0 || height < 0 {
+				return query, opts, data.Error{ErrMsg: "height or width cannot be negative"}
+			}
+			unit, ok := geoUnit(arg(idx + 3))
+			if !ok {
+				return query, opts, UNSUPPORTED_UNIT
+			}
+			query.ByBox, query.Width, query.Height, opts.unit = true, width*unit, height*unit, unit
+			byBox = true
+			idx += 3
+		case option == "ASC":
+			query.Sort = storage.GEO_SORT_ASC
+		case option == "DESC":
+			query.Sort = storage.GEO_SORT_DESC
+		case option == "COUNT" && hasArgs(idx, 1):
+			count, err := strconv.ParseInt(arg(idx+1), 10, 64)
+			if err != nil {
+				return query, opts, NOT_AN_INTEGER
+			}
+			if count <= 0 {
+				return query, opts, data.Error{ErrMsg: "COUNT must be > 0"}
+			}
+			query.Count = int(count)
+			withCount = true
+			idx++
+			if hasArgs(idx, 1) && strings.EqualFold(arg(idx+1), "ANY") {
+				query.Any = true
+				idx++
+			}
+		case option == "WITHCOORD" && !isStore:
+			opts.withCoord = true
+		case option == "WITHDIST" && !isStore:
+			opts.withDist = true
+		case option == "WITHHASH" && !isStore:
+			opts.withHash = true
+		case option == "STOREDIST" && isStore:
+			opts.storeDist = true
+		default:
+			return query, opts, SYNTAX_ERROR
+		}
+	}
+
+	if fromMember == fromLonLat {
+		return query, opts, data.Error{ErrMsg: fmt.Sprintf("exactly one of FROMMEMBER or FROMLONLAT can be specified for %s", command)}
+	}
+	if byRadius == byBox {
+		return query, opts, data.Error{ErrMsg: fmt.Sprintf("exactly one of BYRADIUS and BYBOX can be specified for %s", command)}
+	}
+	if query.Any && !withCount {
+		return query, opts, data.Error{ErrMsg: "the ANY argument requires COUNT argument"}
+	}
+
+	// only the nearest members are returned when the results are limited
+	if withCount && !query.Any && query.Sort == storage.GEO_SORT_NONE {
+		query.Sort = storage.GEO_SORT_ASC
+	}
+	return query, opts, nil
+}
+
+// https://redis.io/docs/latest/commands/geosearch/
+func handleGeoSearch(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+	query, opts, errMsg := parseGeoSearch("geosearch", cmd.Elements[2:], false)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	matches, err := strg.GeoSearch(key, query)
+	if err != nil {
+		return storageError("search", err)
+	}
+
+	elements := make([]data.Message, len(matches))
+	for idx, match := range matches {
+		member := data.BulkString{Data: match.Member}
+		if !opts.withDist && !opts.withHash && !opts.withCoord {
+			elements[idx] = member
+			continue
+		}
+
+		fields := []data.Message{member}
+		if opts.withDist {
+			fields = append(fields, formatDistance(match.Distance/opts.unit))
+		}
+		if opts.withHash {
+			fields = append(fields, data.Integer{Value: int64(match.Score)})
+		}
+		if opts.withCoord {
+			fields = append(fields, geoPosition(match.Score))
+		}
+		elements[idx] = data.Array{Elements: fields}
+	}
+	return data.Array{Elements: elements}
+}
+
+// https://redis.io/docs/latest/commands/geosearchstore/
+func handleGeoSearchStore(cmd data.Array, strg storage.StorageEngine) data.Message {
+	destKey := cmd.Elements[1].(data.BulkString).Data
+	key := cmd.Elements[2].(data.BulkString).Data
+	query, opts, errMsg := parseGeoSearch("geosearchstore", cmd.Elements[3:], true)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	// the distances are stored in the unit of the search area
+	distUnit := 0.0
+	if opts.storeDist {
+		distUnit = opts.unit
+	}
+	count, err := strg.GeoSearchStore(destKey, key, query, distUnit)
+	if err != nil {
+		return storageError("search", err)
+	}
+	return data.Integer{Value: count}
+}
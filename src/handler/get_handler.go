@@ -10,7 +10,7 @@ func handleGet(cmd data.Array, strg storage.StorageEngine) data.Message {
 	keyHolder := cmd.Elements[1].(data.BulkString)
 	ok, val, err := strg.Get(keyHolder.Data)
 	if err != nil {
-		return data.Error{ErrMsg: "failed to retrieve data due to error: " + err.Error()}
+		return storageError("retrieve data", err)
 	}
 
 	if !ok {
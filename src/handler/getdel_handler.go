@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/getdel/
+func handleGetDel(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	ok, val, err := strg.GetDel(key)
+	if err != nil {
+		return storageError("retrieve data", err)
+	}
+	if !ok {
+		return data.Null{}
+	}
+	return data.BulkString{Data: val}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/getex/
+func handleGetEx(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	updateExpiry := false
+	expires := false
+	var expiresAtTimeStampMillis int64 = -1
+
+	switch len(cmd.Elements) {
+	case 2:
+	case 3:
+		if !strings.EqualFold(cmd.Elements[2].(data.BulkString).Data, CMD_GETEX_OPT_PERSIST) {
+			return SYNTAX_ERROR
+		}
+		updateExpiry = true
+	case 4:
+		option := cmd.Elements[2].(data.BulkString).Data
+		optionTimeInt, err := strconv.ParseInt(cmd.Elements[3].(data.BulkString).Data, 10, 64)
+		if err != nil {
+			return NOT_AN_INTEGER
+		}
+		if optionTimeInt <= 0 {
+			return data.Error{ErrMsg: "invalid expire time in 'getex' command"}
+		}
+
+		var ok bool
+		expiresAtTimeStampMillis, ok = expiresAtMillis(option, optionTimeInt)
+		if !ok {
+			return SYNTAX_ERROR
+		}
+		updateExpiry = true
+		expires = true
+	default:
+		return SYNTAX_ERROR
+	}
+
+	ok, val, err := strg.GetEx(key, updateExpiry, expires, expiresAtTimeStampMillis)
+	if err != nil {
+		return storageError("retrieve data", err)
+	}
+	if !ok {
+		return data.Null{}
+	}
+	return data.BulkString{Data: val}
+}
+
+// propagateGetEx replaces the relative expiry of GETEX with an absolute one, so that the key expires on the
+// replicas at the same time as on the master.
+func propagateGetEx(cmd data.Array, _ data.Message, _ storage.StorageEngine) []data.Array {
+	if len(cmd.Elements) != 4 {
+		return []data.Array{cmd}
+	}
+	return []data.Array{withAbsoluteExpiry(cmd, 2)}
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/getset/
+func handleGetSet(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+	value := cmd.Elements[2].(data.BulkString).Data
+
+	ok, oldVal, err := strg.GetSet(key, value)
+	if err != nil {
+		return storageError("set", err)
+	}
+	if !ok {
+		return data.Null{}
+	}
+	return data.BulkString{Data: oldVal}
+}
@@ -7,18 +7,52 @@ import (
 	"github.com/vrajashkr/cc-kv-go/src/data"
 )
 
+const (
+	// the name and version of the server reported by HELLO, which tells other servers apart from this one
+	SERVER_NAME    = "cc-kv-go"
+	SERVER_VERSION = "1.0.0"
+)
+
 // https://redis.io/docs/latest/commands/hello/
-func handleHello(cmd data.Array) data.Message {
-	versionEntry := cmd.Elements[1].(data.BulkString)
+// without a protocol version, the connection stays on the current protocol. either way, the properties of the
+// connection are replied with as a map, which RESP2 renders as a flat array of fields and values.
+func handleHello(ch CommandHandler, client *Client, cmd data.Array) data.Message {
+	if len(cmd.Elements) > 1 {
+		versionEntry := cmd.Elements[1].(data.BulkString)
 
-	versionRequested, err := strconv.Atoi(versionEntry.Data)
-	if err != nil {
-		slog.Error("invalid command args", "error", err.Error())
-		return INVALID_CMD_ARGS
+		versionRequested, err := strconv.Atoi(versionEntry.Data)
+		if err != nil {
+			slog.Error("invalid command args", "error", err.Error())
+			return INVALID_CMD_ARGS
+		}
+
+		if versionRequested != 2 {
+			return data.Error{ErrMsg: "NOPROTO sorry, this protocol version is not supported"}
+		}
+	}
+	if client == nil {
+		return NO_CLIENT_CONN
 	}
 
-	if versionRequested != 2 {
-		return data.Error{ErrMsg: "NOPROTO sorry, this protocol version is not supported"}
+	mode, _ := ch.clusterMode()
+	role := REPL_ROLE_MASTER
+	if ch.replication.IsReplica() {
+		role = "replica"
 	}
-	return OK
+	return data.Array{Elements: []data.Message{
+		data.BulkString{Data: "server"},
+		data.BulkString{Data: SERVER_NAME},
+		data.BulkString{Data: "version"},
+		data.BulkString{Data: SERVER_VERSION},
+		data.BulkString{Data: "proto"},
+		data.Integer{Value: 2},
+		data.BulkString{Data: "id"},
+		data.Integer{Value: client.ID()},
+		data.BulkString{Data: "mode"},
+		data.BulkString{Data: mode},
+		data.BulkString{Data: "role"},
+		data.BulkString{Data: role},
+		data.BulkString{Data: "modules"},
+		data.Array{Elements: []data.Message{}},
+	}}
 }
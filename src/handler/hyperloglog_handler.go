@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// bulkStrings returns the contents of the bulk string arguments.
+func bulkStrings(elements []data.Message) []string {
+	keys := make([]string, len(elements))
+	for idx, element := range elements {
+		keys[idx] = element.(data.BulkString).Data
+	}
+	return keys
+}
+
+// https://redis.io/docs/latest/commands/pfadd/
+func handlePFAdd(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	updated, err := strg.PFAdd(key, bulkStrings(cmd.Elements[2:]))
+	if err != nil {
+		return storageError("add elements", err)
+	}
+	if updated {
+		return data.Integer{Value: 1}
+	}
+	return data.Integer{Value: 0}
+}
+
+// https://redis.io/docs/latest/commands/pfcount/
+func handlePFCount(cmd data.Array, strg storage.StorageEngine) data.Message {
+	card, err := strg.PFCount(bulkStrings(cmd.Elements[1:]))
+	if err != nil {
+		return storageError("count elements", err)
+	}
+	return data.Integer{Value: card}
+}
+
+// https://redis.io/docs/latest/commands/pfmerge/
+func handlePFMerge(cmd data.Array, strg storage.StorageEngine) data.Message {
+	destKey := cmd.Elements[1].(data.BulkString).Data
+
+	if err := strg.PFMerge(destKey, bulkStrings(cmd.Elements[2:])); err != nil {
+		return storageError("merge", err)
+	}
+	return OK
+}
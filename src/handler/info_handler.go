@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// the sections of INFO, in the order they are shown.
+var INFO_SECTIONS = []string{"server", "clients", "stats", "replication", "cluster"}
+
+// https://redis.io/docs/latest/commands/info/
+// without arguments, or with default, all or everything, every section is shown. unknown sections are ignored.
+func handleInfo(ch CommandHandler, cmd data.Array) data.Message {
+	args := bulkStrings(cmd.Elements[1:])
+	all := len(args) == 0
+	requested := []string{}
+	for _, arg := range args {
+		section := strings.ToLower(arg)
+		if section == "default" || section == "all" || section == "everything" {
+			all = true
+		}
+		requested = append(requested, section)
+	}
+
+	parts := []string{}
+	for _, section := range INFO_SECTIONS {
+		if !all && !slices.Contains(requested, section) {
+			continue
+		}
+		title := strings.ToUpper(section[:1]) + section[1:]
+		parts = append(parts, "# "+title+"\r\n"+ch.infoSection(section))
+	}
+	return data.BulkString{Data: strings.Join(parts, "\r\n")}
+}
+
+// clusterMode returns the mode of the server for INFO, along with whether cluster mode is enabled as an integer.
+func (ch CommandHandler) clusterMode() (string, int) {
+	if ch.cluster != nil {
+		return "cluster", 1
+	}
+	return "standalone", 0
+}
+
+// infoSection renders the fields of a section of INFO.
+func (ch CommandHandler) infoSection(section string) string {
+	var sb strings.Builder
+	switch section {
+	case "server":
+		uptime := time.Since(ch.startedAt)
+		mode, _ := ch.clusterMode()
+		fmt.Fprintf(&sb, "redis_mode:%s\r\n", mode)
+		fmt.Fprintf(&sb, "process_id:%d\r\n", os.Getpid())
+		fmt.Fprintf(&sb, "tcp_port:%s\r\n", ch.cfg.Get("port"))
+		fmt.Fprintf(&sb, "uptime_in_seconds:%d\r\n", int64(uptime.Seconds()))
+		fmt.Fprintf(&sb, "uptime_in_days:%d\r\n", int64(uptime.Hours()/24))
+	case "clients":
+		// like redis, replicas and the master are not counted as clients
+		connected := 0
+		for _, client := range ch.clients.List() {
+			if client.Type() == CLIENT_TYPE_NORMAL {
+				connected++
+			}
+		}
+		fmt.Fprintf(&sb, "connected_clients:%d\r\n", connected)
+		fmt.Fprintf(&sb, "maxclients:%s\r\n", ch.cfg.Get("maxclients"))
+	case "stats":
+		sb.WriteString(ch.replication.stats())
+	case "replication":
+		sb.WriteString(ch.replication.info())
+	case "cluster":
+		_, enabled := ch.clusterMode()
+		fmt.Fprintf(&sb, "cluster_enabled:%d\r\n", enabled)
+	}
+	return sb.String()
+}
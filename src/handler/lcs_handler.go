@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/lcs/
+func handleLcs(cmd data.Array, strg storage.StorageEngine) data.Message {
+	getLen := false
+	getIdx := false
+	withMatchLen := false
+	var minMatchLen int64
+
+	for idx := 3; idx < len(cmd.Elements); idx++ {
+		option := cmd.Elements[idx].(data.BulkString).Data
+		switch strings.ToUpper(option) {
+		case "LEN":
+			getLen = true
+		case "IDX":
+			getIdx = true
+		case "WITHMATCHLEN":
+			withMatchLen = true
+		case "MINMATCHLEN":
+			if idx+1 >= len(cmd.Elements) {
+				return SYNTAX_ERROR
+			}
+			parsed, err := strconv.ParseInt(cmd.Elements[idx+1].(data.BulkString).Data, 10, 64)
+			if err != nil {
+				return NOT_AN_INTEGER
+			}
+			minMatchLen = max(parsed, 0)
+			idx++
+		default:
+			return SYNTAX_ERROR
+		}
+	}
+
+	if getLen && getIdx {
+		return data.Error{ErrMsg: "If you want both the length and indexes, please just use IDX."}
+	}
+
+	// both keys are read at once, missing keys are treated as empty strings
+	_, values, err := strg.MGet([]string{cmd.Elements[1].(data.BulkString).Data, cmd.Elements[2].(data.BulkString).Data})
+	if err != nil {
+		return storageError("retrieve data", err)
+	}
+	a, b := values[0], values[1]
+
+	table := lcsTable(a, b)
+	lcsLen := table[len(a)][len(b)]
+	if getLen {
+		return data.Integer{Value: int64(lcsLen)}
+	}
+
+	// walk the table back from the end of both strings, collecting the common subsequence
+	// and the ranges of contiguous matches
+	result := make([]byte, lcsLen)
+	matches := []data.Message{}
+	noRange := len(a)
+	aRangeStart, aRangeEnd, bRangeStart, bRangeEnd := noRange, 0, 0, 0
+	resultIdx := lcsLen
+	i, j := len(a), len(b)
+	for i > 0 && j > 0 {
+		emitRange := false
+		if a[i-1] == b[j-1] {
+			result[resultIdx-1] = a[i-1]
+
+			if aRangeStart == noRange {
+				aRangeStart, aRangeEnd = i-1, i-1
+				bRangeStart, bRangeEnd = j-1, j-1
+			} else if aRangeStart == i && bRangeStart == j {
+				// the match is contiguous with the current range, extend it backwards
+				aRangeStart--
+				bRangeStart--
+			} else {
+				emitRange = true
+			}
+
+			// a match with the first byte of either string ends the walk
+			if aRangeStart == 0 || bRangeStart == 0 {
+				emitRange = true
+			}
+			resultIdx--
+			i--
+			j--
+		} else {
+			if table[i-1][j] > table[i][j-1] {
+				i--
+			} else {
+				j--
+			}
+			if aRangeStart != noRange {
+				emitRange = true
+			}
+		}
+
+		if emitRange {
+			matchLen := int64(aRangeEnd - aRangeStart + 1)
+			if getIdx && (minMatchLen == 0 || matchLen >= minMatchLen) {
+				match := []data.Message{
+					data.Array{Elements: []data.Message{data.Integer{Value: int64(aRangeStart)}, data.Integer{Value: int64(aRangeEnd)}}},
+					data.Array{Elements: []data.Message{data.Integer{Value: int64(bRangeStart)}, data.Integer{Value: int64(bRangeEnd)}}},
+				}
+				if withMatchLen {
+					match = append(match, data.Integer{Value: matchLen})
+				}
+				matches = append(matches, data.Array{Elements: match})
+			}
+			aRangeStart = noRange
+		}
+	}
+
+	if getIdx {
+		return data.Array{Elements: []data.Message{
+			data.BulkString{Data: "matches"},
+			data.Array{Elements: matches},
+			data.BulkString{Data: "len"},
+			data.Integer{Value: int64(lcsLen)},
+		}}
+	}
+	return data.BulkString{Data: string(result)}
+}
+
+// lcsTable computes the length of the longest common subsequence of every pair of prefixes of a and b.
+// the entry at [i][j] is the length for the first i bytes of a and the first j bytes of b.
+func lcsTable(a string, b string) [][]int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				table[i][j] = table[i-1][j-1] + 1
+			} else {
+				table[i][j] = max(table[i-1][j], table[i][j-1])
+			}
+		}
+	}
+	return table
+}
@@ -21,7 +21,7 @@ func handleListPush(cmdArray data.Array, strg storage.StorageEngine, isPrepend b
 
 	res, err := strg.ListPush(listToUpdate, listValues, isPrepend)
 	if err != nil {
-		return data.Error{ErrMsg: "command failed. error: " + err.Error()}
+		return storageError("push", err)
 	}
 
 	return data.Integer{Value: res}
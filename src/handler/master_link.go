@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// masterLink is the connection of a replica with its master. it goes through the handshake, loads the
+// snapshot sent by the master and then applies the replication stream, reconnecting whenever the
+// connection is lost until the link is stopped.
+type masterLink struct {
+	handler CommandHandler
+	host    string
+	port    string
+	stopCh  chan struct{}
+	done    chan struct{}
+
+	mu     sync.Mutex
+	state  string
+	lastIO time.Time
+	conn   net.Conn
+	// writeMu keeps the acknowledgements sent to the master from interleaving
+	writeMu sync.Mutex
+}
+
+func newMasterLink(ch CommandHandler, host string, port string) *masterLink {
+	return &masterLink{
+		handler: ch,
+		host:    host,
+		port:    port,
+		stopCh:  make(chan struct{}),
+		done:    make(chan struct{}),
+		state:   REPL_STATE_CONNECT,
+	}
+}
+
+func (l *masterLink) State() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+// LastIO returns the last time data was received from the master.
+func (l *masterLink) LastIO() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lastIO
+}
+
+func (l *masterLink) setState(state string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.state = state
+	l.lastIO = time.Now()
+}
+
+// setConn records the connection with the master so that stop can close it.
+// it reports false when the link was stopped in the meantime.
+func (l *masterLink) setConn(conn net.Conn) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-l.stopCh:
+		return false
+	default:
+		l.conn = conn
+		return true
+	}
+}
+
+// stop closes the connection with the master and waits for the link to be done with it.
+func (l *masterLink) stop() {
+	close(l.stopCh)
+	l.mu.Lock()
+	if l.conn != nil {
+		_ = l.conn.Close()
+	}
+	l.mu.Unlock()
+	<-l.done
+}
+
+func (l *masterLink) run() {
+	defer close(l.done)
+	addr := net.JoinHostPort(l.host, l.port)
+	for {
+		err := l.replicate(addr)
+		l.setState(REPL_STATE_CONNECT)
+		select {
+		case <-l.stopCh:
+			return
+		default:
+		}
+
+		slog.Warn("lost the connection with the master", "addr", addr, "error", err.Error())
+		select {
+		case <-l.stopCh:
+			return
+		case <-time.After(REPL_RETRY_PERIOD):
+		}
+	}
+}
+
+// send writes a command to the master.
+func (l *masterLink) send(conn net.Conn, args ...string) error {
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+	_, err := conn.Write([]byte(newCommand(args...).ToDataString()))
+	return err
+}
+
+// call sends a command of the handshake to the master and returns its reply, failing on error replies.
+func (l *masterLink) call(conn net.Conn, reader *bufio.Reader, args ...string) (data.Message, error) {
+	if err := l.send(conn, args...); err != nil {
+		return nil, err
+	}
+	reply, err := data.ReadMessage(reader)
+	if err != nil {
+		return nil, err
+	}
+	if errMsg, ok := reply.(data.Error); ok {
+		return nil, fmt.Errorf("master replied to %s with: %s", args[0], errMsg.ErrMsg)
+	}
+	return reply, nil
+}
+
+// replicate connects to the master and applies the replication stream until the connection is lost.
+func (l *masterLink) replicate(addr string) error {
+	l.setState(REPL_STATE_CONNECTING)
+	conn, err := net.DialTimeout("tcp", addr, REPL_CONNECT_LIMIT)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	if !l.setConn(conn) {
+		return net.ErrClosed
+	}
+
+	reader := bufio.NewReader(conn)
+	if err := l.handshake(conn, reader); err != nil {
+		return err
+	}
+
+	// the master is a client of the replica, whose commands are applied without checks
+	master := l.handler.clients.register(conn, l.handler, true)
+	master.setType(CLIENT_TYPE_MASTER)
+	defer master.Close()
+
+	l.setState(REPL_STATE_CONNECTED)
+	slog.Info("replicating the master", "addr", addr)
+
+	stopAcks := make(chan struct{})
+	defer close(stopAcks)
+	go l.sendAcks(conn, stopAcks)
+
+	for {
+		msg, err := data.ReadMessage(reader)
+		if err != nil {
+			return err
+		}
+		l.setState(REPL_STATE_CONNECTED)
+		l.apply(conn, master, msg)
+	}
+}
+
+// handshake authenticates with the master when needed, presents the replica and synchronises with the master.
+func (l *masterLink) handshake(conn net.Conn, reader *bufio.Reader) error {
+	cfg := l.handler.cfg
+	if password := cfg.Get("masterauth"); password != "" {
+		args := []string{CMD_AUTH, password}
+		if user := cfg.Get("masteruser"); user != "" {
+			args = []string{CMD_AUTH, user, password}
+		}
+		if _, err := l.call(conn, reader, args...); err != nil {
+			return err
+		}
+	}
+	if _, err := l.call(conn, reader, CMD_PING); err != nil {
+		return err
+	}
+	if _, err := l.call(conn, reader, CMD_REPLCONF, "listening-port", cfg.Get("port")); err != nil {
+		return err
+	}
+	if _, err := l.call(conn, reader, CMD_REPLCONF, "capa", "psync2"); err != nil {
+		return err
+	}
+
+	replication := l.handler.replication
+	replID, offset := replication.psyncArgs()
+	reply, err := l.call(conn, reader, CMD_PSYNC, replID, offset)
+	if err != nil {
+		return err
+	}
+	status, ok := reply.(data.SimpleString)
+	if !ok {
+		return fmt.Errorf("unexpected reply to PSYNC")
+	}
+
+	l.setState(REPL_STATE_SYNC)
+	fields := strings.Fields(status.Contents)
+	switch {
+	case len(fields) >= 1 && fields[0] == "CONTINUE":
+		newID := ""
+		if len(fields) > 1 {
+			newID = fields[1]
+		}
+		replication.continueWith(newID)
+		slog.Info("partially resynchronised with the master")
+		return nil
+	case len(fields) == 3 && fields[0] == "FULLRESYNC":
+		masterOffset, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid offset in FULLRESYNC reply")
+		}
+		snapshot, err := readSnapshot(reader)
+		if err != nil {
+			return err
+		}
+		if err := replication.loadSnapshot(fields[1], masterOffset, snapshot); err != nil {
+			return err
+		}
+		slog.Info("fully resynchronised with the master", "bytes", len(snapshot))
+		return nil
+	default:
+		return fmt.Errorf("unexpected reply to PSYNC: %s", status.Contents)
+	}
+}
+
+// readSnapshot reads the snapshot sent after FULLRESYNC, which is a bulk string without the final CRLF.
+func readSnapshot(reader *bufio.Reader) ([]byte, error) {
+	line, err := data.ReadLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "$") {
+		return nil, errors.New("invalid snapshot header")
+	}
+	size, err := strconv.Atoi(line[1:])
+	if err != nil || size < 0 {
+		return nil, errors.New("invalid snapshot size")
+	}
+
+	snapshot := make([]byte, size)
+	if _, err := io.ReadFull(reader, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// isGetAck reports whether the command is REPLCONF GETACK, which the master sends to ask for the offset.
+func isGetAck(msg data.Message) bool {
+	cmd, ok := msg.(data.Array)
+	if !ok || len(cmd.Elements) < 2 {
+		return false
+	}
+	name, nameOk := cmd.Elements[0].(data.BulkString)
+	option, optionOk := cmd.Elements[1].(data.BulkString)
+	return nameOk && optionOk && strings.EqualFold(name.Data, CMD_REPLCONF) && strings.EqualFold(option.Data, "GETACK")
+}
+
+// apply executes a command of the replication stream and passes it on to the replicas of the replica.
+func (l *masterLink) apply(conn net.Conn, master *Client, msg data.Message) {
+	replication := l.handler.replication
+	replication.writeMu.Lock()
+	defer replication.writeMu.Unlock()
+
+	if isGetAck(msg) {
+		l.ack(conn)
+	} else {
+		l.handler.HandleClientCommand(master, msg)
+	}
+	replication.feed([]byte(msg.ToDataString()))
+}
+
+// ack sends the offset of the replica to the master.
+func (l *masterLink) ack(conn net.Conn) {
+	offset := l.handler.replication.Offset()
+	if err := l.send(conn, CMD_REPLCONF, "ACK", strconv.FormatInt(offset, 10)); err != nil {
+		slog.Debug("failed to acknowledge the replication offset", "error", err.Error())
+	}
+}
+
+// sendAcks periodically sends the offset of the replica to the master until stopped.
+func (l *masterLink) sendAcks(conn net.Conn, stop <-chan struct{}) {
+	ticker := time.NewTicker(REPL_ACK_PERIOD)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			l.ack(conn)
+		}
+	}
+}
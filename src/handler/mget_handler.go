@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/mget/
+func handleMGet(cmd data.Array, strg storage.StorageEngine) data.Message {
+	keys := make([]string, len(cmd.Elements)-1)
+	for idx := range keys {
+		keys[idx] = cmd.Elements[idx+1].(data.BulkString).Data
+	}
+
+	found, values, err := strg.MGet(keys)
+	if err != nil {
+		return storageError("retrieve data", err)
+	}
+
+	elements := make([]data.Message, len(keys))
+	for idx := range keys {
+		if found[idx] {
+			elements[idx] = data.BulkString{Data: values[idx]}
+		} else {
+			elements[idx] = data.Null{}
+		}
+	}
+	return data.Array{Elements: elements}
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+var (
+	MIGRATE_NOKEY        = data.SimpleString{Contents: "NOKEY"}
+	MIGRATE_CONNECT_FAIL = data.Error{ErrMsg: "IOERR error or timeout connecting to the client"}
+	MIGRATE_IO_FAIL      = data.Error{ErrMsg: "IOERR error or timeout reading to target instance"}
+	MIGRATE_INVALID_DB   = data.Error{ErrMsg: "DB index is out of range"}
+	// the DUMP payloads of this server are not compatible with redis
+	MIGRATE_FOREIGN_TARGET = data.Error{ErrMsg: "Target instance is not a cc-kv-go server, which is the only one the keys can be migrated to"}
+)
+
+// migrateArgs holds the parsed arguments of MIGRATE.
+type migrateArgs struct {
+	addr    string
+	db      int64
+	timeout time.Duration
+	copy    bool
+	replace bool
+	// auth holds the arguments of AUTH for the target, if any
+	auth []string
+	keys []string
+}
+
+// parseMigrate parses the arguments of MIGRATE host port key|"" destination-db timeout [options].
+func parseMigrate(args []string) (migrateArgs, data.Message) {
+	port, err := strconv.Atoi(args[1])
+	if err != nil {
+		return migrateArgs{}, NOT_AN_INTEGER
+	}
+	m := migrateArgs{addr: net.JoinHostPort(args[0], strconv.Itoa(port))}
+	if m.db, err = strconv.ParseInt(args[3], 10, 64); err != nil {
+		return migrateArgs{}, NOT_AN_INTEGER
+	}
+	timeout, err := strconv.ParseInt(args[4], 10, 64)
+	if err != nil {
+		return migrateArgs{}, NOT_AN_INTEGER
+	}
+	if timeout <= 0 {
+		timeout = 1000
+	}
+	m.timeout = time.Duration(timeout) * time.Millisecond
+
+	for idx := 5; idx < len(args); idx++ {
+		switch option := strings.ToUpper(args[idx]); {
+		case option == "COPY":
+			m.copy = true
+		case option == "REPLACE":
+			m.replace = true
+		case option == "AUTH" && idx+1 < len(args):
+			m.auth = []string{args[idx+1]}
+			idx++
+		case option == "AUTH2" && idx+2 < len(args):
+			m.auth = []string{args[idx+1], args[idx+2]}
+			idx += 2
+		case option == "KEYS":
+			if args[2] != "" {
+				return migrateArgs{}, data.Error{ErrMsg: "When using MIGRATE KEYS option, the key argument must be set to the empty string"}
+			}
+			m.keys = args[idx+1:]
+			idx = len(args)
+		default:
+			return migrateArgs{}, SYNTAX_ERROR
+		}
+	}
+	if m.keys == nil {
+		m.keys = []string{args[2]}
+	}
+	return m, nil
+}
+
+// migrateKeys returns the keys of MIGRATE, which are either its key argument or the ones after KEYS.
+func migrateKeys(args []string) []string {
+	m, errMsg := parseMigrate(args[1:])
+	if errMsg != nil {
+		return nil
+	}
+	return m.keys
+}
+
+// https://redis.io/docs/latest/commands/migrate/
+// the keys are sent with RESTORE on a new connection and deleted once the target stored all of them, unless
+// COPY is set. nothing is deleted when the target fails to store any of the keys. since the DUMP payloads
+// can only be restored by this server, the target must be another cc-kv-go server, which it tells with HELLO.
+func handleMigrate(ch CommandHandler, cmd data.Array) data.Message {
+	m, errMsg := parseMigrate(bulkStrings(cmd.Elements[1:]))
+	if errMsg != nil {
+		return errMsg
+	}
+	// like the rest of the server, the targets only have database 0
+	if m.db != 0 {
+		return MIGRATE_INVALID_DB
+	}
+
+	payloads := [][]byte{}
+	keys := []string{}
+	ttls := []string{}
+	for _, key := range m.keys {
+		ok, payload, expiresAt, err := ch.strgEngine.Dump(key)
+		if err != nil {
+			return storageError("dump value", err)
+		}
+		if !ok {
+			continue
+		}
+		// the expiry is sent as the remaining time to live, where 0 is no expiry
+		ttl := int64(0)
+		if !expiresAt.IsZero() {
+			ttl = max(time.Until(expiresAt).Milliseconds(), 1)
+		}
+		keys = append(keys, key)
+		payloads = append(payloads, payload)
+		ttls = append(ttls, strconv.FormatInt(ttl, 10))
+	}
+	if len(keys) == 0 {
+		return MIGRATE_NOKEY
+	}
+
+	conn, err := net.DialTimeout("tcp", m.addr, m.timeout)
+	if err != nil {
+		return MIGRATE_CONNECT_FAIL
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(m.timeout))
+	reader := bufio.NewReader(conn)
+
+	handshake := []data.Array{}
+	if m.auth != nil {
+		handshake = append(handshake, newCommand(append([]string{CMD_AUTH}, m.auth...)...))
+	}
+	handshake = append(handshake, newCommand(CMD_HELLO))
+	replies, errMsg := migrateRoundTrip(conn, reader, handshake)
+	if errMsg != nil {
+		return errMsg
+	}
+	if !isSelf(replies[len(replies)-1]) {
+		return MIGRATE_FOREIGN_TARGET
+	}
+
+	restore := "RESTORE"
+	if ch.cluster != nil {
+		// the target may be importing the slot of the keys
+		restore = "RESTORE-ASKING"
+	}
+	restores := []data.Array{}
+	for idx, key := range keys {
+		args := []string{restore, key, ttls[idx], string(payloads[idx])}
+		if m.replace {
+			args = append(args, "REPLACE")
+		}
+		restores = append(restores, newCommand(args...))
+	}
+	if _, errMsg := migrateRoundTrip(conn, reader, restores); errMsg != nil {
+		return errMsg
+	}
+
+	if !m.copy {
+		if _, err := ch.strgEngine.Delete(keys); err != nil {
+			return storageError("delete data", err)
+		}
+	}
+	return OK
+}
+
+// migrateRoundTrip pipelines commands to the target of MIGRATE and reads their replies in the same order,
+// returning the first error replied by the target.
+func migrateRoundTrip(conn net.Conn, reader *bufio.Reader, commands []data.Array) ([]data.Message, data.Message) {
+	var sb strings.Builder
+	for _, command := range commands {
+		sb.WriteString(command.ToDataString())
+	}
+	if _, err := conn.Write([]byte(sb.String())); err != nil {
+		return nil, MIGRATE_IO_FAIL
+	}
+
+	replies := []data.Message{}
+	var targetErr data.Message
+	for range commands {
+		reply, err := data.ReadMessage(reader)
+		if err != nil {
+			return nil, MIGRATE_IO_FAIL
+		}
+		if errReply, ok := reply.(data.Error); ok && targetErr == nil {
+			targetErr = data.Error{ErrMsg: fmt.Sprintf("Target instance replied with error: %s", errReply.ErrMsg)}
+		}
+		replies = append(replies, reply)
+	}
+	return replies, targetErr
+}
+
+// isSelf reports whether a reply to HELLO comes from a cc-kv-go server.
+func isSelf(hello data.Message) bool {
+	fields, ok := hello.(data.Array)
+	if !ok {
+		return false
+	}
+	for idx := 0; idx+1 < len(fields.Elements); idx += 2 {
+		if fields.Elements[idx] == (data.BulkString{Data: "server"}) {
+			return fields.Elements[idx+1] == data.BulkString{Data: SERVER_NAME}
+		}
+	}
+	return false
+}
+
+// propagateMigrate sends the deletion of the migrated keys to the replicas in place of MIGRATE.
+func propagateMigrate(cmd data.Array, reply data.Message, _ storage.StorageEngine) []data.Array {
+	m, errMsg := parseMigrate(bulkStrings(cmd.Elements[1:]))
+	if errMsg != nil || m.copy || reply != OK {
+		return nil
+	}
+	return []data.Array{newCommand(append([]string{"DEL"}, m.keys...)...)}
+}
+
+// newCommand builds a command to send to another server.
+func newCommand(args ...string) data.Array {
+	elements := make([]data.Message, len(args))
+	for idx, arg := range args {
+		elements[idx] = data.BulkString{Data: arg}
+	}
+	return data.Array{Elements: elements}
+}
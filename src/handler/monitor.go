@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// Monitors keeps track of the clients that are streaming the executed commands with MONITOR.
+type Monitors struct {
+	mu      sync.RWMutex
+	clients map[int64]*Client
+
+	// checked before doing any work so that commands are not slowed down when nobody is monitoring
+	count atomic.Int64
+}
+
+func NewMonitors() *Monitors {
+	return &Monitors{
+		clients: make(map[int64]*Client),
+	}
+}
+
+func (m *Monitors) add(client *Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clients[client.id] = client
+	m.count.Store(int64(len(m.clients)))
+}
+
+func (m *Monitors) remove(id int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.clients, id)
+	m.count.Store(int64(len(m.clients)))
+}
+
+// feed sends the command executed by the client, or by the script when it's set, to every monitor.
+// administrative commands are never shown and the credentials given to AUTH are redacted.
+func (m *Monitors) feed(client *Client, script *scriptRun, spec *commandSpec, cmdArray data.Array) {
+	if m.count.Load() == 0 || spec.hasFlag(CMD_FLAG_ADMIN) {
+		return
+	}
+
+	var db int
+	var source string
+	switch {
+	case script != nil:
+		// like redis, the commands of a script are shown with lua as their source, in the database of its caller
+		source = "lua"
+		if script.client != nil {
+			db = script.client.DB()
+		}
+	case client != nil:
+		db, source = client.DB(), client.monitorAddr()
+	default:
+		return
+	}
+	line := monitorLine(time.Now(), db, source, cmdArray)
+
+	m.mu.RLock()
+	monitors := make([]*Client, 0, len(m.clients))
+	for _, monitor := range m.clients {
+		monitors = append(monitors, monitor)
+	}
+	m.mu.RUnlock()
+
+	for _, monitor := range monitors {
+		if err := monitor.push(line); err != nil {
+			m.remove(monitor.id)
+		}
+	}
+}
+
+// monitorLine renders a command in the MONITOR format: +<unix time> [<db> <source>] "<arg>" ..., where the source
+// is the address of the client or lua.
+func monitorLine(now time.Time, db int, source string, cmdArray data.Array) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "+%d.%06d [%d %s]", now.Unix(), now.Nanosecond()/1000, db, source)
+
+	for idx, element := range cmdArray.Elements {
+		arg := element.(data.BulkString).Data
+		if idx > 0 && strings.EqualFold(cmdArray.Elements[0].(data.BulkString).Data, CMD_AUTH) {
+			arg = "(redacted)"
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(quoteArg(arg))
+	}
+	sb.WriteString("\r\n")
+	return sb.String()
+}
+
+// quoteArg quotes an argument the way redis does, escaping the characters that cannot be printed as they are.
+func quoteArg(arg string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for idx := range len(arg) {
+		char := arg[idx]
+		switch char {
+		case '\\', '"':
+			sb.WriteByte('\\')
+			sb.WriteByte(char)
+		case '\n':
+			sb.WriteString("\\n")
+		case '\r':
+			sb.WriteString("\\r")
+		case '\t':
+			sb.WriteString("\\t")
+		case '\a':
+			sb.WriteString("\\a")
+		case '\b':
+			sb.WriteString("\\b")
+		default:
+			if strconv.IsPrint(rune(char)) && char < 0x7f {
+				sb.WriteByte(char)
+			} else {
+				fmt.Fprintf(&sb, "\\x%02x", char)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// https://redis.io/docs/latest/commands/monitor/
+func handleMonitor(client *Client, monitors *Monitors) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+
+	client.setMonitoring()
+	monitors.add(client)
+	return OK
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/mset/
+// https://redis.io/docs/latest/commands/msetnx/
+func handleMSet(cmd data.Array, strg storage.StorageEngine, onlyIfNoneExist bool) data.Message {
+	if len(cmd.Elements)%2 == 0 {
+		commandName := "mset"
+		if onlyIfNoneExist {
+			commandName = "msetnx"
+		}
+		return data.Error{ErrMsg: fmt.Sprintf("wrong number of arguments for '%s' command", commandName)}
+	}
+
+	numPairs := (len(cmd.Elements) - 1) / 2
+	keys := make([]string, numPairs)
+	values := make([]string, numPairs)
+	for idx := range numPairs {
+		keys[idx] = cmd.Elements[1+2*idx].(data.BulkString).Data
+		values[idx] = cmd.Elements[2+2*idx].(data.BulkString).Data
+	}
+
+	if !onlyIfNoneExist {
+		if err := strg.MSet(keys, values); err != nil {
+			return storageError("store data", err)
+		}
+		return OK
+	}
+
+	stored, err := strg.MSetNX(keys, values)
+	if err != nil {
+		return storageError("store data", err)
+	}
+	if !stored {
+		return data.Integer{Value: 0}
+	}
+	return data.Integer{Value: 1}
+}
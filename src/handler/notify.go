@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+const (
+	// the active expiry cycle runs every ACTIVE_EXPIRE_INTERVAL, checking ACTIVE_EXPIRE_SAMPLE keys at a time for as
+	// long as more than a quarter of them have expired, but never for longer than ACTIVE_EXPIRE_BUDGET
+	ACTIVE_EXPIRE_INTERVAL = 100 * time.Millisecond
+	ACTIVE_EXPIRE_SAMPLE   = 20
+	ACTIVE_EXPIRE_BUDGET   = 25 * time.Millisecond
+)
+
+// KeyspaceNotifications publishes the changes made to the dataset over Pub/Sub, for the classes of events enabled
+// with notify-keyspace-events. the events of a key are published on __keyspace@<db>__:<key> and the keys of an
+// event on __keyevent@<db>__:<event>.
+type KeyspaceNotifications struct {
+	pubsub *PubSub
+	flags  atomic.Pointer[string]
+}
+
+func NewKeyspaceNotifications(cfg *config.Config, pubsub *PubSub) *KeyspaceNotifications {
+	kn := &KeyspaceNotifications{pubsub: pubsub}
+	kn.setFlags(cfg.Get("notify-keyspace-events"))
+	cfg.Watch("notify-keyspace-events", kn.setFlags)
+	return kn
+}
+
+func (kn *KeyspaceNotifications) setFlags(value string) {
+	// the value has been validated by the configuration
+	flags, _ := config.ParseKeyspaceEvents(value)
+	kn.flags.Store(&flags)
+}
+
+// notify publishes a keyspace event of the storage engine, unless its class is disabled.
+func (kn *KeyspaceNotifications) notify(event storage.KeyspaceEvent) {
+	flags := *kn.flags.Load()
+	if flags == "" || strings.IndexByte(flags, event.Class) < 0 {
+		return
+	}
+	if strings.IndexByte(flags, config.NOTIFY_KEYSPACE) >= 0 {
+		kn.pubsub.publish("__keyspace@0__:"+event.Key, event.Name)
+	}
+	if strings.IndexByte(flags, config.NOTIFY_KEYEVENT) >= 0 {
+		kn.pubsub.publish("__keyevent@0__:"+event.Name, event.Key)
+	}
+}
+
+// activeExpiry removes the expired keys in the background, since the keys that are never accessed again
+// would otherwise only be removed when they are looked up.
+type activeExpiry struct {
+	mu   sync.Mutex
+	stop chan struct{}
+}
+
+// StartActiveExpiry starts removing the expired keys in the background.
+func (ch CommandHandler) StartActiveExpiry() {
+	ch.expiry.mu.Lock()
+	defer ch.expiry.mu.Unlock()
+	if ch.expiry.stop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	ch.expiry.stop = stop
+	go func() {
+		ticker := time.NewTicker(ACTIVE_EXPIRE_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ch.expireCycle()
+			}
+		}
+	}()
+}
+
+// StopActiveExpiry stops removing the expired keys in the background.
+func (ch CommandHandler) StopActiveExpiry() {
+	ch.expiry.mu.Lock()
+	defer ch.expiry.mu.Unlock()
+	if ch.expiry.stop != nil {
+		close(ch.expiry.stop)
+		ch.expiry.stop = nil
+	}
+}
+
+// expireCycle removes expired keys until few of the sampled keys have expired or the cycle ran out of time.
+func (ch CommandHandler) expireCycle() {
+	// keys don't expire while a script runs, like the commands the cycle waits for
+	done, errMsg := ch.scripts.enterCommand()
+	if errMsg != nil {
+		return
+	}
+	defer done()
+
+	deadline := time.Now().Add(ACTIVE_EXPIRE_BUDGET)
+	for {
+		expired, sampled := ch.strgEngine.DeleteExpired(ACTIVE_EXPIRE_SAMPLE)
+		if expired*4 <= sampled || time.Now().After(deadline) {
+			return
+		}
+	}
+}
@@ -3,9 +3,18 @@ package handler
 import "github.com/vrajashkr/cc-kv-go/src/data"
 
 // https://redis.io/docs/latest/commands/ping/
-func handlePing(cmd data.Array) data.Message {
+// clients subscribed to channels are replied in the format of the messages they receive.
+func handlePing(client *Client, cmd data.Array) data.Message {
 	cmdLen := len(cmd.Elements)
 
+	if client != nil && client.Type() == CLIENT_TYPE_PUBSUB {
+		message := data.BulkString{}
+		if cmdLen > 1 {
+			message = cmd.Elements[1].(data.BulkString)
+		}
+		return data.Array{Elements: []data.Message{data.BulkString{Data: "pong"}, message}}
+	}
+
 	if cmdLen == 1 {
 		return data.SimpleString{Contents: "PONG"}
 	}
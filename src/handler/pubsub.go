@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/glob"
+)
+
+// multiReply is the reply of the commands answered with several messages, like SUBSCRIBE with one per channel.
+type multiReply []data.Message
+
+func (m multiReply) ToDataString() string {
+	result := ""
+	for _, msg := range m {
+		result += msg.ToDataString()
+	}
+	return result
+}
+
+// subscriptions holds the channels and the patterns a client is subscribed to.
+type subscriptions struct {
+	channels map[string]struct{}
+	patterns map[string]struct{}
+}
+
+func (s *subscriptions) count() int {
+	return len(s.channels) + len(s.patterns)
+}
+
+// PubSub keeps track of the clients subscribed to channels with SUBSCRIBE, or to the channels matching a
+// pattern with PSUBSCRIBE, and delivers the messages published to them.
+type PubSub struct {
+	mu       sync.RWMutex
+	channels map[string]map[int64]*Client
+	patterns map[string]map[int64]*Client
+	clients  map[int64]*subscriptions
+}
+
+func NewPubSub() *PubSub {
+	return &PubSub{
+		channels: make(map[string]map[int64]*Client),
+		patterns: make(map[string]map[int64]*Client),
+		clients:  make(map[int64]*subscriptions),
+	}
+}
+
+// subscriptionsLocked returns the subscriptions of the client, creating them if needed.
+// It must be called with the lock held.
+func (ps *PubSub) subscriptionsLocked(client *Client) *subscriptions {
+	subs, ok := ps.clients[client.id]
+	if !ok {
+		subs = &subscriptions{channels: make(map[string]struct{}), patterns: make(map[string]struct{})}
+		ps.clients[client.id] = subs
+	}
+	return subs
+}
+
+// subscribe adds the client to the channels, or to the patterns when pattern is set, and returns the
+// confirmations along with the number of subscriptions of the client after each of them.
+func (ps *PubSub) subscribe(client *Client, targets []string, pattern bool) multiReply {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	kind, subscribers := "subscribe", ps.channels
+	if pattern {
+		kind, subscribers = "psubscribe", ps.patterns
+	}
+
+	subs := ps.subscriptionsLocked(client)
+	reply := make(multiReply, len(targets))
+	for idx, target := range targets {
+		if _, ok := subscribers[target]; !ok {
+			subscribers[target] = make(map[int64]*Client)
+		}
+		subscribers[target][client.id] = client
+		if pattern {
+			subs.patterns[target] = struct{}{}
+		} else {
+			subs.channels[target] = struct{}{}
+		}
+		reply[idx] = subscriptionReply(kind, data.BulkString{Data: target}, subs.count())
+	}
+	client.setType(CLIENT_TYPE_PUBSUB)
+	return reply
+}
+
+// unsubscribe removes the client from the channels, or from the patterns when pattern is set, and returns
+// the confirmations. without any target, the client is removed from all of them.
+func (ps *PubSub) unsubscribe(client *Client, targets []string, pattern bool) multiReply {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	kind, subscribers := "unsubscribe", ps.channels
+	if pattern {
+		kind, subscribers = "punsubscribe", ps.patterns
+	}
+
+	subs, ok := ps.clients[client.id]
+	if !ok {
+		subs = &subscriptions{}
+	}
+	owned := subs.channels
+	if pattern {
+		owned = subs.patterns
+	}
+	if len(targets) == 0 {
+		targets = slices.Sorted(maps.Keys(owned))
+	}
+
+	reply := make(multiReply, len(targets))
+	for idx, target := range targets {
+		delete(owned, target)
+		delete(subscribers[target], client.id)
+		if len(subscribers[target]) == 0 {
+			delete(subscribers, target)
+		}
+		reply[idx] = subscriptionReply(kind, data.BulkString{Data: target}, subs.count())
+	}
+	if ok && subs.count() == 0 {
+		delete(ps.clients, client.id)
+		client.setType(CLIENT_TYPE_NORMAL)
+	}
+
+	// unsubscribing without being subscribed to anything is still confirmed
+	if len(reply) == 0 {
+		return multiReply{subscriptionReply(kind, data.Null{}, subs.count())}
+	}
+	return reply
+}
+
+// remove drops every subscription of the client once it disconnects.
+func (ps *PubSub) remove(client *Client) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	subs, ok := ps.clients[client.id]
+	if !ok {
+		return
+	}
+	for channel := range subs.channels {
+		delete(ps.channels[channel], client.id)
+		if len(ps.channels[channel]) == 0 {
+			delete(ps.channels, channel)
+		}
+	}
+	for pattern := range subs.patterns {
+		delete(ps.patterns[pattern], client.id)
+		if len(ps.patterns[pattern]) == 0 {
+			delete(ps.patterns, pattern)
+		}
+	}
+	delete(ps.clients, client.id)
+}
+
+// publish delivers the message to the subscribers of the channel and to the subscribers of the patterns
+// matching it, and returns the number of clients that received it.
+func (ps *PubSub) publish(channel string, message string) int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if len(ps.clients) == 0 {
+		return 0
+	}
+
+	receivers := 0
+	if subscribers, ok := ps.channels[channel]; ok {
+		msg := data.Array{Elements: []data.Message{
+			data.BulkString{Data: "message"},
+			data.BulkString{Data: channel},
+			data.BulkString{Data: message},
+		}}.ToDataString()
+		for _, client := range subscribers {
+			_ = client.push(msg)
+			receivers++
+		}
+	}
+
+	for pattern, subscribers := range ps.patterns {
+		if !glob.Match(pattern, channel) {
+			continue
+		}
+		msg := data.Array{Elements: []data.Message{
+			data.BulkString{Data: "pmessage"},
+			data.BulkString{Data: pattern},
+			data.BulkString{Data: channel},
+			data.BulkString{Data: message},
+		}}.ToDataString()
+		for _, client := range subscribers {
+			_ = client.push(msg)
+			receivers++
+		}
+	}
+	return receivers
+}
+
+// subscribed reports whether the client is subscribed to the channel itself, rather than to a pattern matching it.
+func (ps *PubSub) subscribed(client *Client, channel string) bool {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	_, ok := ps.channels[channel][client.id]
+	return ok
+}
+
+// activeChannels returns the channels with at least one subscriber that match the pattern, if any.
+func (ps *PubSub) activeChannels(pattern string) []string {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	channels := []string{}
+	for channel := range ps.channels {
+		if pattern == "" || glob.Match(pattern, channel) {
+			channels = append(channels, channel)
+		}
+	}
+	slices.Sort(channels)
+	return channels
+}
+
+// numSub returns the number of subscribers of the channel, not counting the subscribers of patterns.
+func (ps *PubSub) numSub(channel string) int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return len(ps.channels[channel])
+}
+
+// numPat returns the number of patterns that clients are subscribed to.
+func (ps *PubSub) numPat() int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return len(ps.patterns)
+}
+
+func subscriptionReply(kind string, target data.Message, count int) data.Message {
+	return data.Array{Elements: []data.Message{
+		data.BulkString{Data: kind},
+		target,
+		data.Integer{Value: int64(count)},
+	}}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// the commands that clients subscribed to channels can still run. QUIT and RESET, which redis allows as well,
+// are not implemented.
+var SUBSCRIBED_COMMANDS = []string{"SUBSCRIBE", "PSUBSCRIBE", "UNSUBSCRIBE", "PUNSUBSCRIBE", CMD_PING}
+
+// https://redis.io/docs/latest/commands/subscribe/
+// https://redis.io/docs/latest/commands/psubscribe/
+func handleSubscribe(ch CommandHandler, client *Client, cmd data.Array, pattern bool) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+	return ch.pubsub.subscribe(client, bulkStrings(cmd.Elements[1:]), pattern)
+}
+
+// https://redis.io/docs/latest/commands/unsubscribe/
+// https://redis.io/docs/latest/commands/punsubscribe/
+func handleUnsubscribe(ch CommandHandler, client *Client, cmd data.Array, pattern bool) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+	return ch.pubsub.unsubscribe(client, bulkStrings(cmd.Elements[1:]), pattern)
+}
+
+// https://redis.io/docs/latest/commands/publish/
+func handlePublish(ch CommandHandler, cmd data.Array) data.Message {
+	args := bulkStrings(cmd.Elements[1:])
+	return data.Integer{Value: int64(ch.pubsub.publish(args[0], args[1]))}
+}
+
+// https://redis.io/docs/latest/commands/pubsub/
+func handlePubSub(ch CommandHandler, cmd data.Array) data.Message {
+	args := bulkStrings(cmd.Elements[1:])
+	switch strings.ToUpper(args[0]) {
+	case "CHANNELS":
+		if len(args) > 2 {
+			return data.Error{ErrMsg: "wrong number of arguments for 'pubsub|channels' command"}
+		}
+		pattern := ""
+		if len(args) == 2 {
+			pattern = args[1]
+		}
+		channels := ch.pubsub.activeChannels(pattern)
+		elements := make([]data.Message, len(channels))
+		for idx, channel := range channels {
+			elements[idx] = data.BulkString{Data: channel}
+		}
+		return data.Array{Elements: elements}
+	case "NUMSUB":
+		elements := make([]data.Message, 0, 2*(len(args)-1))
+		for _, channel := range args[1:] {
+			elements = append(elements, data.BulkString{Data: channel}, data.Integer{Value: int64(ch.pubsub.numSub(channel))})
+		}
+		return data.Array{Elements: elements}
+	case "NUMPAT":
+		return data.Integer{Value: int64(ch.pubsub.numPat())}
+	default:
+		return data.Error{ErrMsg: fmt.Sprintf("unsupported subcommand %s for PUBSUB", args[0])}
+	}
+}
+
+// checkSubscribed only lets the clients subscribed to channels run the commands that manage their subscriptions.
+func checkSubscribed(client *Client, spec *commandSpec) data.Message {
+	if client == nil || client.Type() != CLIENT_TYPE_PUBSUB {
+		return nil
+	}
+	for _, command := range SUBSCRIBED_COMMANDS {
+		if strings.EqualFold(spec.name, command) {
+			return nil
+		}
+	}
+	return data.Error{ErrMsg: fmt.Sprintf(
+		"Can't execute '%s': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING are allowed in this context",
+		spec.name,
+	)}
+}
+
+// publishChannels returns the channel of PUBLISH, which is checked against the channel permissions.
+func publishChannels(args []string) []string {
+	return args[1:2]
+}
+
+// subscribeChannels returns the channels of SUBSCRIBE, or the patterns of PSUBSCRIBE, which are checked
+// against the channel permissions.
+func subscribeChannels(args []string) []string {
+	return args[1:]
+}
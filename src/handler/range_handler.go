@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// the largest string that SETRANGE can create, matching the default proto-max-bulk-len of redis.
+const STRING_MAX_LEN = 512 * 1024 * 1024
+
+// https://redis.io/docs/latest/commands/getrange/
+func handleGetRange(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+	start, startErr := strconv.ParseInt(cmd.Elements[2].(data.BulkString).Data, 10, 64)
+	end, endErr := strconv.ParseInt(cmd.Elements[3].(data.BulkString).Data, 10, 64)
+	if startErr != nil || endErr != nil {
+		return NOT_AN_INTEGER
+	}
+
+	_, val, err := strg.Get(key)
+	if err != nil {
+		return storageError("retrieve data", err)
+	}
+
+	return data.BulkString{Data: substring(val, start, end)}
+}
+
+// substring returns the part of the value between start and end (both inclusive).
+// negative offsets count from the end of the value and out of range offsets are clamped.
+func substring(val string, start int64, end int64) string {
+	length := int64(len(val))
+	if start < 0 && end < 0 && start > end {
+		return ""
+	}
+
+	if start < 0 {
+		start = length + start
+	}
+	if end < 0 {
+		end = length + end
+	}
+	start = max(start, 0)
+	end = max(end, 0)
+	end = min(end, length-1)
+
+	if length == 0 || start > end {
+		return ""
+	}
+	return val[start : end+1]
+}
+
+// https://redis.io/docs/latest/commands/setrange/
+func handleSetRange(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+	value := cmd.Elements[3].(data.BulkString).Data
+
+	offset, err := strconv.ParseInt(cmd.Elements[2].(data.BulkString).Data, 10, 64)
+	if err != nil {
+		return NOT_AN_INTEGER
+	}
+	if offset < 0 {
+		return data.Error{ErrMsg: "offset is out of range"}
+	}
+	if offset+int64(len(value)) > STRING_MAX_LEN {
+		return data.Error{ErrMsg: "string exceeds maximum allowed size (proto-max-bulk-len)"}
+	}
+
+	length, err := strg.SetRange(key, offset, value)
+	if err != nil {
+		return storageError("set range", err)
+	}
+	return data.Integer{Value: length}
+}
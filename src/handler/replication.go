@@ -0,0 +1,526 @@
+package handler
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// replication follows the design of redis: the write commands are appended to a replication stream whose
+// bytes are numbered by the replication offset. a replica first loads a snapshot of the dataset taken at
+// some offset of the stream and then applies the stream from there. the end of the stream is kept in a
+// backlog, so that a replica reconnecting after a short disconnection only gets the part it missed.
+// a replication ID names the history of the stream, and a promoted replica remembers the ID of its
+// former master so that the other replicas can carry on with it.
+
+const (
+	REPL_ROLE_MASTER  = "master"
+	REPL_ROLE_REPLICA = "slave"
+
+	// the states of the link of a replica with its master, as shown by ROLE
+	REPL_STATE_CONNECT    = "connect"
+	REPL_STATE_CONNECTING = "connecting"
+	REPL_STATE_SYNC       = "sync"
+	REPL_STATE_CONNECTED  = "connected"
+
+	REPL_ID_LENGTH     = 40
+	REPL_ACK_PERIOD    = time.Second
+	REPL_RETRY_PERIOD  = time.Second
+	REPL_CONNECT_LIMIT = 5 * time.Second
+)
+
+var READONLY_REPLICA = data.Error{ErrMsg: "READONLY You can't write against a read only replica."}
+
+// the replication ID shown when there is none, like the secondary ID of a server that was never a replica.
+var NO_REPL_ID = strings.Repeat("0", REPL_ID_LENGTH)
+
+func newReplID() string {
+	id := make([]byte, REPL_ID_LENGTH/2)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// noReply is the reply of the commands that are not answered, like REPLCONF ACK.
+type noReply struct{}
+
+func (noReply) ToDataString() string {
+	return ""
+}
+
+// replBacklog keeps the last bytes of the replication stream in a circular buffer.
+type replBacklog struct {
+	buf    []byte
+	end    int
+	length int
+}
+
+func newReplBacklog(size int64) *replBacklog {
+	return &replBacklog{buf: make([]byte, max(size, 1))}
+}
+
+func (b *replBacklog) write(p []byte) {
+	size := len(b.buf)
+	if len(p) >= size {
+		copy(b.buf, p[len(p)-size:])
+		b.end, b.length = 0, size
+		return
+	}
+
+	copied := copy(b.buf[b.end:], p)
+	copy(b.buf, p[copied:])
+	b.end = (b.end + len(p)) % size
+	b.length = min(b.length+len(p), size)
+}
+
+// tail returns the last n bytes written, which must be held by the backlog.
+func (b *replBacklog) tail(n int) []byte {
+	size := len(b.buf)
+	start := (b.end - n + size) % size
+	if start+n <= size {
+		return append([]byte{}, b.buf[start:start+n]...)
+	}
+	return append(append([]byte{}, b.buf[start:]...), b.buf[:n-(size-start)]...)
+}
+
+// replicaInfo is a replica connected to this server along with the last offset it acknowledged.
+type replicaInfo struct {
+	client    *Client
+	ackOffset int64
+	ackTime   time.Time
+}
+
+// Replication holds the replication state of the server, whether it is a master or a replica.
+type Replication struct {
+	strg storage.StorageEngine
+	cfg  *config.Config
+
+	// writeMu is held while the write commands are applied and propagated, so that the replication
+	// stream holds them in the order they were applied
+	writeMu sync.Mutex
+
+	mu           sync.Mutex
+	replID       string
+	replID2      string
+	secondOffset int64
+	offset       int64
+	backlog      *replBacklog
+	replicas     map[int64]*replicaInfo
+	// acked is closed and replaced whenever a replica acknowledges an offset, waking up WAIT
+	acked  chan struct{}
+	master *masterLink
+
+	// the number of synchronisations served to replicas, shown in the stats of INFO
+	syncFull       int64
+	syncPartialOK  int64
+	syncPartialErr int64
+}
+
+func NewReplication(strg storage.StorageEngine, cfg *config.Config) *Replication {
+	return &Replication{
+		strg:         strg,
+		cfg:          cfg,
+		replID:       newReplID(),
+		replID2:      NO_REPL_ID,
+		secondOffset: -1,
+		replicas:     make(map[int64]*replicaInfo),
+		acked:        make(chan struct{}),
+	}
+}
+
+// Offset returns the replication offset, which is the number of bytes of the replication stream so far.
+func (r *Replication) Offset() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.offset
+}
+
+// IsReplica reports whether the server replicates a master.
+func (r *Replication) IsReplica() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.master != nil
+}
+
+// readOnly reports whether the write commands of the client are refused, which is the case on read-only
+// replicas for every client but the master.
+func (r *Replication) readOnly(client *Client) bool {
+	if client != nil && client.Type() == CLIENT_TYPE_MASTER {
+		return false
+	}
+	return r.IsReplica() && r.cfg.GetBool("replica-read-only")
+}
+
+// propagate appends a write command to the replication stream, unless it failed or it was rewritten
+// as no command.
+func (r *Replication) propagate(spec *commandSpec, cmdArray data.Array, reply data.Message) {
+	if _, failed := reply.(data.Error); failed {
+		return
+	}
+	if !r.recording() {
+		return
+	}
+
+	cmds := []data.Array{cmdArray}
+	if spec.propagate != nil {
+		cmds = spec.propagate(cmdArray, reply, r.strg)
+	}
+	var raw []byte
+	for _, cmd := range cmds {
+		if len(cmd.Elements) > 0 {
+			raw = append(raw, cmd.ToDataString()...)
+		}
+	}
+	if len(raw) > 0 {
+		r.feed(raw)
+	}
+}
+
+// recording reports whether the replication stream is recorded, so that the commands are only serialised
+// once a replica has connected.
+func (r *Replication) recording() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.backlog != nil
+}
+
+// feed appends bytes to the replication stream and sends them to the replicas. nothing is recorded until
+// the backlog is created, which happens when the first replica connects.
+func (r *Replication) feed(raw []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.backlog == nil {
+		return
+	}
+	r.backlog.write(raw)
+	r.offset += int64(len(raw))
+	for _, replica := range r.replicas {
+		// the replicas that can't keep up are disconnected by the output buffer limits
+		_, _ = replica.client.conn.Write(raw)
+	}
+}
+
+// canContinueLocked reports whether a replica asking for the stream from the given offset can be sent
+// the rest of the stream from the backlog. It must be called with the lock held.
+func (r *Replication) canContinueLocked(replID string, offset int64) bool {
+	if r.backlog == nil || (replID != r.replID && (replID != r.replID2 || offset > r.secondOffset)) {
+		return false
+	}
+	return offset >= r.offset-int64(r.backlog.length)+1 && offset <= r.offset+1
+}
+
+// sync starts the replication of the server to the client that sent PSYNC. the client gets the rest of
+// the stream when the backlog still has it, or a snapshot of the dataset followed by the stream otherwise.
+// It must be called with writeMu held, so that no write is applied between the snapshot and the stream.
+func (r *Replication) sync(client *Client, replID string, offset int64) data.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// a replica has no dataset to share until it is in sync with its own master
+	if r.master != nil && r.master.State() != REPL_STATE_CONNECTED {
+		return data.Error{ErrMsg: "NOMASTERLINK Can't SYNC while not connected with my master"}
+	}
+
+	client.setType(CLIENT_TYPE_REPLICA)
+	r.replicas[client.id] = &replicaInfo{client: client, ackTime: time.Now()}
+
+	if r.canContinueLocked(replID, offset) {
+		r.syncPartialOK++
+		reply := fmt.Sprintf("+CONTINUE %s\r\n", r.replID)
+		_, _ = client.conn.Write(append([]byte(reply), r.backlog.tail(int(r.offset-offset+1))...))
+		return noReply{}
+	}
+
+	// a replica that does not know the history of the master asks for a full synchronisation with "?"
+	if replID != "?" {
+		r.syncPartialErr++
+	}
+	r.syncFull++
+	if r.backlog == nil {
+		r.backlog = newReplBacklog(r.cfg.GetMemory("repl-backlog-size"))
+	}
+	snapshot := r.strg.Snapshot()
+	reply := fmt.Sprintf("+FULLRESYNC %s %d\r\n$%d\r\n", r.replID, r.offset, len(snapshot))
+	_, _ = client.conn.Write(append([]byte(reply), snapshot...))
+	return noReply{}
+}
+
+func (r *Replication) removeReplica(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.replicas, id)
+}
+
+// disconnectReplicasLocked closes the connections of the replicas, so that they synchronise again once the
+// history of the stream has changed. It must be called with the lock held.
+func (r *Replication) disconnectReplicasLocked() {
+	for _, replica := range r.replicas {
+		replica.client.kill(false)
+	}
+}
+
+// ack records the offset acknowledged by a replica.
+func (r *Replication) ack(id int64, offset int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	replica, ok := r.replicas[id]
+	if !ok {
+		return
+	}
+	replica.ackOffset = max(replica.ackOffset, offset)
+	replica.ackTime = time.Now()
+	close(r.acked)
+	r.acked = make(chan struct{})
+}
+
+// ackedReplicas returns how many replicas acknowledged the given offset, along with the channel that is
+// closed on the next acknowledgement.
+func (r *Replication) ackedReplicas(offset int64) (int, <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, replica := range r.replicas {
+		if replica.ackOffset >= offset {
+			count++
+		}
+	}
+	return count, r.acked
+}
+
+// wait blocks until the given number of replicas acknowledged the writes done so far, or until the timeout
+// elapses when it is positive, and returns how many replicas acknowledged them.
+func (r *Replication) wait(numReplicas int, timeout time.Duration) int {
+	target := r.Offset()
+	count, acked := r.ackedReplicas(target)
+	if count >= numReplicas {
+		return count
+	}
+
+	// the replicas are asked for their offset rather than waiting for their periodic acknowledgement
+	getAck := data.Array{Elements: []data.Message{
+		data.BulkString{Data: "REPLCONF"}, data.BulkString{Data: "GETACK"}, data.BulkString{Data: "*"},
+	}}
+	r.feed([]byte(getAck.ToDataString()))
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	for {
+		select {
+		case <-acked:
+		case <-expired:
+			count, _ = r.ackedReplicas(target)
+			return count
+		}
+		if count, acked = r.ackedReplicas(target); count >= numReplicas {
+			return count
+		}
+	}
+}
+
+// follow makes the server a replica of the given master, replacing the current master if any.
+// it reports false when the server already replicates that master.
+func (r *Replication) follow(ch CommandHandler, host string, port string) bool {
+	r.mu.Lock()
+	current := r.master
+	if current != nil && current.host == host && current.port == port {
+		r.mu.Unlock()
+		return false
+	}
+	link := newMasterLink(ch, host, port)
+	r.master = link
+	r.mu.Unlock()
+
+	if current != nil {
+		current.stop()
+	}
+	go link.run()
+	return true
+}
+
+// promote turns a replica into a master. the history of the former master is kept as the secondary
+// replication ID, so that the other replicas of the former master can continue from this server.
+func (r *Replication) promote() {
+	r.mu.Lock()
+	link := r.master
+	r.master = nil
+	r.mu.Unlock()
+	if link == nil {
+		return
+	}
+	link.stop()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replID2, r.secondOffset = r.replID, r.offset+1
+	r.replID = newReplID()
+	r.disconnectReplicasLocked()
+}
+
+// psyncArgs returns the arguments of PSYNC, which ask for a full resynchronisation when the server
+// has no history to continue from.
+func (r *Replication) psyncArgs() (string, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.backlog == nil {
+		return "?", "-1"
+	}
+	return r.replID, strconv.FormatInt(r.offset+1, 10)
+}
+
+// loadSnapshot replaces the dataset with the snapshot sent by the master, starting a new history from
+// the offset of the snapshot.
+func (r *Replication) loadSnapshot(replID string, offset int64, snapshot []byte) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	if err := r.strg.LoadSnapshot(snapshot); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replID, r.offset = replID, offset
+	r.replID2, r.secondOffset = NO_REPL_ID, -1
+	r.backlog = newReplBacklog(r.cfg.GetMemory("repl-backlog-size"))
+	r.disconnectReplicasLocked()
+	return nil
+}
+
+// continueWith carries on with the history of the master after a partial resynchronisation.
+// a master that was promoted since has a new replication ID, which the server takes on.
+func (r *Replication) continueWith(replID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if replID == "" || replID == r.replID {
+		return
+	}
+	r.replID2, r.secondOffset = r.replID, r.offset+1
+	r.replID = replID
+	r.disconnectReplicasLocked()
+}
+
+// replicaAddr returns the address of a replica, with the port it listens on for clients.
+func replicaAddr(client *Client) (string, string) {
+	host := client.Addr()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host, client.ReplicaPort()
+}
+
+// role renders the replication state in the format of ROLE.
+func (r *Replication) role() data.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.master != nil {
+		port, _ := strconv.ParseInt(r.master.port, 10, 64)
+		return data.Array{Elements: []data.Message{
+			data.BulkString{Data: REPL_ROLE_REPLICA},
+			data.BulkString{Data: r.master.host},
+			data.Integer{Value: port},
+			data.BulkString{Data: r.master.State()},
+			data.Integer{Value: r.offset},
+		}}
+	}
+
+	replicas := []data.Message{}
+	for _, replica := range r.sortedReplicasLocked() {
+		host, port := replicaAddr(replica.client)
+		replicas = append(replicas, data.Array{Elements: []data.Message{
+			data.BulkString{Data: host},
+			data.BulkString{Data: port},
+			data.BulkString{Data: strconv.FormatInt(replica.ackOffset, 10)},
+		}})
+	}
+	return data.Array{Elements: []data.Message{
+		data.BulkString{Data: REPL_ROLE_MASTER},
+		data.Integer{Value: r.offset},
+		data.Array{Elements: replicas},
+	}}
+}
+
+// sortedReplicasLocked returns the replicas ordered by client ID. It must be called with the lock held.
+func (r *Replication) sortedReplicasLocked() []*replicaInfo {
+	replicas := make([]*replicaInfo, 0, len(r.replicas))
+	for _, client := range r.replicas {
+		replicas = append(replicas, client)
+	}
+	slices.SortFunc(replicas, func(a, b *replicaInfo) int {
+		return cmp.Compare(a.client.id, b.client.id)
+	})
+	return replicas
+}
+
+// stats renders the replication fields of the stats section of INFO.
+func (r *Replication) stats() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return fmt.Sprintf("sync_full:%d\r\nsync_partial_ok:%d\r\nsync_partial_err:%d\r\n",
+		r.syncFull, r.syncPartialOK, r.syncPartialErr)
+}
+
+// info renders the replication section of INFO.
+func (r *Replication) info() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sb strings.Builder
+	if r.master != nil {
+		linkStatus, lastIO, syncing := "down", int64(-1), 0
+		switch r.master.State() {
+		case REPL_STATE_CONNECTED:
+			linkStatus = "up"
+			lastIO = int64(time.Since(r.master.LastIO()).Seconds())
+		case REPL_STATE_SYNC:
+			syncing = 1
+		}
+		readOnly := 0
+		if r.cfg.GetBool("replica-read-only") {
+			readOnly = 1
+		}
+		fmt.Fprintf(&sb, "role:%s\r\n", REPL_ROLE_REPLICA)
+		fmt.Fprintf(&sb, "master_host:%s\r\nmaster_port:%s\r\n", r.master.host, r.master.port)
+		fmt.Fprintf(&sb, "master_link_status:%s\r\nmaster_last_io_seconds_ago:%d\r\n", linkStatus, lastIO)
+		fmt.Fprintf(&sb, "master_sync_in_progress:%d\r\n", syncing)
+		fmt.Fprintf(&sb, "slave_read_repl_offset:%d\r\nslave_repl_offset:%d\r\n", r.offset, r.offset)
+		fmt.Fprintf(&sb, "slave_read_only:%d\r\n", readOnly)
+	} else {
+		fmt.Fprintf(&sb, "role:%s\r\n", REPL_ROLE_MASTER)
+	}
+
+	fmt.Fprintf(&sb, "connected_slaves:%d\r\n", len(r.replicas))
+	for idx, replica := range r.sortedReplicasLocked() {
+		host, port := replicaAddr(replica.client)
+		fmt.Fprintf(&sb, "slave%d:ip=%s,port=%s,state=online,offset=%d,lag=%d\r\n",
+			idx, host, port, replica.ackOffset, int64(time.Since(replica.ackTime).Seconds()))
+	}
+
+	backlogActive, backlogFirstByte, backlogLength := 0, int64(0), 0
+	if r.backlog != nil {
+		backlogActive, backlogLength = 1, r.backlog.length
+		backlogFirstByte = r.offset - int64(r.backlog.length) + 1
+	}
+	fmt.Fprintf(&sb, "master_replid:%s\r\nmaster_replid2:%s\r\n", r.replID, r.replID2)
+	fmt.Fprintf(&sb, "master_repl_offset:%d\r\nsecond_repl_offset:%d\r\n", r.offset, r.secondOffset)
+	fmt.Fprintf(&sb, "repl_backlog_active:%d\r\nrepl_backlog_size:%d\r\n", backlogActive, r.cfg.GetMemory("repl-backlog-size"))
+	fmt.Fprintf(&sb, "repl_backlog_first_byte_offset:%d\r\nrepl_backlog_histlen:%d\r\n", backlogFirstByte, backlogLength)
+	return sb.String()
+}
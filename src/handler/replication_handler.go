@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// https://redis.io/docs/latest/commands/replicaof/
+func handleReplicaOf(ch CommandHandler, cmd data.Array) data.Message {
+	if ch.cluster != nil {
+		return data.Error{ErrMsg: "REPLICAOF not allowed in cluster mode."}
+	}
+
+	args := bulkStrings(cmd.Elements[1:])
+	host, port := args[0], args[1]
+	if strings.EqualFold(host, "NO") && strings.EqualFold(port, "ONE") {
+		ch.replication.promote()
+		return OK
+	}
+
+	if portNum, err := strconv.ParseInt(port, 10, 64); err != nil || portNum < 0 || portNum > 65535 {
+		return data.Error{ErrMsg: "Invalid master port"}
+	}
+	if !ch.replication.follow(ch, host, port) {
+		return data.SimpleString{Contents: "OK Already connected to specified master"}
+	}
+	return OK
+}
+
+// https://redis.io/docs/latest/commands/psync/
+func handlePsync(ch CommandHandler, client *Client, cmd data.Array) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+
+	args := bulkStrings(cmd.Elements[1:])
+	offset, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return NOT_AN_INTEGER
+	}
+
+	ch.replication.writeMu.Lock()
+	defer ch.replication.writeMu.Unlock()
+	return ch.replication.sync(client, args[0], offset)
+}
+
+// https://redis.io/docs/latest/commands/replconf/
+// the options are sent by replicas to their master: their port during the handshake, and then the
+// offset they reached with ACK, which is not replied to.
+func handleReplconf(ch CommandHandler, client *Client, cmd data.Array) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+
+	args := bulkStrings(cmd.Elements[1:])
+	if len(args)%2 != 0 {
+		return SYNTAX_ERROR
+	}
+
+	for idx := 0; idx < len(args); idx += 2 {
+		option, value := strings.ToUpper(args[idx]), args[idx+1]
+		switch option {
+		case "LISTENING-PORT":
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				return NOT_AN_INTEGER
+			}
+			client.setReplicaPort(value)
+		case "IP-ADDRESS", "CAPA":
+		case "ACK":
+			offset, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return noReply{}
+			}
+			ch.replication.ack(client.id, offset)
+			return noReply{}
+		case "GETACK":
+			// only the master asks for the offset, and the link of the replica answers it
+			return noReply{}
+		default:
+			return data.Error{ErrMsg: fmt.Sprintf("Unrecognized REPLCONF option: %s", args[idx])}
+		}
+	}
+	return OK
+}
+
+// https://redis.io/docs/latest/commands/role/
+func handleRole(replication *Replication) data.Message {
+	return replication.role()
+}
+
+// https://redis.io/docs/latest/commands/wait/
+func handleWait(cmd data.Array, replication *Replication) data.Message {
+	args := bulkStrings(cmd.Elements[1:])
+	numReplicas, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return NOT_AN_INTEGER
+	}
+	millis, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return data.Error{ErrMsg: "timeout is not an integer or out of range"}
+	}
+	if millis < 0 {
+		return data.Error{ErrMsg: "timeout is negative"}
+	}
+
+	if replication.IsReplica() {
+		return data.Error{ErrMsg: "WAIT cannot be used with replica instances. Please also note that writes to replicas are just local and are not propagated."}
+	}
+	return data.Integer{Value: int64(replication.wait(int(numReplicas), time.Duration(millis)*time.Millisecond))}
+}
@@ -0,0 +1,493 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// scripts run atomically: while a script runs, the commands of the other clients wait for it to end. once the
+// script has run for longer than busy-reply-threshold milliseconds, they are replied with BUSY instead, except
+// for the commands allowed while busy like SCRIPT KILL.
+
+var (
+	SCRIPT_BUSY       = data.Error{ErrMsg: "BUSY Redis is busy running a script. You can only call SCRIPT KILL or SHUTDOWN NOSAVE."}
+	SCRIPT_NOT_FOUND  = data.Error{ErrMsg: "NOSCRIPT No matching script. Please use EVAL."}
+	SCRIPT_NOT_BUSY   = data.Error{ErrMsg: "NOTBUSY No scripts in execution right now."}
+	SCRIPT_UNKILLABLE = data.Error{ErrMsg: "UNKILLABLE Sorry the script already executed write commands against the dataset. You can either wait the script termination or kill the server in a hard way using the SHUTDOWN NOSAVE command."}
+	SCRIPT_KILLED     = data.Error{ErrMsg: "Script killed by user with SCRIPT KILL..."}
+)
+
+// the commands that run scripts, which schedule themselves against the other commands.
+var SCRIPT_COMMANDS = []string{"EVAL", "EVALSHA", "FCALL", "FCALL_RO"}
+
+// the log levels of redis.log.
+var SCRIPT_LOG_LEVELS = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelInfo, slog.LevelWarn}
+
+// Scripts holds the cache of the scripts and schedules the scripts against the other commands.
+type Scripts struct {
+	cfg *config.Config
+
+	mu sync.Mutex
+	// changed is signalled when a script or a command ends, and when a script becomes busy
+	changed *sync.Cond
+	// cache holds the compiled scripts by the SHA1 of their source
+	cache map[string]*lua.FunctionProto
+	// commands is the number of commands being executed, and waiting the number of scripts waiting for them
+	commands int
+	waiting  int
+	running  *scriptRun
+}
+
+// scriptRun is a running script.
+type scriptRun struct {
+	name      string
+	startedAt time.Time
+	threshold time.Duration
+	// client is the client that called the script, whose permissions apply to the commands of the script
+	client   *Client
+	readOnly bool
+	cancel   context.CancelFunc
+	timer    *time.Timer
+	// killed and wrote are guarded by the mutex of the scripts
+	killed bool
+	wrote  bool
+}
+
+func NewScripts(cfg *config.Config) *Scripts {
+	s := &Scripts{cfg: cfg, cache: make(map[string]*lua.FunctionProto)}
+	s.changed = sync.NewCond(&s.mu)
+	return s
+}
+
+func (run *scriptRun) busy() bool {
+	return time.Since(run.startedAt) >= run.threshold
+}
+
+// isScriptCommand reports whether a command runs a script.
+func isScriptCommand(spec *commandSpec) bool {
+	return slices.Contains(SCRIPT_COMMANDS, strings.ToUpper(spec.name))
+}
+
+// allowedWhileBusy reports whether a command can be executed while a script is busy, like SCRIPT KILL.
+func allowedWhileBusy(spec *commandSpec, cmdArray data.Array) bool {
+	if len(spec.subcommands) > 0 && len(cmdArray.Elements) > 1 {
+		if sub, ok := spec.subcommand(cmdArray.Elements[1].(data.BulkString).Data); ok {
+			return sub.hasFlag(CMD_FLAG_ALLOWBUSY)
+		}
+	}
+	return spec.hasFlag(CMD_FLAG_ALLOWBUSY)
+}
+
+// enterCommand waits for the running script to end before a command is executed, and returns the function
+// to call once the command is done. commands are replied with BUSY while the running script is busy.
+func (s *Scripts) enterCommand() (func(), data.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the scripts waiting for the commands to end go before the new commands
+	for s.running != nil || s.waiting > 0 {
+		if s.running != nil && s.running.busy() {
+			return nil, SCRIPT_BUSY
+		}
+		s.changed.Wait()
+	}
+	s.commands++
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.commands--
+		s.changed.Broadcast()
+	}, nil
+}
+
+// start waits for the commands being executed and the running script to end, then registers a new script run.
+func (s *Scripts) start(name string, client *Client, readOnly bool) (*scriptRun, context.Context, data.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.waiting++
+	defer func() { s.waiting-- }()
+	for s.running != nil || s.commands > 0 {
+		if s.running != nil && s.running.busy() {
+			return nil, nil, SCRIPT_BUSY
+		}
+		s.changed.Wait()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &scriptRun{
+		name:      name,
+		startedAt: time.Now(),
+		threshold: time.Duration(s.cfg.GetInt("busy-reply-threshold")) * time.Millisecond,
+		client:    client,
+		readOnly:  readOnly,
+		cancel:    cancel,
+	}
+	// the waiting commands are woken up to be replied with BUSY
+	run.timer = time.AfterFunc(run.threshold, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.changed.Broadcast()
+	})
+	s.running = run
+	return run, ctx, nil
+}
+
+func (s *Scripts) finish(run *scriptRun) {
+	run.timer.Stop()
+	run.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = nil
+	s.changed.Broadcast()
+}
+
+// kill stops the running script, unless it already wrote to the dataset.
+func (s *Scripts) kill() data.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running == nil {
+		return SCRIPT_NOT_BUSY
+	}
+	if s.running.wrote {
+		return SCRIPT_UNKILLABLE
+	}
+	s.running.killed = true
+	s.running.cancel()
+	return OK
+}
+
+// markWrite records that a script writes to the dataset, after which it can't be killed anymore.
+func (s *Scripts) markWrite(run *scriptRun) data.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if run.killed {
+		return SCRIPT_KILLED
+	}
+	run.wrote = true
+	return nil
+}
+
+func (s *Scripts) killed(run *scriptRun) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return run.killed
+}
+
+func scriptSHA(source string) string {
+	sum := sha1.Sum([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// load compiles a script and caches it, returning its SHA1.
+func (s *Scripts) load(source string) (string, *lua.FunctionProto, data.Message) {
+	sha := scriptSHA(source)
+	s.mu.Lock()
+	proto, ok := s.cache[sha]
+	s.mu.Unlock()
+	if ok {
+		return sha, proto, nil
+	}
+
+	proto, err := compileScript(source, "user_script")
+	if err != nil {
+		return "", nil, data.Error{ErrMsg: fmt.Sprintf("Error compiling script (new function): %s", singleLine(err.Error()))}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache[sha] = proto
+	return sha, proto, nil
+}
+
+func (s *Scripts) get(sha string) (*lua.FunctionProto, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	proto, ok := s.cache[strings.ToLower(sha)]
+	return proto, ok
+}
+
+func (s *Scripts) exists(sha string) bool {
+	_, ok := s.get(sha)
+	return ok
+}
+
+func (s *Scripts) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = make(map[string]*lua.FunctionProto)
+}
+
+func compileScript(source string, name string) (*lua.FunctionProto, error) {
+	chunk, err := parse.Parse(strings.NewReader(source), name)
+	if err != nil {
+		return nil, err
+	}
+	return lua.Compile(chunk, name)
+}
+
+// checkScriptCommand enforces the restrictions on the commands called by a script.
+func (ch CommandHandler) checkScriptCommand(spec *commandSpec, cmdArray data.Array) data.Message {
+	if spec.hasFlag(CMD_FLAG_NOSCRIPT) {
+		return data.Error{ErrMsg: "This Redis command is not allowed from script"}
+	}
+	if ch.script.client != nil {
+		if errMsg := ch.checkAccess(ch.script.client, spec, cmdArray); errMsg != nil {
+			return errMsg
+		}
+	}
+	if spec.writes(cmdArray) {
+		if ch.script.readOnly {
+			return data.Error{ErrMsg: "Write commands are not allowed from read-only scripts."}
+		}
+		return ch.scripts.markWrite(ch.script)
+	}
+	return nil
+}
+
+// scriptLoader prepares the sandbox of a script, returning the function to call with its arguments.
+type scriptLoader func(L *lua.LState) (*lua.LFunction, []lua.LValue, error)
+
+// runScript runs a script or a function scheduled against the other commands. the commands called by the
+// script go through a copy of the handler that knows about the script.
+func (ch CommandHandler) runScript(client *Client, name string, readOnly bool, load scriptLoader) data.Message {
+	run, ctx, errMsg := ch.scripts.start(name, client, readOnly)
+	if errMsg != nil {
+		return errMsg
+	}
+	defer ch.scripts.finish(run)
+
+	inner := ch
+	inner.script = run
+	L := newScriptState(inner)
+	defer L.Close()
+	L.SetContext(ctx)
+
+	fn, args, err := load(L)
+	if err == nil {
+		err = L.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, args...)
+	}
+	if err != nil {
+		if ch.scripts.killed(run) {
+			return SCRIPT_KILLED
+		}
+		return data.Error{ErrMsg: fmt.Sprintf("%s script: %s", luaErrorMessage(err), run.name)}
+	}
+	return luaToResp(L.Get(-1))
+}
+
+// evalLoader loads a script run by EVAL, with its keys and arguments in the KEYS and ARGV globals.
+func evalLoader(proto *lua.FunctionProto, keys []string, args []string) scriptLoader {
+	return func(L *lua.LState) (*lua.LFunction, []lua.LValue, error) {
+		L.SetGlobal("KEYS", stringsToLua(L, keys))
+		L.SetGlobal("ARGV", stringsToLua(L, args))
+		protectGlobals(L)
+		return L.NewFunctionFromProto(proto), nil, nil
+	}
+}
+
+// luaErrorMessage returns the message of an error raised by Lua, which is the err field of the error
+// replies raised by redis.call.
+func luaErrorMessage(err error) string {
+	msg := err.Error()
+	if apiErr, ok := err.(*lua.ApiError); ok {
+		msg = apiErr.Object.String()
+		if table, ok := apiErr.Object.(*lua.LTable); ok {
+			if errField, ok := table.RawGetString("err").(lua.LString); ok {
+				msg = string(errField)
+			}
+		}
+	}
+	return singleLine(msg)
+}
+
+// singleLine joins the lines of the errors raised by Lua, since error replies can't span lines.
+func singleLine(msg string) string {
+	return strings.Join(strings.Fields(msg), " ")
+}
+
+// newScriptState creates the sandbox a script runs in, with the libraries that can't reach outside of it
+// and the redis library whose commands go through the given handler.
+func newScriptState(ch CommandHandler) *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring", "module", "require"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	redis := L.NewTable()
+	L.SetFuncs(redis, map[string]lua.LGFunction{
+		"call": func(L *lua.LState) int {
+			return ch.scriptCall(L, true)
+		},
+		"pcall": func(L *lua.LState) int {
+			return ch.scriptCall(L, false)
+		},
+		"error_reply": func(L *lua.LState) int {
+			L.Push(replyTable(L, "err", L.CheckString(1)))
+			return 1
+		},
+		"status_reply": func(L *lua.LState) int {
+			L.Push(replyTable(L, "ok", L.CheckString(1)))
+			return 1
+		},
+		"sha1hex": func(L *lua.LState) int {
+			L.Push(lua.LString(scriptSHA(L.CheckString(1))))
+			return 1
+		},
+		"log": func(L *lua.LState) int {
+			level := L.CheckInt(1)
+			if level < 0 || level >= len(SCRIPT_LOG_LEVELS) {
+				L.RaiseError("Invalid debug level.")
+			}
+			parts := []string{}
+			for idx := 2; idx <= L.GetTop(); idx++ {
+				parts = append(parts, L.Get(idx).String())
+			}
+			slog.Log(context.Background(), SCRIPT_LOG_LEVELS[level], strings.Join(parts, " "), "script", ch.script.name)
+			return 0
+		},
+	})
+	for level, name := range []string{"LOG_DEBUG", "LOG_VERBOSE", "LOG_NOTICE", "LOG_WARNING"} {
+		L.SetField(redis, name, lua.LNumber(level))
+	}
+	L.SetGlobal("redis", redis)
+	return L
+}
+
+// protectGlobals makes the scripts fail when they create or read undefined global variables, so that
+// scripts can't leak state into each other.
+func protectGlobals(L *lua.LState) {
+	mt := L.NewTable()
+	L.SetField(mt, "__newindex", L.NewFunction(func(L *lua.LState) int {
+		L.RaiseError("Script attempted to create global variable '%s'", L.CheckAny(2).String())
+		return 0
+	}))
+	L.SetField(mt, "__index", L.NewFunction(func(L *lua.LState) int {
+		L.RaiseError("Script attempted to access nonexistent global variable '%s'", L.CheckAny(2).String())
+		return 0
+	}))
+	L.SetMetatable(L.Get(lua.GlobalsIndex), mt)
+}
+
+// scriptCall runs a command for redis.call and redis.pcall. redis.call raises the error replies as errors,
+// while redis.pcall returns them.
+func (ch CommandHandler) scriptCall(L *lua.LState, raise bool) int {
+	if L.GetTop() == 0 {
+		L.RaiseError("Please specify at least one argument for this redis lib call")
+	}
+	elements := make([]data.Message, L.GetTop())
+	for idx := range elements {
+		switch arg := L.Get(idx + 1).(type) {
+		case lua.LString, lua.LNumber:
+			elements[idx] = data.BulkString{Data: arg.String()}
+		default:
+			L.RaiseError("Lua redis lib command arguments must be strings or integers")
+		}
+	}
+
+	reply := ch.HandleCommand(data.Array{Elements: elements})
+	if errReply, ok := reply.(data.Error); ok && raise {
+		L.Error(replyTable(L, "err", errReply.ErrMsg), 1)
+		return 0
+	}
+	L.Push(respToLua(L, reply))
+	return 1
+}
+
+func replyTable(L *lua.LState, field string, msg string) *lua.LTable {
+	table := L.NewTable()
+	table.RawSetString(field, lua.LString(msg))
+	return table
+}
+
+func stringsToLua(L *lua.LState, values []string) *lua.LTable {
+	table := L.CreateTable(len(values), 0)
+	for _, value := range values {
+		table.Append(lua.LString(value))
+	}
+	return table
+}
+
+// respToLua converts a reply to a Lua value: integers become numbers, bulk strings become strings, arrays
+// become tables, nulls become false, and status and error replies become tables with an ok or err field.
+func respToLua(L *lua.LState, msg data.Message) lua.LValue {
+	switch reply := msg.(type) {
+	case data.Integer:
+		return lua.LNumber(reply.Value)
+	case data.BulkString:
+		return lua.LString(reply.Data)
+	case data.SimpleString:
+		return replyTable(L, "ok", reply.Contents)
+	case data.Error:
+		return replyTable(L, "err", reply.ErrMsg)
+	case data.Array:
+		table := L.CreateTable(len(reply.Elements), 0)
+		for _, element := range reply.Elements {
+			table.Append(respToLua(L, element))
+		}
+		return table
+	default:
+		return lua.LFalse
+	}
+}
+
+// luaToResp converts a Lua value to a reply: numbers are truncated to integers, true becomes 1, false and
+// nil become null, and tables become arrays up to their first nil, unless they have an err or ok field.
+func luaToResp(lv lua.LValue) data.Message {
+	switch value := lv.(type) {
+	case lua.LString:
+		return data.BulkString{Data: string(value)}
+	case lua.LNumber:
+		return data.Integer{Value: int64(value)}
+	case lua.LBool:
+		if value {
+			return data.Integer{Value: 1}
+		}
+		return data.Null{}
+	case *lua.LTable:
+		if errField, ok := value.RawGetString("err").(lua.LString); ok {
+			return data.Error{ErrMsg: string(errField)}
+		}
+		if okField, ok := value.RawGetString("ok").(lua.LString); ok {
+			return data.SimpleString{Contents: string(okField)}
+		}
+		elements := []data.Message{}
+		for idx := 1; ; idx++ {
+			element := value.RawGetInt(idx)
+			if element == lua.LNil {
+				break
+			}
+			elements = append(elements, luaToResp(element))
+		}
+		return data.Array{Elements: elements}
+	default:
+		return data.Null{}
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// scriptKeys returns the keys given to EVAL or EVALSHA, or nil when their number is invalid.
+func scriptKeys(args []string) []string {
+	numKeys, err := strconv.Atoi(args[2])
+	if err != nil || numKeys < 0 || numKeys > len(args)-3 {
+		return nil
+	}
+	return args[3 : 3+numKeys]
+}
+
+// parseScriptArgs splits the arguments following the script of EVAL or EVALSHA into KEYS and ARGV.
+func parseScriptArgs(args []string) ([]string, []string, data.Message) {
+	numKeys, err := strconv.Atoi(args[0])
+	switch {
+	case err != nil:
+		return nil, nil, NOT_AN_INTEGER
+	case numKeys < 0:
+		return nil, nil, data.Error{ErrMsg: "Number of keys can't be negative"}
+	case numKeys > len(args)-1:
+		return nil, nil, data.Error{ErrMsg: "Number of keys can't be greater than number of args"}
+	}
+	return args[1 : 1+numKeys], args[1+numKeys:], nil
+}
+
+// https://redis.io/docs/latest/commands/eval/
+// https://redis.io/docs/latest/commands/evalsha/
+func handleEval(ch CommandHandler, client *Client, cmd data.Array, bySHA bool) data.Message {
+	args := bulkStrings(cmd.Elements[1:])
+	keys, argv, errMsg := parseScriptArgs(args[1:])
+	if errMsg != nil {
+		return errMsg
+	}
+
+	sha := strings.ToLower(args[0])
+	proto, ok := ch.scripts.get(sha)
+	if !ok {
+		if bySHA {
+			return SCRIPT_NOT_FOUND
+		}
+		if sha, proto, errMsg = ch.scripts.load(args[0]); errMsg != nil {
+			return errMsg
+		}
+	}
+	return ch.runScript(client, sha, false, evalLoader(proto, keys, argv))
+}
+
+// https://redis.io/docs/latest/commands/script/
+func handleScript(ch CommandHandler, cmd data.Array) data.Message {
+	args := bulkStrings(cmd.Elements[1:])
+	switch strings.ToUpper(args[0]) {
+	case "LOAD":
+		sha, _, errMsg := ch.scripts.load(args[1])
+		if errMsg != nil {
+			return errMsg
+		}
+		return data.BulkString{Data: sha}
+	case "EXISTS":
+		elements := make([]data.Message, len(args)-1)
+		for idx, sha := range args[1:] {
+			exists := int64(0)
+			if ch.scripts.exists(sha) {
+				exists = 1
+			}
+			elements[idx] = data.Integer{Value: exists}
+		}
+		return data.Array{Elements: elements}
+	case "FLUSH":
+		if len(args) > 2 || (len(args) == 2 && !strings.EqualFold(args[1], "ASYNC") && !strings.EqualFold(args[1], "SYNC")) {
+			return data.Error{ErrMsg: "SCRIPT FLUSH only support SYNC|ASYNC option"}
+		}
+		ch.scripts.flush()
+		return OK
+	case "KILL":
+		return ch.scripts.kill()
+	default:
+		return data.Error{ErrMsg: fmt.Sprintf("unsupported subcommand %s for SCRIPT", args[0])}
+	}
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/vrajashkr/cc-kv-go/src/data"
@@ -31,30 +33,66 @@ func handleSet(cmd data.Array, strg storage.StorageEngine) data.Message {
 		expires = true
 		optionTimeInt, err := strconv.ParseInt(optionArg.Data, 10, 64)
 		if err != nil {
-			return data.Error{ErrMsg: "failed to set due to error: " + err.Error()}
+			return storageError("set", err)
 		}
 
-		switch option.Data {
-		case CMD_SET_OPT_EX:
-			// expiry time in seconds
-			expiresAtTimeStampMillis = time.Now().UnixMilli() + (optionTimeInt * 1000)
-		case CMD_SET_OPT_PX:
-			// expiry time in milliseconds
-			expiresAtTimeStampMillis = time.Now().UnixMilli() + optionTimeInt
-		case CMD_SET_OPT_EXAT:
-			// expiry timestamp epoch in seconds
-			expiresAtTimeStampMillis = optionTimeInt * 1000
-		case CMD_SET_OPT_PXAT:
-			// expiry timestamp epoch in milliseconds
-			expiresAtTimeStampMillis = optionTimeInt
-		default:
-			return data.Error{ErrMsg: "syntax error"}
+		var ok bool
+		expiresAtTimeStampMillis, ok = expiresAtMillis(option.Data, optionTimeInt)
+		if !ok {
+			return SYNTAX_ERROR
 		}
 	}
 
 	err := strg.Set(keyHolder.Data, valueContents, expires, expiresAtTimeStampMillis)
 	if err != nil {
-		return data.Error{ErrMsg: "failed to store data due to error: " + err.Error()}
+		return storageError("store data", err)
 	}
 	return OK
 }
+
+// expiresAtMillis converts the value of an expiry option (EX, PX, EXAT or PXAT) into a unix timestamp in milliseconds.
+func expiresAtMillis(option string, value int64) (int64, bool) {
+	switch strings.ToUpper(option) {
+	case CMD_SET_OPT_EX:
+		// expiry time in seconds
+		return time.Now().UnixMilli() + (value * 1000), true
+	case CMD_SET_OPT_PX:
+		// expiry time in milliseconds
+		return time.Now().UnixMilli() + value, true
+	case CMD_SET_OPT_EXAT:
+		// expiry timestamp epoch in seconds
+		return value * 1000, true
+	case CMD_SET_OPT_PXAT:
+		// expiry timestamp epoch in milliseconds
+		return value, true
+	default:
+		return 0, false
+	}
+}
+
+// propagateSet replaces the relative expiry of SET with an absolute one, so that the key expires on the replicas
+// at the same time as on the master rather than relative to when they receive it.
+func propagateSet(cmd data.Array, _ data.Message, _ storage.StorageEngine) []data.Array {
+	if len(cmd.Elements) != 5 {
+		return []data.Array{cmd}
+	}
+	return []data.Array{withAbsoluteExpiry(cmd, 3)}
+}
+
+// withAbsoluteExpiry rewrites the relative expiry option (EX or PX) at optIdx and its value as PXAT.
+func withAbsoluteExpiry(cmd data.Array, optIdx int) data.Array {
+	option := strings.ToUpper(cmd.Elements[optIdx].(data.BulkString).Data)
+	if option != CMD_SET_OPT_EX && option != CMD_SET_OPT_PX {
+		return cmd
+	}
+	value, err := strconv.ParseInt(cmd.Elements[optIdx+1].(data.BulkString).Data, 10, 64)
+	if err != nil {
+		return cmd
+	}
+
+	expiresAt, _ := expiresAtMillis(option, value)
+	elements := slices.Clone(cmd.Elements)
+	elements[optIdx] = data.BulkString{Data: CMD_SET_OPT_PXAT}
+	elements[optIdx+1] = data.BulkString{Data: strconv.FormatInt(expiresAt, 10)}
+	return data.Array{Elements: elements}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/setex/
+// https://redis.io/docs/latest/commands/psetex/
+func handleSetEx(cmd data.Array, strg storage.StorageEngine, isMillis bool) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+	value := cmd.Elements[3].(data.BulkString).Data
+
+	ttl, err := strconv.ParseInt(cmd.Elements[2].(data.BulkString).Data, 10, 64)
+	if err != nil {
+		return NOT_AN_INTEGER
+	}
+
+	command := CMD_SETEX
+	option := CMD_SET_OPT_EX
+	if isMillis {
+		command = CMD_PSETEX
+		option = CMD_SET_OPT_PX
+	}
+	if ttl <= 0 {
+		return data.Error{ErrMsg: fmt.Sprintf("invalid expire time in '%s' command", strings.ToLower(command))}
+	}
+
+	expiresAtTimeStampMillis, _ := expiresAtMillis(option, ttl)
+	if err := strg.Set(key, value, true, expiresAtTimeStampMillis); err != nil {
+		return storageError("store data", err)
+	}
+	return OK
+}
+
+// propagateSetEx sends SETEX and PSETEX to the replicas as SET with an absolute expiry, so that the key expires
+// on the replicas at the same time as on the master.
+func propagateSetEx(cmd data.Array, _ data.Message, _ storage.StorageEngine) []data.Array {
+	option := CMD_SET_OPT_EX
+	if strings.EqualFold(cmd.Elements[0].(data.BulkString).Data, CMD_PSETEX) {
+		option = CMD_SET_OPT_PX
+	}
+	set := data.Array{Elements: []data.Message{
+		data.BulkString{Data: CMD_SET}, cmd.Elements[1], cmd.Elements[3], data.BulkString{Data: option}, cmd.Elements[2],
+	}}
+	return []data.Array{withAbsoluteExpiry(set, 3)}
+}
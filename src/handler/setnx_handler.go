@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/setnx/
+func handleSetNX(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+	value := cmd.Elements[2].(data.BulkString).Data
+
+	stored, err := strg.SetNX(key, value, false, -1)
+	if err != nil {
+		return storageError("set", err)
+	}
+	if !stored {
+		return data.Integer{Value: 0}
+	}
+	return data.Integer{Value: 1}
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+const (
+	SLOWLOG_ENTRY_MAX_ARGC   = 32
+	SLOWLOG_ENTRY_MAX_STRING = 128
+)
+
+// slowLogEntry records a single command that exceeded the slowlog threshold.
+type slowLogEntry struct {
+	id         int64
+	timestamp  time.Time
+	duration   time.Duration
+	args       []string
+	clientAddr string
+	clientName string
+}
+
+func (e slowLogEntry) toMessage() data.Message {
+	return data.Array{
+		Elements: []data.Message{
+			data.Integer{Value: e.id},
+			data.Integer{Value: e.timestamp.Unix()},
+			data.Integer{Value: e.duration.Microseconds()},
+			bulkStringArray(e.args),
+			data.BulkString{Data: e.clientAddr},
+			data.BulkString{Data: e.clientName},
+		},
+	}
+}
+
+// SlowLog keeps a bounded ring buffer of the commands that took longer than slowlog-log-slower-than to execute.
+type SlowLog struct {
+	mu      sync.Mutex
+	entries []slowLogEntry
+	start   int
+	nextId  int64
+
+	slowerThan atomic.Int64
+	maxLen     atomic.Int64
+}
+
+func NewSlowLog(cfg *config.Config) *SlowLog {
+	sl := &SlowLog{}
+
+	setSlowerThan := func(string) { sl.slowerThan.Store(cfg.GetInt("slowlog-log-slower-than")) }
+	setMaxLen := func(string) {
+		sl.maxLen.Store(cfg.GetInt("slowlog-max-len"))
+		sl.trim()
+	}
+
+	setSlowerThan("")
+	setMaxLen("")
+	cfg.Watch("slowlog-log-slower-than", setSlowerThan)
+	cfg.Watch("slowlog-max-len", setMaxLen)
+
+	return sl
+}
+
+// truncateArgs limits the number and length of the arguments kept for an entry.
+func truncateArgs(cmdArray data.Array) []string {
+	argc := len(cmdArray.Elements)
+	keptArgc := min(argc, SLOWLOG_ENTRY_MAX_ARGC)
+
+	args := make([]string, 0, keptArgc)
+	for idx := range keptArgc {
+		// the last slot is used to mention the number of omitted arguments
+		if idx == keptArgc-1 && argc > SLOWLOG_ENTRY_MAX_ARGC {
+			args = append(args, fmt.Sprintf("... (%d more arguments)", argc-keptArgc+1))
+			break
+		}
+
+		arg := cmdArray.Elements[idx].(data.BulkString).Data
+		if len(arg) > SLOWLOG_ENTRY_MAX_STRING {
+			arg = fmt.Sprintf("%s... (%d more bytes)", arg[:SLOWLOG_ENTRY_MAX_STRING], len(arg)-SLOWLOG_ENTRY_MAX_STRING)
+		}
+		args = append(args, arg)
+	}
+
+	// credentials never end up in the log
+	if len(args) > 1 && strings.EqualFold(cmdArray.Elements[0].(data.BulkString).Data, CMD_AUTH) {
+		args = []string{args[0], "(redacted)"}
+	}
+
+	return args
+}
+
+// record adds an entry for the command if it was slow enough.
+// SLOWLOG commands themselves are never recorded so that inspecting the log does not fill it.
+func (sl *SlowLog) record(client *Client, cmdArray data.Array, duration time.Duration) {
+	slowerThan := sl.slowerThan.Load()
+	if slowerThan < 0 || duration.Microseconds() < slowerThan {
+		return
+	}
+	if strings.EqualFold(cmdArray.Elements[0].(data.BulkString).Data, CMD_SLOWLOG) {
+		return
+	}
+
+	entry := slowLogEntry{
+		timestamp: time.Now(),
+		duration:  duration,
+		args:      truncateArgs(cmdArray),
+	}
+	if client != nil {
+		entry.clientAddr = client.Addr()
+		entry.clientName = client.Name()
+	}
+
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	maxLen := int(sl.maxLen.Load())
+	if maxLen <= 0 {
+		return
+	}
+
+	entry.id = sl.nextId
+	sl.nextId++
+
+	if len(sl.entries) < maxLen {
+		sl.entries = append(sl.entries, entry)
+		return
+	}
+
+	// the buffer is full, overwrite the oldest entry
+	sl.entries[sl.start] = entry
+	sl.start = (sl.start + 1) % len(sl.entries)
+}
+
+// trim drops the oldest entries that no longer fit after slowlog-max-len is reduced.
+func (sl *SlowLog) trim() {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	ordered := sl.orderedLocked()
+	maxLen := max(int(sl.maxLen.Load()), 0)
+	if len(ordered) > maxLen {
+		ordered = ordered[len(ordered)-maxLen:]
+	}
+	sl.entries = ordered
+	sl.start = 0
+}
+
+// orderedLocked returns the entries from the oldest to the newest. It must be called with the lock held.
+func (sl *SlowLog) orderedLocked() []slowLogEntry {
+	ordered := make([]slowLogEntry, 0, len(sl.entries))
+	ordered = append(ordered, sl.entries[sl.start:]...)
+	ordered = append(ordered, sl.entries[:sl.start]...)
+	return ordered
+}
+
+// Get returns up to count entries, newest first. A negative count returns every entry.
+func (sl *SlowLog) Get(count int) []slowLogEntry {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	ordered := sl.orderedLocked()
+	if count < 0 || count > len(ordered) {
+		count = len(ordered)
+	}
+
+	result := make([]slowLogEntry, 0, count)
+	for idx := len(ordered) - 1; idx >= len(ordered)-count; idx-- {
+		result = append(result, ordered[idx])
+	}
+	return result
+}
+
+func (sl *SlowLog) Len() int {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	return len(sl.entries)
+}
+
+func (sl *SlowLog) Reset() {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.entries = nil
+	sl.start = 0
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+const SLOWLOG_DEFAULT_GET_COUNT = 10
+
+// https://redis.io/docs/latest/commands/slowlog/
+func handleSlowLog(cmdArray data.Array, slowLog *SlowLog) data.Message {
+	subCommandHolder := cmdArray.Elements[1].(data.BulkString)
+	numArgs := len(cmdArray.Elements) - 2
+
+	switch strings.ToUpper(subCommandHolder.Data) {
+	case "GET":
+		if numArgs > 1 {
+			return data.Error{ErrMsg: "wrong number of arguments for 'slowlog|get' command"}
+		}
+
+		count := SLOWLOG_DEFAULT_GET_COUNT
+		if numArgs == 1 {
+			parsed, err := strconv.Atoi(cmdArray.Elements[2].(data.BulkString).Data)
+			if err != nil || parsed < -1 {
+				return data.Error{ErrMsg: "count should be greater than or equal to -1"}
+			}
+			count = parsed
+		}
+
+		entries := slowLog.Get(count)
+		elements := make([]data.Message, len(entries))
+		for idx, entry := range entries {
+			elements[idx] = entry.toMessage()
+		}
+		return data.Array{Elements: elements}
+	case "LEN":
+		return data.Integer{Value: int64(slowLog.Len())}
+	case "RESET":
+		slowLog.Reset()
+		return OK
+	default:
+		return data.Error{
+			ErrMsg: fmt.Sprintf("unsupported subcommand %s for %s", subCommandHolder.Data, CMD_SLOWLOG),
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+const (
+	XAUTOCLAIM_DEFAULT_COUNT = 100
+	// the ENTRIESREAD value for a group whose read entries are unknown
+	XGROUP_ENTRIES_READ_UNKNOWN = -1
+)
+
+var XGROUP_NO_SUCH_KEY = data.Error{
+	ErrMsg: "The XGROUP subcommand requires the key to exist. Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically.",
+}
+
+// noSuchKeyOrGroup is the NOGROUP reply of the commands that do not tell a missing stream from a missing group.
+func noSuchKeyOrGroup(key string, group string) data.Message {
+	return data.Error{ErrMsg: fmt.Sprintf("NOGROUP No such key '%s' or consumer group '%s'", key, group)}
+}
+
+// groupCommandError turns an error of a consumer group command into a reply.
+func groupCommandError(key string, group string, action string, err error) data.Message {
+	if errors.Is(err, storage.ErrNoSuchKey) || errors.Is(err, storage.ErrNoGroup) {
+		return noSuchKeyOrGroup(key, group)
+	}
+	return storageError(action, err)
+}
+
+// parseGroupLastID parses the last ID given to XGROUP CREATE and SETID, where $ is the last entry of the stream.
+func parseGroupLastID(value string) (storage.StreamID, bool, data.Message) {
+	if value == "$" {
+		return storage.StreamID{}, true, nil
+	}
+	id, ok := storage.ParseStreamID(value, 0)
+	if !ok {
+		return id, false, INVALID_STREAM_ID
+	}
+	return id, false, nil
+}
+
+// parseGroupOptions parses the options of XGROUP CREATE and SETID, only the first of which accepts MKSTREAM.
+func parseGroupOptions(args []string, allowMkStream bool) (bool, int64, data.Message) {
+	mkStream := false
+	entriesRead := int64(XGROUP_ENTRIES_READ_UNKNOWN)
+	for idx := 0; idx < len(args); idx++ {
+		switch option := strings.ToUpper(args[idx]); {
+		case option == "MKSTREAM" && allowMkStream:
+			mkStream = true
+		case option == "ENTRIESREAD" && idx+1 < len(args):
+			var err error
+			if entriesRead, err = strconv.ParseInt(args[idx+1], 10, 64); err != nil {
+				return false, 0, NOT_AN_INTEGER
+			}
+			if entriesRead < XGROUP_ENTRIES_READ_UNKNOWN {
+				return false, 0, data.Error{ErrMsg: "value for ENTRIESREAD must be positive or -1"}
+			}
+			idx++
+		default:
+			return false, 0, SYNTAX_ERROR
+		}
+	}
+	return mkStream, entriesRead, nil
+}
+
+// https://redis.io/docs/latest/commands/xgroup/
+func handleXGroup(cmd data.Array, strg storage.StorageEngine) data.Message {
+	args := bulkStrings(cmd.Elements)
+	subCommand := strings.ToUpper(args[1])
+
+	var reply data.Message
+	var err error
+	switch subCommand {
+	case "CREATE":
+		id, useLastID, errMsg := parseGroupLastID(args[4])
+		if errMsg != nil {
+			return errMsg
+		}
+		mkStream, entriesRead, errMsg := parseGroupOptions(args[5:], true)
+		if errMsg != nil {
+			return errMsg
+		}
+		err = strg.XGroupCreate(args[2], args[3], id, useLastID, mkStream, entriesRead)
+		reply = OK
+	case "SETID":
+		id, useLastID, errMsg := parseGroupLastID(args[4])
+		if errMsg != nil {
+			return errMsg
+		}
+		_, entriesRead, errMsg := parseGroupOptions(args[5:], false)
+		if errMsg != nil {
+			return errMsg
+		}
+		err = strg.XGroupSetID(args[2], args[3], id, useLastID, entriesRead)
+		reply = OK
+	case "DESTROY":
+		var destroyed bool
+		destroyed, err = strg.XGroupDestroy(args[2], args[3])
+		reply = data.Integer{Value: 0}
+		if destroyed {
+			reply = data.Integer{Value: 1}
+		}
+	case "CREATECONSUMER":
+		var created bool
+		created, err = strg.XGroupCreateConsumer(args[2], args[3], args[4])
+		reply = data.Integer{Value: 0}
+		if created {
+			reply = data.Integer{Value: 1}
+		}
+	case "DELCONSUMER":
+		var pending int64
+		pending, err = strg.XGroupDelConsumer(args[2], args[3], args[4])
+		reply = data.Integer{Value: pending}
+	default:
+		return data.Error{ErrMsg: fmt.Sprintf("unsupported subcommand %s for XGROUP", args[1])}
+	}
+
+	if errors.Is(err, storage.ErrNoSuchKey) {
+		return XGROUP_NO_SUCH_KEY
+	}
+	if err != nil {
+		return storageError("update consumer group", err)
+	}
+	return reply
+}
+
+// https://redis.io/docs/latest/commands/xack/
+func handleXAck(cmd data.Array, strg storage.StorageEngine) data.Message {
+	args := bulkStrings(cmd.Elements)
+
+	ids := make([]storage.StreamID, len(args)-3)
+	for idx, value := range args[3:] {
+		id, ok := storage.ParseStreamID(value, 0)
+		if !ok {
+			return INVALID_STREAM_ID
+		}
+		ids[idx] = id
+	}
+
+	acked, err := strg.XAck(args[1], args[2], ids)
+	if err != nil {
+		return storageError("acknowledge entries", err)
+	}
+	return data.Integer{Value: acked}
+}
+
+// https://redis.io/docs/latest/commands/xpending/
+func handleXPending(cmd data.Array, strg storage.StorageEngine) data.Message {
+	args := bulkStrings(cmd.Elements)
+	key, group := args[1], args[2]
+
+	if len(args) == 3 {
+		summary, err := strg.XPending(key, group)
+		if err != nil {
+			return groupCommandError(key, group, "get pending entries", err)
+		}
+		if summary.Count == 0 {
+			return data.Array{Elements: []data.Message{data.Integer{Value: 0}, data.Null{}, data.Null{}, data.Null{}}}
+		}
+
+		consumers := make([]data.Message, len(summary.Consumers))
+		for idx, consumer := range summary.Consumers {
+			consumers[idx] = data.Array{Elements: []data.Message{
+				data.BulkString{Data: consumer.Name},
+				data.BulkString{Data: strconv.FormatInt(consumer.Count, 10)},
+			}}
+		}
+		return data.Array{Elements: []data.Message{
+			data.Integer{Value: summary.Count},
+			data.BulkString{Data: summary.First.String()},
+			data.BulkString{Data: summary.Last.String()},
+			data.Array{Elements: consumers},
+		}}
+	}
+
+	query := storage.StreamPendingQuery{}
+	rest := args[3:]
+	if strings.ToUpper(rest[0]) == "IDLE" && len(rest) > 1 {
+		idle, err := strconv.ParseInt(rest[1], 10, 64)
+		if err != nil {
+			return NOT_AN_INTEGER
+		}
+		query.MinIdle = time.Duration(idle) * time.Millisecond
+		rest = rest[2:]
+	}
+	if len(rest) != 3 && len(rest) != 4 {
+		return SYNTAX_ERROR
+	}
+
+	var errMsg data.Message
+	if query.Start, errMsg = parseRangeID(rest[0], true); errMsg != nil {
+		return errMsg
+	}
+	if query.End, errMsg = parseRangeID(rest[1], false); errMsg != nil {
+		return errMsg
+	}
+	count, err := strconv.ParseInt(rest[2], 10, 64)
+	if err != nil {
+		return NOT_AN_INTEGER
+	}
+	query.Count = int(max(count, 0))
+	if len(rest) == 4 {
+		query.Consumer = rest[3]
+	}
+
+	entries, err := strg.XPendingRange(key, group, query)
+	if err != nil {
+		return groupCommandError(key, group, "get pending entries", err)
+	}
+
+	now := time.Now()
+	elements := make([]data.Message, len(entries))
+	for idx, entry := range entries {
+		elements[idx] = data.Array{Elements: []data.Message{
+			data.BulkString{Data: entry.ID.String()},
+			data.BulkString{Data: entry.Consumer},
+			data.Integer{Value: now.Sub(entry.DeliveryTime).Milliseconds()},
+			data.Integer{Value: entry.DeliveryCount},
+		}}
+	}
+	return data.Array{Elements: elements}
+}
+
+// parseMinIdle parses the min-idle-time of XCLAIM and XAUTOCLAIM, where negative values are the same as 0.
+func parseMinIdle(command string, value string) (time.Duration, data.Message) {
+	minIdle, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, data.Error{ErrMsg: fmt.Sprintf("Invalid min-idle-time argument for %s", command)}
+	}
+	return time.Duration(max(minIdle, 0)) * time.Millisecond, nil
+}
+
+// streamIDs renders the IDs of the entries.
+func streamIDs(entries []storage.StreamEntry) data.Array {
+	elements := make([]data.Message, len(entries))
+	for idx, entry := range entries {
+		elements[idx] = data.BulkString{Data: entry.ID.String()}
+	}
+	return data.Array{Elements: elements}
+}
+
+// https://redis.io/docs/latest/commands/xclaim/
+func handleXClaim(cmd data.Array, strg storage.StorageEngine) data.Message {
+	args := bulkStrings(cmd.Elements)
+	key, group, consumer := args[1], args[2], args[3]
+
+	minIdle, errMsg := parseMinIdle("XCLAIM", args[4])
+	if errMsg != nil {
+		return errMsg
+	}
+
+	// the IDs are followed by the options
+	idx := 5
+	ids := []storage.StreamID{}
+	for ; idx < len(args); idx++ {
+		id, ok := storage.ParseStreamID(args[idx], 0)
+		if !ok {
+			break
+		}
+		ids = append(ids, id)
+	}
+
+	now := time.Now()
+	opts := storage.StreamClaimOptions{}
+	for ; idx < len(args); idx++ {
+		option := strings.ToUpper(args[idx])
+		hasArg := idx+1 < len(args)
+		switch {
+		case option == "FORCE":
+			opts.Force = true
+		case option == "JUSTID":
+			opts.JustID = true
+		case option == "IDLE" && hasArg:
+			idle, err := strconv.ParseInt(args[idx+1], 10, 64)
+			if err != nil {
+				return data.Error{ErrMsg: "Invalid IDLE option argument for XCLAIM"}
+			}
+			opts.DeliveryTime = now.Add(-time.Duration(idle) * time.Millisecond)
+			idx++
+		case option == "TIME" && hasArg:
+			millis, err := strconv.ParseInt(args[idx+1], 10, 64)
+			if err != nil {
+				return data.Error{ErrMsg: "Invalid TIME option argument for XCLAIM"}
+			}
+			opts.DeliveryTime = time.UnixMilli(millis)
+			idx++
+		case option == "RETRYCOUNT" && hasArg:
+			retryCount, err := strconv.ParseInt(args[idx+1], 10, 64)
+			if err != nil {
+				return data.Error{ErrMsg: "Invalid RETRYCOUNT option argument for XCLAIM"}
+			}
+			opts.RetryCount, opts.HasRetryCount = retryCount, true
+			idx++
+		case option == "LASTID" && hasArg:
+			lastID, ok := storage.ParseStreamID(args[idx+1], 0)
+			if !ok {
+				return INVALID_STREAM_ID
+			}
+			opts.LastID = lastID
+			idx++
+		default:
+			return data.Error{ErrMsg: fmt.Sprintf("Unrecognized XCLAIM option '%s'", args[idx])}
+		}
+	}
+	// like redis, delivery times in the future are the current time
+	if !opts.DeliveryTime.IsZero() && (opts.DeliveryTime.Before(time.UnixMilli(0)) || opts.DeliveryTime.After(now)) {
+		opts.DeliveryTime = now
+	}
+
+	claimed, err := strg.XClaim(key, group, consumer, minIdle, ids, opts)
+	if err != nil {
+		return groupCommandError(key, group, "claim entries", err)
+	}
+	if opts.JustID {
+		return streamIDs(claimed)
+	}
+	return streamEntries(claimed)
+}
+
+// https://redis.io/docs/latest/commands/xautoclaim/
+func handleXAutoClaim(cmd data.Array, strg storage.StorageEngine) data.Message {
+	args := bulkStrings(cmd.Elements)
+	key, group, consumer := args[1], args[2], args[3]
+
+	minIdle, errMsg := parseMinIdle("XAUTOCLAIM", args[4])
+	if errMsg != nil {
+		return errMsg
+	}
+	start, errMsg := parseRangeID(args[5], true)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	count, justID := int64(XAUTOCLAIM_DEFAULT_COUNT), false
+	for idx := 6; idx < len(args); idx++ {
+		switch option := strings.ToUpper(args[idx]); {
+		case option == "JUSTID":
+			justID = true
+		case option == "COUNT" && idx+1 < len(args):
+			var err error
+			count, err = strconv.ParseInt(args[idx+1], 10, 64)
+			if err != nil || count < 1 {
+				return data.Error{ErrMsg: "COUNT must be > 0"}
+			}
+			idx++
+		default:
+			return SYNTAX_ERROR
+		}
+	}
+
+	next, claimed, deleted, err := strg.XAutoClaim(key, group, consumer, minIdle, start, int(count), justID)
+	if err != nil {
+		return groupCommandError(key, group, "claim entries", err)
+	}
+
+	claimedReply := streamEntries(claimed)
+	if justID {
+		claimedReply = streamIDs(claimed)
+	}
+	deletedReply := make([]data.Message, len(deleted))
+	for idx, id := range deleted {
+		deletedReply[idx] = data.BulkString{Data: id.String()}
+	}
+	return data.Array{Elements: []data.Message{
+		data.BulkString{Data: next.String()},
+		claimedReply,
+		data.Array{Elements: deletedReply},
+	}}
+}
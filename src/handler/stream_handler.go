@@ -0,0 +1,314 @@
+package handler
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// the default LIMIT of approximate trimming, like redis: 100 times the entries of a node.
+const STREAM_DEFAULT_TRIM_LIMIT = 100 * storage.STREAM_NODE_MAX_ENTRIES
+
+var (
+	INVALID_STREAM_ID     = data.Error{ErrMsg: "Invalid stream ID specified as stream command argument"}
+	INVALID_INTERVAL_FROM = data.Error{ErrMsg: "invalid start ID for the interval"}
+	INVALID_INTERVAL_TO   = data.Error{ErrMsg: "invalid end ID for the interval"}
+)
+
+// parseStreamTrim parses the trimming options of XADD and XTRIM, along with NOMKSTREAM for XADD.
+// it stops at the first argument that is not an option and returns its index.
+func parseStreamTrim(args []string, isAdd bool) (storage.StreamTrim, bool, int, data.Message) {
+	trim := storage.StreamTrim{Strategy: storage.STREAM_TRIM_NONE}
+	noMkStream, hasLimit := false, false
+
+	idx := 0
+	for idx < len(args) {
+		option := strings.ToUpper(args[idx])
+		if option == "NOMKSTREAM" && isAdd {
+			noMkStream = true
+			idx++
+			continue
+		}
+		if idx+1 >= len(args) {
+			break
+		}
+
+		if option == "LIMIT" {
+			limit, err := strconv.ParseInt(args[idx+1], 10, 64)
+			if err != nil {
+				return trim, false, 0, NOT_AN_INTEGER
+			}
+			if limit < 0 {
+				return trim, false, 0, data.Error{ErrMsg: "The LIMIT argument must be >= 0."}
+			}
+			trim.Limit, hasLimit = limit, true
+			idx += 2
+			continue
+		}
+
+		strategy := storage.STREAM_TRIM_NONE
+		switch option {
+		case "MAXLEN":
+			strategy = storage.STREAM_TRIM_MAXLEN
+		case "MINID":
+			strategy = storage.STREAM_TRIM_MINID
+		}
+		if strategy == storage.STREAM_TRIM_NONE {
+			break
+		}
+		if trim.Strategy != storage.STREAM_TRIM_NONE && trim.Strategy != strategy {
+			return trim, false, 0, data.Error{ErrMsg: "syntax error, MAXLEN and MINID options at the same time are not compatible"}
+		}
+		trim.Strategy = strategy
+		idx++
+
+		trim.Approx = false
+		switch args[idx] {
+		case "~":
+			trim.Approx = true
+			idx++
+		case "=":
+			idx++
+		}
+		if idx >= len(args) {
+			return trim, false, 0, SYNTAX_ERROR
+		}
+
+		if strategy == storage.STREAM_TRIM_MAXLEN {
+			maxLen, err := strconv.ParseInt(args[idx], 10, 64)
+			if err != nil {
+				return trim, false, 0, NOT_AN_INTEGER
+			}
+			if maxLen < 0 {
+				return trim, false, 0, data.Error{ErrMsg: "The MAXLEN argument must be >= 0."}
+			}
+			trim.MaxLen = maxLen
+		} else {
+			minID, ok := storage.ParseStreamID(args[idx], 0)
+			if !ok {
+				return trim, false, 0, INVALID_STREAM_ID
+			}
+			trim.MinID = minID
+		}
+		idx++
+	}
+
+	if hasLimit && !trim.Approx {
+		return trim, false, 0, data.Error{ErrMsg: "syntax error, LIMIT cannot be used without the special ~ option"}
+	}
+	if trim.Approx && !hasLimit {
+		trim.Limit = STREAM_DEFAULT_TRIM_LIMIT
+	}
+	return trim, noMkStream, idx, nil
+}
+
+// parseStreamAddID parses the ID given to XADD, which may be * or have a * sequence to be generated.
+func parseStreamAddID(value string) (storage.StreamAddID, bool) {
+	if value == "*" {
+		return storage.StreamAddID{AutoMs: true}, true
+	}
+	if msPart, found := strings.CutSuffix(value, "-*"); found {
+		ms, err := strconv.ParseUint(msPart, 10, 64)
+		return storage.StreamAddID{ID: storage.StreamID{Ms: ms}, AutoSeq: true}, err == nil
+	}
+
+	id, ok := storage.ParseStreamID(value, 0)
+	return storage.StreamAddID{ID: id}, ok
+}
+
+// parseRangeID parses a bound of XRANGE. - and + are the smallest and largest IDs, a missing sequence
+// is the smallest or largest one depending on the bound, and a leading ( excludes the ID from the range.
+func parseRangeID(value string, isStart bool) (storage.StreamID, data.Message) {
+	switch value {
+	case "-":
+		return storage.MIN_STREAM_ID, nil
+	case "+":
+		return storage.MAX_STREAM_ID, nil
+	}
+
+	missingSeq := uint64(0)
+	if !isStart {
+		missingSeq = storage.MAX_STREAM_ID.Seq
+	}
+	value, exclusive := strings.CutPrefix(value, "(")
+	id, ok := storage.ParseStreamID(value, missingSeq)
+	if !ok {
+		return id, INVALID_STREAM_ID
+	}
+	if !exclusive {
+		return id, nil
+	}
+
+	if isStart {
+		if id, ok = id.Next(); !ok {
+			return id, INVALID_INTERVAL_FROM
+		}
+		return id, nil
+	}
+	if id, ok = id.Prev(); !ok {
+		return id, INVALID_INTERVAL_TO
+	}
+	return id, nil
+}
+
+// streamEntries renders entries as an array of the ID and the fields of each entry.
+// entries without fields were deleted, and have a null in place of their fields.
+func streamEntries(entries []storage.StreamEntry) data.Array {
+	elements := make([]data.Message, len(entries))
+	for idx, entry := range entries {
+		if entry.Fields == nil {
+			elements[idx] = data.Array{Elements: []data.Message{data.BulkString{Data: entry.ID.String()}, data.Null{}}}
+			continue
+		}
+
+		fields := make([]data.Message, len(entry.Fields))
+		for fieldIdx, field := range entry.Fields {
+			fields[fieldIdx] = data.BulkString{Data: field}
+		}
+		elements[idx] = data.Array{Elements: []data.Message{
+			data.BulkString{Data: entry.ID.String()},
+			data.Array{Elements: fields},
+		}}
+	}
+	return data.Array{Elements: elements}
+}
+
+// https://redis.io/docs/latest/commands/xadd/
+func handleXAdd(cmd data.Array, strg storage.StorageEngine) data.Message {
+	args := bulkStrings(cmd.Elements)
+	key := args[1]
+
+	trim, noMkStream, idx, errMsg := parseStreamTrim(args[2:], true)
+	if errMsg != nil {
+		return errMsg
+	}
+	idx += 2
+
+	if fields := len(args) - idx - 1; fields <= 0 || fields%2 != 0 {
+		return data.Error{ErrMsg: "wrong number of arguments for 'xadd' command"}
+	}
+	fields := args[idx+1:]
+	addID, ok := parseStreamAddID(args[idx])
+	if !ok {
+		return INVALID_STREAM_ID
+	}
+
+	id, added, err := strg.XAdd(key, addID, fields, noMkStream, trim)
+	if err != nil {
+		return storageError("add entry", err)
+	}
+	if !added {
+		return data.Null{}
+	}
+	return data.BulkString{Data: id.String()}
+}
+
+// propagateXAdd replaces the ID given to XADD with the ID of the added entry, so that replicas add the
+// entry with the same ID when it was generated.
+func propagateXAdd(cmd data.Array, reply data.Message, _ storage.StorageEngine) []data.Array {
+	id, ok := reply.(data.BulkString)
+	if !ok {
+		return []data.Array{cmd}
+	}
+
+	_, _, idIdx, _ := parseStreamTrim(bulkStrings(cmd.Elements[2:]), true)
+	elements := slices.Clone(cmd.Elements)
+	elements[idIdx+2] = id
+	return []data.Array{{Elements: elements}}
+}
+
+// https://redis.io/docs/latest/commands/xlen/
+func handleXLen(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	length, err := strg.XLen(key)
+	if err != nil {
+		return storageError("get length", err)
+	}
+	return data.Integer{Value: length}
+}
+
+// https://redis.io/docs/latest/commands/xrange/
+// https://redis.io/docs/latest/commands/xrevrange/
+func handleXRange(cmd data.Array, strg storage.StorageEngine, reverse bool) data.Message {
+	args := bulkStrings(cmd.Elements)
+	key := args[1]
+
+	// XREVRANGE takes the end of the range first
+	startArg, endArg := args[2], args[3]
+	if reverse {
+		startArg, endArg = endArg, startArg
+	}
+	start, errMsg := parseRangeID(startArg, true)
+	if errMsg != nil {
+		return errMsg
+	}
+	end, errMsg := parseRangeID(endArg, false)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	count := int64(-1)
+	switch {
+	case len(args) == 6 && strings.ToUpper(args[4]) == "COUNT":
+		var err error
+		if count, err = strconv.ParseInt(args[5], 10, 64); err != nil {
+			return NOT_AN_INTEGER
+		}
+	case len(args) != 4:
+		return SYNTAX_ERROR
+	}
+	if count == 0 {
+		return data.Array{Elements: []data.Message{}}
+	}
+
+	entries, err := strg.XRange(key, start, end, int(max(count, 0)), reverse)
+	if err != nil {
+		return storageError("get range", err)
+	}
+	return streamEntries(entries)
+}
+
+// https://redis.io/docs/latest/commands/xtrim/
+func handleXTrim(cmd data.Array, strg storage.StorageEngine) data.Message {
+	args := bulkStrings(cmd.Elements)
+	key := args[1]
+
+	trim, _, idx, errMsg := parseStreamTrim(args[2:], false)
+	if errMsg != nil {
+		return errMsg
+	}
+	if trim.Strategy == storage.STREAM_TRIM_NONE || idx != len(args)-2 {
+		return SYNTAX_ERROR
+	}
+
+	removed, err := strg.XTrim(key, trim)
+	if err != nil {
+		return storageError("trim", err)
+	}
+	return data.Integer{Value: removed}
+}
+
+// https://redis.io/docs/latest/commands/xdel/
+func handleXDel(cmd data.Array, strg storage.StorageEngine) data.Message {
+	args := bulkStrings(cmd.Elements)
+	key := args[1]
+
+	ids := make([]storage.StreamID, len(args)-2)
+	for idx, value := range args[2:] {
+		id, ok := storage.ParseStreamID(value, 0)
+		if !ok {
+			return INVALID_STREAM_ID
+		}
+		ids[idx] = id
+	}
+
+	deleted, err := strg.XDel(key, ids)
+	if err != nil {
+		return storageError("delete entries", err)
+	}
+	return data.Integer{Value: deleted}
+}
@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// https://redis.io/docs/latest/commands/strlen/
+func handleStrLen(cmd data.Array, strg storage.StorageEngine) data.Message {
+	key := cmd.Elements[1].(data.BulkString).Data
+
+	_, val, err := strg.Get(key)
+	if err != nil {
+		return storageError("retrieve data", err)
+	}
+	return data.Integer{Value: int64(len(val))}
+}
@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// the channel the invalidation messages are published on, as RESP2 has no other way to send them.
+const TRACKING_CHANNEL = "__redis__:invalidate"
+
+var TRACKING_REDIRECT_REQUIRED = data.Error{ErrMsg: "CLIENT TRACKING ON requires REDIRECT to a client subscribed to " + TRACKING_CHANNEL + " since RESP3 is not supported"}
+
+// trackingOptions holds the mode of client side caching enabled by CLIENT TRACKING ON.
+type trackingOptions struct {
+	// redirect is the ID of the client that gets the invalidation messages
+	redirect int64
+	bcast    bool
+	optIn    bool
+	optOut   bool
+	noLoop   bool
+	// prefixes are the prefixes of the keys invalidated in BCAST mode, the empty prefix matching every key
+	prefixes []string
+}
+
+// trackingClient is the tracking state of a client.
+type trackingClient struct {
+	trackingOptions
+	// caching is set by CLIENT CACHING for the next command
+	caching bool
+	// brokenRedirect is set once the client the messages are redirected to is gone, and shows in CLIENT TRACKINGINFO
+	// and in the flags of CLIENT LIST
+	brokenRedirect bool
+}
+
+// Tracking implements the server side of client side caching. in the default mode, the keys read by a client are
+// remembered and the client is sent an invalidation message the next time they change, while in BCAST mode the
+// client is sent an invalidation message for every change of the keys matching its prefixes.
+type Tracking struct {
+	mu      sync.Mutex
+	clients map[int64]*trackingClient
+	// keys holds the clients that read each key. like redis, the IDs of the clients that stopped tracking are only
+	// dropped once the key changes.
+	keys     map[string]map[int64]struct{}
+	registry *ClientRegistry
+	pubsub   *PubSub
+
+	// checked before doing any work so that commands are not slowed down when nobody is tracking keys
+	count atomic.Int64
+}
+
+func NewTracking(registry *ClientRegistry, pubsub *PubSub) *Tracking {
+	return &Tracking{
+		clients:  make(map[int64]*trackingClient),
+		keys:     make(map[string]map[int64]struct{}),
+		registry: registry,
+		pubsub:   pubsub,
+	}
+}
+
+// enable turns tracking on for the client, or updates its options when it is already on. the prefixes are
+// added to the existing ones.
+func (t *Tracking) enable(client *Client, options trackingOptions) data.Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.clients[client.id]
+	if ok && state.bcast != options.bcast {
+		return data.Error{ErrMsg: "You can't switch BCAST mode on/off before disabling tracking for this client, " +
+			"and then re-enabling it with a different mode."}
+	}
+	if options.bcast && (options.optIn || options.optOut) {
+		return data.Error{ErrMsg: "OPTIN and OPTOUT are not compatible with BCAST"}
+	}
+	if options.optIn && options.optOut {
+		return data.Error{ErrMsg: "You can't use both OPTIN and OPTOUT"}
+	}
+	if ok && ((options.optIn && state.optOut) || (options.optOut && state.optIn)) {
+		return data.Error{ErrMsg: "You can't switch OPTIN/OPTOUT mode before disabling tracking for this client, " +
+			"and then re-enabling it with a different mode."}
+	}
+
+	prefixes := []string{}
+	if ok {
+		prefixes = state.prefixes
+	}
+	if options.bcast {
+		if len(options.prefixes) == 0 {
+			options.prefixes = []string{""}
+		}
+		for idx, prefix := range options.prefixes {
+			if errMsg := checkPrefixOverlap(prefix, prefixes, options.prefixes[idx+1:]); errMsg != nil {
+				return errMsg
+			}
+		}
+		for _, prefix := range options.prefixes {
+			if !slices.Contains(prefixes, prefix) {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	}
+	options.prefixes = prefixes
+	// without RESP3 push messages, the invalidation messages can only be received in Pub/Sub mode, which a
+	// client reading keys is never in
+	if options.redirect == 0 {
+		return TRACKING_REDIRECT_REQUIRED
+	}
+
+	if !ok {
+		t.count.Add(1)
+	}
+	t.clients[client.id] = &trackingClient{trackingOptions: options}
+	return OK
+}
+
+// checkPrefixOverlap makes sure that a new prefix doesn't overlap with the existing prefixes of the client,
+// nor with the other new prefixes.
+func checkPrefixOverlap(prefix string, existing []string, others []string) data.Message {
+	overlaps := func(other string) bool {
+		return strings.HasPrefix(prefix, other) || strings.HasPrefix(other, prefix)
+	}
+	for _, other := range existing {
+		if other != prefix && overlaps(other) {
+			return data.Error{ErrMsg: "Prefix '" + prefix + "' overlaps with an existing prefix '" + other +
+				"'. Prefixes for a single client must not overlap."}
+		}
+	}
+	for _, other := range others {
+		if overlaps(other) {
+			return data.Error{ErrMsg: "Prefix '" + prefix + "' overlaps with another provided prefix '" + other +
+				"'. Prefixes for a single client must not overlap."}
+		}
+	}
+	return nil
+}
+
+// disable turns tracking off for the client.
+func (t *Tracking) disable(client *Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.clients[client.id]; ok {
+		delete(t.clients, client.id)
+		t.count.Add(-1)
+	}
+}
+
+// setCaching applies CLIENT CACHING to the next command of the client.
+func (t *Tracking) setCaching(client *Client, yes bool) data.Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.clients[client.id]
+	if !ok || (!state.optIn && !state.optOut) {
+		return data.Error{ErrMsg: "CLIENT CACHING can be called only when the client is in tracking mode with OPTIN or OPTOUT mode enabled"}
+	}
+	if yes && !state.optIn {
+		return data.Error{ErrMsg: "CLIENT CACHING YES is only valid when tracking is enabled in OPTIN mode."}
+	}
+	if !yes && !state.optOut {
+		return data.Error{ErrMsg: "CLIENT CACHING NO is only valid when tracking is enabled in OPTOUT mode."}
+	}
+	state.caching = true
+	return OK
+}
+
+// state returns a copy of the tracking state of the client, if tracking is on.
+func (t *Tracking) state(client *Client) (trackingClient, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.clients[client.id]
+	if !ok {
+		return trackingClient{}, false
+	}
+	copied := *state
+	copied.prefixes = slices.Clone(state.prefixes)
+	return copied, true
+}
+
+// beforeCommand remembers the keys of a read command for the client tracking them. the keys are remembered
+// before they are read, so that a change made while the command runs is never missed.
+func (t *Tracking) beforeCommand(caller *Client, spec *commandSpec, cmdArray data.Array) {
+	if t.count.Load() == 0 || caller == nil || !spec.hasFlag(CMD_FLAG_READONLY) {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.clients[caller.id]
+	if !ok || state.bcast || (state.optIn && !state.caching) || (state.optOut && state.caching) {
+		return
+	}
+	for _, key := range spec.keys(bulkStrings(cmdArray.Elements)) {
+		if _, ok := t.keys[key]; !ok {
+			t.keys[key] = make(map[int64]struct{})
+		}
+		t.keys[key][caller.id] = struct{}{}
+	}
+}
+
+// afterCommand invalidates the keys of a write command, and ends the effect of CLIENT CACHING unless the
+// command was another CLIENT command.
+func (t *Tracking) afterCommand(client *Client, caller *Client, spec *commandSpec, cmdArray data.Array) {
+	if t.count.Load() == 0 {
+		return
+	}
+	if spec.writes(cmdArray) {
+		t.invalidate(caller, spec.keys(bulkStrings(cmdArray.Elements)))
+	}
+	if client != nil && !strings.EqualFold(spec.name, CMD_CLIENT) {
+		t.mu.Lock()
+		if state, ok := t.clients[client.id]; ok {
+			state.caching = false
+		}
+		t.mu.Unlock()
+	}
+}
+
+// keyspaceEvent invalidates the keys that expire, which no command changes.
+func (t *Tracking) keyspaceEvent(event storage.KeyspaceEvent) {
+	if t.count.Load() == 0 {
+		return
+	}
+	if event.Class == storage.EVENT_CLASS_EXPIRED {
+		t.invalidate(nil, []string{event.Key})
+	}
+}
+
+// invalidate sends an invalidation message with the keys to every client tracking them, except to the client
+// that changed them when it enabled NOLOOP.
+func (t *Tracking) invalidate(origin *Client, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	invalidated := map[int64][]string{}
+	for _, key := range keys {
+		for id := range t.keys[key] {
+			if _, ok := t.clients[id]; ok {
+				invalidated[id] = append(invalidated[id], key)
+			}
+		}
+		delete(t.keys, key)
+
+		for id, state := range t.clients {
+			if !state.bcast {
+				continue
+			}
+			for _, prefix := range state.prefixes {
+				if strings.HasPrefix(key, prefix) {
+					invalidated[id] = append(invalidated[id], key)
+					break
+				}
+			}
+		}
+	}
+
+	for id, keys := range invalidated {
+		state := t.clients[id]
+		if state.noLoop && origin != nil && origin.id == id {
+			continue
+		}
+		t.sendLocked(state, keys)
+	}
+}
+
+// sendLocked writes an invalidation message to the client the messages of the client are redirected to. since
+// RESP2 has no other way to receive messages that aren't replies, it is only sent when that client is subscribed
+// to the invalidation channel. It must be called with the lock held.
+func (t *Tracking) sendLocked(state *trackingClient, keys []string) {
+	target, ok := t.registry.Get(state.redirect)
+	if !ok {
+		// redis tells the client with a tracking-redir-broken push message, which only exists in RESP3. like redis
+		// over RESP2, the client is sent nothing, as anything it didn't ask for would be taken for a reply.
+		state.brokenRedirect = true
+		return
+	}
+	if !t.pubsub.subscribed(target, TRACKING_CHANNEL) {
+		return
+	}
+
+	elements := make([]data.Message, len(keys))
+	for idx, key := range keys {
+		elements[idx] = data.BulkString{Data: key}
+	}
+	msg := data.Array{Elements: []data.Message{
+		data.BulkString{Data: "message"},
+		data.BulkString{Data: TRACKING_CHANNEL},
+		data.Array{Elements: elements},
+	}}
+	_ = target.push(msg.ToDataString())
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+// https://redis.io/docs/latest/commands/client-tracking/
+func handleClientTracking(args []string, client *Client, clients *ClientRegistry, tracking *Tracking) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+
+	options := trackingOptions{}
+	for idx := 1; idx < len(args); idx++ {
+		moreArgs := idx+1 < len(args)
+		switch option := strings.ToUpper(args[idx]); {
+		case option == "REDIRECT" && moreArgs:
+			idx++
+			if options.redirect != 0 {
+				return data.Error{ErrMsg: "A client can only redirect to a single other client"}
+			}
+			redirect, err := strconv.ParseInt(args[idx], 10, 64)
+			if err != nil {
+				return NOT_AN_INTEGER
+			}
+			// the client has to exist now, even though it may disconnect later
+			if _, ok := clients.Get(redirect); !ok {
+				return data.Error{ErrMsg: "The client ID you want redirect to does not exist"}
+			}
+			options.redirect = redirect
+		case option == "PREFIX" && moreArgs:
+			idx++
+			options.prefixes = append(options.prefixes, args[idx])
+		case option == "BCAST":
+			options.bcast = true
+		case option == "OPTIN":
+			options.optIn = true
+		case option == "OPTOUT":
+			options.optOut = true
+		case option == "NOLOOP":
+			options.noLoop = true
+		default:
+			return SYNTAX_ERROR
+		}
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "ON":
+		if !options.bcast && len(options.prefixes) > 0 {
+			return data.Error{ErrMsg: "PREFIX option requires BCAST mode to be enabled"}
+		}
+		return tracking.enable(client, options)
+	case "OFF":
+		tracking.disable(client)
+		return OK
+	default:
+		return SYNTAX_ERROR
+	}
+}
+
+// https://redis.io/docs/latest/commands/client-caching/
+func handleClientCaching(args []string, client *Client, tracking *Tracking) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+	switch strings.ToUpper(args[0]) {
+	case "YES":
+		return tracking.setCaching(client, true)
+	case "NO":
+		return tracking.setCaching(client, false)
+	default:
+		return SYNTAX_ERROR
+	}
+}
+
+// https://redis.io/docs/latest/commands/client-getredir/
+func handleClientGetRedir(client *Client, tracking *Tracking) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+	state, ok := tracking.state(client)
+	if !ok {
+		return data.Integer{Value: -1}
+	}
+	return data.Integer{Value: state.redirect}
+}
+
+// https://redis.io/docs/latest/commands/client-trackinginfo/
+func handleClientTrackingInfo(client *Client, tracking *Tracking) data.Message {
+	if client == nil {
+		return NO_CLIENT_CONN
+	}
+
+	state, ok := tracking.state(client)
+	flags := []data.Message{}
+	redirect := int64(-1)
+	if !ok {
+		flags = append(flags, data.BulkString{Data: "off"})
+	} else {
+		flags = append(flags, data.BulkString{Data: "on"})
+		redirect = state.redirect
+	}
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"bcast", state.bcast},
+		{"optin", state.optIn},
+		{"optout", state.optOut},
+		{"caching-yes", state.optIn && state.caching},
+		{"caching-no", state.optOut && state.caching},
+		{"noloop", state.noLoop},
+		{"broken_redirect", state.brokenRedirect},
+	} {
+		if flag.set {
+			flags = append(flags, data.BulkString{Data: flag.name})
+		}
+	}
+
+	prefixes := make([]data.Message, len(state.prefixes))
+	for idx, prefix := range state.prefixes {
+		prefixes[idx] = data.BulkString{Data: prefix}
+	}
+
+	return data.Array{Elements: []data.Message{
+		data.BulkString{Data: "flags"},
+		data.Array{Elements: flags},
+		data.BulkString{Data: "redirect"},
+		data.Integer{Value: redirect},
+		data.BulkString{Data: "prefixes"},
+		data.Array{Elements: prefixes},
+	}}
+}
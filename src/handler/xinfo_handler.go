@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+const XINFO_FULL_DEFAULT_COUNT = 10
+
+// optionalStreamEntry renders an entry that may be missing.
+func optionalStreamEntry(entry *storage.StreamEntry) data.Message {
+	if entry == nil {
+		return data.Null{}
+	}
+	return streamEntries([]storage.StreamEntry{*entry}).Elements[0]
+}
+
+// optionalInteger renders an integer that may be unknown.
+func optionalInteger(value int64, ok bool) data.Message {
+	if !ok {
+		return data.Null{}
+	}
+	return data.Integer{Value: value}
+}
+
+// https://redis.io/docs/latest/commands/xinfo/
+func handleXInfo(cmd data.Array, strg storage.StorageEngine) data.Message {
+	args := bulkStrings(cmd.Elements)
+
+	switch strings.ToUpper(args[1]) {
+	case "STREAM":
+		return handleXInfoStream(args[2:], strg)
+	case "GROUPS":
+		groups, err := strg.XInfoGroups(args[2])
+		if err != nil {
+			return storageError("get consumer groups", err)
+		}
+		elements := make([]data.Message, len(groups))
+		for idx, group := range groups {
+			elements[idx] = xinfoGroup(group)
+		}
+		return data.Array{Elements: elements}
+	case "CONSUMERS":
+		consumers, err := strg.XInfoConsumers(args[2], args[3])
+		if err != nil {
+			return storageError("get consumers", err)
+		}
+
+		now := time.Now()
+		elements := make([]data.Message, len(consumers))
+		for idx, consumer := range consumers {
+			inactive := int64(-1)
+			if !consumer.ActiveTime.IsZero() {
+				inactive = now.Sub(consumer.ActiveTime).Milliseconds()
+			}
+			elements[idx] = data.Array{Elements: []data.Message{
+				data.BulkString{Data: "name"},
+				data.BulkString{Data: consumer.Name},
+				data.BulkString{Data: "pending"},
+				data.Integer{Value: consumer.Pending},
+				data.BulkString{Data: "idle"},
+				data.Integer{Value: now.Sub(consumer.SeenTime).Milliseconds()},
+				data.BulkString{Data: "inactive"},
+				data.Integer{Value: inactive},
+			}}
+		}
+		return data.Array{Elements: elements}
+	default:
+		return data.Error{ErrMsg: fmt.Sprintf("unsupported subcommand %s for XINFO", args[1])}
+	}
+}
+
+// xinfoGroup renders a consumer group as XINFO GROUPS does.
+func xinfoGroup(group storage.StreamGroupInfo) data.Message {
+	return data.Array{Elements: []data.Message{
+		data.BulkString{Data: "name"},
+		data.BulkString{Data: group.Name},
+		data.BulkString{Data: "consumers"},
+		data.Integer{Value: group.Consumers},
+		data.BulkString{Data: "pending"},
+		data.Integer{Value: group.Pending},
+		data.BulkString{Data: "last-delivered-id"},
+		data.BulkString{Data: group.LastDeliveredID.String()},
+		data.BulkString{Data: "entries-read"},
+		optionalInteger(group.EntriesRead, group.HasEntriesRead),
+		data.BulkString{Data: "lag"},
+		optionalInteger(group.Lag, group.HasLag),
+	}}
+}
+
+// handleXInfoStream handles XINFO STREAM key [FULL [COUNT count]].
+func handleXInfoStream(args []string, strg storage.StorageEngine) data.Message {
+	key := args[0]
+	full, count := false, int64(XINFO_FULL_DEFAULT_COUNT)
+	switch {
+	case len(args) == 1:
+	case len(args) == 2 && strings.ToUpper(args[1]) == "FULL":
+		full = true
+	case len(args) == 4 && strings.ToUpper(args[1]) == "FULL" && strings.ToUpper(args[2]) == "COUNT":
+		var err error
+		if count, err = strconv.ParseInt(args[3], 10, 64); err != nil {
+			return NOT_AN_INTEGER
+		}
+		full = true
+	default:
+		return SYNTAX_ERROR
+	}
+
+	info, err := strg.XInfoStream(key, full, int(max(count, 0)))
+	if err != nil {
+		return storageError("get stream", err)
+	}
+
+	elements := []data.Message{
+		data.BulkString{Data: "length"},
+		data.Integer{Value: info.Length},
+		data.BulkString{Data: "radix-tree-keys"},
+		data.Integer{Value: info.Nodes},
+		data.BulkString{Data: "radix-tree-nodes"},
+		data.Integer{Value: info.Nodes},
+		data.BulkString{Data: "last-generated-id"},
+		data.BulkString{Data: info.LastID.String()},
+		data.BulkString{Data: "max-deleted-entry-id"},
+		data.BulkString{Data: info.MaxDeletedID.String()},
+		data.BulkString{Data: "entries-added"},
+		data.Integer{Value: info.EntriesAdded},
+		data.BulkString{Data: "recorded-first-entry-id"},
+		data.BulkString{Data: info.FirstID.String()},
+	}
+	if !full {
+		return data.Array{Elements: append(elements,
+			data.BulkString{Data: "groups"},
+			data.Integer{Value: int64(len(info.Groups))},
+			data.BulkString{Data: "first-entry"},
+			optionalStreamEntry(info.First),
+			data.BulkString{Data: "last-entry"},
+			optionalStreamEntry(info.Last),
+		)}
+	}
+
+	groups := make([]data.Message, len(info.Groups))
+	for idx, group := range info.Groups {
+		groups[idx] = xinfoFullGroup(group)
+	}
+	return data.Array{Elements: append(elements,
+		data.BulkString{Data: "entries"},
+		streamEntries(info.Entries),
+		data.BulkString{Data: "groups"},
+		data.Array{Elements: groups},
+	)}
+}
+
+// xinfoFullGroup renders a consumer group with its pending entries and consumers, as XINFO STREAM FULL does.
+func xinfoFullGroup(group storage.StreamGroupInfo) data.Message {
+	pending := make([]data.Message, len(group.PendingEntries))
+	for idx, entry := range group.PendingEntries {
+		pending[idx] = data.Array{Elements: []data.Message{
+			data.BulkString{Data: entry.ID.String()},
+			data.BulkString{Data: entry.Consumer},
+			data.Integer{Value: entry.DeliveryTime.UnixMilli()},
+			data.Integer{Value: entry.DeliveryCount},
+		}}
+	}
+
+	consumers := make([]data.Message, len(group.ConsumerInfos))
+	for idx, consumer := range group.ConsumerInfos {
+		consumerPending := make([]data.Message, len(consumer.PendingEntries))
+		for entryIdx, entry := range consumer.PendingEntries {
+			consumerPending[entryIdx] = data.Array{Elements: []data.Message{
+				data.BulkString{Data: entry.ID.String()},
+				data.Integer{Value: entry.DeliveryTime.UnixMilli()},
+				data.Integer{Value: entry.DeliveryCount},
+			}}
+		}
+
+		activeTime := int64(-1)
+		if !consumer.ActiveTime.IsZero() {
+			activeTime = consumer.ActiveTime.UnixMilli()
+		}
+		consumers[idx] = data.Array{Elements: []data.Message{
+			data.BulkString{Data: "name"},
+			data.BulkString{Data: consumer.Name},
+			data.BulkString{Data: "seen-time"},
+			data.Integer{Value: consumer.SeenTime.UnixMilli()},
+			data.BulkString{Data: "active-time"},
+			data.Integer{Value: activeTime},
+			data.BulkString{Data: "pel-count"},
+			data.Integer{Value: consumer.Pending},
+			data.BulkString{Data: "pending"},
+			data.Array{Elements: consumerPending},
+		}}
+	}
+
+	return data.Array{Elements: []data.Message{
+		data.BulkString{Data: "name"},
+		data.BulkString{Data: group.Name},
+		data.BulkString{Data: "last-delivered-id"},
+		data.BulkString{Data: group.LastDeliveredID.String()},
+		data.BulkString{Data: "entries-read"},
+		optionalInteger(group.EntriesRead, group.HasEntriesRead),
+		data.BulkString{Data: "lag"},
+		optionalInteger(group.Lag, group.HasLag),
+		data.BulkString{Data: "pel-count"},
+		data.Integer{Value: group.Pending},
+		data.BulkString{Data: "pending"},
+		data.Array{Elements: pending},
+		data.BulkString{Data: "consumers"},
+		data.Array{Elements: consumers},
+	}}
+}
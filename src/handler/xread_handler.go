@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+// the ID of XREADGROUP that reads the entries never delivered to the group.
+const XREADGROUP_NEW_ENTRIES = ">"
+
+// xreadOptions holds the options shared by XREAD and XREADGROUP.
+type xreadOptions struct {
+	count    int64
+	blocking bool
+	timeout  time.Duration
+	noAck    bool
+	group    string
+	consumer string
+	keys     []string
+	ids      []string
+}
+
+// xreadKeys returns the streams of an XREAD command, which are the first half of the arguments after STREAMS.
+func xreadKeys(args []string) []string {
+	idx := slices.IndexFunc(args, func(arg string) bool {
+		return strings.ToUpper(arg) == "STREAMS"
+	})
+	if idx < 0 {
+		return nil
+	}
+
+	streams := args[idx+1:]
+	return streams[:len(streams)/2]
+}
+
+// parseXRead parses the options of XREAD and XREADGROUP, which only XREADGROUP takes GROUP and NOACK for.
+func parseXRead(command string, args []string, isGroup bool) (xreadOptions, data.Message) {
+	opts := xreadOptions{}
+	streamsIdx := -1
+	for idx := 0; idx < len(args) && streamsIdx < 0; idx++ {
+		option := strings.ToUpper(args[idx])
+		switch {
+		case option == "STREAMS":
+			streamsIdx = idx + 1
+		case option == "COUNT" && idx+1 < len(args):
+			var err error
+			if opts.count, err = strconv.ParseInt(args[idx+1], 10, 64); err != nil {
+				return opts, NOT_AN_INTEGER
+			}
+			idx++
+		case option == "BLOCK" && idx+1 < len(args):
+			millis, err := strconv.ParseInt(args[idx+1], 10, 64)
+			if err != nil {
+				return opts, data.Error{ErrMsg: "timeout is not an integer or out of range"}
+			}
+			if millis < 0 {
+				return opts, data.Error{ErrMsg: "timeout is negative"}
+			}
+			opts.blocking, opts.timeout = true, time.Duration(millis)*time.Millisecond
+			idx++
+		case option == "GROUP" && idx+2 < len(args):
+			if !isGroup {
+				return opts, data.Error{ErrMsg: "The GROUP option is only supported by XREADGROUP. You called XREAD instead."}
+			}
+			opts.group, opts.consumer = args[idx+1], args[idx+2]
+			idx += 2
+		case option == "NOACK" && isGroup:
+			opts.noAck = true
+		default:
+			return opts, SYNTAX_ERROR
+		}
+	}
+	if streamsIdx < 0 {
+		return opts, SYNTAX_ERROR
+	}
+	if isGroup && opts.group == "" {
+		return opts, data.Error{ErrMsg: "Missing GROUP option for XREADGROUP"}
+	}
+
+	streams := args[streamsIdx:]
+	if len(streams) == 0 || len(streams)%2 != 0 {
+		newID := "$"
+		if isGroup {
+			newID = XREADGROUP_NEW_ENTRIES
+		}
+		return opts, data.Error{ErrMsg: fmt.Sprintf("Unbalanced '%s' list of streams: for each stream key an ID or '%s' must be specified.", command, newID)}
+	}
+	opts.keys, opts.ids = streams[:len(streams)/2], streams[len(streams)/2:]
+	return opts, nil
+}
+
+// enterFunc waits for the turn of a command to run, and returns the function to call with the reply once it is
+// done, which is nil when there was nothing to reply with yet.
+type enterFunc func() (func(reply data.Message), data.Message)
+
+// blockingRead returns the results of read, waiting for entries to be added to the streams when the
+// read has no results and the command is blocking. read returns nil when it has no results. every read
+// runs in a turn of its own given by enter, like a new command, so that it never sees a script half done.
+func blockingRead(opts xreadOptions, added <-chan struct{}, enter enterFunc, read func() (data.Message, error)) (data.Message, error) {
+	// BLOCK 0 waits forever, which a nil channel does
+	var expired <-chan time.Time
+	if opts.blocking && opts.timeout > 0 {
+		timer := time.NewTimer(opts.timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		done, errMsg := enter()
+		if errMsg != nil {
+			return errMsg, nil
+		}
+		reply, err := read()
+		done(reply)
+		if err != nil || reply != nil {
+			return reply, err
+		}
+		if !opts.blocking {
+			return data.Null{}, nil
+		}
+
+		select {
+		case <-added:
+		case <-expired:
+			return data.Null{}, nil
+		}
+	}
+}
+
+// streamReplies renders the entries read from each stream, leaving out the streams for which keep is false.
+func streamReplies(keys []string, results [][]storage.StreamEntry, keep func(idx int) bool) data.Message {
+	replies := []data.Message{}
+	for idx, entries := range results {
+		if keep(idx) {
+			replies = append(replies, data.Array{Elements: []data.Message{
+				data.BulkString{Data: keys[idx]},
+				streamEntries(entries),
+			}})
+		}
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	return data.Array{Elements: replies}
+}
+
+// https://redis.io/docs/latest/commands/xread/
+func handleXRead(cmd data.Array, strg storage.StorageEngine, enter enterFunc) data.Message {
+	opts, errMsg := parseXRead("xread", bulkStrings(cmd.Elements[1:]), false)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	// the streams are watched before $ is resolved, so that no entry added in between is missed
+	var added <-chan struct{}
+	if opts.blocking {
+		watch, stop := strg.WatchStreams(opts.keys)
+		defer stop()
+		added = watch
+	}
+
+	lastIDs, err := strg.StreamLastIDs(opts.keys)
+	if err != nil {
+		return storageError("read", err)
+	}
+	ids := make([]storage.StreamID, len(opts.keys))
+	for idx, value := range opts.ids {
+		switch value {
+		case "$":
+			ids[idx] = lastIDs[idx]
+			continue
+		case XREADGROUP_NEW_ENTRIES:
+			return data.Error{ErrMsg: "The > ID can be specified only when calling XREADGROUP using the GROUP <group> <consumer> option."}
+		}
+		id, ok := storage.ParseStreamID(value, 0)
+		if !ok {
+			return INVALID_STREAM_ID
+		}
+		ids[idx] = id
+	}
+
+	reply, err := blockingRead(opts, added, enter, func() (data.Message, error) {
+		results, err := strg.XRead(opts.keys, ids, int(max(opts.count, 0)))
+		if err != nil {
+			return nil, err
+		}
+		return streamReplies(opts.keys, results, func(idx int) bool {
+			return len(results[idx]) > 0
+		}), nil
+	})
+	if err != nil {
+		return storageError("read", err)
+	}
+	return reply
+}
+
+// https://redis.io/docs/latest/commands/xreadgroup/
+func handleXReadGroup(cmd data.Array, strg storage.StorageEngine, enter enterFunc) data.Message {
+	opts, errMsg := parseXRead("xreadgroup", bulkStrings(cmd.Elements[1:]), true)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	ids := make([]storage.StreamID, len(opts.keys))
+	newOnly := make([]bool, len(opts.keys))
+	for idx, value := range opts.ids {
+		switch value {
+		case XREADGROUP_NEW_ENTRIES:
+			newOnly[idx] = true
+			continue
+		case "$":
+			return data.Error{ErrMsg: "The $ ID is meaningless in the context of XREADGROUP: you want to read the history of this consumer by specifying a proper ID, or use the > ID to get new messages. The $ ID would just return an empty result set."}
+		}
+		id, ok := storage.ParseStreamID(value, 0)
+		if !ok {
+			return INVALID_STREAM_ID
+		}
+		ids[idx] = id
+	}
+
+	var added <-chan struct{}
+	if opts.blocking {
+		watch, stop := strg.WatchStreams(opts.keys)
+		defer stop()
+		added = watch
+	}
+
+	reply, err := blockingRead(opts, added, enter, func() (data.Message, error) {
+		results, err := strg.XReadGroup(opts.keys, opts.group, opts.consumer, ids, newOnly, int(max(opts.count, 0)), opts.noAck)
+		if err != nil {
+			return nil, err
+		}
+		// the history of the consumer is always replied with, even when it is empty
+		return streamReplies(opts.keys, results, func(idx int) bool {
+			return !newOnly[idx] || len(results[idx]) > 0
+		}), nil
+	})
+
+	var noGroup *storage.NoGroupError
+	if errors.As(err, &noGroup) {
+		return data.Error{ErrMsg: fmt.Sprintf("NOGROUP No such key '%s' or consumer group '%s' in XREADGROUP with GROUP option", noGroup.Key, noGroup.Group)}
+	}
+	if err != nil {
+		return storageError("read", err)
+	}
+	return reply
+}
+
+// withoutBlock leaves out the BLOCK option of XREAD and XREADGROUP, so that scripts never wait for entries.
+func withoutBlock(cmd data.Array, _ data.Message) data.Array {
+	elements := []data.Message{cmd.Elements[0]}
+	for idx := 1; idx < len(cmd.Elements); idx++ {
+		switch strings.ToUpper(cmd.Elements[idx].(data.BulkString).Data) {
+		case "STREAMS":
+			elements = append(elements, cmd.Elements[idx:]...)
+			return data.Array{Elements: elements}
+		case "BLOCK":
+			idx++
+			continue
+		case "GROUP":
+			elements = append(elements, cmd.Elements[idx:min(idx+3, len(cmd.Elements))]...)
+			idx += 2
+			continue
+		case "COUNT":
+			elements = append(elements, cmd.Elements[idx:min(idx+2, len(cmd.Elements))]...)
+			idx++
+			continue
+		}
+		elements = append(elements, cmd.Elements[idx])
+	}
+	return data.Array{Elements: elements}
+}
+
+// propagateXReadGroup sends the effects of XREADGROUP to the replicas in place of the command, like redis: every
+// entry delivered to the consumer is claimed for it with XCLAIM, and the last entry delivered to the group is set
+// with XGROUP SETID. the history of the consumer is read without effects for the replicas.
+func propagateXReadGroup(cmd data.Array, reply data.Message, strg storage.StorageEngine) []data.Array {
+	opts, errMsg := parseXRead("xreadgroup", bulkStrings(cmd.Elements[1:]), true)
+	streams, ok := reply.(data.Array)
+	if errMsg != nil || !ok {
+		return nil
+	}
+
+	cmds := []data.Array{}
+	deliveryTime := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	for _, stream := range streams.Elements {
+		key := stream.(data.Array).Elements[0].(data.BulkString).Data
+		entries := stream.(data.Array).Elements[1].(data.Array).Elements
+		keyIdx := slices.Index(opts.keys, key)
+		if keyIdx < 0 || opts.ids[keyIdx] != XREADGROUP_NEW_ENTRIES || len(entries) == 0 {
+			continue
+		}
+
+		groups, err := strg.XInfoGroups(key)
+		if err != nil {
+			continue
+		}
+		groupIdx := slices.IndexFunc(groups, func(group storage.StreamGroupInfo) bool {
+			return group.Name == opts.group
+		})
+		if groupIdx < 0 {
+			continue
+		}
+		lastID := groups[groupIdx].LastDeliveredID.String()
+		entriesRead := int64(XGROUP_ENTRIES_READ_UNKNOWN)
+		if groups[groupIdx].HasEntriesRead {
+			entriesRead = groups[groupIdx].EntriesRead
+		}
+
+		// the entries read with NOACK aren't pending, but the consumer is still created
+		if opts.noAck {
+			cmds = append(cmds, newCommand("XGROUP", "CREATECONSUMER", key, opts.group, opts.consumer))
+		} else {
+			for _, entry := range entries {
+				id := entry.(data.Array).Elements[0].(data.BulkString).Data
+				cmds = append(cmds, newCommand("XCLAIM", key, opts.group, opts.consumer, "0", id, "TIME", deliveryTime,
+					"RETRYCOUNT", "1", "FORCE", "JUSTID", "LASTID", lastID))
+			}
+		}
+		cmds = append(cmds, newCommand("XGROUP", "SETID", key, opts.group, lastID, "ENTRIESREAD", strconv.FormatInt(entriesRead, 10)))
+	}
+	return cmds
+}
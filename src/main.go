@@ -2,8 +2,13 @@ package main
 
 import (
 	"log/slog"
+	"net"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/vrajashkr/cc-kv-go/src/config"
 	"github.com/vrajashkr/cc-kv-go/src/handler"
 	"github.com/vrajashkr/cc-kv-go/src/server"
 	"github.com/vrajashkr/cc-kv-go/src/storage"
@@ -14,19 +19,93 @@ func main() {
 
 	slog.Info("starting cc-kv-go server")
 
+	cfg := config.NewConfig()
+	if len(os.Args) > 1 {
+		slog.Info("loading configuration", "path", os.Args[1])
+		if err := cfg.LoadFile(os.Args[1]); err != nil {
+			slog.Error("failed to load configuration", "error", err.Error())
+			os.Exit(1)
+		}
+	}
+
 	slog.Info("initializing storage engine")
 	storageEngine := storage.NewMapStorageEngine()
 
 	slog.Info("initializing command handler")
-	commandHandler := handler.NewCommandHandler(&storageEngine)
+	commandHandler := handler.NewCommandHandlerWithConfig(&storageEngine, cfg)
+	if err := commandHandler.LoadACLFile(); err != nil {
+		slog.Error("failed to load ACL file", "error", err.Error())
+		os.Exit(1)
+	}
+
+	srv := server.NewServer(func(conn net.Conn) server.Session {
+		return commandHandler.NewClient(conn)
+	})
+	srv.ApplyConfig(cfg)
+	bindAddrs := strings.Fields(cfg.Get("bind"))
+
+	if cfg.GetInt("port") != 0 {
+		slog.Info("starting listener", "bind", cfg.Get("bind"), "port", cfg.Get("port"))
+		if err := srv.ListenTcp(bindAddrs, cfg.Get("port")); err != nil {
+			slog.Error("failed to start listener", "error", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if cfg.GetInt("tls-port") != 0 {
+		certs, err := server.NewTlsCertificates(
+			cfg.Get("tls-cert-file"),
+			cfg.Get("tls-key-file"),
+			cfg.Get("tls-ca-cert-file"),
+			cfg.Get("tls-auth-clients"),
+		)
+		if err != nil {
+			slog.Error("failed to load TLS certificates", "error", err.Error())
+			os.Exit(1)
+		}
+		go reloadCertificatesOnSighup(certs)
+
+		slog.Info("starting TLS listener", "bind", cfg.Get("bind"), "port", cfg.Get("tls-port"))
+		if err := srv.ListenTls(bindAddrs, cfg.Get("tls-port"), certs); err != nil {
+			slog.Error("failed to start TLS listener", "error", err.Error())
+			os.Exit(1)
+		}
+	}
 
-	slog.Info("starting listener")
-	listener, err := server.NewTcpServer("6379", commandHandler.ServeInput)
-	if err != nil {
-		slog.Error("failed to start listener", "error", err.Error())
+	if unixSocket := cfg.Get("unixsocket"); unixSocket != "" {
+		slog.Info("starting unix socket listener", "path", unixSocket)
+		if err := srv.ListenUnix(unixSocket, os.FileMode(cfg.GetOctal("unixsocketperm"))); err != nil {
+			slog.Error("failed to start unix socket listener", "error", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if len(srv.Addrs()) == 0 {
+		slog.Error("no listeners configured, set port, tls-port or unixsocket")
+		os.Exit(1)
+	}
+	defer srv.StopListen()
+
+	if err := commandHandler.StartCluster(); err != nil {
+		slog.Error("failed to start the cluster bus", "error", err.Error())
 		os.Exit(1)
 	}
-	defer listener.StopListen()
+	defer commandHandler.StopCluster()
 
-	listener.Serve()
+	commandHandler.StartActiveExpiry()
+	defer commandHandler.StopActiveExpiry()
+
+	commandHandler.StartReplication()
+	srv.Serve()
+}
+
+func reloadCertificatesOnSighup(certs *server.TlsCertificates) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	for range sighup {
+		slog.Info("reloading TLS certificates")
+		if err := certs.Reload(); err != nil {
+			slog.Error("failed to reload TLS certificates", "error", err.Error())
+		}
+	}
 }
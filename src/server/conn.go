@@ -0,0 +1,155 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/config"
+)
+
+const (
+	// the time given to a closing connection to flush its pending output
+	CLOSE_FLUSH_TIMEOUT = 1 * time.Second
+)
+
+var ErrOutputBufferLimit = errors.New("client closed for overcoming of output buffer limits")
+
+// bufferedConn queues writes and flushes them to the connection from a dedicated goroutine.
+// Sessions can push data to the client concurrently with the replies written by the connection goroutine,
+// and clients that do not read their replies are disconnected once they exceed their output buffer limits.
+type bufferedConn struct {
+	net.Conn
+
+	mu             sync.Mutex
+	cond           *sync.Cond
+	pending        [][]byte
+	pendingBytes   int64
+	softLimitSince time.Time
+	closed         bool
+	err            error
+	limit          func() config.OutputBufferLimit
+	writerDone     chan struct{}
+	closeOnce      sync.Once
+	closeErr       error
+}
+
+func newBufferedConn(conn net.Conn, limit func() config.OutputBufferLimit) *bufferedConn {
+	bc := &bufferedConn{
+		Conn:       conn,
+		limit:      limit,
+		writerDone: make(chan struct{}),
+	}
+	bc.cond = sync.NewCond(&bc.mu)
+	go bc.flushLoop()
+	return bc
+}
+
+func (bc *bufferedConn) Write(b []byte) (int, error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if bc.err != nil {
+		return 0, bc.err
+	}
+	if bc.closed {
+		return 0, net.ErrClosed
+	}
+
+	bc.pending = append(bc.pending, append([]byte(nil), b...))
+	bc.pendingBytes += int64(len(b))
+
+	if bc.exceedsLimit() {
+		bc.err = ErrOutputBufferLimit
+		slog.Warn("disconnecting client", "addr", bc.RemoteAddr().String(), "reason", ErrOutputBufferLimit.Error(), "pending", bc.pendingBytes)
+		// abort without flushing, the client is not reading anyway
+		_ = bc.Conn.Close()
+		bc.cond.Broadcast()
+		return 0, bc.err
+	}
+
+	bc.cond.Signal()
+	return len(b), nil
+}
+
+// exceedsLimit checks the pending output against the limits of the client. It must be called with the lock held.
+func (bc *bufferedConn) exceedsLimit() bool {
+	if bc.limit == nil {
+		return false
+	}
+
+	limit := bc.limit()
+	if limit.Hard > 0 && bc.pendingBytes >= limit.Hard {
+		return true
+	}
+
+	if limit.Soft > 0 && bc.pendingBytes >= limit.Soft {
+		if bc.softLimitSince.IsZero() {
+			bc.softLimitSince = time.Now()
+		} else if time.Since(bc.softLimitSince) >= limit.SoftDuration {
+			return true
+		}
+	} else {
+		bc.softLimitSince = time.Time{}
+	}
+
+	return false
+}
+
+// PendingBytes returns the amount of output waiting to be written to the client.
+func (bc *bufferedConn) PendingBytes() int64 {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return bc.pendingBytes
+}
+
+func (bc *bufferedConn) flushLoop() {
+	defer close(bc.writerDone)
+
+	for {
+		bc.mu.Lock()
+		for len(bc.pending) == 0 && !bc.closed && bc.err == nil {
+			bc.cond.Wait()
+		}
+		if bc.err != nil || len(bc.pending) == 0 {
+			bc.mu.Unlock()
+			return
+		}
+		chunks := bc.pending
+		bc.pending = nil
+		bc.mu.Unlock()
+
+		// the chunks queued since the last flush, like the replies to pipelined requests, are written at once
+		buffers := net.Buffers(chunks)
+		written, err := buffers.WriteTo(bc.Conn)
+
+		bc.mu.Lock()
+		bc.pendingBytes -= written
+		if err != nil && bc.err == nil {
+			bc.err = err
+		}
+		failed := bc.err != nil
+		bc.mu.Unlock()
+
+		if failed {
+			return
+		}
+	}
+}
+
+// Close flushes the pending output, waiting at most CLOSE_FLUSH_TIMEOUT, and closes the connection.
+func (bc *bufferedConn) Close() error {
+	bc.closeOnce.Do(func() {
+		bc.mu.Lock()
+		bc.closed = true
+		bc.cond.Broadcast()
+		bc.mu.Unlock()
+
+		_ = bc.Conn.SetWriteDeadline(time.Now().Add(CLOSE_FLUSH_TIMEOUT))
+		<-bc.writerDone
+		bc.closeErr = bc.Conn.Close()
+	})
+	return bc.closeErr
+}
@@ -1,87 +1,335 @@
 package server
 
 import (
+	"bufio"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
 )
 
 const (
-	LARGE_BUF_SIZE = 2048
-	READ_BUF_SIZE  = 128
+	READ_BUF_SIZE = 16 * 1024
+
+	MAX_CLIENTS_REACHED = "-ERR max number of clients reached\r\n"
 )
 
-type TcpServer struct {
-	listener       *net.Listener
-	connHandleFunc func(msg []byte) string
+// Session holds the state of a single client connection.
+type Session interface {
+	// ServeRequest executes a request read from the connection and writes the reply to it, in order with the
+	// data pushed by the session. pending is the number of bytes received after the request which haven't been
+	// processed yet.
+	ServeRequest(request data.Message, pending int)
+	// Closing reports whether the connection should be closed once the last reply has been written.
+	Closing() bool
+	// OutputBufferClass returns the client class whose output buffer limits apply to the session.
+	OutputBufferClass() string
+	// IdleExempt reports whether the session waits for data without sending commands, so that the idle
+	// timeout doesn't apply to it.
+	IdleExempt() bool
+	// Close releases the session once the connection has been closed.
+	Close()
 }
 
-func NewTcpServer(port string, handlerFunc func([]byte) string) (*TcpServer, error) {
-	listener, err := net.Listen("tcp4", fmt.Sprintf(":%s", port))
-	if err != nil {
+// SessionFactory creates the session for a newly accepted connection.
+type SessionFactory func(conn net.Conn) Session
+
+// Server accepts connections on one or more listeners and feeds all of them to the same session factory.
+type Server struct {
+	mu         sync.Mutex
+	listeners  []net.Listener
+	newSession SessionFactory
+
+	activeConns  atomic.Int64
+	maxClients   atomic.Int64
+	idleTimeout  atomic.Int64
+	keepAlive    atomic.Int64
+	outputLimits atomic.Pointer[map[string]config.OutputBufferLimit]
+}
+
+func NewServer(sessionFactory SessionFactory) *Server {
+	return &Server{
+		newSession: sessionFactory,
+	}
+}
+
+// ApplyConfig applies the connection limits from the configuration and keeps them up to date when it changes.
+func (srv *Server) ApplyConfig(cfg *config.Config) {
+	setMaxClients := func(string) { srv.maxClients.Store(cfg.GetInt("maxclients")) }
+	setIdleTimeout := func(string) { srv.idleTimeout.Store(int64(time.Duration(cfg.GetInt("timeout")) * time.Second)) }
+	setKeepAlive := func(string) { srv.keepAlive.Store(int64(time.Duration(cfg.GetInt("tcp-keepalive")) * time.Second)) }
+	setOutputLimits := func(value string) {
+		limits, err := config.ParseOutputBufferLimits(value)
+		if err != nil {
+			slog.Error("invalid output buffer limits", "error", err.Error())
+			return
+		}
+		srv.outputLimits.Store(&limits)
+	}
+
+	setMaxClients("")
+	setIdleTimeout("")
+	setKeepAlive("")
+	setOutputLimits(cfg.Get("client-output-buffer-limit"))
+
+	cfg.Watch("maxclients", setMaxClients)
+	cfg.Watch("timeout", setIdleTimeout)
+	cfg.Watch("tcp-keepalive", setKeepAlive)
+	cfg.Watch("client-output-buffer-limit", setOutputLimits)
+}
+
+// ActiveConnections returns the number of connections currently being served.
+func (srv *Server) ActiveConnections() int64 {
+	return srv.activeConns.Load()
+}
+
+// NewTcpServer creates a server listening for TCP connections on all IPv4 interfaces.
+func NewTcpServer(port string, sessionFactory SessionFactory) (*Server, error) {
+	srv := NewServer(sessionFactory)
+	if err := srv.ListenTcp([]string{"*"}, port); err != nil {
 		return nil, err
 	}
+	return srv, nil
+}
+
+// bindAddress resolves an entry of the bind configuration into a network and address for net.Listen.
+// "*" stands for all IPv4 interfaces and "::*" for all IPv6 interfaces.
+// A leading "-" marks the address as optional: failing to bind it is not an error.
+func bindAddress(bindAddr string, port string) (network string, address string, optional bool) {
+	optional = strings.HasPrefix(bindAddr, "-")
+	bindAddr = strings.TrimPrefix(bindAddr, "-")
+
+	switch {
+	case bindAddr == "*":
+		return "tcp4", net.JoinHostPort("0.0.0.0", port), optional
+	case bindAddr == "::*":
+		return "tcp6", net.JoinHostPort("::", port), optional
+	case strings.Contains(bindAddr, ":"):
+		return "tcp6", net.JoinHostPort(bindAddr, port), optional
+	default:
+		return "tcp4", net.JoinHostPort(bindAddr, port), optional
+	}
+}
+
+// listenAll binds every address of the bind configuration, wrapping the listeners when required.
+func (srv *Server) listenAll(bindAddrs []string, port string, wrap func(net.Listener) net.Listener) error {
+	listeners := []net.Listener{}
+	for _, bindAddr := range bindAddrs {
+		network, address, optional := bindAddress(bindAddr, port)
+		listener, err := net.Listen(network, address)
+		if err != nil {
+			if optional {
+				slog.Warn("skipping optional bind address", "address", address, "error", err.Error())
+				continue
+			}
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return err
+		}
+		listeners = append(listeners, wrap(listener))
+	}
+
+	if len(listeners) == 0 {
+		return fmt.Errorf("failed to bind any of the addresses %s on port %s", strings.Join(bindAddrs, " "), port)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.listeners = append(srv.listeners, listeners...)
+	return nil
+}
+
+// ListenTcp adds plain TCP listeners for the given bind addresses.
+func (srv *Server) ListenTcp(bindAddrs []string, port string) error {
+	return srv.listenAll(bindAddrs, port, func(l net.Listener) net.Listener { return l })
+}
+
+// ListenUnix adds a listener on a Unix domain socket.
+// A stale socket file left behind at the path is removed, and the permissions of the socket are set when perm is non-zero.
+func (srv *Server) ListenUnix(path string, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+
+	if perm != 0 {
+		if err := os.Chmod(path, perm); err != nil {
+			_ = listener.Close()
+			return err
+		}
+	}
 
-	return &TcpServer{
-		&listener,
-		handlerFunc,
-	}, nil
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.listeners = append(srv.listeners, listener)
+	return nil
 }
 
-func (ts *TcpServer) Serve() {
+// Addrs returns the addresses of all the listeners.
+func (srv *Server) Addrs() []net.Addr {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	addrs := make([]net.Addr, len(srv.listeners))
+	for idx, listener := range srv.listeners {
+		addrs[idx] = listener.Addr()
+	}
+	return addrs
+}
+
+// Serve accepts connections on all listeners until they are closed.
+func (srv *Server) Serve() {
+	srv.mu.Lock()
+	listeners := slices.Clone(srv.listeners)
+	srv.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, listener := range listeners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			srv.acceptLoop(listener)
+		}()
+	}
+	wg.Wait()
+}
+
+func (srv *Server) acceptLoop(listener net.Listener) {
 	for {
-		conn, err := (*ts.listener).Accept()
+		conn, err := listener.Accept()
 		if err != nil {
-			slog.Error("failed to accept connection", "error", err.Error())
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("failed to accept connection", "error", err.Error())
+			}
 			return
 		}
-		go ts.handleConnection(conn)
+		if !srv.reserveConn() {
+			slog.Warn("rejecting connection, max number of clients reached", "addr", conn.RemoteAddr().String())
+			_, _ = conn.Write([]byte(MAX_CLIENTS_REACHED))
+			_ = conn.Close()
+			continue
+		}
+
+		go srv.handleConnection(conn)
+	}
+}
+
+// reserveConn counts a new connection, unless the server already has maxclients connections. the check and the
+// count are a single step, since every listener accepts connections on its own goroutine.
+func (srv *Server) reserveConn() bool {
+	for {
+		active := srv.activeConns.Load()
+		if maxClients := srv.maxClients.Load(); maxClients > 0 && active >= maxClients {
+			return false
+		}
+		if srv.activeConns.CompareAndSwap(active, active+1) {
+			return true
+		}
 	}
 }
 
-func (ts *TcpServer) StopListen() {
-	if ts.listener != nil {
-		closeErr := (*ts.listener).Close()
-		if closeErr != nil {
+// enableKeepAlive turns on TCP keepalive for the connection, unwrapping TLS connections when needed.
+func enableKeepAlive(conn net.Conn, period time.Duration) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return
+	}
+
+	if err := tcpConn.SetKeepAlive(true); err != nil {
+		slog.Warn("failed to enable tcp keepalive", "error", err.Error())
+		return
+	}
+	if err := tcpConn.SetKeepAlivePeriod(period); err != nil {
+		slog.Warn("failed to set tcp keepalive period", "error", err.Error())
+	}
+}
+
+func (srv *Server) StopListen() {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	for _, listener := range srv.listeners {
+		closeErr := listener.Close()
+		if closeErr != nil && !errors.Is(closeErr, net.ErrClosed) {
 			slog.Error("failed to close listener", "error", closeErr.Error())
 		}
 	}
 }
 
-func (ts *TcpServer) handleConnection(c net.Conn) {
+func (srv *Server) handleConnection(rawConn net.Conn) {
+	if keepAlive := time.Duration(srv.keepAlive.Load()); keepAlive > 0 {
+		enableKeepAlive(rawConn, keepAlive)
+	}
+
+	var session Session
+	c := newBufferedConn(rawConn, func() config.OutputBufferLimit {
+		limits := srv.outputLimits.Load()
+		if limits == nil || session == nil {
+			return config.OutputBufferLimit{}
+		}
+		return (*limits)[session.OutputBufferClass()]
+	})
+	session = srv.newSession(c)
+
 	defer func() {
+		srv.activeConns.Add(-1)
+		session.Close()
 		err := c.Close()
-		if err != nil {
+		if err != nil && !errors.Is(err, net.ErrClosed) {
 			slog.Error("failed to close connection", "error", err.Error())
 		}
 	}()
 
-	reachedEnd := false
+	reader := bufio.NewReaderSize(c, READ_BUF_SIZE)
 	for {
-		buf := make([]byte, 0, LARGE_BUF_SIZE)
-		tmp := make([]byte, READ_BUF_SIZE)
-		for {
-			numBytes, err := c.Read(tmp)
-			if err != nil {
-				if err != io.EOF {
-					slog.Error("error while processing request", "error", err.Error())
-				}
-				reachedEnd = true
-				break
-			}
-			buf = append(buf, tmp[:numBytes]...)
-			if numBytes < READ_BUF_SIZE {
-				result := ts.connHandleFunc(buf)
-				_, err := c.Write([]byte(result))
-				if err != nil {
-					slog.Error("failed to respond to client", "error", err.Error())
-				}
-				break
+		if idleTimeout := time.Duration(srv.idleTimeout.Load()); idleTimeout > 0 && !session.IdleExempt() {
+			_ = c.SetReadDeadline(time.Now().Add(idleTimeout))
+		} else {
+			_ = c.SetReadDeadline(time.Time{})
+		}
+
+		// requests are framed from the stream, so that a request received in several parts is only served
+		// once it's complete and the data of a request is never mistaken for the start of the next one
+		request, err := data.ReadRequest(reader)
+		if err != nil {
+			var protocolErr data.ProtocolError
+			var netErr net.Error
+			if errors.As(err, &protocolErr) {
+				// the rest of the input cannot be framed once a request fails to decode
+				_, _ = c.Write([]byte(data.Error{ErrMsg: "ERR " + err.Error()}.ToDataString()))
+			} else if errors.As(err, &netErr) && netErr.Timeout() {
+				slog.Debug("closing idle connection", "addr", c.RemoteAddr().String())
+			} else if err != io.EOF && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, net.ErrClosed) {
+				slog.Error("error while processing request", "error", err.Error())
 			}
+			return
 		}
-		if reachedEnd {
-			break
+
+		session.ServeRequest(request, reader.Buffered())
+		if session.Closing() {
+			return
 		}
 	}
 }
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+)
+
+const (
+	TLS_AUTH_CLIENTS_YES      = "yes"
+	TLS_AUTH_CLIENTS_NO       = "no"
+	TLS_AUTH_CLIENTS_OPTIONAL = "optional"
+)
+
+// TlsCertificates holds the certificates used by TLS listeners.
+// The certificates are read from disk and can be reloaded at runtime without restarting the listeners.
+type TlsCertificates struct {
+	certFile    string
+	keyFile     string
+	caCertFile  string
+	authClients string
+	current     atomic.Pointer[tls.Config]
+}
+
+func NewTlsCertificates(certFile string, keyFile string, caCertFile string, authClients string) (*TlsCertificates, error) {
+	switch authClients {
+	case TLS_AUTH_CLIENTS_YES, TLS_AUTH_CLIENTS_OPTIONAL:
+		if caCertFile == "" {
+			return nil, fmt.Errorf("a CA certificate is required to authenticate clients")
+		}
+	case TLS_AUTH_CLIENTS_NO:
+	default:
+		return nil, fmt.Errorf("invalid value for tls-auth-clients: %s", authClients)
+	}
+
+	tc := &TlsCertificates{
+		certFile:    certFile,
+		keyFile:     keyFile,
+		caCertFile:  caCertFile,
+		authClients: authClients,
+	}
+
+	if err := tc.Reload(); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// Reload reads the certificates from disk again.
+// If any of them fail to load, the previously loaded certificates remain in use.
+func (tc *TlsCertificates) Reload() error {
+	cert, err := tls.LoadX509KeyPair(tc.certFile, tc.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate and key: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   tls.NoClientCert,
+	}
+
+	if tc.caCertFile != "" {
+		caCerts, err := os.ReadFile(tc.caCertFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCerts) {
+			return fmt.Errorf("failed to parse CA certificate %s", tc.caCertFile)
+		}
+		tlsConfig.ClientCAs = pool
+	}
+
+	switch tc.authClients {
+	case TLS_AUTH_CLIENTS_YES:
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	case TLS_AUTH_CLIENTS_OPTIONAL:
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	tc.current.Store(tlsConfig)
+	return nil
+}
+
+// Config returns a TLS configuration that always uses the most recently loaded certificates.
+func (tc *TlsCertificates) Config() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return tc.current.Load(), nil
+		},
+	}
+}
+
+// NewTlsServer creates a server listening for TLS connections on all IPv4 interfaces.
+func NewTlsServer(port string, certs *TlsCertificates, sessionFactory SessionFactory) (*Server, error) {
+	srv := NewServer(sessionFactory)
+	if err := srv.ListenTls([]string{"*"}, port, certs); err != nil {
+		return nil, err
+	}
+	return srv, nil
+}
+
+// ListenTls adds TLS listeners for the given bind addresses.
+func (srv *Server) ListenTls(bindAddrs []string, port string, certs *TlsCertificates) error {
+	tlsConfig := certs.Config()
+	return srv.listenAll(bindAddrs, port, func(l net.Listener) net.Listener {
+		return tls.NewListener(l, tlsConfig)
+	})
+}
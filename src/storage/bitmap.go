@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// bits are numbered from the most significant bit of the first byte, like redis does.
+
+const (
+	BITOP_AND = "AND"
+	BITOP_OR  = "OR"
+	BITOP_XOR = "XOR"
+	BITOP_NOT = "NOT"
+)
+
+const (
+	BITFIELD_GET = iota
+	BITFIELD_SET
+	BITFIELD_INCRBY
+)
+
+const (
+	BITFIELD_OVERFLOW_WRAP = iota
+	BITFIELD_OVERFLOW_SAT
+	BITFIELD_OVERFLOW_FAIL
+)
+
+// BitRange is the optional range given to BITCOUNT and BITPOS.
+// Start and End may be negative to count from the end, and are bit indexes instead of byte indexes when IsBit is set.
+type BitRange struct {
+	Start    int64
+	End      int64
+	HasStart bool
+	HasEnd   bool
+	IsBit    bool
+}
+
+// BitFieldOp is a single GET, SET or INCRBY of a BITFIELD command.
+// Value holds the value to set for SET and the increment for INCRBY.
+type BitFieldOp struct {
+	Kind     int
+	Signed   bool
+	Bits     int
+	Offset   int64
+	Value    int64
+	Overflow int
+}
+
+// BitFieldResult is the reply to a single BITFIELD operation. Failed is set when the operation
+// overflowed with the FAIL overflow mode and was not applied.
+type BitFieldResult struct {
+	Value  int64
+	Failed bool
+}
+
+func getBit(contents []byte, offset int64) int64 {
+	byteIdx := offset >> 3
+	if byteIdx >= int64(len(contents)) {
+		return 0
+	}
+	return int64(contents[byteIdx]>>(7-uint(offset&7))) & 1
+}
+
+func setBit(contents []byte, offset int64, value bool) {
+	mask := byte(1) << (7 - uint(offset&7))
+	if value {
+		contents[offset>>3] |= mask
+	} else {
+		contents[offset>>3] &^= mask
+	}
+}
+
+// padTo zero-extends the contents so that they hold at least size bytes.
+func padTo(contents []byte, size int64) []byte {
+	if size > int64(len(contents)) {
+		contents = append(contents, make([]byte, size-int64(len(contents)))...)
+	}
+	return contents
+}
+
+// resolve applies the range to a string of the given length and returns it as inclusive bit indexes.
+// ok is false when the range is empty.
+func (rng BitRange) resolve(length int64) (start int64, end int64, ok bool) {
+	total := length
+	if rng.IsBit {
+		total = length * 8
+	}
+
+	start, end = 0, total-1
+	if rng.HasStart {
+		start = rng.Start
+	}
+	if rng.HasEnd {
+		end = rng.End
+	}
+
+	if start < 0 {
+		start += total
+	}
+	if end < 0 {
+		end += total
+	}
+	start = max(start, 0)
+	end = max(end, 0)
+	end = min(end, total-1)
+	if total == 0 || start > end {
+		return 0, 0, false
+	}
+
+	if !rng.IsBit {
+		start, end = start*8, end*8+7
+	}
+	return start, end, true
+}
+
+// bitCount counts the set bits between the inclusive bit indexes.
+func bitCount(contents []byte, start int64, end int64) int64 {
+	count := int64(0)
+	for byteIdx := start >> 3; byteIdx <= end>>3; byteIdx++ {
+		value := contents[byteIdx]
+		if byteIdx == start>>3 {
+			value &= 0xff >> uint(start&7)
+		}
+		if byteIdx == end>>3 {
+			value &= 0xff << (7 - uint(end&7))
+		}
+		count += int64(bits.OnesCount8(value))
+	}
+	return count
+}
+
+// bitPos returns the index of the first bit with the given value between the inclusive bit indexes, or -1.
+func bitPos(contents []byte, bit bool, start int64, end int64) int64 {
+	skip := byte(0)
+	if !bit {
+		skip = 0xff
+	}
+
+	for offset := start; offset <= end; {
+		// whole bytes without the bit are skipped at once
+		if offset&7 == 0 && offset+7 <= end && contents[offset>>3] == skip {
+			offset += 8
+			continue
+		}
+		if (getBit(contents, offset) == 1) == bit {
+			return offset
+		}
+		offset++
+	}
+	return -1
+}
+
+// bitOp combines the values byte by byte, treating the shorter ones as if they were padded with zero bytes.
+func bitOp(op string, values []string) []byte {
+	maxLen := 0
+	for _, value := range values {
+		maxLen = max(maxLen, len(value))
+	}
+
+	result := make([]byte, maxLen)
+	for idx := range result {
+		var combined byte
+		for valueIdx, value := range values {
+			var current byte
+			if idx < len(value) {
+				current = value[idx]
+			}
+
+			switch {
+			case op == BITOP_NOT:
+				combined = ^current
+			case valueIdx == 0:
+				combined = current
+			case op == BITOP_AND:
+				combined &= current
+			case op == BITOP_OR:
+				combined |= current
+			case op == BITOP_XOR:
+				combined ^= current
+			}
+		}
+		result[idx] = combined
+	}
+	return result
+}
+
+// getField reads the integer of the given width stored at the bit offset.
+func getField(contents []byte, offset int64, width int, signed bool) int64 {
+	var value uint64
+	for idx := range int64(width) {
+		value = value<<1 | uint64(getBit(contents, offset+idx))
+	}
+
+	if signed && width < 64 && value&(1<<(width-1)) != 0 {
+		// sign extension
+		value |= ^uint64(0) << width
+	}
+	return int64(value)
+}
+
+// setField writes the lowest bits of the value at the bit offset. contents must be long enough to hold them.
+func setField(contents []byte, offset int64, width int, value int64) {
+	for idx := range int64(width) {
+		setBit(contents, offset+idx, uint64(value)>>(int64(width)-1-idx)&1 == 1)
+	}
+}
+
+// fieldLimits returns the smallest and largest values of an integer of the given width.
+func fieldLimits(width int, signed bool) (*big.Int, *big.Int) {
+	if signed {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(width-1))
+		return new(big.Int).Neg(limit), limit.Sub(limit, big.NewInt(1))
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(width))
+	return big.NewInt(0), limit.Sub(limit, big.NewInt(1))
+}
+
+// fieldAdd adds the increment to the value of a field, handling overflows with the given mode.
+// ok is false when the result overflows with the FAIL mode.
+func fieldAdd(value int64, incr int64, width int, signed bool, overflow int) (result int64, ok bool) {
+	sum := new(big.Int).Add(big.NewInt(value), big.NewInt(incr))
+	lower, upper := fieldLimits(width, signed)
+	if sum.Cmp(lower) >= 0 && sum.Cmp(upper) <= 0 {
+		return sum.Int64(), true
+	}
+
+	switch overflow {
+	case BITFIELD_OVERFLOW_SAT:
+		if sum.Sign() < 0 {
+			return lower.Int64(), true
+		}
+		return upper.Int64(), true
+	case BITFIELD_OVERFLOW_FAIL:
+		return 0, false
+	default:
+		modulus := new(big.Int).Lsh(big.NewInt(1), uint(width))
+		sum.Mod(sum, modulus)
+		if sum.Cmp(upper) > 0 {
+			sum.Sub(sum, modulus)
+		}
+		return sum.Int64(), true
+	}
+}
+
+// bitField runs the operations one after the other on the contents.
+func bitField(contents []byte, ops []BitFieldOp) ([]byte, []BitFieldResult) {
+	results := make([]BitFieldResult, len(ops))
+	for idx, op := range ops {
+		if op.Kind != BITFIELD_GET {
+			contents = padTo(contents, (op.Offset+int64(op.Bits)+7)/8)
+		}
+		current := getField(contents, op.Offset, op.Bits, op.Signed)
+
+		switch op.Kind {
+		case BITFIELD_GET:
+			results[idx] = BitFieldResult{Value: current}
+		case BITFIELD_SET:
+			value, ok := fieldAdd(op.Value, 0, op.Bits, op.Signed, op.Overflow)
+			if !ok {
+				results[idx] = BitFieldResult{Failed: true}
+				continue
+			}
+			setField(contents, op.Offset, op.Bits, value)
+			results[idx] = BitFieldResult{Value: current}
+		case BITFIELD_INCRBY:
+			value, ok := fieldAdd(current, op.Value, op.Bits, op.Signed, op.Overflow)
+			if !ok {
+				results[idx] = BitFieldResult{Failed: true}
+				continue
+			}
+			setField(contents, op.Offset, op.Bits, value)
+			results[idx] = BitFieldResult{Value: value}
+		}
+	}
+	return contents, results
+}
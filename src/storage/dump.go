@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc64"
+	"time"
+)
+
+// DUMP serialises a single value in the format of snapshots. like redis, the expiry is not part of the payload
+// but given to RESTORE. a version and a CRC64 of everything before it close the payload, so that RESTORE rejects
+// payloads it can't read, including the ones of redis.
+const DUMP_VERSION = 3
+
+var (
+	ErrInvalidDump = errors.New("DUMP payload version or checksum are wrong")
+	ErrBusyKey     = errors.New("BUSYKEY Target key name already exists.")
+)
+
+// Dump serialises the value stored at key, and returns it along with the expiry of the key, which is zero
+// when the key doesn't expire.
+func (mse *MapStorageEngine) Dump(key string) (bool, []byte, time.Time, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	dc, ok := mse.getLocked(key)
+	if !ok {
+		return false, nil, time.Time{}, nil
+	}
+
+	e := &encoder{}
+	encodeValue(e, dc)
+	var expiresAt time.Time
+	if dc.Expires {
+		expiresAt = dc.ExpiresAt
+	}
+	return true, sealPayload(e), expiresAt, nil
+}
+
+// sealPayload closes a payload with the version and the checksum.
+func sealPayload(e *encoder) []byte {
+	e.byte(DUMP_VERSION)
+	return binary.LittleEndian.AppendUint64(e.buf, crc64.Checksum(e.buf, crcTable))
+}
+
+// openPayload checks the version and the checksum of a payload, returning the decoder of its contents.
+func openPayload(payload []byte) (*decoder, error) {
+	if len(payload) < 9 || payload[len(payload)-9] != DUMP_VERSION {
+		return nil, ErrInvalidDump
+	}
+	body := payload[:len(payload)-8]
+	if crc64.Checksum(body, crcTable) != binary.LittleEndian.Uint64(payload[len(body):]) {
+		return nil, ErrInvalidDump
+	}
+	return &decoder{buf: body[:len(body)-1]}, nil
+}
+
+// Restore stores the value of a payload created by Dump at key, which must not exist unless replace is set.
+// the key expires at expiresAt, or never when it is zero. a value that has already expired is not stored,
+// although it still replaces the existing one.
+func (mse *MapStorageEngine) Restore(key string, payload []byte, replace bool, expiresAt time.Time) error {
+	d, err := openPayload(payload)
+	if err != nil {
+		return err
+	}
+	dc := decodeValue(d)
+	if d.err != nil || len(d.buf) > 0 {
+		return ErrInvalidDump
+	}
+	dc.Expires, dc.ExpiresAt = !expiresAt.IsZero(), expiresAt
+
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	if _, exists := mse.getLocked(key); exists && !replace {
+		return ErrBusyKey
+	}
+	if dc.Expires && !time.Now().Before(dc.ExpiresAt) {
+		mse.deleteLocked(key, EVENT_CLASS_GENERIC, "del")
+		return nil
+	}
+	mse.putLocked(key, dc)
+	mse.notifyLocked(EVENT_CLASS_GENERIC, "restore", key)
+	mse.signalWaitersLocked(key)
+	return nil
+}
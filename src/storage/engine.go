@@ -1,20 +1,92 @@
 package storage
 
 import (
+	"errors"
 	"time"
 )
 
+var (
+	ErrNotInteger    = errors.New("value is not an integer")
+	ErrNotFloat      = errors.New("value is not a valid float")
+	ErrOverflow      = errors.New("increment or decrement would overflow")
+	ErrNaNOrInfinity = errors.New("increment would produce NaN or Infinity")
+	ErrWrongType     = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+)
+
 type StorageEngine interface {
 	Set(key string, value string, expires bool, expiresAtTimeStampMillis int64) error
 	Get(key string) (bool, string, error)
 	Exists(keys []string) (int, error)
 	Delete(keys []string) (int, error)
+	Keys() []string
 	AtomicDelta(key string, delta int64) (int64, error)
+	AtomicFloatDelta(key string, delta float64) (string, error)
 	ListPush(key string, values []string, isPrepend bool) (int64, error)
+	Append(key string, value string) (int64, error)
+	SetRange(key string, offset int64, value string) (int64, error)
+	GetDel(key string) (bool, string, error)
+	GetEx(key string, updateExpiry bool, expires bool, expiresAtTimeStampMillis int64) (bool, string, error)
+	GetSet(key string, value string) (bool, string, error)
+	SetNX(key string, value string, expires bool, expiresAtTimeStampMillis int64) (bool, error)
+	MGet(keys []string) ([]bool, []string, error)
+	MSet(keys []string, values []string) error
+	MSetNX(keys []string, values []string) (bool, error)
+	SetBit(key string, offset int64, value bool) (int64, error)
+	GetBit(key string, offset int64) (int64, error)
+	BitCount(key string, rng BitRange) (int64, error)
+	BitPos(key string, bit bool, rng BitRange) (int64, error)
+	BitOp(op string, destKey string, keys []string) (int64, error)
+	BitField(key string, ops []BitFieldOp) ([]BitFieldResult, error)
+	PFAdd(key string, elements []string) (bool, error)
+	PFCount(keys []string) (int64, error)
+	PFMerge(destKey string, keys []string) error
+	GeoAdd(key string, members []string, scores []uint64, onlyIfNotExists bool, onlyIfExists bool, countChanged bool) (int64, error)
+	GeoScores(key string, members []string) ([]bool, []uint64, error)
+	GeoSearch(key string, query GeoQuery) ([]GeoMatch, error)
+	GeoSearchStore(destKey string, key string, query GeoQuery, distUnit float64) (int64, error)
+	XAdd(key string, id StreamAddID, fields []string, noMkStream bool, trim StreamTrim) (StreamID, bool, error)
+	XLen(key string) (int64, error)
+	XRange(key string, start StreamID, end StreamID, count int, reverse bool) ([]StreamEntry, error)
+	XTrim(key string, trim StreamTrim) (int64, error)
+	XDel(key string, ids []StreamID) (int64, error)
+	XRead(keys []string, after []StreamID, count int) ([][]StreamEntry, error)
+	StreamLastIDs(keys []string) ([]StreamID, error)
+	WatchStreams(keys []string) (<-chan struct{}, func())
+	XGroupCreate(key string, group string, id StreamID, useLastID bool, mkStream bool, entriesRead int64) error
+	XGroupSetID(key string, group string, id StreamID, useLastID bool, entriesRead int64) error
+	XGroupDestroy(key string, group string) (bool, error)
+	XGroupCreateConsumer(key string, group string, consumer string) (bool, error)
+	XGroupDelConsumer(key string, group string, consumer string) (int64, error)
+	XReadGroup(keys []string, group string, consumer string, after []StreamID, newOnly []bool, count int, noAck bool) ([][]StreamEntry, error)
+	XAck(key string, group string, ids []StreamID) (int64, error)
+	XPending(key string, group string) (StreamPendingSummary, error)
+	XPendingRange(key string, group string, query StreamPendingQuery) ([]StreamPendingEntry, error)
+	XClaim(key string, group string, consumer string, minIdle time.Duration, ids []StreamID, opts StreamClaimOptions) ([]StreamEntry, error)
+	XAutoClaim(key string, group string, consumer string, minIdle time.Duration, start StreamID, count int, justID bool) (StreamID, []StreamEntry, []StreamID, error)
+	XInfoStream(key string, full bool, count int) (StreamInfo, error)
+	XInfoGroups(key string) ([]StreamGroupInfo, error)
+	XInfoConsumers(key string, group string) ([]StreamConsumerInfo, error)
+	Snapshot() []byte
+	LoadSnapshot(snapshot []byte) error
+	Dump(key string) (bool, []byte, time.Time, error)
+	Restore(key string, payload []byte, replace bool, expiresAt time.Time) error
+	Libraries() map[string]string
+	SetLibraries(libraries map[string]string)
+	SetNotifier(notify func(event KeyspaceEvent))
+	DeleteExpired(sampleSize int) (int, int)
 }
 
+// DataContainer holds the value of a key. Strings are kept in Data, while the other types of values
+// are kept in their own field.
 type DataContainer struct {
 	Data      string
+	Geo       *GeoIndex
+	Stream    *Stream
 	Expires   bool
 	ExpiresAt time.Time
 }
+
+// IsString reports whether the value is a string.
+func (dc DataContainer) IsString() bool {
+	return dc.Geo == nil && dc.Stream == nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"maps"
+	"slices"
+)
+
+// the function libraries are kept along with the dataset so that they are part of the snapshots. the storage
+// engine only holds their code by the name of the library, which the handler compiles into functions.
+
+// Libraries returns the code of the function libraries by their name.
+func (mse *MapStorageEngine) Libraries() map[string]string {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+	return maps.Clone(mse.libraries)
+}
+
+// SetLibraries replaces the function libraries.
+func (mse *MapStorageEngine) SetLibraries(libraries map[string]string) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+	mse.libraries = maps.Clone(libraries)
+}
+
+// DumpLibraries serialises function libraries in a payload that is checked like the ones of DUMP.
+func DumpLibraries(libraries map[string]string) []byte {
+	e := &encoder{}
+	encodeLibraries(e, libraries)
+	return sealPayload(e)
+}
+
+// RestoreLibraries reads the function libraries of a payload created by DumpLibraries.
+func RestoreLibraries(payload []byte) (map[string]string, error) {
+	d, err := openPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	libraries := decodeLibraries(d)
+	if d.err != nil || len(d.buf) > 0 {
+		return nil, ErrInvalidDump
+	}
+	return libraries, nil
+}
+
+func encodeLibraries(e *encoder, libraries map[string]string) {
+	e.uvarint(uint64(len(libraries)))
+	for _, name := range slices.Sorted(maps.Keys(libraries)) {
+		e.string(name)
+		e.string(libraries[name])
+	}
+}
+
+func decodeLibraries(d *decoder) map[string]string {
+	libraries := make(map[string]string)
+	for range d.count() {
+		name := d.string()
+		libraries[name] = d.string()
+	}
+	return libraries
+}
@@ -0,0 +1,339 @@
+package storage
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// geo indexes keep their members ordered by a 52 bit geohash score, like the sorted sets used by redis,
+// so that searches only look at the members in the geohash boxes around the searched area. like redis, the
+// scores are floats, which hold the geohashes exactly and can hold the distances stored by GEOSEARCHSTORE.
+
+const (
+	GEO_STEP_MAX = 26
+	GEO_LAT_MIN  = -85.05112878
+	GEO_LAT_MAX  = 85.05112878
+	GEO_LONG_MIN = -180.0
+	GEO_LONG_MAX = 180.0
+
+	// the earth radius and mercator projection limit used by redis for distances
+	GEO_EARTH_RADIUS = 6372797.560856
+	GEO_MERCATOR_MAX = 20037726.37
+)
+
+const (
+	GEO_SORT_NONE = iota
+	GEO_SORT_ASC
+	GEO_SORT_DESC
+)
+
+const GEO_ALPHABET = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+var ErrGeoMemberNotFound = errors.New("could not decode requested zset member")
+
+type geoMember struct {
+	member string
+	score  float64
+}
+
+// GeoIndex holds the members of a geo index along with their scores.
+type GeoIndex struct {
+	scores  map[string]float64
+	ordered []geoMember
+}
+
+func newGeoIndex() *GeoIndex {
+	return &GeoIndex{scores: make(map[string]float64)}
+}
+
+func compareGeoMembers(a geoMember, b geoMember) int {
+	if a.score != b.score {
+		return cmp.Compare(a.score, b.score)
+	}
+	return strings.Compare(a.member, b.member)
+}
+
+// Len returns the number of members of the index.
+func (gi *GeoIndex) Len() int {
+	return len(gi.ordered)
+}
+
+// set adds the member or moves it to the new score, and reports whether it was added or its score changed.
+func (gi *GeoIndex) set(member string, score float64) (added bool, changed bool) {
+	current, exists := gi.scores[member]
+	if exists {
+		if current == score {
+			return false, false
+		}
+		idx, _ := slices.BinarySearchFunc(gi.ordered, geoMember{member, current}, compareGeoMembers)
+		gi.ordered = slices.Delete(gi.ordered, idx, idx+1)
+	}
+
+	entry := geoMember{member, score}
+	idx, _ := slices.BinarySearchFunc(gi.ordered, entry, compareGeoMembers)
+	gi.ordered = slices.Insert(gi.ordered, idx, entry)
+	gi.scores[member] = score
+	return !exists, exists
+}
+
+// scoreRange calls fn for every member with a score in [min, max) in order, until fn returns false.
+func (gi *GeoIndex) scoreRange(min uint64, max uint64, fn func(geoMember) bool) bool {
+	start := sort.Search(len(gi.ordered), func(idx int) bool { return gi.ordered[idx].score >= float64(min) })
+	for _, entry := range gi.ordered[start:] {
+		if entry.score >= float64(max) {
+			break
+		}
+		if !fn(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// interleave spreads the bits of lat over the even bits of the result and the bits of long over the odd bits.
+func interleave(lat uint32, long uint32) uint64 {
+	var result uint64
+	for bit := range 32 {
+		result |= uint64(lat>>bit&1) << (2 * bit)
+		result |= uint64(long>>bit&1) << (2*bit + 1)
+	}
+	return result
+}
+
+func deinterleave(hash uint64) (lat uint32, long uint32) {
+	for bit := range 32 {
+		lat |= uint32(hash>>(2*bit)&1) << bit
+		long |= uint32(hash>>(2*bit+1)&1) << bit
+	}
+	return lat, long
+}
+
+// ValidGeoCoordinates reports whether the coordinates can be indexed. Latitudes are limited to the
+// range of the web mercator projection.
+func ValidGeoCoordinates(long float64, lat float64) bool {
+	return long >= GEO_LONG_MIN && long <= GEO_LONG_MAX && lat >= GEO_LAT_MIN && lat <= GEO_LAT_MAX
+}
+
+// geoCells returns the cells containing the coordinates in a grid of 2^step cells by 2^step cells.
+func geoCells(long float64, lat float64, step int, latMin float64, latMax float64) (uint32, uint32) {
+	cells := float64(uint64(1) << step)
+	latCell := math.Min((lat-latMin)/(latMax-latMin)*cells, cells-1)
+	longCell := math.Min((long-GEO_LONG_MIN)/(GEO_LONG_MAX-GEO_LONG_MIN)*cells, cells-1)
+	return uint32(latCell), uint32(longCell)
+}
+
+// GeoEncode returns the 52 bit geohash score of the coordinates.
+func GeoEncode(long float64, lat float64) uint64 {
+	latCell, longCell := geoCells(long, lat, GEO_STEP_MAX, GEO_LAT_MIN, GEO_LAT_MAX)
+	return interleave(latCell, longCell)
+}
+
+// GeoDecode returns the coordinates at the center of the area of a geohash score.
+func GeoDecode(score uint64) (long float64, lat float64) {
+	latCell, longCell := deinterleave(score)
+	cells := float64(uint64(1) << GEO_STEP_MAX)
+
+	latMin := GEO_LAT_MIN + float64(latCell)/cells*(GEO_LAT_MAX-GEO_LAT_MIN)
+	latMax := GEO_LAT_MIN + float64(latCell+1)/cells*(GEO_LAT_MAX-GEO_LAT_MIN)
+	longMin := GEO_LONG_MIN + float64(longCell)/cells*(GEO_LONG_MAX-GEO_LONG_MIN)
+	longMax := GEO_LONG_MIN + float64(longCell+1)/cells*(GEO_LONG_MAX-GEO_LONG_MIN)
+
+	long = math.Max(GEO_LONG_MIN, math.Min(GEO_LONG_MAX, (longMin+longMax)/2))
+	lat = math.Max(GEO_LAT_MIN, math.Min(GEO_LAT_MAX, (latMin+latMax)/2))
+	return long, lat
+}
+
+// GeoHashString returns the standard 11 character geohash of a score. Unlike the scores, standard geohashes
+// cover latitudes from -90 to 90, so the position is encoded again.
+func GeoHashString(score uint64) string {
+	long, lat := GeoDecode(score)
+	latCell, longCell := geoCells(long, lat, GEO_STEP_MAX, -90, 90)
+	hash := interleave(latCell, longCell)
+
+	var sb strings.Builder
+	for idx := range 11 {
+		// the 52 bits of the hash only fill 10 characters and a half
+		charIdx := 0
+		if idx < 10 {
+			charIdx = int(hash>>(52-(idx+1)*5)) & 0x1f
+		}
+		sb.WriteByte(GEO_ALPHABET[charIdx])
+	}
+	return sb.String()
+}
+
+func degRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func radDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// GeoDistance returns the distance in meters between two points using the haversine formula.
+func GeoDistance(long1 float64, lat1 float64, long2 float64, lat2 float64) float64 {
+	lat1r, lat2r := degRad(lat1), degRad(lat2)
+	v := math.Sin(degRad(long2-long1) / 2)
+	u := math.Sin((lat2r - lat1r) / 2)
+	a := u*u + math.Cos(lat1r)*math.Cos(lat2r)*v*v
+	return 2 * GEO_EARTH_RADIUS * math.Asin(math.Sqrt(a))
+}
+
+// GeoQuery describes a GEOSEARCH. The search starts from Member when FromMember is set, or from Long and Lat
+// otherwise, and covers Radius meters or, when ByBox is set, a box of Width by Height meters.
+// A positive Count limits the results to the nearest matches, or to the first ones found when Any is set.
+type GeoQuery struct {
+	FromMember bool
+	Member     string
+	Long       float64
+	Lat        float64
+
+	ByBox  bool
+	Radius float64
+	Width  float64
+	Height float64
+
+	Sort  int
+	Count int
+	Any   bool
+}
+
+// GeoMatch is a member found by a GEOSEARCH along with its distance in meters to the center of the search.
+type GeoMatch struct {
+	Member   string
+	Score    uint64
+	Distance float64
+}
+
+// contains returns the distance from the center of the search to the point, if the point is within the search area.
+func (query GeoQuery) contains(long float64, lat float64) (float64, bool) {
+	if !query.ByBox {
+		distance := GeoDistance(query.Long, query.Lat, long, lat)
+		return distance, distance <= query.Radius
+	}
+
+	if GEO_EARTH_RADIUS*math.Abs(degRad(lat)-degRad(query.Lat)) > query.Height/2 {
+		return 0, false
+	}
+	if GeoDistance(query.Long, lat, long, lat) > query.Width/2 {
+		return 0, false
+	}
+	return GeoDistance(query.Long, query.Lat, long, lat), true
+}
+
+// searchRadius returns the radius of the circle enclosing the search area.
+func (query GeoQuery) searchRadius() float64 {
+	if query.ByBox {
+		return math.Hypot(query.Width/2, query.Height/2)
+	}
+	return query.Radius
+}
+
+// estimateStep returns the precision of the geohash boxes that are about as large as the search area.
+func estimateStep(radius float64, lat float64) int {
+	if radius == 0 {
+		return GEO_STEP_MAX
+	}
+
+	step := 1
+	for radius < GEO_MERCATOR_MAX {
+		radius *= 2
+		step++
+	}
+	step -= 2
+
+	// the boxes get narrower towards the poles
+	if lat > 66 || lat < -66 {
+		step--
+		if lat > 80 || lat < -80 {
+			step--
+		}
+	}
+	return max(1, min(step, GEO_STEP_MAX))
+}
+
+// covers reports whether the box containing the center at the given step, together with its neighbours,
+// covers the whole search area.
+func (query GeoQuery) covers(step int) bool {
+	cells := float64(uint64(1) << step)
+	latSize := (GEO_LAT_MAX - GEO_LAT_MIN) / cells
+	longSize := (GEO_LONG_MAX - GEO_LONG_MIN) / cells
+	latCell, longCell := geoCells(query.Long, query.Lat, step, GEO_LAT_MIN, GEO_LAT_MAX)
+
+	radius := query.searchRadius()
+	latDelta := radDeg(radius / GEO_EARTH_RADIUS)
+	// the longitude delta is the largest at the edge of the area that is the furthest from the equator
+	farthestLat := math.Min(math.Abs(query.Lat)+latDelta, 89.999)
+	longDelta := radDeg(radius / GEO_EARTH_RADIUS / math.Cos(degRad(farthestLat)))
+
+	latMin := GEO_LAT_MIN + float64(latCell)*latSize
+	longMin := GEO_LONG_MIN + float64(longCell)*longSize
+	latCovered := (query.Lat-latDelta >= latMin-latSize || latCell == 0) &&
+		(query.Lat+latDelta <= latMin+2*latSize || latCell == uint32(cells)-1)
+	longCovered := query.Long-longDelta >= longMin-longSize && query.Long+longDelta <= longMin+2*longSize
+	return latCovered && (longCovered || cells <= 3)
+}
+
+// scoreRanges returns the score ranges of the box containing the center of the search and its neighbours.
+func (query GeoQuery) scoreRanges() [][2]uint64 {
+	step := estimateStep(query.searchRadius(), query.Lat)
+	for step > 1 && !query.covers(step) {
+		step--
+	}
+
+	cells := int64(1) << step
+	latCell, longCell := geoCells(query.Long, query.Lat, step, GEO_LAT_MIN, GEO_LAT_MAX)
+	shift := uint(2 * (GEO_STEP_MAX - step))
+
+	ranges := [][2]uint64{}
+	for latOffset := int64(-1); latOffset <= 1; latOffset++ {
+		lat := int64(latCell) + latOffset
+		if lat < 0 || lat >= cells {
+			continue
+		}
+		for longOffset := int64(-1); longOffset <= 1; longOffset++ {
+			// longitudes wrap around the antimeridian
+			long := (int64(longCell) + longOffset + cells) % cells
+			start := interleave(uint32(lat), uint32(long)) << shift
+			scoreRange := [2]uint64{start, start + 1<<shift}
+			if !slices.Contains(ranges, scoreRange) {
+				ranges = append(ranges, scoreRange)
+			}
+		}
+	}
+	return ranges
+}
+
+// search returns the members within the search area. The center must already be resolved.
+func (gi *GeoIndex) search(query GeoQuery) []GeoMatch {
+	matches := []GeoMatch{}
+	for _, scoreRange := range query.scoreRanges() {
+		done := !gi.scoreRange(scoreRange[0], scoreRange[1], func(entry geoMember) bool {
+			long, lat := GeoDecode(uint64(entry.score))
+			distance, ok := query.contains(long, lat)
+			if ok {
+				matches = append(matches, GeoMatch{Member: entry.member, Score: uint64(entry.score), Distance: distance})
+			}
+			return !query.Any || query.Count <= 0 || len(matches) < query.Count
+		})
+		if done {
+			break
+		}
+	}
+
+	switch query.Sort {
+	case GEO_SORT_ASC:
+		slices.SortStableFunc(matches, func(a, b GeoMatch) int { return cmp.Compare(a.Distance, b.Distance) })
+	case GEO_SORT_DESC:
+		slices.SortStableFunc(matches, func(a, b GeoMatch) int { return cmp.Compare(b.Distance, a.Distance) })
+	}
+
+	if query.Count > 0 && len(matches) > query.Count {
+		matches = matches[:query.Count]
+	}
+	return matches
+}
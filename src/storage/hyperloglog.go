@@ -0,0 +1,328 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// hyperloglogs are stored as strings using the same layout as redis, so that they survive GET/SET round trips
+// and can be exchanged with redis: a 16 byte header ("HYLL", the encoding, 3 unused bytes and the cached
+// cardinality) followed by the registers in either the dense or the sparse encoding.
+
+const (
+	HLL_P         = 14
+	HLL_Q         = 64 - HLL_P
+	HLL_REGISTERS = 1 << HLL_P
+	HLL_P_MASK    = HLL_REGISTERS - 1
+	HLL_BITS      = 6
+	HLL_REG_MAX   = 1<<HLL_BITS - 1
+	HLL_HDR_SIZE  = 16
+	HLL_DENSE_LEN = HLL_HDR_SIZE + (HLL_REGISTERS*HLL_BITS+7)/8
+
+	HLL_DENSE  = 0
+	HLL_SPARSE = 1
+
+	// the largest value and run length that the sparse opcodes can represent
+	HLL_SPARSE_VAL_MAX_VALUE = 32
+	HLL_SPARSE_VAL_MAX_LEN   = 4
+	HLL_SPARSE_ZERO_MAX_LEN  = 64
+	HLL_SPARSE_XZERO_MAX_LEN = 16384
+
+	// sparse hyperloglogs larger than this are converted to the dense encoding, like hll-sparse-max-bytes in redis
+	HLL_SPARSE_MAX_BYTES = 3000
+
+	HLL_ALPHA_INF = 0.721347520444481703680
+	HLL_MAGIC     = "HYLL"
+	HLL_HASH_SEED = 0xadc83b19
+)
+
+var (
+	ErrNotHyperLogLog     = errors.New("WRONGTYPE Key is not a valid HyperLogLog string value.")
+	ErrCorruptHyperLogLog = errors.New("INVALIDOBJ Corrupted HLL object detected")
+)
+
+// hllRegisters holds the registers of a hyperloglog regardless of how it is encoded.
+type hllRegisters [HLL_REGISTERS]uint8
+
+// hyperLogLog is a decoded hyperloglog along with the details needed to encode it back.
+type hyperLogLog struct {
+	registers hllRegisters
+	dense     bool
+}
+
+// murmurHash64A is the 64 bit MurmurHash2 variant used by redis to hash the elements.
+func murmurHash64A(key []byte, seed uint64) uint64 {
+	const m = 0xc6a4a7935bd1e995
+	const r = 47
+
+	h := seed ^ (uint64(len(key)) * m)
+	for len(key) >= 8 {
+		k := binary.LittleEndian.Uint64(key)
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+		key = key[8:]
+	}
+
+	if len(key) > 0 {
+		for idx := len(key) - 1; idx >= 0; idx-- {
+			h ^= uint64(key[idx]) << (8 * idx)
+		}
+		h *= m
+	}
+
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
+
+// hllPatLen returns the register of the element and the length of the run of zeros in its hash plus one.
+func hllPatLen(element string) (int, uint8) {
+	hash := murmurHash64A([]byte(element), HLL_HASH_SEED)
+	index := int(hash & HLL_P_MASK)
+
+	// the bit at position Q makes sure the loop terminates
+	hash >>= HLL_P
+	hash |= 1 << HLL_Q
+	count := uint8(1)
+	for bit := uint64(1); hash&bit == 0; bit <<= 1 {
+		count++
+	}
+	return index, count
+}
+
+// newHyperLogLog returns an empty hyperloglog, which starts out in the sparse encoding.
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{}
+}
+
+// decodeHyperLogLog reads a hyperloglog stored as a string.
+func decodeHyperLogLog(value string) (*hyperLogLog, error) {
+	if len(value) < HLL_HDR_SIZE || value[:4] != HLL_MAGIC {
+		return nil, ErrNotHyperLogLog
+	}
+
+	hll := &hyperLogLog{}
+	payload := value[HLL_HDR_SIZE:]
+	switch value[4] {
+	case HLL_DENSE:
+		if len(value) != HLL_DENSE_LEN {
+			return nil, ErrNotHyperLogLog
+		}
+		hll.dense = true
+		for idx := range HLL_REGISTERS {
+			hll.registers[idx] = denseRegister(payload, idx)
+		}
+	case HLL_SPARSE:
+		if err := decodeSparse(payload, &hll.registers); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrNotHyperLogLog
+	}
+	return hll, nil
+}
+
+// denseRegister reads a 6 bit register. registers are packed starting from the least significant bits of each byte.
+func denseRegister(payload string, idx int) uint8 {
+	byteIdx := idx * HLL_BITS / 8
+	shift := uint(idx * HLL_BITS & 7)
+	value := uint(payload[byteIdx]) >> shift
+	if byteIdx+1 < len(payload) {
+		value |= uint(payload[byteIdx+1]) << (8 - shift)
+	}
+	return uint8(value & HLL_REG_MAX)
+}
+
+func setDenseRegister(payload []byte, idx int, value uint8) {
+	byteIdx := idx * HLL_BITS / 8
+	shift := uint(idx * HLL_BITS & 7)
+	payload[byteIdx] &^= HLL_REG_MAX << shift
+	payload[byteIdx] |= value << shift
+	if byteIdx+1 < len(payload) {
+		payload[byteIdx+1] &^= HLL_REG_MAX >> (8 - shift)
+		payload[byteIdx+1] |= value >> (8 - shift)
+	}
+}
+
+// decodeSparse expands the sparse opcodes into the registers:
+// ZERO (00xxxxxx) is a run of up to 64 zero registers, XZERO (01xxxxxx yyyyyyyy) a run of up to 16384
+// zero registers and VAL (1vvvvvxx) a run of up to 4 registers with a value of up to 32.
+func decodeSparse(payload string, registers *hllRegisters) error {
+	idx := 0
+	for pos := 0; pos < len(payload); pos++ {
+		opcode := payload[pos]
+		switch {
+		case opcode&0xc0 == 0x00:
+			idx += int(opcode&0x3f) + 1
+		case opcode&0xc0 == 0x40:
+			if pos+1 >= len(payload) {
+				return ErrCorruptHyperLogLog
+			}
+			pos++
+			idx += (int(opcode&0x3f)<<8 | int(payload[pos])) + 1
+		default:
+			runLen := int(opcode&0x3) + 1
+			value := (opcode>>2)&0x1f + 1
+			if idx+runLen > HLL_REGISTERS {
+				return ErrCorruptHyperLogLog
+			}
+			for run := range runLen {
+				registers[idx+run] = value
+			}
+			idx += runLen
+		}
+		if idx > HLL_REGISTERS {
+			return ErrCorruptHyperLogLog
+		}
+	}
+
+	if idx != HLL_REGISTERS {
+		return ErrCorruptHyperLogLog
+	}
+	return nil
+}
+
+// encodeSparse returns the sparse opcodes for the registers, or false when a register is too large for them.
+func encodeSparse(registers *hllRegisters) ([]byte, bool) {
+	payload := []byte{}
+	for idx := 0; idx < HLL_REGISTERS; {
+		value := registers[idx]
+		runLen := 1
+		for idx+runLen < HLL_REGISTERS && registers[idx+runLen] == value {
+			runLen++
+		}
+		idx += runLen
+
+		if value == 0 {
+			if runLen > HLL_SPARSE_ZERO_MAX_LEN {
+				payload = append(payload, 0x40|byte((runLen-1)>>8), byte(runLen-1))
+			} else {
+				payload = append(payload, byte(runLen-1))
+			}
+			continue
+		}
+
+		if value > HLL_SPARSE_VAL_MAX_VALUE {
+			return nil, false
+		}
+		for ; runLen > 0; runLen -= HLL_SPARSE_VAL_MAX_LEN {
+			chunk := min(runLen, HLL_SPARSE_VAL_MAX_LEN)
+			payload = append(payload, 0x80|(value-1)<<2|byte(chunk-1))
+		}
+	}
+	return payload, true
+}
+
+// encode writes the hyperloglog as a string. sparse hyperloglogs switch to the dense encoding once they
+// get too large, and dense ones never go back. card is stored as the cached cardinality when valid is set.
+func (hll *hyperLogLog) encode(card int64, valid bool) string {
+	header := make([]byte, HLL_HDR_SIZE)
+	copy(header, HLL_MAGIC)
+	binary.LittleEndian.PutUint64(header[8:], uint64(card))
+	if !valid {
+		header[15] |= 1 << 7
+	}
+
+	if !hll.dense {
+		payload, ok := encodeSparse(&hll.registers)
+		if ok && len(payload)+HLL_HDR_SIZE <= HLL_SPARSE_MAX_BYTES {
+			header[4] = HLL_SPARSE
+			return string(append(header, payload...))
+		}
+		hll.dense = true
+	}
+
+	header[4] = HLL_DENSE
+	payload := make([]byte, HLL_DENSE_LEN-HLL_HDR_SIZE)
+	for idx, value := range hll.registers {
+		setDenseRegister(payload, idx, value)
+	}
+	return string(append(header, payload...))
+}
+
+// cachedCardinality returns the cardinality stored in the header of an encoded hyperloglog, if it is still valid.
+func cachedCardinality(value string) (int64, bool) {
+	if value[15]&(1<<7) != 0 {
+		return 0, false
+	}
+	return int64(binary.LittleEndian.Uint64([]byte(value[8:HLL_HDR_SIZE]))), true
+}
+
+// withCachedCardinality returns the encoded hyperloglog with the cardinality cached in its header.
+func withCachedCardinality(value string, card int64) string {
+	encoded := []byte(value)
+	binary.LittleEndian.PutUint64(encoded[8:HLL_HDR_SIZE], uint64(card))
+	return string(encoded)
+}
+
+// add adds the element and reports whether any register changed.
+func (hll *hyperLogLog) add(element string) bool {
+	idx, count := hllPatLen(element)
+	if hll.registers[idx] >= count {
+		return false
+	}
+	hll.registers[idx] = count
+	return true
+}
+
+// merge keeps the largest value of each register, giving the union of both hyperloglogs.
+func (hll *hyperLogLog) merge(other *hyperLogLog) {
+	for idx, value := range other.registers {
+		hll.registers[idx] = max(hll.registers[idx], value)
+	}
+	hll.dense = hll.dense || other.dense
+}
+
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		zPrime := z
+		z += x * y
+		y += y
+		if zPrime == z {
+			return z
+		}
+	}
+}
+
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		zPrime := z
+		y *= 0.5
+		z -= math.Pow(1-x, 2) * y
+		if zPrime == z {
+			return z / 3
+		}
+	}
+}
+
+// count estimates the cardinality with the improved estimator by Otmar Ertl that redis uses.
+func (hll *hyperLogLog) count() int64 {
+	histogram := [HLL_REG_MAX + 1]int{}
+	for _, value := range hll.registers {
+		histogram[value]++
+	}
+
+	m := float64(HLL_REGISTERS)
+	z := m * hllTau((m-float64(histogram[HLL_Q+1]))/m)
+	for j := HLL_Q; j >= 1; j-- {
+		z += float64(histogram[j])
+		z *= 0.5
+	}
+	z += m * hllSigma(float64(histogram[0])/m)
+	return int64(math.Round(HLL_ALPHA_INF * m * m / z))
+}
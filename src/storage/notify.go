@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"time"
+)
+
+// the classes of keyspace events, named after the flags of notify-keyspace-events that enable them.
+const (
+	EVENT_CLASS_GENERIC = 'g'
+	EVENT_CLASS_STRING  = '$'
+	EVENT_CLASS_LIST    = 'l'
+	EVENT_CLASS_ZSET    = 'z'
+	EVENT_CLASS_STREAM  = 't'
+	EVENT_CLASS_EXPIRED = 'x'
+	EVENT_CLASS_NEW     = 'n'
+)
+
+// KeyspaceEvent describes a change made to a key, like "set" or "expired".
+type KeyspaceEvent struct {
+	Class byte
+	Name  string
+	Key   string
+}
+
+// SetNotifier registers the function called with the event of every change made to the dataset. it is called
+// while the change is applied so that the events of a key are seen in the order of the changes, which means it
+// must neither block nor use the storage engine.
+func (mse *MapStorageEngine) SetNotifier(notify func(event KeyspaceEvent)) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+	mse.notify = notify
+}
+
+// notifyLocked sends a keyspace event to the notifier, if any. It must be called with the lock held.
+func (mse *MapStorageEngine) notifyLocked(class byte, name string, key string) {
+	if mse.notify != nil {
+		mse.notify(KeyspaceEvent{Class: class, Name: name, Key: key})
+	}
+}
+
+// putLocked stores the entry at key, notifying the creation of the key when it didn't exist.
+// It must be called with the lock held.
+func (mse *MapStorageEngine) putLocked(key string, entry DataContainer) {
+	if _, exists := mse.store[key]; !exists {
+		mse.notifyLocked(EVENT_CLASS_NEW, "new", key)
+	}
+	mse.storeLocked(key, entry)
+}
+
+// storeLocked stores the entry at key, keeping the index of the keys with an expiry up to date.
+// It must be called with the lock held.
+func (mse *MapStorageEngine) storeLocked(key string, entry DataContainer) {
+	mse.store[key] = entry
+	if entry.Expires {
+		mse.expires[key] = struct{}{}
+	} else {
+		delete(mse.expires, key)
+	}
+}
+
+// deleteLocked removes the key, notifying the event that removed it when it existed.
+// It must be called with the lock held.
+func (mse *MapStorageEngine) deleteLocked(key string, class byte, name string) bool {
+	if _, exists := mse.store[key]; !exists {
+		return false
+	}
+	delete(mse.store, key)
+	delete(mse.expires, key)
+	mse.notifyLocked(class, name, key)
+	return true
+}
+
+// consumerLocked returns the consumer of the group, notifying its creation when it is new.
+// It must be called with the lock held.
+func (mse *MapStorageEngine) consumerLocked(key string, cg *streamGroup, name string, now time.Time) *streamConsumer {
+	if _, exists := cg.consumers[name]; !exists {
+		mse.notifyLocked(EVENT_CLASS_STREAM, "xgroup-createconsumer", key)
+	}
+	return cg.consumer(name, now)
+}
+
+// DeleteExpired removes the expired keys among up to sampleSize keys with an expiry, picked at random, and
+// returns the number of keys removed along with the number of keys checked. this is how the keys that are
+// never accessed again are eventually removed.
+func (mse *MapStorageEngine) DeleteExpired(sampleSize int) (int, int) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	// like redis, the keys are sampled from the index of the keys with an expiry rather than from the whole
+	// dataset, and the iteration order of maps is random
+	now := time.Now()
+	sampled := make([]string, 0, min(sampleSize, len(mse.expires)))
+	for key := range mse.expires {
+		if len(sampled) == sampleSize {
+			break
+		}
+		sampled = append(sampled, key)
+	}
+
+	expired := 0
+	for _, key := range sampled {
+		if !now.Before(mse.store[key].ExpiresAt) {
+			mse.deleteLocked(key, EVENT_CLASS_EXPIRED, "expired")
+			expired++
+		}
+	}
+	return expired, len(sampled)
+}
@@ -0,0 +1,339 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc64"
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// snapshots serialise the whole dataset, so that replicas can load the data of their master on a full
+// resynchronisation. a snapshot starts with a magic string and a version, followed by the number of keys
+// and then each key with its expiry and its value, and by the function libraries. a CRC64 of everything
+// before it closes the snapshot.
+const (
+	SNAPSHOT_MAGIC   = "CCKV"
+	SNAPSHOT_VERSION = 3
+)
+
+// the types of the values, which prefix their encoding.
+const (
+	VALUE_TYPE_STRING byte = iota
+	VALUE_TYPE_GEO
+	VALUE_TYPE_STREAM
+)
+
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+var crcTable = crc64.MakeTable(crc64.ECMA)
+
+// encoder appends values to a buffer in the binary format shared by snapshots.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) byte(b byte) {
+	e.buf = append(e.buf, b)
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+func (e *encoder) varint(v int64) {
+	e.buf = binary.AppendVarint(e.buf, v)
+}
+
+func (e *encoder) float(f float64) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(f))
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) streamID(id StreamID) {
+	e.uvarint(id.Ms)
+	e.uvarint(id.Seq)
+}
+
+// time encodes a time in milliseconds, with the zero time encoded as 0.
+func (e *encoder) time(t time.Time) {
+	if t.IsZero() {
+		e.varint(0)
+		return
+	}
+	e.varint(t.UnixMilli())
+}
+
+// decoder reads values written by an encoder. the first error is kept, and every read after it returns zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = ErrInvalidSnapshot
+	}
+	d.buf = nil
+}
+
+func (d *decoder) byte() byte {
+	if len(d.buf) == 0 {
+		d.fail()
+		return 0
+	}
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) float() float64 {
+	if len(d.buf) < 8 {
+		d.fail()
+		return 0
+	}
+	f := math.Float64frombits(binary.LittleEndian.Uint64(d.buf))
+	d.buf = d.buf[8:]
+	return f
+}
+
+// count reads the length of a collection, which can't be larger than the bytes left to decode.
+func (d *decoder) count() int {
+	n := d.uvarint()
+	if n > uint64(len(d.buf)) {
+		d.fail()
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) string() string {
+	n := d.count()
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
+
+func (d *decoder) streamID() StreamID {
+	return StreamID{Ms: d.uvarint(), Seq: d.uvarint()}
+}
+
+func (d *decoder) time() time.Time {
+	ms := d.varint()
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// encodeValue appends the type and the contents of a value, leaving out its expiry.
+func encodeValue(e *encoder, dc DataContainer) {
+	switch {
+	case dc.Geo != nil:
+		e.byte(VALUE_TYPE_GEO)
+		e.uvarint(uint64(len(dc.Geo.ordered)))
+		for _, member := range dc.Geo.ordered {
+			e.string(member.member)
+			e.float(member.score)
+		}
+	case dc.Stream != nil:
+		e.byte(VALUE_TYPE_STREAM)
+		encodeStream(e, dc.Stream)
+	default:
+		e.byte(VALUE_TYPE_STRING)
+		e.string(dc.Data)
+	}
+}
+
+func encodeStream(e *encoder, s *Stream) {
+	e.uvarint(uint64(len(s.entries)))
+	for _, entry := range s.entries {
+		e.streamID(entry.ID)
+		e.uvarint(uint64(len(entry.Fields)))
+		for _, field := range entry.Fields {
+			e.string(field)
+		}
+	}
+	e.streamID(s.lastID)
+	e.streamID(s.maxDeletedID)
+	e.uvarint(s.entriesAdded)
+
+	e.uvarint(uint64(len(s.groups)))
+	for _, name := range slices.Sorted(maps.Keys(s.groups)) {
+		group := s.groups[name]
+		e.string(name)
+		e.streamID(group.lastID)
+		e.varint(group.entriesRead)
+
+		// the pending entries of the group are rebuilt from the ones of its consumers
+		e.uvarint(uint64(len(group.consumers)))
+		for _, consumerName := range slices.Sorted(maps.Keys(group.consumers)) {
+			consumer := group.consumers[consumerName]
+			e.string(consumer.name)
+			e.time(consumer.seenTime)
+			e.time(consumer.activeTime)
+			e.uvarint(uint64(len(consumer.pending)))
+			for _, id := range sortedIDs(consumer.pending) {
+				nack := consumer.pending[id]
+				e.streamID(id)
+				e.time(nack.deliveryTime)
+				e.varint(nack.deliveryCount)
+			}
+		}
+	}
+}
+
+// decodeValue reads a value written by encodeValue.
+func decodeValue(d *decoder) DataContainer {
+	switch d.byte() {
+	case VALUE_TYPE_STRING:
+		return DataContainer{Data: d.string()}
+	case VALUE_TYPE_GEO:
+		geo := newGeoIndex()
+		for range d.count() {
+			member := d.string()
+			geo.set(member, d.float())
+		}
+		return DataContainer{Geo: geo}
+	case VALUE_TYPE_STREAM:
+		return DataContainer{Stream: decodeStream(d)}
+	default:
+		d.fail()
+		return DataContainer{}
+	}
+}
+
+func decodeStream(d *decoder) *Stream {
+	s := newStream()
+	entries := d.count()
+	s.entries = make([]StreamEntry, 0, entries)
+	for range entries {
+		id := d.streamID()
+		fields := make([]string, d.count())
+		for idx := range fields {
+			fields[idx] = d.string()
+		}
+		s.entries = append(s.entries, StreamEntry{ID: id, Fields: fields})
+	}
+	s.lastID = d.streamID()
+	s.maxDeletedID = d.streamID()
+	s.entriesAdded = d.uvarint()
+
+	for range d.count() {
+		name := d.string()
+		lastID := d.streamID()
+		group := newStreamGroup(lastID, d.varint())
+		for range d.count() {
+			consumer := &streamConsumer{
+				name:       d.string(),
+				seenTime:   d.time(),
+				activeTime: d.time(),
+				pending:    make(map[StreamID]*streamNACK),
+			}
+			for range d.count() {
+				id := d.streamID()
+				nack := &streamNACK{consumer: consumer, deliveryTime: d.time(), deliveryCount: d.varint()}
+				consumer.pending[id] = nack
+				group.pending[id] = nack
+			}
+			group.consumers[consumer.name] = consumer
+		}
+		if s.groups == nil {
+			s.groups = make(map[string]*streamGroup)
+		}
+		s.groups[name] = group
+	}
+	return s
+}
+
+// Snapshot serialises every key that has not expired, along with the function libraries.
+func (mse *MapStorageEngine) Snapshot() []byte {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	keys := mse.keysLocked()
+	e := &encoder{buf: []byte(SNAPSHOT_MAGIC)}
+	e.byte(SNAPSHOT_VERSION)
+	e.uvarint(uint64(len(keys)))
+	for _, key := range keys {
+		dc := mse.store[key]
+		e.string(key)
+		if dc.Expires {
+			e.time(dc.ExpiresAt)
+		} else {
+			e.time(time.Time{})
+		}
+		encodeValue(e, dc)
+	}
+	encodeLibraries(e, mse.libraries)
+	return binary.LittleEndian.AppendUint64(e.buf, crc64.Checksum(e.buf, crcTable))
+}
+
+// LoadSnapshot replaces the whole dataset and the function libraries with the ones of a snapshot. the
+// dataset is left untouched when the snapshot is invalid.
+func (mse *MapStorageEngine) LoadSnapshot(snapshot []byte) error {
+	header := len(SNAPSHOT_MAGIC) + 1
+	if len(snapshot) < header+8 || string(snapshot[:len(SNAPSHOT_MAGIC)]) != SNAPSHOT_MAGIC ||
+		snapshot[len(SNAPSHOT_MAGIC)] != SNAPSHOT_VERSION {
+		return ErrInvalidSnapshot
+	}
+	body := snapshot[:len(snapshot)-8]
+	if crc64.Checksum(body, crcTable) != binary.LittleEndian.Uint64(snapshot[len(body):]) {
+		return ErrInvalidSnapshot
+	}
+
+	d := &decoder{buf: body[header:]}
+	store := make(map[string]DataContainer)
+	expires := make(map[string]struct{})
+	for range d.count() {
+		key := d.string()
+		expiresAt := d.time()
+		dc := decodeValue(d)
+		dc.Expires, dc.ExpiresAt = !expiresAt.IsZero(), expiresAt
+		store[key] = dc
+		if dc.Expires {
+			expires[key] = struct{}{}
+		}
+	}
+	libraries := decodeLibraries(d)
+	if d.err != nil || len(d.buf) > 0 {
+		return ErrInvalidSnapshot
+	}
+
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+	mse.store = store
+	mse.expires = expires
+	mse.libraries = libraries
+	// the streams of blocked clients may have been replaced
+	for key := range mse.waiters {
+		mse.signalWaitersLocked(key)
+	}
+	return nil
+}
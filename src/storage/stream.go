@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// streams keep their entries ordered by ID, so that ranges are found with a binary search.
+// like the listpack nodes of redis, approximate trimming only removes whole nodes of entries.
+const STREAM_NODE_MAX_ENTRIES = 100
+
+const (
+	STREAM_TRIM_NONE = iota
+	STREAM_TRIM_MAXLEN
+	STREAM_TRIM_MINID
+)
+
+var (
+	ErrStreamIDTooSmall  = errors.New("The ID specified in XADD is equal or smaller than the target stream top item")
+	ErrStreamIDZero      = errors.New("The ID specified in XADD must be greater than 0-0")
+	ErrStreamIDExhausted = errors.New("The stream has exhausted the last possible ID, unable to add more items")
+)
+
+// StreamID identifies an entry of a stream by the milliseconds time it was added at and a sequence number.
+type StreamID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+var (
+	MIN_STREAM_ID = StreamID{0, 0}
+	MAX_STREAM_ID = StreamID{math.MaxUint64, math.MaxUint64}
+)
+
+// ParseStreamID parses an ID in the ms-seq format. The sequence may be left out, in which case it is set to missingSeq.
+func ParseStreamID(value string, missingSeq uint64) (StreamID, bool) {
+	msPart, seqPart, hasSeq := strings.Cut(value, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return StreamID{}, false
+	}
+	if !hasSeq {
+		return StreamID{ms, missingSeq}, true
+	}
+
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return StreamID{}, false
+	}
+	return StreamID{ms, seq}, true
+}
+
+func (id StreamID) String() string {
+	return fmt.Sprintf("%d-%d", id.Ms, id.Seq)
+}
+
+func (id StreamID) Compare(other StreamID) int {
+	if id.Ms != other.Ms {
+		return cmp.Compare(id.Ms, other.Ms)
+	}
+	return cmp.Compare(id.Seq, other.Seq)
+}
+
+// Next returns the smallest ID greater than the ID, or false if there is none.
+func (id StreamID) Next() (StreamID, bool) {
+	switch {
+	case id.Seq < math.MaxUint64:
+		return StreamID{id.Ms, id.Seq + 1}, true
+	case id.Ms < math.MaxUint64:
+		return StreamID{id.Ms + 1, 0}, true
+	default:
+		return id, false
+	}
+}
+
+// Prev returns the largest ID smaller than the ID, or false if there is none.
+func (id StreamID) Prev() (StreamID, bool) {
+	switch {
+	case id.Seq > 0:
+		return StreamID{id.Ms, id.Seq - 1}, true
+	case id.Ms > 0:
+		return StreamID{id.Ms - 1, math.MaxUint64}, true
+	default:
+		return id, false
+	}
+}
+
+// StreamEntry is an entry of a stream. Fields holds the field names and values one after the other.
+type StreamEntry struct {
+	ID     StreamID
+	Fields []string
+}
+
+// StreamAddID is the ID given to XADD. AutoMs generates the whole ID from the current time,
+// while AutoSeq only generates the sequence number for the given milliseconds.
+type StreamAddID struct {
+	ID      StreamID
+	AutoMs  bool
+	AutoSeq bool
+}
+
+// StreamTrim describes how a stream is trimmed, either to MaxLen entries or to the entries from MinID.
+// Approximate trimming only removes whole nodes of entries, at most Limit entries when Limit is positive.
+type StreamTrim struct {
+	Strategy int
+	MaxLen   int64
+	MinID    StreamID
+	Approx   bool
+	Limit    int64
+}
+
+// Stream is an append-only log of entries.
+type Stream struct {
+	entries      []StreamEntry
+	lastID       StreamID
+	maxDeletedID StreamID
+	entriesAdded uint64
+	groups       map[string]*streamGroup
+}
+
+func newStream() *Stream {
+	return &Stream{}
+}
+
+// Len returns the number of entries of the stream.
+func (s *Stream) Len() int {
+	return len(s.entries)
+}
+
+// LastID returns the ID of the last entry added to the stream, which may have been deleted since.
+func (s *Stream) LastID() StreamID {
+	return s.lastID
+}
+
+// nextID returns the ID of the entry to add, checking that it is greater than all the existing ones.
+func (s *Stream) nextID(addID StreamAddID, now time.Time) (StreamID, error) {
+	if addID.AutoMs {
+		ms := max(uint64(now.UnixMilli()), s.lastID.Ms)
+		if ms > s.lastID.Ms {
+			return StreamID{ms, 0}, nil
+		}
+		next, ok := s.lastID.Next()
+		if !ok {
+			return StreamID{}, ErrStreamIDExhausted
+		}
+		return next, nil
+	}
+
+	id := addID.ID
+	if addID.AutoSeq {
+		switch {
+		case id.Ms > s.lastID.Ms:
+			id.Seq = 0
+		case id.Ms == s.lastID.Ms && s.lastID.Seq < math.MaxUint64:
+			id.Seq = s.lastID.Seq + 1
+		default:
+			return StreamID{}, ErrStreamIDTooSmall
+		}
+		// 0-0 is never a valid ID, so the first sequence number of the 0 millisecond is 1
+		if id == MIN_STREAM_ID {
+			id.Seq = 1
+		}
+	}
+
+	if id == MIN_STREAM_ID {
+		return StreamID{}, ErrStreamIDZero
+	}
+	if id.Compare(s.lastID) <= 0 {
+		return StreamID{}, ErrStreamIDTooSmall
+	}
+	return id, nil
+}
+
+// add appends an entry with the given ID, which must be greater than the last ID.
+func (s *Stream) add(id StreamID, fields []string) {
+	s.entries = append(s.entries, StreamEntry{ID: id, Fields: fields})
+	s.lastID = id
+	s.entriesAdded++
+}
+
+// search returns the index of the first entry with an ID greater than or equal to the given ID.
+func (s *Stream) search(id StreamID) int {
+	idx, _ := slices.BinarySearchFunc(s.entries, id, func(entry StreamEntry, target StreamID) int {
+		return entry.ID.Compare(target)
+	})
+	return idx
+}
+
+// rangeEntries returns the entries with IDs between start and end (both inclusive), at most count entries
+// when count is positive. reverse returns the entries from end to start.
+func (s *Stream) rangeEntries(start StreamID, end StreamID, count int, reverse bool) []StreamEntry {
+	if start.Compare(end) > 0 {
+		return []StreamEntry{}
+	}
+
+	from := s.search(start)
+	to := from
+	for to < len(s.entries) && s.entries[to].ID.Compare(end) <= 0 {
+		to++
+	}
+
+	entries := slices.Clone(s.entries[from:to])
+	if reverse {
+		slices.Reverse(entries)
+	}
+	if count > 0 && len(entries) > count {
+		entries = entries[:count]
+	}
+	return entries
+}
+
+// trim removes the oldest entries according to the trimming options and returns how many were removed.
+func (s *Stream) trim(trim StreamTrim) int64 {
+	removable := 0
+	switch trim.Strategy {
+	case STREAM_TRIM_MAXLEN:
+		removable = max(len(s.entries)-int(trim.MaxLen), 0)
+	case STREAM_TRIM_MINID:
+		removable = s.search(trim.MinID)
+	default:
+		return 0
+	}
+
+	if trim.Approx {
+		limit := trim.Limit
+		if limit <= 0 {
+			limit = math.MaxInt64
+		}
+		nodes := min(int64(removable/STREAM_NODE_MAX_ENTRIES), limit/STREAM_NODE_MAX_ENTRIES)
+		removable = int(nodes) * STREAM_NODE_MAX_ENTRIES
+	}
+
+	s.entries = slices.Delete(s.entries, 0, removable)
+	return int64(removable)
+}
+
+// delete removes the entries with the given IDs and returns how many existed.
+func (s *Stream) delete(ids []StreamID) int64 {
+	deleted := int64(0)
+	for _, id := range ids {
+		idx := s.search(id)
+		if idx == len(s.entries) || s.entries[idx].ID != id {
+			continue
+		}
+		s.entries = slices.Delete(s.entries, idx, idx+1)
+		if id.Compare(s.maxDeletedID) > 0 {
+			s.maxDeletedID = id
+		}
+		deleted++
+	}
+	return deleted
+}
@@ -0,0 +1,392 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// the entries read by a group are unknown once entries are deleted from the part of the stream it has not read yet.
+const STREAM_ENTRIES_READ_UNKNOWN = -1
+
+var (
+	ErrNoSuchKey = errors.New("no such key")
+	ErrNoGroup   = errors.New("NOGROUP No such consumer group")
+	ErrBusyGroup = errors.New("BUSYGROUP Consumer Group name already exists")
+)
+
+// NoGroupError reports a consumer group missing from a stream. It matches ErrNoGroup.
+type NoGroupError struct {
+	Key   string
+	Group string
+}
+
+func (e *NoGroupError) Error() string {
+	return fmt.Sprintf("%s '%s' for key name '%s'", ErrNoGroup, e.Group, e.Key)
+}
+
+func (e *NoGroupError) Unwrap() error {
+	return ErrNoGroup
+}
+
+// streamNACK is an entry delivered to a consumer of a group that was not acknowledged yet.
+type streamNACK struct {
+	consumer      *streamConsumer
+	deliveryTime  time.Time
+	deliveryCount int64
+}
+
+// streamConsumer is a consumer of a group along with the entries delivered to it that are still pending.
+// activeTime is zero until the consumer reads or claims an entry.
+type streamConsumer struct {
+	name       string
+	seenTime   time.Time
+	activeTime time.Time
+	pending    map[StreamID]*streamNACK
+}
+
+// streamGroup is a consumer group. the pending entries of the group are the union of the pending
+// entries of its consumers, and share the same streamNACK.
+type streamGroup struct {
+	lastID      StreamID
+	entriesRead int64
+	pending     map[StreamID]*streamNACK
+	consumers   map[string]*streamConsumer
+}
+
+// StreamPendingEntry is an entry pending in a consumer group.
+type StreamPendingEntry struct {
+	ID            StreamID
+	Consumer      string
+	DeliveryTime  time.Time
+	DeliveryCount int64
+}
+
+// StreamPendingSummary is the summary form of XPENDING: the number of pending entries, their smallest
+// and largest IDs and the number of entries pending for each consumer.
+type StreamPendingSummary struct {
+	Count     int64
+	First     StreamID
+	Last      StreamID
+	Consumers []StreamConsumerPending
+}
+
+type StreamConsumerPending struct {
+	Name  string
+	Count int64
+}
+
+// StreamPendingQuery selects the pending entries returned by the extended form of XPENDING.
+// Consumer and MinIdle are ignored when empty.
+type StreamPendingQuery struct {
+	Start    StreamID
+	End      StreamID
+	Count    int
+	Consumer string
+	MinIdle  time.Duration
+}
+
+// StreamClaimOptions holds the options of XCLAIM. A zero DeliveryTime is the current time.
+type StreamClaimOptions struct {
+	DeliveryTime  time.Time
+	RetryCount    int64
+	HasRetryCount bool
+	Force         bool
+	JustID        bool
+	LastID        StreamID
+}
+
+// StreamGroupInfo describes a consumer group for XINFO. The pending entries and the consumers
+// are only filled in for XINFO STREAM FULL.
+type StreamGroupInfo struct {
+	Name            string
+	Consumers       int64
+	Pending         int64
+	LastDeliveredID StreamID
+	EntriesRead     int64
+	HasEntriesRead  bool
+	Lag             int64
+	HasLag          bool
+	PendingEntries  []StreamPendingEntry
+	ConsumerInfos   []StreamConsumerInfo
+}
+
+// StreamConsumerInfo describes a consumer of a group for XINFO. ActiveTime is zero when the consumer never
+// read or claimed an entry, and the pending entries are only filled in for XINFO STREAM FULL.
+type StreamConsumerInfo struct {
+	Name           string
+	Pending        int64
+	SeenTime       time.Time
+	ActiveTime     time.Time
+	PendingEntries []StreamPendingEntry
+}
+
+// StreamInfo describes a stream for XINFO STREAM. Entries are only filled in for XINFO STREAM FULL.
+type StreamInfo struct {
+	Length       int64
+	Nodes        int64
+	LastID       StreamID
+	MaxDeletedID StreamID
+	EntriesAdded int64
+	FirstID      StreamID
+	First        *StreamEntry
+	Last         *StreamEntry
+	Entries      []StreamEntry
+	Groups       []StreamGroupInfo
+}
+
+func newStreamGroup(lastID StreamID, entriesRead int64) *streamGroup {
+	return &streamGroup{
+		lastID:      lastID,
+		entriesRead: entriesRead,
+		pending:     make(map[StreamID]*streamNACK),
+		consumers:   make(map[string]*streamConsumer),
+	}
+}
+
+// sortedIDs returns the IDs of the pending entries in increasing order.
+func sortedIDs(pending map[StreamID]*streamNACK) []StreamID {
+	return slices.SortedFunc(maps.Keys(pending), StreamID.Compare)
+}
+
+// group returns the consumer group with the given name, if it exists.
+func (s *Stream) group(name string) (*streamGroup, bool) {
+	group, ok := s.groups[name]
+	return group, ok
+}
+
+// createGroup adds a consumer group that has read the entries up to lastID, and reports whether the group is new.
+func (s *Stream) createGroup(name string, lastID StreamID, entriesRead int64) bool {
+	if _, ok := s.groups[name]; ok {
+		return false
+	}
+	if s.groups == nil {
+		s.groups = make(map[string]*streamGroup)
+	}
+	s.groups[name] = newStreamGroup(lastID, entriesRead)
+	return true
+}
+
+// lookup returns the entry with the given ID, if it was not deleted.
+func (s *Stream) lookup(id StreamID) (StreamEntry, bool) {
+	idx := s.search(id)
+	if idx == len(s.entries) || s.entries[idx].ID != id {
+		return StreamEntry{}, false
+	}
+	return s.entries[idx], true
+}
+
+// firstID returns the ID of the first entry, or 0-0 when the stream is empty.
+func (s *Stream) firstID() StreamID {
+	if len(s.entries) == 0 {
+		return MIN_STREAM_ID
+	}
+	return s.entries[0].ID
+}
+
+// hasTombstonesFrom reports whether entries with IDs from the given one may have been deleted.
+func (s *Stream) hasTombstonesFrom(id StreamID) bool {
+	if len(s.entries) == 0 || s.maxDeletedID == MIN_STREAM_ID {
+		return false
+	}
+	return s.maxDeletedID.Compare(id) >= 0
+}
+
+// entriesUpTo returns the number of entries that were ever added up to the given ID, if it can be known.
+func (s *Stream) entriesUpTo(id StreamID) (int64, bool) {
+	added := int64(s.entriesAdded)
+	if added == 0 {
+		return 0, true
+	}
+	if len(s.entries) == 0 && id.Compare(s.lastID) < 0 {
+		return added, true
+	}
+	if id.Compare(s.lastID) >= 0 {
+		return added, true
+	}
+
+	// without deletions in the middle of the stream, the entries before the first one were all trimmed
+	if !s.hasTombstonesFrom(s.firstID()) {
+		switch id.Compare(s.firstID()) {
+		case -1:
+			return added - int64(len(s.entries)), true
+		case 0:
+			return added - int64(len(s.entries)) + 1, true
+		}
+	}
+	return 0, false
+}
+
+// lag returns the number of entries of the stream that the group has not read yet, if it can be known.
+func (s *Stream) lag(group *streamGroup) (int64, bool) {
+	if s.entriesAdded == 0 {
+		return 0, true
+	}
+	if group.entriesRead != STREAM_ENTRIES_READ_UNKNOWN && !s.hasTombstonesFrom(group.lastID) {
+		return int64(s.entriesAdded) - group.entriesRead, true
+	}
+	read, ok := s.entriesUpTo(group.lastID)
+	if !ok {
+		return 0, false
+	}
+	return int64(s.entriesAdded) - read, true
+}
+
+// consumer returns the consumer with the given name, creating it if needed, and marks it as seen.
+func (g *streamGroup) consumer(name string, now time.Time) *streamConsumer {
+	consumer, ok := g.consumers[name]
+	if !ok {
+		consumer = &streamConsumer{name: name, pending: make(map[StreamID]*streamNACK)}
+		g.consumers[name] = consumer
+	}
+	consumer.seenTime = now
+	return consumer
+}
+
+// deliver records that the entry was delivered to the consumer, moving it from its previous consumer if needed.
+func (g *streamGroup) deliver(id StreamID, consumer *streamConsumer, now time.Time) {
+	nack, ok := g.pending[id]
+	if !ok {
+		nack = &streamNACK{}
+		g.pending[id] = nack
+	} else if nack.consumer != nil {
+		delete(nack.consumer.pending, id)
+	}
+	nack.consumer = consumer
+	nack.deliveryTime = now
+	nack.deliveryCount = 1
+	consumer.pending[id] = nack
+}
+
+// ack removes the entry from the pending entries and reports whether it was pending.
+func (g *streamGroup) ack(id StreamID) bool {
+	nack, ok := g.pending[id]
+	if !ok {
+		return false
+	}
+	delete(g.pending, id)
+	if nack.consumer != nil {
+		delete(nack.consumer.pending, id)
+	}
+	return true
+}
+
+// readNew delivers the entries after the last ID of the group to the consumer, at most count entries
+// when count is positive. noAck delivers them without adding them to the pending entries.
+func (s *Stream) readNew(group *streamGroup, consumer *streamConsumer, count int, noAck bool, now time.Time) []StreamEntry {
+	start, ok := group.lastID.Next()
+	if !ok {
+		return []StreamEntry{}
+	}
+
+	entries := s.rangeEntries(start, MAX_STREAM_ID, count, false)
+	for _, entry := range entries {
+		if group.entriesRead != STREAM_ENTRIES_READ_UNKNOWN && !s.hasTombstonesFrom(entry.ID) {
+			group.entriesRead++
+		} else if read, ok := s.entriesUpTo(entry.ID); ok {
+			group.entriesRead = read
+		} else {
+			group.entriesRead = STREAM_ENTRIES_READ_UNKNOWN
+		}
+		group.lastID = entry.ID
+
+		if !noAck {
+			group.deliver(entry.ID, consumer, now)
+		}
+	}
+	if len(entries) > 0 {
+		consumer.activeTime = now
+	}
+	return entries
+}
+
+// readHistory returns the entries pending for the consumer with IDs greater than the given one, at most count
+// entries when count is positive, and counts them as delivered again. Deleted entries are returned without fields.
+func (s *Stream) readHistory(consumer *streamConsumer, after StreamID, count int, now time.Time) []StreamEntry {
+	entries := []StreamEntry{}
+	for _, id := range sortedIDs(consumer.pending) {
+		if id.Compare(after) <= 0 {
+			continue
+		}
+		if count > 0 && len(entries) == count {
+			break
+		}
+
+		nack := consumer.pending[id]
+		nack.deliveryTime = now
+		nack.deliveryCount++
+		entry, ok := s.lookup(id)
+		if !ok {
+			entry = StreamEntry{ID: id}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// claim transfers a pending entry to the consumer.
+func (g *streamGroup) claim(id StreamID, consumer *streamConsumer, deliveryTime time.Time) *streamNACK {
+	nack := g.pending[id]
+	if nack.consumer != nil {
+		delete(nack.consumer.pending, id)
+	}
+	nack.consumer = consumer
+	nack.deliveryTime = deliveryTime
+	consumer.pending[id] = nack
+	return nack
+}
+
+// pendingEntries renders pending entries in increasing ID order.
+func pendingEntries(pending map[StreamID]*streamNACK) []StreamPendingEntry {
+	entries := []StreamPendingEntry{}
+	for _, id := range sortedIDs(pending) {
+		nack := pending[id]
+		entries = append(entries, StreamPendingEntry{
+			ID:            id,
+			Consumer:      nack.consumer.name,
+			DeliveryTime:  nack.deliveryTime,
+			DeliveryCount: nack.deliveryCount,
+		})
+	}
+	return entries
+}
+
+// groupInfo describes the group for XINFO, along with its pending entries and consumers when full is set.
+func (s *Stream) groupInfo(name string, group *streamGroup, full bool) StreamGroupInfo {
+	info := StreamGroupInfo{
+		Name:            name,
+		Consumers:       int64(len(group.consumers)),
+		Pending:         int64(len(group.pending)),
+		LastDeliveredID: group.lastID,
+		EntriesRead:     group.entriesRead,
+		HasEntriesRead:  group.entriesRead != STREAM_ENTRIES_READ_UNKNOWN,
+	}
+	info.Lag, info.HasLag = s.lag(group)
+
+	if full {
+		info.PendingEntries = pendingEntries(group.pending)
+		info.ConsumerInfos = group.consumerInfos(true)
+	}
+	return info
+}
+
+// consumerInfos describes the consumers of the group ordered by name, along with their pending entries when full is set.
+func (g *streamGroup) consumerInfos(full bool) []StreamConsumerInfo {
+	infos := []StreamConsumerInfo{}
+	for _, name := range slices.Sorted(maps.Keys(g.consumers)) {
+		consumer := g.consumers[name]
+		info := StreamConsumerInfo{
+			Name:       name,
+			Pending:    int64(len(consumer.pending)),
+			SeenTime:   consumer.seenTime,
+			ActiveTime: consumer.activeTime,
+		}
+		if full {
+			info.PendingEntries = pendingEntries(consumer.pending)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
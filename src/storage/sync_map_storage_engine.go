@@ -1,7 +1,11 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -10,12 +14,23 @@ import (
 
 type MapStorageEngine struct {
 	store map[string]DataContainer
-	mu    sync.Mutex
+	// expires holds the keys of the store with an expiry, which the active expiry samples
+	expires map[string]struct{}
+	mu      sync.Mutex
+	// waiters holds the channels of the clients blocked on each stream, which are signalled by XADD
+	waiters map[string]map[chan struct{}]struct{}
+	// libraries holds the code of the function libraries by their name
+	libraries map[string]string
+	// notify is called with the keyspace event of every change, see SetNotifier
+	notify func(event KeyspaceEvent)
 }
 
 func NewMapStorageEngine() MapStorageEngine {
 	return MapStorageEngine{
-		store: make(map[string]DataContainer),
+		store:     make(map[string]DataContainer),
+		expires:   make(map[string]struct{}),
+		waiters:   make(map[string]map[chan struct{}]struct{}),
+		libraries: make(map[string]string),
 	}
 }
 
@@ -27,10 +42,14 @@ func (mse *MapStorageEngine) Set(key string, value string, expires bool, expires
 		expiryTime = time.UnixMilli(expiresAtTimeStampMillis)
 	}
 
-	mse.store[key] = DataContainer{
+	mse.putLocked(key, DataContainer{
 		Data:      value,
 		Expires:   expires,
 		ExpiresAt: expiryTime,
+	})
+	mse.notifyLocked(EVENT_CLASS_STRING, "set", key)
+	if expires {
+		mse.notifyLocked(EVENT_CLASS_GENERIC, "expire", key)
 	}
 	return nil
 }
@@ -38,14 +57,12 @@ func (mse *MapStorageEngine) Set(key string, value string, expires bool, expires
 func (mse *MapStorageEngine) Get(key string) (bool, string, error) {
 	mse.mu.Lock()
 	defer mse.mu.Unlock()
-	result, ok := mse.store[key]
-	if !ok {
-		return false, "", nil
-	}
 
-	if result.Expires && time.Since(result.ExpiresAt).Milliseconds() >= 0 {
-		// entry has expired, delete from map
-		delete(mse.store, key)
+	result, ok, err := mse.getStringLocked(key)
+	if err != nil {
+		return false, "", err
+	}
+	if !ok {
 		return false, "", nil
 	}
 
@@ -59,8 +76,7 @@ func (mse *MapStorageEngine) Exists(keys []string) (int, error) {
 	presentCount := 0
 
 	for _, key := range keys {
-		_, ok := mse.store[key]
-		if ok {
+		if _, ok := mse.getLocked(key); ok {
 			presentCount += 1
 		}
 	}
@@ -68,14 +84,33 @@ func (mse *MapStorageEngine) Exists(keys []string) (int, error) {
 	return presentCount, nil
 }
 
+// Keys returns the keys that have not expired, in sorted order.
+func (mse *MapStorageEngine) Keys() []string {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+	return mse.keysLocked()
+}
+
+// keysLocked is Keys for callers that hold the lock.
+func (mse *MapStorageEngine) keysLocked() []string {
+	keys := []string{}
+	for _, key := range slices.Sorted(maps.Keys(mse.store)) {
+		if _, ok := mse.getLocked(key); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
 func (mse *MapStorageEngine) Delete(keys []string) (int, error) {
 	mse.mu.Lock()
 	defer mse.mu.Unlock()
 
 	deletedCount := 0
 	for _, key := range keys {
-		if _, ok := mse.store[key]; ok {
-			delete(mse.store, key)
+		// expired keys are removed as expired rather than deleted
+		if _, ok := mse.getLocked(key); ok {
+			mse.deleteLocked(key, EVENT_CLASS_GENERIC, "del")
 			deletedCount += 1
 		}
 	}
@@ -83,37 +118,89 @@ func (mse *MapStorageEngine) Delete(keys []string) (int, error) {
 	return deletedCount, nil
 }
 
+// AtomicDelta adds delta to the integer stored at key, keeping its expiry, and returns the new value.
+// A missing key is treated as 0.
 func (mse *MapStorageEngine) AtomicDelta(key string, delta int64) (int64, error) {
 	mse.mu.Lock()
 	defer mse.mu.Unlock()
 
-	valCtr, ok := mse.store[key]
+	entry, ok, err := mse.getStringLocked(key)
+	if err != nil {
+		return -1, err
+	}
 	if !ok {
 		// counter doesn't exist yet, forcefully set it to the delta value and return the same
-		mse.store[key] = DataContainer{Data: fmt.Sprintf("%d", delta), Expires: false, ExpiresAt: time.Now()}
+		mse.putLocked(key, DataContainer{Data: strconv.FormatInt(delta, 10), Expires: false, ExpiresAt: time.Now()})
+		mse.notifyLocked(EVENT_CLASS_STRING, "incrby", key)
 		return delta, nil
 	}
 
 	// counter exists already
-	counterIntVal, err := strconv.ParseInt(valCtr.Data, 10, 64)
+	counterIntVal, err := strconv.ParseInt(entry.Data, 10, 64)
 	if err != nil {
-		return -1, err
+		return -1, ErrNotInteger
+	}
+
+	if (delta > 0 && counterIntVal > math.MaxInt64-delta) || (delta < 0 && counterIntVal < math.MinInt64-delta) {
+		return -1, ErrOverflow
 	}
 
 	// delta the value and set it
 	counterIntVal += delta
-	mse.store[key] = DataContainer{Data: fmt.Sprintf("%d", counterIntVal), Expires: false, ExpiresAt: time.Now()}
-
+	entry.Data = strconv.FormatInt(counterIntVal, 10)
+	mse.storeLocked(key, entry)
+	mse.notifyLocked(EVENT_CLASS_STRING, "incrby", key)
 	return counterIntVal, nil
 }
 
+// AtomicFloatDelta adds delta to the number stored at key, keeping its expiry, and returns the new value
+// as it was stored. A missing key is treated as 0.
+func (mse *MapStorageEngine) AtomicFloatDelta(key string, delta float64) (string, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStringLocked(key)
+	if err != nil {
+		return "", err
+	}
+	current := 0.0
+	if ok {
+		parsed, err := strconv.ParseFloat(entry.Data, 64)
+		if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+			return "", ErrNotFloat
+		}
+		current = parsed
+	}
+
+	result := current + delta
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return "", ErrNaNOrInfinity
+	}
+
+	// the shortest representation that reads back to the same value, without an exponent
+	entry.Data = strconv.FormatFloat(result, 'f', -1, 64)
+	if !ok {
+		entry.ExpiresAt = time.Now()
+	}
+	mse.putLocked(key, entry)
+	mse.notifyLocked(EVENT_CLASS_STRING, "incrbyfloat", key)
+	return entry.Data, nil
+}
+
 func (mse *MapStorageEngine) ListPush(key string, values []string, isPrepend bool) (int64, error) {
 	mse.mu.Lock()
 	defer mse.mu.Unlock()
 
 	numNewValues := len(values)
+	event := "rpush"
+	if isPrepend {
+		event = "lpush"
+	}
 
-	data, ok := mse.store[key]
+	data, ok := mse.getLocked(key)
+	if ok && !data.IsString() {
+		return 0, ErrWrongType
+	}
 	if !ok {
 		// key doesn't exist, fresh list creation
 		valToStore := ""
@@ -129,7 +216,8 @@ func (mse *MapStorageEngine) ListPush(key string, values []string, isPrepend boo
 			// remove final tab
 			valToStore = valToStore[:len(valToStore)-1]
 		}
-		mse.store[key] = DataContainer{Data: valToStore, Expires: false, ExpiresAt: time.Now()}
+		mse.putLocked(key, DataContainer{Data: valToStore, Expires: false, ExpiresAt: time.Now()})
+		mse.notifyLocked(EVENT_CLASS_LIST, event, key)
 
 		return int64(numNewValues), nil
 	}
@@ -149,7 +237,1185 @@ func (mse *MapStorageEngine) ListPush(key string, values []string, isPrepend boo
 		listContents = newContents + listContents
 	}
 
-	mse.store[key] = DataContainer{Data: listContents, Expires: false, ExpiresAt: time.Now()}
+	mse.storeLocked(key, DataContainer{Data: listContents, Expires: false, ExpiresAt: time.Now()})
+	mse.notifyLocked(EVENT_CLASS_LIST, event, key)
 
 	return int64(numNewValues), nil
 }
+
+// getLocked returns the entry for the key, removing it if it has expired. It must be called with the lock held.
+func (mse *MapStorageEngine) getLocked(key string) (DataContainer, bool) {
+	entry, ok := mse.store[key]
+	if !ok {
+		return DataContainer{}, false
+	}
+
+	if entry.Expires && time.Since(entry.ExpiresAt).Milliseconds() >= 0 {
+		mse.deleteLocked(key, EVENT_CLASS_EXPIRED, "expired")
+		return DataContainer{}, false
+	}
+	return entry, true
+}
+
+// getStringLocked is getLocked for operations on strings, failing when the key holds another type of value.
+func (mse *MapStorageEngine) getStringLocked(key string) (DataContainer, bool, error) {
+	entry, ok := mse.getLocked(key)
+	if ok && !entry.IsString() {
+		return DataContainer{}, false, ErrWrongType
+	}
+	return entry, ok, nil
+}
+
+// Append adds the value at the end of the string stored at key, keeping its expiry, and returns the new length.
+func (mse *MapStorageEngine) Append(key string, value string) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, _, err := mse.getStringLocked(key)
+	if err != nil {
+		return 0, err
+	}
+	entry.Data += value
+	mse.putLocked(key, entry)
+	mse.notifyLocked(EVENT_CLASS_STRING, "append", key)
+	return int64(len(entry.Data)), nil
+}
+
+// SetRange overwrites the string stored at key starting at offset, padding it with zero bytes if needed,
+// and returns the new length. A missing key is not created when the value is empty.
+func (mse *MapStorageEngine) SetRange(key string, offset int64, value string) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStringLocked(key)
+	if err != nil {
+		return 0, err
+	}
+	if len(value) == 0 {
+		return int64(len(entry.Data)), nil
+	}
+	if !ok {
+		entry = DataContainer{}
+	}
+
+	contents := []byte(entry.Data)
+	if end := offset + int64(len(value)); end > int64(len(contents)) {
+		contents = append(contents, make([]byte, end-int64(len(contents)))...)
+	}
+	copy(contents[offset:], value)
+
+	entry.Data = string(contents)
+	mse.putLocked(key, entry)
+	mse.notifyLocked(EVENT_CLASS_STRING, "setrange", key)
+	return int64(len(entry.Data)), nil
+}
+
+// GetDel returns the value stored at key and deletes the key.
+func (mse *MapStorageEngine) GetDel(key string) (bool, string, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStringLocked(key)
+	if err != nil {
+		return false, "", err
+	}
+	if !ok {
+		return false, "", nil
+	}
+	mse.deleteLocked(key, EVENT_CLASS_GENERIC, "del")
+	return true, entry.Data, nil
+}
+
+// GetEx returns the value stored at key and, if updateExpiry is set, replaces its expiry.
+// Passing expires as false removes the expiry of the key.
+func (mse *MapStorageEngine) GetEx(key string, updateExpiry bool, expires bool, expiresAtTimeStampMillis int64) (bool, string, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStringLocked(key)
+	if err != nil {
+		return false, "", err
+	}
+	if !ok {
+		return false, "", nil
+	}
+
+	if updateExpiry {
+		entry.Expires = expires
+		entry.ExpiresAt = time.Now()
+		if expires {
+			entry.ExpiresAt = time.UnixMilli(expiresAtTimeStampMillis)
+		}
+		mse.storeLocked(key, entry)
+		if expires {
+			mse.notifyLocked(EVENT_CLASS_GENERIC, "expire", key)
+		} else {
+			mse.notifyLocked(EVENT_CLASS_GENERIC, "persist", key)
+		}
+	}
+	return true, entry.Data, nil
+}
+
+// GetSet stores the value at key, discarding any expiry, and returns the previous value.
+func (mse *MapStorageEngine) GetSet(key string, value string) (bool, string, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStringLocked(key)
+	if err != nil {
+		return false, "", err
+	}
+	mse.putLocked(key, DataContainer{Data: value, Expires: false, ExpiresAt: time.Now()})
+	mse.notifyLocked(EVENT_CLASS_STRING, "set", key)
+	return ok, entry.Data, nil
+}
+
+// SetNX stores the value at key only if the key does not exist and reports whether it was stored.
+func (mse *MapStorageEngine) SetNX(key string, value string, expires bool, expiresAtTimeStampMillis int64) (bool, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	if _, ok := mse.getLocked(key); ok {
+		return false, nil
+	}
+
+	expiryTime := time.Now()
+	if expires {
+		expiryTime = time.UnixMilli(expiresAtTimeStampMillis)
+	}
+	mse.putLocked(key, DataContainer{Data: value, Expires: expires, ExpiresAt: expiryTime})
+	mse.notifyLocked(EVENT_CLASS_STRING, "set", key)
+	if expires {
+		mse.notifyLocked(EVENT_CLASS_GENERIC, "expire", key)
+	}
+	return true, nil
+}
+
+// MGet returns the values of all the keys, read under a single lock.
+func (mse *MapStorageEngine) MGet(keys []string) ([]bool, []string, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	found := make([]bool, len(keys))
+	values := make([]string, len(keys))
+	for idx, key := range keys {
+		// keys holding other types of values are reported as missing
+		entry, ok := mse.getLocked(key)
+		found[idx] = ok && entry.IsString()
+		if found[idx] {
+			values[idx] = entry.Data
+		}
+	}
+	return found, values, nil
+}
+
+// MSet stores every value at the key with the same index, discarding any expiry. All the keys are set at once.
+func (mse *MapStorageEngine) MSet(keys []string, values []string) error {
+	if len(keys) != len(values) {
+		return fmt.Errorf("expected as many values as keys")
+	}
+
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	mse.msetLocked(keys, values)
+	return nil
+}
+
+// MSetNX behaves like MSet when none of the keys exist, and sets nothing otherwise.
+func (mse *MapStorageEngine) MSetNX(keys []string, values []string) (bool, error) {
+	if len(keys) != len(values) {
+		return false, fmt.Errorf("expected as many values as keys")
+	}
+
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	for _, key := range keys {
+		if _, ok := mse.getLocked(key); ok {
+			return false, nil
+		}
+	}
+
+	mse.msetLocked(keys, values)
+	return true, nil
+}
+
+func (mse *MapStorageEngine) msetLocked(keys []string, values []string) {
+	now := time.Now()
+	for idx, key := range keys {
+		mse.putLocked(key, DataContainer{Data: values[idx], Expires: false, ExpiresAt: now})
+		mse.notifyLocked(EVENT_CLASS_STRING, "set", key)
+	}
+}
+
+// SetBit sets or clears the bit at offset in the string stored at key, zero-extending it as needed,
+// and returns the previous value of the bit.
+func (mse *MapStorageEngine) SetBit(key string, offset int64, value bool) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStringLocked(key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		entry = DataContainer{ExpiresAt: time.Now()}
+	}
+
+	contents := padTo([]byte(entry.Data), offset/8+1)
+	previous := getBit(contents, offset)
+	setBit(contents, offset, value)
+
+	entry.Data = string(contents)
+	mse.putLocked(key, entry)
+	mse.notifyLocked(EVENT_CLASS_STRING, "setbit", key)
+	return previous, nil
+}
+
+// GetBit returns the bit at offset in the string stored at key. Bits past the end of the string are 0.
+func (mse *MapStorageEngine) GetBit(key string, offset int64) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, _, err := mse.getStringLocked(key)
+	if err != nil {
+		return 0, err
+	}
+	return getBit([]byte(entry.Data), offset), nil
+}
+
+// BitCount returns the number of set bits of the string stored at key within the range.
+func (mse *MapStorageEngine) BitCount(key string, rng BitRange) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, _, err := mse.getStringLocked(key)
+	if err != nil {
+		return 0, err
+	}
+	start, end, ok := rng.resolve(int64(len(entry.Data)))
+	if !ok {
+		return 0, nil
+	}
+	return bitCount([]byte(entry.Data), start, end), nil
+}
+
+// BitPos returns the position of the first bit with the given value in the string stored at key within the range,
+// or -1 if there is none. When looking for a clear bit without an end to the range, the string is considered
+// to be padded with zeros on the right.
+func (mse *MapStorageEngine) BitPos(key string, bit bool, rng BitRange) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStringLocked(key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		if bit {
+			return -1, nil
+		}
+		return 0, nil
+	}
+
+	start, end, ok := rng.resolve(int64(len(entry.Data)))
+	if !ok {
+		return -1, nil
+	}
+
+	pos := bitPos([]byte(entry.Data), bit, start, end)
+	if pos == -1 && !bit && !rng.HasEnd {
+		return end + 1, nil
+	}
+	return pos, nil
+}
+
+// BitOp stores the result of the bitwise operation between the strings stored at the keys in destKey
+// and returns its length. Missing keys are treated as empty strings and an empty result deletes destKey.
+func (mse *MapStorageEngine) BitOp(op string, destKey string, keys []string) (int64, error) {
+	if op == BITOP_NOT && len(keys) != 1 {
+		return 0, fmt.Errorf("expected a single key for %s", BITOP_NOT)
+	}
+
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	values := make([]string, len(keys))
+	for idx, key := range keys {
+		entry, _, err := mse.getStringLocked(key)
+		if err != nil {
+			return 0, err
+		}
+		values[idx] = entry.Data
+	}
+
+	result := bitOp(op, values)
+	if len(result) == 0 {
+		mse.deleteLocked(destKey, EVENT_CLASS_GENERIC, "del")
+		return 0, nil
+	}
+
+	mse.putLocked(destKey, DataContainer{Data: string(result), Expires: false, ExpiresAt: time.Now()})
+	mse.notifyLocked(EVENT_CLASS_STRING, "set", destKey)
+	return int64(len(result)), nil
+}
+
+// BitField runs the operations in order on the string stored at key, zero-extending it for writes,
+// and returns the result of each operation. A missing key is only created by writes.
+func (mse *MapStorageEngine) BitField(key string, ops []BitFieldOp) ([]BitFieldResult, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStringLocked(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		entry = DataContainer{ExpiresAt: time.Now()}
+	}
+
+	contents, results := bitField([]byte(entry.Data), ops)
+	if ok || len(contents) > 0 {
+		entry.Data = string(contents)
+		mse.putLocked(key, entry)
+		for idx, op := range ops {
+			if op.Kind != BITFIELD_GET && !results[idx].Failed {
+				mse.notifyLocked(EVENT_CLASS_STRING, "setbit", key)
+				break
+			}
+		}
+	}
+	return results, nil
+}
+
+// PFAdd adds the elements to the hyperloglog stored at key, creating it if needed, and reports whether
+// the hyperloglog was created or any of its registers changed.
+func (mse *MapStorageEngine) PFAdd(key string, elements []string) (bool, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStringLocked(key)
+	if err != nil {
+		return false, err
+	}
+	hll := newHyperLogLog()
+	if ok {
+		decoded, err := decodeHyperLogLog(entry.Data)
+		if err != nil {
+			return false, err
+		}
+		hll = decoded
+	} else {
+		entry = DataContainer{ExpiresAt: time.Now()}
+	}
+
+	changed := false
+	for _, element := range elements {
+		if hll.add(element) {
+			changed = true
+		}
+	}
+	if ok && !changed {
+		return false, nil
+	}
+
+	// a newly created hyperloglog without any element has a valid cardinality of 0
+	entry.Data = hll.encode(0, !changed)
+	mse.putLocked(key, entry)
+	mse.notifyLocked(EVENT_CLASS_STRING, "pfadd", key)
+	return true, nil
+}
+
+// PFCount returns the estimated cardinality of the union of the hyperloglogs stored at the keys.
+// Missing keys are treated as empty hyperloglogs. The cardinality of a single key is cached in its header.
+func (mse *MapStorageEngine) PFCount(keys []string) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	if len(keys) == 1 {
+		entry, ok, err := mse.getStringLocked(keys[0])
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, nil
+		}
+		hll, err := decodeHyperLogLog(entry.Data)
+		if err != nil {
+			return 0, err
+		}
+		if card, valid := cachedCardinality(entry.Data); valid {
+			return card, nil
+		}
+
+		card := hll.count()
+		entry.Data = withCachedCardinality(entry.Data, card)
+		mse.store[keys[0]] = entry
+		return card, nil
+	}
+
+	union, err := mse.mergeHyperLogLogsLocked(keys)
+	if err != nil {
+		return 0, err
+	}
+	return union.count(), nil
+}
+
+// PFMerge stores the union of the hyperloglogs stored at destKey and the keys in destKey, keeping its expiry.
+func (mse *MapStorageEngine) PFMerge(destKey string, keys []string) error {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	union, err := mse.mergeHyperLogLogsLocked(append([]string{destKey}, keys...))
+	if err != nil {
+		return err
+	}
+
+	entry, ok := mse.getLocked(destKey)
+	if !ok {
+		entry = DataContainer{ExpiresAt: time.Now()}
+	}
+	entry.Data = union.encode(0, false)
+	mse.putLocked(destKey, entry)
+	mse.notifyLocked(EVENT_CLASS_STRING, "pfadd", destKey)
+	return nil
+}
+
+// mergeHyperLogLogsLocked returns the union of the hyperloglogs stored at the keys, skipping missing keys.
+// It must be called with the lock held.
+func (mse *MapStorageEngine) mergeHyperLogLogsLocked(keys []string) (*hyperLogLog, error) {
+	union := newHyperLogLog()
+	for _, key := range keys {
+		entry, ok, err := mse.getStringLocked(key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		hll, err := decodeHyperLogLog(entry.Data)
+		if err != nil {
+			return nil, err
+		}
+		union.merge(hll)
+	}
+	return union, nil
+}
+
+// getGeoLocked returns the geo index stored at key, failing when the key holds another type of value.
+// It must be called with the lock held.
+func (mse *MapStorageEngine) getGeoLocked(key string) (DataContainer, bool, error) {
+	entry, ok := mse.getLocked(key)
+	if ok && entry.Geo == nil {
+		return DataContainer{}, false, ErrWrongType
+	}
+	return entry, ok, nil
+}
+
+// GeoAdd adds the members with their geohash scores to the geo index stored at key, creating it if needed.
+// Existing members are only updated when onlyIfNotExists is not set, and new members are only added when
+// onlyIfExists is not set. It returns the number of members added, plus the number of members whose score
+// changed when countChanged is set.
+func (mse *MapStorageEngine) GeoAdd(key string, members []string, scores []uint64, onlyIfNotExists bool, onlyIfExists bool, countChanged bool) (int64, error) {
+	if len(members) != len(scores) {
+		return 0, fmt.Errorf("expected as many scores as members")
+	}
+
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getGeoLocked(key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		entry = DataContainer{Geo: newGeoIndex(), ExpiresAt: time.Now()}
+	}
+
+	count, modified := int64(0), false
+	for idx, member := range members {
+		_, exists := entry.Geo.scores[member]
+		if (exists && onlyIfNotExists) || (!exists && onlyIfExists) {
+			continue
+		}
+		added, changed := entry.Geo.set(member, float64(scores[idx]))
+		if added || (changed && countChanged) {
+			count++
+		}
+		modified = modified || added || changed
+	}
+
+	if entry.Geo.Len() > 0 {
+		mse.putLocked(key, entry)
+	}
+	if modified {
+		mse.notifyLocked(EVENT_CLASS_ZSET, "zadd", key)
+	}
+	return count, nil
+}
+
+// GeoScores returns the geohash scores of the members of the geo index stored at key.
+func (mse *MapStorageEngine) GeoScores(key string, members []string) ([]bool, []uint64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getGeoLocked(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found := make([]bool, len(members))
+	scores := make([]uint64, len(members))
+	if !ok {
+		return found, scores, nil
+	}
+	for idx, member := range members {
+		score, ok := entry.Geo.scores[member]
+		scores[idx], found[idx] = uint64(score), ok
+	}
+	return found, scores, nil
+}
+
+// GeoSearch returns the members of the geo index stored at key that are within the area of the query.
+func (mse *MapStorageEngine) GeoSearch(key string, query GeoQuery) ([]GeoMatch, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	return mse.geoSearchLocked(key, query)
+}
+
+// GeoSearchStore stores the members found by GeoSearch in a new geo index at destKey and returns their number.
+// the members keep their geohash scores, unless distUnit is positive in which case their score is their
+// distance in that unit, given in meters. destKey is deleted when nothing is found.
+func (mse *MapStorageEngine) GeoSearchStore(destKey string, key string, query GeoQuery, distUnit float64) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	matches, err := mse.geoSearchLocked(key, query)
+	if err != nil {
+		return 0, err
+	}
+	if len(matches) == 0 {
+		mse.deleteLocked(destKey, EVENT_CLASS_GENERIC, "del")
+		return 0, nil
+	}
+
+	index := newGeoIndex()
+	for _, match := range matches {
+		if distUnit > 0 {
+			index.set(match.Member, match.Distance/distUnit)
+		} else {
+			index.set(match.Member, float64(match.Score))
+		}
+	}
+	mse.putLocked(destKey, DataContainer{Geo: index, Expires: false, ExpiresAt: time.Now()})
+	mse.notifyLocked(EVENT_CLASS_ZSET, "geosearchstore", destKey)
+	return int64(len(matches)), nil
+}
+
+// geoSearchLocked resolves the center of the query and runs it. It must be called with the lock held.
+func (mse *MapStorageEngine) geoSearchLocked(key string, query GeoQuery) ([]GeoMatch, error) {
+	entry, ok, err := mse.getGeoLocked(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []GeoMatch{}, nil
+	}
+
+	if query.FromMember {
+		score, exists := entry.Geo.scores[query.Member]
+		if !exists {
+			return nil, ErrGeoMemberNotFound
+		}
+		query.Long, query.Lat = GeoDecode(uint64(score))
+	}
+	return entry.Geo.search(query), nil
+}
+
+// getStreamLocked returns the stream stored at key, failing when the key holds another type of value.
+// It must be called with the lock held.
+func (mse *MapStorageEngine) getStreamLocked(key string) (DataContainer, bool, error) {
+	entry, ok := mse.getLocked(key)
+	if ok && entry.Stream == nil {
+		return DataContainer{}, false, ErrWrongType
+	}
+	return entry, ok, nil
+}
+
+// XAdd appends an entry with the fields to the stream stored at key and trims the stream afterwards.
+// The stream is created if needed, unless noMkStream is set, in which case false is returned for a missing stream.
+func (mse *MapStorageEngine) XAdd(key string, id StreamAddID, fields []string, noMkStream bool, trim StreamTrim) (StreamID, bool, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStreamLocked(key)
+	if err != nil {
+		return StreamID{}, false, err
+	}
+	if !ok {
+		if noMkStream {
+			return StreamID{}, false, nil
+		}
+		entry = DataContainer{Stream: newStream(), ExpiresAt: time.Now()}
+	}
+
+	newID, err := entry.Stream.nextID(id, time.Now())
+	if err != nil {
+		return StreamID{}, false, err
+	}
+	entry.Stream.add(newID, fields)
+	trimmed := entry.Stream.trim(trim)
+	mse.putLocked(key, entry)
+	mse.notifyLocked(EVENT_CLASS_STREAM, "xadd", key)
+	if trimmed > 0 {
+		mse.notifyLocked(EVENT_CLASS_STREAM, "xtrim", key)
+	}
+
+	mse.signalWaitersLocked(key)
+	return newID, true, nil
+}
+
+// signalWaitersLocked wakes up the clients blocked on the stream stored at key.
+// It must be called with the lock held.
+func (mse *MapStorageEngine) signalWaitersLocked(key string) {
+	for waiter := range mse.waiters[key] {
+		select {
+		case waiter <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// XLen returns the number of entries of the stream stored at key.
+func (mse *MapStorageEngine) XLen(key string) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStreamLocked(key)
+	if err != nil || !ok {
+		return 0, err
+	}
+	return int64(entry.Stream.Len()), nil
+}
+
+// XRange returns the entries of the stream stored at key with IDs between start and end, both inclusive.
+// At most count entries are returned when count is positive, and reverse returns them from end to start.
+func (mse *MapStorageEngine) XRange(key string, start StreamID, end StreamID, count int, reverse bool) ([]StreamEntry, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStreamLocked(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []StreamEntry{}, nil
+	}
+	return entry.Stream.rangeEntries(start, end, count, reverse), nil
+}
+
+// XTrim trims the stream stored at key and returns the number of entries removed.
+func (mse *MapStorageEngine) XTrim(key string, trim StreamTrim) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStreamLocked(key)
+	if err != nil || !ok {
+		return 0, err
+	}
+	trimmed := entry.Stream.trim(trim)
+	if trimmed > 0 {
+		mse.notifyLocked(EVENT_CLASS_STREAM, "xtrim", key)
+	}
+	return trimmed, nil
+}
+
+// XDel removes the entries with the given IDs from the stream stored at key and returns how many existed.
+func (mse *MapStorageEngine) XDel(key string, ids []StreamID) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStreamLocked(key)
+	if err != nil || !ok {
+		return 0, err
+	}
+	deleted := entry.Stream.delete(ids)
+	if deleted > 0 {
+		mse.notifyLocked(EVENT_CLASS_STREAM, "xdel", key)
+	}
+	return deleted, nil
+}
+
+// XRead returns, for each stream, the entries with IDs greater than the matching ID of after.
+// At most count entries are returned per stream when count is positive. Missing streams have no entries.
+func (mse *MapStorageEngine) XRead(keys []string, after []StreamID, count int) ([][]StreamEntry, error) {
+	if len(keys) != len(after) {
+		return nil, fmt.Errorf("expected as many IDs as keys")
+	}
+
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	results := make([][]StreamEntry, len(keys))
+	for idx, key := range keys {
+		entry, ok, err := mse.getStreamLocked(key)
+		if err != nil {
+			return nil, err
+		}
+		start, hasNext := after[idx].Next()
+		if !ok || !hasNext {
+			results[idx] = []StreamEntry{}
+			continue
+		}
+		results[idx] = entry.Stream.rangeEntries(start, MAX_STREAM_ID, count, false)
+	}
+	return results, nil
+}
+
+// StreamLastIDs returns the last ID added to each stream, which is 0-0 for missing streams.
+func (mse *MapStorageEngine) StreamLastIDs(keys []string) ([]StreamID, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	ids := make([]StreamID, len(keys))
+	for idx, key := range keys {
+		entry, ok, err := mse.getStreamLocked(key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ids[idx] = entry.Stream.LastID()
+		}
+	}
+	return ids, nil
+}
+
+// WatchStreams returns a channel that is signalled whenever an entry is added to one of the streams,
+// along with a function that stops watching them and must be called once the caller is done.
+func (mse *MapStorageEngine) WatchStreams(keys []string) (<-chan struct{}, func()) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	waiter := make(chan struct{}, 1)
+	for _, key := range keys {
+		if mse.waiters[key] == nil {
+			mse.waiters[key] = make(map[chan struct{}]struct{})
+		}
+		mse.waiters[key][waiter] = struct{}{}
+	}
+
+	stop := func() {
+		mse.mu.Lock()
+		defer mse.mu.Unlock()
+
+		for _, key := range keys {
+			delete(mse.waiters[key], waiter)
+			if len(mse.waiters[key]) == 0 {
+				delete(mse.waiters, key)
+			}
+		}
+	}
+	return waiter, stop
+}
+
+// getGroupLocked returns the stream stored at key along with the given consumer group.
+// It must be called with the lock held.
+func (mse *MapStorageEngine) getGroupLocked(key string, group string) (*Stream, *streamGroup, error) {
+	entry, ok, err := mse.getStreamLocked(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, ErrNoSuchKey
+	}
+	cg, ok := entry.Stream.group(group)
+	if !ok {
+		return nil, nil, &NoGroupError{Key: key, Group: group}
+	}
+	return entry.Stream, cg, nil
+}
+
+// XGroupCreate creates a consumer group that has read the entries of the stream up to id, or up to the last
+// entry when useLastID is set. The stream is created when it is missing and mkStream is set.
+func (mse *MapStorageEngine) XGroupCreate(key string, group string, id StreamID, useLastID bool, mkStream bool, entriesRead int64) error {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStreamLocked(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if !mkStream {
+			return ErrNoSuchKey
+		}
+		entry = DataContainer{Stream: newStream(), ExpiresAt: time.Now()}
+	}
+
+	if useLastID {
+		id = entry.Stream.LastID()
+	}
+	if !entry.Stream.createGroup(group, id, entriesRead) {
+		return ErrBusyGroup
+	}
+	mse.putLocked(key, entry)
+	mse.notifyLocked(EVENT_CLASS_STREAM, "xgroup-create", key)
+	return nil
+}
+
+// XGroupSetID sets the last entry read by the consumer group to id, or to the last entry when useLastID is set.
+func (mse *MapStorageEngine) XGroupSetID(key string, group string, id StreamID, useLastID bool, entriesRead int64) error {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	stream, cg, err := mse.getGroupLocked(key, group)
+	if err != nil {
+		return err
+	}
+	if useLastID {
+		id = stream.LastID()
+	}
+	cg.lastID = id
+	cg.entriesRead = entriesRead
+	mse.notifyLocked(EVENT_CLASS_STREAM, "xgroup-setid", key)
+	return nil
+}
+
+// XGroupDestroy removes the consumer group along with its pending entries and reports whether it existed.
+func (mse *MapStorageEngine) XGroupDestroy(key string, group string) (bool, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	stream, _, err := mse.getGroupLocked(key, group)
+	if errors.Is(err, ErrNoGroup) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	delete(stream.groups, group)
+	mse.notifyLocked(EVENT_CLASS_STREAM, "xgroup-destroy", key)
+	return true, nil
+}
+
+// XGroupCreateConsumer adds a consumer to the group and reports whether it is new.
+func (mse *MapStorageEngine) XGroupCreateConsumer(key string, group string, consumer string) (bool, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	_, cg, err := mse.getGroupLocked(key, group)
+	if err != nil {
+		return false, err
+	}
+	_, exists := cg.consumers[consumer]
+	mse.consumerLocked(key, cg, consumer, time.Now())
+	return !exists, nil
+}
+
+// XGroupDelConsumer removes a consumer from the group and returns the number of entries that were pending for it,
+// which are no longer pending for the group either.
+func (mse *MapStorageEngine) XGroupDelConsumer(key string, group string, consumer string) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	_, cg, err := mse.getGroupLocked(key, group)
+	if err != nil {
+		return 0, err
+	}
+	cons, ok := cg.consumers[consumer]
+	if !ok {
+		return 0, nil
+	}
+
+	pending := int64(len(cons.pending))
+	for id := range cons.pending {
+		delete(cg.pending, id)
+	}
+	delete(cg.consumers, consumer)
+	mse.notifyLocked(EVENT_CLASS_STREAM, "xgroup-delconsumer", key)
+	return pending, nil
+}
+
+// XReadGroup reads the streams on behalf of a consumer of the group. The streams for which newOnly is set get the
+// entries never delivered to the group, which become pending for the consumer unless noAck is set. The other streams
+// get the entries pending for the consumer with IDs greater than the matching ID of after.
+func (mse *MapStorageEngine) XReadGroup(keys []string, group string, consumer string, after []StreamID, newOnly []bool, count int, noAck bool) ([][]StreamEntry, error) {
+	if len(keys) != len(after) || len(keys) != len(newOnly) {
+		return nil, fmt.Errorf("expected as many IDs as keys")
+	}
+
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	// the groups are checked first, so that nothing is delivered when one of them is missing
+	streams := make([]*Stream, len(keys))
+	groups := make([]*streamGroup, len(keys))
+	for idx, key := range keys {
+		stream, cg, err := mse.getGroupLocked(key, group)
+		if errors.Is(err, ErrNoSuchKey) {
+			err = &NoGroupError{Key: key, Group: group}
+		}
+		if err != nil {
+			return nil, err
+		}
+		streams[idx], groups[idx] = stream, cg
+	}
+
+	now := time.Now()
+	results := make([][]StreamEntry, len(keys))
+	for idx, stream := range streams {
+		cons := mse.consumerLocked(keys[idx], groups[idx], consumer, now)
+		if newOnly[idx] {
+			results[idx] = stream.readNew(groups[idx], cons, count, noAck, now)
+		} else {
+			results[idx] = stream.readHistory(cons, after[idx], count, now)
+		}
+	}
+	return results, nil
+}
+
+// XAck acknowledges the entries for the consumer group and returns how many of them were pending.
+func (mse *MapStorageEngine) XAck(key string, group string, ids []StreamID) (int64, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	_, cg, err := mse.getGroupLocked(key, group)
+	if errors.Is(err, ErrNoSuchKey) || errors.Is(err, ErrNoGroup) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	acked := int64(0)
+	for _, id := range ids {
+		if cg.ack(id) {
+			acked++
+		}
+	}
+	return acked, nil
+}
+
+// XPending summarises the entries pending for the consumer group.
+func (mse *MapStorageEngine) XPending(key string, group string) (StreamPendingSummary, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	_, cg, err := mse.getGroupLocked(key, group)
+	if err != nil {
+		return StreamPendingSummary{}, err
+	}
+
+	summary := StreamPendingSummary{Count: int64(len(cg.pending)), Consumers: []StreamConsumerPending{}}
+	if len(cg.pending) == 0 {
+		return summary, nil
+	}
+	ids := sortedIDs(cg.pending)
+	summary.First, summary.Last = ids[0], ids[len(ids)-1]
+	for _, info := range cg.consumerInfos(false) {
+		if info.Pending > 0 {
+			summary.Consumers = append(summary.Consumers, StreamConsumerPending{Name: info.Name, Count: info.Pending})
+		}
+	}
+	return summary, nil
+}
+
+// XPendingRange returns the entries pending for the consumer group, or for one of its consumers, that match the query.
+func (mse *MapStorageEngine) XPendingRange(key string, group string, query StreamPendingQuery) ([]StreamPendingEntry, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	_, cg, err := mse.getGroupLocked(key, group)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := cg.pending
+	if query.Consumer != "" {
+		cons, ok := cg.consumers[query.Consumer]
+		if !ok {
+			return []StreamPendingEntry{}, nil
+		}
+		pending = cons.pending
+	}
+
+	now := time.Now()
+	entries := []StreamPendingEntry{}
+	for _, entry := range pendingEntries(pending) {
+		if len(entries) == query.Count {
+			break
+		}
+		if entry.ID.Compare(query.Start) < 0 || entry.ID.Compare(query.End) > 0 || now.Sub(entry.DeliveryTime) < query.MinIdle {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// XClaim transfers the pending entries that have been idle for at least minIdle to the consumer and returns them.
+// Pending entries that were deleted from the stream are no longer pending, and are not returned.
+func (mse *MapStorageEngine) XClaim(key string, group string, consumer string, minIdle time.Duration, ids []StreamID, opts StreamClaimOptions) ([]StreamEntry, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	stream, cg, err := mse.getGroupLocked(key, group)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deliveryTime := opts.DeliveryTime
+	if deliveryTime.IsZero() {
+		deliveryTime = now
+	}
+	if opts.LastID.Compare(cg.lastID) > 0 {
+		cg.lastID = opts.LastID
+	}
+
+	cons := mse.consumerLocked(key, cg, consumer, now)
+	claimed := []StreamEntry{}
+	for _, id := range ids {
+		entry, exists := stream.lookup(id)
+		_, pending := cg.pending[id]
+		forced := false
+		switch {
+		case !pending && opts.Force && exists:
+			cg.pending[id] = &streamNACK{deliveryTime: now}
+			forced = true
+		case !pending:
+			continue
+		case !exists:
+			cg.ack(id)
+			continue
+		}
+		if !forced && now.Sub(cg.pending[id].deliveryTime) < minIdle {
+			continue
+		}
+
+		nack := cg.claim(id, cons, deliveryTime)
+		if opts.HasRetryCount {
+			nack.deliveryCount = opts.RetryCount
+		} else if !opts.JustID {
+			nack.deliveryCount++
+		}
+		claimed = append(claimed, entry)
+	}
+	if len(claimed) > 0 {
+		cons.activeTime = now
+	}
+	return claimed, nil
+}
+
+// XAutoClaim scans the pending entries of the consumer group from start and transfers the ones that have been
+// idle for at least minIdle to the consumer, at most count of them. It returns the ID to continue the scan from,
+// which is 0-0 once the scan is complete, the entries claimed and the IDs of the pending entries that were
+// deleted from the stream, which are no longer pending.
+func (mse *MapStorageEngine) XAutoClaim(key string, group string, consumer string, minIdle time.Duration, start StreamID, count int, justID bool) (StreamID, []StreamEntry, []StreamID, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	stream, cg, err := mse.getGroupLocked(key, group)
+	if err != nil {
+		return StreamID{}, nil, nil, err
+	}
+
+	now := time.Now()
+	cons := mse.consumerLocked(key, cg, consumer, now)
+	claimed, deleted := []StreamEntry{}, []StreamID{}
+	// like redis, at most 10 entries are examined for each entry that may be claimed
+	attempts := count * 10
+	ids := sortedIDs(cg.pending)
+	idx, _ := slices.BinarySearchFunc(ids, start, StreamID.Compare)
+	for ; idx < len(ids) && attempts > 0 && count > 0; idx++ {
+		attempts--
+		id := ids[idx]
+		entry, exists := stream.lookup(id)
+		if !exists {
+			cg.ack(id)
+			deleted = append(deleted, id)
+			count--
+			continue
+		}
+		if now.Sub(cg.pending[id].deliveryTime) < minIdle {
+			continue
+		}
+
+		nack := cg.claim(id, cons, now)
+		if !justID {
+			nack.deliveryCount++
+		}
+		claimed = append(claimed, entry)
+		count--
+	}
+	if len(claimed) > 0 {
+		cons.activeTime = now
+	}
+
+	next := MIN_STREAM_ID
+	if idx < len(ids) {
+		next = ids[idx]
+	}
+	return next, claimed, deleted, nil
+}
+
+// XInfoStream describes the stream stored at key. full adds the entries, at most count of them when count is
+// positive, and the details of the consumer groups.
+func (mse *MapStorageEngine) XInfoStream(key string, full bool, count int) (StreamInfo, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	entry, ok, err := mse.getStreamLocked(key)
+	if err != nil {
+		return StreamInfo{}, err
+	}
+	if !ok {
+		return StreamInfo{}, ErrNoSuchKey
+	}
+
+	stream := entry.Stream
+	info := StreamInfo{
+		Length:       int64(stream.Len()),
+		Nodes:        int64((stream.Len() + STREAM_NODE_MAX_ENTRIES - 1) / STREAM_NODE_MAX_ENTRIES),
+		LastID:       stream.LastID(),
+		MaxDeletedID: stream.maxDeletedID,
+		EntriesAdded: int64(stream.entriesAdded),
+		FirstID:      stream.firstID(),
+		Groups:       []StreamGroupInfo{},
+	}
+	if stream.Len() > 0 {
+		first, last := stream.entries[0], stream.entries[stream.Len()-1]
+		info.First, info.Last = &first, &last
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(stream.groups)) {
+		info.Groups = append(info.Groups, stream.groupInfo(name, stream.groups[name], full))
+	}
+	if full {
+		info.Entries = stream.rangeEntries(MIN_STREAM_ID, MAX_STREAM_ID, count, false)
+	}
+	return info, nil
+}
+
+// XInfoGroups describes the consumer groups of the stream stored at key, ordered by name.
+func (mse *MapStorageEngine) XInfoGroups(key string) ([]StreamGroupInfo, error) {
+	info, err := mse.XInfoStream(key, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	return info.Groups, nil
+}
+
+// XInfoConsumers describes the consumers of the group, ordered by name.
+func (mse *MapStorageEngine) XInfoConsumers(key string, group string) ([]StreamConsumerInfo, error) {
+	mse.mu.Lock()
+	defer mse.mu.Unlock()
+
+	_, cg, err := mse.getGroupLocked(key, group)
+	if err != nil {
+		return nil, err
+	}
+	return cg.consumerInfos(false), nil
+}
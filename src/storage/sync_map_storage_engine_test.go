@@ -1,6 +1,11 @@
 package storage_test
 
 import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
 	"testing"
 	"time"
 
@@ -106,3 +111,764 @@ func TestMapStorageEngine(t *testing.T) {
 	assert.False(ok)
 	assert.Empty(val)
 }
+
+func TestMapStorageEngineStringOperations(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+
+	length, err := mse.Append("str", "abc")
+	require.Nil(err)
+	assert.Equal(int64(3), length)
+
+	length, err = mse.SetRange("str", 5, "xy")
+	require.Nil(err)
+	assert.Equal(int64(7), length)
+	_, val, _ := mse.Get("str")
+	assert.Equal("abc\x00\x00xy", val)
+
+	stored, err := mse.SetNX("str", "other", false, 0)
+	require.Nil(err)
+	assert.False(stored)
+
+	ok, old, err := mse.GetSet("str", "new")
+	require.Nil(err)
+	assert.True(ok)
+	assert.Equal("abc\x00\x00xy", old)
+
+	// expiry is kept by Append and removed by GetEx without a new expiry
+	require.Nil(mse.Set("ttl", "a", true, time.Now().UnixMilli()+5))
+	_, err = mse.Append("ttl", "b")
+	require.Nil(err)
+	ok, val, err = mse.GetEx("ttl", true, false, 0)
+	require.Nil(err)
+	assert.True(ok)
+	assert.Equal("ab", val)
+	time.Sleep(6 * time.Millisecond)
+	ok, _, _ = mse.Get("ttl")
+	assert.True(ok)
+
+	ok, val, err = mse.GetDel("ttl")
+	require.Nil(err)
+	assert.True(ok)
+	assert.Equal("ab", val)
+	ok, _, _ = mse.GetDel("ttl")
+	assert.False(ok)
+}
+
+func TestMapStorageEngineBatchOperations(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+
+	require.Nil(mse.MSet([]string{"k1", "k2"}, []string{"v1", "v2"}))
+	found, values, err := mse.MGet([]string{"k1", "absent", "k2"})
+	require.Nil(err)
+	assert.Equal([]bool{true, false, true}, found)
+	assert.Equal([]string{"v1", "", "v2"}, values)
+
+	stored, err := mse.MSetNX([]string{"k3", "k1"}, []string{"v3", "other"})
+	require.Nil(err)
+	assert.False(stored)
+	found, _, _ = mse.MGet([]string{"k3"})
+	assert.Equal([]bool{false}, found)
+
+	stored, err = mse.MSetNX([]string{"k3", "k4"}, []string{"v3", "v4"})
+	require.Nil(err)
+	assert.True(stored)
+
+	assert.NotNil(mse.MSet([]string{"k1"}, []string{}))
+}
+
+func TestMapStorageEngineCounterOperations(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+
+	require.Nil(mse.Set("ctr", "9223372036854775806", false, 0))
+	res, err := mse.AtomicDelta("ctr", 1)
+	require.Nil(err)
+	assert.Equal(int64(math.MaxInt64), res)
+	_, err = mse.AtomicDelta("ctr", 1)
+	assert.ErrorIs(err, storage.ErrOverflow)
+
+	require.Nil(mse.Set("str", "abc", false, 0))
+	_, err = mse.AtomicDelta("str", 1)
+	assert.ErrorIs(err, storage.ErrNotInteger)
+	_, err = mse.AtomicFloatDelta("str", 1)
+	assert.ErrorIs(err, storage.ErrNotFloat)
+
+	flt, err := mse.AtomicFloatDelta("flt", 0.25)
+	require.Nil(err)
+	assert.Equal("0.25", flt)
+	_, err = mse.AtomicFloatDelta("flt", math.MaxFloat64)
+	require.Nil(err)
+	_, err = mse.AtomicFloatDelta("flt", math.MaxFloat64)
+	assert.ErrorIs(err, storage.ErrNaNOrInfinity)
+
+	// counters keep their expiry
+	require.Nil(mse.Set("ttl", "1", true, time.Now().UnixMilli()+5))
+	_, err = mse.AtomicDelta("ttl", 1)
+	require.Nil(err)
+	time.Sleep(6 * time.Millisecond)
+	ok, _, _ := mse.Get("ttl")
+	assert.False(ok)
+}
+
+func TestMapStorageEngineBitOperations(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+
+	// setting a bit zero-extends the string and keeps its expiry
+	require.Nil(mse.Set("bits", "", true, time.Now().UnixMilli()+5))
+	previous, err := mse.SetBit("bits", 9, true)
+	require.Nil(err)
+	assert.Equal(int64(0), previous)
+	_, val, _ := mse.Get("bits")
+	assert.Equal("\x00\x40", val)
+	bit, err := mse.GetBit("bits", 9)
+	require.Nil(err)
+	assert.Equal(int64(1), bit)
+	time.Sleep(6 * time.Millisecond)
+	ok, _, _ := mse.Get("bits")
+	assert.False(ok)
+
+	require.Nil(mse.Set("str", "\x0f\xf0", false, 0))
+	count, err := mse.BitCount("str", storage.BitRange{Start: 2, End: 9, HasStart: true, HasEnd: true, IsBit: true})
+	require.Nil(err)
+	assert.Equal(int64(6), count)
+	pos, err := mse.BitPos("str", true, storage.BitRange{Start: 1, HasStart: true})
+	require.Nil(err)
+	assert.Equal(int64(8), pos)
+	pos, err = mse.BitPos("str", false, storage.BitRange{Start: 1, HasStart: true})
+	require.Nil(err)
+	assert.Equal(int64(12), pos)
+
+	length, err := mse.BitOp(storage.BITOP_OR, "dest", []string{"str", "missing"})
+	require.Nil(err)
+	assert.Equal(int64(2), length)
+	_, err = mse.BitOp(storage.BITOP_NOT, "dest", []string{"str", "missing"})
+	assert.NotNil(err)
+
+	results, err := mse.BitField("field", []storage.BitFieldOp{
+		{Kind: storage.BITFIELD_GET, Bits: 8},
+		{Kind: storage.BITFIELD_SET, Bits: 4, Offset: 4, Value: 17, Overflow: storage.BITFIELD_OVERFLOW_WRAP},
+		{Kind: storage.BITFIELD_INCRBY, Signed: true, Bits: 4, Offset: 4, Value: 7, Overflow: storage.BITFIELD_OVERFLOW_SAT},
+		{Kind: storage.BITFIELD_INCRBY, Bits: 8, Value: 1000, Overflow: storage.BITFIELD_OVERFLOW_FAIL},
+	})
+	require.Nil(err)
+	assert.Equal([]storage.BitFieldResult{{Value: 0}, {Value: 0}, {Value: 7}, {Failed: true}}, results)
+	_, val, _ = mse.Get("field")
+	assert.Equal("\x07", val)
+}
+
+func TestMapStorageEngineHyperLogLogEncodings(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+
+	// an empty hyperloglog is a sparse header with a valid cardinality followed by a single XZERO opcode
+	updated, err := mse.PFAdd("hll", nil)
+	require.Nil(err)
+	assert.True(updated)
+	_, val, _ := mse.Get("hll")
+	assert.Equal("HYLL\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x7f\xff", val)
+
+	// the sparse encoding is kept while it is small and converted to the dense encoding once it grows
+	elements := []string{}
+	for idx := range 100 {
+		elements = append(elements, fmt.Sprintf("element:%d", idx))
+	}
+	_, err = mse.PFAdd("hll", elements)
+	require.Nil(err)
+	_, val, _ = mse.Get("hll")
+	assert.Equal(byte(storage.HLL_SPARSE), val[4])
+	sparseCount, err := mse.PFCount([]string{"hll"})
+	require.Nil(err)
+
+	require.Nil(mse.PFMerge("dense", []string{"hll"}))
+	for idx := range 5000 {
+		elements = append(elements, fmt.Sprintf("element:%d", idx))
+	}
+	_, err = mse.PFAdd("dense", elements)
+	require.Nil(err)
+	_, val, _ = mse.Get("dense")
+	assert.Equal(byte(storage.HLL_DENSE), val[4])
+	assert.Len(val, storage.HLL_DENSE_LEN)
+
+	// the registers of the sparse hyperloglog survive the conversion
+	union, err := mse.PFCount([]string{"dense", "hll"})
+	require.Nil(err)
+	denseCount, err := mse.PFCount([]string{"dense"})
+	require.Nil(err)
+	assert.Equal(denseCount, union)
+	assert.Greater(denseCount, sparseCount)
+
+	// the cardinality cached by PFCOUNT is invalidated by PFADD
+	_, val, _ = mse.Get("dense")
+	assert.Zero(val[15] & 0x80)
+	_, err = mse.PFAdd("dense", []string{"new element"})
+	require.Nil(err)
+	_, val, _ = mse.Get("dense")
+	assert.NotZero(val[15] & 0x80)
+
+	require.Nil(mse.Set("str", "HYLL", false, 0))
+	_, err = mse.PFCount([]string{"str"})
+	assert.ErrorIs(err, storage.ErrNotHyperLogLog)
+}
+
+func TestMapStorageEngineHyperLogLogAccuracy(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+
+	// three times the standard error of 0.81%
+	const maxError = 3 * 0.0081
+	withinError := func(card int, estimate int64) {
+		assert.LessOrEqual(math.Abs(float64(estimate-int64(card)))/float64(card), maxError, "cardinality %d estimated as %d", card, estimate)
+	}
+
+	elements := []string{}
+	for _, card := range []int{100, 1000, 10000} {
+		for idx := len(elements); idx < card; idx++ {
+			elements = append(elements, fmt.Sprintf("visitor:%d", idx))
+		}
+		_, err := mse.PFAdd("visitors", elements)
+		require.Nil(err)
+		estimate, err := mse.PFCount([]string{"visitors"})
+		require.Nil(err)
+		withinError(card, estimate)
+	}
+
+	// the union of two disjoint halves matches the whole
+	halves := [2][]string{}
+	for idx, element := range elements {
+		halves[idx%2] = append(halves[idx%2], element)
+	}
+	_, err := mse.PFAdd("even", halves[0])
+	require.Nil(err)
+	_, err = mse.PFAdd("odd", halves[1])
+	require.Nil(err)
+	union, err := mse.PFCount([]string{"even", "odd"})
+	require.Nil(err)
+	withinError(len(elements), union)
+}
+
+func TestMapStorageEngineGeoSearchMatchesScan(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+	rnd := rand.New(rand.NewPCG(1, 2))
+
+	members := make([]string, 2000)
+	scores := make([]uint64, len(members))
+	for idx := range members {
+		members[idx] = fmt.Sprintf("member:%d", idx)
+		scores[idx] = storage.GeoEncode(rnd.Float64()*360-180, rnd.Float64()*170-85)
+	}
+	_, err := mse.GeoAdd("geo", members, scores, false, false, false)
+	require.Nil(err)
+
+	centers := [][2]float64{{0, 0}, {179.9, 10}, {-179.9, -10}, {45, 84}, {-120, -84}, {12.5, 41.9}}
+	for _, center := range centers {
+		for _, radius := range []float64{1000, 500_000, 3_000_000, 15_000_000} {
+			for _, byBox := range []bool{false, true} {
+				query := storage.GeoQuery{Long: center[0], Lat: center[1], Radius: radius, ByBox: byBox, Width: radius, Height: 2 * radius}
+
+				want := []string{}
+				for idx, member := range members {
+					long, lat := storage.GeoDecode(scores[idx])
+					distance := storage.GeoDistance(center[0], center[1], long, lat)
+					inside := distance <= radius
+					if byBox {
+						inside = storage.GEO_EARTH_RADIUS*math.Abs(lat-center[1])*math.Pi/180 <= radius &&
+							storage.GeoDistance(center[0], lat, long, lat) <= radius/2
+					}
+					if inside {
+						want = append(want, member)
+					}
+				}
+
+				matches, err := mse.GeoSearch("geo", query)
+				require.Nil(err)
+				got := []string{}
+				for _, match := range matches {
+					got = append(got, match.Member)
+				}
+				assert.ElementsMatch(want, got, "center %v radius %f box %t", center, radius, byBox)
+			}
+		}
+	}
+}
+
+func TestMapStorageEngineGeoSearchStore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+	scores := []uint64{storage.GeoEncode(13.361389, 38.115556), storage.GeoEncode(15.087269, 37.502669)}
+	_, err := mse.GeoAdd("Sicily", []string{"Palermo", "Catania"}, scores, false, false, false)
+	require.Nil(err)
+	query := storage.GeoQuery{FromMember: true, Member: "Palermo", Radius: 200_000}
+
+	// the members keep their positions, or are stored with their distance in the given unit
+	stored, err := mse.GeoSearchStore("near", "Sicily", query, 0)
+	require.Nil(err)
+	assert.Equal(int64(2), stored)
+	_, nearScores, err := mse.GeoScores("near", []string{"Palermo", "Catania"})
+	require.Nil(err)
+	assert.Equal(scores, nearScores)
+
+	stored, err = mse.GeoSearchStore("near", "Sicily", query, 1000)
+	require.Nil(err)
+	assert.Equal(int64(2), stored)
+	_, nearScores, err = mse.GeoScores("near", []string{"Palermo", "Catania"})
+	require.Nil(err)
+	// the distance of Catania is 166.27 km, and the scores are truncated like the geohashes
+	assert.Equal([]uint64{0, 166}, nearScores)
+
+	// the distances survive a dump and restore
+	_, payload, _, err := mse.Dump("near")
+	require.Nil(err)
+	require.Nil(mse.Restore("copy", payload, false, time.Time{}))
+	_, copyScores, err := mse.GeoScores("copy", []string{"Palermo", "Catania"})
+	require.Nil(err)
+	assert.Equal(nearScores, copyScores)
+}
+
+func TestMapStorageEngineWrongType(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+	_, err := mse.GeoAdd("geo", []string{"a"}, []uint64{storage.GeoEncode(1, 1)}, false, false, false)
+	require.Nil(err)
+	require.Nil(mse.Set("str", "value", false, 0))
+
+	_, _, err = mse.Get("geo")
+	assert.ErrorIs(err, storage.ErrWrongType)
+	_, err = mse.Append("geo", "a")
+	assert.ErrorIs(err, storage.ErrWrongType)
+	_, err = mse.AtomicDelta("geo", 1)
+	assert.ErrorIs(err, storage.ErrWrongType)
+	_, err = mse.ListPush("geo", []string{"a"}, false)
+	assert.ErrorIs(err, storage.ErrWrongType)
+	_, err = mse.PFAdd("geo", []string{"a"})
+	assert.ErrorIs(err, storage.ErrWrongType)
+	_, err = mse.GeoSearch("str", storage.GeoQuery{Radius: 1})
+	assert.ErrorIs(err, storage.ErrWrongType)
+
+	// MGET reports keys holding other types as missing, and SET replaces them
+	found, _, err := mse.MGet([]string{"geo", "str"})
+	require.Nil(err)
+	assert.Equal([]bool{false, true}, found)
+	require.Nil(mse.Set("geo", "value", false, 0))
+	_, val, err := mse.Get("geo")
+	require.Nil(err)
+	assert.Equal("value", val)
+}
+
+func TestMapStorageEngineStreamOperations(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+	noTrim := storage.StreamTrim{Strategy: storage.STREAM_TRIM_NONE}
+
+	// generated IDs keep increasing even when the clock is behind the last ID
+	future := storage.StreamID{Ms: uint64(time.Now().Add(time.Hour).UnixMilli()), Seq: 7}
+	_, _, err := mse.XAdd("s", storage.StreamAddID{ID: future}, []string{"a", "1"}, false, noTrim)
+	require.Nil(err)
+	id, added, err := mse.XAdd("s", storage.StreamAddID{AutoMs: true}, []string{"b", "2"}, false, noTrim)
+	require.Nil(err)
+	assert.True(added)
+	assert.Equal(storage.StreamID{Ms: future.Ms, Seq: 8}, id)
+
+	last := storage.StreamID{Ms: math.MaxUint64, Seq: math.MaxUint64}
+	_, _, err = mse.XAdd("s", storage.StreamAddID{ID: last}, []string{"c", "3"}, false, noTrim)
+	require.Nil(err)
+	_, _, err = mse.XAdd("s", storage.StreamAddID{AutoMs: true}, []string{"d", "4"}, false, noTrim)
+	assert.ErrorIs(err, storage.ErrStreamIDExhausted)
+
+	ids, err := mse.StreamLastIDs([]string{"s", "nexist"})
+	require.Nil(err)
+	assert.Equal([]storage.StreamID{last, storage.MIN_STREAM_ID}, ids)
+
+	// nothing follows the largest ID
+	entries, err := mse.XRead([]string{"s", "nexist"}, []storage.StreamID{last, storage.MIN_STREAM_ID}, 0)
+	require.Nil(err)
+	assert.Equal([][]storage.StreamEntry{{}, {}}, entries)
+
+	removed, err := mse.XTrim("s", storage.StreamTrim{Strategy: storage.STREAM_TRIM_MINID, MinID: id})
+	require.Nil(err)
+	assert.Equal(int64(1), removed)
+	entries, err = mse.XRead([]string{"s"}, []storage.StreamID{storage.MIN_STREAM_ID}, 1)
+	require.Nil(err)
+	assert.Equal([][]storage.StreamEntry{{{ID: id, Fields: []string{"b", "2"}}}}, entries)
+
+	require.Nil(mse.Set("str", "value", false, 0))
+	_, err = mse.XLen("str")
+	assert.ErrorIs(err, storage.ErrWrongType)
+	_, err = mse.Append("s", "a")
+	assert.ErrorIs(err, storage.ErrWrongType)
+	_, err = mse.GeoSearch("s", storage.GeoQuery{Radius: 1})
+	assert.ErrorIs(err, storage.ErrWrongType)
+}
+
+func TestMapStorageEngineWatchStreams(t *testing.T) {
+	assert := assert.New(t)
+
+	mse := storage.NewMapStorageEngine()
+	noTrim := storage.StreamTrim{Strategy: storage.STREAM_TRIM_NONE}
+	added, stop := mse.WatchStreams([]string{"a", "b"})
+
+	_, _, err := mse.XAdd("c", storage.StreamAddID{AutoMs: true}, []string{"f", "v"}, false, noTrim)
+	assert.Nil(err)
+	assert.Len(added, 0)
+
+	// the channel holds a single signal, so adding to several streams never blocks XADD
+	for _, key := range []string{"a", "b"} {
+		_, _, err = mse.XAdd(key, storage.StreamAddID{AutoMs: true}, []string{"f", "v"}, false, noTrim)
+		assert.Nil(err)
+	}
+	assert.Len(added, 1)
+	<-added
+
+	stop()
+	_, _, err = mse.XAdd("a", storage.StreamAddID{AutoMs: true}, []string{"f", "v"}, false, noTrim)
+	assert.Nil(err)
+	assert.Len(added, 0)
+}
+
+func TestMapStorageEngineStreamGroups(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+	noTrim := storage.StreamTrim{Strategy: storage.STREAM_TRIM_NONE}
+	fields := []string{"f", "v"}
+	for ms := uint64(1); ms <= 5; ms++ {
+		_, _, err := mse.XAdd("s", storage.StreamAddID{ID: storage.StreamID{Ms: ms}}, fields, false, noTrim)
+		require.Nil(err)
+	}
+	require.Nil(mse.XGroupCreate("s", "g", storage.MIN_STREAM_ID, false, false, storage.STREAM_ENTRIES_READ_UNKNOWN))
+
+	groups, err := mse.XInfoGroups("s")
+	require.Nil(err)
+	require.Len(groups, 1)
+	assert.True(groups[0].HasLag)
+	assert.Equal(int64(5), groups[0].Lag)
+
+	readNew := func(consumer string, count int) []storage.StreamEntry {
+		results, err := mse.XReadGroup([]string{"s"}, "g", consumer, []storage.StreamID{{}}, []bool{true}, count, false)
+		require.Nil(err)
+		return results[0]
+	}
+	assert.Len(readNew("alice", 2), 2)
+	groups, err = mse.XInfoGroups("s")
+	require.Nil(err)
+	assert.Equal(int64(2), groups[0].EntriesRead)
+	assert.Equal(int64(3), groups[0].Lag)
+
+	// the lag can't be known once an entry after the last delivered one is deleted
+	deleted, err := mse.XDel("s", []storage.StreamID{{Ms: 4}})
+	require.Nil(err)
+	assert.Equal(int64(1), deleted)
+	groups, err = mse.XInfoGroups("s")
+	require.Nil(err)
+	assert.False(groups[0].HasLag)
+
+	assert.Len(readNew("alice", 0), 2)
+	groups, err = mse.XInfoGroups("s")
+	require.Nil(err)
+	assert.True(groups[0].HasLag)
+	assert.Equal(int64(0), groups[0].Lag)
+
+	// the cursor of XAUTOCLAIM continues from the first pending entry that was not scanned
+	next, claimed, deletedIDs, err := mse.XAutoClaim("s", "g", "bob", 0, storage.MIN_STREAM_ID, 2, false)
+	require.Nil(err)
+	assert.Equal(storage.StreamID{Ms: 3}, next)
+	assert.Equal([]storage.StreamEntry{{ID: storage.StreamID{Ms: 1}, Fields: fields}, {ID: storage.StreamID{Ms: 2}, Fields: fields}}, claimed)
+	assert.Empty(deletedIDs)
+
+	_, err = mse.XDel("s", []storage.StreamID{{Ms: 5}})
+	require.Nil(err)
+	next, claimed, deletedIDs, err = mse.XAutoClaim("s", "g", "bob", 0, next, 10, false)
+	require.Nil(err)
+	assert.Equal(storage.MIN_STREAM_ID, next)
+	assert.Equal([]storage.StreamEntry{{ID: storage.StreamID{Ms: 3}, Fields: fields}}, claimed)
+	assert.Equal([]storage.StreamID{{Ms: 5}}, deletedIDs)
+
+	summary, err := mse.XPending("s", "g")
+	require.Nil(err)
+	assert.Equal(storage.StreamPendingSummary{
+		Count:     3,
+		First:     storage.StreamID{Ms: 1},
+		Last:      storage.StreamID{Ms: 3},
+		Consumers: []storage.StreamConsumerPending{{Name: "bob", Count: 3}},
+	}, summary)
+
+	// claiming entries that are not pending needs FORCE
+	claimed, err = mse.XClaim("s", "g", "alice", 0, []storage.StreamID{{Ms: 5}, {Ms: 1}}, storage.StreamClaimOptions{})
+	require.Nil(err)
+	assert.Equal([]storage.StreamEntry{{ID: storage.StreamID{Ms: 1}, Fields: fields}}, claimed)
+	claimed, err = mse.XClaim("s", "g", "alice", time.Hour, []storage.StreamID{{Ms: 2}}, storage.StreamClaimOptions{})
+	require.Nil(err)
+	assert.Empty(claimed)
+
+	_, err = mse.XPending("s", "nexist")
+	assert.ErrorIs(err, storage.ErrNoGroup)
+	_, err = mse.XPending("nexist", "g")
+	assert.ErrorIs(err, storage.ErrNoSuchKey)
+	assert.ErrorIs(mse.XGroupCreate("s", "g", storage.MIN_STREAM_ID, false, false, 0), storage.ErrBusyGroup)
+}
+
+func TestMapStorageEngineSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+	noTrim := storage.StreamTrim{Strategy: storage.STREAM_TRIM_NONE}
+	expiresAt := time.Now().Add(time.Hour).UnixMilli()
+	require.Nil(mse.Set("str", "value", true, expiresAt))
+	require.Nil(mse.Set("expired", "value", true, time.Now().Add(-time.Second).UnixMilli()))
+	_, err := mse.GeoAdd("geo", []string{"a", "b"}, []uint64{storage.GeoEncode(13.36, 38.11), storage.GeoEncode(15.08, 37.5)}, false, false, false)
+	require.Nil(err)
+	for ms := uint64(1); ms <= 3; ms++ {
+		_, _, err = mse.XAdd("s", storage.StreamAddID{ID: storage.StreamID{Ms: ms}}, []string{"f", "v"}, false, noTrim)
+		require.Nil(err)
+	}
+	_, err = mse.XDel("s", []storage.StreamID{{Ms: 2}})
+	require.Nil(err)
+	require.Nil(mse.XGroupCreate("s", "g", storage.MIN_STREAM_ID, false, false, 0))
+	_, err = mse.XReadGroup([]string{"s"}, "g", "alice", []storage.StreamID{{}}, []bool{true}, 1, false)
+	require.Nil(err)
+	libraries := map[string]string{"lib": "#!lua name=lib\nredis.register_function('f', function() return 1 end)"}
+	mse.SetLibraries(libraries)
+
+	snapshot := mse.Snapshot()
+	loaded := storage.NewMapStorageEngine()
+	require.Nil(loaded.Set("stale", "value", false, 0))
+	require.Nil(loaded.LoadSnapshot(snapshot))
+
+	// the snapshot of the loaded dataset is the same, since keys and groups are written in order
+	assert.Equal(snapshot, loaded.Snapshot())
+	count, err := loaded.Exists([]string{"str", "expired", "stale", "geo", "s"})
+	require.Nil(err)
+	assert.Equal(3, count)
+	info, err := loaded.XInfoStream("s", true, 0)
+	require.Nil(err)
+	assert.Equal(int64(2), info.Length)
+	assert.Equal(storage.StreamID{Ms: 2}, info.MaxDeletedID)
+	require.Len(info.Groups, 1)
+	assert.Equal(int64(1), info.Groups[0].Pending)
+	assert.Equal("alice", info.Groups[0].ConsumerInfos[0].Name)
+	assert.Equal(libraries, loaded.Libraries())
+
+	// corrupted snapshots are rejected without touching the dataset
+	snapshot[len(snapshot)/2] ^= 0xff
+	assert.ErrorIs(loaded.LoadSnapshot(snapshot), storage.ErrInvalidSnapshot)
+	assert.ErrorIs(loaded.LoadSnapshot([]byte("CCKV")), storage.ErrInvalidSnapshot)
+	found, value, err := loaded.Get("str")
+	require.Nil(err)
+	assert.True(found)
+	assert.Equal("value", value)
+}
+
+func TestMapStorageEngineDumpRestore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+	noTrim := storage.StreamTrim{Strategy: storage.STREAM_TRIM_NONE}
+	require.Nil(mse.Set("str", "value", true, time.Now().Add(time.Hour).UnixMilli()))
+	_, err := mse.GeoAdd("geo", []string{"a"}, []uint64{storage.GeoEncode(13.36, 38.11)}, false, false, false)
+	require.Nil(err)
+	_, _, err = mse.XAdd("s", storage.StreamAddID{ID: storage.StreamID{Ms: 1}}, []string{"f", "v"}, false, noTrim)
+	require.Nil(err)
+
+	found, _, _, err := mse.Dump("nexist")
+	require.Nil(err)
+	assert.False(found)
+
+	// the restored values dump to the same payloads, and the expiry comes along with the payload
+	restored := storage.NewMapStorageEngine()
+	for _, key := range []string{"str", "geo", "s"} {
+		found, payload, expiresAt, err := mse.Dump(key)
+		require.Nil(err)
+		require.True(found)
+		assert.Equal(key == "str", !expiresAt.IsZero(), key)
+		require.Nil(restored.Restore(key, payload, false, expiresAt))
+		_, restoredPayload, restoredExpiresAt, err := restored.Dump(key)
+		require.Nil(err)
+		assert.Equal(payload, restoredPayload, key)
+		assert.True(expiresAt.Equal(restoredExpiresAt), key)
+	}
+	entries, err := restored.XRange("s", storage.MIN_STREAM_ID, storage.MAX_STREAM_ID, -1, false)
+	require.Nil(err)
+	assert.Equal([]storage.StreamEntry{{ID: storage.StreamID{Ms: 1}, Fields: []string{"f", "v"}}}, entries)
+
+	_, payload, _, err := mse.Dump("str")
+	require.Nil(err)
+	assert.ErrorIs(restored.Restore("str", payload, false, time.Time{}), storage.ErrBusyKey)
+	require.Nil(restored.Restore("geo", payload, true, time.Time{}))
+	found, value, err := restored.Get("geo")
+	require.Nil(err)
+	assert.True(found)
+	assert.Equal("value", value)
+
+	// a value restored without an expiry doesn't expire, even when it was dumped with one
+	require.Nil(restored.Restore("str", payload, true, time.Time{}))
+	_, _, expiresAt, err := restored.Dump("str")
+	require.Nil(err)
+	assert.True(expiresAt.IsZero())
+
+	// values restored already expired are not stored
+	require.Nil(restored.Restore("str", payload, true, time.Now().Add(-time.Second)))
+	count, err := restored.Exists([]string{"str"})
+	require.Nil(err)
+	assert.Equal(0, count)
+
+	corrupted := slices.Clone(payload)
+	corrupted[0] ^= 0xff
+	assert.ErrorIs(restored.Restore("key", corrupted, false, time.Time{}), storage.ErrInvalidDump)
+	assert.ErrorIs(restored.Restore("key", payload[:5], false, time.Time{}), storage.ErrInvalidDump)
+}
+
+func TestMapStorageEngineLibraries(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+	assert.Empty(mse.Libraries())
+	libraries := map[string]string{"a": "#!lua name=a", "b": "#!lua name=b"}
+	mse.SetLibraries(libraries)
+
+	// the libraries are copied in and out of the storage engine
+	libraries["c"] = "#!lua name=c"
+	stored := mse.Libraries()
+	assert.Equal(map[string]string{"a": "#!lua name=a", "b": "#!lua name=b"}, stored)
+	delete(stored, "a")
+	assert.Len(mse.Libraries(), 2)
+
+	payload := storage.DumpLibraries(mse.Libraries())
+	restored, err := storage.RestoreLibraries(payload)
+	require.Nil(err)
+	assert.Equal(mse.Libraries(), restored)
+
+	payload[0] ^= 0xff
+	_, err = storage.RestoreLibraries(payload)
+	assert.ErrorIs(err, storage.ErrInvalidDump)
+	_, err = storage.RestoreLibraries([]byte("payload"))
+	assert.ErrorIs(err, storage.ErrInvalidDump)
+}
+
+func TestMapStorageEngineNotifications(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+	events := []storage.KeyspaceEvent{}
+	mse.SetNotifier(func(event storage.KeyspaceEvent) {
+		events = append(events, event)
+	})
+	event := func(class byte, name string, key string) storage.KeyspaceEvent {
+		return storage.KeyspaceEvent{Class: class, Name: name, Key: key}
+	}
+
+	require.Nil(mse.Set("key", "value", true, time.Now().Add(time.Hour).UnixMilli()))
+	_, err := mse.AtomicDelta("key", 1)
+	require.Error(err)
+	_, err = mse.ListPush("list", []string{"a"}, true)
+	require.Nil(err)
+	_, err = mse.ListPush("list", []string{"b"}, false)
+	require.Nil(err)
+	_, _, err = mse.XAdd("stream", storage.StreamAddID{AutoMs: true}, []string{"f", "v"}, false, storage.StreamTrim{Strategy: storage.STREAM_TRIM_MAXLEN, MaxLen: 0})
+	require.Nil(err)
+	_, err = mse.Delete([]string{"key", "nexist"})
+	require.Nil(err)
+	_, err = mse.BitOp(storage.BITOP_AND, "dest", []string{"nexist"})
+	require.Nil(err)
+
+	assert.Equal([]storage.KeyspaceEvent{
+		event(storage.EVENT_CLASS_NEW, "new", "key"),
+		event(storage.EVENT_CLASS_STRING, "set", "key"),
+		event(storage.EVENT_CLASS_GENERIC, "expire", "key"),
+		event(storage.EVENT_CLASS_NEW, "new", "list"),
+		event(storage.EVENT_CLASS_LIST, "lpush", "list"),
+		event(storage.EVENT_CLASS_LIST, "rpush", "list"),
+		event(storage.EVENT_CLASS_NEW, "new", "stream"),
+		event(storage.EVENT_CLASS_STREAM, "xadd", "stream"),
+		event(storage.EVENT_CLASS_STREAM, "xtrim", "stream"),
+		event(storage.EVENT_CLASS_GENERIC, "del", "key"),
+	}, events)
+
+	// the expired keys are removed when they are looked up, or by DeleteExpired
+	past := time.Now().Add(-time.Second).UnixMilli()
+	for _, key := range []string{"a", "b", "c"} {
+		require.Nil(mse.Set(key, "value", true, past))
+	}
+	events = events[:0]
+	found, _, err := mse.Get("a")
+	require.Nil(err)
+	assert.False(found)
+	deleted, err := mse.Delete([]string{"b"})
+	require.Nil(err)
+	assert.Equal(0, deleted)
+	expired, sampled := mse.DeleteExpired(20)
+	assert.Equal(1, expired)
+	assert.Equal(1, sampled)
+	assert.Equal([]storage.KeyspaceEvent{
+		event(storage.EVENT_CLASS_EXPIRED, "expired", "a"),
+		event(storage.EVENT_CLASS_EXPIRED, "expired", "b"),
+		event(storage.EVENT_CLASS_EXPIRED, "expired", "c"),
+	}, events)
+	assert.Equal([]string{"list", "stream"}, mse.Keys())
+}
+
+func TestMapStorageEngineDeleteExpired(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mse := storage.NewMapStorageEngine()
+	past := time.Now().Add(-time.Second).UnixMilli()
+	future := time.Now().Add(time.Hour).UnixMilli()
+	for idx := range 1000 {
+		require.Nil(mse.Set("plain:"+strconv.Itoa(idx), "value", false, 0))
+	}
+	for idx := range 10 {
+		require.Nil(mse.Set("volatile:"+strconv.Itoa(idx), "value", true, future))
+	}
+	for idx := range 5 {
+		require.Nil(mse.Set("expired:"+strconv.Itoa(idx), "value", true, past))
+	}
+
+	// only the keys with an expiry are sampled
+	expired, sampled := mse.DeleteExpired(100)
+	assert.Equal(5, expired)
+	assert.Equal(15, sampled)
+	_, sampled = mse.DeleteExpired(4)
+	assert.Equal(4, sampled)
+
+	// the keys leave the sample once their expiry is removed, or once they are overwritten or deleted
+	_, _, err := mse.GetEx("volatile:0", true, false, 0)
+	require.Nil(err)
+	require.Nil(mse.Set("volatile:1", "value", false, 0))
+	_, err = mse.Delete([]string{"volatile:2"})
+	require.Nil(err)
+	_, sampled = mse.DeleteExpired(100)
+	assert.Equal(7, sampled)
+
+	loaded := storage.NewMapStorageEngine()
+	require.Nil(loaded.LoadSnapshot(mse.Snapshot()))
+	_, sampled = loaded.DeleteExpired(100)
+	assert.Equal(7, sampled)
+}
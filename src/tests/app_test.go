@@ -25,7 +25,9 @@ func TestApplicationWithConcurrentClient(t *testing.T) {
 
 	strgEng := storage.NewMapStorageEngine()
 	cmdHandler := handler.NewCommandHandler(&strgEng)
-	listener, err := server.NewTcpServer(serverPort, cmdHandler.ServeInput)
+	listener, err := server.NewTcpServer(serverPort, func(conn net.Conn) server.Session {
+		return cmdHandler.NewClient(conn)
+	})
 	require.Nil(err)
 
 	go listener.Serve()
@@ -54,6 +56,11 @@ func TestApplicationWithConcurrentClient(t *testing.T) {
 		{"*5\r\n$3\r\nDEL\r\n$6\r\nelpmas\r\n$4\r\ntest\r\n$2\r\nn1\r\n$2\r\nn2\r\n", ":2\r\n"},
 		{"*4\r\n$3\r\nSET\r\n$6\r\nelpmas\r\n$2\r\nPX\r\n$2\r\n32\r\n", "-wrong number of arguments for 'set' command\r\n"},
 		{"*5\r\n$3\r\nSET\r\n$6\r\nelpmas\r\n$8\r\necnetnes\r\n$2\r\nPX\r\n$2\r\n32\r\n", "+OK\r\n"},
+		{"*3\r\n$3\r\nset\r\n$5\r\nlower\r\n$4\r\ncase\r\n", "+OK\r\n"},
+		{"PING\r\n", "+PONG\r\n"},
+		{"get lower\r\n", "$4\r\ncase\r\n"},
+		{"set \"with space\" 'it\\'s'\r\n", "+OK\r\n"},
+		{"GET \"with space\"\r\n", "$4\r\nit's\r\n"},
 	}
 
 	tcSets := [][]TestCase{tcSet1, tcSet2}
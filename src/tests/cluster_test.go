@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+)
+
+func startClusterServer(t *testing.T, port string) handler.CommandHandler {
+	cfg := config.NewConfig()
+	require.Nil(t, cfg.Set("port", port))
+	require.Nil(t, cfg.Set("cluster-enabled", "yes"))
+	require.Nil(t, cfg.Set("cluster-node-timeout", "2000"))
+	_, cmdHandler := startServerWithConfig(t, port, cfg)
+	require.Nil(t, cmdHandler.StartCluster())
+	t.Cleanup(cmdHandler.StopCluster)
+	return cmdHandler
+}
+
+func TestCluster(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	nodeA := startClusterServer(t, "34573")
+	nodeB := startClusterServer(t, "34574")
+	nodeC := startClusterServer(t, "34575")
+	nodes := []handler.CommandHandler{nodeA, nodeB, nodeC}
+	idA := nodeA.HandleCommand(bulkCmd("CLUSTER", "MYID")).(data.BulkString).Data
+	idB := nodeB.HandleCommand(bulkCmd("CLUSTER", "MYID")).(data.BulkString).Data
+
+	// a single node meets the others, which then learn about each other through gossip
+	require.Equal(handler.OK, nodeA.HandleCommand(bulkCmd("CLUSTER", "MEET", "127.0.0.1", "34574")))
+	require.Equal(handler.OK, nodeA.HandleCommand(bulkCmd("CLUSTER", "MEET", "127.0.0.1", "34575", "44575")))
+	require.Equal(handler.OK, nodeA.HandleCommand(bulkCmd("CLUSTER", "ADDSLOTSRANGE", "0", "5460")))
+	require.Equal(handler.OK, nodeB.HandleCommand(bulkCmd("CLUSTER", "ADDSLOTSRANGE", "5461", "10922")))
+	require.Equal(handler.OK, nodeC.HandleCommand(bulkCmd("CLUSTER", "ADDSLOTSRANGE", "10923", "16383")))
+	for _, node := range nodes {
+		waitFor(t, func() bool {
+			info := node.HandleCommand(bulkCmd("CLUSTER", "INFO")).(data.BulkString).Data
+			return strings.Contains(info, "cluster_state:ok\r\n") && strings.Contains(info, "cluster_known_nodes:3\r\n")
+		})
+	}
+
+	// the keys are served by the node owning their slot
+	assert.Equal(data.Error{ErrMsg: "MOVED 12182 127.0.0.1:34575"}, nodeA.HandleCommand(bulkCmd("SET", "foo", "value")))
+	assert.Equal(data.Error{ErrMsg: "MOVED 12182 127.0.0.1:34575"}, nodeB.HandleCommand(bulkCmd("GET", "foo")))
+	assert.Equal(handler.OK, nodeC.HandleCommand(bulkCmd("SET", "foo", "value")))
+	assert.Equal(data.Error{ErrMsg: "CROSSSLOT Keys in request don't hash to the same slot"}, nodeC.HandleCommand(bulkCmd("MGET", "foo", "bar")))
+
+	slots := nodeB.HandleCommand(bulkCmd("CLUSTER", "SLOTS")).(data.Array).Elements
+	require.Len(slots, 3)
+	assert.Equal(data.Array{Elements: []data.Message{
+		data.Integer{Value: 0},
+		data.Integer{Value: 5460},
+		data.Array{Elements: []data.Message{data.BulkString{Data: "127.0.0.1"}, data.Integer{Value: 34573}, data.BulkString{Data: idA}}},
+	}}, slots[0])
+	assert.Len(nodeC.HandleCommand(bulkCmd("CLUSTER", "SHARDS")).(data.Array).Elements, 3)
+
+	description := nodeA.HandleCommand(bulkCmd("CLUSTER", "NODES")).(data.BulkString).Data
+	lines := strings.Split(strings.TrimSuffix(description, "\n"), "\n")
+	require.Len(lines, 3)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		assert.Equal("connected", fields[7], line)
+		if fields[0] == idA {
+			assert.Equal([]string{"127.0.0.1:34573@44573", "myself,master"}, fields[1:3])
+			assert.Equal("0-5460", fields[8])
+		}
+	}
+
+	// slot 5061 of bar moves from the first node to the second one, with its keys sent by MIGRATE
+	require.Equal(handler.OK, nodeA.HandleCommand(bulkCmd("SET", "bar", "value")))
+	require.Equal(handler.OK, nodeB.HandleCommand(bulkCmd("CLUSTER", "SETSLOT", "5061", "IMPORTING", idA)))
+	require.Equal(handler.OK, nodeA.HandleCommand(bulkCmd("CLUSTER", "SETSLOT", "5061", "MIGRATING", idB)))
+	assert.Contains(nodeA.HandleCommand(bulkCmd("CLUSTER", "NODES")).(data.BulkString).Data, "[5061->-"+idB+"]")
+
+	assert.Equal(data.BulkString{Data: "value"}, nodeA.HandleCommand(bulkCmd("GET", "bar")))
+	assert.Equal(data.Error{ErrMsg: "ASK 5061 127.0.0.1:34574"}, nodeA.HandleCommand(bulkCmd("GET", "{bar}.new")))
+	assert.Equal(data.Error{ErrMsg: "TRYAGAIN Multiple keys request during rehashing of slot"}, nodeA.HandleCommand(bulkCmd("MGET", "bar", "{bar}.new")))
+	assert.Equal(data.Error{ErrMsg: "MOVED 5061 127.0.0.1:34573"}, nodeB.HandleCommand(bulkCmd("SET", "{bar}.new", "value")))
+
+	conn, err := net.Dial("tcp4", "127.0.0.1:34574")
+	require.Nil(err)
+	defer func() { _ = conn.Close() }()
+	assert.Equal("+OK\r\n", roundTrip(t, conn, "*1\r\n$6\r\nASKING\r\n"))
+	assert.Equal("+OK\r\n", roundTrip(t, conn, "*3\r\n$3\r\nSET\r\n$9\r\n{bar}.new\r\n$5\r\nvalue\r\n"))
+	assert.Equal("-MOVED 5061 127.0.0.1:34573\r\n", roundTrip(t, conn, "*2\r\n$3\r\nGET\r\n$9\r\n{bar}.new\r\n"))
+
+	assert.Equal(data.Error{ErrMsg: "Can't assign hashslot 5061 to a different node while I still hold keys for this hash slot."}, nodeA.HandleCommand(bulkCmd("CLUSTER", "SETSLOT", "5061", "NODE", idB)))
+	assert.Equal(data.Error{ErrMsg: "CROSSSLOT Keys in request don't hash to the same slot"}, nodeA.HandleCommand(bulkCmd("MIGRATE", "127.0.0.1", "34574", "", "0", "1000", "KEYS", "bar", "foo")))
+	require.Equal(handler.OK, nodeA.HandleCommand(bulkCmd("MIGRATE", "127.0.0.1", "34574", "", "0", "1000", "KEYS", "bar", "{bar}.nexist")))
+	assert.Equal(data.Error{ErrMsg: "ASK 5061 127.0.0.1:34574"}, nodeA.HandleCommand(bulkCmd("GET", "bar")))
+	require.Equal(handler.OK, nodeA.HandleCommand(bulkCmd("CLUSTER", "SETSLOT", "5061", "NODE", idB)))
+	require.Equal(handler.OK, nodeB.HandleCommand(bulkCmd("CLUSTER", "SETSLOT", "5061", "NODE", idB)))
+
+	// the new owner of the slot is propagated to the rest of the cluster
+	waitFor(t, func() bool {
+		return nodeC.HandleCommand(bulkCmd("GET", "{bar}.new")) == data.Error{ErrMsg: "MOVED 5061 127.0.0.1:34574"}
+	})
+	assert.Equal(data.Error{ErrMsg: "MOVED 5061 127.0.0.1:34574"}, nodeA.HandleCommand(bulkCmd("GET", "{bar}.new")))
+	assert.Equal(data.BulkString{Data: "value"}, nodeB.HandleCommand(bulkCmd("GET", "{bar}.new")))
+	assert.Equal(data.BulkString{Data: "value"}, nodeB.HandleCommand(bulkCmd("GET", "bar")))
+	assert.Equal(data.Integer{Value: 2}, nodeB.HandleCommand(bulkCmd("CLUSTER", "COUNTKEYSINSLOT", "5061")))
+	assert.Equal("0-5060 5062-5460", rangesOf(t, nodeC, idA))
+}
+
+// rangesOf returns the slots of a node as listed by CLUSTER NODES.
+func rangesOf(t *testing.T, ch handler.CommandHandler, id string) string {
+	for _, line := range strings.Split(ch.HandleCommand(bulkCmd("CLUSTER", "NODES")).(data.BulkString).Data, "\n") {
+		if fields := strings.Fields(line); len(fields) > 8 && fields[0] == id {
+			return strings.Join(fields[8:], " ")
+		}
+	}
+	require.Fail(t, "node not found", id)
+	return ""
+}
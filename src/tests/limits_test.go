@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/server"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func startServerWithConfig(t *testing.T, port string, cfg *config.Config) (*server.Server, handler.CommandHandler) {
+	strgEng := storage.NewMapStorageEngine()
+	cmdHandler := handler.NewCommandHandlerWithConfig(&strgEng, cfg)
+	srv := server.NewServer(func(conn net.Conn) server.Session {
+		return cmdHandler.NewClient(conn)
+	})
+	srv.ApplyConfig(cfg)
+	require.Nil(t, srv.ListenTcp([]string{"127.0.0.1"}, port))
+	t.Cleanup(srv.StopListen)
+	cmdHandler.StartActiveExpiry()
+	t.Cleanup(cmdHandler.StopActiveExpiry)
+
+	go srv.Serve()
+	return srv, cmdHandler
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			require.Fail(t, "condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMaxClients(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("maxclients", "1"))
+	srv, _ := startServerWithConfig(t, "34568", cfg)
+
+	conn1, err := net.Dial("tcp4", "127.0.0.1:34568")
+	require.Nil(err)
+	assert.Equal("+PONG\r\n", roundTrip(t, conn1, "*1\r\n$4\r\nPING\r\n"))
+
+	conn2, err := net.Dial("tcp4", "127.0.0.1:34568")
+	require.Nil(err)
+	reply, err := io.ReadAll(conn2)
+	require.Nil(err)
+	assert.Equal("-ERR max number of clients reached\r\n", string(reply))
+	_ = conn2.Close()
+
+	_ = conn1.Close()
+	waitFor(t, func() bool { return srv.ActiveConnections() == 0 })
+
+	// raising the limit at runtime lets more clients in
+	require.Nil(cfg.Set("maxclients", "2"))
+	conn3, err := net.Dial("tcp4", "127.0.0.1:34568")
+	require.Nil(err)
+	defer func() { _ = conn3.Close() }()
+	conn4, err := net.Dial("tcp4", "127.0.0.1:34568")
+	require.Nil(err)
+	defer func() { _ = conn4.Close() }()
+	assert.Equal("+PONG\r\n", roundTrip(t, conn3, "*1\r\n$4\r\nPING\r\n"))
+	assert.Equal("+PONG\r\n", roundTrip(t, conn4, "*1\r\n$4\r\nPING\r\n"))
+}
+
+func TestMaxClientsConcurrentConnects(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("maxclients", "5"))
+	strgEng := storage.NewMapStorageEngine()
+	cmdHandler := handler.NewCommandHandlerWithConfig(&strgEng, cfg)
+	srv := server.NewServer(func(conn net.Conn) server.Session {
+		return cmdHandler.NewClient(conn)
+	})
+	srv.ApplyConfig(cfg)
+	// each listener accepts connections on its own goroutine
+	require.Nil(srv.ListenTcp([]string{"127.0.0.1", "127.0.0.2"}, "34585"))
+	t.Cleanup(srv.StopListen)
+	go srv.Serve()
+
+	replies := make(chan string)
+	conns := make(chan net.Conn, 40)
+	for idx := range 40 {
+		go func() {
+			conn, err := net.Dial("tcp4", fmt.Sprintf("127.0.0.%d:34585", idx%2+1))
+			if err != nil {
+				replies <- err.Error()
+				return
+			}
+			conns <- conn
+			_, _ = conn.Write([]byte("*1\r\n$4\r\nPING\r\n"))
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			reply := make([]byte, 64)
+			n, _ := conn.Read(reply)
+			replies <- string(reply[:n])
+		}()
+	}
+	defer func() {
+		close(conns)
+		for conn := range conns {
+			_ = conn.Close()
+		}
+	}()
+
+	accepted := 0
+	for range 40 {
+		switch reply := <-replies; reply {
+		case "+PONG\r\n":
+			accepted++
+		default:
+			assert.Equal("-ERR max number of clients reached\r\n", reply)
+		}
+	}
+	assert.Equal(5, accepted)
+	assert.Equal(int64(5), srv.ActiveConnections())
+}
+
+func TestIdleTimeout(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("timeout", "1"))
+	srv, _ := startServerWithConfig(t, "34569", cfg)
+
+	conn, err := net.Dial("tcp4", "127.0.0.1:34569")
+	require.Nil(err)
+	defer func() { _ = conn.Close() }()
+	assert.Equal("+PONG\r\n", roundTrip(t, conn, "*1\r\n$4\r\nPING\r\n"))
+
+	start := time.Now()
+	require.Nil(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	_, err = conn.Read(make([]byte, 16))
+	assert.Equal(io.EOF, err)
+	assert.GreaterOrEqual(time.Since(start), 900*time.Millisecond)
+
+	waitFor(t, func() bool { return srv.ActiveConnections() == 0 })
+}
+
+func TestIdleTimeoutSubscriber(t *testing.T) {
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("timeout", "1"))
+	_, cmdHandler := startServerWithConfig(t, "34581", cfg)
+
+	conn, err := net.Dial("tcp4", "127.0.0.1:34581")
+	require.Nil(err)
+	defer func() { _ = conn.Close() }()
+	_, err = conn.Write([]byte(bulkCmd("SUBSCRIBE", "news").ToDataString()))
+	require.Nil(err)
+	readExactly(t, conn, subscriptionReply("subscribe", "news", 1))
+
+	// the subscriber only receives messages, so it is kept open past the timeout
+	time.Sleep(1500 * time.Millisecond)
+	require.Equal(data.Integer{Value: 1}, cmdHandler.HandleCommand(bulkCmd("PUBLISH", "news", "hello")))
+	readExactly(t, conn, bulkCmd("message", "news", "hello"))
+}
+
+func TestOutputBufferLimit(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("client-output-buffer-limit", "normal 1mb 0 0"))
+	srv, cmdHandler := startServerWithConfig(t, "34570", cfg)
+
+	bigValue := strings.Repeat("x", 1024*1024)
+	cmdHandler.HandleCommand(data.Array{Elements: []data.Message{
+		data.BulkString{Data: "SET"},
+		data.BulkString{Data: "big"},
+		data.BulkString{Data: bigValue},
+	}})
+
+	conn, err := net.Dial("tcp4", "127.0.0.1:34570")
+	require.Nil(err)
+	defer func() { _ = conn.Close() }()
+	require.Nil(conn.(*net.TCPConn).SetReadBuffer(4096))
+
+	// request far more output than the socket buffers can hold without reading any of it
+	numRequests := 64
+	for range numRequests {
+		if _, err := conn.Write([]byte("*2\r\n$3\r\nGET\r\n$3\r\nbig\r\n")); err != nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	waitFor(t, func() bool { return srv.ActiveConnections() == 0 })
+
+	require.Nil(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	received, _ := io.ReadAll(conn)
+	assert.Less(len(received), numRequests*len(bigValue))
+}
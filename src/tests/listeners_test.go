@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/server"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+func roundTrip(t *testing.T, conn net.Conn, input string) string {
+	_, err := conn.Write([]byte(input))
+	require.Nil(t, err)
+
+	reply := make([]byte, 1024)
+	numBytesRead, err := conn.Read(reply)
+	require.Nil(t, err)
+	return string(reply[:numBytesRead])
+}
+
+func TestApplicationWithMultipleListeners(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	serverPort := "34567"
+	socketPath := filepath.Join(t.TempDir(), "cc-kv-go.sock")
+
+	strgEng := storage.NewMapStorageEngine()
+	cmdHandler := handler.NewCommandHandler(&strgEng)
+	srv := server.NewServer(func(conn net.Conn) server.Session {
+		return cmdHandler.NewClient(conn)
+	})
+
+	// a required address that cannot be bound fails, an optional one is skipped
+	assert.NotNil(srv.ListenTcp([]string{"192.0.2.1"}, serverPort))
+	assert.NotNil(srv.ListenTcp([]string{"-192.0.2.1"}, serverPort))
+
+	require.Nil(srv.ListenTcp([]string{"127.0.0.1", "-::1"}, serverPort))
+	require.Nil(srv.ListenUnix(socketPath, 0o770))
+	defer srv.StopListen()
+
+	info, err := os.Stat(socketPath)
+	require.Nil(err)
+	assert.Equal(os.FileMode(0o770), info.Mode().Perm())
+
+	go srv.Serve()
+
+	unixConn, err := net.Dial("unix", socketPath)
+	require.Nil(err)
+	defer func() { _ = unixConn.Close() }()
+
+	tcpConn, err := net.Dial("tcp4", "127.0.0.1:"+serverPort)
+	require.Nil(err)
+	defer func() { _ = tcpConn.Close() }()
+
+	// all listeners feed the same command handler
+	assert.Equal("+OK\r\n", roundTrip(t, unixConn, "*3\r\n$3\r\nSET\r\n$6\r\nsocket\r\n$4\r\nunix\r\n"))
+	assert.Equal("$4\r\nunix\r\n", roundTrip(t, tcpConn, "*2\r\n$3\r\nGET\r\n$6\r\nsocket\r\n"))
+
+	clientInfo := roundTrip(t, unixConn, "*2\r\n$6\r\nCLIENT\r\n$4\r\nINFO\r\n")
+	assert.Contains(clientInfo, " addr="+socketPath+":0 ")
+
+	listeningOnIPv6 := false
+	for _, addr := range srv.Addrs() {
+		if strings.HasPrefix(addr.String(), "[::1]") {
+			listeningOnIPv6 = true
+		}
+	}
+	if !listeningOnIPv6 {
+		t.Log("IPv6 loopback is unavailable, skipping IPv6 checks")
+		return
+	}
+
+	ipv6Conn, err := net.Dial("tcp6", "[::1]:"+serverPort)
+	require.Nil(err)
+	defer func() { _ = ipv6Conn.Close() }()
+	assert.Equal("$4\r\nunix\r\n", roundTrip(t, ipv6Conn, "*2\r\n$3\r\nGET\r\n$6\r\nsocket\r\n"))
+}
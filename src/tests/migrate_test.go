@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+)
+
+func TestMigrate(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	source := startReplicationServer(t, "34576")
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("port", "34577"))
+	require.Nil(cfg.Set("requirepass", "secret"))
+	_, target := startServerWithConfig(t, "34577", cfg)
+
+	require.Equal(handler.OK, source.HandleCommand(bulkCmd("SET", "str", "value", "EX", "100")))
+	source.HandleCommand(bulkCmd("XADD", "stream", "1-1", "field", "value"))
+	require.Equal(handler.OK, source.HandleCommand(bulkCmd("SET", "other", "value")))
+
+	// the target requires authentication
+	assert.Equal(data.Error{ErrMsg: "Target instance replied with error: NOAUTH Authentication required."}, source.HandleCommand(bulkCmd("MIGRATE", "127.0.0.1", "34577", "str", "0", "1000")))
+	assert.Equal(data.Integer{Value: 1}, source.HandleCommand(bulkCmd("EXISTS", "str")))
+
+	// a key is moved along with its expiry
+	assert.Equal(handler.OK, source.HandleCommand(bulkCmd("MIGRATE", "127.0.0.1", "34577", "str", "0", "1000", "AUTH", "secret")))
+	assert.Equal(data.Integer{Value: 0}, source.HandleCommand(bulkCmd("EXISTS", "str")))
+	assert.Equal(data.BulkString{Data: "value"}, target.HandleCommand(bulkCmd("GET", "str")))
+	assert.Equal(data.SimpleString{Contents: "NOKEY"}, source.HandleCommand(bulkCmd("MIGRATE", "127.0.0.1", "34577", "str", "0", "1000", "AUTH", "secret")))
+
+	// the remaining time to live is sent with the key, which expires on the target too
+	require.Equal(handler.OK, source.HandleCommand(bulkCmd("SET", "short", "value", "PX", "300")))
+	assert.Equal(handler.OK, source.HandleCommand(bulkCmd("MIGRATE", "127.0.0.1", "34577", "short", "0", "1000", "AUTH", "secret")))
+	assert.Equal(data.BulkString{Data: "value"}, target.HandleCommand(bulkCmd("GET", "short")))
+	waitFor(t, func() bool { return target.HandleCommand(bulkCmd("GET", "short")) == data.Null{} })
+
+	// the keys can only be moved to database 0 of another cc-kv-go server
+	assert.Equal(data.Error{ErrMsg: "DB index is out of range"}, source.HandleCommand(bulkCmd("MIGRATE", "127.0.0.1", "34577", "other", "1", "1000", "AUTH", "secret")))
+	foreignPort := startForeignServer(t)
+	assert.Equal(data.Error{ErrMsg: "Target instance is not a cc-kv-go server, which is the only one the keys can be migrated to"},
+		source.HandleCommand(bulkCmd("MIGRATE", "127.0.0.1", foreignPort, "other", "0", "1000")))
+	assert.Equal(data.Integer{Value: 1}, source.HandleCommand(bulkCmd("EXISTS", "other")))
+
+	// COPY leaves the keys on the source, and REPLACE overwrites the keys of the target
+	assert.Equal(handler.OK, source.HandleCommand(bulkCmd("MIGRATE", "127.0.0.1", "34577", "", "0", "1000", "COPY", "AUTH2", "default", "secret", "KEYS", "stream", "other", "nexist")))
+	assert.Equal(data.Integer{Value: 2}, source.HandleCommand(bulkCmd("EXISTS", "stream", "other")))
+	assert.Equal(source.HandleCommand(bulkCmd("XRANGE", "stream", "-", "+")), target.HandleCommand(bulkCmd("XRANGE", "stream", "-", "+")))
+	assert.Equal(data.Error{ErrMsg: "Target instance replied with error: BUSYKEY Target key name already exists."}, source.HandleCommand(bulkCmd("MIGRATE", "127.0.0.1", "34577", "other", "0", "1000", "AUTH", "secret")))
+
+	require.Equal(handler.OK, source.HandleCommand(bulkCmd("SET", "other", "new")))
+	assert.Equal(handler.OK, source.HandleCommand(bulkCmd("MIGRATE", "127.0.0.1", "34577", "", "0", "1000", "REPLACE", "AUTH", "secret", "KEYS", "stream", "other")))
+	assert.Equal(data.Integer{Value: 0}, source.HandleCommand(bulkCmd("EXISTS", "stream", "other")))
+	assert.Equal(data.BulkString{Data: "new"}, target.HandleCommand(bulkCmd("GET", "other")))
+}
+
+// startForeignServer starts a server that answers HELLO like redis and fails every other command, returning its port.
+func startForeignServer(t *testing.T) string {
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					request, err := data.ReadRequest(reader)
+					if err != nil {
+						return
+					}
+					var reply data.Message = data.Error{ErrMsg: "ERR unexpected command"}
+					if request.(data.Array).Elements[0] == (data.BulkString{Data: "HELLO"}) {
+						reply = bulkCmd("server", "redis", "version", "7.4.0")
+					}
+					_, _ = conn.Write([]byte(reply.ToDataString()))
+				}
+			}()
+		}
+	}()
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
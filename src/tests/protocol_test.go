@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+)
+
+func TestRequestSplitAcrossWrites(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	_, cmdHandler := startServerWithConfig(t, "34582", config.NewConfig())
+	cmdHandler.HandleCommand(bulkCmd("SET", "victim", "alive"))
+
+	conn, err := net.Dial("tcp4", "127.0.0.1:34582")
+	require.Nil(err)
+	defer conn.Close()
+
+	// the second half of the bulk string looks like an inline command when read on its own
+	value := "ab\r\nDEL victim\r\n"
+	request := bulkCmd("SET", "k", value).ToDataString()
+	splitAt := len(request) - len("DEL victim\r\n\r\n")
+	_, err = conn.Write([]byte(request[:splitAt]))
+	require.Nil(err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = conn.Write([]byte(request[splitAt:]))
+	require.Nil(err)
+	readExactly(t, conn, data.SimpleString{Contents: "OK"})
+
+	assert.Equal(data.BulkString{Data: value}, cmdHandler.HandleCommand(bulkCmd("GET", "k")))
+	assert.Equal(data.BulkString{Data: "alive"}, cmdHandler.HandleCommand(bulkCmd("GET", "victim")))
+
+	// inline commands are still served when they start a request
+	_, err = conn.Write([]byte("PING\r\n"))
+	require.Nil(err)
+	readExactly(t, conn, data.SimpleString{Contents: "PONG"})
+}
+
+func TestLargeMsetInSmallWrites(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	_, cmdHandler := startServerWithConfig(t, "34583", config.NewConfig())
+
+	conn, err := net.Dial("tcp4", "127.0.0.1:34583")
+	require.Nil(err)
+	defer conn.Close()
+
+	args := []string{"MSET"}
+	for idx := range 5000 {
+		args = append(args, fmt.Sprintf("key:%d", idx), fmt.Sprintf("value:%d", idx))
+	}
+	request := bulkCmd(args...).ToDataString()
+	for start := 0; start < len(request); start += 1000 {
+		_, err = conn.Write([]byte(request[start:min(start+1000, len(request))]))
+		require.Nil(err)
+	}
+	readExactly(t, conn, data.SimpleString{Contents: "OK"})
+
+	assert.Equal(data.BulkString{Data: "value:0"}, cmdHandler.HandleCommand(bulkCmd("GET", "key:0")))
+	assert.Equal(data.BulkString{Data: "value:4999"}, cmdHandler.HandleCommand(bulkCmd("GET", "key:4999")))
+}
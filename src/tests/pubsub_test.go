@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+)
+
+// readExactly reads the given replies from the connection, which must be exactly the expected ones.
+func readExactly(t *testing.T, conn net.Conn, replies ...data.Message) {
+	expected := ""
+	for _, reply := range replies {
+		expected += reply.ToDataString()
+	}
+
+	require.Nil(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	received := make([]byte, len(expected))
+	_, err := io.ReadFull(conn, received)
+	require.Nil(t, err)
+	assert.Equal(t, expected, string(received))
+}
+
+func subscriptionReply(kind string, target string, count int64) data.Array {
+	return data.Array{Elements: []data.Message{data.BulkString{Data: kind}, data.BulkString{Data: target}, data.Integer{Value: count}}}
+}
+
+func TestKeyspaceNotifications(t *testing.T) {
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	require.Nil(cfg.Set("notify-keyspace-events", "KEA"))
+	_, cmdHandler := startServerWithConfig(t, "34578", cfg)
+
+	conn, err := net.Dial("tcp4", "127.0.0.1:34578")
+	require.Nil(err)
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write([]byte(bulkCmd("SUBSCRIBE", "__keyevent@0__:expired").ToDataString() + bulkCmd("PSUBSCRIBE", "__keyspace@0__:*").ToDataString()))
+	require.Nil(err)
+	readExactly(t, conn, subscriptionReply("subscribe", "__keyevent@0__:expired", 1), subscriptionReply("psubscribe", "__keyspace@0__:*", 2))
+
+	keyspace := func(key string, event string) data.Array {
+		return bulkCmd("pmessage", "__keyspace@0__:*", "__keyspace@0__:"+key, event)
+	}
+
+	// the key is never accessed again, and is removed by the active expiry cycle
+	require.Equal(handler.OK, cmdHandler.HandleCommand(bulkCmd("SET", "counter", "1")))
+	require.Equal(data.Integer{Value: 6}, cmdHandler.HandleCommand(bulkCmd("INCRBY", "counter", "5")))
+	require.Equal(data.Integer{Value: 1}, cmdHandler.HandleCommand(bulkCmd("DEL", "counter")))
+	require.Equal(handler.OK, cmdHandler.HandleCommand(bulkCmd("SET", "session", "token", "PX", "50")))
+	readExactly(t, conn,
+		keyspace("counter", "set"),
+		keyspace("counter", "incrby"),
+		keyspace("counter", "del"),
+		keyspace("session", "set"),
+		keyspace("session", "expire"),
+		keyspace("session", "expired"),
+		bulkCmd("message", "__keyevent@0__:expired", "session"),
+	)
+}
+
+func TestPushesFollowReplies(t *testing.T) {
+	require := require.New(t)
+
+	_, cmdHandler := startServerWithConfig(t, "34584", config.NewConfig())
+
+	// a monitor gets its own commands after their replies
+	monitor, err := net.Dial("tcp4", "127.0.0.1:34584")
+	require.Nil(err)
+	defer func() { _ = monitor.Close() }()
+	require.Equal("+OK\r\n", roundTrip(t, monitor, "MONITOR\r\n"))
+	_, err = monitor.Write([]byte(bulkCmd("PING").ToDataString()))
+	require.Nil(err)
+	require.Nil(monitor.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	monitorReader := bufio.NewReader(monitor)
+	reply, err := monitorReader.ReadString('\n')
+	require.Nil(err)
+	require.Equal("+PONG\r\n", reply)
+	line, err := monitorReader.ReadString('\n')
+	require.Nil(err)
+	require.True(strings.HasSuffix(line, "\"PING\"\r\n"), line)
+
+	stop := make(chan struct{})
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				cmdHandler.HandleCommand(bulkCmd("PUBLISH", "news", "hello"))
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-published
+	}()
+
+	// the confirmation of SUBSCRIBE comes first, even though messages are published while the pipelined
+	// requests that follow it are served
+	for range 20 {
+		conn, err := net.Dial("tcp4", "127.0.0.1:34584")
+		require.Nil(err)
+
+		request := bulkCmd("SUBSCRIBE", "news").ToDataString() + strings.Repeat(bulkCmd("PING").ToDataString(), 1000)
+		_, err = conn.Write([]byte(request))
+		require.Nil(err)
+		require.Nil(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+		msg, err := data.ReadMessage(bufio.NewReader(conn))
+		require.Nil(err)
+		require.Equal(subscriptionReply("subscribe", "news", 1), msg)
+		require.Nil(conn.Close())
+	}
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+)
+
+func bulkCmd(args ...string) data.Array {
+	elements := make([]data.Message, len(args))
+	for idx, arg := range args {
+		elements[idx] = data.BulkString{Data: arg}
+	}
+	return data.Array{Elements: elements}
+}
+
+func startReplicationServer(t *testing.T, port string) handler.CommandHandler {
+	cfg := config.NewConfig()
+	require.Nil(t, cfg.Set("port", port))
+	_, cmdHandler := startServerWithConfig(t, port, cfg)
+	return cmdHandler
+}
+
+func infoField(ch handler.CommandHandler, section string, field string) string {
+	info := ch.HandleCommand(bulkCmd("INFO", section)).(data.BulkString).Data
+	for _, line := range strings.Split(info, "\r\n") {
+		if value, ok := strings.CutPrefix(line, field+":"); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func TestReplication(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	master := startReplicationServer(t, "34571")
+	replica := startReplicationServer(t, "34572")
+
+	// the data written before the replica connects comes with the full synchronisation
+	require.Equal(handler.OK, master.HandleCommand(bulkCmd("SET", "before", "sync")))
+	require.Equal(handler.OK, master.HandleCommand(bulkCmd("SET", "volatile", "value", "EX", "100")))
+	master.HandleCommand(bulkCmd("XADD", "stream", "*", "field", "1"))
+	require.Equal(data.BulkString{Data: "counters"}, master.HandleCommand(bulkCmd("FUNCTION", "LOAD",
+		"#!lua name=counters\nredis.register_function('incrtwice', function(keys) redis.call('INCR', keys[1]) return redis.call('INCR', keys[1]) end)")))
+
+	require.Equal(handler.OK, replica.HandleCommand(bulkCmd("REPLICAOF", "127.0.0.1", "34571")))
+	t.Cleanup(func() { replica.HandleCommand(bulkCmd("REPLICAOF", "NO", "ONE")) })
+	waitFor(t, func() bool { return infoField(replica, "replication", "master_link_status") == "up" })
+
+	assert.Equal(data.BulkString{Data: "sync"}, replica.HandleCommand(bulkCmd("GET", "before")))
+	assert.Equal(data.BulkString{Data: "value"}, replica.HandleCommand(bulkCmd("GET", "volatile")))
+	assert.Equal("1", infoField(master, "stats", "sync_full"))
+	assert.Equal(master.HandleCommand(bulkCmd("FUNCTION", "LIST")), replica.HandleCommand(bulkCmd("FUNCTION", "LIST")))
+
+	// the writes that follow are streamed, with the IDs generated by the master
+	require.Equal(handler.OK, master.HandleCommand(bulkCmd("SET", "after", "sync")))
+	master.HandleCommand(bulkCmd("XADD", "stream", "*", "field", "2"))
+	master.HandleCommand(bulkCmd("XGROUP", "CREATE", "stream", "group", "0"))
+	master.HandleCommand(bulkCmd("XREADGROUP", "GROUP", "group", "consumer", "BLOCK", "10", "STREAMS", "stream", ">"))
+	for range 3 {
+		master.HandleCommand(bulkCmd("INCR", "counter"))
+	}
+	master.HandleCommand(bulkCmd("GET", "counter"))
+	require.Equal(data.BulkString{Data: "getters"}, master.HandleCommand(bulkCmd("FUNCTION", "LOAD",
+		"#!lua name=getters\nredis.register_function{function_name='read', callback=function(keys) return redis.call('GET', keys[1]) end, flags={'no-writes'}}")))
+	assert.Equal(data.Integer{Value: 2}, master.HandleCommand(bulkCmd("FCALL", "incrtwice", "1", "fcounter")))
+	assert.Equal(data.Integer{Value: 1}, master.HandleCommand(bulkCmd("WAIT", "1", "5000")))
+
+	assert.Equal(data.BulkString{Data: "sync"}, replica.HandleCommand(bulkCmd("GET", "after")))
+	assert.Equal(data.BulkString{Data: "3"}, replica.HandleCommand(bulkCmd("GET", "counter")))
+	assert.Equal(master.HandleCommand(bulkCmd("XRANGE", "stream", "-", "+")), replica.HandleCommand(bulkCmd("XRANGE", "stream", "-", "+")))
+	assert.Equal(master.HandleCommand(bulkCmd("XPENDING", "stream", "group")), replica.HandleCommand(bulkCmd("XPENDING", "stream", "group")))
+	assert.Equal(data.BulkString{Data: "2"}, replica.HandleCommand(bulkCmd("FCALL_RO", "read", "1", "fcounter")))
+
+	// relative expiries and blocked group reads are replicated by their effects
+	monitor, err := net.Dial("tcp4", "127.0.0.1:34572")
+	require.Nil(err)
+	defer monitor.Close()
+	require.Equal("+OK\r\n", roundTrip(t, monitor, "MONITOR\r\n"))
+
+	read := make(chan data.Message)
+	go func() {
+		read <- master.HandleCommand(bulkCmd("XREADGROUP", "GROUP", "group", "blocked", "BLOCK", "5000", "STREAMS", "stream", ">"))
+	}()
+	require.Equal(handler.OK, master.HandleCommand(bulkCmd("SET", "relative", "value", "EX", "100")))
+	id := master.HandleCommand(bulkCmd("XADD", "stream", "*", "field", "3")).(data.BulkString)
+	require.IsType(data.Array{}, <-read)
+	assert.Equal(data.Integer{Value: 1}, master.HandleCommand(bulkCmd("WAIT", "1", "5000")))
+
+	require.Nil(monitor.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	monitorReader := bufio.NewReader(monitor)
+	received := ""
+	for !strings.Contains(received, `"SETID"`) || !strings.Contains(received, `"relative"`) {
+		line, err := monitorReader.ReadString('\n')
+		require.Nil(err)
+		received += line
+	}
+	assert.Contains(received, `"SET" "relative" "value" "PXAT"`)
+	assert.Contains(received, `"XCLAIM" "stream" "group" "blocked" "0" "`+id.Data+`"`)
+	assert.NotContains(received, `"XREADGROUP"`)
+	assert.Equal(master.HandleCommand(bulkCmd("XPENDING", "stream", "group")), replica.HandleCommand(bulkCmd("XPENDING", "stream", "group")))
+	assert.Equal(master.HandleCommand(bulkCmd("XINFO", "GROUPS", "stream")), replica.HandleCommand(bulkCmd("XINFO", "GROUPS", "stream")))
+
+	// the replica is read only, and both servers report their role
+	assert.Equal(data.Error{ErrMsg: "READONLY You can't write against a read only replica."}, replica.HandleCommand(bulkCmd("SET", "key", "value")))
+	assert.Equal(data.Error{ErrMsg: "READONLY You can't write against a read only replica."}, replica.HandleCommand(bulkCmd("FUNCTION", "FLUSH")))
+	waitFor(t, func() bool {
+		return infoField(master, "replication", "master_repl_offset") == infoField(replica, "replication", "master_repl_offset")
+	})
+	offset := master.HandleCommand(bulkCmd("ROLE")).(data.Array).Elements[1]
+	assert.Equal(data.Array{Elements: []data.Message{
+		data.BulkString{Data: "slave"},
+		data.BulkString{Data: "127.0.0.1"},
+		data.Integer{Value: 34571},
+		data.BulkString{Data: "connected"},
+		offset,
+	}}, replica.HandleCommand(bulkCmd("ROLE")))
+	replicas := master.HandleCommand(bulkCmd("ROLE")).(data.Array).Elements[2].(data.Array).Elements
+	require.Len(replicas, 1)
+	assert.Equal(data.BulkString{Data: "34572"}, replicas[0].(data.Array).Elements[1])
+	assert.Equal("1", infoField(master, "replication", "connected_slaves"))
+	assert.True(strings.HasPrefix(infoField(master, "replication", "slave0"), "ip=127.0.0.1,port=34572,state=online,"))
+	assert.Equal(infoField(master, "replication", "master_replid"), infoField(replica, "replication", "master_replid"))
+
+	// after losing the connection, the replica only gets the writes it missed
+	assert.Equal(data.Integer{Value: 1}, replica.HandleCommand(bulkCmd("CLIENT", "KILL", "TYPE", "master")))
+	require.Equal(handler.OK, master.HandleCommand(bulkCmd("SET", "during", "outage")))
+	waitFor(t, func() bool { return infoField(master, "stats", "sync_partial_ok") == "1" })
+	waitFor(t, func() bool {
+		return replica.HandleCommand(bulkCmd("GET", "during")) == data.BulkString{Data: "outage"}
+	})
+	assert.Equal("1", infoField(master, "stats", "sync_full"))
+
+	// a promoted replica takes writes and remembers the history of its former master
+	masterID := infoField(master, "replication", "master_replid")
+	assert.Equal(handler.OK, replica.HandleCommand(bulkCmd("REPLICAOF", "NO", "ONE")))
+	assert.Equal(data.BulkString{Data: "master"}, replica.HandleCommand(bulkCmd("ROLE")).(data.Array).Elements[0])
+	assert.Equal(handler.OK, replica.HandleCommand(bulkCmd("SET", "key", "value")))
+	assert.Equal(masterID, infoField(replica, "replication", "master_replid2"))
+	assert.NotEqual(masterID, infoField(replica, "replication", "master_replid"))
+	waitFor(t, func() bool { return infoField(master, "replication", "connected_slaves") == "0" })
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+	"github.com/vrajashkr/cc-kv-go/src/server"
+	"github.com/vrajashkr/cc-kv-go/src/storage"
+)
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// generateCertificate creates a certificate signed by the parent, or a self-signed CA when parent is nil.
+func generateCertificate(t *testing.T, serial int64, parent *testCertificate, isServer bool) *testCertificate {
+	require := require.New(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "cc-kv-go test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+
+	signerCert := template
+	signerKey := key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signerCert = parent.cert
+		signerKey = parent.key
+		if isServer {
+			template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+			template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+			template.DNSNames = []string{"localhost"}
+		} else {
+			template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	require.Nil(err)
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.Nil(err)
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}
+}
+
+func (tc *testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(tc.certPEM, tc.keyPEM)
+	require.Nil(t, err)
+	return cert
+}
+
+func TestApplicationOverTls(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	serverPort := "34566"
+
+	ca := generateCertificate(t, 1, nil, false)
+	serverCert := generateCertificate(t, 2, ca, true)
+	clientCert := generateCertificate(t, 3, ca, false)
+
+	certDir := t.TempDir()
+	certFile := filepath.Join(certDir, "server.crt")
+	keyFile := filepath.Join(certDir, "server.key")
+	caFile := filepath.Join(certDir, "ca.crt")
+	require.Nil(os.WriteFile(certFile, serverCert.certPEM, 0o600))
+	require.Nil(os.WriteFile(keyFile, serverCert.keyPEM, 0o600))
+	require.Nil(os.WriteFile(caFile, ca.certPEM, 0o600))
+
+	_, err := server.NewTlsCertificates(certFile, keyFile, "", server.TLS_AUTH_CLIENTS_YES)
+	assert.NotNil(err)
+
+	certs, err := server.NewTlsCertificates(certFile, keyFile, caFile, server.TLS_AUTH_CLIENTS_YES)
+	require.Nil(err)
+
+	strgEng := storage.NewMapStorageEngine()
+	cmdHandler := handler.NewCommandHandler(&strgEng)
+	listener, err := server.NewTlsServer(serverPort, certs, func(conn net.Conn) server.Session {
+		return cmdHandler.NewClient(conn)
+	})
+	require.Nil(err)
+	defer listener.StopListen()
+
+	go listener.Serve()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.cert)
+
+	dial := func(withClientCert bool) (*tls.Conn, error) {
+		clientConfig := &tls.Config{
+			RootCAs:    rootCAs,
+			ServerName: "localhost",
+			MinVersion: tls.VersionTLS12,
+		}
+		if withClientCert {
+			clientConfig.Certificates = []tls.Certificate{clientCert.tlsCertificate(t)}
+		}
+		return tls.Dial("tcp", "127.0.0.1:"+serverPort, clientConfig)
+	}
+
+	roundTrip := func(conn *tls.Conn, input string) (string, error) {
+		if _, err := conn.Write([]byte(input)); err != nil {
+			return "", err
+		}
+		reply := make([]byte, 1024)
+		numBytesRead, err := conn.Read(reply)
+		return string(reply[:numBytesRead]), err
+	}
+
+	// clients without a certificate are rejected
+	conn, err := dial(false)
+	if err == nil {
+		_, err = roundTrip(conn, "*1\r\n$4\r\nPING\r\n")
+		_ = conn.Close()
+	}
+	assert.NotNil(err)
+
+	// clients with a certificate signed by the CA are served
+	conn, err = dial(true)
+	require.Nil(err)
+	reply, err := roundTrip(conn, "*3\r\n$3\r\nSET\r\n$5\r\nhello\r\n$5\r\nworld\r\n")
+	require.Nil(err)
+	assert.Equal("+OK\r\n", reply)
+	reply, err = roundTrip(conn, "*2\r\n$3\r\nGET\r\n$5\r\nhello\r\n")
+	require.Nil(err)
+	assert.Equal("$5\r\nworld\r\n", reply)
+	assert.Equal(big.NewInt(2), conn.ConnectionState().PeerCertificates[0].SerialNumber)
+	_ = conn.Close()
+
+	// reloading picks up the new certificate for new connections
+	renewedCert := generateCertificate(t, 4, ca, true)
+	require.Nil(os.WriteFile(certFile, renewedCert.certPEM, 0o600))
+	require.Nil(os.WriteFile(keyFile, renewedCert.keyPEM, 0o600))
+	require.Nil(certs.Reload())
+
+	conn, err = dial(true)
+	require.Nil(err)
+	reply, err = roundTrip(conn, "*1\r\n$4\r\nPING\r\n")
+	require.Nil(err)
+	assert.Equal("+PONG\r\n", reply)
+	assert.Equal(big.NewInt(4), conn.ConnectionState().PeerCertificates[0].SerialNumber)
+	_ = conn.Close()
+
+	// a broken certificate is rejected and the previous one stays in use
+	require.Nil(os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+	assert.NotNil(certs.Reload())
+
+	conn, err = dial(true)
+	require.Nil(err)
+	assert.Equal(big.NewInt(4), conn.ConnectionState().PeerCertificates[0].SerialNumber)
+	_ = conn.Close()
+}
@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrajashkr/cc-kv-go/src/config"
+	"github.com/vrajashkr/cc-kv-go/src/data"
+	"github.com/vrajashkr/cc-kv-go/src/handler"
+)
+
+func TestClientTrackingInvalidation(t *testing.T) {
+	require := require.New(t)
+
+	_, cmdHandler := startServerWithConfig(t, "34579", config.NewConfig())
+
+	subscriber, err := net.Dial("tcp4", "127.0.0.1:34579")
+	require.Nil(err)
+	defer func() { _ = subscriber.Close() }()
+	tracker, err := net.Dial("tcp4", "127.0.0.1:34579")
+	require.Nil(err)
+	defer func() { _ = tracker.Close() }()
+
+	id := strings.Trim(roundTrip(t, subscriber, bulkCmd("CLIENT", "ID").ToDataString()), ":\r\n")
+	_, err = subscriber.Write([]byte(bulkCmd("SUBSCRIBE", handler.TRACKING_CHANNEL).ToDataString()))
+	require.Nil(err)
+	readExactly(t, subscriber, subscriptionReply("subscribe", handler.TRACKING_CHANNEL, 1))
+
+	require.Equal(handler.OK, cmdHandler.HandleCommand(bulkCmd("SET", "session", "token", "PX", "100")))
+	_, err = tracker.Write([]byte(
+		bulkCmd("CLIENT", "TRACKING", "ON", "REDIRECT", id).ToDataString() +
+			bulkCmd("MGET", "key", "session").ToDataString(),
+	))
+	require.Nil(err)
+	readExactly(t, tracker, handler.OK, data.Array{Elements: []data.Message{data.Null{}, data.BulkString{Data: "token"}}})
+
+	// the session is never accessed again, and is invalidated when the active expiry cycle removes it
+	require.Equal(handler.OK, cmdHandler.HandleCommand(bulkCmd("SET", "key", "value")))
+	invalidation := func(key string) data.Array {
+		return data.Array{Elements: []data.Message{
+			data.BulkString{Data: "message"},
+			data.BulkString{Data: handler.TRACKING_CHANNEL},
+			bulkCmd(key),
+		}}
+	}
+	readExactly(t, subscriber, invalidation("key"), invalidation("session"))
+}